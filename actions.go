@@ -1,10 +1,15 @@
 package agentbrowser
 
 import (
+	"cmp"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
+	"slices"
+	"strings"
+	"time"
 )
 
 // ExecuteCommand executes a command and returns the response.
@@ -28,18 +33,62 @@ func ExecuteCommand(cmd Command, browser *BrowserManager) Response {
 		return handleUncheck(c, browser)
 	case *PressCommand:
 		return handlePress(c, browser)
+	case *KeyboardCommand:
+		return handleKeyboard(c, browser)
+	case *InsertTextCommand:
+		return handleInsertText(c, browser)
 	case *HoverCommand:
 		return handleHover(c, browser)
+	case *TapCommand:
+		return handleTap(c, browser)
+	case *InputMouseCommand:
+		return handleInputMouse(c, browser)
+	case *MouseMoveCommand:
+		return handleMouseMove(c, browser)
+	case *MouseDownCommand:
+		return handleMouseDown(c, browser)
+	case *MouseUpCommand:
+		return handleMouseUp(c, browser)
+	case *WheelCommand:
+		return handleWheel(c, browser)
+	case *InputKeyboardCommand:
+		return handleInputKeyboard(c, browser)
+	case *KeyDownCommand:
+		return handleKeyDown(c, browser)
+	case *KeyUpCommand:
+		return handleKeyUp(c, browser)
+	case *ClipboardCommand:
+		return handleClipboard(c, browser)
+	case *HighlightCommand:
+		return handleHighlight(c, browser)
+	case *SelectAllCommand:
+		return handleSelectAll(c, browser)
+	case *DispatchEventCommand:
+		return handleDispatchEvent(c, browser)
+	case *AddScriptCommand:
+		return handleAddScript(c, browser)
+	case *AddStyleCommand:
+		return handleAddStyle(c, browser)
+	case *AddInitScriptCommand:
+		return handleAddInitScript(c, browser)
 	case *FocusCommand:
 		return handleFocus(c, browser)
 	case *ClearCommand:
 		return handleClear(c, browser)
 	case *SelectCommand:
 		return handleSelect(c, browser)
+	case *UploadCommand:
+		return handleUpload(c, browser)
 	case *DoubleClickCommand:
 		return handleDoubleClick(c, browser)
 	case *ScreenshotCommand:
 		return handleScreenshot(c, browser)
+	case *PdfCommand:
+		return handlePdf(c, browser)
+	case *TraceStartCommand:
+		return handleTraceStart(c, browser)
+	case *TraceStopCommand:
+		return handleTraceStop(c, browser)
 	case *SnapshotCommand:
 		return handleSnapshot(c, browser)
 	case *EvaluateCommand:
@@ -76,12 +125,30 @@ func ExecuteCommand(cmd Command, browser *BrowserManager) Response {
 		return handleCount(c, browser)
 	case *BoundingBoxCommand:
 		return handleBoundingBox(c, browser)
+	case *DescribeCommand:
+		return handleDescribe(c, browser)
+	case *TextCommand:
+		return handleText(c, browser)
+	case *LinksCommand:
+		return handleLinks(c, browser)
+	case *FormsCommand:
+		return handleForms(c, browser)
+	case *FillFormCommand:
+		return handleFillForm(c, browser)
 	case *URLCommand:
 		return handleURL(c, browser)
+	case *WaitForURLCommand:
+		return handleWaitForURL(c, browser)
+	case *WaitForLoadStateCommand:
+		return handleWaitForLoadState(c, browser)
 	case *TitleCommand:
 		return handleTitle(c, browser)
 	case *BackCommand:
 		return handleBack(c, browser)
+	case *HistoryCommand:
+		return handleHistory(c, browser)
+	case *HistoryGoCommand:
+		return handleHistoryGo(c, browser)
 	case *ForwardCommand:
 		return handleForward(c, browser)
 	case *ReloadCommand:
@@ -90,126 +157,533 @@ func ExecuteCommand(cmd Command, browser *BrowserManager) Response {
 		return handleViewport(c, browser)
 	case *TabNewCommand:
 		return handleTabNew(c, browser)
+	case *WindowNewCommand:
+		return handleWindowNew(c, browser)
 	case *TabListCommand:
 		return handleTabList(c, browser)
+	case *BringToFrontCommand:
+		return handleBringToFront(c, browser)
 	case *TabSwitchCommand:
 		return handleTabSwitch(c, browser)
 	case *TabCloseCommand:
 		return handleTabClose(c, browser)
+	case *FrameCommand:
+		return handleFrame(c, browser)
+	case *MainFrameCommand:
+		return handleMainFrame(c, browser)
+	case *GetByRoleCommand:
+		return handleGetByRole(c, browser)
+	case *GetByTextCommand:
+		return handleGetByText(c, browser)
+	case *GetByLabelCommand:
+		return handleGetByLabel(c, browser)
+	case *GetByPlaceholderCommand:
+		return handleGetByPlaceholder(c, browser)
+	case *GetByAltTextCommand:
+		return handleGetByAltText(c, browser)
+	case *GetByTitleCommand:
+		return handleGetByTitle(c, browser)
+	case *GetByTestIdCommand:
+		return handleGetByTestId(c, browser)
 	case *CloseCommand:
 		return handleClose(c, browser)
+	case *WatchStartCommand:
+		return handleWatchStart(c, browser)
+	case *WatchStopCommand:
+		return handleWatchStop(c, browser)
+	case *ScreencastStartCommand:
+		return handleScreencastStart(c, browser)
+	case *ScreencastStopCommand:
+		return handleScreencastStop(c, browser)
+	case *RecordStartCommand:
+		return handleRecordStart(c, browser)
+	case *RecordStopCommand:
+		return handleRecordStop(c, browser)
+	case *RequestsCommand:
+		return handleRequests(c, browser)
+	case *ConsoleCommand:
+		return handleConsole(c, browser)
+	case *ErrorsCommand:
+		return handleErrors(c, browser)
+	case *PerfCommand:
+		return handlePerf(c, browser)
+	case *StatsCommand:
+		return handleStats(c, browser)
+	case *AuditCommand:
+		return handleAudit(c, browser)
+	case *ChallengeCommand:
+		return handleChallenge(c, browser)
+	case *GeolocationCommand:
+		return handleGeolocation(c, browser)
+	case *PermissionsCommand:
+		return handlePermissions(c, browser)
+	case *UserAgentCommand:
+		return handleUserAgent(c, browser)
+	case *LocaleCommand:
+		return handleLocale(c, browser)
+	case *HTTPCredentialsCommand:
+		return handleHTTPCredentials(c, browser)
+	case *OfflineCommand:
+		return handleOffline(c, browser)
+	case *DialogCommand:
+		return handleDialog(c, browser)
+	case *HeadersCommand:
+		return handleHeaders(c, browser)
+	case *EmulateMediaCommand:
+		return handleEmulateMedia(c, browser)
+	case *UARotationStartCommand:
+		return handleUARotationStart(c, browser)
+	case *UARotationStopCommand:
+		return handleUARotationStop(c, browser)
+	case *UARotateCommand:
+		return handleUARotate(c, browser)
+	case *CookiesGetCommand:
+		return handleCookiesGet(c, browser)
+	case *CookiesSetCommand:
+		return handleCookiesSet(c, browser)
+	case *CookiesClearCommand:
+		return handleCookiesClear(c, browser)
+	case *CookiesExportCommand:
+		return handleCookiesExport(c, browser)
+	case *StorageGetCommand:
+		return handleStorageGet(c, browser)
+	case *StorageSetCommand:
+		return handleStorageSet(c, browser)
+	case *StorageClearCommand:
+		return handleStorageClear(c, browser)
+	case *CookiesImportCommand:
+		return handleCookiesImport(c, browser)
+	case *FetchCommand:
+		return handleFetch(c, browser)
+	case *CrawlCommand:
+		return handleCrawl(c, browser)
+	case *PaginateCommand:
+		return handlePaginate(c, browser)
+	case *PoliteCommand:
+		return handlePolite(c, browser)
+	case *SitemapCommand:
+		return handleSitemap(c, browser)
+	case *ClearDataCommand:
+		return handleClearData(c, browser)
+	case *ServiceWorkersCommand:
+		return handleServiceWorkers(c, browser)
+	case *ServiceWorkersUnregisterCommand:
+		return handleServiceWorkersUnregister(c, browser)
+	case *ServiceWorkersBypassCommand:
+		return handleServiceWorkersBypass(c, browser)
+	case *StateSaveCommand:
+		return handleStateSave(c, browser)
+	case *StateLoadCommand:
+		return handleStateLoad(c, browser)
+	case *LoginCommand:
+		return handleLogin(c, browser)
 	default:
 		return ErrorResponse(id, fmt.Sprintf("unsupported action: %s", cmd.GetAction()))
 	}
 }
 
 func handleLaunch(cmd *LaunchCommand, browser *BrowserManager) Response {
-	headless := true
-	if cmd.Headless != nil {
-		headless = *cmd.Headless
+	if cmd.Browser != "" && cmd.Browser != "chromium" {
+		return ErrorResponse(cmd.ID, fmt.Sprintf("unsupported browser %q: only chromium is supported", cmd.Browser))
 	}
 
-	opts := LaunchOptions{
-		Headless:       headless,
-		Viewport:       cmd.Viewport,
-		ExecutablePath: cmd.ExecutablePath,
-		CDPPort:        cmd.CDPPort,
+	// Options the command leaves out keep the session's
+	opts := browser.launchDefaultOptions()
+	if cmd.Headless != nil {
+		opts.Headless = *cmd.Headless
+	}
+	if cmd.Viewport != nil {
+		if cmd.Viewport.Width <= 0 || cmd.Viewport.Height <= 0 {
+			return ErrorResponse(cmd.ID, "viewport width and height must be positive")
+		}
+		opts.Viewport = cmd.Viewport
+	}
+	if cmd.ExecutablePath != "" {
+		opts.ExecutablePath = cmd.ExecutablePath
+	}
+	if cmd.CDPPort != 0 {
+		if cmd.CDPPort < 0 || cmd.CDPPort > 65535 {
+			return ErrorResponse(cmd.ID, fmt.Sprintf("invalid CDP port %d", cmd.CDPPort))
+		}
+		opts.CDPPort = cmd.CDPPort
+	}
+	if len(cmd.Extensions) > 0 {
+		for _, dir := range cmd.Extensions {
+			if err := checkExtension(dir); err != nil {
+				return errorResponse(cmd.ID, err)
+			}
+		}
+		opts.Extensions = cmd.Extensions
+	}
+	if cmd.Fingerprint != nil {
+		opts.Fingerprint = cmd.Fingerprint
+	}
+	opts.Stealth = opts.Stealth || cmd.Stealth
+	opts.BlockServiceWorkers = opts.BlockServiceWorkers || cmd.BlockServiceWorkers
+	opts.TLS.IgnoreHTTPSErrors = opts.TLS.IgnoreHTTPSErrors || cmd.IgnoreHTTPSErrors
+	if len(cmd.ClientCertificates) > 0 {
+		for _, cert := range cmd.ClientCertificates {
+			if err := cert.Validate(); err != nil {
+				return errorResponse(cmd.ID, err)
+			}
+		}
+		opts.TLS.ClientCertificates = cmd.ClientCertificates
+	}
+	if len(cmd.HostRules) > 0 {
+		opts.HostRules = nil
+		for _, rule := range cmd.HostRules {
+			normalized, err := ParseHostRule(rule)
+			if err != nil {
+				return errorResponse(cmd.ID, err)
+			}
+			opts.HostRules = append(opts.HostRules, normalized)
+		}
+	}
+	if cmd.Proxy != "" {
+		proxy, err := ParseProxy(cmd.Proxy, cmd.ProxyBypass)
+		if err != nil {
+			return errorResponse(cmd.ID, err)
+		}
+		opts.Proxy = proxy
+	}
+	// Set before launching, so invalid headers fail first and the first
+	// page's requests carry them
+	if cmd.Headers != nil {
+		if err := browser.SetExtraHeaders(cmd.Headers); err != nil {
+			return errorResponse(cmd.ID, err)
+		}
 	}
 
 	if err := browser.Launch(opts); err != nil {
-		return ErrorResponse(cmd.ID, err.Error())
+		resp := ErrorResponse(cmd.ID, err.Error())
+		resp.Code = CodeBrowser
+		return resp
+	}
+	browser.SetLaunchDefaults(opts)
+	// A browser that was already running keeps its viewport otherwise
+	if cmd.Viewport != nil {
+		if err := browser.SetViewport(cmd.Viewport.Width, cmd.Viewport.Height); err != nil {
+			return errorResponse(cmd.ID, err)
+		}
 	}
 
 	return SuccessResponse(cmd.ID, map[string]bool{"launched": true})
 }
 
 func handleNavigate(cmd *NavigateCommand, browser *BrowserManager) Response {
-	waitUntil := "load"
-	if cmd.WaitUntil != "" {
-		waitUntil = cmd.WaitUntil
+	waitUntil := cmp.Or(cmd.WaitUntil, "load")
+	if !slices.Contains(loadStates, waitUntil) {
+		return ErrorResponse(cmd.ID, fmt.Sprintf("unknown waitUntil %q (expected %s)", waitUntil, strings.Join(loadStates, ", ")))
 	}
 
-	url, title, err := browser.Navigate(cmd.URL, waitUntil)
+	url, title, err := browser.NavigateRequest(NavigationRequest{
+		URL:      cmd.URL,
+		Method:   cmd.Method,
+		Body:     cmd.Body,
+		Headers:  cmd.Headers,
+		Referrer: cmd.Referrer,
+	}, waitUntil)
 	if err != nil {
-		return ErrorResponse(cmd.ID, err.Error())
+		return errorResponse(cmd.ID, err)
 	}
 
-	return SuccessResponse(cmd.ID, NavigateData{URL: url, Title: title})
+	// Detection is best effort; a failed check must not fail the navigation
+	challenge, _ := browser.DetectChallenge()
+
+	return SuccessResponse(cmd.ID, NavigateData{URL: url, Title: title, Challenge: challenge})
 }
 
 func handleClick(cmd *ClickCommand, browser *BrowserManager) Response {
-	if err := browser.Click(cmd.Selector); err != nil {
-		return ErrorResponse(cmd.ID, toAIFriendlyError(err, cmd.Selector))
+	summary, err := observeAction(browser, func() error {
+		return browser.Click(cmd.Selector)
+	})
+	if err != nil {
+		return elementErrorResponse(cmd.ID, err, cmd.Selector, browser)
 	}
-	return SuccessResponse(cmd.ID, nil)
+	return SuccessResponse(cmd.ID, summary)
 }
 
 func handleType(cmd *TypeCommand, browser *BrowserManager) Response {
 	if err := browser.Type(cmd.Selector, cmd.Text, cmd.Delay); err != nil {
-		return ErrorResponse(cmd.ID, toAIFriendlyError(err, cmd.Selector))
+		return elementErrorResponse(cmd.ID, err, cmd.Selector, browser)
 	}
 	return SuccessResponse(cmd.ID, nil)
 }
 
 func handleFill(cmd *FillCommand, browser *BrowserManager) Response {
-	if err := browser.Fill(cmd.Selector, cmd.Value); err != nil {
-		return ErrorResponse(cmd.ID, toAIFriendlyError(err, cmd.Selector))
+	summary, err := observeAction(browser, func() error {
+		return browser.Fill(cmd.Selector, cmd.Value)
+	})
+	if err != nil {
+		return elementErrorResponse(cmd.ID, err, cmd.Selector, browser)
 	}
-	return SuccessResponse(cmd.ID, nil)
+	return SuccessResponse(cmd.ID, summary)
 }
 
 func handleCheck(cmd *CheckCommand, browser *BrowserManager) Response {
 	if err := browser.Check(cmd.Selector); err != nil {
-		return ErrorResponse(cmd.ID, toAIFriendlyError(err, cmd.Selector))
+		return elementErrorResponse(cmd.ID, err, cmd.Selector, browser)
 	}
 	return SuccessResponse(cmd.ID, nil)
 }
 
 func handleUncheck(cmd *UncheckCommand, browser *BrowserManager) Response {
 	if err := browser.Uncheck(cmd.Selector); err != nil {
-		return ErrorResponse(cmd.ID, toAIFriendlyError(err, cmd.Selector))
+		return elementErrorResponse(cmd.ID, err, cmd.Selector, browser)
 	}
 	return SuccessResponse(cmd.ID, nil)
 }
 
 func handlePress(cmd *PressCommand, browser *BrowserManager) Response {
-	if err := browser.Press(cmd.Key, cmd.Selector); err != nil {
-		return ErrorResponse(cmd.ID, err.Error())
+	summary, err := observeAction(browser, func() error {
+		return browser.Press(cmd.Key, cmd.Selector)
+	})
+	if err != nil {
+		return errorResponse(cmd.ID, err)
+	}
+	return SuccessResponse(cmd.ID, summary)
+}
+
+func handleKeyboard(cmd *KeyboardCommand, browser *BrowserManager) Response {
+	summary, err := observeAction(browser, func() error {
+		return browser.Keyboard(cmd.Keys)
+	})
+	if err != nil {
+		return errorResponse(cmd.ID, err)
+	}
+	return SuccessResponse(cmd.ID, summary)
+}
+
+func handleInsertText(cmd *InsertTextCommand, browser *BrowserManager) Response {
+	summary, err := observeAction(browser, func() error {
+		return browser.InsertText(cmd.Selector, cmd.Text)
+	})
+	if err != nil && cmd.Selector != "" {
+		return elementErrorResponse(cmd.ID, err, cmd.Selector, browser)
+	}
+	if err != nil {
+		return errorResponse(cmd.ID, err)
+	}
+	return SuccessResponse(cmd.ID, summary)
+}
+
+// actionSettleTimeout is how long an action's side effects are waited for
+// after it returns. Navigations, dialogs and console errors it triggers are
+// reported by events that can arrive after the action itself completes.
+const actionSettleTimeout = 50 * time.Millisecond
+
+// observeAction runs an action and summarizes its side effects: URL changes,
+// navigations, new console errors and dialogs.
+func observeAction(browser *BrowserManager, action func() error) (ActionSummary, error) {
+	beforeURL, _ := browser.URL()
+	before := browser.Activity()
+
+	if err := action(); err != nil {
+		return ActionSummary{}, err
+	}
+
+	after := browser.WaitActivity(before, actionSettleTimeout)
+	afterURL, _ := browser.URL()
+	return summarizeAction(beforeURL, afterURL, before, after), nil
+}
+
+func handleTap(cmd *TapCommand, browser *BrowserManager) Response {
+	summary, err := observeAction(browser, func() error {
+		return browser.Tap(cmd.Selector)
+	})
+	if err != nil {
+		return elementErrorResponse(cmd.ID, err, cmd.Selector, browser)
+	}
+	return SuccessResponse(cmd.ID, summary)
+}
+
+func handleInputMouse(cmd *InputMouseCommand, browser *BrowserManager) Response {
+	err := browser.InputMouse(MouseEvent{
+		Type:       cmd.Type,
+		X:          float64(cmd.X),
+		Y:          float64(cmd.Y),
+		Button:     cmd.Button,
+		ClickCount: cmd.ClickCount,
+		DeltaX:     float64(cmd.DeltaX),
+		DeltaY:     float64(cmd.DeltaY),
+		Modifiers:  cmd.Modifiers,
+	})
+	if err != nil {
+		return errorResponse(cmd.ID, err)
+	}
+	return SuccessResponse(cmd.ID, nil)
+}
+
+func handleMouseMove(cmd *MouseMoveCommand, browser *BrowserManager) Response {
+	if err := browser.MouseMove(float64(cmd.X), float64(cmd.Y), cmd.Steps); err != nil {
+		return errorResponse(cmd.ID, err)
+	}
+	return SuccessResponse(cmd.ID, nil)
+}
+
+func handleMouseDown(cmd *MouseDownCommand, browser *BrowserManager) Response {
+	if err := browser.MouseDown(cmd.Button); err != nil {
+		return errorResponse(cmd.ID, err)
+	}
+	return SuccessResponse(cmd.ID, nil)
+}
+
+func handleMouseUp(cmd *MouseUpCommand, browser *BrowserManager) Response {
+	summary, err := observeAction(browser, func() error {
+		return browser.MouseUp(cmd.Button)
+	})
+	if err != nil {
+		return errorResponse(cmd.ID, err)
+	}
+	return SuccessResponse(cmd.ID, summary)
+}
+
+func handleWheel(cmd *WheelCommand, browser *BrowserManager) Response {
+	err := browser.Wheel(float64(cmd.DeltaX), float64(cmd.DeltaY), cmd.Selector)
+	if err != nil && cmd.Selector != "" {
+		return elementErrorResponse(cmd.ID, err, cmd.Selector, browser)
+	}
+	if err != nil {
+		return errorResponse(cmd.ID, err)
+	}
+	return SuccessResponse(cmd.ID, nil)
+}
+
+func handleInputKeyboard(cmd *InputKeyboardCommand, browser *BrowserManager) Response {
+	err := browser.InputKeyboard(KeyEvent{
+		Type:      cmd.Type,
+		Key:       cmd.Key,
+		Code:      cmd.Code,
+		Text:      cmd.Text,
+		Modifiers: cmd.Modifiers,
+	})
+	if err != nil {
+		return errorResponse(cmd.ID, err)
+	}
+	return SuccessResponse(cmd.ID, nil)
+}
+
+func handleKeyDown(cmd *KeyDownCommand, browser *BrowserManager) Response {
+	if err := browser.InputKeyboard(KeyEvent{Type: "keyDown", Key: cmd.Key}); err != nil {
+		return errorResponse(cmd.ID, err)
+	}
+	return SuccessResponse(cmd.ID, nil)
+}
+
+func handleKeyUp(cmd *KeyUpCommand, browser *BrowserManager) Response {
+	if err := browser.InputKeyboard(KeyEvent{Type: "keyUp", Key: cmd.Key}); err != nil {
+		return errorResponse(cmd.ID, err)
+	}
+	return SuccessResponse(cmd.ID, nil)
+}
+
+func handleClipboard(cmd *ClipboardCommand, browser *BrowserManager) Response {
+	switch cmd.Operation {
+	case "copy":
+		if err := browser.ClipboardCopy(cmd.Text); err != nil {
+			return errorResponse(cmd.ID, err)
+		}
+		return SuccessResponse(cmd.ID, nil)
+	case "read":
+		text, err := browser.ClipboardRead()
+		if err != nil {
+			return errorResponse(cmd.ID, err)
+		}
+		return SuccessResponse(cmd.ID, ClipboardData{Text: text})
+	case "paste":
+		if cmd.Selector == "" {
+			return ErrorResponse(cmd.ID, "paste requires a selector")
+		}
+		text, err := browser.ClipboardPaste(cmd.Selector)
+		if err != nil {
+			return elementErrorResponse(cmd.ID, err, cmd.Selector, browser)
+		}
+		return SuccessResponse(cmd.ID, ClipboardData{Text: text})
+	default:
+		return ErrorResponse(cmd.ID, fmt.Sprintf("unknown clipboard operation %q (expected copy, paste or read)", cmd.Operation))
+	}
+}
+
+func handleHighlight(cmd *HighlightCommand, browser *BrowserManager) Response {
+	if err := browser.Highlight(cmd.Selector, cmd.Duration); err != nil {
+		return elementErrorResponse(cmd.ID, err, cmd.Selector, browser)
+	}
+	return SuccessResponse(cmd.ID, nil)
+}
+
+func handleSelectAll(cmd *SelectAllCommand, browser *BrowserManager) Response {
+	if err := browser.SelectAll(cmd.Selector); err != nil {
+		return elementErrorResponse(cmd.ID, err, cmd.Selector, browser)
+	}
+	return SuccessResponse(cmd.ID, nil)
+}
+
+func handleDispatchEvent(cmd *DispatchEventCommand, browser *BrowserManager) Response {
+	if err := browser.DispatchEvent(cmd.Selector, cmd.Event, cmd.EventInit); err != nil {
+		return elementErrorResponse(cmd.ID, err, cmd.Selector, browser)
+	}
+	return SuccessResponse(cmd.ID, nil)
+}
+
+func handleAddScript(cmd *AddScriptCommand, browser *BrowserManager) Response {
+	if err := browser.AddScriptTag(cmd.URL, cmd.Content); err != nil {
+		return errorResponse(cmd.ID, err)
+	}
+	return SuccessResponse(cmd.ID, nil)
+}
+
+func handleAddStyle(cmd *AddStyleCommand, browser *BrowserManager) Response {
+	if err := browser.AddStyleTag(cmd.URL, cmd.Content); err != nil {
+		return errorResponse(cmd.ID, err)
+	}
+	return SuccessResponse(cmd.ID, nil)
+}
+
+func handleAddInitScript(cmd *AddInitScriptCommand, browser *BrowserManager) Response {
+	if err := browser.AddInitScript(cmd.Script); err != nil {
+		return errorResponse(cmd.ID, err)
 	}
 	return SuccessResponse(cmd.ID, nil)
 }
 
 func handleHover(cmd *HoverCommand, browser *BrowserManager) Response {
 	if err := browser.Hover(cmd.Selector); err != nil {
-		return ErrorResponse(cmd.ID, toAIFriendlyError(err, cmd.Selector))
+		return elementErrorResponse(cmd.ID, err, cmd.Selector, browser)
 	}
 	return SuccessResponse(cmd.ID, nil)
 }
 
 func handleFocus(cmd *FocusCommand, browser *BrowserManager) Response {
 	if err := browser.Focus(cmd.Selector); err != nil {
-		return ErrorResponse(cmd.ID, toAIFriendlyError(err, cmd.Selector))
+		return elementErrorResponse(cmd.ID, err, cmd.Selector, browser)
 	}
 	return SuccessResponse(cmd.ID, nil)
 }
 
 func handleClear(cmd *ClearCommand, browser *BrowserManager) Response {
 	if err := browser.Clear(cmd.Selector); err != nil {
-		return ErrorResponse(cmd.ID, toAIFriendlyError(err, cmd.Selector))
+		return elementErrorResponse(cmd.ID, err, cmd.Selector, browser)
 	}
 	return SuccessResponse(cmd.ID, nil)
 }
 
 func handleSelect(cmd *SelectCommand, browser *BrowserManager) Response {
-	if err := browser.Select(cmd.Selector, cmd.Values); err != nil {
-		return ErrorResponse(cmd.ID, toAIFriendlyError(err, cmd.Selector))
+	if err := browser.Select(cmd.Selector, cmd.Values, cmd.By); err != nil {
+		return elementErrorResponse(cmd.ID, err, cmd.Selector, browser)
 	}
 	return SuccessResponse(cmd.ID, nil)
 }
 
+func handleUpload(cmd *UploadCommand, browser *BrowserManager) Response {
+	if err := browser.Upload(cmd.Selector, cmd.Files); err != nil {
+		return elementErrorResponse(cmd.ID, err, cmd.Selector, browser)
+	}
+	return SuccessResponse(cmd.ID, map[string]interface{}{"uploaded": len(cmd.Files)})
+}
+
 func handleDoubleClick(cmd *DoubleClickCommand, browser *BrowserManager) Response {
 	if err := browser.DoubleClick(cmd.Selector); err != nil {
-		return ErrorResponse(cmd.ID, toAIFriendlyError(err, cmd.Selector))
+		return elementErrorResponse(cmd.ID, err, cmd.Selector, browser)
 	}
 	return SuccessResponse(cmd.ID, nil)
 }
@@ -222,7 +696,7 @@ func handleScreenshot(cmd *ScreenshotCommand, browser *BrowserManager) Response
 
 	buf, err := browser.Screenshot(cmd.FullPage, cmd.Selector, quality)
 	if err != nil {
-		return ErrorResponse(cmd.ID, err.Error())
+		return errorResponse(cmd.ID, err)
 	}
 
 	if cmd.Path != "" {
@@ -235,6 +709,38 @@ func handleScreenshot(cmd *ScreenshotCommand, browser *BrowserManager) Response
 	return SuccessResponse(cmd.ID, ScreenshotData{Base64: base64.StdEncoding.EncodeToString(buf)})
 }
 
+func handlePdf(cmd *PdfCommand, browser *BrowserManager) Response {
+	buf, err := browser.PDF(cmd.PDFOptions)
+	if err != nil {
+		return errorResponse(cmd.ID, err)
+	}
+
+	if cmd.Path != "" {
+		if err := os.WriteFile(cmd.Path, buf, 0644); err != nil {
+			return ErrorResponse(cmd.ID, fmt.Sprintf("failed to save PDF: %v", err))
+		}
+		return SuccessResponse(cmd.ID, PdfData{Path: cmd.Path, Bytes: len(buf)})
+	}
+
+	return SuccessResponse(cmd.ID, PdfData{Base64: base64.StdEncoding.EncodeToString(buf), Bytes: len(buf)})
+}
+
+func handleTraceStart(cmd *TraceStartCommand, browser *BrowserManager) Response {
+	opts := TraceOptions{Screenshots: cmd.Screenshots, Snapshots: cmd.Snapshots}
+	if err := browser.StartTracing(opts); err != nil {
+		return errorResponse(cmd.ID, err)
+	}
+	return SuccessResponse(cmd.ID, nil)
+}
+
+func handleTraceStop(cmd *TraceStopCommand, browser *BrowserManager) Response {
+	trace, err := browser.StopTracing(cmd.Path)
+	if err != nil {
+		return errorResponse(cmd.ID, err)
+	}
+	return SuccessResponse(cmd.ID, trace)
+}
+
 func handleSnapshot(cmd *SnapshotCommand, browser *BrowserManager) Response {
 	opts := SnapshotOptions{
 		Interactive: cmd.Interactive,
@@ -245,7 +751,16 @@ func handleSnapshot(cmd *SnapshotCommand, browser *BrowserManager) Response {
 
 	snapshot, err := browser.GetSnapshot(opts)
 	if err != nil {
-		return ErrorResponse(cmd.ID, err.Error())
+		return errorResponse(cmd.ID, err)
+	}
+
+	tree, err := EncodeSnapshot(snapshot.Tree, cmd.Format, cmd.NoIndent)
+	if err != nil {
+		return errorResponse(cmd.ID, err)
+	}
+	if cmd.Format == SnapshotFormatCompactV2 {
+		// Roles and names are inline, so the refs map would only duplicate them
+		return SuccessResponse(cmd.ID, SnapshotData{Snapshot: tree})
 	}
 
 	// Convert refs to the expected format
@@ -254,13 +769,13 @@ func handleSnapshot(cmd *SnapshotCommand, browser *BrowserManager) Response {
 		refsData[k] = RefInfo{Role: v.Role, Name: v.Name}
 	}
 
-	return SuccessResponse(cmd.ID, SnapshotData{Snapshot: snapshot.Tree, Refs: refsData})
+	return SuccessResponse(cmd.ID, SnapshotData{Snapshot: tree, Refs: refsData})
 }
 
 func handleEvaluate(cmd *EvaluateCommand, browser *BrowserManager) Response {
 	result, err := browser.Evaluate(cmd.Script)
 	if err != nil {
-		return ErrorResponse(cmd.ID, err.Error())
+		return errorResponse(cmd.ID, err)
 	}
 	return SuccessResponse(cmd.ID, EvaluateData{Result: result})
 }
@@ -268,31 +783,42 @@ func handleEvaluate(cmd *EvaluateCommand, browser *BrowserManager) Response {
 func handleWait(cmd *WaitCommand, browser *BrowserManager) Response {
 	if cmd.Selector != "" {
 		if err := browser.Wait(cmd.Selector, cmd.Timeout, cmd.State); err != nil {
-			return ErrorResponse(cmd.ID, toAIFriendlyError(err, cmd.Selector))
+			return elementErrorResponse(cmd.ID, err, cmd.Selector, browser)
 		}
 	} else if cmd.Timeout > 0 {
 		if err := browser.WaitForTimeout(cmd.Timeout); err != nil {
-			return ErrorResponse(cmd.ID, err.Error())
+			return errorResponse(cmd.ID, err)
 		}
 	}
 	return SuccessResponse(cmd.ID, nil)
 }
 
 func handleScroll(cmd *ScrollCommand, browser *BrowserManager) Response {
+	if cmd.ToEnd {
+		result, err := browser.ScrollToEnd(ScrollToEndOptions{
+			MaxRounds:     cmd.MaxRounds,
+			UntilSelector: cmd.UntilSelector,
+		})
+		if err != nil {
+			return errorResponse(cmd.ID, err)
+		}
+		return SuccessResponse(cmd.ID, result)
+	}
+
 	amount := 100
 	if cmd.Amount > 0 {
 		amount = cmd.Amount
 	}
 
 	if err := browser.Scroll(cmd.Direction, amount); err != nil {
-		return ErrorResponse(cmd.ID, err.Error())
+		return errorResponse(cmd.ID, err)
 	}
 	return SuccessResponse(cmd.ID, nil)
 }
 
 func handleScrollIntoView(cmd *ScrollIntoViewCommand, browser *BrowserManager) Response {
 	if err := browser.ScrollIntoView(cmd.Selector); err != nil {
-		return ErrorResponse(cmd.ID, toAIFriendlyError(err, cmd.Selector))
+		return elementErrorResponse(cmd.ID, err, cmd.Selector, browser)
 	}
 	return SuccessResponse(cmd.ID, nil)
 }
@@ -301,21 +827,21 @@ func handleContent(cmd *ContentCommand, browser *BrowserManager) Response {
 	if cmd.Selector != "" {
 		html, err := browser.GetHTML(cmd.Selector, true)
 		if err != nil {
-			return ErrorResponse(cmd.ID, toAIFriendlyError(err, cmd.Selector))
+			return elementErrorResponse(cmd.ID, err, cmd.Selector, browser)
 		}
 		return SuccessResponse(cmd.ID, ContentData{HTML: html})
 	}
 
 	html, err := browser.Content()
 	if err != nil {
-		return ErrorResponse(cmd.ID, err.Error())
+		return errorResponse(cmd.ID, err)
 	}
 	return SuccessResponse(cmd.ID, ContentData{HTML: html})
 }
 
 func handleSetContent(cmd *SetContentCommand, browser *BrowserManager) Response {
 	if err := browser.SetContent(cmd.HTML); err != nil {
-		return ErrorResponse(cmd.ID, err.Error())
+		return errorResponse(cmd.ID, err)
 	}
 	return SuccessResponse(cmd.ID, nil)
 }
@@ -323,7 +849,7 @@ func handleSetContent(cmd *SetContentCommand, browser *BrowserManager) Response
 func handleGetText(cmd *GetTextCommand, browser *BrowserManager) Response {
 	text, err := browser.GetText(cmd.Selector)
 	if err != nil {
-		return ErrorResponse(cmd.ID, toAIFriendlyError(err, cmd.Selector))
+		return elementErrorResponse(cmd.ID, err, cmd.Selector, browser)
 	}
 	return SuccessResponse(cmd.ID, map[string]string{"text": text})
 }
@@ -331,7 +857,7 @@ func handleGetText(cmd *GetTextCommand, browser *BrowserManager) Response {
 func handleGetAttribute(cmd *GetAttributeCommand, browser *BrowserManager) Response {
 	value, err := browser.GetAttribute(cmd.Selector, cmd.Attribute)
 	if err != nil {
-		return ErrorResponse(cmd.ID, toAIFriendlyError(err, cmd.Selector))
+		return elementErrorResponse(cmd.ID, err, cmd.Selector, browser)
 	}
 	return SuccessResponse(cmd.ID, map[string]string{"value": value})
 }
@@ -339,7 +865,7 @@ func handleGetAttribute(cmd *GetAttributeCommand, browser *BrowserManager) Respo
 func handleInnerHTML(cmd *InnerHTMLCommand, browser *BrowserManager) Response {
 	html, err := browser.GetHTML(cmd.Selector, false)
 	if err != nil {
-		return ErrorResponse(cmd.ID, toAIFriendlyError(err, cmd.Selector))
+		return elementErrorResponse(cmd.ID, err, cmd.Selector, browser)
 	}
 	return SuccessResponse(cmd.ID, map[string]string{"html": html})
 }
@@ -347,7 +873,7 @@ func handleInnerHTML(cmd *InnerHTMLCommand, browser *BrowserManager) Response {
 func handleInnerText(cmd *InnerTextCommand, browser *BrowserManager) Response {
 	text, err := browser.GetText(cmd.Selector)
 	if err != nil {
-		return ErrorResponse(cmd.ID, toAIFriendlyError(err, cmd.Selector))
+		return elementErrorResponse(cmd.ID, err, cmd.Selector, browser)
 	}
 	return SuccessResponse(cmd.ID, map[string]string{"text": text})
 }
@@ -355,14 +881,14 @@ func handleInnerText(cmd *InnerTextCommand, browser *BrowserManager) Response {
 func handleInputValue(cmd *InputValueCommand, browser *BrowserManager) Response {
 	value, err := browser.GetInputValue(cmd.Selector)
 	if err != nil {
-		return ErrorResponse(cmd.ID, toAIFriendlyError(err, cmd.Selector))
+		return elementErrorResponse(cmd.ID, err, cmd.Selector, browser)
 	}
 	return SuccessResponse(cmd.ID, map[string]string{"value": value})
 }
 
 func handleSetValue(cmd *SetValueCommand, browser *BrowserManager) Response {
 	if err := browser.SetValue(cmd.Selector, cmd.Value); err != nil {
-		return ErrorResponse(cmd.ID, toAIFriendlyError(err, cmd.Selector))
+		return elementErrorResponse(cmd.ID, err, cmd.Selector, browser)
 	}
 	return SuccessResponse(cmd.ID, nil)
 }
@@ -370,7 +896,7 @@ func handleSetValue(cmd *SetValueCommand, browser *BrowserManager) Response {
 func handleIsVisible(cmd *IsVisibleCommand, browser *BrowserManager) Response {
 	visible, err := browser.IsVisible(cmd.Selector)
 	if err != nil {
-		return ErrorResponse(cmd.ID, toAIFriendlyError(err, cmd.Selector))
+		return elementErrorResponse(cmd.ID, err, cmd.Selector, browser)
 	}
 	return SuccessResponse(cmd.ID, map[string]bool{"visible": visible})
 }
@@ -378,7 +904,7 @@ func handleIsVisible(cmd *IsVisibleCommand, browser *BrowserManager) Response {
 func handleIsEnabled(cmd *IsEnabledCommand, browser *BrowserManager) Response {
 	enabled, err := browser.IsEnabled(cmd.Selector)
 	if err != nil {
-		return ErrorResponse(cmd.ID, toAIFriendlyError(err, cmd.Selector))
+		return elementErrorResponse(cmd.ID, err, cmd.Selector, browser)
 	}
 	return SuccessResponse(cmd.ID, map[string]bool{"enabled": enabled})
 }
@@ -386,7 +912,7 @@ func handleIsEnabled(cmd *IsEnabledCommand, browser *BrowserManager) Response {
 func handleIsChecked(cmd *IsCheckedCommand, browser *BrowserManager) Response {
 	checked, err := browser.IsChecked(cmd.Selector)
 	if err != nil {
-		return ErrorResponse(cmd.ID, toAIFriendlyError(err, cmd.Selector))
+		return elementErrorResponse(cmd.ID, err, cmd.Selector, browser)
 	}
 	return SuccessResponse(cmd.ID, map[string]bool{"checked": checked})
 }
@@ -394,7 +920,7 @@ func handleIsChecked(cmd *IsCheckedCommand, browser *BrowserManager) Response {
 func handleCount(cmd *CountCommand, browser *BrowserManager) Response {
 	count, err := browser.Count(cmd.Selector)
 	if err != nil {
-		return ErrorResponse(cmd.ID, err.Error())
+		return errorResponse(cmd.ID, err)
 	}
 	return SuccessResponse(cmd.ID, map[string]int{"count": count})
 }
@@ -402,51 +928,130 @@ func handleCount(cmd *CountCommand, browser *BrowserManager) Response {
 func handleBoundingBox(cmd *BoundingBoxCommand, browser *BrowserManager) Response {
 	box, err := browser.GetBoundingBox(cmd.Selector)
 	if err != nil {
-		return ErrorResponse(cmd.ID, toAIFriendlyError(err, cmd.Selector))
+		return elementErrorResponse(cmd.ID, err, cmd.Selector, browser)
 	}
 	return SuccessResponse(cmd.ID, box)
 }
 
+func handleDescribe(cmd *DescribeCommand, browser *BrowserManager) Response {
+	maxText := defaultDescribeTextLength
+	if cmd.MaxText > 0 {
+		maxText = cmd.MaxText
+	}
+
+	desc, err := browser.Describe(cmd.Selector, maxText)
+	if err != nil {
+		return elementErrorResponse(cmd.ID, err, cmd.Selector, browser)
+	}
+	return SuccessResponse(cmd.ID, desc)
+}
+
+func handleText(cmd *TextCommand, browser *BrowserManager) Response {
+	text, err := browser.ReadableText(cmd.Selector, cmd.Plain)
+	if err != nil {
+		if cmd.Selector == "" {
+			return errorResponse(cmd.ID, err)
+		}
+		return elementErrorResponse(cmd.ID, err, cmd.Selector, browser)
+	}
+	return SuccessResponse(cmd.ID, TextData{Text: text})
+}
+
+func handleLinks(cmd *LinksCommand, browser *BrowserManager) Response {
+	links, err := browser.Links(cmd.Selector)
+	if err != nil {
+		if cmd.Selector == "" {
+			return errorResponse(cmd.ID, err)
+		}
+		return elementErrorResponse(cmd.ID, err, cmd.Selector, browser)
+	}
+	return SuccessResponse(cmd.ID, LinksData{Links: links})
+}
+
+func handleForms(cmd *FormsCommand, browser *BrowserManager) Response {
+	forms, err := browser.Forms()
+	if err != nil {
+		return errorResponse(cmd.ID, err)
+	}
+	return SuccessResponse(cmd.ID, FormsData{Forms: forms})
+}
+
+func handleFillForm(cmd *FillFormCommand, browser *BrowserManager) Response {
+	if len(cmd.Fields) == 0 {
+		return ErrorResponse(cmd.ID, "fields is required")
+	}
+	result := browser.FillForm(cmd.Fields)
+	if result.Filled == len(cmd.Fields) {
+		return SuccessResponse(cmd.ID, result)
+	}
+
+	var failures []string
+	for _, f := range result.Fields {
+		if f.Error != "" {
+			failures = append(failures, fmt.Sprintf("%s: %s", f.Field, f.Error))
+		}
+	}
+	resp := ErrorResponse(cmd.ID, fmt.Sprintf("%d of %d fields failed: %s",
+		len(failures), len(cmd.Fields), strings.Join(failures, "; ")))
+	resp.Data, _ = json.Marshal(result)
+	return resp
+}
+
 func handleURL(cmd *URLCommand, browser *BrowserManager) Response {
 	url, err := browser.URL()
 	if err != nil {
-		return ErrorResponse(cmd.ID, err.Error())
+		return errorResponse(cmd.ID, err)
 	}
 	return SuccessResponse(cmd.ID, map[string]string{"url": url})
 }
 
+func handleWaitForURL(cmd *WaitForURLCommand, browser *BrowserManager) Response {
+	url, err := browser.WaitForURL(cmd.URL, cmd.Timeout)
+	if err != nil {
+		return errorResponse(cmd.ID, err)
+	}
+	return SuccessResponse(cmd.ID, map[string]string{"url": url})
+}
+
+func handleWaitForLoadState(cmd *WaitForLoadStateCommand, browser *BrowserManager) Response {
+	if err := browser.WaitForLoadState(cmd.State, cmd.Timeout); err != nil {
+		return errorResponse(cmd.ID, err)
+	}
+	return SuccessResponse(cmd.ID, nil)
+}
+
 func handleTitle(cmd *TitleCommand, browser *BrowserManager) Response {
 	title, err := browser.Title()
 	if err != nil {
-		return ErrorResponse(cmd.ID, err.Error())
+		return errorResponse(cmd.ID, err)
 	}
 	return SuccessResponse(cmd.ID, map[string]string{"title": title})
 }
 
 func handleBack(cmd *BackCommand, browser *BrowserManager) Response {
 	if err := browser.Back(); err != nil {
-		return ErrorResponse(cmd.ID, err.Error())
+		return errorResponse(cmd.ID, err)
 	}
 	return SuccessResponse(cmd.ID, nil)
 }
 
 func handleForward(cmd *ForwardCommand, browser *BrowserManager) Response {
 	if err := browser.Forward(); err != nil {
-		return ErrorResponse(cmd.ID, err.Error())
+		return errorResponse(cmd.ID, err)
 	}
 	return SuccessResponse(cmd.ID, nil)
 }
 
 func handleReload(cmd *ReloadCommand, browser *BrowserManager) Response {
 	if err := browser.Reload(); err != nil {
-		return ErrorResponse(cmd.ID, err.Error())
+		return errorResponse(cmd.ID, err)
 	}
 	return SuccessResponse(cmd.ID, nil)
 }
 
 func handleViewport(cmd *ViewportCommand, browser *BrowserManager) Response {
 	if err := browser.SetViewport(cmd.Width, cmd.Height); err != nil {
-		return ErrorResponse(cmd.ID, err.Error())
+		return errorResponse(cmd.ID, err)
 	}
 	return SuccessResponse(cmd.ID, nil)
 }
@@ -454,16 +1059,49 @@ func handleViewport(cmd *ViewportCommand, browser *BrowserManager) Response {
 func handleTabNew(cmd *TabNewCommand, browser *BrowserManager) Response {
 	index, err := browser.NewTab(cmd.URL)
 	if err != nil {
-		return ErrorResponse(cmd.ID, err.Error())
+		return errorResponse(cmd.ID, err)
 	}
 	tabs, _ := browser.ListTabs()
 	return SuccessResponse(cmd.ID, TabNewData{Index: index, Total: len(tabs)})
 }
 
+func handleWindowNew(cmd *WindowNewCommand, browser *BrowserManager) Response {
+	index, err := browser.NewWindow(cmd.Viewport)
+	if err != nil {
+		return errorResponse(cmd.ID, err)
+	}
+	data := TabNewData{Index: index}
+	tabs, _ := browser.ListTabs()
+	data.Total = len(tabs)
+	if index < len(tabs) {
+		data.Window = tabs[index].Window
+	}
+	return SuccessResponse(cmd.ID, data)
+}
+
+func handleHistory(cmd *HistoryCommand, browser *BrowserManager) Response {
+	history, err := browser.History()
+	if err != nil {
+		return errorResponse(cmd.ID, err)
+	}
+	return SuccessResponse(cmd.ID, history)
+}
+
+func handleHistoryGo(cmd *HistoryGoCommand, browser *BrowserManager) Response {
+	if err := browser.HistoryGo(cmd.Index); err != nil {
+		return errorResponse(cmd.ID, err)
+	}
+
+	url, _ := browser.URL()
+	title, _ := browser.Title()
+
+	return SuccessResponse(cmd.ID, NavigateData{URL: url, Title: title})
+}
+
 func handleTabList(cmd *TabListCommand, browser *BrowserManager) Response {
 	tabs, err := browser.ListTabs()
 	if err != nil {
-		return ErrorResponse(cmd.ID, err.Error())
+		return errorResponse(cmd.ID, err)
 	}
 
 	active := 0
@@ -477,9 +1115,16 @@ func handleTabList(cmd *TabListCommand, browser *BrowserManager) Response {
 	return SuccessResponse(cmd.ID, TabListData{Tabs: tabs, Active: active})
 }
 
+func handleBringToFront(cmd *BringToFrontCommand, browser *BrowserManager) Response {
+	if err := browser.BringToFront(); err != nil {
+		return errorResponse(cmd.ID, err)
+	}
+	return SuccessResponse(cmd.ID, nil)
+}
+
 func handleTabSwitch(cmd *TabSwitchCommand, browser *BrowserManager) Response {
 	if err := browser.SwitchTab(cmd.Index); err != nil {
-		return ErrorResponse(cmd.ID, err.Error())
+		return errorResponse(cmd.ID, err)
 	}
 
 	url, _ := browser.URL()
@@ -488,6 +1133,102 @@ func handleTabSwitch(cmd *TabSwitchCommand, browser *BrowserManager) Response {
 	return SuccessResponse(cmd.ID, TabSwitchData{Index: cmd.Index, URL: url, Title: title})
 }
 
+func handleFrame(cmd *FrameCommand, browser *BrowserManager) Response {
+	ref := FrameRef{Selector: cmd.Selector, Name: cmd.Name, URL: cmd.URL}
+	if err := browser.SwitchFrame(ref); err != nil {
+		return errorResponse(cmd.ID, err)
+	}
+	return SuccessResponse(cmd.ID, nil)
+}
+
+func handleMainFrame(cmd *MainFrameCommand, browser *BrowserManager) Response {
+	if err := browser.MainFrame(); err != nil {
+		return errorResponse(cmd.ID, err)
+	}
+	return SuccessResponse(cmd.ID, nil)
+}
+
+func handleGetByRole(cmd *GetByRoleCommand, browser *BrowserManager) Response {
+	loc := Locator{By: "role", Role: cmd.Role, Text: cmd.Name, Exact: cmd.Exact}
+	return handleLocatorAction(cmd.ID, loc, cmd.SubAction, cmd.Value, browser)
+}
+
+func handleGetByText(cmd *GetByTextCommand, browser *BrowserManager) Response {
+	loc := Locator{By: "text", Text: cmd.Text, Exact: cmd.Exact}
+	return handleLocatorAction(cmd.ID, loc, cmd.SubAction, cmd.Value, browser)
+}
+
+func handleGetByLabel(cmd *GetByLabelCommand, browser *BrowserManager) Response {
+	loc := Locator{By: "label", Text: cmd.Label, Exact: cmd.Exact}
+	return handleLocatorAction(cmd.ID, loc, cmd.SubAction, cmd.Value, browser)
+}
+
+func handleGetByPlaceholder(cmd *GetByPlaceholderCommand, browser *BrowserManager) Response {
+	loc := Locator{By: "placeholder", Text: cmd.Placeholder, Exact: cmd.Exact}
+	return handleLocatorAction(cmd.ID, loc, cmd.SubAction, cmd.Value, browser)
+}
+
+func handleGetByAltText(cmd *GetByAltTextCommand, browser *BrowserManager) Response {
+	loc := Locator{By: "alt", Text: cmd.Text, Exact: cmd.Exact}
+	return handleLocatorAction(cmd.ID, loc, cmd.SubAction, cmd.Value, browser)
+}
+
+func handleGetByTitle(cmd *GetByTitleCommand, browser *BrowserManager) Response {
+	loc := Locator{By: "title", Text: cmd.Text, Exact: cmd.Exact}
+	return handleLocatorAction(cmd.ID, loc, cmd.SubAction, cmd.Value, browser)
+}
+
+func handleGetByTestId(cmd *GetByTestIdCommand, browser *BrowserManager) Response {
+	loc := Locator{By: "testid", Text: cmd.TestID}
+	return handleLocatorAction(cmd.ID, loc, cmd.SubAction, cmd.Value, browser)
+}
+
+// locatorSubActions are the actions the getby* commands run on the element
+// they find.
+var locatorSubActions = map[string]bool{
+	"click": true, "dblclick": true, "fill": true, "type": true, "check": true,
+	"uncheck": true, "hover": true, "focus": true, "text": true, "describe": true,
+}
+
+// handleLocatorAction finds the element loc matches and runs subAction on
+// it as the matching command would, describing it when subAction is empty.
+func handleLocatorAction(id string, loc Locator, subAction, value string, browser *BrowserManager) Response {
+	if subAction == "" {
+		subAction = "describe"
+	}
+	if !locatorSubActions[subAction] {
+		return ErrorResponse(id, fmt.Sprintf("unknown subaction %q (expected click, dblclick, fill, type, check, uncheck, hover, focus, text or describe)", subAction))
+	}
+	selector, err := browser.Locate(loc)
+	if err != nil {
+		return elementErrorResponse(id, err, loc.String(), browser)
+	}
+
+	base := BaseCommand{ID: id, Action: subAction}
+	switch subAction {
+	case "click":
+		return handleClick(&ClickCommand{BaseCommand: base, Selector: selector}, browser)
+	case "dblclick":
+		return handleDoubleClick(&DoubleClickCommand{BaseCommand: base, Selector: selector}, browser)
+	case "fill":
+		return handleFill(&FillCommand{BaseCommand: base, Selector: selector, Value: value}, browser)
+	case "type":
+		return handleType(&TypeCommand{BaseCommand: base, Selector: selector, Text: value}, browser)
+	case "check":
+		return handleCheck(&CheckCommand{BaseCommand: base, Selector: selector}, browser)
+	case "uncheck":
+		return handleUncheck(&UncheckCommand{BaseCommand: base, Selector: selector}, browser)
+	case "hover":
+		return handleHover(&HoverCommand{BaseCommand: base, Selector: selector}, browser)
+	case "focus":
+		return handleFocus(&FocusCommand{BaseCommand: base, Selector: selector}, browser)
+	case "text":
+		return handleGetText(&GetTextCommand{BaseCommand: base, Selector: selector}, browser)
+	default:
+		return handleDescribe(&DescribeCommand{BaseCommand: base, Selector: selector}, browser)
+	}
+}
+
 func handleTabClose(cmd *TabCloseCommand, browser *BrowserManager) Response {
 	// Get active tab index from ListTabs
 	tabs, _ := browser.ListTabs()
@@ -504,7 +1245,7 @@ func handleTabClose(cmd *TabCloseCommand, browser *BrowserManager) Response {
 	}
 
 	if err := browser.CloseTab(index); err != nil {
-		return ErrorResponse(cmd.ID, err.Error())
+		return errorResponse(cmd.ID, err)
 	}
 
 	tabs, _ = browser.ListTabs()
@@ -513,13 +1254,315 @@ func handleTabClose(cmd *TabCloseCommand, browser *BrowserManager) Response {
 
 func handleClose(cmd *CloseCommand, browser *BrowserManager) Response {
 	if err := browser.Close(); err != nil {
-		return ErrorResponse(cmd.ID, err.Error())
+		return errorResponse(cmd.ID, err)
 	}
 	return SuccessResponse(cmd.ID, map[string]bool{"closed": true})
 }
 
+func handleWatchStart(cmd *WatchStartCommand, browser *BrowserManager) Response {
+	if err := browser.StartWatch(WatchOptions{Selector: cmd.Selector, Debounce: cmd.Debounce}); err != nil {
+		return errorResponse(cmd.ID, err)
+	}
+	return SuccessResponse(cmd.ID, map[string]bool{"watching": true})
+}
+
+func handleWatchStop(cmd *WatchStopCommand, browser *BrowserManager) Response {
+	if err := browser.StopWatch(); err != nil {
+		return errorResponse(cmd.ID, err)
+	}
+	return SuccessResponse(cmd.ID, map[string]bool{"watching": false})
+}
+
+func handleScreencastStart(cmd *ScreencastStartCommand, browser *BrowserManager) Response {
+	screencast, err := browser.StartScreencast(ScreencastOptions{
+		Format:        cmd.Format,
+		Quality:       cmd.Quality,
+		MaxWidth:      cmd.MaxWidth,
+		MaxHeight:     cmd.MaxHeight,
+		EveryNthFrame: cmd.EveryNthFrame,
+		Dir:           cmd.Dir,
+	})
+	if err != nil {
+		return errorResponse(cmd.ID, err)
+	}
+	return SuccessResponse(cmd.ID, screencast)
+}
+
+func handleScreencastStop(cmd *ScreencastStopCommand, browser *BrowserManager) Response {
+	screencast, err := browser.StopScreencast()
+	if err != nil {
+		return errorResponse(cmd.ID, err)
+	}
+	return SuccessResponse(cmd.ID, screencast)
+}
+
+func handleRecordStart(cmd *RecordStartCommand, browser *BrowserManager) Response {
+	recording, err := browser.StartRecording(RecordOptions{Path: cmd.Path, Format: cmd.Format})
+	if err != nil {
+		return errorResponse(cmd.ID, err)
+	}
+	return SuccessResponse(cmd.ID, recording)
+}
+
+func handleRecordStop(cmd *RecordStopCommand, browser *BrowserManager) Response {
+	recording, err := browser.StopRecording()
+	if err != nil {
+		return errorResponse(cmd.ID, err)
+	}
+	return SuccessResponse(cmd.ID, recording)
+}
+
+func handleRequests(cmd *RequestsCommand, browser *BrowserManager) Response {
+	requests := browser.Requests(cmd.Filter)
+	if cmd.Clear {
+		browser.ClearRequests()
+	}
+	if requests == nil {
+		requests = []TrackedRequest{}
+	}
+	return SuccessResponse(cmd.ID, RequestsData{Requests: requests})
+}
+
+func handleConsole(cmd *ConsoleCommand, browser *BrowserManager) Response {
+	messages := browser.ConsoleMessages()
+	if cmd.Clear {
+		browser.ClearConsole()
+	}
+	if messages == nil {
+		messages = []ConsoleMessage{}
+	}
+	return SuccessResponse(cmd.ID, ConsoleData{Messages: messages})
+}
+
+func handleErrors(cmd *ErrorsCommand, browser *BrowserManager) Response {
+	pageErrors := browser.PageErrors()
+	if cmd.Clear {
+		browser.ClearPageErrors()
+	}
+	if pageErrors == nil {
+		pageErrors = []PageError{}
+	}
+	return SuccessResponse(cmd.ID, ErrorsData{Errors: pageErrors})
+}
+
+func handlePerf(cmd *PerfCommand, browser *BrowserManager) Response {
+	report, err := browser.Perf()
+	if err != nil {
+		return errorResponse(cmd.ID, err)
+	}
+	return SuccessResponse(cmd.ID, report)
+}
+
+func handleStats(cmd *StatsCommand, browser *BrowserManager) Response {
+	if cmd.Kind != "memory" {
+		return ErrorResponse(cmd.ID, fmt.Sprintf("unknown stats kind: %s", cmd.Kind))
+	}
+	stats, err := browser.MemoryStats()
+	if err != nil {
+		return errorResponse(cmd.ID, err)
+	}
+	if cmd.HeapSnapshot == "" {
+		return SuccessResponse(cmd.ID, stats)
+	}
+	if err := browser.HeapSnapshot(cmd.HeapSnapshot); err != nil {
+		return errorResponse(cmd.ID, err)
+	}
+	return SuccessResponse(cmd.ID, struct {
+		*MemoryStats
+		HeapSnapshot string `json:"heapSnapshot"`
+	}{stats, cmd.HeapSnapshot})
+}
+
+func handleAudit(cmd *AuditCommand, browser *BrowserManager) Response {
+	if cmd.Kind != "page" {
+		return ErrorResponse(cmd.ID, fmt.Sprintf("unknown audit kind: %s", cmd.Kind))
+	}
+	report, err := browser.Audit()
+	if err != nil {
+		return errorResponse(cmd.ID, err)
+	}
+	return SuccessResponse(cmd.ID, report)
+}
+
+func handleChallenge(cmd *ChallengeCommand, browser *BrowserManager) Response {
+	challenge, err := browser.DetectChallenge()
+	if err != nil {
+		return errorResponse(cmd.ID, err)
+	}
+	return SuccessResponse(cmd.ID, map[string]*Challenge{"challenge": challenge})
+}
+
+func handleGeolocation(cmd *GeolocationCommand, browser *BrowserManager) Response {
+	var geo *Geolocation
+	if !cmd.Clear {
+		geo = &Geolocation{Latitude: cmd.Latitude, Longitude: cmd.Longitude, Accuracy: cmd.Accuracy}
+	}
+	if err := browser.SetGeolocation(geo); err != nil {
+		return errorResponse(cmd.ID, err)
+	}
+	return SuccessResponse(cmd.ID, nil)
+}
+
+func handlePermissions(cmd *PermissionsCommand, browser *BrowserManager) Response {
+	var err error
+	switch {
+	case cmd.Reset:
+		err = browser.ResetPermissions()
+	case cmd.Grant:
+		err = browser.GrantPermissions(cmd.Permissions, cmd.Origin)
+	default:
+		err = browser.DenyPermissions(cmd.Permissions, cmd.Origin)
+	}
+	if err != nil {
+		return errorResponse(cmd.ID, err)
+	}
+	return SuccessResponse(cmd.ID, nil)
+}
+
+func handleUserAgent(cmd *UserAgentCommand, browser *BrowserManager) Response {
+	if err := browser.SetUserAgent(cmd.UserAgent); err != nil {
+		return errorResponse(cmd.ID, err)
+	}
+	return SuccessResponse(cmd.ID, nil)
+}
+
+func handleLocale(cmd *LocaleCommand, browser *BrowserManager) Response {
+	if err := browser.SetLocale(cmd.Locale); err != nil {
+		return errorResponse(cmd.ID, err)
+	}
+	return SuccessResponse(cmd.ID, nil)
+}
+
+func handleHTTPCredentials(cmd *HTTPCredentialsCommand, browser *BrowserManager) Response {
+	var creds *HTTPCredentials
+	if !cmd.Clear {
+		creds = &HTTPCredentials{Username: cmd.Username, Password: cmd.Password, Origin: cmd.Origin}
+	}
+	if err := browser.SetHTTPCredentials(creds); err != nil {
+		return errorResponse(cmd.ID, err)
+	}
+	return SuccessResponse(cmd.ID, nil)
+}
+
+func handleOffline(cmd *OfflineCommand, browser *BrowserManager) Response {
+	if err := browser.SetOffline(cmd.Offline); err != nil {
+		return errorResponse(cmd.ID, err)
+	}
+	return SuccessResponse(cmd.ID, nil)
+}
+
+func handleDialog(cmd *DialogCommand, browser *BrowserManager) Response {
+	if err := browser.SetDialogResponse(cmd.Response, cmd.PromptText); err != nil {
+		return errorResponse(cmd.ID, err)
+	}
+	return SuccessResponse(cmd.ID, nil)
+}
+
+func handleHeaders(cmd *HeadersCommand, browser *BrowserManager) Response {
+	if err := browser.SetExtraHeaders(cmd.Headers); err != nil {
+		return errorResponse(cmd.ID, err)
+	}
+	return SuccessResponse(cmd.ID, nil)
+}
+
+func handleEmulateMedia(cmd *EmulateMediaCommand, browser *BrowserManager) Response {
+	media, err := browser.EmulateMedia(MediaEmulation{
+		Media:         cmd.Media,
+		ColorScheme:   cmd.ColorScheme,
+		ReducedMotion: cmd.ReducedMotion,
+		ForcedColors:  cmd.ForcedColors,
+	})
+	if err != nil {
+		return errorResponse(cmd.ID, err)
+	}
+	return SuccessResponse(cmd.ID, media)
+}
+
+func handleUARotationStart(cmd *UARotationStartCommand, browser *BrowserManager) Response {
+	ua, err := browser.StartUARotation(UARotation{
+		UserAgents:    cmd.UserAgents,
+		PerNavigation: cmd.PerNavigation,
+		Random:        cmd.Random,
+	})
+	if err != nil {
+		return errorResponse(cmd.ID, err)
+	}
+	return SuccessResponse(cmd.ID, map[string]string{"userAgent": ua})
+}
+
+func handleUARotationStop(cmd *UARotationStopCommand, browser *BrowserManager) Response {
+	if err := browser.StopUARotation(); err != nil {
+		return errorResponse(cmd.ID, err)
+	}
+	return SuccessResponse(cmd.ID, map[string]bool{"rotating": false})
+}
+
+func handleUARotate(cmd *UARotateCommand, browser *BrowserManager) Response {
+	ua, err := browser.RotateUserAgent()
+	if err != nil {
+		return errorResponse(cmd.ID, err)
+	}
+	return SuccessResponse(cmd.ID, map[string]string{"userAgent": ua})
+}
+
+// excerptRadius is the number of snapshot lines shown on each side of the
+// closest match in element-not-found errors.
+const excerptRadius = 4
+
+// elementErrorResponse builds the error response for a failed element action.
+// When the element could not be found, the response data carries the page
+// URL/title and an interactive snapshot excerpt around the closest match, so
+// the caller can retarget without another round trip. The excerpt comes from
+// a fresh snapshot, so its refs replace the previous ones.
+func elementErrorResponse(id string, err error, selector string, browser *BrowserManager) Response {
+	resp := ErrorResponse(id, toAIFriendlyError(err, selector))
+	resp.Code = failureCode(err)
+	if resp.Code == "" {
+		return resp
+	}
+
+	query := targetQuery(selector, browser.GetRefMap())
+	var refErr *RefNotFoundError
+	if errors.As(err, &refErr) && refErr.Role != "" {
+		query = refErr.Role + " " + refErr.Name
+	}
+
+	// The refs the caller holds stay valid; the excerpt's refs are only hints
+	snapshot, serr := browser.peekSnapshot(SnapshotOptions{Interactive: true})
+	if serr != nil {
+		return resp
+	}
+
+	data := ElementErrorData{}
+	data.URL, _ = browser.URL()
+	data.Title, _ = browser.Title()
+
+	var ref string
+	if candidates := rankRefs(snapshot.Refs, query, 0); len(candidates) > 0 {
+		data.BestMatch = &candidates[0]
+		ref = candidates[0].Ref
+		if refErr != nil {
+			data.Candidates = rankRefs(snapshot.Refs, query, minRefMatchScore)
+			if len(data.Candidates) > maxRefCandidates {
+				data.Candidates = data.Candidates[:maxRefCandidates]
+			}
+		}
+	}
+	data.Excerpt = snapshotExcerpt(snapshot.Tree, ref, excerptRadius)
+
+	if raw, merr := json.Marshal(data); merr == nil {
+		resp.Data = raw
+	}
+	return resp
+}
+
 // toAIFriendlyError converts chromedp errors to user-friendly messages.
 func toAIFriendlyError(err error, selector string) string {
+	var refErr *RefNotFoundError
+	if errors.As(err, &refErr) {
+		return refErr.Error()
+	}
+
 	errStr := err.Error()
 
 	// Check for common error patterns
@@ -556,3 +1599,204 @@ func containsImpl(s, substr string) bool {
 func SerializeCommand(cmd Command) ([]byte, error) {
 	return json.Marshal(cmd)
 }
+
+func handleCookiesGet(cmd *CookiesGetCommand, browser *BrowserManager) Response {
+	cookies, err := browser.Cookies(cmd.URLs)
+	if err != nil {
+		return errorResponse(cmd.ID, err)
+	}
+	if cookies == nil {
+		cookies = []Cookie{}
+	}
+	return SuccessResponse(cmd.ID, CookiesData{Cookies: cookies})
+}
+
+func handleCookiesSet(cmd *CookiesSetCommand, browser *BrowserManager) Response {
+	if err := browser.SetCookies(cmd.Cookies); err != nil {
+		return errorResponse(cmd.ID, err)
+	}
+	return SuccessResponse(cmd.ID, map[string]interface{}{"set": len(cmd.Cookies)})
+}
+
+func handleCookiesClear(cmd *CookiesClearCommand, browser *BrowserManager) Response {
+	if err := browser.ClearCookies(); err != nil {
+		return errorResponse(cmd.ID, err)
+	}
+	return SuccessResponse(cmd.ID, nil)
+}
+
+func handleStorageGet(cmd *StorageGetCommand, browser *BrowserManager) Response {
+	storage, err := browser.Storage(cmd.Type, cmd.Key)
+	if err != nil {
+		return errorResponse(cmd.ID, err)
+	}
+	return SuccessResponse(cmd.ID, storage)
+}
+
+func handleStorageSet(cmd *StorageSetCommand, browser *BrowserManager) Response {
+	if err := browser.SetStorage(cmd.Type, cmd.Key, cmd.Value); err != nil {
+		return errorResponse(cmd.ID, err)
+	}
+	return SuccessResponse(cmd.ID, nil)
+}
+
+func handleStorageClear(cmd *StorageClearCommand, browser *BrowserManager) Response {
+	if err := browser.ClearStorage(cmd.Type); err != nil {
+		return errorResponse(cmd.ID, err)
+	}
+	return SuccessResponse(cmd.ID, nil)
+}
+
+func handleCookiesExport(cmd *CookiesExportCommand, browser *BrowserManager) Response {
+	if cmd.Path == "" {
+		return ErrorResponse(cmd.ID, "path is required")
+	}
+	n, err := browser.ExportCookies(cmd.Path, cmd.Format)
+	if err != nil {
+		return errorResponse(cmd.ID, err)
+	}
+	return SuccessResponse(cmd.ID, map[string]interface{}{"path": cmd.Path, "count": n})
+}
+
+func handleCookiesImport(cmd *CookiesImportCommand, browser *BrowserManager) Response {
+	if cmd.Path == "" {
+		return ErrorResponse(cmd.ID, "path is required")
+	}
+	n, err := browser.ImportCookies(cmd.Path)
+	if err != nil {
+		return errorResponse(cmd.ID, err)
+	}
+	return SuccessResponse(cmd.ID, map[string]interface{}{"path": cmd.Path, "count": n})
+}
+
+func handleFetch(cmd *FetchCommand, browser *BrowserManager) Response {
+	if cmd.URL == "" {
+		return ErrorResponse(cmd.ID, "url is required")
+	}
+	resp, err := browser.Fetch(FetchRequest{
+		URL:     cmd.URL,
+		Method:  cmd.Method,
+		Headers: cmd.Headers,
+		Body:    cmd.Body,
+	})
+	if err != nil {
+		return errorResponse(cmd.ID, err)
+	}
+	return SuccessResponse(cmd.ID, resp)
+}
+
+func handleCrawl(cmd *CrawlCommand, browser *BrowserManager) Response {
+	if cmd.URL == "" {
+		return ErrorResponse(cmd.ID, "url is required")
+	}
+	pages, err := browser.Crawl(CrawlOptions{
+		StartURL:   cmd.URL,
+		Depth:      cmd.Depth,
+		SameOrigin: cmd.SameOrigin,
+		MaxPages:   cmd.MaxPages,
+		Delay:      time.Duration(cmd.Delay) * time.Millisecond,
+		Markdown:   cmd.Markdown,
+		Polite:     cmd.Polite,
+	})
+	if err != nil {
+		return errorResponse(cmd.ID, err)
+	}
+	return SuccessResponse(cmd.ID, map[string]interface{}{"pages": pages})
+}
+
+func handlePaginate(cmd *PaginateCommand, browser *BrowserManager) Response {
+	result, err := browser.Paginate(PaginateOptions{
+		Next:     cmd.Next,
+		Extract:  cmd.Extract,
+		MaxPages: cmd.MaxPages,
+		Timeout:  time.Duration(cmd.Timeout) * time.Millisecond,
+	})
+	if err != nil {
+		return errorResponse(cmd.ID, err)
+	}
+	return SuccessResponse(cmd.ID, result)
+}
+
+func handlePolite(cmd *PoliteCommand, browser *BrowserManager) Response {
+	browser.SetPolite(cmd.Enabled)
+	return SuccessResponse(cmd.ID, map[string]bool{"polite": cmd.Enabled})
+}
+
+func handleSitemap(cmd *SitemapCommand, browser *BrowserManager) Response {
+	result, err := browser.Sitemap(cmd.URL)
+	if err != nil {
+		return errorResponse(cmd.ID, err)
+	}
+	return SuccessResponse(cmd.ID, result)
+}
+
+func handleClearData(cmd *ClearDataCommand, browser *BrowserManager) Response {
+	result, err := browser.ClearData(ClearDataOptions{
+		Cache:   cmd.Cache,
+		Cookies: cmd.Cookies,
+		Storage: cmd.Storage,
+	})
+	if err != nil {
+		return errorResponse(cmd.ID, err)
+	}
+	return SuccessResponse(cmd.ID, result)
+}
+
+func handleServiceWorkers(cmd *ServiceWorkersCommand, browser *BrowserManager) Response {
+	workers, err := browser.ServiceWorkers()
+	if err != nil {
+		return errorResponse(cmd.ID, err)
+	}
+	return SuccessResponse(cmd.ID, map[string]interface{}{"serviceWorkers": workers})
+}
+
+func handleServiceWorkersUnregister(cmd *ServiceWorkersUnregisterCommand, browser *BrowserManager) Response {
+	n, err := browser.UnregisterServiceWorkers(cmd.Scope)
+	if err != nil {
+		return errorResponse(cmd.ID, err)
+	}
+	return SuccessResponse(cmd.ID, map[string]int{"unregistered": n})
+}
+
+func handleServiceWorkersBypass(cmd *ServiceWorkersBypassCommand, browser *BrowserManager) Response {
+	if err := browser.SetServiceWorkerBypass(cmd.Bypass); err != nil {
+		return errorResponse(cmd.ID, err)
+	}
+	return SuccessResponse(cmd.ID, map[string]bool{"bypass": cmd.Bypass})
+}
+
+func handleStateSave(cmd *StateSaveCommand, browser *BrowserManager) Response {
+	if cmd.Path == "" {
+		return ErrorResponse(cmd.ID, "path is required")
+	}
+	if err := browser.SaveStorageState(cmd.Path); err != nil {
+		return errorResponse(cmd.ID, err)
+	}
+	return SuccessResponse(cmd.ID, map[string]string{"path": cmd.Path})
+}
+
+func handleStateLoad(cmd *StateLoadCommand, browser *BrowserManager) Response {
+	if cmd.Path == "" {
+		return ErrorResponse(cmd.ID, "path is required")
+	}
+	state, err := browser.LoadStorageState(cmd.Path)
+	if err != nil {
+		return errorResponse(cmd.ID, err)
+	}
+	return SuccessResponse(cmd.ID, map[string]interface{}{
+		"path":    cmd.Path,
+		"cookies": len(state.Cookies),
+		"origins": len(state.Origins),
+	})
+}
+
+func handleLogin(cmd *LoginCommand, browser *BrowserManager) Response {
+	result, err := browser.Login(cmd.Recipe)
+	if err != nil {
+		if cmd.Site != "" {
+			return errorResponse(cmd.ID, fmt.Errorf("%s: %w", cmd.Site, err))
+		}
+		return errorResponse(cmd.ID, err)
+	}
+	return SuccessResponse(cmd.ID, result)
+}
@@ -0,0 +1,219 @@
+package agentbrowser
+
+import (
+	"sync"
+	"time"
+)
+
+// PageActivity counts page events since launch. Readings taken before and
+// after an action are diffed to summarize the action's side effects.
+type PageActivity struct {
+	Navigations   int    // main-frame navigations started
+	ConsoleErrors int    // console.error calls and uncaught exceptions
+	Dialogs       int    // JavaScript dialogs opened
+	LastDialog    string // message of the most recent dialog
+}
+
+// activityTracker records console messages, page errors, dialogs and
+// navigations for a backend. Backends embed it and feed it from their
+// event listeners.
+type activityTracker struct {
+	activityLock sync.Mutex
+	activity     PageActivity
+	activityWake chan struct{} // closed when the activity changes, for WaitActivity
+	consoleLog   []ConsoleMessage
+	pageErrors   []PageError
+}
+
+// maxTrackedEvents bounds the console and error logs kept in memory.
+const maxTrackedEvents = 1000
+
+// recordConsole logs a console message and returns it as logged, with its
+// timestamp.
+func (t *activityTracker) recordConsole(msg ConsoleMessage) ConsoleMessage {
+	t.activityLock.Lock()
+	defer t.activityLock.Unlock()
+
+	if msg.Timestamp == 0 {
+		msg.Timestamp = time.Now().UnixMilli()
+	}
+	t.consoleLog = appendBounded(t.consoleLog, msg)
+	if msg.Type == "error" {
+		t.activity.ConsoleErrors++
+		t.activityChanged()
+	}
+	return msg
+}
+
+func (t *activityTracker) recordPageError(pageErr PageError) {
+	t.activityLock.Lock()
+	defer t.activityLock.Unlock()
+
+	if pageErr.Timestamp == 0 {
+		pageErr.Timestamp = time.Now().UnixMilli()
+	}
+	t.pageErrors = appendBounded(t.pageErrors, pageErr)
+	t.activity.ConsoleErrors++
+	t.activityChanged()
+}
+
+func (t *activityTracker) recordDialog(message string) {
+	t.activityLock.Lock()
+	defer t.activityLock.Unlock()
+
+	t.activity.Dialogs++
+	t.activity.LastDialog = message
+	t.activityChanged()
+}
+
+func (t *activityTracker) recordNavigation() {
+	t.activityLock.Lock()
+	defer t.activityLock.Unlock()
+
+	t.activity.Navigations++
+	t.activityChanged()
+}
+
+// activityChanged wakes WaitActivity callers. activityLock must be held.
+func (t *activityTracker) activityChanged() {
+	if t.activityWake != nil {
+		close(t.activityWake)
+		t.activityWake = nil
+	}
+}
+
+// Activity returns the current event counters.
+func (t *activityTracker) Activity() PageActivity {
+	t.activityLock.Lock()
+	defer t.activityLock.Unlock()
+
+	return t.activity
+}
+
+// WaitActivity waits up to timeout for the activity to differ from since,
+// and returns it.
+func (t *activityTracker) WaitActivity(since PageActivity, timeout time.Duration) PageActivity {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	for {
+		t.activityLock.Lock()
+		activity := t.activity
+		if t.activityWake == nil {
+			t.activityWake = make(chan struct{})
+		}
+		wake := t.activityWake
+		t.activityLock.Unlock()
+		if activity != since {
+			return activity
+		}
+
+		select {
+		case <-wake:
+		case <-timer.C:
+			return t.Activity()
+		}
+	}
+}
+
+// ConsoleMessages returns the recorded console messages, oldest first.
+func (t *activityTracker) ConsoleMessages() []ConsoleMessage {
+	t.activityLock.Lock()
+	defer t.activityLock.Unlock()
+
+	return append([]ConsoleMessage(nil), t.consoleLog...)
+}
+
+// ClearConsole empties the console message log. The activity counters are
+// kept, so action summaries are unaffected.
+func (t *activityTracker) ClearConsole() {
+	t.activityLock.Lock()
+	defer t.activityLock.Unlock()
+
+	t.consoleLog = nil
+}
+
+// PageErrors returns the recorded uncaught exceptions, oldest first.
+func (t *activityTracker) PageErrors() []PageError {
+	t.activityLock.Lock()
+	defer t.activityLock.Unlock()
+
+	return append([]PageError(nil), t.pageErrors...)
+}
+
+// ClearPageErrors empties the uncaught exception log. The activity
+// counters are kept.
+func (t *activityTracker) ClearPageErrors() {
+	t.activityLock.Lock()
+	defer t.activityLock.Unlock()
+
+	t.pageErrors = nil
+}
+
+// resetActivity clears all recorded events, e.g. when the browser relaunches.
+func (t *activityTracker) resetActivity() {
+	t.activityLock.Lock()
+	defer t.activityLock.Unlock()
+
+	t.activity = PageActivity{}
+	t.consoleLog = nil
+	t.pageErrors = nil
+	t.activityChanged()
+}
+
+// ConsoleMessages returns the console messages logged by pages since
+// launch, or since the log was last cleared, oldest first.
+func (m *BrowserManager) ConsoleMessages() []ConsoleMessage {
+	return m.backend.ConsoleMessages()
+}
+
+// ClearConsole empties the console message log.
+func (m *BrowserManager) ClearConsole() {
+	m.backend.ClearConsole()
+}
+
+// PageErrors returns the uncaught exceptions thrown in pages since launch,
+// or since the log was last cleared, oldest first.
+func (m *BrowserManager) PageErrors() []PageError {
+	return m.backend.PageErrors()
+}
+
+// ClearPageErrors empties the uncaught exception log.
+func (m *BrowserManager) ClearPageErrors() {
+	m.backend.ClearPageErrors()
+}
+
+// appendBounded appends v, dropping the oldest entries beyond maxTrackedEvents.
+func appendBounded[T any](s []T, v T) []T {
+	s = append(s, v)
+	if len(s) > maxTrackedEvents {
+		s = s[len(s)-maxTrackedEvents:]
+	}
+	return s
+}
+
+// ActionSummary describes the side effects observed around an action.
+type ActionSummary struct {
+	URL               string `json:"url,omitempty"`
+	URLChanged        bool   `json:"urlChanged"`
+	NavigationStarted bool   `json:"navigationStarted"`
+	ConsoleErrors     int    `json:"consoleErrors"`
+	DialogOpened      bool   `json:"dialogOpened"`
+	Dialog            string `json:"dialog,omitempty"` // message of the dialog opened by the action
+}
+
+// summarizeAction compares readings taken before and after an action.
+func summarizeAction(beforeURL, afterURL string, before, after PageActivity) ActionSummary {
+	summary := ActionSummary{
+		URLChanged:        beforeURL != afterURL,
+		NavigationStarted: after.Navigations > before.Navigations,
+		ConsoleErrors:     after.ConsoleErrors - before.ConsoleErrors,
+	}
+	if summary.URLChanged {
+		summary.URL = afterURL
+	}
+	if after.Dialogs > before.Dialogs {
+		summary.DialogOpened = true
+		summary.Dialog = after.LastDialog
+	}
+	return summary
+}
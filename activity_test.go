@@ -0,0 +1,191 @@
+package agentbrowser_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/chromedp/cdproto/runtime"
+
+	agentbrowser "github.com/cpunion/agent-browser-go"
+)
+
+// TestSummarizeAction tests diffing page activity around an action
+func TestSummarizeAction(t *testing.T) {
+	before := agentbrowser.PageActivity{Navigations: 1, ConsoleErrors: 2}
+
+	tests := []struct {
+		name      string
+		beforeURL string
+		afterURL  string
+		after     agentbrowser.PageActivity
+		expected  agentbrowser.ActionSummary
+	}{
+		{
+			name:      "no side effects",
+			beforeURL: "https://example.com/",
+			afterURL:  "https://example.com/",
+			after:     before,
+			expected:  agentbrowser.ActionSummary{},
+		},
+		{
+			name:      "navigation",
+			beforeURL: "https://example.com/",
+			afterURL:  "https://example.com/next",
+			after:     agentbrowser.PageActivity{Navigations: 2, ConsoleErrors: 2},
+			expected: agentbrowser.ActionSummary{
+				URL:               "https://example.com/next",
+				URLChanged:        true,
+				NavigationStarted: true,
+			},
+		},
+		{
+			name:      "errors and dialog",
+			beforeURL: "https://example.com/",
+			afterURL:  "https://example.com/",
+			after:     agentbrowser.PageActivity{Navigations: 1, ConsoleErrors: 5, Dialogs: 1, LastDialog: "Are you sure?"},
+			expected: agentbrowser.ActionSummary{
+				ConsoleErrors: 3,
+				DialogOpened:  true,
+				Dialog:        "Are you sure?",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := agentbrowser.SummarizeAction(tt.beforeURL, tt.afterURL, before, tt.after)
+			if got != tt.expected {
+				t.Errorf("expected %+v, got %+v", tt.expected, got)
+			}
+		})
+	}
+}
+
+// TestClearConsole tests that clearing the console log keeps the activity
+// counters action summaries are diffed from
+func TestClearConsole(t *testing.T) {
+	var tracker agentbrowser.ActivityTracker
+	tracker.Console(agentbrowser.ConsoleMessage{Type: "log", Text: "ready"})
+	tracker.Console(agentbrowser.ConsoleMessage{Type: "error", Text: "boom"})
+
+	messages := tracker.ConsoleMessages()
+	if len(messages) != 2 || messages[1].Text != "boom" || messages[0].Timestamp == 0 {
+		t.Fatalf("ConsoleMessages() = %+v, want both messages with timestamps", messages)
+	}
+	tracker.ClearConsole()
+	if messages := tracker.ConsoleMessages(); len(messages) != 0 {
+		t.Errorf("ConsoleMessages() after ClearConsole() = %+v, want none", messages)
+	}
+	if errors := tracker.Activity().ConsoleErrors; errors != 1 {
+		t.Errorf("ConsoleErrors after ClearConsole() = %d, want 1", errors)
+	}
+}
+
+// TestWaitActivity tests that waiting for an action's side effects ends
+// when one is reported, and at the timeout when none is
+func TestWaitActivity(t *testing.T) {
+	var tracker agentbrowser.ActivityTracker
+	before := tracker.Activity()
+
+	start := time.Now()
+	if got := tracker.WaitActivity(before, 20*time.Millisecond); got != before {
+		t.Errorf("WaitActivity() without events = %+v, want %+v", got, before)
+	}
+	if elapsed := time.Since(start); elapsed < 20*time.Millisecond {
+		t.Errorf("WaitActivity() without events returned after %v, want the 20ms timeout", elapsed)
+	}
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		tracker.Console(agentbrowser.ConsoleMessage{Type: "log", Text: "ignored"})
+		tracker.PageError(agentbrowser.PageError{Message: "Error: boom"})
+	}()
+	start = time.Now()
+	if got := tracker.WaitActivity(before, 5*time.Second); got.ConsoleErrors != 1 {
+		t.Errorf("WaitActivity() = %+v, want the page error counted", got)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("WaitActivity() returned after %v, want it to return on the page error", elapsed)
+	}
+}
+
+// TestSetDialogResponse tests that only known dialog answers are passed to
+// the backend
+func TestSetDialogResponse(t *testing.T) {
+	var answers []string
+	m := agentbrowser.NewBrowserManagerForTest(&fakeBackend{
+		setDialogResponse: func(response, promptText string) error {
+			answers = append(answers, response+":"+promptText)
+			return nil
+		},
+	})
+	for _, response := range []string{"accept", "dismiss", "", "ok"} {
+		err := m.SetDialogResponse(response, "Ada")
+		if (err != nil) != (response == "ok") {
+			t.Errorf("SetDialogResponse(%q) error = %v", response, err)
+		}
+	}
+	if want := []string{"accept:Ada", "dismiss:Ada", ":Ada"}; strings.Join(answers, ",") != strings.Join(want, ",") {
+		t.Errorf("backend answers = %q, want %q", answers, want)
+	}
+}
+
+// TestClearPageErrors tests emptying the uncaught exception log
+func TestClearPageErrors(t *testing.T) {
+	var tracker agentbrowser.ActivityTracker
+	tracker.PageError(agentbrowser.PageError{Message: "Error: boom"})
+	if errors := tracker.PageErrors(); len(errors) != 1 || errors[0].Timestamp == 0 {
+		t.Fatalf("PageErrors() = %+v, want one error with a timestamp", errors)
+	}
+	tracker.ClearPageErrors()
+	if errors := tracker.PageErrors(); len(errors) != 0 {
+		t.Errorf("PageErrors() after ClearPageErrors() = %+v, want none", errors)
+	}
+}
+
+// TestExceptionError tests describing CDP exceptions with their stacks
+func TestExceptionError(t *testing.T) {
+	tests := []struct {
+		name    string
+		details *runtime.ExceptionDetails
+		want    agentbrowser.PageError
+	}{
+		{
+			name: "error object",
+			details: &runtime.ExceptionDetails{
+				Text:      "Uncaught",
+				Exception: &runtime.RemoteObject{Description: "TypeError: x is undefined\n    at f (https://example.com/app.js:3:9)"},
+			},
+			want: agentbrowser.PageError{
+				Message: "TypeError: x is undefined",
+				Stack:   "TypeError: x is undefined\n    at f (https://example.com/app.js:3:9)",
+			},
+		},
+		{
+			name: "thrown value",
+			details: &runtime.ExceptionDetails{
+				Text: "Uncaught boom",
+				StackTrace: &runtime.StackTrace{CallFrames: []*runtime.CallFrame{
+					{URL: "https://example.com/", LineNumber: 0, ColumnNumber: 7},
+				}},
+			},
+			want: agentbrowser.PageError{
+				Message: "Uncaught boom",
+				Stack:   "Uncaught boom\n    at <anonymous> (https://example.com/:1:8)",
+			},
+		},
+		{
+			name:    "no stack",
+			details: &runtime.ExceptionDetails{Text: "Uncaught boom"},
+			want:    agentbrowser.PageError{Message: "Uncaught boom"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := agentbrowser.ExceptionError(tt.details); got != tt.want {
+				t.Errorf("ExceptionError() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
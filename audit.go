@@ -0,0 +1,229 @@
+package agentbrowser
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+)
+
+// AuditReport is a lightweight quality scorecard of the current page.
+type AuditReport struct {
+	URL    string       `json:"url"`
+	Score  int          `json:"score"` // 0-100: passed checks count fully, warnings half
+	Checks []AuditCheck `json:"checks"`
+}
+
+// AuditCheck is the outcome of one audit check.
+type AuditCheck struct {
+	ID     string   `json:"id"`
+	Status string   `json:"status"` // pass, warn or fail
+	Detail string   `json:"detail"`
+	Items  []string `json:"items,omitempty"` // offending URLs, elements or messages
+}
+
+// Audit status values.
+const (
+	AuditPass = "pass"
+	AuditWarn = "warn"
+	AuditFail = "fail"
+)
+
+// Audit thresholds, after Lighthouse's defaults where it has one.
+const (
+	auditWeightWarn   = 1600 << 10 // bytes transferred
+	auditWeightFail   = 4000 << 10
+	auditRequestsWarn = 50
+	auditRequestsFail = 100
+	auditMaxItems     = 20 // items listed per check
+)
+
+// pageAudit is the raw page data the audit is computed from.
+type pageAudit struct {
+	URL          string   `json:"url"`
+	HTTPS        bool     `json:"https"`
+	TimeOrigin   float64  `json:"timeOrigin"` // ms since the epoch
+	TransferSize int64    `json:"transferSize"`
+	Requests     int      `json:"requests"`
+	Blocking     []string `json:"blocking"`
+	LegacyImages []string `json:"legacyImages"`
+	MixedContent []string `json:"mixedContent"`
+	Title        string   `json:"title"`
+	Description  bool     `json:"description"`
+	Lang         bool     `json:"lang"`
+	Viewport     bool     `json:"viewport"`
+	Canonical    bool     `json:"canonical"`
+	H1           int      `json:"h1"`
+	MissingAlt   []string `json:"missingAlt"`
+}
+
+// auditScript gathers the page data for an audit from the DOM and the
+// Resource Timing entries.
+const auditScript = `(() => {
+	const nav = performance.getEntriesByType('navigation')[0];
+	const resources = performance.getEntriesByType('resource');
+	const https = location.protocol === 'https:';
+	const describe = el => el.outerHTML.slice(0, 120);
+
+	const blocking = new Set();
+	for (const r of resources) {
+		if (r.renderBlockingStatus === 'blocking') blocking.add(r.name);
+	}
+	for (const el of document.head.querySelectorAll('script[src]:not([async]):not([defer]):not([type=module])')) {
+		blocking.add(el.src);
+	}
+	for (const el of document.head.querySelectorAll('link[rel=stylesheet]')) {
+		if (!el.media || el.media === 'all' || matchMedia(el.media).matches) blocking.add(el.href);
+	}
+
+	const legacy = /\.(jpe?g|png|gif|bmp)(\?|#|$)/i;
+	const legacyImages = new Set();
+	for (const r of resources) {
+		if (r.initiatorType !== 'img' && r.initiatorType !== 'css') continue;
+		const type = r.contentType || '';
+		if (/^image\/(jpeg|png|gif|bmp)/.test(type) || (!type && legacy.test(r.name))) legacyImages.add(r.name);
+	}
+
+	const mixed = new Set();
+	if (https) {
+		for (const r of resources) if (r.name.startsWith('http:')) mixed.add(r.name);
+		for (const el of document.querySelectorAll('img[src], script[src], iframe[src], video[src], audio[src], source[src], link[rel=stylesheet][href]')) {
+			const url = el.src || el.href;
+			if (url && url.startsWith('http:')) mixed.add(url);
+		}
+	}
+
+	const meta = name => document.querySelector('meta[name="' + name + '"]');
+	return {
+		url: location.href,
+		https,
+		timeOrigin: performance.timeOrigin,
+		transferSize: (nav ? nav.transferSize : 0) + resources.reduce((n, r) => n + (r.transferSize || 0), 0),
+		requests: resources.length + 1,
+		blocking: [...blocking],
+		legacyImages: [...legacyImages],
+		mixedContent: [...mixed],
+		title: document.title.trim(),
+		description: !!(meta('description') && meta('description').content.trim()),
+		lang: !!document.documentElement.lang,
+		viewport: !!meta('viewport'),
+		canonical: !!document.querySelector('link[rel=canonical]'),
+		h1: document.querySelectorAll('h1').length,
+		missingAlt: [...document.querySelectorAll('img:not([alt])')].map(describe),
+	};
+})()`
+
+// Audit checks the current page for weight, request count, render-blocking
+// resources, legacy image formats, meta and SEO basics, mixed content and
+// console errors, from data the page and the session already have.
+func (m *BrowserManager) Audit() (*AuditReport, error) {
+	result, err := m.backend.Evaluate(auditScript)
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(result)
+	if err != nil {
+		return nil, err
+	}
+	var page pageAudit
+	if err := json.Unmarshal(data, &page); err != nil {
+		return nil, fmt.Errorf("unexpected audit data: %w", err)
+	}
+
+	// Console errors logged since this document started loading
+	since := int64(page.TimeOrigin)
+	var consoleErrors []string
+	for _, msg := range m.backend.ConsoleMessages() {
+		if msg.Type == "error" && msg.Timestamp >= since {
+			consoleErrors = append(consoleErrors, msg.Text)
+		}
+	}
+	for _, pageErr := range m.backend.PageErrors() {
+		if pageErr.Timestamp >= since {
+			consoleErrors = append(consoleErrors, pageErr.Message)
+		}
+	}
+	return buildAudit(page, consoleErrors), nil
+}
+
+// buildAudit scores the checks of an audit.
+func buildAudit(page pageAudit, consoleErrors []string) *AuditReport {
+	report := &AuditReport{URL: page.URL}
+	add := func(id, status, detail string, items []string) {
+		if len(items) > auditMaxItems {
+			items = append(items[:auditMaxItems:auditMaxItems], fmt.Sprintf("... %d more", len(items)-auditMaxItems))
+		}
+		report.Checks = append(report.Checks, AuditCheck{ID: id, Status: status, Detail: detail, Items: items})
+	}
+	threshold := func(n, warn, fail int64) string {
+		switch {
+		case n > fail:
+			return AuditFail
+		case n > warn:
+			return AuditWarn
+		}
+		return AuditPass
+	}
+	unless := func(n int, status string) string {
+		if n == 0 {
+			return AuditPass
+		}
+		return status
+	}
+
+	add("page-weight", threshold(page.TransferSize, auditWeightWarn, auditWeightFail),
+		fmt.Sprintf("%.0f KiB transferred", float64(page.TransferSize)/1024), nil)
+	add("request-count", threshold(int64(page.Requests), auditRequestsWarn, auditRequestsFail),
+		fmt.Sprintf("%d requests", page.Requests), nil)
+	add("render-blocking", unless(len(page.Blocking), AuditWarn),
+		fmt.Sprintf("%d render-blocking scripts and stylesheets", len(page.Blocking)), page.Blocking)
+	add("image-formats", unless(len(page.LegacyImages), AuditWarn),
+		fmt.Sprintf("%d images not in WebP, AVIF or SVG", len(page.LegacyImages)), page.LegacyImages)
+
+	var missing []string
+	if page.Title == "" {
+		missing = append(missing, "title")
+	}
+	if !page.Description {
+		missing = append(missing, `meta name="description"`)
+	}
+	if !page.Lang {
+		missing = append(missing, "html lang")
+	}
+	if !page.Viewport {
+		missing = append(missing, `meta name="viewport"`)
+	}
+	if !page.Canonical {
+		missing = append(missing, `link rel="canonical"`)
+	}
+	if page.H1 != 1 {
+		missing = append(missing, fmt.Sprintf("exactly one h1 (found %d)", page.H1))
+	}
+	metaStatus := unless(len(missing), AuditWarn)
+	if page.Title == "" {
+		metaStatus = AuditFail
+	}
+	add("meta", metaStatus, fmt.Sprintf("%d of 6 basics missing", len(missing)), missing)
+	add("image-alt", unless(len(page.MissingAlt), AuditWarn),
+		fmt.Sprintf("%d images without alt text", len(page.MissingAlt)), page.MissingAlt)
+
+	if page.HTTPS {
+		add("mixed-content", unless(len(page.MixedContent), AuditFail),
+			fmt.Sprintf("%d insecure subresources", len(page.MixedContent)), page.MixedContent)
+	} else {
+		add("https", AuditFail, "page is not served over HTTPS", nil)
+	}
+	add("console-errors", unless(len(consoleErrors), AuditFail),
+		fmt.Sprintf("%d console errors", len(consoleErrors)), consoleErrors)
+
+	points := 0.0
+	for _, c := range report.Checks {
+		switch c.Status {
+		case AuditPass:
+			points++
+		case AuditWarn:
+			points += 0.5
+		}
+	}
+	report.Score = int(math.Round(100 * points / float64(len(report.Checks))))
+	return report
+}
@@ -0,0 +1,105 @@
+package agentbrowser_test
+
+import (
+	"testing"
+
+	agentbrowser "github.com/cpunion/agent-browser-go"
+)
+
+// TestAudit tests scoring of the page audit checks
+func TestAudit(t *testing.T) {
+	good := map[string]interface{}{
+		"url": "https://a.com/", "https": true, "timeOrigin": 1000.0,
+		"transferSize": 300000.0, "requests": 12.0,
+		"title": "Home", "description": true, "lang": true, "viewport": true, "canonical": true, "h1": 1.0,
+	}
+	bad := map[string]interface{}{
+		"url": "https://a.com/", "https": true, "timeOrigin": 1000.0,
+		"transferSize": 5000000.0, "requests": 60.0,
+		"blocking":     []interface{}{"https://a.com/app.js"},
+		"legacyImages": []interface{}{"https://a.com/hero.png"},
+		"mixedContent": []interface{}{"http://cdn.a.com/x.js"},
+		"title":        "", "lang": true, "h1": 2.0,
+		"missingAlt": []interface{}{`<img src="hero.png">`},
+	}
+	console := []agentbrowser.ConsoleMessage{
+		{Type: "error", Text: "from the previous page", Timestamp: 500},
+		{Type: "log", Text: "hello", Timestamp: 1500},
+		{Type: "error", Text: "TypeError: x is undefined", Timestamp: 1600},
+	}
+
+	tests := []struct {
+		name      string
+		page      map[string]interface{}
+		wantScore int
+		want      map[string]string
+	}{
+		{
+			name:      "clean page",
+			page:      good,
+			wantScore: 100,
+			want: map[string]string{
+				"page-weight": "pass", "request-count": "pass", "render-blocking": "pass", "image-formats": "pass",
+				"meta": "pass", "image-alt": "pass", "mixed-content": "pass", "console-errors": "pass",
+			},
+		},
+		{
+			name:      "problems",
+			page:      bad,
+			wantScore: 25,
+			want: map[string]string{
+				"page-weight": "fail", "request-count": "warn", "render-blocking": "warn", "image-formats": "warn",
+				"meta": "fail", "image-alt": "warn", "mixed-content": "fail", "console-errors": "fail",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var messages []agentbrowser.ConsoleMessage
+			if tt.name == "problems" {
+				messages = console
+			}
+			backend := &fakeBackend{
+				evaluate:        evalResult(tt.page),
+				consoleMessages: func() []agentbrowser.ConsoleMessage { return messages },
+				pageErrors:      func() []agentbrowser.PageError { return nil },
+			}
+			report, err := agentbrowser.NewBrowserManagerForTest(backend).Audit()
+			if err != nil {
+				t.Fatalf("Audit() error = %v", err)
+			}
+			if report.Score != tt.wantScore {
+				t.Errorf("Score = %d, want %d", report.Score, tt.wantScore)
+			}
+			if len(report.Checks) != len(tt.want) {
+				t.Errorf("got %d checks, want %d", len(report.Checks), len(tt.want))
+			}
+			for _, c := range report.Checks {
+				if c.Status != tt.want[c.ID] {
+					t.Errorf("%s = %s (%s), want %s", c.ID, c.Status, c.Detail, tt.want[c.ID])
+				}
+				if c.ID == "console-errors" && tt.name == "problems" && (len(c.Items) != 1 || c.Items[0] != "TypeError: x is undefined") {
+					t.Errorf("console-errors items = %q, want only the error of this page", c.Items)
+				}
+			}
+		})
+	}
+
+	t.Run("http page", func(t *testing.T) {
+		page := map[string]interface{}{"url": "http://a.com/", "title": "x"}
+		report, err := agentbrowser.NewBrowserManagerForTest(&fakeBackend{
+			evaluate:        evalResult(page),
+			consoleMessages: func() []agentbrowser.ConsoleMessage { return nil },
+			pageErrors:      func() []agentbrowser.PageError { return nil },
+		}).Audit()
+		if err != nil {
+			t.Fatalf("Audit() error = %v", err)
+		}
+		for _, c := range report.Checks {
+			if c.ID == "mixed-content" || (c.ID == "https" && c.Status != "fail") {
+				t.Errorf("unexpected check %+v on an http page", c)
+			}
+		}
+	})
+}
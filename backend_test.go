@@ -1,7 +1,20 @@
 package agentbrowser_test
 
 import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	agentbrowser "github.com/cpunion/agent-browser-go"
 )
@@ -248,12 +261,59 @@ func TestBackend_Evaluate(t *testing.T) {
 	}
 }
 
-// TestBackend_Tabs tests tab management for all backends
-func TestBackend_Tabs(t *testing.T) {
+// TestBackend_Stealth tests that stealth mode hides automation tells for all backends
+func TestBackend_Stealth(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	for _, tt := range testBackends() {
+		t.Run(tt.name, func(t *testing.T) {
+			browser := agentbrowser.NewBrowserManagerWithBackend(tt.backend)
+			defer browser.Close()
+
+			err := browser.Launch(agentbrowser.LaunchOptions{Headless: true, Stealth: true})
+			if err != nil {
+				t.Fatalf("Launch() error = %v", err)
+			}
+
+			_, _, err = browser.Navigate("https://example.com", "load")
+			if err != nil {
+				t.Fatalf("Navigate() error = %v", err)
+			}
+
+			checks := map[string]interface{}{
+				"navigator.webdriver === undefined":              true,
+				"navigator.plugins.length > 0":                   true,
+				"navigator.languages.length > 0":                 true,
+				"typeof window.chrome.runtime === 'object'":      true,
+				"navigator.userAgent.includes('HeadlessChrome')": false,
+			}
+			for script, want := range checks {
+				got, err := browser.Evaluate(script)
+				if err != nil {
+					t.Fatalf("Evaluate(%q) error = %v", script, err)
+				}
+				if got != want {
+					t.Errorf("Evaluate(%q) = %v, want %v", script, got, want)
+				}
+			}
+		})
+	}
+}
+
+// TestBackend_NavigateRequest tests POST and referrer navigation for all backends
+func TestBackend_NavigateRequest(t *testing.T) {
 	if testing.Short() {
 		t.Skip("skipping integration test in short mode")
 	}
 
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		fmt.Fprintf(w, "<title>%s</title><pre id=body>%s|%s</pre><p id=referer>%s</p>", r.Method, r.Header.Get("Content-Type"), body, r.Header.Get("Referer"))
+	}))
+	defer server.Close()
+
 	for _, tt := range testBackends() {
 		t.Run(tt.name, func(t *testing.T) {
 			browser := agentbrowser.NewBrowserManagerWithBackend(tt.backend)
@@ -264,39 +324,2724 @@ func TestBackend_Tabs(t *testing.T) {
 				t.Fatalf("Launch() error = %v", err)
 			}
 
-			// Create new tab
-			index, err := browser.NewTab("")
+			_, title, err := browser.NavigateRequest(agentbrowser.NavigationRequest{
+				URL:     server.URL,
+				Body:    `{"q":1}`,
+				Headers: map[string]string{"Content-Type": "application/json"},
+			}, "load")
 			if err != nil {
-				t.Fatalf("NewTab() error = %v", err)
+				t.Fatalf("NavigateRequest() error = %v", err)
+			}
+			if title != "POST" {
+				t.Errorf("method = %s, want POST", title)
+			}
+			text, err := browser.GetText("#body")
+			if err != nil {
+				t.Fatalf("GetText() error = %v", err)
+			}
+			if want := `application/json|{"q":1}`; text != want {
+				t.Errorf("request = %q, want %q", text, want)
 			}
 
-			if index != 1 {
-				t.Errorf("expected new tab index 1, got %d", index)
+			// Later navigations are plain GETs again
+			_, title, err = browser.Navigate(server.URL, "load")
+			if err != nil || title != "GET" {
+				t.Errorf("Navigate() title = %q, %v, want GET", title, err)
 			}
 
-			// List tabs
-			tabs, err := browser.ListTabs()
+			referrer := server.URL + "/from"
+			if _, _, err := browser.NavigateRequest(agentbrowser.NavigationRequest{URL: server.URL, Referrer: referrer}, "load"); err != nil {
+				t.Fatalf("NavigateRequest(referrer) error = %v", err)
+			}
+			if text, _ := browser.GetText("#referer"); text != referrer {
+				t.Errorf("Referer = %q, want %q", text, referrer)
+			}
+		})
+	}
+}
+
+// TestBackend_Fetch tests in-page fetch with the page's cookies for all backends
+func TestBackend_Fetch(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api" {
+			session := ""
+			if cookie, err := r.Cookie("session"); err == nil {
+				session = cookie.Value
+			}
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprintf(w, `{"method":%q,"session":%q}`, r.Method, session)
+			return
+		}
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "abc"})
+		fmt.Fprint(w, "<title>home</title>")
+	}))
+	defer server.Close()
+
+	for _, tt := range testBackends() {
+		t.Run(tt.name, func(t *testing.T) {
+			browser := agentbrowser.NewBrowserManagerWithBackend(tt.backend)
+			defer browser.Close()
+
+			if err := browser.Launch(agentbrowser.LaunchOptions{Headless: true}); err != nil {
+				t.Fatalf("Launch() error = %v", err)
+			}
+			if _, _, err := browser.Navigate(server.URL, "load"); err != nil {
+				t.Fatalf("Navigate() error = %v", err)
+			}
+
+			resp, err := browser.Fetch(agentbrowser.FetchRequest{URL: "/api", Method: "POST", Body: "x"})
 			if err != nil {
-				t.Fatalf("ListTabs() error = %v", err)
+				t.Fatalf("Fetch() error = %v", err)
 			}
+			want := map[string]interface{}{"method": "POST", "session": "abc"}
+			if resp.Status != 200 || !reflect.DeepEqual(resp.Body, want) {
+				t.Errorf("Fetch() = %+v, want body %v", resp, want)
+			}
+		})
+	}
+}
 
-			if len(tabs) != 2 {
-				t.Errorf("expected 2 tabs, got %d", len(tabs))
+// TestBackend_LaunchCDPPort tests that other tools can attach on the
+// launch's CDP port for all backends
+func TestBackend_LaunchCDPPort(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	for _, tt := range testBackends() {
+		t.Run(tt.name, func(t *testing.T) {
+			l, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatal(err)
 			}
+			port := l.Addr().(*net.TCPAddr).Port
+			l.Close()
 
-			// Close tab
-			err = browser.CloseTab(1)
+			browser := agentbrowser.NewBrowserManagerWithBackend(tt.backend)
+			defer browser.Close()
+
+			if err := browser.Launch(agentbrowser.LaunchOptions{Headless: true, CDPPort: port}); err != nil {
+				t.Fatalf("Launch() error = %v", err)
+			}
+			resp, err := http.Get(fmt.Sprintf("http://127.0.0.1:%d/json/version", port))
 			if err != nil {
-				t.Fatalf("CloseTab() error = %v", err)
+				t.Fatalf("GET /json/version error = %v", err)
+			}
+			defer resp.Body.Close()
+			body, _ := io.ReadAll(resp.Body)
+			if !strings.Contains(string(body), "webSocketDebuggerUrl") {
+				t.Errorf("/json/version = %s, want the browser's debugger URL", body)
 			}
+		})
+	}
+}
 
-			tabs, err = browser.ListTabs()
+// TestBackend_HTTPAuth tests answering basic auth challenges for all backends
+func TestBackend_HTTPAuth(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, pass, ok := r.BasicAuth(); !ok || user != "admin" || pass != "secret" {
+			w.Header().Set("WWW-Authenticate", `Basic realm="test"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, "<title>denied</title>")
+			return
+		}
+		fmt.Fprint(w, "<title>welcome</title>")
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name      string
+		creds     *agentbrowser.HTTPCredentials
+		wantTitle string
+	}{
+		{"valid", &agentbrowser.HTTPCredentials{Username: "admin", Password: "secret"}, "welcome"},
+		{"rejected", &agentbrowser.HTTPCredentials{Username: "admin", Password: "wrong"}, "denied"},
+		{"other origin", &agentbrowser.HTTPCredentials{Username: "admin", Password: "secret", Origin: "https://example.com"}, "denied"},
+	}
+
+	for _, backend := range testBackends() {
+		for _, tt := range tests {
+			t.Run(backend.name+"/"+tt.name, func(t *testing.T) {
+				browser := agentbrowser.NewBrowserManagerWithBackend(backend.backend)
+				defer browser.Close()
+
+				if err := browser.Launch(agentbrowser.LaunchOptions{Headless: true, HTTPCredentials: tt.creds}); err != nil {
+					t.Fatalf("Launch() error = %v", err)
+				}
+				_, title, err := browser.Navigate(server.URL, "load")
+				if err != nil {
+					t.Fatalf("Navigate() error = %v", err)
+				}
+				if title != tt.wantTitle {
+					t.Errorf("title = %q, want %q", title, tt.wantTitle)
+				}
+			})
+		}
+	}
+}
+
+// TestBackend_SetHTTPCredentials tests changing HTTP credentials on a
+// running browser for all backends
+func TestBackend_SetHTTPCredentials(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, pass, ok := r.BasicAuth(); !ok || user != "admin" || pass != "secret" {
+			w.Header().Set("WWW-Authenticate", `Basic realm="test"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, "<title>denied</title>")
+			return
+		}
+		fmt.Fprint(w, "<title>welcome</title>")
+	}))
+	defer server.Close()
+
+	for _, tt := range testBackends() {
+		t.Run(tt.name, func(t *testing.T) {
+			browser := agentbrowser.NewBrowserManagerWithBackend(tt.backend)
+			defer browser.Close()
+
+			if err := browser.Launch(agentbrowser.LaunchOptions{Headless: true}); err != nil {
+				t.Fatalf("Launch() error = %v", err)
+			}
+			check := func(want string) {
+				t.Helper()
+				_, title, err := browser.Navigate(server.URL, "load")
+				if err != nil {
+					t.Fatalf("Navigate() error = %v", err)
+				}
+				if title != want {
+					t.Errorf("title = %q, want %q", title, want)
+				}
+			}
+
+			check("denied")
+			if err := browser.SetHTTPCredentials(&agentbrowser.HTTPCredentials{Username: "admin", Password: "secret"}); err != nil {
+				t.Fatalf("SetHTTPCredentials() error = %v", err)
+			}
+			check("welcome")
+			if err := browser.SetHTTPCredentials(nil); err != nil {
+				t.Fatalf("SetHTTPCredentials(nil) error = %v", err)
+			}
+			// Browsers cache basic auth per origin; a new one shows the change
+			server2 := httptest.NewServer(server.Config.Handler)
+			defer server2.Close()
+			_, title, err := browser.Navigate(server2.URL, "load")
 			if err != nil {
-				t.Fatalf("ListTabs() error = %v", err)
+				t.Fatalf("Navigate() error = %v", err)
 			}
+			if title != "denied" {
+				t.Errorf("title after clearing = %q, want denied", title)
+			}
+		})
+	}
+}
 
-			if len(tabs) != 1 {
-				t.Errorf("expected 1 tab after close, got %d", len(tabs))
+// TestBackend_Offline tests offline mode for all backends
+func TestBackend_Offline(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<p>online</p>`)
+	}))
+	defer server.Close()
+
+	const probe = `fetch("/data?" + Math.random()).then(() => "fetched", () => "failed").then(r => navigator.onLine + " " + r)`
+
+	for _, tt := range testBackends() {
+		t.Run(tt.name, func(t *testing.T) {
+			browser := agentbrowser.NewBrowserManagerWithBackend(tt.backend)
+			defer browser.Close()
+
+			if err := browser.Launch(agentbrowser.LaunchOptions{Headless: true}); err != nil {
+				t.Fatalf("Launch() error = %v", err)
+			}
+			if _, _, err := browser.Navigate(server.URL+"/", "load"); err != nil {
+				t.Fatalf("Navigate() error = %v", err)
+			}
+
+			if err := browser.SetOffline(true); err != nil {
+				t.Fatalf("SetOffline(true) error = %v", err)
+			}
+			if got, _ := browser.Evaluate(probe); got != "false failed" {
+				t.Errorf("offline: probe = %v, want \"false failed\"", got)
+			}
+			if _, _, err := browser.Navigate(server.URL+"/other", "load"); err == nil {
+				t.Errorf("Navigate() offline succeeded")
+			}
+
+			if err := browser.SetOffline(false); err != nil {
+				t.Fatalf("SetOffline(false) error = %v", err)
+			}
+			if _, _, err := browser.Navigate(server.URL+"/", "load"); err != nil {
+				t.Fatalf("Navigate() error = %v", err)
+			}
+			if got, _ := browser.Evaluate(probe); got != "true fetched" {
+				t.Errorf("online: probe = %v, want \"true fetched\"", got)
+			}
+		})
+	}
+}
+
+// TestBackend_ExtraHeaders tests headers sent with every request for all
+// backends
+func TestBackend_ExtraHeaders(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	var lock sync.Mutex
+	seen := map[string]string{} // path -> X-Request-ID
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		seen[r.URL.Path] = r.Header.Get("X-Request-ID")
+		lock.Unlock()
+		if r.URL.Path == "/" {
+			fmt.Fprint(w, `<img src="/pixel.png">`)
+		}
+	}))
+	defer server.Close()
+
+	for _, tt := range testBackends() {
+		t.Run(tt.name, func(t *testing.T) {
+			browser := agentbrowser.NewBrowserManagerWithBackend(tt.backend)
+			defer browser.Close()
+
+			if err := browser.Launch(agentbrowser.LaunchOptions{Headless: true}); err != nil {
+				t.Fatalf("Launch() error = %v", err)
+			}
+			if err := browser.SetExtraHeaders(map[string]string{"X-Request-ID": "run-42"}); err != nil {
+				t.Fatalf("SetExtraHeaders() error = %v", err)
+			}
+			if _, _, err := browser.Navigate(server.URL+"/", "load"); err != nil {
+				t.Fatalf("Navigate() error = %v", err)
+			}
+			lock.Lock()
+			if seen["/"] != "run-42" || seen["/pixel.png"] != "run-42" {
+				t.Errorf("X-Request-ID = %q, want run-42 on the page and its image", seen)
+			}
+			lock.Unlock()
+
+			if err := browser.SetExtraHeaders(nil); err != nil {
+				t.Fatalf("SetExtraHeaders(nil) error = %v", err)
+			}
+			if _, _, err := browser.Navigate(server.URL+"/", "load"); err != nil {
+				t.Fatalf("Navigate() error = %v", err)
+			}
+			lock.Lock()
+			if seen["/"] != "" {
+				t.Errorf("X-Request-ID = %q after clearing", seen["/"])
+			}
+			lock.Unlock()
+		})
+	}
+}
+
+// TestBackend_ServiceWorkers tests service worker controls for all backends
+func TestBackend_ServiceWorkers(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/sw.js" {
+			w.Header().Set("Content-Type", "text/javascript")
+			fmt.Fprint(w, "self.addEventListener('fetch', () => {});")
+			return
+		}
+		fmt.Fprint(w, `<title>app</title><script>
+			window.registered = navigator.serviceWorker.register('/sw.js')
+				.then(() => navigator.serviceWorker.ready).then(() => 'ok', (e) => e.name);
+		</script>`)
+	}))
+	defer server.Close()
+
+	for _, tt := range testBackends() {
+		t.Run(tt.name, func(t *testing.T) {
+			browser := agentbrowser.NewBrowserManagerWithBackend(tt.backend)
+			defer browser.Close()
+
+			if err := browser.Launch(agentbrowser.LaunchOptions{Headless: true}); err != nil {
+				t.Fatalf("Launch() error = %v", err)
+			}
+			if _, _, err := browser.Navigate(server.URL, "load"); err != nil {
+				t.Fatalf("Navigate() error = %v", err)
+			}
+			if got, err := browser.Evaluate("window.registered"); err != nil || got != "ok" {
+				t.Fatalf("registration = %v, %v, want ok", got, err)
+			}
+
+			workers, err := browser.ServiceWorkers()
+			if err != nil || len(workers) != 1 || !strings.HasSuffix(workers[0].ScriptURL, "/sw.js") {
+				t.Fatalf("ServiceWorkers() = %+v, %v, want sw.js", workers, err)
+			}
+			if err := browser.SetServiceWorkerBypass(true); err != nil {
+				t.Errorf("SetServiceWorkerBypass() error = %v", err)
+			}
+			if n, err := browser.UnregisterServiceWorkers(""); err != nil || n != 1 {
+				t.Errorf("UnregisterServiceWorkers() = %d, %v, want 1", n, err)
+			}
+
+			// Blocking makes registration fail
+			if err := browser.Launch(agentbrowser.LaunchOptions{Headless: true, BlockServiceWorkers: true}); err != nil {
+				t.Fatalf("Launch(block) error = %v", err)
+			}
+			if _, _, err := browser.Navigate(server.URL, "load"); err != nil {
+				t.Fatalf("Navigate() error = %v", err)
+			}
+			if got, _ := browser.Evaluate("window.registered"); got == "ok" {
+				t.Error("registration succeeded with service workers blocked")
+			}
+		})
+	}
+}
+
+// TestBackend_ClearData tests clearing cookies and storage for all backends
+func TestBackend_ClearData(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "abc"})
+		fmt.Fprint(w, "<title>app</title><script>localStorage.setItem('k', 'v')</script>")
+	}))
+	defer server.Close()
+
+	for _, tt := range testBackends() {
+		t.Run(tt.name, func(t *testing.T) {
+			browser := agentbrowser.NewBrowserManagerWithBackend(tt.backend)
+			defer browser.Close()
+
+			if err := browser.Launch(agentbrowser.LaunchOptions{Headless: true}); err != nil {
+				t.Fatalf("Launch() error = %v", err)
+			}
+			if _, _, err := browser.Navigate(server.URL, "load"); err != nil {
+				t.Fatalf("Navigate() error = %v", err)
+			}
+
+			if _, err := browser.ClearData(agentbrowser.ClearDataOptions{Cache: true, Cookies: true, Storage: true}); err != nil {
+				t.Fatalf("ClearData() error = %v", err)
+			}
+			if cookies, err := browser.GetCookies(); err != nil || len(cookies) != 0 {
+				t.Errorf("cookies after clear = %+v, %v, want none", cookies, err)
+			}
+			if got, err := browser.Evaluate("localStorage.length"); err != nil || got != float64(0) {
+				t.Errorf("localStorage.length after clear = %v, %v, want 0", got, err)
+			}
+		})
+	}
+}
+
+// TestBackend_Crawl tests crawling with Markdown for all backends
+func TestBackend_Crawl(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/":
+			fmt.Fprint(w, `<title>home</title><main><h1>Home</h1><p>See <a href="/a">page <b>A</b></a>.</p>
+				<ul><li>one</li><li>two</li></ul><script>var x = 1;</script><p hidden>secret</p></main>`)
+		case "/a":
+			fmt.Fprint(w, `<title>A</title><a href="/#top">home</a>`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	for _, tt := range testBackends() {
+		t.Run(tt.name, func(t *testing.T) {
+			browser := agentbrowser.NewBrowserManagerWithBackend(tt.backend)
+			defer browser.Close()
+
+			if err := browser.Launch(agentbrowser.LaunchOptions{Headless: true}); err != nil {
+				t.Fatalf("Launch() error = %v", err)
+			}
+			pages, err := browser.Crawl(agentbrowser.CrawlOptions{StartURL: server.URL + "/", Depth: 2, Markdown: true})
+			if err != nil {
+				t.Fatalf("Crawl() error = %v", err)
+			}
+			if len(pages) != 2 || pages[0].Status != 200 || pages[1].Title != "A" {
+				t.Fatalf("Crawl() = %+v, want home and A", pages)
+			}
+			wantMD := "# Home\n\nSee [page **A**](" + server.URL + "/a).\n\n- one\n- two"
+			if pages[0].Markdown != wantMD {
+				t.Errorf("Markdown = %q, want %q", pages[0].Markdown, wantMD)
+			}
+		})
+	}
+}
+
+// TestBackend_ScrollToEnd tests loading an infinite-scroll page for all backends
+func TestBackend_ScrollToEnd(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<ul id="feed"></ul><script>
+			let batches = 0;
+			function more() {
+				for (let i = 0; i < 10; i++) {
+					const li = document.createElement('li');
+					li.style.height = '100px';
+					li.textContent = 'item ' + (batches * 10 + i);
+					feed.appendChild(li);
+				}
+				batches++;
+			}
+			more();
+			addEventListener('scroll', () => {
+				if (batches < 3 && innerHeight + scrollY >= document.body.scrollHeight - 10) setTimeout(more, 200);
+			});
+		</script>`)
+	}))
+	defer server.Close()
+
+	for _, tt := range testBackends() {
+		t.Run(tt.name, func(t *testing.T) {
+			browser := agentbrowser.NewBrowserManagerWithBackend(tt.backend)
+			defer browser.Close()
+
+			if err := browser.Launch(agentbrowser.LaunchOptions{Headless: true}); err != nil {
+				t.Fatalf("Launch() error = %v", err)
+			}
+			if _, _, err := browser.Navigate(server.URL, "load"); err != nil {
+				t.Fatalf("Navigate() error = %v", err)
+			}
+			result, err := browser.ScrollToEnd(agentbrowser.ScrollToEndOptions{})
+			if err != nil {
+				t.Fatalf("ScrollToEnd() error = %v", err)
+			}
+			if result.Reason != "end" || result.Items != 20 {
+				t.Errorf("ScrollToEnd() = %+v, want 20 items loaded before the end", result)
+			}
+			if n, _ := browser.Count("li"); n != 30 {
+				t.Errorf("Count(li) = %d, want 30", n)
+			}
+		})
+	}
+}
+
+// TestBackend_Paginate tests link and JavaScript pagination for all backends
+func TestBackend_Paginate(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/links":
+			page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+			fmt.Fprintf(w, `<div class="result"><h3>link %d</h3></div>`, page)
+			if page < 2 {
+				fmt.Fprintf(w, `<a href="?page=%d">Next &raquo;</a>`, page+1)
+			}
+		case "/js":
+			fmt.Fprint(w, `<div id="list"></div><button id="next">Next</button><script>
+				let page = 0;
+				function render() {
+					list.innerHTML = '<div class="result"><h3>js ' + page + '</h3></div>';
+					next.disabled = page === 2;
+				}
+				next.onclick = () => setTimeout(() => { page++; render(); }, 100);
+				render();
+			</script>`)
+		}
+	}))
+	defer server.Close()
+
+	spec := &agentbrowser.ExtractSpec{Item: ".result", Fields: map[string]string{"title": "h3"}}
+	for _, tt := range testBackends() {
+		t.Run(tt.name, func(t *testing.T) {
+			browser := agentbrowser.NewBrowserManagerWithBackend(tt.backend)
+			defer browser.Close()
+
+			if err := browser.Launch(agentbrowser.LaunchOptions{Headless: true}); err != nil {
+				t.Fatalf("Launch() error = %v", err)
+			}
+			for _, pager := range []struct{ path, prefix, reason string }{
+				{"/links", "link", "no-next"},
+				{"/js", "js", "disabled"},
+			} {
+				if _, _, err := browser.Navigate(server.URL+pager.path, "load"); err != nil {
+					t.Fatalf("Navigate() error = %v", err)
+				}
+				result, err := browser.Paginate(agentbrowser.PaginateOptions{Next: "text=Next", Extract: spec})
+				if err != nil {
+					t.Fatalf("Paginate(%s) error = %v", pager.path, err)
+				}
+				want := []map[string]interface{}{
+					{"title": pager.prefix + " 0"}, {"title": pager.prefix + " 1"}, {"title": pager.prefix + " 2"},
+				}
+				if !reflect.DeepEqual(result.Items, want) || result.Reason != pager.reason {
+					t.Errorf("Paginate(%s) = %v (%s), want %v (%s)", pager.path, result.Items, result.Reason, want, pager.reason)
+				}
+			}
+		})
+	}
+}
+
+// TestBackend_Perf tests the performance report for all backends
+func TestBackend_Perf(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/app.js":
+			fmt.Fprint(w, "document.title = 'loaded';")
+		default:
+			fmt.Fprint(w, `<h1>Performance</h1><p>Some text to paint.</p><script src="/app.js"></script>`)
+		}
+	}))
+	defer server.Close()
+
+	for _, tt := range testBackends() {
+		t.Run(tt.name, func(t *testing.T) {
+			browser := agentbrowser.NewBrowserManagerWithBackend(tt.backend)
+			defer browser.Close()
+
+			if err := browser.Launch(agentbrowser.LaunchOptions{Headless: true}); err != nil {
+				t.Fatalf("Launch() error = %v", err)
+			}
+			if _, _, err := browser.Navigate(server.URL, "load"); err != nil {
+				t.Fatalf("Navigate() error = %v", err)
+			}
+			report, err := browser.Perf()
+			if err != nil {
+				t.Fatalf("Perf() error = %v", err)
+			}
+			if report.Navigation == nil || report.Navigation.Load <= 0 {
+				t.Errorf("Navigation = %+v, want a load time", report.Navigation)
+			}
+			if report.Resources.ByType["script"].Count != 1 {
+				t.Errorf("Resources = %+v, want one script", report.Resources)
+			}
+			if report.Metrics["Nodes"] == 0 {
+				t.Errorf("Metrics = %v, want a DOM node count", report.Metrics)
+			}
+		})
+	}
+}
+
+// TestBackend_MemoryStats tests memory statistics and heap snapshots for all backends
+func TestBackend_MemoryStats(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	for _, tt := range testBackends() {
+		t.Run(tt.name, func(t *testing.T) {
+			browser := agentbrowser.NewBrowserManagerWithBackend(tt.backend)
+			defer browser.Close()
+
+			if err := browser.Launch(agentbrowser.LaunchOptions{Headless: true}); err != nil {
+				t.Fatalf("Launch() error = %v", err)
+			}
+			if _, _, err := browser.Navigate("data:text/html,<ul><li>a<li>b</ul>", "load"); err != nil {
+				t.Fatalf("Navigate() error = %v", err)
+			}
+			stats, err := browser.MemoryStats()
+			if err != nil {
+				t.Fatalf("MemoryStats() error = %v", err)
+			}
+			if len(stats.Tabs) != 1 || stats.Tabs[0].Nodes == 0 || stats.Tabs[0].JSHeapUsed == 0 {
+				t.Errorf("Tabs = %+v, want one tab with nodes and heap", stats.Tabs)
+			}
+			if len(stats.Processes) == 0 {
+				t.Error("Processes is empty")
+			}
+
+			path := t.TempDir() + "/page.heapsnapshot"
+			if err := browser.HeapSnapshot(path); err != nil {
+				t.Fatalf("HeapSnapshot() error = %v", err)
+			}
+			data, err := os.ReadFile(path)
+			if err != nil || !strings.HasPrefix(string(data), `{"snapshot":`) {
+				t.Errorf("heap snapshot starts with %.20q, want a snapshot", data)
+			}
+		})
+	}
+}
+
+// TestBackend_Audit tests the page audit for all backends
+func TestBackend_Audit(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/app.js":
+			fmt.Fprint(w, "console.error('boom');")
+		default:
+			fmt.Fprint(w, `<html lang="en"><head><title>Audit</title><script src="/app.js"></script></head>
+				<body><h1>Audit</h1><img src="/missing.webp"></body></html>`)
+		}
+	}))
+	defer server.Close()
+
+	for _, tt := range testBackends() {
+		t.Run(tt.name, func(t *testing.T) {
+			browser := agentbrowser.NewBrowserManagerWithBackend(tt.backend)
+			defer browser.Close()
+
+			if err := browser.Launch(agentbrowser.LaunchOptions{Headless: true}); err != nil {
+				t.Fatalf("Launch() error = %v", err)
+			}
+			if _, _, err := browser.Navigate(server.URL, "load"); err != nil {
+				t.Fatalf("Navigate() error = %v", err)
+			}
+			report, err := browser.Audit()
+			if err != nil {
+				t.Fatalf("Audit() error = %v", err)
+			}
+			want := map[string]string{"render-blocking": "warn", "image-alt": "warn", "console-errors": "fail", "https": "fail"}
+			for _, c := range report.Checks {
+				if status, ok := want[c.ID]; ok && c.Status != status {
+					t.Errorf("%s = %s (%s %q), want %s", c.ID, c.Status, c.Detail, c.Items, status)
+				}
+			}
+		})
+	}
+}
+
+// TestBackend_Press tests special keys and chords for all backends
+func TestBackend_Press(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	for _, tt := range testBackends() {
+		t.Run(tt.name, func(t *testing.T) {
+			browser := agentbrowser.NewBrowserManagerWithBackend(tt.backend)
+			defer browser.Close()
+
+			if err := browser.Launch(agentbrowser.LaunchOptions{Headless: true}); err != nil {
+				t.Fatalf("Launch() error = %v", err)
+			}
+			err := browser.SetContent(`<form onsubmit="event.preventDefault(); window.submitted = true">
+				<input id="a" value="hello world"><input id="b"></form>
+				<script>window.keys = []; addEventListener('keydown', e => keys.push(e.key))</script>`)
+			if err != nil {
+				t.Fatalf("SetContent() error = %v", err)
+			}
+
+			for _, key := range []string{"Control+a", "Backspace", "Shift+x", "y", "Tab", "z", "Enter"} {
+				if err := browser.Press(key, "#a"); err != nil {
+					t.Fatalf("Press(%q) error = %v", key, err)
+				}
+			}
+			got, err := browser.Evaluate(`[a.value, b.value, window.submitted === true].join('|')`)
+			if err != nil {
+				t.Fatalf("Evaluate() error = %v", err)
+			}
+			// Tab moves to #b, then pressing on #a refocuses it
+			if got != "Xyz||true" {
+				t.Errorf("after key presses got %v, want Xyz||true", got)
+			}
+		})
+	}
+}
+
+// TestBackend_Requests tests request tracking for all backends
+func TestBackend_Requests(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api":
+			fmt.Fprint(w, `{"ok":true}`)
+		case "/":
+			fmt.Fprint(w, `<script>fetch("/api").then(() => fetch("/missing"))</script>`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	for _, tt := range testBackends() {
+		t.Run(tt.name, func(t *testing.T) {
+			browser := agentbrowser.NewBrowserManagerWithBackend(tt.backend)
+			defer browser.Close()
+
+			if err := browser.Launch(agentbrowser.LaunchOptions{Headless: true}); err != nil {
+				t.Fatalf("Launch() error = %v", err)
+			}
+			if _, _, err := browser.Navigate(server.URL+"/", "load"); err != nil {
+				t.Fatalf("Navigate() error = %v", err)
+			}
+
+			var missing []agentbrowser.TrackedRequest
+			for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(50 * time.Millisecond) {
+				if missing = browser.Requests("/missing"); len(missing) == 1 && missing[0].Duration > 0 {
+					break
+				}
+			}
+			if len(missing) != 1 || missing[0].Status != 404 {
+				t.Fatalf("Requests(/missing) = %+v, want one 404", missing)
+			}
+
+			docs := browser.Requests("document")
+			if len(docs) != 1 || docs[0].Status != 200 || docs[0].Method != "GET" {
+				t.Errorf("Requests(document) = %+v, want one GET 200", docs)
+			}
+			fetches := browser.Requests("fetch")
+			if len(fetches) != 2 || fetches[0].URL != server.URL+"/api" || fetches[0].Status != 200 {
+				t.Errorf("Requests(fetch) = %+v, want /api then /missing", fetches)
+			}
+
+			browser.ClearRequests()
+			if reqs := browser.Requests(""); len(reqs) != 0 {
+				t.Errorf("Requests() after ClearRequests() = %+v, want none", reqs)
+			}
+		})
+	}
+}
+
+// TestBackend_AddTags tests injecting scripts and stylesheets by URL and
+// inline content for all backends
+func TestBackend_AddTags(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/helpers.js":
+			w.Header().Set("Content-Type", "text/javascript")
+			fmt.Fprint(w, `window.double = (n) => n * 2;`)
+		case "/hide.css":
+			w.Header().Set("Content-Type", "text/css")
+			fmt.Fprint(w, `#banner { display: none }`)
+		default:
+			fmt.Fprint(w, `<div id="banner">cookies?</div><p id="text">text</p>`)
+		}
+	}))
+	defer server.Close()
+
+	for _, tt := range testBackends() {
+		t.Run(tt.name, func(t *testing.T) {
+			browser := agentbrowser.NewBrowserManagerWithBackend(tt.backend)
+			defer browser.Close()
+
+			if err := browser.Launch(agentbrowser.LaunchOptions{Headless: true}); err != nil {
+				t.Fatalf("Launch() error = %v", err)
+			}
+			if _, _, err := browser.Navigate(server.URL, "load"); err != nil {
+				t.Fatalf("Navigate() error = %v", err)
+			}
+
+			if err := browser.AddScriptTag(server.URL+"/helpers.js", ""); err != nil {
+				t.Fatalf("AddScriptTag(url) error = %v", err)
+			}
+			if err := browser.AddScriptTag("", `window.greet = "it's \"quoted\"";`); err != nil {
+				t.Fatalf("AddScriptTag(content) error = %v", err)
+			}
+			if err := browser.AddStyleTag(server.URL+"/hide.css", ""); err != nil {
+				t.Fatalf("AddStyleTag(url) error = %v", err)
+			}
+			if err := browser.AddStyleTag("", `#text { color: rgb(255, 0, 0) }`); err != nil {
+				t.Fatalf("AddStyleTag(content) error = %v", err)
+			}
+
+			result, err := browser.Evaluate(`[double(21), greet,
+				getComputedStyle(banner).display, getComputedStyle(text).color].join("|")`)
+			if err != nil {
+				t.Fatalf("Evaluate() error = %v", err)
+			}
+			if want := `42|it's "quoted"|none|rgb(255, 0, 0)`; result != want {
+				t.Errorf("page state = %v, want %s", result, want)
+			}
+		})
+	}
+}
+
+func TestBackend_InitScript(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<script>window.seen = typeof window.early</script>`)
+	}))
+	defer server.Close()
+
+	for _, tt := range testBackends() {
+		t.Run(tt.name, func(t *testing.T) {
+			browser := agentbrowser.NewBrowserManagerWithBackend(tt.backend)
+			defer browser.Close()
+
+			if err := browser.Launch(agentbrowser.LaunchOptions{Headless: true}); err != nil {
+				t.Fatalf("Launch() error = %v", err)
+			}
+			if err := browser.AddInitScript(`window.early = 1`); err != nil {
+				t.Fatalf("AddInitScript() error = %v", err)
+			}
+			if _, _, err := browser.Navigate(server.URL, "load"); err != nil {
+				t.Fatalf("Navigate() error = %v", err)
+			}
+
+			// The page's own script sees what the init script defined
+			result, err := browser.Evaluate(`window.seen`)
+			if err != nil {
+				t.Fatalf("Evaluate() error = %v", err)
+			}
+			if result != "number" {
+				t.Errorf("page saw typeof early = %v, want number", result)
+			}
+		})
+	}
+}
+
+func TestBackend_Tracing(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<button onclick="this.textContent = 'done'">go</button>`)
+	}))
+	defer server.Close()
+
+	for _, tt := range testBackends() {
+		t.Run(tt.name, func(t *testing.T) {
+			browser := agentbrowser.NewBrowserManagerWithBackend(tt.backend)
+			defer browser.Close()
+
+			if err := browser.Launch(agentbrowser.LaunchOptions{Headless: true}); err != nil {
+				t.Fatalf("Launch() error = %v", err)
+			}
+			if err := browser.StartTracing(agentbrowser.TraceOptions{Screenshots: true}); err != nil {
+				t.Fatalf("StartTracing() error = %v", err)
+			}
+			if err := browser.StartTracing(agentbrowser.TraceOptions{}); err == nil {
+				t.Error("StartTracing() while tracing expected an error")
+			}
+			if _, _, err := browser.Navigate(server.URL, "load"); err != nil {
+				t.Fatalf("Navigate() error = %v", err)
+			}
+			if err := browser.Click("button"); err != nil {
+				t.Fatalf("Click() error = %v", err)
+			}
+
+			trace, err := browser.StopTracing(filepath.Join(t.TempDir(), "trace"))
+			if err != nil {
+				t.Fatalf("StopTracing() error = %v", err)
+			}
+			want := map[string]string{"chromedp": "chrome", "playwright": "playwright"}[tt.name]
+			if trace.Format != want || trace.Bytes == 0 {
+				t.Errorf("StopTracing() = %+v, want a non-empty %s trace", trace, want)
+			}
+			if _, err := browser.StopTracing(filepath.Join(t.TempDir(), "again")); err == nil {
+				t.Error("StopTracing() without tracing expected an error")
+			}
+		})
+	}
+}
+
+func TestBackend_Console(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<script>console.log("ready", 42); console.warn("slow")</script>`)
+	}))
+	defer server.Close()
+
+	for _, tt := range testBackends() {
+		t.Run(tt.name, func(t *testing.T) {
+			browser := agentbrowser.NewBrowserManagerWithBackend(tt.backend)
+			defer browser.Close()
+			var eventsLock sync.Mutex
+			var events []string
+			browser.SetEventHandler(func(ev agentbrowser.Event) {
+				var msg agentbrowser.ConsoleMessage
+				if ev.Event == agentbrowser.EventConsole && json.Unmarshal(ev.Data, &msg) == nil {
+					eventsLock.Lock()
+					events = append(events, msg.Type+" "+msg.Text)
+					eventsLock.Unlock()
+				}
+			})
+
+			if err := browser.Launch(agentbrowser.LaunchOptions{Headless: true}); err != nil {
+				t.Fatalf("Launch() error = %v", err)
+			}
+			if _, _, err := browser.Navigate(server.URL, "load"); err != nil {
+				t.Fatalf("Navigate() error = %v", err)
+			}
+
+			var got []string
+			for _, msg := range browser.ConsoleMessages() {
+				got = append(got, msg.Type+" "+msg.Text)
+			}
+			want := []string{"log ready 42", "warn slow"}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("console = %q, want %q", got, want)
+			}
+			eventsLock.Lock()
+			if !reflect.DeepEqual(events, want) {
+				t.Errorf("console events = %q, want %q", events, want)
+			}
+			eventsLock.Unlock()
+
+			browser.ClearConsole()
+			if messages := browser.ConsoleMessages(); len(messages) != 0 {
+				t.Errorf("console after ClearConsole() = %+v, want none", messages)
+			}
+		})
+	}
+}
+
+func TestBackend_Errors(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<script>function explode() { null.boom }; setTimeout(explode)</script>`)
+	}))
+	defer server.Close()
+
+	for _, tt := range testBackends() {
+		t.Run(tt.name, func(t *testing.T) {
+			browser := agentbrowser.NewBrowserManagerWithBackend(tt.backend)
+			defer browser.Close()
+
+			if err := browser.Launch(agentbrowser.LaunchOptions{Headless: true}); err != nil {
+				t.Fatalf("Launch() error = %v", err)
+			}
+			if _, _, err := browser.Navigate(server.URL, "load"); err != nil {
+				t.Fatalf("Navigate() error = %v", err)
+			}
+
+			var pageErrors []agentbrowser.PageError
+			for i := 0; i < 20 && len(pageErrors) == 0; i++ {
+				time.Sleep(50 * time.Millisecond)
+				pageErrors = browser.PageErrors()
+			}
+			if len(pageErrors) != 1 {
+				t.Fatalf("PageErrors() = %+v, want one error", pageErrors)
+			}
+			if got := pageErrors[0]; !strings.HasPrefix(got.Message, "TypeError: ") || !strings.Contains(got.Stack, "explode") {
+				t.Errorf("PageErrors()[0] = %+v, want a TypeError with a stack through explode", got)
+			}
+
+			browser.ClearPageErrors()
+			if pageErrors := browser.PageErrors(); len(pageErrors) != 0 {
+				t.Errorf("PageErrors() after ClearPageErrors() = %+v, want none", pageErrors)
+			}
+		})
+	}
+}
+
+func TestBackend_BringToFront(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	for _, tt := range testBackends() {
+		t.Run(tt.name, func(t *testing.T) {
+			browser := agentbrowser.NewBrowserManagerWithBackend(tt.backend)
+			defer browser.Close()
+
+			if err := browser.Launch(agentbrowser.LaunchOptions{Headless: true}); err != nil {
+				t.Fatalf("Launch() error = %v", err)
+			}
+			if _, err := browser.NewTab("about:blank"); err != nil {
+				t.Fatalf("NewTab() error = %v", err)
+			}
+			if err := browser.SwitchTab(0); err != nil {
+				t.Fatalf("SwitchTab() error = %v", err)
+			}
+			if err := browser.BringToFront(); err != nil {
+				t.Fatalf("BringToFront() error = %v", err)
+			}
+
+			// The raised tab is the visible one
+			result, err := browser.Evaluate(`document.visibilityState`)
+			if err != nil {
+				t.Fatalf("Evaluate() error = %v", err)
+			}
+			if result != "visible" {
+				t.Errorf("visibilityState = %v, want visible", result)
+			}
+		})
+	}
+}
+
+// TestBackend_DispatchEvent tests firing standard and custom events for
+// all backends
+func TestBackend_DispatchEvent(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<form id="f"><input id="email"></form><p id="log"></p>
+<script>
+const log = (s) => document.getElementById("log").textContent += s + " ";
+f.addEventListener("input", (e) => log("input:" + e.constructor.name));
+email.addEventListener("keydown", (e) => log("key:" + e.key));
+email.addEventListener("cart:update", (e) => log("cart:" + e.detail.count));
+email.addEventListener("ping", () => log("ping"));
+document.body.addEventListener("ping", () => log("ping-bubbled"));
+</script>`)
+	}))
+	defer server.Close()
+
+	for _, tt := range testBackends() {
+		t.Run(tt.name, func(t *testing.T) {
+			browser := agentbrowser.NewBrowserManagerWithBackend(tt.backend)
+			defer browser.Close()
+
+			if err := browser.Launch(agentbrowser.LaunchOptions{Headless: true}); err != nil {
+				t.Fatalf("Launch() error = %v", err)
+			}
+			if _, _, err := browser.Navigate(server.URL, "load"); err != nil {
+				t.Fatalf("Navigate() error = %v", err)
+			}
+
+			events := []struct {
+				event string
+				init  map[string]interface{}
+			}{
+				{"input", nil},
+				{"keydown", map[string]interface{}{"key": "Enter"}},
+				{"cart:update", map[string]interface{}{"detail": map[string]interface{}{"count": 3}}},
+				{"ping", map[string]interface{}{"bubbles": false}},
+			}
+			for _, e := range events {
+				if err := browser.DispatchEvent("#email", e.event, e.init); err != nil {
+					t.Fatalf("DispatchEvent(%s) error = %v", e.event, err)
+				}
+			}
+			text, err := browser.GetText("#log")
+			if err != nil {
+				t.Fatalf("GetText() error = %v", err)
+			}
+			if want := "input:InputEvent key:Enter cart:3 ping"; strings.TrimSpace(text) != want {
+				t.Errorf("events = %q, want %q", text, want)
+			}
+		})
+	}
+}
+
+// TestBackend_SelectAll tests replacing an input's text by selecting it
+// before typing, and selecting an element's text, for all backends
+func TestBackend_SelectAll(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<input id="q" value="old query"><div id="note" contenteditable>some notes</div>`)
+	}))
+	defer server.Close()
+
+	for _, tt := range testBackends() {
+		t.Run(tt.name, func(t *testing.T) {
+			browser := agentbrowser.NewBrowserManagerWithBackend(tt.backend)
+			defer browser.Close()
+
+			if err := browser.Launch(agentbrowser.LaunchOptions{Headless: true}); err != nil {
+				t.Fatalf("Launch() error = %v", err)
+			}
+			if _, _, err := browser.Navigate(server.URL, "load"); err != nil {
+				t.Fatalf("Navigate() error = %v", err)
+			}
+
+			if err := browser.SelectAll("#q"); err != nil {
+				t.Fatalf("SelectAll(#q) error = %v", err)
+			}
+			if err := browser.Type("#q", "new", 0); err != nil {
+				t.Fatalf("Type() error = %v", err)
+			}
+			if value, _ := browser.GetInputValue("#q"); value != "new" {
+				t.Errorf("value after SelectAll and Type = %q, want new", value)
+			}
+
+			if err := browser.SelectAll("#note"); err != nil {
+				t.Fatalf("SelectAll(#note) error = %v", err)
+			}
+			selected, err := browser.Evaluate(`window.getSelection().toString()`)
+			if err != nil {
+				t.Fatalf("Evaluate() error = %v", err)
+			}
+			if selected != "some notes" {
+				t.Errorf("selection = %v, want some notes", selected)
+			}
+		})
+	}
+}
+
+// TestBackend_Highlight tests that a highlight overlay appears and is
+// removed after its duration for all backends
+func TestBackend_Highlight(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<button id="buy">Buy</button>`)
+	}))
+	defer server.Close()
+
+	for _, tt := range testBackends() {
+		t.Run(tt.name, func(t *testing.T) {
+			browser := agentbrowser.NewBrowserManagerWithBackend(tt.backend)
+			defer browser.Close()
+
+			if err := browser.Launch(agentbrowser.LaunchOptions{Headless: true}); err != nil {
+				t.Fatalf("Launch() error = %v", err)
+			}
+			if _, _, err := browser.Navigate(server.URL, "load"); err != nil {
+				t.Fatalf("Navigate() error = %v", err)
+			}
+
+			overlays := func() float64 {
+				t.Helper()
+				n, err := browser.Evaluate(`document.querySelectorAll("#__agent_browser_highlight").length`)
+				if err != nil {
+					t.Fatalf("Evaluate() error = %v", err)
+				}
+				count, _ := n.(float64)
+				return count
+			}
+			if err := browser.Highlight("#buy", 300); err != nil {
+				t.Fatalf("Highlight() error = %v", err)
+			}
+			if err := browser.Highlight("#buy", 300); err != nil {
+				t.Fatalf("Highlight() again error = %v", err)
+			}
+			if n := overlays(); n != 1 {
+				t.Errorf("overlays = %v, want 1", n)
+			}
+			time.Sleep(600 * time.Millisecond)
+			if n := overlays(); n != 0 {
+				t.Errorf("overlays after the duration = %v, want 0", n)
+			}
+		})
+	}
+}
+
+// TestBackend_Tap tests that tapping sends touch events for all backends
+func TestBackend_Tap(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<div id="pad" style="margin-top:1500px;height:80px">tap me</div>
+<p id="log"></p>
+<script>
+for (const type of ["touchstart", "touchend"]) {
+	pad.addEventListener(type, () => log.textContent += type + " ");
+}
+</script>`)
+	}))
+	defer server.Close()
+
+	for _, tt := range testBackends() {
+		t.Run(tt.name, func(t *testing.T) {
+			browser := agentbrowser.NewBrowserManagerWithBackend(tt.backend)
+			defer browser.Close()
+
+			if err := browser.Launch(agentbrowser.LaunchOptions{Headless: true}); err != nil {
+				t.Fatalf("Launch() error = %v", err)
+			}
+			if _, _, err := browser.Navigate(server.URL, "load"); err != nil {
+				t.Fatalf("Navigate() error = %v", err)
+			}
+			if err := browser.Tap("#pad"); err != nil {
+				t.Fatalf("Tap() error = %v", err)
+			}
+			text, err := browser.GetText("#log")
+			if err != nil {
+				t.Fatalf("GetText() error = %v", err)
+			}
+			if !strings.Contains(text, "touchstart touchend") {
+				t.Errorf("touch events = %q, want touchstart touchend", text)
+			}
+		})
+	}
+}
+
+// TestBackend_Select tests choosing options by value, label and index for
+// all backends
+func TestBackend_Select(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<select id="country" onchange="document.title = 'changed ' + this.value">
+<option value="ca">Canada</option><option value="us">United States</option><option value="mx">Mexico</option>
+</select>
+<select id="toppings" multiple>
+<option value="cheese">Cheese</option><option value="ham">Ham</option><option value="olives">Olives</option>
+</select>`)
+	}))
+	defer server.Close()
+
+	for _, tt := range testBackends() {
+		t.Run(tt.name, func(t *testing.T) {
+			browser := agentbrowser.NewBrowserManagerWithBackend(tt.backend)
+			defer browser.Close()
+
+			if err := browser.Launch(agentbrowser.LaunchOptions{Headless: true}); err != nil {
+				t.Fatalf("Launch() error = %v", err)
+			}
+			if _, _, err := browser.Navigate(server.URL, "load"); err != nil {
+				t.Fatalf("Navigate() error = %v", err)
+			}
+
+			selected := func(selector string) []interface{} {
+				t.Helper()
+				result, err := browser.Evaluate(`[...document.querySelector("` + selector + `").selectedOptions].map(o => o.value)`)
+				if err != nil {
+					t.Fatalf("Evaluate() error = %v", err)
+				}
+				values, _ := result.([]interface{})
+				return values
+			}
+
+			for _, step := range []struct {
+				value, by, want string
+			}{
+				{"us", "value", "us"},
+				{"Mexico", "label", "mx"},
+				{"0", "index", "ca"},
+			} {
+				if err := browser.Select("#country", []string{step.value}, step.by); err != nil {
+					t.Fatalf("Select(%s by %s) error = %v", step.value, step.by, err)
+				}
+				if got := selected("#country"); len(got) != 1 || got[0] != step.want {
+					t.Errorf("Select(%s by %s) selected %v, want %s", step.value, step.by, got, step.want)
+				}
+			}
+			if title, _ := browser.Title(); title != "changed ca" {
+				t.Errorf("title = %q, want the change event to have fired", title)
+			}
+
+			if err := browser.Select("#toppings", []string{"cheese", "olives"}, ""); err != nil {
+				t.Fatalf("Select(multiple) error = %v", err)
+			}
+			if got := selected("#toppings"); !reflect.DeepEqual(got, []interface{}{"cheese", "olives"}) {
+				t.Errorf("Select(multiple) selected %v, want [cheese olives]", got)
+			}
+		})
+	}
+}
+
+// TestBackend_WaitForURL tests waiting for a client-side redirect for all
+// backends
+func TestBackend_WaitForURL(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/login" {
+			fmt.Fprint(w, `<script>setTimeout(() => location.href = "/app/dashboard", 300)</script>`)
+			return
+		}
+		fmt.Fprint(w, `<h1>Dashboard</h1>`)
+	}))
+	defer server.Close()
+
+	for _, tt := range testBackends() {
+		t.Run(tt.name, func(t *testing.T) {
+			browser := agentbrowser.NewBrowserManagerWithBackend(tt.backend)
+			defer browser.Close()
+
+			if err := browser.Launch(agentbrowser.LaunchOptions{Headless: true}); err != nil {
+				t.Fatalf("Launch() error = %v", err)
+			}
+			if _, _, err := browser.Navigate(server.URL+"/login", "load"); err != nil {
+				t.Fatalf("Navigate() error = %v", err)
+			}
+
+			url, err := browser.WaitForURL("**/dashboard", 5000)
+			if err != nil {
+				t.Fatalf("WaitForURL() error = %v", err)
+			}
+			if url != server.URL+"/app/dashboard" {
+				t.Errorf("WaitForURL() = %q, want %s/app/dashboard", url, server.URL)
+			}
+			if _, err := browser.WaitForURL("**/settings", 300); err == nil {
+				t.Error("WaitForURL(settings) expected a timeout")
+			}
+		})
+	}
+}
+
+// TestBackend_WaitForLoadState tests waiting for network idle after the
+// page loads data for all backends
+func TestBackend_WaitForLoadState(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/data" {
+			time.Sleep(800 * time.Millisecond)
+			fmt.Fprint(w, `loaded`)
+			return
+		}
+		fmt.Fprint(w, `<p id="out">loading</p>
+<script>
+addEventListener("load", () => fetch("/data").then(r => r.text()).then(t => out.textContent = t));
+</script>`)
+	}))
+	defer server.Close()
+
+	for _, tt := range testBackends() {
+		t.Run(tt.name, func(t *testing.T) {
+			browser := agentbrowser.NewBrowserManagerWithBackend(tt.backend)
+			defer browser.Close()
+
+			if err := browser.Launch(agentbrowser.LaunchOptions{Headless: true}); err != nil {
+				t.Fatalf("Launch() error = %v", err)
+			}
+			if _, _, err := browser.Navigate(server.URL, "load"); err != nil {
+				t.Fatalf("Navigate() error = %v", err)
+			}
+			if err := browser.WaitForLoadState("load", 5000); err != nil {
+				t.Fatalf("WaitForLoadState(load) error = %v", err)
+			}
+			if err := browser.WaitForLoadState("networkidle", 10000); err != nil {
+				t.Fatalf("WaitForLoadState(networkidle) error = %v", err)
+			}
+			text, err := browser.GetText("#out")
+			if err != nil {
+				t.Fatalf("GetText() error = %v", err)
+			}
+			if text != "loaded" {
+				t.Errorf("text after networkidle = %q, want loaded", text)
+			}
+		})
+	}
+}
+
+// TestBackend_Storage tests reading and writing localStorage and
+// sessionStorage for all backends
+func TestBackend_Storage(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<script>localStorage.setItem("theme", "dark")</script>`)
+	}))
+	defer server.Close()
+
+	for _, tt := range testBackends() {
+		t.Run(tt.name, func(t *testing.T) {
+			browser := agentbrowser.NewBrowserManagerWithBackend(tt.backend)
+			defer browser.Close()
+
+			if err := browser.Launch(agentbrowser.LaunchOptions{Headless: true}); err != nil {
+				t.Fatalf("Launch() error = %v", err)
+			}
+			if _, _, err := browser.Navigate(server.URL, "load"); err != nil {
+				t.Fatalf("Navigate() error = %v", err)
+			}
+
+			if err := browser.SetStorage("local", "lang", "en"); err != nil {
+				t.Fatalf("SetStorage(local) error = %v", err)
+			}
+			if err := browser.SetStorage("session", "step", "2"); err != nil {
+				t.Fatalf("SetStorage(session) error = %v", err)
+			}
+			local, err := browser.Storage("local", "")
+			if err != nil {
+				t.Fatalf("Storage(local) error = %v", err)
+			}
+			if want := map[string]string{"theme": "dark", "lang": "en"}; !reflect.DeepEqual(local.Items, want) {
+				t.Errorf("Storage(local) = %v, want %v", local.Items, want)
+			}
+			if step, err := browser.Storage("session", "step"); err != nil || step.Items["step"] != "2" {
+				t.Errorf("Storage(session, step) = %+v, %v, want 2", step, err)
+			}
+
+			if err := browser.ClearStorage("session"); err != nil {
+				t.Fatalf("ClearStorage(session) error = %v", err)
+			}
+			if session, _ := browser.Storage("session", ""); session == nil || len(session.Items) != 0 {
+				t.Errorf("Storage(session) after ClearStorage() = %+v, want no entries", session)
+			}
+			if local, _ := browser.Storage("local", ""); local == nil || len(local.Items) != 2 {
+				t.Errorf("Storage(local) after clearing sessionStorage = %+v, want 2 entries", local)
+			}
+		})
+	}
+}
+
+// TestBackend_Cookies tests setting cookies before navigation and clearing
+// them for all backends
+func TestBackend_Cookies(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	var sent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sent = r.Header.Get("Cookie")
+		fmt.Fprint(w, `<p>account</p>`)
+	}))
+	defer server.Close()
+
+	for _, tt := range testBackends() {
+		t.Run(tt.name, func(t *testing.T) {
+			browser := agentbrowser.NewBrowserManagerWithBackend(tt.backend)
+			defer browser.Close()
+
+			if err := browser.Launch(agentbrowser.LaunchOptions{Headless: true}); err != nil {
+				t.Fatalf("Launch() error = %v", err)
+			}
+			err := browser.SetCookies([]agentbrowser.Cookie{
+				{Name: "session", Value: "abc123", URL: server.URL, HTTPOnly: true},
+				{Name: "admin", Value: "1", URL: server.URL + "/admin"},
+			})
+			if err != nil {
+				t.Fatalf("SetCookies() error = %v", err)
+			}
+			if _, _, err := browser.Navigate(server.URL+"/account", "load"); err != nil {
+				t.Fatalf("Navigate() error = %v", err)
+			}
+			if sent != "session=abc123" {
+				t.Errorf("Cookie header = %q, want session=abc123", sent)
+			}
+
+			cookies, err := browser.Cookies([]string{server.URL + "/admin/users"})
+			if err != nil {
+				t.Fatalf("Cookies() error = %v", err)
+			}
+			if len(cookies) != 2 {
+				t.Errorf("Cookies(/admin/users) = %+v, want session and admin", cookies)
+			}
+
+			if err := browser.ClearCookies(); err != nil {
+				t.Fatalf("ClearCookies() error = %v", err)
+			}
+			if cookies, _ := browser.Cookies(nil); len(cookies) != 0 {
+				t.Errorf("Cookies() after ClearCookies() = %+v, want none", cookies)
+			}
+		})
+	}
+}
+
+// TestBackend_UserAgent tests the launch user agent and overriding it for
+// all backends
+func TestBackend_UserAgent(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	var header string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Get("User-Agent")
+		fmt.Fprint(w, `<p>ua</p>`)
+	}))
+	defer server.Close()
+
+	const launchUA = "Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36 LaunchAgent"
+	const otherUA = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/121.0.0.0 Safari/537.36 OtherAgent"
+
+	for _, tt := range testBackends() {
+		t.Run(tt.name, func(t *testing.T) {
+			browser := agentbrowser.NewBrowserManagerWithBackend(tt.backend)
+			defer browser.Close()
+
+			if err := browser.Launch(agentbrowser.LaunchOptions{Headless: true, UserAgent: launchUA}); err != nil {
+				t.Fatalf("Launch() error = %v", err)
+			}
+			check := func(want string) {
+				t.Helper()
+				if _, _, err := browser.Navigate(server.URL+"/", "load"); err != nil {
+					t.Fatalf("Navigate() error = %v", err)
+				}
+				if header != want {
+					t.Errorf("User-Agent header = %q, want %q", header, want)
+				}
+				if got, _ := browser.Evaluate("navigator.userAgent"); got != want {
+					t.Errorf("navigator.userAgent = %v, want %q", got, want)
+				}
+			}
+
+			check(launchUA)
+			if err := browser.SetUserAgent(otherUA); err != nil {
+				t.Fatalf("SetUserAgent() error = %v", err)
+			}
+			check(otherUA)
+			if err := browser.SetUserAgent(""); err != nil {
+				t.Fatalf("SetUserAgent(\"\") error = %v", err)
+			}
+			check(launchUA)
+		})
+	}
+}
+
+// TestBackend_Locale tests the runtime locale override for all backends
+func TestBackend_Locale(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	var header string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Get("Accept-Language")
+		fmt.Fprint(w, `<p>locale</p>`)
+	}))
+	defer server.Close()
+
+	for _, tt := range testBackends() {
+		t.Run(tt.name, func(t *testing.T) {
+			browser := agentbrowser.NewBrowserManagerWithBackend(tt.backend)
+			defer browser.Close()
+
+			if err := browser.Launch(agentbrowser.LaunchOptions{Headless: true}); err != nil {
+				t.Fatalf("Launch() error = %v", err)
+			}
+			if err := browser.SetLocale("de-DE"); err != nil {
+				t.Fatalf("SetLocale() error = %v", err)
+			}
+			if _, _, err := browser.Navigate(server.URL+"/", "load"); err != nil {
+				t.Fatalf("Navigate() error = %v", err)
+			}
+			if !strings.HasPrefix(header, "de-DE") {
+				t.Errorf("Accept-Language header = %q, want de-DE first", header)
+			}
+			if got, _ := browser.Evaluate("navigator.language"); got != "de-DE" {
+				t.Errorf("navigator.language = %v, want de-DE", got)
+			}
+			if got, _ := browser.Evaluate("new Intl.NumberFormat().format(1234.5)"); got != "1.234,5" {
+				t.Errorf("Intl.NumberFormat = %v, want 1.234,5", got)
+			}
+
+			if err := browser.SetLocale(""); err != nil {
+				t.Fatalf("SetLocale(\"\") error = %v", err)
+			}
+			if _, _, err := browser.Navigate(server.URL+"/", "load"); err != nil {
+				t.Fatalf("Navigate() error = %v", err)
+			}
+			if strings.HasPrefix(header, "de-DE") {
+				t.Errorf("Accept-Language header = %q after reset", header)
+			}
+		})
+	}
+}
+
+// TestBackend_EmulateMedia tests media emulation for all backends
+func TestBackend_EmulateMedia(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<style>@media print { p { display: none } }</style><p>media</p>`)
+	}))
+	defer server.Close()
+
+	const probe = `[matchMedia("(prefers-color-scheme: dark)").matches, matchMedia("print").matches,
+		matchMedia("(prefers-reduced-motion: reduce)").matches, getComputedStyle(document.querySelector("p")).display].join(" ")`
+
+	for _, tt := range testBackends() {
+		t.Run(tt.name, func(t *testing.T) {
+			browser := agentbrowser.NewBrowserManagerWithBackend(tt.backend)
+			defer browser.Close()
+
+			if err := browser.Launch(agentbrowser.LaunchOptions{Headless: true}); err != nil {
+				t.Fatalf("Launch() error = %v", err)
+			}
+			if _, _, err := browser.Navigate(server.URL+"/", "load"); err != nil {
+				t.Fatalf("Navigate() error = %v", err)
+			}
+			check := func(want string) {
+				t.Helper()
+				if got, _ := browser.Evaluate(probe); got != want {
+					t.Errorf("media = %v, want %q", got, want)
+				}
+			}
+
+			if _, err := browser.EmulateMedia(agentbrowser.MediaEmulation{ColorScheme: "dark", Media: "print"}); err != nil {
+				t.Fatalf("EmulateMedia() error = %v", err)
+			}
+			check("true true false none")
+			if _, err := browser.EmulateMedia(agentbrowser.MediaEmulation{Media: "no-override", ReducedMotion: "reduce"}); err != nil {
+				t.Fatalf("EmulateMedia() error = %v", err)
+			}
+			check("true false true block")
+
+			// Tabs opened later get the emulation too
+			if _, err := browser.NewTab(server.URL + "/"); err != nil {
+				t.Fatalf("NewTab() error = %v", err)
+			}
+			check("true false true block")
+
+			if _, err := browser.EmulateMedia(agentbrowser.MediaEmulation{ColorScheme: "light", ReducedMotion: "no-override"}); err != nil {
+				t.Fatalf("EmulateMedia() error = %v", err)
+			}
+			check("false false false block")
+		})
+	}
+}
+
+// TestBackend_Geolocation tests the geolocation override for all backends
+func TestBackend_Geolocation(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	// Geolocation needs a secure context; localhost is one
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<p>map</p>`)
+	}))
+	defer server.Close()
+
+	const position = `new Promise((resolve, reject) => navigator.geolocation.getCurrentPosition(
+		p => resolve(p.coords.latitude + "," + p.coords.longitude + "," + p.coords.accuracy),
+		e => reject(new Error(e.message))))`
+
+	for _, tt := range testBackends() {
+		t.Run(tt.name, func(t *testing.T) {
+			browser := agentbrowser.NewBrowserManagerWithBackend(tt.backend)
+			defer browser.Close()
+
+			if err := browser.Launch(agentbrowser.LaunchOptions{Headless: true}); err != nil {
+				t.Fatalf("Launch() error = %v", err)
+			}
+			if err := browser.SetGeolocation(&agentbrowser.Geolocation{Latitude: 51.5, Longitude: -0.125, Accuracy: 20}); err != nil {
+				t.Fatalf("SetGeolocation() error = %v", err)
+			}
+			if _, _, err := browser.Navigate(server.URL+"/", "load"); err != nil {
+				t.Fatalf("Navigate() error = %v", err)
+			}
+
+			got, err := browser.Evaluate(position)
+			if err != nil {
+				t.Fatalf("Evaluate() error = %v", err)
+			}
+			if got != "51.5,-0.125,20" {
+				t.Errorf("position = %v, want 51.5,-0.125,20", got)
+			}
+
+			// Tabs opened later get the position too
+			if _, err := browser.NewTab(server.URL + "/"); err != nil {
+				t.Fatalf("NewTab() error = %v", err)
+			}
+			if got, err := browser.Evaluate(position); err != nil || got != "51.5,-0.125,20" {
+				t.Errorf("new tab position = %v, %v, want 51.5,-0.125,20", got, err)
+			}
+		})
+	}
+}
+
+// TestBackend_Permissions tests granting and resetting permissions for all
+// backends
+func TestBackend_Permissions(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<p>app</p>`)
+	}))
+	defer server.Close()
+
+	for _, tt := range testBackends() {
+		t.Run(tt.name, func(t *testing.T) {
+			browser := agentbrowser.NewBrowserManagerWithBackend(tt.backend)
+			defer browser.Close()
+
+			if err := browser.Launch(agentbrowser.LaunchOptions{Headless: true}); err != nil {
+				t.Fatalf("Launch() error = %v", err)
+			}
+			if _, _, err := browser.Navigate(server.URL+"/", "load"); err != nil {
+				t.Fatalf("Navigate() error = %v", err)
+			}
+
+			state := func(name string) interface{} {
+				got, err := browser.Evaluate(`navigator.permissions.query({name: "` + name + `"}).then(s => s.state)`)
+				if err != nil {
+					t.Fatalf("permissions.query(%s) error = %v", name, err)
+				}
+				return got
+			}
+
+			if err := browser.GrantPermissions([]string{"notifications", "geolocation"}, server.URL); err != nil {
+				t.Fatalf("GrantPermissions() error = %v", err)
+			}
+			if got := state("notifications"); got != "granted" {
+				t.Errorf("notifications = %v, want granted", got)
+			}
+
+			if err := browser.DenyPermissions([]string{"notifications"}, ""); err != nil {
+				t.Fatalf("DenyPermissions() error = %v", err)
+			}
+			if got := state("notifications"); got == "granted" {
+				t.Errorf("notifications still granted after DenyPermissions()")
+			}
+			if got := state("geolocation"); got != "granted" {
+				t.Errorf("geolocation = %v after denying notifications, want granted", got)
+			}
+
+			if err := browser.ResetPermissions(); err != nil {
+				t.Fatalf("ResetPermissions() error = %v", err)
+			}
+			if got := state("geolocation"); got == "granted" {
+				t.Errorf("geolocation still granted after ResetPermissions()")
+			}
+		})
+	}
+}
+
+// TestBackend_PDF tests printing to PDF for all backends
+func TestBackend_PDF(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	for _, tt := range testBackends() {
+		t.Run(tt.name, func(t *testing.T) {
+			browser := agentbrowser.NewBrowserManagerWithBackend(tt.backend)
+			defer browser.Close()
+
+			if err := browser.Launch(agentbrowser.LaunchOptions{Headless: true}); err != nil {
+				t.Fatalf("Launch() error = %v", err)
+			}
+			if err := browser.SetContent(`<h1>Invoice</h1>`); err != nil {
+				t.Fatalf("SetContent() error = %v", err)
+			}
+
+			margin, _ := agentbrowser.ParsePDFMargin("1cm")
+			buf, err := browser.PDF(agentbrowser.PDFOptions{Format: "A4", Landscape: true, Margin: margin})
+			if err != nil {
+				t.Fatalf("PDF() error = %v", err)
+			}
+			if !strings.HasPrefix(string(buf), "%PDF-") {
+				t.Errorf("PDF() = %q..., want a PDF", buf[:min(len(buf), 16)])
+			}
+		})
+	}
+}
+
+// TestBackend_Upload tests setting the files of a file input for all backends
+func TestBackend_Upload(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	dir := t.TempDir()
+	var files []string
+	for _, name := range []string{"a.txt", "b.txt"} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+		files = append(files, path)
+	}
+
+	for _, tt := range testBackends() {
+		t.Run(tt.name, func(t *testing.T) {
+			browser := agentbrowser.NewBrowserManagerWithBackend(tt.backend)
+			defer browser.Close()
+
+			if err := browser.Launch(agentbrowser.LaunchOptions{Headless: true}); err != nil {
+				t.Fatalf("Launch() error = %v", err)
+			}
+			if err := browser.SetContent(`<input type="file" id="f" multiple onchange="document.title = 'changed'">`); err != nil {
+				t.Fatalf("SetContent() error = %v", err)
+			}
+			if err := browser.Upload("#f", files); err != nil {
+				t.Fatalf("Upload() error = %v", err)
+			}
+
+			got, err := browser.Evaluate(`[...document.querySelector('#f').files].map(f => f.name).join(',') + '|' + document.title`)
+			if err != nil {
+				t.Fatalf("Evaluate() error = %v", err)
+			}
+			if got != "a.txt,b.txt|changed" {
+				t.Errorf("input files = %v, want a.txt,b.txt|changed", got)
+			}
+		})
+	}
+}
+
+// TestBackend_Frames tests scoping selectors and scripts to iframes for all backends
+func TestBackend_Frames(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/inner":
+			fmt.Fprint(w, `<p id="where">inner</p><input id="card"><script>var frameVar = "inner"</script>`)
+		default:
+			fmt.Fprint(w, `<p id="where">outer</p><iframe id="pay" name="payment" src="/inner"></iframe>`)
+		}
+	}))
+	defer server.Close()
+
+	for _, tt := range testBackends() {
+		t.Run(tt.name, func(t *testing.T) {
+			browser := agentbrowser.NewBrowserManagerWithBackend(tt.backend)
+			defer browser.Close()
+
+			if err := browser.Launch(agentbrowser.LaunchOptions{Headless: true}); err != nil {
+				t.Fatalf("Launch() error = %v", err)
+			}
+			if _, _, err := browser.Navigate(server.URL+"/", "load"); err != nil {
+				t.Fatalf("Navigate() error = %v", err)
+			}
+
+			where := func() string {
+				text, err := browser.GetText("#where")
+				if err != nil {
+					t.Fatalf("GetText() error = %v", err)
+				}
+				return text
+			}
+
+			if err := browser.SwitchFrame(agentbrowser.FrameRef{Selector: "#pay"}); err != nil {
+				t.Fatalf("SwitchFrame(selector) error = %v", err)
+			}
+			if got := where(); got != "inner" {
+				t.Errorf("text in frame = %q, want inner", got)
+			}
+			if err := browser.Fill("#card", "4242"); err != nil {
+				t.Fatalf("Fill() in frame error = %v", err)
+			}
+			got, err := browser.Evaluate(`frameVar + ":" + document.querySelector("#card").value`)
+			if err != nil || got != "inner:4242" {
+				t.Errorf("Evaluate() in frame = %v, %v, want inner:4242", got, err)
+			}
+
+			if err := browser.MainFrame(); err != nil {
+				t.Fatalf("MainFrame() error = %v", err)
+			}
+			if got := where(); got != "outer" {
+				t.Errorf("text after mainframe = %q, want outer", got)
+			}
+
+			for _, ref := range []agentbrowser.FrameRef{{Name: "payment"}, {URL: "/inner"}} {
+				if err := browser.SwitchFrame(ref); err != nil {
+					t.Fatalf("SwitchFrame(%v) error = %v", ref, err)
+				}
+				if got := where(); got != "inner" {
+					t.Errorf("text in frame %v = %q, want inner", ref, got)
+				}
+			}
+			if err := browser.SwitchFrame(agentbrowser.FrameRef{Name: "missing"}); err == nil {
+				t.Error("SwitchFrame(missing) expected an error")
+			}
+
+			// Navigating away detaches the frame
+			if _, _, err := browser.Navigate(server.URL+"/inner", "load"); err != nil {
+				t.Fatalf("Navigate() error = %v", err)
+			}
+			if _, _, err := browser.Navigate(server.URL+"/", "load"); err != nil {
+				t.Fatalf("Navigate() error = %v", err)
+			}
+			if got := where(); got != "outer" {
+				t.Errorf("text after navigation = %q, want outer", got)
+			}
+		})
+	}
+}
+
+// TestBackend_History tests listing and jumping through history for all backends
+func TestBackend_History(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "<title>page %s</title>", r.URL.Path)
+	}))
+	defer server.Close()
+
+	for _, tt := range testBackends() {
+		t.Run(tt.name, func(t *testing.T) {
+			browser := agentbrowser.NewBrowserManagerWithBackend(tt.backend)
+			defer browser.Close()
+
+			if err := browser.Launch(agentbrowser.LaunchOptions{Headless: true}); err != nil {
+				t.Fatalf("Launch() error = %v", err)
+			}
+			for _, path := range []string{"/a", "/b", "/c"} {
+				if _, _, err := browser.Navigate(server.URL+path, "load"); err != nil {
+					t.Fatalf("Navigate() error = %v", err)
+				}
+			}
+
+			history, err := browser.History()
+			if err != nil {
+				t.Fatalf("History() error = %v", err)
+			}
+			last := len(history.Entries) - 1
+			if history.Current != last || history.Entries[last].Title != "page /c" {
+				t.Fatalf("History() = %+v, want /c current", history)
+			}
+			first := last - 2
+			if history.Entries[first].URL != server.URL+"/a" {
+				t.Fatalf("History() entry %d = %+v, want /a", first, history.Entries[first])
+			}
+
+			if err := browser.HistoryGo(first); err != nil {
+				t.Fatalf("HistoryGo() error = %v", err)
+			}
+			if title, _ := browser.Title(); title != "page /a" {
+				t.Errorf("Title() = %q after HistoryGo, want page /a", title)
+			}
+			if history, _ := browser.History(); history == nil || history.Current != first {
+				t.Errorf("History().Current = %+v, want %d", history, first)
+			}
+		})
+	}
+}
+
+// TestBackend_Tabs tests tab management for all backends
+func TestBackend_Tabs(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	for _, tt := range testBackends() {
+		t.Run(tt.name, func(t *testing.T) {
+			browser := agentbrowser.NewBrowserManagerWithBackend(tt.backend)
+			defer browser.Close()
+
+			err := browser.Launch(agentbrowser.LaunchOptions{Headless: true})
+			if err != nil {
+				t.Fatalf("Launch() error = %v", err)
+			}
+
+			// Create new tab
+			index, err := browser.NewTab("")
+			if err != nil {
+				t.Fatalf("NewTab() error = %v", err)
+			}
+
+			if index != 1 {
+				t.Errorf("expected new tab index 1, got %d", index)
+			}
+
+			// List tabs
+			tabs, err := browser.ListTabs()
+			if err != nil {
+				t.Fatalf("ListTabs() error = %v", err)
+			}
+
+			if len(tabs) != 2 {
+				t.Errorf("expected 2 tabs, got %d", len(tabs))
+			}
+
+			// Close tab
+			err = browser.CloseTab(1)
+			if err != nil {
+				t.Fatalf("CloseTab() error = %v", err)
+			}
+
+			tabs, err = browser.ListTabs()
+			if err != nil {
+				t.Fatalf("ListTabs() error = %v", err)
+			}
+
+			if len(tabs) != 1 {
+				t.Errorf("expected 1 tab after close, got %d", len(tabs))
+			}
+		})
+	}
+}
+
+func TestBackend_Screencast(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<p id="n">0</p><script>setInterval(() => n.textContent++, 50)</script>`)
+	}))
+	defer server.Close()
+
+	for _, tt := range testBackends() {
+		t.Run(tt.name, func(t *testing.T) {
+			browser := agentbrowser.NewBrowserManagerWithBackend(tt.backend)
+			defer browser.Close()
+
+			if err := browser.Launch(agentbrowser.LaunchOptions{Headless: true}); err != nil {
+				t.Fatalf("Launch() error = %v", err)
+			}
+			if _, _, err := browser.Navigate(server.URL, "load"); err != nil {
+				t.Fatalf("Navigate() error = %v", err)
+			}
+			dir := t.TempDir()
+			if _, err := browser.StartScreencast(agentbrowser.ScreencastOptions{Dir: dir}); err != nil {
+				t.Fatalf("StartScreencast() error = %v", err)
+			}
+			time.Sleep(time.Second)
+
+			result, err := browser.StopScreencast()
+			if err != nil {
+				t.Fatalf("StopScreencast() error = %v", err)
+			}
+			if result.Frames == 0 {
+				t.Fatal("StopScreencast() reported no frames")
+			}
+			if _, err := os.Stat(filepath.Join(dir, "frame-000001.jpg")); err != nil {
+				t.Errorf("first frame not written: %v", err)
+			}
+		})
+	}
+}
+
+func TestBackend_InputMouse(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<canvas id="c" width="400" height="300" style="position:fixed;left:0;top:0"></canvas>
+<script>
+window.log = [];
+for (const type of ['mousedown', 'mousemove', 'mouseup', 'contextmenu']) {
+  c.addEventListener(type, e => log.push(type + ' ' + e.clientX + ',' + e.clientY + ' ' + e.buttons + (e.shiftKey ? ' shift' : '')));
+}
+</script>`)
+	}))
+	defer server.Close()
+
+	for _, tt := range testBackends() {
+		t.Run(tt.name, func(t *testing.T) {
+			browser := agentbrowser.NewBrowserManagerWithBackend(tt.backend)
+			defer browser.Close()
+
+			if err := browser.Launch(agentbrowser.LaunchOptions{Headless: true}); err != nil {
+				t.Fatalf("Launch() error = %v", err)
+			}
+			if _, _, err := browser.Navigate(server.URL, "load"); err != nil {
+				t.Fatalf("Navigate() error = %v", err)
+			}
+			for _, ev := range []agentbrowser.MouseEvent{
+				{Type: "down", X: 10, Y: 20},
+				{Type: "move", X: 50, Y: 60, Modifiers: 8},
+				{Type: "up", X: 50, Y: 60},
+			} {
+				if err := browser.InputMouse(ev); err != nil {
+					t.Fatalf("InputMouse(%+v) error = %v", ev, err)
+				}
+			}
+
+			log, err := browser.Evaluate("log.filter(e => !e.startsWith('mousemove 10,20')).join('; ')")
+			if err != nil {
+				t.Fatalf("Evaluate() error = %v", err)
+			}
+			if want := "mousedown 10,20 1; mousemove 50,60 1 shift; mouseup 50,60 0"; log != want {
+				t.Errorf("events = %q, want %q", log, want)
+			}
+		})
+	}
+}
+
+func TestBackend_InputKeyboard(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<input id="field" autofocus>
+<script>
+window.log = [];
+for (const type of ['keydown', 'keyup']) {
+  field.addEventListener(type, e => log.push(type + ' ' + e.key + ' ' + e.code + (e.repeat ? ' repeat' : '')));
+}
+</script>`)
+	}))
+	defer server.Close()
+
+	for _, tt := range testBackends() {
+		t.Run(tt.name, func(t *testing.T) {
+			browser := agentbrowser.NewBrowserManagerWithBackend(tt.backend)
+			defer browser.Close()
+
+			if err := browser.Launch(agentbrowser.LaunchOptions{Headless: true}); err != nil {
+				t.Fatalf("Launch() error = %v", err)
+			}
+			if _, _, err := browser.Navigate(server.URL, "load"); err != nil {
+				t.Fatalf("Navigate() error = %v", err)
+			}
+			if err := browser.Focus("#field"); err != nil {
+				t.Fatalf("Focus() error = %v", err)
+			}
+			for _, ev := range []agentbrowser.KeyEvent{
+				{Type: "keyDown", Key: "Shift"},
+				{Type: "keyDown", Key: "a"},
+				{Type: "keyUp", Key: "a"},
+				{Type: "keyUp", Key: "Shift"},
+				{Type: "char", Text: "é"},
+			} {
+				if err := browser.InputKeyboard(ev); err != nil {
+					t.Fatalf("InputKeyboard(%+v) error = %v", ev, err)
+				}
+			}
+
+			value, err := browser.GetInputValue("#field")
+			if err != nil {
+				t.Fatalf("GetInputValue() error = %v", err)
+			}
+			if value != "Aé" {
+				t.Errorf("value = %q, want %q", value, "Aé")
+			}
+			log, err := browser.Evaluate("log.join('; ')")
+			if err != nil {
+				t.Fatalf("Evaluate() error = %v", err)
+			}
+			if want := "keydown Shift ShiftLeft; keydown A KeyA; keyup A KeyA; keyup Shift ShiftLeft"; log != want {
+				t.Errorf("events = %q, want %q", log, want)
+			}
+		})
+	}
+}
+
+func TestBackend_Clipboard(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<input id="plain"><div id="editor" contenteditable></div>
+<script>
+editor.addEventListener('paste', e => {
+  e.preventDefault();
+  editor.textContent = '[' + e.clipboardData.getData('text/plain') + ']';
+});
+</script>`)
+	}))
+	defer server.Close()
+
+	for _, tt := range testBackends() {
+		t.Run(tt.name, func(t *testing.T) {
+			browser := agentbrowser.NewBrowserManagerWithBackend(tt.backend)
+			defer browser.Close()
+
+			if err := browser.Launch(agentbrowser.LaunchOptions{Headless: true}); err != nil {
+				t.Fatalf("Launch() error = %v", err)
+			}
+			if _, _, err := browser.Navigate(server.URL, "load"); err != nil {
+				t.Fatalf("Navigate() error = %v", err)
+			}
+			if err := browser.ClipboardCopy("héllo"); err != nil {
+				t.Fatalf("ClipboardCopy() error = %v", err)
+			}
+			if text, err := browser.ClipboardRead(); err != nil || text != "héllo" {
+				t.Errorf("ClipboardRead() = %q, %v; want héllo", text, err)
+			}
+
+			if _, err := browser.ClipboardPaste("#plain"); err != nil {
+				t.Fatalf("ClipboardPaste() error = %v", err)
+			}
+			if value, _ := browser.GetInputValue("#plain"); value != "héllo" {
+				t.Errorf("input value = %q, want the pasted text", value)
+			}
+			if _, err := browser.ClipboardPaste("#editor"); err != nil {
+				t.Fatalf("ClipboardPaste() error = %v", err)
+			}
+			if text, _ := browser.GetText("#editor"); text != "[héllo]" {
+				t.Errorf("editor text = %q, want the page's paste handler to run", text)
+			}
+		})
+	}
+}
+
+func TestBackend_InsertText(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<input id="field" value="> ">
+<script>
+window.keys = 0;
+window.inputs = 0;
+field.addEventListener('keydown', () => keys++);
+field.addEventListener('input', () => inputs++);
+</script>`)
+	}))
+	defer server.Close()
+
+	for _, tt := range testBackends() {
+		t.Run(tt.name, func(t *testing.T) {
+			browser := agentbrowser.NewBrowserManagerWithBackend(tt.backend)
+			defer browser.Close()
+
+			if err := browser.Launch(agentbrowser.LaunchOptions{Headless: true}); err != nil {
+				t.Fatalf("Launch() error = %v", err)
+			}
+			if _, _, err := browser.Navigate(server.URL, "load"); err != nil {
+				t.Fatalf("Navigate() error = %v", err)
+			}
+			if err := browser.InsertText("#field", "こんにちは 👋"); err != nil {
+				t.Fatalf("InsertText() error = %v", err)
+			}
+			if err := browser.InsertText("", "!"); err != nil {
+				t.Fatalf("InsertText() into the focused element error = %v", err)
+			}
+
+			value, err := browser.GetInputValue("#field")
+			if err != nil {
+				t.Fatalf("GetInputValue() error = %v", err)
+			}
+			if want := "> こんにちは 👋!"; value != want {
+				t.Errorf("value = %q, want %q", value, want)
+			}
+			counts, err := browser.Evaluate("keys + ' ' + inputs")
+			if err != nil {
+				t.Fatalf("Evaluate() error = %v", err)
+			}
+			if counts != "0 2" {
+				t.Errorf("keydown and input events = %v, want 0 2", counts)
+			}
+		})
+	}
+}
+
+func TestBackend_MouseDrag(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<div id="track" style="position:fixed;left:0;top:0;width:400px;height:40px"></div>
+<script>
+window.moves = 0;
+window.value = 0;
+let dragging = false;
+track.addEventListener('mousedown', () => dragging = true);
+document.addEventListener('mousemove', e => { if (dragging) { moves++; value = e.clientX; } });
+document.addEventListener('mouseup', () => dragging = false);
+</script>`)
+	}))
+	defer server.Close()
+
+	for _, tt := range testBackends() {
+		t.Run(tt.name, func(t *testing.T) {
+			browser := agentbrowser.NewBrowserManagerWithBackend(tt.backend)
+			defer browser.Close()
+
+			if err := browser.Launch(agentbrowser.LaunchOptions{Headless: true}); err != nil {
+				t.Fatalf("Launch() error = %v", err)
+			}
+			if _, _, err := browser.Navigate(server.URL, "load"); err != nil {
+				t.Fatalf("Navigate() error = %v", err)
+			}
+			if err := browser.MouseMove(10, 20, 1); err != nil {
+				t.Fatalf("MouseMove() error = %v", err)
+			}
+			if err := browser.MouseDown(""); err != nil {
+				t.Fatalf("MouseDown() error = %v", err)
+			}
+			if err := browser.MouseMove(250, 20, 5); err != nil {
+				t.Fatalf("MouseMove() error = %v", err)
+			}
+			if err := browser.MouseUp(""); err != nil {
+				t.Fatalf("MouseUp() error = %v", err)
+			}
+			if err := browser.MouseMove(300, 20, 1); err != nil {
+				t.Fatalf("MouseMove() error = %v", err)
+			}
+
+			result, err := browser.Evaluate("moves + ' ' + value")
+			if err != nil {
+				t.Fatalf("Evaluate() error = %v", err)
+			}
+			if result != "5 250" {
+				t.Errorf("drag moves and value = %v, want 5 250", result)
+			}
+		})
+	}
+}
+
+func TestBackend_Wheel(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<div id="list" style="height:200px;overflow:auto"><div style="height:5000px"></div></div>
+<script>
+window.wheels = 0;
+list.addEventListener('wheel', () => wheels++);
+</script>`)
+	}))
+	defer server.Close()
+
+	for _, tt := range testBackends() {
+		t.Run(tt.name, func(t *testing.T) {
+			browser := agentbrowser.NewBrowserManagerWithBackend(tt.backend)
+			defer browser.Close()
+
+			if err := browser.Launch(agentbrowser.LaunchOptions{Headless: true}); err != nil {
+				t.Fatalf("Launch() error = %v", err)
+			}
+			if _, _, err := browser.Navigate(server.URL, "load"); err != nil {
+				t.Fatalf("Navigate() error = %v", err)
+			}
+			if err := browser.Wheel(0, 400, "#list"); err != nil {
+				t.Fatalf("Wheel() error = %v", err)
+			}
+
+			// The wheel scrolls asynchronously
+			var result interface{}
+			for i := 0; i < 20; i++ {
+				var err error
+				if result, err = browser.Evaluate("wheels + ' ' + (list.scrollTop > 0)"); err != nil {
+					t.Fatalf("Evaluate() error = %v", err)
+				}
+				if result == "1 true" {
+					break
+				}
+				time.Sleep(50 * time.Millisecond)
+			}
+			if result != "1 true" {
+				t.Errorf("wheel events and scrolled = %v, want 1 true", result)
+			}
+		})
+	}
+}
+
+func TestBackend_GetByRole(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<button onclick="clicked = 'register'">Register</button>
+<button onclick="clicked = 'sign in'">Sign in</button>
+<div role="button" aria-label="Close dialog" onclick="clicked = 'close'">x</div>
+<label>Email <input type="email"></label>
+<script>window.clicked = ''</script>`)
+	}))
+	defer server.Close()
+
+	for _, tt := range testBackends() {
+		t.Run(tt.name, func(t *testing.T) {
+			browser := agentbrowser.NewBrowserManagerWithBackend(tt.backend)
+			defer browser.Close()
+
+			if err := browser.Launch(agentbrowser.LaunchOptions{Headless: true}); err != nil {
+				t.Fatalf("Launch() error = %v", err)
+			}
+			if _, _, err := browser.Navigate(server.URL, "load"); err != nil {
+				t.Fatalf("Navigate() error = %v", err)
+			}
+
+			for _, c := range []struct {
+				loc  agentbrowser.Locator
+				want string
+			}{
+				{agentbrowser.Locator{By: "role", Role: "button", Text: "sign"}, "sign in"},
+				{agentbrowser.Locator{By: "role", Role: "button", Text: "close"}, "close"},
+				{agentbrowser.Locator{By: "role", Role: "button"}, "register"},
+			} {
+				sel, err := browser.Locate(c.loc)
+				if err != nil {
+					t.Fatalf("Locate(%s) error = %v", c.loc, err)
+				}
+				if err := browser.Click(sel); err != nil {
+					t.Fatalf("Click(%s) error = %v", sel, err)
+				}
+				if clicked, _ := browser.Evaluate("clicked"); clicked != c.want {
+					t.Errorf("Locate(%s) clicked %v, want %s", c.loc, clicked, c.want)
+				}
+			}
+
+			sel, err := browser.Locate(agentbrowser.Locator{By: "role", Role: "textbox", Text: "Email"})
+			if err != nil {
+				t.Fatalf("Locate(textbox) error = %v", err)
+			}
+			if err := browser.Fill(sel, "test@example.com"); err != nil {
+				t.Fatalf("Fill() error = %v", err)
+			}
+			if value, _ := browser.Evaluate("document.querySelector('input').value"); value != "test@example.com" {
+				t.Errorf("value = %v, want test@example.com", value)
+			}
+
+			_, err = browser.Locate(agentbrowser.Locator{By: "role", Role: "button", Text: "sign", Exact: true})
+			if err == nil || !strings.Contains(err.Error(), "element not found") {
+				t.Errorf("Locate(exact) error = %v, want element not found", err)
+			}
+		})
+	}
+}
+
+func TestBackend_GetByText(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<p style="display:none"><a onclick="clicked = 'hidden'">Sign in</a></p>
+<p>Already have an account? <a href="#" onclick="clicked = 'link'">Sign  in</a></p>
+<input type="submit" value="Sign in now" onclick="clicked = 'submit'">
+<script>window.clicked = ''</script>`)
+	}))
+	defer server.Close()
+
+	for _, tt := range testBackends() {
+		t.Run(tt.name, func(t *testing.T) {
+			browser := agentbrowser.NewBrowserManagerWithBackend(tt.backend)
+			defer browser.Close()
+
+			if err := browser.Launch(agentbrowser.LaunchOptions{Headless: true}); err != nil {
+				t.Fatalf("Launch() error = %v", err)
+			}
+			if _, _, err := browser.Navigate(server.URL, "load"); err != nil {
+				t.Fatalf("Navigate() error = %v", err)
+			}
+
+			for _, c := range []struct {
+				loc  agentbrowser.Locator
+				want string
+			}{
+				{agentbrowser.Locator{By: "text", Text: "sign in"}, "link"},
+				{agentbrowser.Locator{By: "text", Text: "Sign in now", Exact: true}, "submit"},
+			} {
+				sel, err := browser.Locate(c.loc)
+				if err != nil {
+					t.Fatalf("Locate(%s) error = %v", c.loc, err)
+				}
+				if err := browser.Click(sel); err != nil {
+					t.Fatalf("Click(%s) error = %v", sel, err)
+				}
+				if clicked, _ := browser.Evaluate("clicked"); clicked != c.want {
+					t.Errorf("Locate(%s) clicked %v, want %s", c.loc, clicked, c.want)
+				}
+			}
+
+			_, err := browser.Locate(agentbrowser.Locator{By: "text", Text: "sign in", Exact: true})
+			if err == nil || !strings.Contains(err.Error(), "element not found") {
+				t.Errorf("Locate(exact) error = %v, want element not found", err)
+			}
+		})
+	}
+}
+
+func TestBackend_GetByLabel(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<label for="email">Email address</label> <input id="email">
+<label>Country <select id="country"><option>France</option><option>Japan</option></select></label>
+<span id="pw-label">Password</span> <input id="password" type="password" aria-labelledby="pw-label">
+<input id="search" aria-label="Search the site">
+<label><input id="terms" type="checkbox"> I agree</label>`)
+	}))
+	defer server.Close()
+
+	for _, tt := range testBackends() {
+		t.Run(tt.name, func(t *testing.T) {
+			browser := agentbrowser.NewBrowserManagerWithBackend(tt.backend)
+			defer browser.Close()
+
+			if err := browser.Launch(agentbrowser.LaunchOptions{Headless: true}); err != nil {
+				t.Fatalf("Launch() error = %v", err)
+			}
+			if _, _, err := browser.Navigate(server.URL, "load"); err != nil {
+				t.Fatalf("Navigate() error = %v", err)
+			}
+
+			for _, c := range []struct {
+				label string
+				exact bool
+				id    string
+			}{
+				{"email", false, "email"},
+				{"country", false, "country"},
+				{"Password", true, "password"},
+				{"search", false, "search"},
+				{"I agree", true, "terms"},
+			} {
+				sel, err := browser.Locate(agentbrowser.Locator{By: "label", Text: c.label, Exact: c.exact})
+				if err != nil {
+					t.Fatalf("Locate(label %q) error = %v", c.label, err)
+				}
+				if id, _ := browser.GetAttribute(sel, "id"); id != c.id {
+					t.Errorf("Locate(label %q) found #%s, want #%s", c.label, id, c.id)
+				}
+			}
+
+			sel, err := browser.Locate(agentbrowser.Locator{By: "label", Text: "Email"})
+			if err != nil {
+				t.Fatalf("Locate() error = %v", err)
+			}
+			if err := browser.Fill(sel, "test@example.com"); err != nil {
+				t.Fatalf("Fill() error = %v", err)
+			}
+			if value, _ := browser.Evaluate("email.value"); value != "test@example.com" {
+				t.Errorf("value = %v, want test@example.com", value)
+			}
+		})
+	}
+}
+
+func TestBackend_GetByAttribute(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<input id="search" placeholder="Search products">
+<img id="logo" alt="Company logo" src="data:image/gif;base64,R0lGODlhAQABAAAAACw=" width="10" height="10">
+<button id="settings" title="Settings">&#9881;</button>`)
+	}))
+	defer server.Close()
+
+	for _, tt := range testBackends() {
+		t.Run(tt.name, func(t *testing.T) {
+			browser := agentbrowser.NewBrowserManagerWithBackend(tt.backend)
+			defer browser.Close()
+
+			if err := browser.Launch(agentbrowser.LaunchOptions{Headless: true}); err != nil {
+				t.Fatalf("Launch() error = %v", err)
+			}
+			if _, _, err := browser.Navigate(server.URL, "load"); err != nil {
+				t.Fatalf("Navigate() error = %v", err)
+			}
+
+			for _, c := range []struct {
+				loc agentbrowser.Locator
+				id  string
+			}{
+				{agentbrowser.Locator{By: "placeholder", Text: "search"}, "search"},
+				{agentbrowser.Locator{By: "alt", Text: "Company logo", Exact: true}, "logo"},
+				{agentbrowser.Locator{By: "title", Text: "settings"}, "settings"},
+			} {
+				sel, err := browser.Locate(c.loc)
+				if err != nil {
+					t.Fatalf("Locate(%s) error = %v", c.loc, err)
+				}
+				if id, _ := browser.GetAttribute(sel, "id"); id != c.id {
+					t.Errorf("Locate(%s) found #%s, want #%s", c.loc, id, c.id)
+				}
+			}
+
+			_, err := browser.Locate(agentbrowser.Locator{By: "alt", Text: "company logo", Exact: true})
+			if err == nil || !strings.Contains(err.Error(), "element not found") {
+				t.Errorf("Locate(exact) error = %v, want element not found", err)
+			}
+		})
+	}
+}
+
+func TestBackend_GetByTestId(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<button id="a" data-testid="submit-order-now">Later</button>
+<button id="b" data-testid="submit-order">Order</button>
+<button id="c" data-cy="submit-order">Order</button>`)
+	}))
+	defer server.Close()
+
+	for _, tt := range testBackends() {
+		t.Run(tt.name, func(t *testing.T) {
+			browser := agentbrowser.NewBrowserManagerWithBackend(tt.backend)
+			defer browser.Close()
+
+			if err := browser.Launch(agentbrowser.LaunchOptions{Headless: true}); err != nil {
+				t.Fatalf("Launch() error = %v", err)
+			}
+			if _, _, err := browser.Navigate(server.URL, "load"); err != nil {
+				t.Fatalf("Navigate() error = %v", err)
+			}
+
+			for _, c := range []struct {
+				attr string
+				id   string
+			}{
+				{"", "b"},
+				{"data-cy", "c"},
+			} {
+				if _, err := browser.ApplySessionConfig(agentbrowser.SessionConfig{TestIDAttribute: c.attr}); err != nil {
+					t.Fatalf("ApplySessionConfig() error = %v", err)
+				}
+				sel, err := browser.Locate(agentbrowser.Locator{By: "testid", Text: "submit-order"})
+				if err != nil {
+					t.Fatalf("Locate(%q) error = %v", c.attr, err)
+				}
+				if id, _ := browser.GetAttribute(sel, "id"); id != c.id {
+					t.Errorf("Locate(%q) found #%s, want #%s", c.attr, id, c.id)
+				}
+			}
+		})
+	}
+}
+
+func TestBackend_NewWindow(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	for _, tt := range testBackends() {
+		t.Run(tt.name, func(t *testing.T) {
+			browser := agentbrowser.NewBrowserManagerWithBackend(tt.backend)
+			defer browser.Close()
+
+			if err := browser.Launch(agentbrowser.LaunchOptions{Headless: true}); err != nil {
+				t.Fatalf("Launch() error = %v", err)
+			}
+			index, err := browser.NewWindow(&agentbrowser.Viewport{Width: 390, Height: 844})
+			if err != nil {
+				t.Fatalf("NewWindow() error = %v", err)
+			}
+			if index != 1 {
+				t.Errorf("NewWindow() index = %d, want 1", index)
+			}
+
+			size, err := browser.Evaluate("innerWidth + 'x' + innerHeight")
+			if err != nil {
+				t.Fatalf("Evaluate() error = %v", err)
+			}
+			if size != "390x844" {
+				t.Errorf("viewport = %v, want 390x844", size)
+			}
+
+			tabs, err := browser.ListTabs()
+			if err != nil {
+				t.Fatalf("ListTabs() error = %v", err)
+			}
+			if len(tabs) != 2 || !tabs[1].Active {
+				t.Fatalf("tabs = %+v, want the new window's tab active", tabs)
+			}
+			if tabs[0].Window == 0 || tabs[1].Window == 0 || tabs[0].Window == tabs[1].Window {
+				t.Errorf("windows = %d and %d, want two different windows", tabs[0].Window, tabs[1].Window)
 			}
 		})
 	}
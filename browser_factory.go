@@ -1,5 +1,12 @@
 package agentbrowser
 
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
 // NewBrowser creates a browser backend based on the specified type.
 func NewBrowser(backendType BackendType) BrowserBackend {
 	switch backendType {
@@ -15,6 +22,53 @@ func NewBrowser(backendType BackendType) BrowserBackend {
 // BrowserManager wraps a backend for backward compatibility.
 type BrowserManager struct {
 	backend BrowserBackend
+
+	// Refs from the previous snapshot, for matching stale refs
+	refLock      sync.Mutex
+	prevRefs     RefMap
+	retargetRefs bool
+
+	// Commands that timed out but still run, which later commands wait for
+	abandonLock sync.Mutex
+	abandoned   []abandonedCommand
+
+	// User-agent rotation
+	uaLock     sync.Mutex
+	uaRotation *UARotation
+	uaIndex    int
+	uaUnused   bool // the current user agent has not been used by a navigation
+
+	// Polite mode and the robots.txt files fetched for it, by origin
+	robotsLock sync.Mutex
+	polite     bool
+	robots     map[string]*Robots
+
+	// Default LaunchOptions.LogDir, and the options LaunchCommand starts from
+	logDir         string
+	launchDefaults *LaunchOptions
+
+	headless bool // LaunchOptions.Headless of the running browser
+
+	// Credentials set with SetHTTPCredentials, kept across relaunches
+	httpCredentials *HTTPCredentials
+
+	media MediaEmulation // set with EmulateMedia
+
+	// Session config settings in effect
+	session sessionSettings
+
+	// Mouse position, and mouse buttons and modifier keys held down, from
+	// InputMouse and InputKeyboard
+	inputLock      sync.Mutex
+	mouseX, mouseY float64
+	mouseButtons   int
+	heldModifiers  int
+
+	// Screencast frames and the events they are sent as, and the video
+	// recorded from them
+	screencast screencastRecorder
+	recording  videoRecorder
+	events     eventEmitter
 }
 
 // NewBrowserManager creates a new browser manager with chromedp backend (default).
@@ -25,14 +79,37 @@ func NewBrowserManager() *BrowserManager {
 // NewBrowserManagerWithBackend creates a browser manager with the specified backend.
 func NewBrowserManagerWithBackend(backendType BackendType) *BrowserManager {
 	return &BrowserManager{
-		backend: NewBrowser(backendType),
+		backend:      NewBrowser(backendType),
+		retargetRefs: os.Getenv("AGENT_BROWSER_REF_RETARGET") == "1",
 	}
 }
 
 // Lifecycle methods - delegate to backend
 
 func (m *BrowserManager) Launch(opts LaunchOptions) error {
-	return m.backend.Launch(opts)
+	if opts.LogDir == "" {
+		opts.LogDir = m.logDir
+	}
+	if opts.HTTPCredentials == nil {
+		opts.HTTPCredentials = m.httpCredentials
+	}
+	if err := m.backend.Launch(opts); err != nil {
+		return err
+	}
+	Logger().Info("browser launched", "headless", opts.Headless, "proxy", opts.Proxy != nil, "userDataDir", opts.UserDataDir)
+	m.headless = opts.Headless
+	return nil
+}
+
+// Headless reports whether the browser was launched headless.
+func (m *BrowserManager) Headless() bool {
+	return m.headless
+}
+
+// SetLogDir sets where browsers launched without a LogDir keep their
+// stderr and crash dumps.
+func (m *BrowserManager) SetLogDir(dir string) {
+	m.logDir = dir
 }
 
 func (m *BrowserManager) Close() error {
@@ -46,6 +123,13 @@ func (m *BrowserManager) IsLaunched() bool {
 // Navigation methods
 
 func (m *BrowserManager) Navigate(url string, waitUntil string) (string, string, error) {
+	if err := m.checkRobots(url); err != nil {
+		return "", "", err
+	}
+	if err := m.rotateForNavigation(); err != nil {
+		return "", "", err
+	}
+	m.waitRateLimit()
 	return m.backend.Navigate(url, waitUntil)
 }
 
@@ -64,91 +148,217 @@ func (m *BrowserManager) Reload() error {
 // Interaction methods
 
 func (m *BrowserManager) Click(selector string) error {
+	selector, err := m.resolveRef(selector)
+	if err != nil {
+		return err
+	}
 	return m.backend.Click(selector)
 }
 
 func (m *BrowserManager) Fill(selector, value string) error {
+	selector, err := m.resolveRef(selector)
+	if err != nil {
+		return err
+	}
 	return m.backend.Fill(selector, value)
 }
 
 func (m *BrowserManager) Type(selector, text string, delay int) error {
+	selector, err := m.resolveRef(selector)
+	if err != nil {
+		return err
+	}
 	return m.backend.Type(selector, text, delay)
 }
 
 func (m *BrowserManager) Press(key string, selector string) error {
+	selector, err := m.resolveOptionalRef(selector)
+	if err != nil {
+		return err
+	}
 	return m.backend.Press(key, selector)
 }
 
+// InsertText inserts text at the caret of an element, or of the focused
+// element when selector is empty, as one input event instead of a
+// keystroke per character. Emoji, CJK and other text that typing would
+// split or that an IME would compose arrive intact.
+func (m *BrowserManager) InsertText(selector, text string) error {
+	selector, err := m.resolveOptionalRef(selector)
+	if err != nil {
+		return err
+	}
+	return m.backend.InsertText(selector, text)
+}
+
 func (m *BrowserManager) Hover(selector string) error {
+	selector, err := m.resolveRef(selector)
+	if err != nil {
+		return err
+	}
 	return m.backend.Hover(selector)
 }
 
+func (m *BrowserManager) Tap(selector string) error {
+	selector, err := m.resolveRef(selector)
+	if err != nil {
+		return err
+	}
+	return m.backend.Tap(selector)
+}
+
 func (m *BrowserManager) Focus(selector string) error {
+	selector, err := m.resolveRef(selector)
+	if err != nil {
+		return err
+	}
 	return m.backend.Focus(selector)
 }
 
 func (m *BrowserManager) Check(selector string) error {
+	selector, err := m.resolveRef(selector)
+	if err != nil {
+		return err
+	}
 	return m.backend.Check(selector)
 }
 
 func (m *BrowserManager) Uncheck(selector string) error {
+	selector, err := m.resolveRef(selector)
+	if err != nil {
+		return err
+	}
 	return m.backend.Uncheck(selector)
 }
 
-func (m *BrowserManager) Select(selector string, values []string) error {
-	return m.backend.Select(selector, values)
-}
-
 func (m *BrowserManager) DoubleClick(selector string) error {
+	selector, err := m.resolveRef(selector)
+	if err != nil {
+		return err
+	}
 	return m.backend.DoubleClick(selector)
 }
 
 func (m *BrowserManager) Clear(selector string) error {
+	selector, err := m.resolveRef(selector)
+	if err != nil {
+		return err
+	}
 	return m.backend.Clear(selector)
 }
 
+// Upload sets the files of the file input at selector. The files must
+// exist, since the browser reads them only when the page does.
+func (m *BrowserManager) Upload(selector string, files []string) error {
+	if len(files) == 0 {
+		return fmt.Errorf("no files to upload")
+	}
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return fmt.Errorf("%s is a directory", file)
+		}
+	}
+	selector, err := m.resolveRef(selector)
+	if err != nil {
+		return err
+	}
+	return m.backend.Upload(selector, files)
+}
+
 // Query methods
 
 func (m *BrowserManager) GetText(selector string) (string, error) {
+	selector, err := m.resolveRef(selector)
+	if err != nil {
+		return "", err
+	}
 	return m.backend.GetText(selector)
 }
 
 func (m *BrowserManager) GetAttribute(selector, attr string) (string, error) {
+	selector, err := m.resolveRef(selector)
+	if err != nil {
+		return "", err
+	}
 	return m.backend.GetAttribute(selector, attr)
 }
 
 func (m *BrowserManager) GetHTML(selector string, outer bool) (string, error) {
+	selector, err := m.resolveRef(selector)
+	if err != nil {
+		return "", err
+	}
 	return m.backend.GetHTML(selector, outer)
 }
 
 func (m *BrowserManager) GetInputValue(selector string) (string, error) {
+	selector, err := m.resolveRef(selector)
+	if err != nil {
+		return "", err
+	}
 	return m.backend.GetInputValue(selector)
 }
 
 func (m *BrowserManager) SetValue(selector, value string) error {
+	selector, err := m.resolveRef(selector)
+	if err != nil {
+		return err
+	}
 	return m.backend.SetValue(selector, value)
 }
 
 func (m *BrowserManager) IsVisible(selector string) (bool, error) {
+	selector, err := m.resolveRef(selector)
+	if err != nil {
+		return false, err
+	}
 	return m.backend.IsVisible(selector)
 }
 
 func (m *BrowserManager) IsEnabled(selector string) (bool, error) {
+	selector, err := m.resolveRef(selector)
+	if err != nil {
+		return false, err
+	}
 	return m.backend.IsEnabled(selector)
 }
 
 func (m *BrowserManager) IsChecked(selector string) (bool, error) {
+	selector, err := m.resolveRef(selector)
+	if err != nil {
+		return false, err
+	}
 	return m.backend.IsChecked(selector)
 }
 
 func (m *BrowserManager) Count(selector string) (int, error) {
+	selector, err := m.resolveRef(selector)
+	if err != nil {
+		return 0, err
+	}
 	return m.backend.Count(selector)
 }
 
 func (m *BrowserManager) GetBoundingBox(selector string) (*BoundingBox, error) {
+	selector, err := m.resolveRef(selector)
+	if err != nil {
+		return nil, err
+	}
 	return m.backend.GetBoundingBox(selector)
 }
 
+func (m *BrowserManager) Describe(selector string, maxText int) (*ElementDescription, error) {
+	selector, err := m.resolveRef(selector)
+	if err != nil {
+		return nil, err
+	}
+	return m.backend.Describe(selector, maxText)
+}
+
 // Page info methods
 
 func (m *BrowserManager) URL() (string, error) {
@@ -174,6 +384,10 @@ func (m *BrowserManager) SetViewport(width, height int) error {
 }
 
 func (m *BrowserManager) Screenshot(fullPage bool, selector string, quality int) ([]byte, error) {
+	selector, err := m.resolveOptionalRef(selector)
+	if err != nil {
+		return nil, err
+	}
 	return m.backend.Screenshot(fullPage, selector, quality)
 }
 
@@ -186,7 +400,11 @@ func (m *BrowserManager) Evaluate(script string) (interface{}, error) {
 // Waiting
 
 func (m *BrowserManager) Wait(selector string, timeout int, state string) error {
-	return m.backend.Wait(selector, timeout, state)
+	selector, err := m.resolveOptionalRef(selector)
+	if err != nil {
+		return err
+	}
+	return m.backend.Wait(selector, m.waitTimeout(timeout), state)
 }
 
 func (m *BrowserManager) WaitForTimeout(ms int) error {
@@ -200,6 +418,10 @@ func (m *BrowserManager) Scroll(direction string, amount int) error {
 }
 
 func (m *BrowserManager) ScrollIntoView(selector string) error {
+	selector, err := m.resolveRef(selector)
+	if err != nil {
+		return err
+	}
 	return m.backend.ScrollIntoView(selector)
 }
 
@@ -209,10 +431,25 @@ func (m *BrowserManager) NewTab(url string) (int, error) {
 	return m.backend.NewTab(url)
 }
 
+// NewWindow opens a tab in a new browser window and makes it active. With
+// viewport the tab gets that viewport size; others keep theirs.
+func (m *BrowserManager) NewWindow(viewport *Viewport) (int, error) {
+	if viewport != nil && (viewport.Width <= 0 || viewport.Height <= 0) {
+		return 0, fmt.Errorf("viewport must be at least 1x1")
+	}
+	return m.backend.NewWindow(viewport)
+}
+
 func (m *BrowserManager) SwitchTab(index int) error {
 	return m.backend.SwitchTab(index)
 }
 
+// BringToFront raises the active tab: its window comes to the front and it
+// becomes the window's selected tab.
+func (m *BrowserManager) BringToFront() error {
+	return m.backend.BringToFront()
+}
+
 func (m *BrowserManager) CloseTab(index int) error {
 	return m.backend.CloseTab(index)
 }
@@ -224,6 +461,14 @@ func (m *BrowserManager) ListTabs() ([]TabInfo, error) {
 // Snapshot
 
 func (m *BrowserManager) GetSnapshot(opts SnapshotOptions) (*EnhancedSnapshot, error) {
+	m.rememberRefs(m.backend.GetRefMap())
+	return m.backend.GetSnapshot(opts)
+}
+
+// peekSnapshot takes a snapshot without replacing the refs handed out by
+// the last one.
+func (m *BrowserManager) peekSnapshot(opts SnapshotOptions) (*EnhancedSnapshot, error) {
+	opts.keepRefs = true
 	return m.backend.GetSnapshot(opts)
 }
 
@@ -231,8 +476,41 @@ func (m *BrowserManager) GetRefMap() RefMap {
 	return m.backend.GetRefMap()
 }
 
+// Identity
+
+func (m *BrowserManager) SetUserAgent(ua string) error {
+	return m.backend.SetUserAgent(ua)
+}
+
 // Storage
 
 func (m *BrowserManager) GetCookies() ([]Cookie, error) {
 	return m.backend.GetCookies()
 }
+
+func (m *BrowserManager) ClearCookies() error {
+	return m.backend.ClearCookies()
+}
+
+// Events
+
+func (m *BrowserManager) Activity() PageActivity {
+	return m.backend.Activity()
+}
+
+func (m *BrowserManager) WaitActivity(since PageActivity, timeout time.Duration) PageActivity {
+	return m.backend.WaitActivity(since, timeout)
+}
+
+func (m *BrowserManager) SetEventHandler(handler func(Event)) {
+	m.backend.SetEventHandler(handler)
+	m.events.SetEventHandler(handler)
+}
+
+func (m *BrowserManager) StartWatch(opts WatchOptions) error {
+	return m.backend.StartWatch(opts)
+}
+
+func (m *BrowserManager) StopWatch() error {
+	return m.backend.StopWatch()
+}
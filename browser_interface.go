@@ -1,5 +1,10 @@
 package agentbrowser
 
+import (
+	"io"
+	"time"
+)
+
 // BrowserBackend defines the interface all browser implementations must satisfy.
 type BrowserBackend interface {
 	// Lifecycle
@@ -9,22 +14,34 @@ type BrowserBackend interface {
 
 	// Navigation
 	Navigate(url string, waitUntil string) (string, string, error)
+	NavigateRequest(req NavigationRequest, waitUntil string) (string, string, error)
 	Back() error
 	Forward() error
 	Reload() error
+	History() (*NavigationHistory, error)
+	GoToHistoryEntry(index int) error
 
 	// Interaction
 	Click(selector string) error
 	Fill(selector, value string) error
 	Type(selector, text string, delay int) error
 	Press(key string, selector string) error
+	InsertText(selector, text string) error // at the caret in one input event; selector is optional
 	Hover(selector string) error
+	Tap(selector string) error // touchstart and touchend at the element's center
 	Focus(selector string) error
 	Check(selector string) error
 	Uncheck(selector string) error
-	Select(selector string, values []string) error
+	Select(selector string, values []string, by string) error // by: value, label or index
 	DoubleClick(selector string) error
 	Clear(selector string) error
+	SelectAll(selector string) error // focus and select all text, like Ctrl+A
+	DispatchEvent(selector, event string, init map[string]interface{}) error
+	Upload(selector string, files []string) error // sets a file input's files
+
+	// Raw input at viewport coordinates
+	InputMouse(ev MouseEvent) error
+	InputKeyboard(ev KeyEvent) error
 
 	// Queries
 	GetText(selector string) (string, error)
@@ -37,6 +54,11 @@ type BrowserBackend interface {
 	IsChecked(selector string) (bool, error)
 	Count(selector string) (int, error)
 	GetBoundingBox(selector string) (*BoundingBox, error)
+	Describe(selector string, maxText int) (*ElementDescription, error)
+	ReadableText(selector string, plain bool) (string, error) // the element's content as Markdown, or plain text
+	Links(selector string) ([]Link, error)                    // the links inside the element
+	Highlight(selector string, duration int) error            // outline the element for duration ms
+	Locate(loc Locator) (string, error)                       // a selector for the first element loc matches
 
 	// Page Info
 	URL() (string, error)
@@ -47,6 +69,11 @@ type BrowserBackend interface {
 	// Viewport & Screenshot
 	SetViewport(width, height int) error
 	Screenshot(fullPage bool, selector string, quality int) ([]byte, error)
+	PDF(opts PDFOptions) ([]byte, error) // headless only
+
+	// Tracing
+	StartTracing(opts TraceOptions) error // one trace at a time
+	StopTracing(path string) error        // writes the trace file
 
 	// JavaScript
 	Evaluate(script string) (interface{}, error)
@@ -54,6 +81,7 @@ type BrowserBackend interface {
 	// Waiting
 	Wait(selector string, timeout int, state string) error
 	WaitForTimeout(ms int) error
+	WaitForLoadState(state string, timeout int) error // load, domcontentloaded or networkidle of the active tab
 
 	// Scrolling
 	Scroll(direction string, amount int) error
@@ -61,16 +89,67 @@ type BrowserBackend interface {
 
 	// Tabs
 	NewTab(url string) (int, error)
+	NewWindow(viewport *Viewport) (int, error) // a tab in a new browser window; viewport is optional
 	SwitchTab(index int) error
 	CloseTab(index int) error
 	ListTabs() ([]TabInfo, error)
+	BringToFront() error // raises the active tab's window and makes it the visible tab
+
+	// Frames
+	SwitchFrame(ref FrameRef) error // scopes the current tab's selectors and scripts to an iframe
+	MainFrame() error
 
 	// Snapshot
 	GetSnapshot(opts SnapshotOptions) (*EnhancedSnapshot, error)
 	GetRefMap() RefMap
 
+	// Identity
+	SetUserAgent(ua string) error            // empty restores the launch user agent
+	SetGeolocation(geo *Geolocation) error   // nil removes the override
+	SetLocale(locale string) error           // Intl locale and Accept-Language; empty removes the override
+	EmulateMedia(media MediaEmulation) error // replaces the whole emulation; empty fields are not overridden
+
+	// Permissions, by Permissions API name; an empty origin is every origin
+	GrantPermissions(permissions []string, origin string) error
+	DenyPermissions(permissions []string, origin string) error
+	ResetPermissions() error
+
+	// Requests and documents
+	SetExtraHeaders(headers map[string]string) error // sent with every request, replacing earlier ones
+	SetHTTPCredentials(creds *HTTPCredentials) error // answer authentication challenges; nil cancels them
+	SetOffline(offline bool) error                   // every tab, including tabs opened later
+	AddInitScript(script string) error               // runs before page scripts in every new document
+	AddScriptTag(url, content string) error          // <script> in the current frame, one of url or content
+	AddStyleTag(url, content string) error           // stylesheet in the current frame, one of url or content
+
 	// Storage
 	GetCookies() ([]Cookie, error)
+	SetCookies(cookies []Cookie) error
+	ClearCookies() error
+	ClearCache() error
+	ClearStorage(origin string) error // localStorage, IndexedDB, caches and service workers
+	SetServiceWorkerBypass(bypass bool) error
+
+	// Performance
+	PerformanceMetrics() (map[string]float64, error) // CDP Performance.getMetrics
+	BrowserProcesses() ([]BrowserProcess, error)
+	HeapSnapshot(w io.Writer) error // active tab
+
+	// Events
+	Activity() PageActivity
+	WaitActivity(since PageActivity, timeout time.Duration) PageActivity // returns once the activity differs from since
+	SetDialogResponse(response, promptText string) error                 // accept, dismiss, or "" for the backend's default
+	ConsoleMessages() []ConsoleMessage                                   // oldest first
+	ClearConsole()
+	PageErrors() []PageError // uncaught exceptions, oldest first
+	ClearPageErrors()
+	Requests() []TrackedRequest // network requests, oldest first
+	ClearRequests()
+	SetEventHandler(handler func(Event))
+	StartScreencast(opts ScreencastOptions, onFrame func(ScreencastFrame)) error // active tab
+	StopScreencast() error
+	StartWatch(opts WatchOptions) error
+	StopWatch() error
 }
 
 // BackendType specifies which browser backend to use.
@@ -0,0 +1,92 @@
+package agentbrowser
+
+import "encoding/json"
+
+// Challenge types reported by DetectChallenge.
+const (
+	ChallengeCaptcha      = "captcha"      // a CAPTCHA widget must be solved
+	ChallengeInterstitial = "interstitial" // a bot-check page stands in front of the content
+	ChallengeLogin        = "login"        // the content requires signing in
+)
+
+// Challenge describes an interstitial that blocks automation, so agents can
+// branch to a fallback instead of interacting with it.
+type Challenge struct {
+	Type     string `json:"type"`               // captcha, interstitial or login
+	Provider string `json:"provider"`           // recaptcha, hcaptcha, turnstile, cloudflare, datadome, perimeterx, login
+	Selector string `json:"selector,omitempty"` // element that revealed the challenge
+	Detail   string `json:"detail,omitempty"`   // e.g. the interstitial's title
+}
+
+// detectChallengeScript returns a Challenge for the current page, or null.
+// Checks run from most to least specific; widgets must be visible so
+// invisible reCAPTCHA v3 badges and preloaded scripts are not reported.
+const detectChallengeScript = `(() => {
+	const visible = (el) => {
+		if (!el) return false;
+		const rect = el.getBoundingClientRect();
+		const style = window.getComputedStyle(el);
+		return rect.width > 0 && rect.height > 0 && style.visibility !== 'hidden' && style.display !== 'none';
+	};
+	const find = (selectors) => {
+		for (const sel of selectors) {
+			for (const el of document.querySelectorAll(sel)) {
+				if (visible(el)) return sel;
+			}
+		}
+		return '';
+	};
+	const title = document.title || '';
+	const result = (type, provider, selector, detail) => ({ type, provider, selector: selector || '', detail: detail || '' });
+
+	let sel;
+	if (/^just a moment|^attention required|^please wait\b.*cloudflare|^checking your browser/i.test(title) ||
+		document.querySelector('#challenge-form, #cf-challenge-running, #challenge-running, #cf-please-wait')) {
+		return result('interstitial', 'cloudflare', '', title);
+	}
+	if ((sel = find(['iframe[src*="challenges.cloudflare.com"]', '.cf-turnstile']))) {
+		return result('captcha', 'turnstile', sel);
+	}
+	if ((sel = find(['iframe[src*="captcha-delivery.com"]', 'iframe[src*="geo.captcha-delivery"]']))) {
+		return result('captcha', 'datadome', sel);
+	}
+	if ((sel = find(['#px-captcha', 'iframe[src*="px-cdn.net"]', 'iframe[src*="perimeterx"]']))) {
+		return result('captcha', 'perimeterx', sel);
+	}
+	if ((sel = find(['iframe[src*="hcaptcha.com"]', '.h-captcha']))) {
+		return result('captcha', 'hcaptcha', sel);
+	}
+	if ((sel = find(['iframe[src*="/recaptcha/api2/anchor"]', 'iframe[src*="/recaptcha/enterprise/anchor"]',
+		'iframe[src*="/recaptcha/api2/bframe"]', '.g-recaptcha']))) {
+		return result('captcha', 'recaptcha', sel);
+	}
+
+	const password = Array.from(document.querySelectorAll('input[type="password"]')).find(visible);
+	if (password) {
+		const path = location.pathname + location.search;
+		const headings = Array.from(document.querySelectorAll('h1, h2, legend, button, [role="heading"]'))
+			.map((el) => el.textContent || '').join(' ');
+		if (/log-?in|sign-?in|signin|auth|sso|account/i.test(path) || /\b(log ?in|sign ?in)\b/i.test(headings + ' ' + title)) {
+			return result('login', 'login', 'input[type="password"]', title);
+		}
+	}
+	return null;
+})()`
+
+// DetectChallenge reports a CAPTCHA, bot-check interstitial or login wall
+// on the current page. It returns nil when none is found.
+func (m *BrowserManager) DetectChallenge() (*Challenge, error) {
+	result, err := m.backend.Evaluate(detectChallengeScript)
+	if err != nil || result == nil {
+		return nil, err
+	}
+	data, err := json.Marshal(result)
+	if err != nil {
+		return nil, err
+	}
+	var challenge Challenge
+	if err := json.Unmarshal(data, &challenge); err != nil {
+		return nil, err
+	}
+	return &challenge, nil
+}
@@ -0,0 +1,41 @@
+package agentbrowser_test
+
+import (
+	"testing"
+
+	agentbrowser "github.com/cpunion/agent-browser-go"
+)
+
+// TestDetectChallenge tests decoding of the challenge detection result
+func TestDetectChallenge(t *testing.T) {
+	tests := []struct {
+		name   string
+		result interface{}
+		want   *agentbrowser.Challenge
+	}{
+		{"clear page", nil, nil},
+		{
+			"recaptcha",
+			map[string]interface{}{"type": "captcha", "provider": "recaptcha", "selector": ".g-recaptcha", "detail": ""},
+			&agentbrowser.Challenge{Type: agentbrowser.ChallengeCaptcha, Provider: "recaptcha", Selector: ".g-recaptcha"},
+		},
+		{
+			"cloudflare",
+			map[string]interface{}{"type": "interstitial", "provider": "cloudflare", "selector": "", "detail": "Just a moment..."},
+			&agentbrowser.Challenge{Type: agentbrowser.ChallengeInterstitial, Provider: "cloudflare", Detail: "Just a moment..."},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := agentbrowser.NewBrowserManagerForTest(&fakeBackend{evaluate: evalResult(tt.result)})
+			got, err := m.DetectChallenge()
+			if err != nil {
+				t.Fatalf("DetectChallenge() error = %v", err)
+			}
+			if (got == nil) != (tt.want == nil) || (got != nil && *got != *tt.want) {
+				t.Errorf("DetectChallenge() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
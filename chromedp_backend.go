@@ -1,22 +1,41 @@
 package agentbrowser
 
 import (
+	"bytes"
 	"context"
+	"encoding/base64"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"net/http"
 	"os"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
 
+	"github.com/chromedp/cdproto/accessibility"
+	"github.com/chromedp/cdproto/browser"
+	"github.com/chromedp/cdproto/cdp"
 	"github.com/chromedp/cdproto/dom"
+	"github.com/chromedp/cdproto/emulation"
+	"github.com/chromedp/cdproto/fetch"
+	"github.com/chromedp/cdproto/heapprofiler"
+	"github.com/chromedp/cdproto/input"
+	cdpio "github.com/chromedp/cdproto/io"
 	"github.com/chromedp/cdproto/network"
 	"github.com/chromedp/cdproto/page"
+	"github.com/chromedp/cdproto/performance"
+	"github.com/chromedp/cdproto/runtime"
+	"github.com/chromedp/cdproto/security"
 	"github.com/chromedp/cdproto/storage"
+	"github.com/chromedp/cdproto/systeminfo"
 	"github.com/chromedp/cdproto/target"
+	"github.com/chromedp/cdproto/tracing"
 	"github.com/chromedp/chromedp"
 )
 
@@ -43,30 +62,92 @@ type ChromeDPBackend struct {
 	refMap  RefMap
 	refLock sync.RWMutex
 
+	// Frame selected with SwitchFrame per tab, and the main-world execution
+	// context of each tab's frames
+	frameLock     sync.Mutex
+	frames        map[target.ID]cdp.FrameID
+	frameContexts map[target.ID]map[cdp.FrameID]runtime.ExecutionContextID
+
 	// State
-	launched     atomic.Bool
-	headless     bool
-	viewport     *Viewport
-	consoleLog   []ConsoleMessage
-	pageErrors   []PageError
-	consoleLock  sync.Mutex
-	requests     []TrackedRequest
-	requestsLock sync.Mutex
-
-	// Screencast
+	launched    atomic.Bool
+	headless    bool
+	stealth     string // init script installed in every tab, empty when off
+	fingerprint *Fingerprint
+	launchUA    string // LaunchOptions.UserAgent, what an empty SetUserAgent restores
+	tls         TLSOptions
+	hostRules   []string
+	blockSW     bool     // service worker registration fails in every tab
+	browserLog  *os.File // browser stderr, when LaunchOptions.LogDir is set
+	viewport    *Viewport
+	launchOpts  LaunchOptions // options of the running browser
+
+	// Settings applied to every tab, kept across relaunches. prepareTab
+	// reads them for tabs pages open while a setter may be running.
+	settingsLock sync.RWMutex
+	userAgent    string       // set by SetUserAgent
+	geolocation  *Geolocation // set by SetGeolocation
+	locale       string       // set by SetLocale
+	extraHeaders map[string]string
+	initScripts  []string
+	bypassSW     bool           // requests skip service workers, set by SetServiceWorkerBypass
+	offline      bool           // set by SetOffline
+	media        MediaEmulation // set by EmulateMedia
+
+	// Fetch interception: HTTP authentication, client certificates and
+	// request rewriting
+	fetchLock       sync.Mutex
+	httpCredentials *HTTPCredentials
+	proxyCredential *HTTPCredentials        // answers the proxy's challenges, set at launch
+	certClients     map[string]*http.Client // present client certificates, by origin; set at launch
+	authAnswered    map[authChallenge]bool
+	pausedHooks     map[target.ID]func(*fetch.EventRequestPaused) *fetch.ContinueRequestParams
+
+	// Tracing of the tab it was started in
+	traceLock sync.Mutex
+	traceCtx  context.Context
+	traceDone chan *tracing.EventTracingComplete
+
+	// Screencast of the tab it was started in
 	screencastCallback func(ScreencastFrame)
 	screencastLock     sync.Mutex
+	screencastCtx      context.Context
+	screencastGen      int // frames of earlier screencasts are ignored
+
+	// Requests in flight per tab, for networkidle waits
+	netLock     sync.Mutex
+	inflight    map[cdpRequestKey]bool
+	netActivity map[target.ID]time.Time // last time a request started or ended
+
+	// DOM change notifications
+	watchLock sync.Mutex
+	watchOpts *WatchOptions
+	watchTab  target.ID
+
+	activityTracker
+	dialogPolicy
+	requestTracker
+	eventEmitter
 }
 
 // LaunchOptions configures browser launch.
 type LaunchOptions struct {
-	Headless       bool
-	Viewport       *Viewport
-	ExecutablePath string
-	UserDataDir    string // Path to user data directory for persistent profiles
-	Locale         string // Browser locale, e.g. "en-US", "zh-CN"
-	CDPPort        int
-	Headers        map[string]string
+	Headless            bool
+	Viewport            *Viewport
+	ExecutablePath      string
+	UserDataDir         string        // Path to user data directory for persistent profiles
+	Locale              string        // Browser locale, e.g. "en-US", "zh-CN"
+	CDPPort             int           // Remote debugging port other tools can attach to, 0 for none
+	Extensions          []string      // Absolute paths of unpacked extensions to load
+	Proxy               *ProxyOptions // Proxy server for all traffic, nil to connect directly
+	Headers             map[string]string
+	Stealth             bool             // Hide common automation tells from bot detectors
+	Fingerprint         *Fingerprint     // Browser identity to present, nil for the real one
+	TLS                 TLSOptions       // Certificate errors and client certificates
+	HostRules           []string         // Host resolver rules, e.g. "MAP example.com 127.0.0.1"
+	HTTPCredentials     *HTTPCredentials // Answer HTTP authentication challenges, nil to cancel them
+	BlockServiceWorkers bool             // Make service worker registration fail
+	LogDir              string           // Where browser stderr and crash dumps are kept, empty to discard them
+	UserAgent           string           // User agent to present instead of the fingerprint's or the browser's
 }
 
 // NewBrowserManager creates a new browser manager.
@@ -84,13 +165,17 @@ func (b *ChromeDPBackend) Launch(opts LaunchOptions) error {
 	defer b.lifecycleMu.Unlock()
 
 	if b.launched.Load() {
-		// Check if headless setting changed
-		if b.headless != opts.Headless {
+		// Check if headless, stealth or fingerprint setting changed
+		if b.headless != opts.Headless || (b.stealth != "") != opts.Stealth ||
+			!sameFingerprint(b.fingerprint, opts.Fingerprint) || !sameTLS(b.tls, opts.TLS) ||
+			!sameHostRules(b.hostRules, opts.HostRules) || b.blockSW != opts.BlockServiceWorkers ||
+			b.launchUA != opts.UserAgent || !sameProcessOptions(b.launchOpts, opts) {
 			// Need to relaunch with new settings
 			b.cleanupLocked()
 			b.launched.Store(false)
 		} else {
-			return nil // Already launched with same settings
+			// Already launched with same settings; credentials apply without a relaunch
+			return b.SetHTTPCredentials(opts.HTTPCredentials)
 		}
 	}
 
@@ -151,7 +236,9 @@ func (b *ChromeDPBackend) launchChromeInstanceLocked(opts LaunchOptions) error {
 		b.viewport = &Viewport{Width: 1280, Height: 720}
 	}
 
+	b.launchOpts = opts
 	b.headless = opts.Headless
+	b.launchUA = opts.UserAgent
 
 	// Build final options
 	var finalOpts []chromedp.ExecAllocatorOption
@@ -189,6 +276,70 @@ func (b *ChromeDPBackend) launchChromeInstanceLocked(opts LaunchOptions) error {
 		finalOpts = append(finalOpts, chromedpOpts...)
 	}
 
+	b.fingerprint = opts.Fingerprint
+	b.stealth = ""
+	if opts.Stealth {
+		b.stealth = stealthScript(opts.Locale)
+		finalOpts = append(finalOpts,
+			chromedp.Flag("enable-automation", false),
+			chromedp.Flag("disable-gpu", false))
+		for _, arg := range stealthArgs {
+			name, value, hasValue := strings.Cut(strings.TrimPrefix(arg, "--"), "=")
+			if hasValue {
+				finalOpts = append(finalOpts, chromedp.Flag(name, value))
+			} else {
+				finalOpts = append(finalOpts, chromedp.Flag(name, true))
+			}
+		}
+	}
+
+	// Chrome only takes client certificates from the OS certificate store, so
+	// requests to their origins are made here and fulfilled with Fetch
+	certClients, err := clientCertificateClients(opts)
+	if err != nil {
+		return err
+	}
+
+	b.tls = opts.TLS
+	b.hostRules = opts.HostRules
+	b.blockSW = opts.BlockServiceWorkers
+	b.fetchLock.Lock()
+	b.httpCredentials = opts.HTTPCredentials
+	b.proxyCredential = opts.Proxy.credentials()
+	b.certClients = certClients
+	b.fetchLock.Unlock()
+	if len(opts.HostRules) > 0 {
+		finalOpts = append(finalOpts, chromedp.Flag("host-resolver-rules", hostResolverRulesArg(opts.HostRules)))
+	}
+	if opts.TLS.IgnoreHTTPSErrors {
+		finalOpts = append(finalOpts, chromedp.Flag("ignore-certificate-errors", true))
+	}
+	if opts.CDPPort != 0 {
+		finalOpts = append(finalOpts, chromedp.Flag("remote-debugging-port", strconv.Itoa(opts.CDPPort)))
+	}
+	if opts.Proxy != nil {
+		finalOpts = append(finalOpts, chromedp.ProxyServer(opts.Proxy.Server))
+		if len(opts.Proxy.Bypass) > 0 {
+			finalOpts = append(finalOpts, chromedp.Flag("proxy-bypass-list", proxyBypassArg(opts.Proxy.Bypass)))
+		}
+	}
+	if len(opts.Extensions) > 0 {
+		finalOpts = append(finalOpts, chromedp.Flag("disable-extensions", false))
+		for name, value := range extensionArgs(opts.Extensions) {
+			finalOpts = append(finalOpts, chromedp.Flag(name, value))
+		}
+	}
+	if opts.LogDir != "" {
+		f, crashDir, err := openBrowserLog(opts.LogDir)
+		if err != nil {
+			return fmt.Errorf("failed to open browser log: %w", err)
+		}
+		b.browserLog = f
+		finalOpts = append(finalOpts,
+			chromedp.CombinedOutput(f),
+			chromedp.Flag("crash-dumps-dir", crashDir))
+	}
+
 	// Create allocator
 	b.allocCtx, b.allocCancel = chromedp.NewExecAllocator(
 		context.Background(),
@@ -204,6 +355,14 @@ func (b *ChromeDPBackend) launchChromeInstanceLocked(opts LaunchOptions) error {
 		return fmt.Errorf("failed to launch browser: %w", err)
 	}
 
+	if opts.TLS.IgnoreHTTPSErrors {
+		// The flag is not honored by every Chrome build; the Security domain is
+		if err := chromedp.Run(b.ctx, security.SetIgnoreCertificateErrors(true)); err != nil {
+			b.cleanupLocked()
+			return fmt.Errorf("failed to ignore certificate errors: %w", err)
+		}
+	}
+
 	// Get initial target
 	targets, err := chromedp.Targets(b.ctx)
 	if err != nil {
@@ -216,6 +375,11 @@ func (b *ChromeDPBackend) launchChromeInstanceLocked(opts LaunchOptions) error {
 			b.targets = append(b.targets, t.TargetID)
 			b.tabContexts[t.TargetID] = b.ctx
 			b.tabCancels[t.TargetID] = b.cancel
+			b.listenTab(b.ctx, t.TargetID)
+			if err := b.prepareTab(b.ctx); err != nil {
+				b.cleanupLocked()
+				return fmt.Errorf("failed to prepare tab: %w", err)
+			}
 			break
 		}
 	}
@@ -266,6 +430,11 @@ func (b *ChromeDPBackend) cleanupLocked() {
 		b.allocCancel()
 	}
 
+	if b.browserLog != nil {
+		b.browserLog.Close()
+		b.browserLog = nil
+	}
+
 	b.ctx = nil
 	b.cancel = nil
 	b.allocCtx = nil
@@ -275,6 +444,935 @@ func (b *ChromeDPBackend) cleanupLocked() {
 	b.tabContexts = make(map[target.ID]context.Context)
 	b.tabCancels = make(map[target.ID]context.CancelFunc)
 	b.refMap = make(RefMap)
+	b.resetActivity()
+	b.ClearRequests()
+	b.forgetNetwork("")
+
+	b.frameLock.Lock()
+	b.frames = nil
+	b.frameContexts = nil
+	b.frameLock.Unlock()
+
+	b.watchLock.Lock()
+	b.watchOpts = nil
+	b.watchLock.Unlock()
+}
+
+// prepareTab applies per-tab settings: the stealth and fingerprint init
+// scripts, the fingerprint's timezone, the user agent, the locale, the
+// geolocation, media emulation, offline mode, HTTP authentication, client
+// certificates and service worker handling.
+func (b *ChromeDPBackend) prepareTab(ctx context.Context) error {
+	intercept := b.interceptsRequests()
+	b.settingsLock.RLock()
+	media, offline, geolocation, locale := b.media, b.offline, b.geolocation, b.locale
+	userAgent, extraHeaders, initScripts, bypassSW := b.userAgent, b.extraHeaders, b.initScripts, b.bypassSW
+	b.settingsLock.RUnlock()
+
+	if b.stealth == "" && b.fingerprint == nil && userAgent == "" && b.launchUA == "" && !intercept && !b.blockSW && !bypassSW &&
+		len(extraHeaders) == 0 && len(initScripts) == 0 && geolocation == nil && locale == "" && !offline &&
+		media == (MediaEmulation{}) {
+		return nil
+	}
+	return chromedp.Run(ctx, chromedp.ActionFunc(func(ctx context.Context) error {
+		if media != (MediaEmulation{}) {
+			if err := b.applyMedia(ctx); err != nil {
+				return err
+			}
+		}
+		if offline {
+			if err := b.applyOffline(ctx); err != nil {
+				return err
+			}
+		}
+		if geolocation != nil {
+			if err := b.applyGeolocation(ctx); err != nil {
+				return err
+			}
+		}
+		if locale != "" {
+			if err := b.applyLocale(ctx); err != nil {
+				return err
+			}
+		}
+		if len(extraHeaders) > 0 {
+			if err := b.applyExtraHeaders(ctx); err != nil {
+				return err
+			}
+		}
+		for _, script := range initScripts {
+			if _, err := page.AddScriptToEvaluateOnNewDocument(script).Do(ctx); err != nil {
+				return err
+			}
+		}
+		if b.blockSW {
+			if _, err := page.AddScriptToEvaluateOnNewDocument(blockServiceWorkersScript).Do(ctx); err != nil {
+				return err
+			}
+		}
+		if bypassSW {
+			if err := b.applyServiceWorkerBypass(ctx); err != nil {
+				return err
+			}
+		}
+		if intercept {
+			if err := b.applyFetch(ctx); err != nil {
+				return err
+			}
+		}
+		if b.stealth != "" {
+			if _, err := page.AddScriptToEvaluateOnNewDocument(b.stealth).Do(ctx); err != nil {
+				return err
+			}
+		}
+		if fp := b.fingerprint; fp != nil {
+			if _, err := page.AddScriptToEvaluateOnNewDocument(fingerprintScript(*fp)).Do(ctx); err != nil {
+				return err
+			}
+			if fp.Timezone != "" {
+				if err := emulation.SetTimezoneOverride(fp.Timezone).Do(ctx); err != nil {
+					return err
+				}
+			}
+		}
+		return b.applyUserAgent(ctx)
+	}))
+}
+
+// applyUserAgent sets the tab's user agent and matching client hints, and
+// the Accept-Language of the locale set with SetLocale. The user agent set
+// with SetUserAgent wins over the launch option's, which wins over the
+// fingerprint's, which wins over the stealth fix; with none of them the
+// override is cleared.
+func (b *ChromeDPBackend) applyUserAgent(ctx context.Context) error {
+	b.settingsLock.RLock()
+	ua, locale := b.userAgent, b.locale
+	b.settingsLock.RUnlock()
+	var platform string
+	switch {
+	case ua != "":
+	case b.launchUA != "":
+		ua = b.launchUA
+	case b.fingerprint != nil:
+		ua, platform = b.fingerprint.UserAgent, b.fingerprint.Platform
+	case b.stealth != "":
+		_, _, _, browserUA, _, err := browser.GetVersion().Do(ctx)
+		if err != nil {
+			return err
+		}
+		ua = stealthUserAgent(browserUA)
+	}
+
+	o := userAgentOverrideFor(ua)
+	if platform != "" {
+		o.Platform = platform
+	}
+	params := emulation.SetUserAgentOverride(o.UserAgent)
+	if o.Platform != "" {
+		params = params.WithPlatform(o.Platform)
+	}
+	if o.UserAgentMetadata != nil {
+		params = params.WithUserAgentMetadata(o.UserAgentMetadata)
+	}
+	if locale != "" {
+		params = params.WithAcceptLanguage(locale)
+	}
+	return params.Do(ctx)
+}
+
+// SetUserAgent overrides the user agent and client hints in every tab,
+// including tabs opened later. An empty ua restores the launch user agent.
+func (b *ChromeDPBackend) SetUserAgent(ua string) error {
+	b.settingsLock.Lock()
+	b.userAgent = ua
+	b.settingsLock.Unlock()
+	for _, tid := range b.targets {
+		ctx, ok := b.tabContexts[tid]
+		if !ok {
+			continue
+		}
+		if err := chromedp.Run(ctx, chromedp.ActionFunc(b.applyUserAgent)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SetLocale overrides the locale in every tab, including tabs opened
+// later. An empty locale removes the override.
+func (b *ChromeDPBackend) SetLocale(locale string) error {
+	b.settingsLock.Lock()
+	b.locale = locale
+	b.settingsLock.Unlock()
+	for _, tid := range b.targets {
+		ctx, ok := b.tabContexts[tid]
+		if !ok {
+			continue
+		}
+		err := chromedp.Run(ctx, chromedp.ActionFunc(func(ctx context.Context) error {
+			if err := b.applyLocale(ctx); err != nil {
+				return err
+			}
+			return b.applyUserAgent(ctx)
+		}))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyLocale sets or clears a tab's locale for Intl and date formatting.
+// Chrome refuses to replace an override, so the old one is cleared first.
+func (b *ChromeDPBackend) applyLocale(ctx context.Context) error {
+	b.settingsLock.RLock()
+	locale := b.locale
+	b.settingsLock.RUnlock()
+
+	if err := emulation.SetLocaleOverride().Do(ctx); err != nil {
+		return err
+	}
+	if locale == "" {
+		return nil
+	}
+	return emulation.SetLocaleOverride().WithLocale(locale).Do(ctx)
+}
+
+// SetGeolocation overrides the position in every tab, including tabs opened
+// later, and grants the geolocation permission. nil removes the override.
+func (b *ChromeDPBackend) SetGeolocation(geo *Geolocation) error {
+	b.settingsLock.Lock()
+	b.geolocation = geo
+	b.settingsLock.Unlock()
+	for _, tid := range b.targets {
+		ctx, ok := b.tabContexts[tid]
+		if !ok {
+			continue
+		}
+		if err := chromedp.Run(ctx, chromedp.ActionFunc(b.applyGeolocation)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyGeolocation sets or clears a tab's position override. The
+// permission is granted browser-wide, for every origin.
+func (b *ChromeDPBackend) applyGeolocation(ctx context.Context) error {
+	b.settingsLock.RLock()
+	geo := b.geolocation
+	b.settingsLock.RUnlock()
+	if geo == nil {
+		return emulation.ClearGeolocationOverride().Do(ctx)
+	}
+	grant := browser.GrantPermissions([]browser.PermissionType{browser.PermissionTypeGeolocation})
+	if err := grant.Do(cdp.WithExecutor(ctx, chromedp.FromContext(ctx).Browser)); err != nil {
+		return err
+	}
+	return emulation.SetGeolocationOverride().
+		WithLatitude(geo.Latitude).
+		WithLongitude(geo.Longitude).
+		WithAccuracy(geo.Accuracy).
+		Do(ctx)
+}
+
+// GrantPermissions grants permissions to origin, or to every origin.
+func (b *ChromeDPBackend) GrantPermissions(permissions []string, origin string) error {
+	return b.setPermissions(permissions, origin, browser.PermissionSettingGranted)
+}
+
+// DenyPermissions denies permissions to origin, or to every origin.
+func (b *ChromeDPBackend) DenyPermissions(permissions []string, origin string) error {
+	return b.setPermissions(permissions, origin, browser.PermissionSettingDenied)
+}
+
+// setPermissions sets permissions browser-wide.
+func (b *ChromeDPBackend) setPermissions(permissions []string, origin string, setting browser.PermissionSetting) error {
+	if !b.launched.Load() {
+		return ErrBrowserNotLaunched
+	}
+	return chromedp.Run(b.Context(), chromedp.ActionFunc(func(ctx context.Context) error {
+		ctx = cdp.WithExecutor(ctx, chromedp.FromContext(ctx).Browser)
+		for _, name := range permissions {
+			desc := &browser.PermissionDescriptor{Name: name}
+			if name == "midi-sysex" {
+				desc = &browser.PermissionDescriptor{Name: "midi", Sysex: true}
+			}
+			params := browser.SetPermission(desc, setting)
+			if origin != "" {
+				params = params.WithOrigin(origin)
+			}
+			if err := params.Do(ctx); err != nil {
+				return fmt.Errorf("%s: %w", name, err)
+			}
+		}
+		return nil
+	}))
+}
+
+// ResetPermissions drops every permission grant and denial.
+func (b *ChromeDPBackend) ResetPermissions() error {
+	if !b.launched.Load() {
+		return ErrBrowserNotLaunched
+	}
+	return chromedp.Run(b.Context(), chromedp.ActionFunc(func(ctx context.Context) error {
+		return browser.ResetPermissions().Do(cdp.WithExecutor(ctx, chromedp.FromContext(ctx).Browser))
+	}))
+}
+
+// SetExtraHeaders sets headers sent with every request of every tab,
+// including tabs opened later. An empty map removes them.
+func (b *ChromeDPBackend) SetExtraHeaders(headers map[string]string) error {
+	b.settingsLock.Lock()
+	b.extraHeaders = headers
+	b.settingsLock.Unlock()
+	for _, tid := range b.targets {
+		ctx, ok := b.tabContexts[tid]
+		if !ok {
+			continue
+		}
+		if err := chromedp.Run(ctx, chromedp.ActionFunc(b.applyExtraHeaders)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyExtraHeaders sets a tab's extra headers.
+func (b *ChromeDPBackend) applyExtraHeaders(ctx context.Context) error {
+	b.settingsLock.RLock()
+	headers := make(network.Headers, len(b.extraHeaders))
+	for name, value := range b.extraHeaders {
+		headers[name] = value
+	}
+	b.settingsLock.RUnlock()
+	return network.SetExtraHTTPHeaders(headers).Do(ctx)
+}
+
+// AddInitScript runs script before page scripts in every new document of
+// every tab, including tabs opened later.
+func (b *ChromeDPBackend) AddInitScript(script string) error {
+	b.settingsLock.Lock()
+	b.initScripts = append(b.initScripts, script)
+	b.settingsLock.Unlock()
+	for _, tid := range b.targets {
+		ctx, ok := b.tabContexts[tid]
+		if !ok {
+			continue
+		}
+		err := chromedp.Run(ctx, chromedp.ActionFunc(func(ctx context.Context) error {
+			_, err := page.AddScriptToEvaluateOnNewDocument(script).Do(ctx)
+			return err
+		}))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AddScriptTag adds a <script> to the current frame and waits for an
+// external one to load.
+func (b *ChromeDPBackend) AddScriptTag(url, content string) error {
+	return b.addTag("script", url, content)
+}
+
+// AddStyleTag adds a stylesheet to the current frame and waits for an
+// external one to load.
+func (b *ChromeDPBackend) AddStyleTag(url, content string) error {
+	return b.addTag("style", url, content)
+}
+
+func (b *ChromeDPBackend) addTag(tag, url, content string) error {
+	ctx := b.Context()
+	opts := append(b.frameScope(ctx).eval, func(p *runtime.EvaluateParams) *runtime.EvaluateParams {
+		return p.WithAwaitPromise(true)
+	})
+	// JSON rather than %q: script content can hold escapes Go and JS read differently
+	args, err := json.Marshal([]string{tag, url, content})
+	if err != nil {
+		return err
+	}
+	return chromedp.Run(ctx, chromedp.Evaluate(fmt.Sprintf(`(%s)(...%s)`, addTagScript, args), nil, opts...))
+}
+
+// SetServiceWorkerBypass makes requests skip service workers in every tab,
+// including tabs opened later, or restores normal handling.
+func (b *ChromeDPBackend) SetServiceWorkerBypass(bypass bool) error {
+	b.settingsLock.Lock()
+	b.bypassSW = bypass
+	b.settingsLock.Unlock()
+	for _, tid := range b.targets {
+		ctx, ok := b.tabContexts[tid]
+		if !ok {
+			continue
+		}
+		if err := chromedp.Run(ctx, chromedp.ActionFunc(b.applyServiceWorkerBypass)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyServiceWorkerBypass sets a tab's service worker bypass. The setting
+// only takes effect while the Network domain is enabled.
+func (b *ChromeDPBackend) applyServiceWorkerBypass(ctx context.Context) error {
+	b.settingsLock.RLock()
+	bypass := b.bypassSW
+	b.settingsLock.RUnlock()
+
+	if err := network.Enable().Do(ctx); err != nil {
+		return err
+	}
+	return network.SetBypassServiceWorker(bypass).Do(ctx)
+}
+
+// EmulateMedia sets the media type and features in every tab, including
+// tabs opened later.
+func (b *ChromeDPBackend) EmulateMedia(media MediaEmulation) error {
+	b.settingsLock.Lock()
+	b.media = media
+	b.settingsLock.Unlock()
+	for _, tid := range b.targets {
+		ctx, ok := b.tabContexts[tid]
+		if !ok {
+			continue
+		}
+		if err := chromedp.Run(ctx, chromedp.ActionFunc(b.applyMedia)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyMedia sets a tab's emulated media. Empty values remove overrides.
+func (b *ChromeDPBackend) applyMedia(ctx context.Context) error {
+	b.settingsLock.RLock()
+	media := b.media
+	b.settingsLock.RUnlock()
+
+	return emulation.SetEmulatedMedia().WithMedia(media.Media).WithFeatures([]*emulation.MediaFeature{
+		{Name: "prefers-color-scheme", Value: media.ColorScheme},
+		{Name: "prefers-reduced-motion", Value: media.ReducedMotion},
+		{Name: "forced-colors", Value: media.ForcedColors},
+	}).Do(ctx)
+}
+
+// SetDialogResponse sets how dialogs opened from now on are answered. A
+// dialog already open in the active tab is answered the same way, as it
+// blocks the page until it is.
+func (b *ChromeDPBackend) SetDialogResponse(response, promptText string) error {
+	b.setDialogResponse(response, promptText)
+	if response == "" || !b.launched.Load() {
+		return nil
+	}
+	// Fails when no dialog is open
+	_ = chromedp.Run(b.Context(), page.HandleJavaScriptDialog(response == "accept").WithPromptText(promptText))
+	return nil
+}
+
+// SetOffline cuts every tab, including tabs opened later, off the network,
+// or reconnects them.
+func (b *ChromeDPBackend) SetOffline(offline bool) error {
+	b.settingsLock.Lock()
+	b.offline = offline
+	b.settingsLock.Unlock()
+	for _, tid := range b.targets {
+		ctx, ok := b.tabContexts[tid]
+		if !ok {
+			continue
+		}
+		if err := chromedp.Run(ctx, chromedp.ActionFunc(b.applyOffline)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyOffline sets a tab's network offline or back online, without
+// throttling.
+func (b *ChromeDPBackend) applyOffline(ctx context.Context) error {
+	b.settingsLock.RLock()
+	offline := b.offline
+	b.settingsLock.RUnlock()
+
+	if err := network.Enable().Do(ctx); err != nil {
+		return err
+	}
+	return network.EmulateNetworkConditions(offline, 0, -1, -1).Do(ctx)
+}
+
+// handlesAuth reports whether there are credentials to answer server or
+// proxy authentication challenges with.
+func (b *ChromeDPBackend) handlesAuth() bool {
+	b.fetchLock.Lock()
+	defer b.fetchLock.Unlock()
+	return b.httpCredentials != nil || b.proxyCredential != nil
+}
+
+// interceptsRequests reports whether tabs pause requests with Fetch, to
+// answer authentication challenges or present client certificates.
+func (b *ChromeDPBackend) interceptsRequests() bool {
+	b.fetchLock.Lock()
+	defer b.fetchLock.Unlock()
+	return b.httpCredentials != nil || b.proxyCredential != nil || len(b.certClients) > 0
+}
+
+// SetHTTPCredentials sets the credentials that answer HTTP authentication
+// challenges in every tab, including tabs opened later. Without them
+// challenges are cancelled, so the 401 page loads.
+func (b *ChromeDPBackend) SetHTTPCredentials(creds *HTTPCredentials) error {
+	b.fetchLock.Lock()
+	changed := !sameHTTPCredentials(b.httpCredentials, creds)
+	b.httpCredentials = creds
+	b.fetchLock.Unlock()
+	if !changed {
+		return nil
+	}
+	for _, tid := range b.targets {
+		ctx, ok := b.tabContexts[tid]
+		if !ok {
+			continue
+		}
+		if err := chromedp.Run(ctx, chromedp.ActionFunc(b.applyFetch)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyFetch enables Fetch interception of a tab's requests, so Chrome
+// reports authentication challenges instead of waiting on a login prompt
+// that headless Chrome never shows, and requests to the origins of client
+// certificates can present them. Without either it is disabled.
+func (b *ChromeDPBackend) applyFetch(ctx context.Context) error {
+	auth := b.handlesAuth()
+	b.fetchLock.Lock()
+	origins := make([]string, 0, len(b.certClients))
+	for origin := range b.certClients {
+		origins = append(origins, origin)
+	}
+	b.fetchLock.Unlock()
+	sort.Strings(origins)
+
+	if !auth && len(origins) == 0 {
+		return fetch.Disable().Do(ctx)
+	}
+	patterns := []*fetch.RequestPattern{{URLPattern: "*"}}
+	if !auth {
+		patterns = patterns[:0]
+		for _, origin := range origins {
+			patterns = append(patterns, &fetch.RequestPattern{URLPattern: origin + "/*"})
+		}
+	}
+	return fetch.Enable().WithHandleAuthRequests(auth).WithPatterns(patterns).Do(ctx)
+}
+
+// authChallenge identifies the challenges of a request from a server or
+// its proxy.
+type authChallenge struct {
+	request fetch.RequestID
+	source  fetch.AuthChallengeSource
+}
+
+// answerAuth answers an authentication challenge with the credentials, or
+// a proxy's challenge with the proxy credentials. A second challenge for
+// the same request means they were rejected; it is cancelled rather than
+// answered again, which would loop.
+func (b *ChromeDPBackend) answerAuth(e *fetch.EventAuthRequired) *fetch.ContinueWithAuthParams {
+	key := authChallenge{e.RequestID, e.AuthChallenge.Source}
+	b.fetchLock.Lock()
+	creds := b.httpCredentials
+	if key.source == fetch.AuthChallengeSourceProxy {
+		creds = b.proxyCredential
+	}
+	retried := b.authAnswered[key]
+	if b.authAnswered == nil || len(b.authAnswered) > 256 {
+		b.authAnswered = make(map[authChallenge]bool)
+	}
+	b.authAnswered[key] = true
+	b.fetchLock.Unlock()
+
+	resp := &fetch.AuthChallengeResponse{Response: fetch.AuthChallengeResponseResponseCancelAuth}
+	if creds != nil && !retried && (key.source == fetch.AuthChallengeSourceProxy || creds.matches(e.AuthChallenge.Origin)) {
+		resp.Response = fetch.AuthChallengeResponseResponseProvideCredentials
+		resp.Username = creds.Username
+		resp.Password = creds.Password
+	}
+	return fetch.ContinueWithAuth(e.RequestID, resp)
+}
+
+// setPausedHook sets the function that decides how a tab's paused requests
+// continue; nil continues them unchanged.
+func (b *ChromeDPBackend) setPausedHook(tid target.ID, hook func(*fetch.EventRequestPaused) *fetch.ContinueRequestParams) {
+	b.fetchLock.Lock()
+	defer b.fetchLock.Unlock()
+	if hook == nil {
+		delete(b.pausedHooks, tid)
+		return
+	}
+	if b.pausedHooks == nil {
+		b.pausedHooks = make(map[target.ID]func(*fetch.EventRequestPaused) *fetch.ContinueRequestParams)
+	}
+	b.pausedHooks[tid] = hook
+}
+
+// continuePaused returns how a paused request continues. Requests to the
+// origin of a client certificate are made with it and fulfilled.
+func (b *ChromeDPBackend) continuePaused(tid target.ID, e *fetch.EventRequestPaused) chromedp.Action {
+	b.fetchLock.Lock()
+	hook := b.pausedHooks[tid]
+	client := b.certClients[certificateOrigin(e.Request.URL)]
+	b.fetchLock.Unlock()
+	action := fetch.ContinueRequest(e.RequestID)
+	if hook != nil {
+		action = hook(e)
+	}
+	if client != nil {
+		return fulfillWithCertificate(client, e, action)
+	}
+	return action
+}
+
+// fulfillWithCertificate makes a paused request with a client presenting a
+// certificate, with the changes of how it was to continue, and fulfills it
+// with the response. The request carries the browser's cookies for its URL,
+// which Fetch leaves out of paused requests.
+func fulfillWithCertificate(client *http.Client, e *fetch.EventRequestPaused, continued *fetch.ContinueRequestParams) chromedp.Action {
+	return chromedp.ActionFunc(func(ctx context.Context) error {
+		req, err := certificateRequest(ctx, e, continued)
+		if err == nil {
+			var resp *http.Response
+			if resp, err = client.Do(req); err == nil {
+				defer resp.Body.Close()
+				var body []byte
+				if body, err = io.ReadAll(resp.Body); err == nil {
+					// The body is decoded, so its encoding and length no longer apply
+					var headers []*fetch.HeaderEntry
+					for name, values := range resp.Header {
+						if name == "Content-Encoding" || name == "Content-Length" || name == "Transfer-Encoding" {
+							continue
+						}
+						for _, value := range values {
+							headers = append(headers, &fetch.HeaderEntry{Name: name, Value: value})
+						}
+					}
+					return fetch.FulfillRequest(e.RequestID, int64(resp.StatusCode)).
+						WithResponseHeaders(headers).
+						WithResponsePhrase(http.StatusText(resp.StatusCode)).
+						WithBody(base64.StdEncoding.EncodeToString(body)).Do(ctx)
+				}
+			}
+		}
+		Logger().Warn("client certificate request failed", "url", e.Request.URL, "err", err)
+		return fetch.FailRequest(e.RequestID, network.ErrorReasonConnectionFailed).Do(ctx)
+	})
+}
+
+// certificateRequest returns the HTTP request a paused request makes, with
+// the method, body and headers it was to continue with.
+func certificateRequest(ctx context.Context, e *fetch.EventRequestPaused, continued *fetch.ContinueRequestParams) (*http.Request, error) {
+	method := e.Request.Method
+	if continued.Method != "" {
+		method = continued.Method
+	}
+
+	var body []byte
+	switch {
+	case continued.PostData != "":
+		data, err := base64.StdEncoding.DecodeString(continued.PostData)
+		if err != nil {
+			return nil, err
+		}
+		body = data
+	case len(e.Request.PostDataEntries) > 0:
+		for _, entry := range e.Request.PostDataEntries {
+			data, err := base64.StdEncoding.DecodeString(entry.Bytes)
+			if err != nil {
+				return nil, err
+			}
+			body = append(body, data...)
+		}
+	case e.Request.HasPostData:
+		data, err := network.GetRequestPostData(e.NetworkID).Do(ctx)
+		if err != nil {
+			return nil, err
+		}
+		body = []byte(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, e.Request.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if continued.Headers != nil {
+		for _, h := range continued.Headers {
+			req.Header.Add(h.Name, h.Value)
+		}
+	} else {
+		for name, value := range e.Request.Headers {
+			req.Header.Set(name, fmt.Sprint(value))
+		}
+	}
+	// Go decodes the encodings it asks for itself
+	req.Header.Del("Accept-Encoding")
+
+	cookies, err := network.GetCookies().WithUrls([]string{e.Request.URL}).Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range cookies {
+		req.AddCookie(&http.Cookie{Name: c.Name, Value: c.Value})
+	}
+	return req, nil
+}
+
+// cdpRequestKey identifies a request in the request log. Request IDs are
+// only unique within a tab.
+type cdpRequestKey struct {
+	tab target.ID
+	id  network.RequestID
+}
+
+// listenTab records console messages, exceptions, dialogs, network requests
+// and main-frame navigations for a tab, and answers Fetch interception. Dialogs are
+// left open unless SetDialogResponse chose how to answer them.
+func (b *ChromeDPBackend) listenTab(ctx context.Context, tid target.ID) {
+	chromedp.ListenTarget(ctx, func(ev interface{}) {
+		switch e := ev.(type) {
+		case *runtime.EventConsoleAPICalled:
+			msgType := string(e.Type)
+			if e.Type == runtime.APITypeWarning {
+				msgType = "warn"
+			}
+			msg := b.recordConsole(ConsoleMessage{Type: msgType, Text: remoteObjectsText(e.Args)})
+			b.emit(EventConsole, msg)
+		case *runtime.EventExceptionThrown:
+			b.recordPageError(exceptionError(e.ExceptionDetails))
+		case *runtime.EventExecutionContextCreated:
+			b.recordFrameContext(tid, e.Context)
+		case *runtime.EventExecutionContextDestroyed:
+			b.forgetFrameContext(tid, e.ExecutionContextID)
+		case *runtime.EventExecutionContextsCleared:
+			b.forgetFrameContext(tid, 0)
+		case *network.EventRequestWillBeSent:
+			key := cdpRequestKey{tid, e.RequestID}
+			if e.RedirectResponse != nil {
+				// A redirect reuses the request ID for the next hop
+				b.requestResponded(key, int(e.RedirectResponse.Status))
+				b.requestFinished(key, "")
+			}
+			headers := make(map[string]string, len(e.Request.Headers))
+			for name, value := range e.Request.Headers {
+				headers[name] = fmt.Sprint(value)
+			}
+			b.requestStarted(key, TrackedRequest{
+				URL:          e.Request.URL,
+				Method:       e.Request.Method,
+				Headers:      headers,
+				ResourceType: string(e.Type),
+			})
+			b.networkActivity(key, true)
+		case *network.EventResponseReceived:
+			b.requestResponded(cdpRequestKey{tid, e.RequestID}, int(e.Response.Status))
+		case *network.EventLoadingFinished:
+			b.requestFinished(cdpRequestKey{tid, e.RequestID}, "")
+			b.networkActivity(cdpRequestKey{tid, e.RequestID}, false)
+		case *network.EventLoadingFailed:
+			b.requestFinished(cdpRequestKey{tid, e.RequestID}, e.ErrorText)
+			b.networkActivity(cdpRequestKey{tid, e.RequestID}, false)
+		case *page.EventJavascriptDialogOpening:
+			b.recordDialog(e.Message)
+			if response, promptText := b.dialogAnswer(); response != "" {
+				go func() {
+					_ = chromedp.Run(ctx, page.HandleJavaScriptDialog(response == "accept").WithPromptText(promptText))
+				}()
+			}
+		case *page.EventFrameStartedLoading:
+			// The main frame of a page target shares the target's ID
+			if string(e.FrameID) == string(tid) {
+				b.recordNavigation()
+			}
+		case *page.EventDomContentEventFired:
+			// Observers do not survive navigation; reinstall on the new document
+			b.watchLock.Lock()
+			opts := b.watchOpts
+			watching := opts != nil && b.watchTab == tid
+			b.watchLock.Unlock()
+			if watching {
+				go func() {
+					_ = chromedp.Run(ctx, chromedp.Evaluate(watchScript(*opts), nil))
+				}()
+			}
+		case *fetch.EventRequestPaused:
+			action := b.continuePaused(tid, e)
+			go func() {
+				_ = chromedp.Run(ctx, action)
+			}()
+		case *fetch.EventAuthRequired:
+			action := b.answerAuth(e)
+			go func() {
+				_ = chromedp.Run(ctx, action)
+			}()
+		case *runtime.EventBindingCalled:
+			if e.Name == watchBinding {
+				if change, err := decodeWatchChange(e.Payload); err == nil {
+					b.emit(EventWatch, change)
+				}
+			}
+		}
+	})
+}
+
+// networkActivity records a request of a tab starting or ending.
+func (b *ChromeDPBackend) networkActivity(key cdpRequestKey, started bool) {
+	b.netLock.Lock()
+	defer b.netLock.Unlock()
+
+	if b.inflight == nil {
+		b.inflight = make(map[cdpRequestKey]bool)
+		b.netActivity = make(map[target.ID]time.Time)
+	}
+	if started {
+		b.inflight[key] = true
+	} else {
+		delete(b.inflight, key)
+	}
+	b.netActivity[key.tab] = time.Now()
+}
+
+// networkIdle reports whether a tab has had no request in flight for
+// networkIdleTime.
+func (b *ChromeDPBackend) networkIdle(tid target.ID) bool {
+	b.netLock.Lock()
+	defer b.netLock.Unlock()
+
+	for key := range b.inflight {
+		if key.tab == tid {
+			return false
+		}
+	}
+	return time.Since(b.netActivity[tid]) >= networkIdleTime
+}
+
+// forgetNetwork drops the in-flight requests of a tab, or of every tab.
+func (b *ChromeDPBackend) forgetNetwork(tid target.ID) {
+	b.netLock.Lock()
+	defer b.netLock.Unlock()
+
+	if tid == "" {
+		b.inflight = nil
+		b.netActivity = nil
+		return
+	}
+	for key := range b.inflight {
+		if key.tab == tid {
+			delete(b.inflight, key)
+		}
+	}
+	delete(b.netActivity, tid)
+}
+
+// StartWatch starts reporting DOM changes in the active tab as watch events.
+func (b *ChromeDPBackend) StartWatch(opts WatchOptions) error {
+	if err := b.StopWatch(); err != nil {
+		return err
+	}
+
+	ctx := b.Context()
+	if len(b.targets) == 0 {
+		return ErrBrowserNotLaunched
+	}
+	tid := b.targets[b.activeTab]
+
+	b.watchLock.Lock()
+	b.watchOpts = &opts
+	b.watchTab = tid
+	b.watchLock.Unlock()
+
+	err := chromedp.Run(ctx,
+		runtime.AddBinding(watchBinding),
+		chromedp.Evaluate(watchScript(opts), nil),
+	)
+	if err != nil {
+		b.watchLock.Lock()
+		b.watchOpts = nil
+		b.watchLock.Unlock()
+	}
+	return err
+}
+
+// StopWatch stops DOM change notifications.
+func (b *ChromeDPBackend) StopWatch() error {
+	b.watchLock.Lock()
+	watching := b.watchOpts != nil
+	tid := b.watchTab
+	b.watchOpts = nil
+	b.watchLock.Unlock()
+
+	if !watching {
+		return nil
+	}
+	ctx, ok := b.tabContexts[tid]
+	if !ok {
+		return nil // Tab already closed
+	}
+	return chromedp.Run(ctx,
+		chromedp.Evaluate(stopWatchScript, nil),
+		runtime.RemoveBinding(watchBinding),
+	)
+}
+
+// remoteObjectsText renders console arguments the way DevTools prints them.
+func remoteObjectsText(args []*runtime.RemoteObject) string {
+	parts := make([]string, 0, len(args))
+	for _, arg := range args {
+		switch {
+		case arg.Value != nil:
+			var s string
+			if err := json.Unmarshal(arg.Value, &s); err == nil {
+				parts = append(parts, s)
+			} else {
+				parts = append(parts, string(arg.Value))
+			}
+		case arg.UnserializableValue != "":
+			parts = append(parts, string(arg.UnserializableValue))
+		case arg.Description != "":
+			parts = append(parts, arg.Description)
+		default:
+			parts = append(parts, string(arg.Type))
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// exceptionError describes an uncaught exception: the first line of its
+// description, e.g. "TypeError: x is undefined", and its JavaScript stack.
+func exceptionError(details *runtime.ExceptionDetails) PageError {
+	if details == nil {
+		return PageError{}
+	}
+	text := details.Text
+	if details.Exception != nil && details.Exception.Description != "" {
+		text = details.Exception.Description
+	}
+	message, rest, _ := strings.Cut(text, "\n")
+	pageErr := PageError{Message: message}
+	if rest != "" {
+		// An Error's description is its stack
+		pageErr.Stack = text
+	} else if details.StackTrace != nil && len(details.StackTrace.CallFrames) > 0 {
+		lines := []string{message}
+		for _, f := range details.StackTrace.CallFrames {
+			name := f.FunctionName
+			if name == "" {
+				name = "<anonymous>"
+			}
+			lines = append(lines, fmt.Sprintf("    at %s (%s:%d:%d)", name, f.URL, f.LineNumber+1, f.ColumnNumber+1))
+		}
+		pageErr.Stack = strings.Join(lines, "\n")
+	}
+	return pageErr
 }
 
 // IsLaunched returns whether the browser is launched.
@@ -296,14 +1394,40 @@ func (b *ChromeDPBackend) Context() context.Context {
 
 // Navigate navigates to a URL.
 func (b *ChromeDPBackend) Navigate(url string, waitUntil string) (string, string, error) {
+	return b.navigate(url, "", waitUntil)
+}
+
+// navigate navigates to a URL with an optional referrer.
+func (b *ChromeDPBackend) navigate(url, referrer, waitUntil string) (string, string, error) {
 	ctx := b.Context()
 
 	var title string
 	var currentURL string
 
+	var nav chromedp.Action = chromedp.Navigate(url)
+	switch {
+	case waitUntil == "domcontentloaded":
+		nav = navigateDOMContentLoaded(url, referrer)
+	case referrer != "":
+		// chromedp.Navigate has no referrer; RunResponse gives the same wait for the load
+		nav = chromedp.ActionFunc(func(ctx context.Context) error {
+			_, err := chromedp.RunResponse(ctx, chromedp.ActionFunc(func(ctx context.Context) error {
+				_, _, errorText, err := page.Navigate(url).WithReferrer(referrer).Do(ctx)
+				if err != nil {
+					return err
+				}
+				if errorText != "" {
+					return fmt.Errorf("page load error %s", errorText)
+				}
+				return nil
+			}))
+			return err
+		})
+	}
+
 	// Simple navigation - WaitReady waits for body to be ready
 	err := chromedp.Run(ctx,
-		chromedp.Navigate(url),
+		nav,
 		chromedp.WaitReady("body"),
 		chromedp.Title(&title),
 		chromedp.Location(&currentURL),
@@ -312,24 +1436,131 @@ func (b *ChromeDPBackend) Navigate(url string, waitUntil string) (string, string
 	if err != nil {
 		return "", "", err
 	}
+	if waitUntil == "networkidle" {
+		if err := b.WaitForLoadState(waitUntil, defaultWaitTimeout); err != nil {
+			return "", "", err
+		}
+	}
 
 	return currentURL, title, nil
 }
 
+// navigateDOMContentLoaded navigates the tab and returns once the new
+// document has fired DOMContentLoaded, without waiting for its images,
+// stylesheets and frames as chromedp.Navigate does.
+func navigateDOMContentLoaded(url, referrer string) chromedp.Action {
+	return chromedp.ActionFunc(func(ctx context.Context) error {
+		var lock sync.Mutex
+		loaded := make(map[cdp.LoaderID]bool)
+		notify := make(chan struct{}, 1)
+		lctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		chromedp.ListenTarget(lctx, func(ev interface{}) {
+			if e, ok := ev.(*page.EventLifecycleEvent); ok && e.Name == "DOMContentLoaded" {
+				lock.Lock()
+				loaded[e.LoaderID] = true
+				lock.Unlock()
+				select {
+				case notify <- struct{}{}:
+				default:
+				}
+			}
+		})
+
+		_, loaderID, errorText, err := page.Navigate(url).WithReferrer(referrer).Do(ctx)
+		if err != nil {
+			return err
+		}
+		if errorText != "" {
+			return fmt.Errorf("page load error %s", errorText)
+		}
+		if loaderID == "" {
+			return nil // same-document navigation, e.g. to a fragment
+		}
+		for {
+			lock.Lock()
+			done := loaded[loaderID]
+			lock.Unlock()
+			if done {
+				return nil
+			}
+			select {
+			case <-notify:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+	})
+}
+
+// NavigateRequest navigates with a custom method, body, headers or
+// referrer. The navigation request is rewritten with Fetch interception;
+// other document requests, including redirects, continue unchanged.
+func (b *ChromeDPBackend) NavigateRequest(req NavigationRequest, waitUntil string) (string, string, error) {
+	if !req.rewritesRequest() {
+		return b.navigate(req.URL, req.Referrer, waitUntil)
+	}
+
+	ctx := b.Context()
+	if len(b.targets) == 0 {
+		return "", "", ErrBrowserNotLaunched
+	}
+	tid := b.targets[b.activeTab]
+	mainFrame := string(tid)
+
+	var rewritten atomic.Bool
+	b.setPausedHook(tid, func(e *fetch.EventRequestPaused) *fetch.ContinueRequestParams {
+		action := fetch.ContinueRequest(e.RequestID)
+		if string(e.FrameID) != mainFrame || e.RedirectedRequestID != "" || !rewritten.CompareAndSwap(false, true) {
+			return action
+		}
+		headers := make(map[string]string, len(e.Request.Headers))
+		for name, value := range e.Request.Headers {
+			headers[name] = fmt.Sprint(value)
+		}
+		var entries []*fetch.HeaderEntry
+		for name, value := range mergeHeaders(headers, req.Headers) {
+			entries = append(entries, &fetch.HeaderEntry{Name: name, Value: value})
+		}
+		action = action.WithMethod(req.method()).WithHeaders(entries)
+		if req.Body != "" {
+			action = action.WithPostData(base64.StdEncoding.EncodeToString([]byte(req.Body)))
+		}
+		return action
+	})
+	defer b.setPausedHook(tid, nil)
+
+	// Keep intercepting every request while credentials or client
+	// certificates are set
+	pattern := &fetch.RequestPattern{URLPattern: "*", RequestStage: fetch.RequestStageRequest}
+	if !b.interceptsRequests() {
+		pattern.ResourceType = network.ResourceTypeDocument
+	}
+	err := chromedp.Run(ctx, fetch.Enable().WithHandleAuthRequests(b.handlesAuth()).WithPatterns([]*fetch.RequestPattern{pattern}))
+	if err != nil {
+		return "", "", err
+	}
+	defer func() { _ = chromedp.Run(ctx, chromedp.ActionFunc(b.applyFetch)) }()
+
+	return b.navigate(req.URL, req.Referrer, waitUntil)
+}
+
 // Click clicks an element.
 func (b *ChromeDPBackend) Click(selector string) error {
 	ctx := b.Context()
 	sel := b.resolveSelector(selector)
-	return chromedp.Run(ctx, chromedp.Click(sel, chromedp.NodeVisible))
+	scope := b.frameScope(ctx)
+	return chromedp.Run(ctx, chromedp.Click(sel, append(scope.query, chromedp.NodeVisible)...))
 }
 
 // Fill clears and fills an input.
 func (b *ChromeDPBackend) Fill(selector, value string) error {
 	ctx := b.Context()
 	sel := b.resolveSelector(selector)
+	scope := b.frameScope(ctx)
 	return chromedp.Run(ctx,
-		chromedp.Clear(sel),
-		chromedp.SendKeys(sel, value),
+		chromedp.Clear(sel, scope.query...),
+		chromedp.SendKeys(sel, value, scope.query...),
 	)
 }
 
@@ -337,15 +1568,16 @@ func (b *ChromeDPBackend) Fill(selector, value string) error {
 func (b *ChromeDPBackend) Type(selector, text string, delay int) error {
 	ctx := b.Context()
 	sel := b.resolveSelector(selector)
+	scope := b.frameScope(ctx)
 
 	if delay > 0 {
 		// Type with delay between keystrokes not directly supported,
 		// we'll type character by character
-		if err := chromedp.Run(ctx, chromedp.Focus(sel)); err != nil {
+		if err := chromedp.Run(ctx, chromedp.Focus(sel, scope.query...)); err != nil {
 			return err
 		}
 		for _, char := range text {
-			if err := chromedp.Run(ctx, chromedp.SendKeys(sel, string(char))); err != nil {
+			if err := chromedp.Run(ctx, chromedp.SendKeys(sel, string(char), scope.query...)); err != nil {
 				return err
 			}
 			time.Sleep(time.Duration(delay) * time.Millisecond)
@@ -353,46 +1585,95 @@ func (b *ChromeDPBackend) Type(selector, text string, delay int) error {
 		return nil
 	}
 
-	return chromedp.Run(ctx, chromedp.SendKeys(sel, text))
+	return chromedp.Run(ctx, chromedp.SendKeys(sel, text, scope.query...))
 }
 
-// Press presses a key.
+// Press presses a key or a chord such as Control+a, named as in Playwright.
 func (b *ChromeDPBackend) Press(key string, selector string) error {
 	ctx := b.Context()
+	events, err := keyEvents(key)
+	if err != nil {
+		return err
+	}
+	press := chromedp.ActionFunc(func(ctx context.Context) error {
+		for _, ev := range events {
+			if err := ev.Do(ctx); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
 	if selector != "" {
 		sel := b.resolveSelector(selector)
-		return chromedp.Run(ctx,
-			chromedp.Focus(sel),
-			chromedp.KeyEvent(key),
-		)
+		scope := b.frameScope(ctx)
+		return chromedp.Run(ctx, chromedp.Focus(sel, scope.query...), press)
+	}
+	return chromedp.Run(ctx, press)
+}
+
+// InsertText inserts text with Input.insertText, after focusing the
+// element if one is given.
+func (b *ChromeDPBackend) InsertText(selector, text string) error {
+	ctx := b.Context()
+	if selector != "" {
+		sel := b.resolveSelector(selector)
+		if err := chromedp.Run(ctx, chromedp.Focus(sel, b.frameScope(ctx).query...)); err != nil {
+			return err
+		}
 	}
-	return chromedp.Run(ctx, chromedp.KeyEvent(key))
+	return chromedp.Run(ctx, input.InsertText(text))
 }
 
 // Hover hovers over an element.
 func (b *ChromeDPBackend) Hover(selector string) error {
 	ctx := b.Context()
 	sel := b.resolveSelector(selector)
+	scope := b.frameScope(ctx)
 
-	var x, y float64
-	err := chromedp.Run(ctx,
-		chromedp.ScrollIntoView(sel),
-		chromedp.Evaluate(fmt.Sprintf(`
-			(function() {
-				const el = document.querySelector(%q);
-				if (!el) return {x: 0, y: 0};
-				const rect = el.getBoundingClientRect();
-				return {x: rect.left + rect.width/2, y: rect.top + rect.height/2};
-			})()
-		`, sel), &struct {
-			X *float64 `json:"x"`
-			Y *float64 `json:"y"`
-		}{&x, &y}),
-	)
-	if err != nil {
+	// MouseClickNode scrolls the element into view and finds its center
+	// from content quads, which are in page coordinates even in a frame
+	var nodes []*cdp.Node
+	if err := chromedp.Run(ctx, chromedp.Nodes(sel, &nodes, scope.query...)); err != nil {
 		return err
 	}
-	return chromedp.Run(ctx, chromedp.MouseClickXY(x, y, chromedp.ButtonNone))
+	return chromedp.Run(ctx, chromedp.MouseClickNode(nodes[0], chromedp.ButtonNone))
+}
+
+// Tap taps an element with Input.dispatchTouchEvent, for pages that only
+// listen to touch events. The browser follows it with the mouse events and
+// click of a tap gesture.
+func (b *ChromeDPBackend) Tap(selector string) error {
+	ctx := b.Context()
+	sel := b.resolveSelector(selector)
+	scope := b.frameScope(ctx)
+
+	var nodes []*cdp.Node
+	if err := chromedp.Run(ctx, chromedp.Nodes(sel, &nodes, append(scope.query, chromedp.NodeVisible)...)); err != nil {
+		return err
+	}
+	return chromedp.Run(ctx, chromedp.ActionFunc(func(ctx context.Context) error {
+		node := nodes[0].NodeID
+		if err := dom.ScrollIntoViewIfNeeded().WithNodeID(node).Do(ctx); err != nil {
+			return err
+		}
+		// Content quads are in page coordinates even in a frame
+		quads, err := dom.GetContentQuads().WithNodeID(node).Do(ctx)
+		if err != nil {
+			return err
+		}
+		if len(quads) == 0 || len(quads[0]) < 8 {
+			return fmt.Errorf("element has no visible area: %s", selector)
+		}
+		var x, y float64
+		for i := 0; i < 8; i += 2 {
+			x += quads[0][i] / 4
+			y += quads[0][i+1] / 4
+		}
+		if err := input.DispatchTouchEvent(input.TouchStart, []*input.TouchPoint{{X: x, Y: y}}).Do(ctx); err != nil {
+			return err
+		}
+		return input.DispatchTouchEvent(input.TouchEnd, []*input.TouchPoint{}).Do(ctx)
+	}))
 }
 
 // Screenshot takes a screenshot.
@@ -404,7 +1685,7 @@ func (b *ChromeDPBackend) Screenshot(fullPage bool, selector string, quality int
 
 	if selector != "" {
 		sel := b.resolveSelector(selector)
-		err = chromedp.Run(ctx, chromedp.Screenshot(sel, &buf))
+		err = chromedp.Run(ctx, chromedp.Screenshot(sel, &buf, b.frameScope(ctx).query...))
 	} else if fullPage {
 		err = chromedp.Run(ctx, chromedp.FullScreenshot(&buf, quality))
 	} else {
@@ -414,12 +1695,218 @@ func (b *ChromeDPBackend) Screenshot(fullPage bool, selector string, quality int
 	return buf, err
 }
 
+// PDF prints the page with Page.printToPDF.
+func (b *ChromeDPBackend) PDF(opts PDFOptions) ([]byte, error) {
+	_, size, err := paperSize(opts.Format)
+	if err != nil {
+		return nil, err
+	}
+	top, right, bottom, left, err := opts.Margin.inches()
+	if err != nil {
+		return nil, err
+	}
+
+	var buf []byte
+	err = chromedp.Run(b.Context(), chromedp.ActionFunc(func(ctx context.Context) error {
+		var err error
+		buf, _, err = page.PrintToPDF().
+			WithPaperWidth(size[0]).
+			WithPaperHeight(size[1]).
+			WithLandscape(opts.Landscape).
+			WithPrintBackground(opts.PrintBackground).
+			WithMarginTop(top).
+			WithMarginRight(right).
+			WithMarginBottom(bottom).
+			WithMarginLeft(left).
+			Do(ctx)
+		return err
+	}))
+	return buf, err
+}
+
+// StartScreencast starts Page.startScreencast in the active tab and passes
+// each frame to onFrame, acknowledging it so Chrome sends the next.
+func (b *ChromeDPBackend) StartScreencast(opts ScreencastOptions, onFrame func(ScreencastFrame)) error {
+	b.screencastLock.Lock()
+	defer b.screencastLock.Unlock()
+	// A screencast ends with the tab it was started in
+	if b.screencastCallback != nil && b.screencastCtx.Err() == nil {
+		return fmt.Errorf("screencast already started")
+	}
+
+	ctx := b.Context()
+	b.screencastGen++
+	gen := b.screencastGen
+	chromedp.ListenTarget(ctx, func(ev interface{}) {
+		e, ok := ev.(*page.EventScreencastFrame)
+		if !ok {
+			return
+		}
+		b.screencastLock.Lock()
+		callback := b.screencastCallback
+		current := gen == b.screencastGen
+		b.screencastLock.Unlock()
+		if !current || callback == nil {
+			return
+		}
+		go func() { _ = chromedp.Run(ctx, page.ScreencastFrameAck(e.SessionID)) }()
+
+		var frame cdpScreencastFrame
+		if data, err := json.Marshal(e); err == nil && json.Unmarshal(data, &frame) == nil {
+			callback(frame.frame())
+		}
+	})
+
+	start := page.StartScreencast().
+		WithFormat(page.ScreencastFormat(opts.Format)).
+		WithEveryNthFrame(int64(opts.EveryNthFrame))
+	if opts.Format == "jpeg" {
+		start = start.WithQuality(int64(opts.Quality))
+	}
+	if opts.MaxWidth > 0 {
+		start = start.WithMaxWidth(int64(opts.MaxWidth))
+	}
+	if opts.MaxHeight > 0 {
+		start = start.WithMaxHeight(int64(opts.MaxHeight))
+	}
+	b.screencastCallback, b.screencastCtx = onFrame, ctx
+	if err := chromedp.Run(ctx, start); err != nil {
+		b.screencastCallback, b.screencastCtx = nil, nil
+		return err
+	}
+	return nil
+}
+
+// StopScreencast stops the screencast. Frames still in flight are dropped.
+func (b *ChromeDPBackend) StopScreencast() error {
+	b.screencastLock.Lock()
+	ctx := b.screencastCtx
+	b.screencastCallback, b.screencastCtx = nil, nil
+	b.screencastGen++
+	b.screencastLock.Unlock()
+	if ctx == nil || ctx.Err() != nil {
+		return nil // the tab is gone, and its screencast with it
+	}
+	return chromedp.Run(ctx, page.StopScreencast())
+}
+
+// traceCategories are the trace categories the Performance panel of
+// DevTools records, as in Puppeteer.
+var traceCategories = []string{
+	"devtools.timeline",
+	"v8.execute",
+	"disabled-by-default-devtools.timeline",
+	"disabled-by-default-devtools.timeline.frame",
+	"toplevel",
+	"blink.console",
+	"blink.user_timing",
+	"latencyInfo",
+	"disabled-by-default-devtools.timeline.stack",
+	"disabled-by-default-v8.cpu_profiler",
+}
+
+// StartTracing starts the Tracing domain in the current tab. Snapshots are
+// not supported and ignored.
+func (b *ChromeDPBackend) StartTracing(opts TraceOptions) error {
+	b.traceLock.Lock()
+	defer b.traceLock.Unlock()
+	// A trace ends with the tab it was started in
+	if b.traceDone != nil && b.traceCtx.Err() == nil {
+		return fmt.Errorf("tracing already started")
+	}
+
+	categories := traceCategories
+	if opts.Screenshots {
+		categories = append(categories[:len(categories):len(categories)], "disabled-by-default-devtools.screenshot")
+	}
+	ctx := b.Context()
+	done := make(chan *tracing.EventTracingComplete, 1)
+	chromedp.ListenTarget(ctx, func(ev interface{}) {
+		if ev, ok := ev.(*tracing.EventTracingComplete); ok {
+			select {
+			case done <- ev:
+			default:
+			}
+		}
+	})
+	err := chromedp.Run(ctx, tracing.Start().
+		WithTraceConfig(&tracing.TraceConfig{
+			IncludedCategories: categories,
+			ExcludedCategories: []string{"*"},
+		}).
+		WithTransferMode(tracing.TransferModeReturnAsStream))
+	if err != nil {
+		return err
+	}
+	b.traceCtx, b.traceDone = ctx, done
+	return nil
+}
+
+// StopTracing ends tracing and streams the Chrome trace JSON to path.
+func (b *ChromeDPBackend) StopTracing(path string) error {
+	b.traceLock.Lock()
+	ctx, done := b.traceCtx, b.traceDone
+	b.traceCtx, b.traceDone = nil, nil
+	b.traceLock.Unlock()
+	if done == nil {
+		return fmt.Errorf("tracing not started")
+	}
+
+	if err := chromedp.Run(ctx, tracing.End()); err != nil {
+		return err
+	}
+	var complete *tracing.EventTracingComplete
+	select {
+	case complete = <-done:
+	case <-ctx.Done():
+		return fmt.Errorf("tab closed before the trace was complete")
+	case <-time.After(30 * time.Second):
+		return fmt.Errorf("timeout waiting for the trace")
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	err = chromedp.Run(ctx, chromedp.ActionFunc(func(ctx context.Context) error {
+		defer cdpio.Close(complete.Stream).Do(ctx)
+		for {
+			var chunk cdpio.ReadReturns
+			if err := cdp.Execute(ctx, cdpio.CommandRead, cdpio.Read(complete.Stream), &chunk); err != nil {
+				return err
+			}
+			data := []byte(chunk.Data)
+			if chunk.Base64encoded {
+				var err error
+				if data, err = base64.StdEncoding.DecodeString(chunk.Data); err != nil {
+					return err
+				}
+			}
+			if _, err := f.Write(data); err != nil {
+				return err
+			}
+			if chunk.EOF {
+				return nil
+			}
+		}
+	}))
+	if err != nil {
+		return err
+	}
+	return f.Close()
+}
+
 // Evaluate runs JavaScript and returns the result.
 func (b *ChromeDPBackend) Evaluate(script string) (interface{}, error) {
 	ctx := b.Context()
 
+	// Await promises like Playwright's Evaluate does
 	var result interface{}
-	err := chromedp.Run(ctx, chromedp.Evaluate(script, &result))
+	opts := append(b.frameScope(ctx).eval, func(p *runtime.EvaluateParams) *runtime.EvaluateParams {
+		return p.WithAwaitPromise(true)
+	})
+	err := chromedp.Run(ctx, chromedp.Evaluate(script, &result, opts...))
 	return result, err
 }
 
@@ -429,7 +1916,7 @@ func (b *ChromeDPBackend) GetText(selector string) (string, error) {
 	sel := b.resolveSelector(selector)
 
 	var text string
-	err := chromedp.Run(ctx, chromedp.Text(sel, &text))
+	err := chromedp.Run(ctx, chromedp.Text(sel, &text, b.frameScope(ctx).query...))
 	return text, err
 }
 
@@ -440,7 +1927,7 @@ func (b *ChromeDPBackend) GetAttribute(selector, attr string) (string, error) {
 
 	var value string
 	var ok bool
-	err := chromedp.Run(ctx, chromedp.AttributeValue(sel, attr, &value, &ok))
+	err := chromedp.Run(ctx, chromedp.AttributeValue(sel, attr, &value, &ok, b.frameScope(ctx).query...))
 	if err != nil {
 		return "", err
 	}
@@ -455,12 +1942,14 @@ func (b *ChromeDPBackend) GetHTML(selector string, outer bool) (string, error) {
 	ctx := b.Context()
 	sel := b.resolveSelector(selector)
 
+	scope := b.frameScope(ctx)
+
 	var html string
 	if outer {
-		err := chromedp.Run(ctx, chromedp.OuterHTML(sel, &html))
+		err := chromedp.Run(ctx, chromedp.OuterHTML(sel, &html, scope.query...))
 		return html, err
 	}
-	err := chromedp.Run(ctx, chromedp.InnerHTML(sel, &html))
+	err := chromedp.Run(ctx, chromedp.InnerHTML(sel, &html, scope.query...))
 	return html, err
 }
 
@@ -480,7 +1969,7 @@ func (b *ChromeDPBackend) IsVisible(selector string) (bool, error) {
 			       style.opacity !== '0' &&
 			       el.offsetParent !== null;
 		})()
-	`, sel), &visible))
+	`, sel), &visible, b.frameScope(ctx).eval...))
 
 	return visible, err
 }
@@ -496,19 +1985,54 @@ func (b *ChromeDPBackend) Wait(selector string, timeout int, state string) error
 	}
 
 	sel := b.resolveSelector(selector)
+	scope := b.frameScope(ctx)
 
 	switch state {
 	case "hidden":
-		return chromedp.Run(ctx, chromedp.WaitNotPresent(sel))
+		return chromedp.Run(ctx, chromedp.WaitNotPresent(sel, scope.query...))
 	case "detached":
-		return chromedp.Run(ctx, chromedp.WaitNotPresent(sel))
+		return chromedp.Run(ctx, chromedp.WaitNotPresent(sel, scope.query...))
 	case "attached":
-		return chromedp.Run(ctx, chromedp.WaitReady(sel))
+		return chromedp.Run(ctx, chromedp.WaitReady(sel, scope.query...))
 	default: // visible
-		return chromedp.Run(ctx, chromedp.WaitVisible(sel))
+		return chromedp.Run(ctx, chromedp.WaitVisible(sel, scope.query...))
 	}
 }
 
+// WaitForLoadState waits for a load state of the active tab: the document's
+// readyState for domcontentloaded and load, then for networkidle no request
+// of the tab in flight for networkIdleTime.
+func (b *ChromeDPBackend) WaitForLoadState(state string, timeout int) error {
+	ctx := b.Context()
+	var tid target.ID
+	if b.activeTab < len(b.targets) {
+		tid = b.targets[b.activeTab]
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(timeout)*time.Millisecond)
+	defer cancel()
+
+	ready := func() bool {
+		var readyState string
+		// Evaluation fails while a navigation swaps the document; poll again
+		if err := chromedp.Run(ctx, chromedp.Evaluate("document.readyState", &readyState)); err != nil {
+			return false
+		}
+		if readyState != "complete" && (state != "domcontentloaded" || readyState != "interactive") {
+			return false
+		}
+		return state != "networkidle" || b.networkIdle(tid)
+	}
+	for !ready() {
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("timeout %dms waiting for load state %s", timeout, state)
+		case <-time.After(waitPollInterval):
+		}
+	}
+	return nil
+}
+
 // WaitForTimeout waits for specified milliseconds.
 func (b *ChromeDPBackend) WaitForTimeout(ms int) error {
 	time.Sleep(time.Duration(ms) * time.Millisecond)
@@ -537,10 +2061,47 @@ func (b *ChromeDPBackend) Back() error {
 	return chromedp.Run(ctx, chromedp.NavigateBack())
 }
 
-// Forward navigates forward.
-func (b *ChromeDPBackend) Forward() error {
+// Forward navigates forward.
+func (b *ChromeDPBackend) Forward() error {
+	ctx := b.Context()
+	return chromedp.Run(ctx, chromedp.NavigateForward())
+}
+
+// History returns the session history of the current tab.
+func (b *ChromeDPBackend) History() (*NavigationHistory, error) {
+	var current int64
+	var entries []*page.NavigationEntry
+	err := chromedp.Run(b.Context(), chromedp.ActionFunc(func(ctx context.Context) error {
+		var err error
+		current, entries, err = page.GetNavigationHistory().Do(ctx)
+		return err
+	}))
+	if err != nil {
+		return nil, err
+	}
+	history := &NavigationHistory{Current: int(current), Entries: []HistoryEntry{}}
+	for i, e := range entries {
+		history.Entries = append(history.Entries, HistoryEntry{Index: i, URL: e.URL, Title: e.Title})
+	}
+	return history, nil
+}
+
+// GoToHistoryEntry navigates to the history entry at index.
+func (b *ChromeDPBackend) GoToHistoryEntry(index int) error {
 	ctx := b.Context()
-	return chromedp.Run(ctx, chromedp.NavigateForward())
+	var entries []*page.NavigationEntry
+	err := chromedp.Run(ctx, chromedp.ActionFunc(func(ctx context.Context) error {
+		var err error
+		_, entries, err = page.GetNavigationHistory().Do(ctx)
+		return err
+	}))
+	if err != nil {
+		return err
+	}
+	if index < 0 || index >= len(entries) {
+		return fmt.Errorf("history index %d out of range", index)
+	}
+	return chromedp.Run(ctx, chromedp.NavigateToHistoryEntry(entries[index].ID))
 }
 
 // Reload reloads the page.
@@ -563,19 +2124,39 @@ func (b *ChromeDPBackend) Count(selector string) (int, error) {
 	var count int
 	err := chromedp.Run(ctx, chromedp.Evaluate(fmt.Sprintf(`
 		document.querySelectorAll(%q).length
-	`, sel), &count))
+	`, sel), &count, b.frameScope(ctx).eval...))
 
 	return count, err
 }
 
 // NewTab creates a new tab.
 func (b *ChromeDPBackend) NewTab(url string) (int, error) {
+	return b.openTab(url, false)
+}
+
+// NewWindow opens a tab in a new browser window, with its own viewport
+// size when viewport is set.
+func (b *ChromeDPBackend) NewWindow(viewport *Viewport) (int, error) {
+	index, err := b.openTab("", true)
+	if err != nil {
+		return 0, err
+	}
+	if viewport != nil {
+		if err := b.SetViewport(viewport.Width, viewport.Height); err != nil {
+			return 0, err
+		}
+	}
+	return index, nil
+}
+
+// openTab creates a tab, in a new window if newWindow, and makes it active.
+func (b *ChromeDPBackend) openTab(url string, newWindow bool) (int, error) {
 	// Create new target
 	ctx := b.Context()
 
 	var targetID target.ID
 	if err := chromedp.Run(ctx, chromedp.ActionFunc(func(ctx context.Context) error {
-		createTarget := target.CreateTarget("about:blank")
+		createTarget := target.CreateTarget("about:blank").WithNewWindow(newWindow)
 		tid, err := createTarget.Do(ctx)
 		if err != nil {
 			return err
@@ -593,6 +2174,10 @@ func (b *ChromeDPBackend) NewTab(url string) (int, error) {
 	b.tabContexts[targetID] = newCtx
 	b.tabCancels[targetID] = newCancel
 	b.activeTab = len(b.targets) - 1
+	b.listenTab(newCtx, targetID)
+	if err := b.prepareTab(newCtx); err != nil {
+		return 0, err
+	}
 
 	// Navigate if URL provided
 	if url != "" && url != "about:blank" {
@@ -613,6 +2198,11 @@ func (b *ChromeDPBackend) SwitchTab(index int) error {
 	return nil
 }
 
+// BringToFront activates the active tab with Page.bringToFront.
+func (b *ChromeDPBackend) BringToFront() error {
+	return chromedp.Run(b.Context(), page.BringToFront())
+}
+
 // CloseTab closes a tab.
 func (b *ChromeDPBackend) CloseTab(index int) error {
 	if index < 0 || index >= len(b.targets) {
@@ -625,6 +2215,11 @@ func (b *ChromeDPBackend) CloseTab(index int) error {
 		delete(b.tabContexts, tid)
 		delete(b.tabCancels, tid)
 	}
+	b.frameLock.Lock()
+	delete(b.frames, tid)
+	delete(b.frameContexts, tid)
+	b.frameLock.Unlock()
+	b.forgetNetwork(tid)
 
 	// Remove from targets
 	b.targets = append(b.targets[:index], b.targets[index+1:]...)
@@ -648,10 +2243,16 @@ func (b *ChromeDPBackend) ListTabs() ([]TabInfo, error) {
 		ctx := b.tabContexts[tid]
 		var url, title string
 
+		var window browser.WindowID
 		if ctx != nil {
 			_ = chromedp.Run(ctx,
 				chromedp.Location(&url),
 				chromedp.Title(&title),
+				chromedp.ActionFunc(func(ctx context.Context) error {
+					var err error
+					window, _, err = browser.GetWindowForTarget().Do(ctx)
+					return err
+				}),
 			)
 		}
 
@@ -660,12 +2261,205 @@ func (b *ChromeDPBackend) ListTabs() ([]TabInfo, error) {
 			URL:    url,
 			Title:  title,
 			Active: i == b.activeTab,
+			Window: int(window),
 		}
 	}
 
 	return tabs, nil
 }
 
+// chromedpFrame scopes element queries and scripts to the frame selected
+// with SwitchFrame. It is empty for the main frame.
+type chromedpFrame struct {
+	owner *cdp.Node                 // the iframe element
+	query []chromedp.QueryOption    // queries run in the frame's document
+	eval  []chromedp.EvaluateOption // scripts run in the frame's main world
+}
+
+// frameLookupTimeout bounds finding the selected frame's element.
+const frameLookupTimeout = 5 * time.Second
+
+// SwitchFrame scopes the current tab's selectors and scripts to the iframe
+// matching ref.
+func (b *ChromeDPBackend) SwitchFrame(ref FrameRef) error {
+	ctx := b.Context()
+	if len(b.targets) == 0 {
+		return ErrBrowserNotLaunched
+	}
+	tid := b.targets[b.activeTab]
+
+	var id cdp.FrameID
+	if ref.Selector != "" {
+		// Matched in the current frame, so frames nest
+		scope := b.frameScope(ctx)
+		sel := b.resolveSelector(ref.Selector)
+		var nodes []*cdp.Node
+		if err := chromedp.Run(ctx, chromedp.Nodes(sel, &nodes, append(scope.query, chromedp.AtLeast(0))...)); err != nil {
+			return err
+		}
+		if len(nodes) == 0 {
+			return frameNotFound(ref)
+		}
+		if nodes[0].FrameID == "" {
+			return fmt.Errorf("%s is not a frame", ref.Selector)
+		}
+		id = nodes[0].FrameID
+	} else {
+		var tree *page.FrameTree
+		err := chromedp.Run(ctx, chromedp.ActionFunc(func(ctx context.Context) error {
+			var err error
+			tree, err = page.GetFrameTree().Do(ctx)
+			return err
+		}))
+		if err != nil {
+			return err
+		}
+		var ids []cdp.FrameID
+		var frames []frameInfo
+		var walk func(children []*page.FrameTree)
+		walk = func(children []*page.FrameTree) {
+			for _, child := range children {
+				ids = append(ids, child.Frame.ID)
+				frames = append(frames, frameInfo{Name: child.Frame.Name, URL: child.Frame.URL})
+				walk(child.ChildFrames)
+			}
+		}
+		walk(tree.ChildFrames)
+		i := matchFrame(frames, ref)
+		if i < 0 {
+			return frameNotFound(ref)
+		}
+		id = ids[i]
+	}
+
+	b.frameLock.Lock()
+	if b.frames == nil {
+		b.frames = make(map[target.ID]cdp.FrameID)
+	}
+	b.frames[tid] = id
+	b.frameLock.Unlock()
+	b.clearRefs()
+	return nil
+}
+
+// MainFrame scopes the current tab's selectors and scripts to the top
+// document again.
+func (b *ChromeDPBackend) MainFrame() error {
+	if len(b.targets) == 0 {
+		return ErrBrowserNotLaunched
+	}
+	b.frameLock.Lock()
+	delete(b.frames, b.targets[b.activeTab])
+	b.frameLock.Unlock()
+	b.clearRefs()
+	return nil
+}
+
+// frameScope returns where the current tab's element queries and scripts
+// run. A selected frame that has gone away, for example because the page
+// navigated, drops back to the main frame.
+func (b *ChromeDPBackend) frameScope(ctx context.Context) chromedpFrame {
+	if len(b.targets) == 0 {
+		return chromedpFrame{}
+	}
+	tid := b.targets[b.activeTab]
+	b.frameLock.Lock()
+	id, ok := b.frames[tid]
+	execCtx := b.frameContexts[tid][id]
+	b.frameLock.Unlock()
+	if !ok {
+		return chromedpFrame{}
+	}
+
+	lookupCtx, cancel := context.WithTimeout(ctx, frameLookupTimeout)
+	defer cancel()
+	var owner *cdp.Node
+	err := chromedp.Run(lookupCtx, chromedp.ActionFunc(func(ctx context.Context) error {
+		backendID, nodeID, err := dom.GetFrameOwner(id).Do(ctx)
+		if err != nil {
+			return err
+		}
+		if nodeID == 0 {
+			ids, err := dom.PushNodesByBackendIDsToFrontend([]cdp.BackendNodeID{backendID}).Do(ctx)
+			if err != nil {
+				return err
+			}
+			nodeID = ids[0]
+		}
+		var nodes []*cdp.Node
+		if err := chromedp.Nodes([]cdp.NodeID{nodeID}, &nodes, chromedp.ByNodeID).Do(ctx); err != nil {
+			return err
+		}
+		owner = nodes[0]
+		if execCtx == 0 {
+			// Contexts created before the tab was listened to are unknown;
+			// an isolated world sees the same DOM
+			execCtx, err = page.CreateIsolatedWorld(id).WithWorldName("agent-browser-go").Do(ctx)
+		}
+		return err
+	}))
+	if err != nil || owner.ContentDocument == nil {
+		b.frameLock.Lock()
+		if b.frames[tid] == id {
+			delete(b.frames, tid)
+		}
+		b.frameLock.Unlock()
+		return chromedpFrame{}
+	}
+
+	return chromedpFrame{
+		owner: owner,
+		query: []chromedp.QueryOption{chromedp.ByQuery, chromedp.FromNode(owner)},
+		eval: []chromedp.EvaluateOption{func(p *runtime.EvaluateParams) *runtime.EvaluateParams {
+			return p.WithContextID(execCtx)
+		}},
+	}
+}
+
+// recordFrameContext keeps the main-world execution context of each of a
+// tab's frames, where scripts for a selected frame run.
+func (b *ChromeDPBackend) recordFrameContext(tid target.ID, c *runtime.ExecutionContextDescription) {
+	var aux struct {
+		FrameID   cdp.FrameID `json:"frameId"`
+		IsDefault bool        `json:"isDefault"`
+	}
+	if json.Unmarshal(c.AuxData, &aux) != nil || !aux.IsDefault || aux.FrameID == "" {
+		return
+	}
+	b.frameLock.Lock()
+	defer b.frameLock.Unlock()
+	if b.frameContexts == nil {
+		b.frameContexts = make(map[target.ID]map[cdp.FrameID]runtime.ExecutionContextID)
+	}
+	if b.frameContexts[tid] == nil {
+		b.frameContexts[tid] = make(map[cdp.FrameID]runtime.ExecutionContextID)
+	}
+	b.frameContexts[tid][aux.FrameID] = c.ID
+}
+
+// forgetFrameContext drops a destroyed execution context of a tab, or all
+// of them when id is 0.
+func (b *ChromeDPBackend) forgetFrameContext(tid target.ID, id runtime.ExecutionContextID) {
+	b.frameLock.Lock()
+	defer b.frameLock.Unlock()
+	if id == 0 {
+		delete(b.frameContexts, tid)
+		return
+	}
+	for frame, ctxID := range b.frameContexts[tid] {
+		if ctxID == id {
+			delete(b.frameContexts[tid], frame)
+		}
+	}
+}
+
+// clearRefs drops snapshot refs, which belong to the frame they were taken in.
+func (b *ChromeDPBackend) clearRefs() {
+	b.refLock.Lock()
+	b.refMap = make(RefMap)
+	b.refLock.Unlock()
+}
+
 // resolveSelector resolves refs to actual selectors.
 func (b *ChromeDPBackend) resolveSelector(selector string) string {
 	// Check if it's a ref
@@ -753,7 +2547,7 @@ func (b *ChromeDPBackend) GetSnapshot(opts SnapshotOptions) (*EnhancedSnapshot,
 	`
 
 	var treeData *AXNode
-	err := chromedp.Run(ctx, chromedp.Evaluate(script, &treeData))
+	err := chromedp.Run(ctx, chromedp.Evaluate(script, &treeData, b.frameScope(ctx).eval...))
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to get accessibility tree: %w", err)
@@ -763,9 +2557,11 @@ func (b *ChromeDPBackend) GetSnapshot(opts SnapshotOptions) (*EnhancedSnapshot,
 	snapshot := BuildSnapshotFromNodes(treeData, opts)
 
 	// Update ref map
-	b.refLock.Lock()
-	b.refMap = snapshot.Refs
-	b.refLock.Unlock()
+	if !opts.keepRefs {
+		b.refLock.Lock()
+		b.refMap = snapshot.Refs
+		b.refLock.Unlock()
+	}
 
 	return snapshot, nil
 }
@@ -787,17 +2583,18 @@ func (b *ChromeDPBackend) GetRefMap() RefMap {
 func (b *ChromeDPBackend) Check(selector string) error {
 	ctx := b.Context()
 	sel := b.resolveSelector(selector)
+	scope := b.frameScope(ctx)
 
 	return chromedp.Run(ctx, chromedp.ActionFunc(func(ctx context.Context) error {
 		// Check if already checked
 		var checked bool
-		if err := chromedp.Evaluate(fmt.Sprintf(`document.querySelector(%q).checked`, sel), &checked).Do(ctx); err != nil {
+		if err := chromedp.Evaluate(fmt.Sprintf(`document.querySelector(%q).checked`, sel), &checked, scope.eval...).Do(ctx); err != nil {
 			return err
 		}
 		if checked {
 			return nil
 		}
-		return chromedp.Click(sel).Do(ctx)
+		return chromedp.Click(sel, scope.query...).Do(ctx)
 	}))
 }
 
@@ -805,47 +2602,131 @@ func (b *ChromeDPBackend) Check(selector string) error {
 func (b *ChromeDPBackend) Uncheck(selector string) error {
 	ctx := b.Context()
 	sel := b.resolveSelector(selector)
+	scope := b.frameScope(ctx)
 
 	return chromedp.Run(ctx, chromedp.ActionFunc(func(ctx context.Context) error {
 		// Check if already unchecked
 		var checked bool
-		if err := chromedp.Evaluate(fmt.Sprintf(`document.querySelector(%q).checked`, sel), &checked).Do(ctx); err != nil {
+		if err := chromedp.Evaluate(fmt.Sprintf(`document.querySelector(%q).checked`, sel), &checked, scope.eval...).Do(ctx); err != nil {
 			return err
 		}
 		if !checked {
 			return nil
 		}
-		return chromedp.Click(sel).Do(ctx)
+		return chromedp.Click(sel, scope.query...).Do(ctx)
 	}))
 }
 
-// Select selects dropdown option(s).
-func (b *ChromeDPBackend) Select(selector string, values []string) error {
+// Select selects dropdown option(s) by value, label or index.
+func (b *ChromeDPBackend) Select(selector string, values []string, by string) error {
 	ctx := b.Context()
 	sel := b.resolveSelector(selector)
+	scope := b.frameScope(ctx)
 
-	return chromedp.Run(ctx, chromedp.SetValue(sel, values[0]))
+	args, err := json.Marshal(values)
+	if err != nil {
+		return err
+	}
+	var failure string
+	err = chromedp.Run(ctx,
+		chromedp.WaitReady(sel, scope.query...),
+		chromedp.Evaluate(fmt.Sprintf(`(%s)(document.querySelector(%q), %s, %q)`,
+			selectOptionsScript, sel, args, by), &failure, scope.eval...),
+	)
+	if err != nil {
+		return err
+	}
+	if failure != "" {
+		return fmt.Errorf("select %s: %s", selector, failure)
+	}
+	return nil
+}
+
+// Upload sets the files of a file input with DOM.setFileInputFiles.
+func (b *ChromeDPBackend) Upload(selector string, files []string) error {
+	ctx := b.Context()
+	sel := b.resolveSelector(selector)
+	return chromedp.Run(ctx, chromedp.SetUploadFiles(sel, files, b.frameScope(ctx).query...))
+}
+
+// InputMouse dispatches a raw mouse event with Input.dispatchMouseEvent.
+func (b *ChromeDPBackend) InputMouse(ev MouseEvent) error {
+	return chromedp.Run(b.Context(), mouseEventParams(ev))
+}
+
+// InputKeyboard dispatches a raw key event with Input.dispatchKeyEvent.
+func (b *ChromeDPBackend) InputKeyboard(ev KeyEvent) error {
+	params, err := keyEventParams(ev)
+	if err != nil {
+		return err
+	}
+	return chromedp.Run(b.Context(), params)
 }
 
 // Focus focuses an element.
 func (b *ChromeDPBackend) Focus(selector string) error {
 	ctx := b.Context()
 	sel := b.resolveSelector(selector)
-	return chromedp.Run(ctx, chromedp.Focus(sel))
+	return chromedp.Run(ctx, chromedp.Focus(sel, b.frameScope(ctx).query...))
 }
 
 // Clear clears an input.
 func (b *ChromeDPBackend) Clear(selector string) error {
 	ctx := b.Context()
 	sel := b.resolveSelector(selector)
-	return chromedp.Run(ctx, chromedp.Clear(sel))
+	return chromedp.Run(ctx, chromedp.Clear(sel, b.frameScope(ctx).query...))
+}
+
+// DispatchEvent fires a DOM event on an element.
+func (b *ChromeDPBackend) DispatchEvent(selector, event string, init map[string]interface{}) error {
+	ctx := b.Context()
+	sel := b.resolveSelector(selector)
+	scope := b.frameScope(ctx)
+
+	initJSON, err := json.Marshal(init)
+	if err != nil {
+		return err
+	}
+	var dispatched *bool
+	err = chromedp.Run(ctx,
+		chromedp.WaitReady(sel, scope.query...),
+		chromedp.Evaluate(fmt.Sprintf(`(%s)(document.querySelector(%q), %q, %s)`,
+			dispatchEventScript, sel, event, initJSON), &dispatched, scope.eval...),
+	)
+	if err != nil {
+		return err
+	}
+	if dispatched == nil {
+		return fmt.Errorf("%w: %s", ErrElementNotFound, selector)
+	}
+	return nil
+}
+
+// SelectAll focuses an element and selects all its text.
+func (b *ChromeDPBackend) SelectAll(selector string) error {
+	ctx := b.Context()
+	sel := b.resolveSelector(selector)
+	scope := b.frameScope(ctx)
+
+	var found bool
+	err := chromedp.Run(ctx,
+		chromedp.WaitReady(sel, scope.query...),
+		chromedp.Evaluate(fmt.Sprintf(`(%s)(document.querySelector(%q))`, selectAllScript, sel), &found, scope.eval...),
+	)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return fmt.Errorf("%w: %s", ErrElementNotFound, selector)
+	}
+	return nil
 }
 
 // ScrollIntoView scrolls element into view.
 func (b *ChromeDPBackend) ScrollIntoView(selector string) error {
 	ctx := b.Context()
 	sel := b.resolveSelector(selector)
-	return chromedp.Run(ctx, chromedp.ScrollIntoView(sel))
+	return chromedp.Run(ctx, chromedp.ScrollIntoView(sel, b.frameScope(ctx).query...))
 }
 
 // Scroll scrolls the page.
@@ -871,14 +2752,20 @@ func (b *ChromeDPBackend) Scroll(direction string, amount int) error {
 func (b *ChromeDPBackend) DoubleClick(selector string) error {
 	ctx := b.Context()
 	sel := b.resolveSelector(selector)
-	return chromedp.Run(ctx, chromedp.DoubleClick(sel))
+	return chromedp.Run(ctx, chromedp.DoubleClick(sel, b.frameScope(ctx).query...))
 }
 
-// Content gets page HTML content.
+// Content gets the HTML of the page, or of the selected frame.
 func (b *ChromeDPBackend) Content() (string, error) {
 	ctx := b.Context()
+	scope := b.frameScope(ctx)
 	var html string
 	err := chromedp.Run(ctx, chromedp.ActionFunc(func(ctx context.Context) error {
+		if scope.owner != nil {
+			var err error
+			html, err = dom.GetOuterHTML().WithNodeID(scope.owner.ContentDocument.NodeID).Do(ctx)
+			return err
+		}
 		node, err := dom.GetDocument().Do(ctx)
 		if err != nil {
 			return err
@@ -889,10 +2776,14 @@ func (b *ChromeDPBackend) Content() (string, error) {
 	return html, err
 }
 
-// SetContent sets page HTML content.
+// SetContent sets the HTML of the page, or of the selected frame.
 func (b *ChromeDPBackend) SetContent(html string) error {
 	ctx := b.Context()
+	scope := b.frameScope(ctx)
 	return chromedp.Run(ctx, chromedp.ActionFunc(func(ctx context.Context) error {
+		if scope.owner != nil {
+			return page.SetDocumentContent(scope.owner.FrameID, html).Do(ctx)
+		}
 		frameTree, err := page.GetFrameTree().Do(ctx)
 		if err != nil {
 			return err
@@ -906,7 +2797,7 @@ func (b *ChromeDPBackend) GetInputValue(selector string) (string, error) {
 	ctx := b.Context()
 	sel := b.resolveSelector(selector)
 	var value string
-	err := chromedp.Run(ctx, chromedp.Value(sel, &value))
+	err := chromedp.Run(ctx, chromedp.Value(sel, &value, b.frameScope(ctx).query...))
 	return value, err
 }
 
@@ -914,7 +2805,7 @@ func (b *ChromeDPBackend) GetInputValue(selector string) (string, error) {
 func (b *ChromeDPBackend) SetValue(selector, value string) error {
 	ctx := b.Context()
 	sel := b.resolveSelector(selector)
-	return chromedp.Run(ctx, chromedp.SetValue(sel, value))
+	return chromedp.Run(ctx, chromedp.SetValue(sel, value, b.frameScope(ctx).query...))
 }
 
 // IsEnabled checks if element is enabled.
@@ -925,11 +2816,79 @@ func (b *ChromeDPBackend) IsEnabled(selector string) (bool, error) {
 	var disabled bool
 	err := chromedp.Run(ctx, chromedp.Evaluate(fmt.Sprintf(`
 		document.querySelector(%q).disabled === true
-	`, sel), &disabled))
+	`, sel), &disabled, b.frameScope(ctx).eval...))
 
 	return !disabled, err
 }
 
+// Describe returns a summary of an element's role, name, states and contents.
+func (b *ChromeDPBackend) Describe(selector string, maxText int) (*ElementDescription, error) {
+	ctx := b.Context()
+	sel := b.resolveSelector(selector)
+
+	var desc *ElementDescription
+	err := chromedp.Run(ctx, chromedp.Evaluate(fmt.Sprintf(`(%s)(document.querySelector(%q), %d)`,
+		describeElementScript, sel, maxText), &desc, b.frameScope(ctx).eval...))
+	if err != nil {
+		return nil, err
+	}
+	if desc == nil {
+		return nil, fmt.Errorf("%w: %s", ErrElementNotFound, selector)
+	}
+	return desc, nil
+}
+
+// ReadableText returns an element's content as Markdown or plain text.
+func (b *ChromeDPBackend) ReadableText(selector string, plain bool) (string, error) {
+	ctx := b.Context()
+	sel := b.resolveSelector(selector)
+
+	var text *string
+	err := chromedp.Run(ctx, chromedp.Evaluate(fmt.Sprintf(`(%s)(document.querySelector(%q), %t)`,
+		markdownScript, sel, plain), &text, b.frameScope(ctx).eval...))
+	if err != nil {
+		return "", err
+	}
+	if text == nil {
+		return "", fmt.Errorf("%w: %s", ErrElementNotFound, selector)
+	}
+	return *text, nil
+}
+
+// Links returns the links inside an element.
+func (b *ChromeDPBackend) Links(selector string) ([]Link, error) {
+	ctx := b.Context()
+	sel := b.resolveSelector(selector)
+
+	var links *[]Link
+	err := chromedp.Run(ctx, chromedp.Evaluate(fmt.Sprintf(`(%s)(document.querySelector(%q))`,
+		linksScript, sel), &links, b.frameScope(ctx).eval...))
+	if err != nil {
+		return nil, err
+	}
+	if links == nil {
+		return nil, fmt.Errorf("%w: %s", ErrElementNotFound, selector)
+	}
+	return *links, nil
+}
+
+// Highlight outlines an element with an overlay in the page.
+func (b *ChromeDPBackend) Highlight(selector string, duration int) error {
+	ctx := b.Context()
+	sel := b.resolveSelector(selector)
+
+	var found bool
+	err := chromedp.Run(ctx, chromedp.Evaluate(fmt.Sprintf(`(%s)(document.querySelector(%q), %d)`,
+		highlightElementScript, sel, duration), &found, b.frameScope(ctx).eval...))
+	if err != nil {
+		return err
+	}
+	if !found {
+		return fmt.Errorf("%w: %s", ErrElementNotFound, selector)
+	}
+	return nil
+}
+
 // IsChecked checks if checkbox is checked.
 func (b *ChromeDPBackend) IsChecked(selector string) (bool, error) {
 	ctx := b.Context()
@@ -938,7 +2897,7 @@ func (b *ChromeDPBackend) IsChecked(selector string) (bool, error) {
 	var checked bool
 	err := chromedp.Run(ctx, chromedp.Evaluate(fmt.Sprintf(`
 		document.querySelector(%q).checked === true
-	`, sel), &checked))
+	`, sel), &checked, b.frameScope(ctx).eval...))
 
 	return checked, err
 }
@@ -948,14 +2907,21 @@ func (b *ChromeDPBackend) GetBoundingBox(selector string) (*BoundingBox, error)
 	ctx := b.Context()
 	sel := b.resolveSelector(selector)
 
+	scope := b.frameScope(ctx)
+
 	var box *dom.BoxModel
 	err := chromedp.Run(ctx, chromedp.ActionFunc(func(ctx context.Context) error {
-		nodes, err := dom.GetDocument().Do(ctx)
-		if err != nil {
-			return err
+		var root *cdp.Node
+		if scope.owner != nil {
+			root = scope.owner.ContentDocument
+		} else {
+			var err error
+			if root, err = dom.GetDocument().Do(ctx); err != nil {
+				return err
+			}
 		}
 
-		nodeID, err := dom.QuerySelector(nodes.NodeID, sel).Do(ctx)
+		nodeID, err := dom.QuerySelector(root.NodeID, sel).Do(ctx)
 		if err != nil {
 			return err
 		}
@@ -1012,37 +2978,183 @@ func (b *ChromeDPBackend) GetCookies() ([]Cookie, error) {
 	return cookies, nil
 }
 
-// Shortcuts for semantic locators
+// SetCookies adds cookies to the browser. Cookies without an expiry are
+// session cookies.
+func (b *ChromeDPBackend) SetCookies(cookies []Cookie) error {
+	ctx := b.Context()
 
-// GetByRole finds element by ARIA role.
-func (b *ChromeDPBackend) GetByRole(role, name string) string {
-	if name != "" {
-		return fmt.Sprintf(`[role="%s"][aria-label="%s"], [role="%s"]:has-text("%s")`, role, name, role, name)
+	params := make([]*network.CookieParam, len(cookies))
+	for i, c := range cookies {
+		param := &network.CookieParam{
+			Name:     c.Name,
+			Value:    c.Value,
+			URL:      c.URL,
+			Domain:   c.Domain,
+			Path:     c.Path,
+			Secure:   c.Secure,
+			HTTPOnly: c.HTTPOnly,
+			SameSite: network.CookieSameSite(c.SameSite),
+		}
+		if c.Expires > 0 {
+			expires := cdp.TimeSinceEpoch(time.Unix(c.Expires, 0))
+			param.Expires = &expires
+		}
+		params[i] = param
 	}
-	return fmt.Sprintf(`[role="%s"]`, role)
+
+	return chromedp.Run(ctx, chromedp.ActionFunc(func(ctx context.Context) error {
+		return storage.SetCookies(params).Do(ctx)
+	}))
 }
 
-// GetByText finds element by text.
-func (b *ChromeDPBackend) GetByText(text string, exact bool) string {
-	if exact {
-		return fmt.Sprintf(`text="%s"`, text)
+// ClearCookies deletes all cookies.
+func (b *ChromeDPBackend) ClearCookies() error {
+	return chromedp.Run(b.Context(), storage.ClearCookies())
+}
+
+// ClearCache clears the HTTP cache.
+func (b *ChromeDPBackend) ClearCache() error {
+	return chromedp.Run(b.Context(), network.ClearBrowserCache())
+}
+
+// ClearStorage clears an origin's stored data other than cookies.
+func (b *ChromeDPBackend) ClearStorage(origin string) error {
+	return chromedp.Run(b.Context(), storage.ClearDataForOrigin(origin, clearStorageTypes))
+}
+
+// PerformanceMetrics returns the page's run-time metrics.
+func (b *ChromeDPBackend) PerformanceMetrics() (map[string]float64, error) {
+	var metrics []*performance.Metric
+	err := chromedp.Run(b.Context(), chromedp.ActionFunc(func(ctx context.Context) error {
+		if err := performance.Enable().Do(ctx); err != nil {
+			return err
+		}
+		var err error
+		metrics, err = performance.GetMetrics().Do(ctx)
+		return err
+	}))
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string]float64, len(metrics))
+	for _, m := range metrics {
+		result[m.Name] = m.Value
 	}
-	return fmt.Sprintf(`text=%s`, text)
+	return result, nil
 }
 
-// GetByLabel finds element by label.
-func (b *ChromeDPBackend) GetByLabel(label string) string {
-	return fmt.Sprintf(`[aria-label="%s"], label:has-text("%s") + input, label:has-text("%s") input`, label, label, label)
+// BrowserProcesses lists the browser's processes.
+func (b *ChromeDPBackend) BrowserProcesses() ([]BrowserProcess, error) {
+	var infos []*systeminfo.ProcessInfo
+	err := chromedp.Run(b.Context(), chromedp.ActionFunc(func(ctx context.Context) error {
+		var err error
+		infos, err = systeminfo.GetProcessInfo().Do(cdp.WithExecutor(ctx, chromedp.FromContext(ctx).Browser))
+		return err
+	}))
+	if err != nil {
+		return nil, err
+	}
+	processes := make([]BrowserProcess, len(infos))
+	for i, info := range infos {
+		processes[i] = BrowserProcess{PID: int(info.ID), Type: info.Type}
+	}
+	return processes, nil
 }
 
-// GetByPlaceholder finds element by placeholder.
-func (b *ChromeDPBackend) GetByPlaceholder(placeholder string) string {
-	return fmt.Sprintf(`[placeholder="%s"]`, placeholder)
+// HeapSnapshot writes a heap snapshot of the active tab, which the page
+// streams as chunk events while the command runs.
+func (b *ChromeDPBackend) HeapSnapshot(w io.Writer) error {
+	ctx, cancel := context.WithCancel(b.Context())
+	defer cancel()
+
+	var mu sync.Mutex
+	var writeErr error
+	chromedp.ListenTarget(ctx, func(ev interface{}) {
+		if e, ok := ev.(*heapprofiler.EventAddHeapSnapshotChunk); ok {
+			mu.Lock()
+			defer mu.Unlock()
+			if writeErr == nil {
+				_, writeErr = io.WriteString(w, e.Chunk)
+			}
+		}
+	})
+	if err := chromedp.Run(ctx, heapprofiler.TakeHeapSnapshot().WithReportProgress(false)); err != nil {
+		return err
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	return writeErr
 }
 
-// GetByTestId finds element by data-testid.
-func (b *ChromeDPBackend) GetByTestId(testId string) string {
-	return fmt.Sprintf(`[data-testid="%s"]`, testId)
+// Locate finds the first element a locator matches in the current frame
+// and marks it, returning a selector for the mark. Roles and accessible
+// names come from the browser's accessibility tree, so they are what
+// assistive technology sees; other locators are resolved in the page.
+func (b *ChromeDPBackend) Locate(loc Locator) (string, error) {
+	ctx := b.Context()
+	scope := b.frameScope(ctx)
+	mark, selector := nextLocatorMark()
+
+	if loc.By != "role" {
+		data, err := json.Marshal(loc)
+		if err != nil {
+			return "", err
+		}
+		var found bool
+		err = chromedp.Run(ctx, chromedp.Evaluate(fmt.Sprintf(`(%s)(%s, %q, %q)`,
+			locateScript, data, locatorAttr, mark), &found, scope.eval...))
+		if err != nil {
+			return "", err
+		}
+		if !found {
+			return "", fmt.Errorf("%w: %s", ErrElementNotFound, loc)
+		}
+		return selector, nil
+	}
+
+	err := chromedp.Run(ctx, chromedp.ActionFunc(func(ctx context.Context) error {
+		var root *cdp.Node
+		if scope.owner != nil {
+			root = scope.owner.ContentDocument
+		} else {
+			var err error
+			if root, err = dom.GetDocument().Do(ctx); err != nil {
+				return err
+			}
+		}
+
+		nodes, err := accessibility.QueryAXTree().WithNodeID(root.NodeID).WithRole(loc.Role).Do(ctx)
+		if err != nil {
+			return err
+		}
+		var found cdp.BackendNodeID
+		for _, n := range nodes {
+			if n.Ignored || n.BackendDOMNodeID == 0 {
+				continue
+			}
+			var name string
+			if n.Name != nil {
+				_ = json.Unmarshal(n.Name.Value, &name)
+			}
+			if loc.matches(name) {
+				found = n.BackendDOMNodeID
+				break
+			}
+		}
+		if found == 0 {
+			return fmt.Errorf("%w: %s", ErrElementNotFound, loc)
+		}
+
+		ids, err := dom.PushNodesByBackendIDsToFrontend([]cdp.BackendNodeID{found}).Do(ctx)
+		if err != nil {
+			return err
+		}
+		return dom.SetAttributeValue(ids[0], locatorAttr, mark).Do(ctx)
+	}))
+	if err != nil {
+		return "", err
+	}
+	return selector, nil
 }
 
 // Private helper: convert string to int with default
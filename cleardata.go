@@ -0,0 +1,75 @@
+package agentbrowser
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ClearDataOptions selects the browsing data ClearData removes.
+type ClearDataOptions struct {
+	Cache   bool // HTTP cache
+	Cookies bool // all cookies
+	Storage bool // localStorage, sessionStorage, IndexedDB, Cache Storage and service workers of the open origins
+}
+
+// ClearDataResult reports what ClearData removed.
+type ClearDataResult struct {
+	Cleared []string `json:"cleared"`           // cache, cookies and/or storage
+	Origins []string `json:"origins,omitempty"` // origins whose storage was cleared
+}
+
+// clearStorageTypes are the Storage.clearDataForOrigin types cleared for
+// an origin. Cookies are left to ClearCookies, which clears them all.
+var clearStorageTypes = strings.Join([]string{
+	"local_storage", "indexeddb", "websql", "file_systems", "cache_storage", "service_workers",
+}, ",")
+
+// clearSessionStorageScript clears sessionStorage, which is per tab and not
+// part of an origin's stored data. Opaque origins throw on access.
+const clearSessionStorageScript = `(() => { try { sessionStorage.clear(); } catch (e) {} })()`
+
+// ClearData removes browsing data so a session starts clean without a new
+// profile. Storage is cleared for the origins open in the tabs; the active
+// tab's sessionStorage is cleared too.
+func (m *BrowserManager) ClearData(opts ClearDataOptions) (*ClearDataResult, error) {
+	if !opts.Cache && !opts.Cookies && !opts.Storage {
+		return nil, fmt.Errorf("nothing to clear: choose cache, cookies or storage")
+	}
+
+	result := &ClearDataResult{Cleared: []string{}}
+	if opts.Cache {
+		if err := m.backend.ClearCache(); err != nil {
+			return nil, fmt.Errorf("clear cache: %w", err)
+		}
+		result.Cleared = append(result.Cleared, "cache")
+	}
+	if opts.Cookies {
+		if err := m.backend.ClearCookies(); err != nil {
+			return nil, fmt.Errorf("clear cookies: %w", err)
+		}
+		result.Cleared = append(result.Cleared, "cookies")
+	}
+	if opts.Storage {
+		tabs, err := m.backend.ListTabs()
+		if err != nil {
+			return nil, err
+		}
+		seen := make(map[string]bool)
+		for _, tab := range tabs {
+			origin := originOf(tab.URL)
+			if origin == "" || seen[origin] {
+				continue
+			}
+			seen[origin] = true
+			if err := m.backend.ClearStorage(origin); err != nil {
+				return nil, fmt.Errorf("clear storage for %s: %w", origin, err)
+			}
+			result.Origins = append(result.Origins, origin)
+		}
+		if _, err := m.backend.Evaluate(clearSessionStorageScript); err != nil {
+			return nil, fmt.Errorf("clear sessionStorage: %w", err)
+		}
+		result.Cleared = append(result.Cleared, "storage")
+	}
+	return result, nil
+}
@@ -0,0 +1,72 @@
+package agentbrowser_test
+
+import (
+	"reflect"
+	"testing"
+
+	agentbrowser "github.com/cpunion/agent-browser-go"
+)
+
+// TestClearData tests which data ClearData clears
+func TestClearData(t *testing.T) {
+	tabs := []agentbrowser.TabInfo{
+		{Index: 0, URL: "https://app.example.com/inbox"},
+		{Index: 1, URL: "about:blank"},
+		{Index: 2, URL: "https://app.example.com/settings"},
+		{Index: 3, URL: "http://localhost:3000/"},
+	}
+	tests := []struct {
+		name        string
+		opts        agentbrowser.ClearDataOptions
+		wantCleared []string
+		wantResult  agentbrowser.ClearDataResult
+		wantErr     bool
+	}{
+		{
+			name:        "cache",
+			opts:        agentbrowser.ClearDataOptions{Cache: true},
+			wantCleared: []string{"cache"},
+			wantResult:  agentbrowser.ClearDataResult{Cleared: []string{"cache"}},
+		},
+		{
+			name:        "all",
+			opts:        agentbrowser.ClearDataOptions{Cache: true, Cookies: true, Storage: true},
+			wantCleared: []string{"cache", "cookies", "https://app.example.com", "http://localhost:3000", "sessionStorage"},
+			wantResult: agentbrowser.ClearDataResult{
+				Cleared: []string{"cache", "cookies", "storage"},
+				Origins: []string{"https://app.example.com", "http://localhost:3000"},
+			},
+		},
+		{name: "nothing", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var cleared []string
+			clear := func(what string) error {
+				cleared = append(cleared, what)
+				return nil
+			}
+			m := agentbrowser.NewBrowserManagerForTest(&fakeBackend{
+				clearCache:   func() error { return clear("cache") },
+				clearCookies: func() error { return clear("cookies") },
+				clearStorage: clear,
+				listTabs:     func() ([]agentbrowser.TabInfo, error) { return tabs, nil },
+				evaluate:     func(string) (interface{}, error) { return nil, clear("sessionStorage") },
+			})
+			result, err := m.ClearData(tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ClearData() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !reflect.DeepEqual(cleared, tt.wantCleared) {
+				t.Errorf("cleared %q, want %q", cleared, tt.wantCleared)
+			}
+			if !reflect.DeepEqual(*result, tt.wantResult) {
+				t.Errorf("ClearData() = %+v, want %+v", *result, tt.wantResult)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package agentbrowser
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+)
+
+// ClipboardData is the response for clipboard read and paste.
+type ClipboardData struct {
+	Text string `json:"text"`
+}
+
+// pasteScript is a JavaScript function (text) that pastes text into the
+// focused element as a user's paste does: a paste event carrying the text
+// is fired, and unless the page cancels it the text is inserted at the
+// caret, replacing the selection.
+const pasteScript = `(text) => {
+	const el = document.activeElement;
+	if (!el || el === document.body) return false;
+	const data = new DataTransfer();
+	data.setData('text/plain', text);
+	const event = new ClipboardEvent('paste', {clipboardData: data, bubbles: true, cancelable: true, composed: true});
+	if (el.dispatchEvent(event)) document.execCommand('insertText', false, text);
+	return true;
+}`
+
+// allowClipboard grants the active page's origin the clipboard
+// permissions and focuses the page, as the async Clipboard API needs both.
+func (m *BrowserManager) allowClipboard() error {
+	pageURL, err := m.backend.URL()
+	if err != nil {
+		return err
+	}
+	// Pages without an origin, such as about:blank, need a grant for
+	// every origin
+	var origin string
+	if u, err := url.Parse(pageURL); err == nil && (u.Scheme == "http" || u.Scheme == "https") {
+		origin = u.Scheme + "://" + u.Host
+	}
+	if err := m.backend.GrantPermissions([]string{"clipboard-read", "clipboard-write"}, origin); err != nil {
+		return err
+	}
+	return m.backend.BringToFront()
+}
+
+// ClipboardCopy writes text to the clipboard.
+func (m *BrowserManager) ClipboardCopy(text string) error {
+	if err := m.allowClipboard(); err != nil {
+		return err
+	}
+	quoted, err := json.Marshal(text)
+	if err != nil {
+		return err
+	}
+	if _, err := m.backend.Evaluate(fmt.Sprintf("navigator.clipboard.writeText(%s)", quoted)); err != nil {
+		return fmt.Errorf("writing the clipboard: %w", err)
+	}
+	return nil
+}
+
+// ClipboardRead returns the text on the clipboard, e.g. after the page's
+// own copy button was clicked.
+func (m *BrowserManager) ClipboardRead() (string, error) {
+	if err := m.allowClipboard(); err != nil {
+		return "", err
+	}
+	result, err := m.backend.Evaluate("navigator.clipboard.readText()")
+	if err != nil {
+		return "", fmt.Errorf("reading the clipboard: %w", err)
+	}
+	text, _ := result.(string)
+	return text, nil
+}
+
+// ClipboardPaste pastes the clipboard's text into an element and returns
+// it. The page sees a paste event, so editors that handle pasting
+// themselves get the text as they would from a user.
+func (m *BrowserManager) ClipboardPaste(selector string) (string, error) {
+	text, err := m.ClipboardRead()
+	if err != nil {
+		return "", err
+	}
+	if err := m.Focus(selector); err != nil {
+		return "", err
+	}
+	quoted, err := json.Marshal(text)
+	if err != nil {
+		return "", err
+	}
+	result, err := m.backend.Evaluate(fmt.Sprintf("(%s)(%s)", pasteScript, quoted))
+	if err != nil {
+		return "", err
+	}
+	if pasted, _ := result.(bool); !pasted {
+		return "", fmt.Errorf("cannot paste into %s: it did not take focus", selector)
+	}
+	return text, nil
+}
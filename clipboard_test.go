@@ -0,0 +1,66 @@
+package agentbrowser_test
+
+import (
+	"strings"
+	"testing"
+
+	agentbrowser "github.com/cpunion/agent-browser-go"
+)
+
+// TestClipboard tests that the clipboard is used with the page's origin
+// granted the clipboard permissions
+func TestClipboard(t *testing.T) {
+	url := "https://example.com/editor?doc=1"
+	var origin, clipboard, focused string
+	var scripts []string
+	m := agentbrowser.NewBrowserManagerForTest(&fakeBackend{
+		url:          func() (string, error) { return url, nil },
+		bringToFront: func() error { return nil },
+		grantPermissions: func(permissions []string, o string) error {
+			origin = o
+			return nil
+		},
+		focus: func(selector string) error {
+			focused = selector
+			return nil
+		},
+		// The page's clipboard
+		evaluate: func(script string) (interface{}, error) {
+			scripts = append(scripts, script)
+			switch {
+			case strings.HasPrefix(script, "navigator.clipboard.writeText("):
+				clipboard = strings.Trim(strings.TrimPrefix(script, "navigator.clipboard.writeText("), `()"`)
+				return nil, nil
+			case script == "navigator.clipboard.readText()":
+				return clipboard, nil
+			}
+			return true, nil
+		},
+	})
+
+	if err := m.ClipboardCopy("hello"); err != nil {
+		t.Fatalf("ClipboardCopy() error = %v", err)
+	}
+	if origin != "https://example.com" {
+		t.Errorf("granted to %q, want the page's origin", origin)
+	}
+	if text, err := m.ClipboardRead(); err != nil || text != "hello" {
+		t.Errorf("ClipboardRead() = %q, %v; want hello", text, err)
+	}
+
+	text, err := m.ClipboardPaste("#message")
+	if err != nil || text != "hello" {
+		t.Fatalf("ClipboardPaste() = %q, %v; want hello", text, err)
+	}
+	if focused != "#message" {
+		t.Errorf("focused %q, want #message", focused)
+	}
+	if last := scripts[len(scripts)-1]; !strings.Contains(last, "'paste'") || !strings.HasSuffix(last, `("hello")`) {
+		t.Errorf("paste script = %s, want a paste event with the text", last)
+	}
+
+	url = "about:blank"
+	if _, err := m.ClipboardRead(); err != nil || origin != "" {
+		t.Errorf("ClipboardRead() on about:blank granted to %q, %v; want every origin", origin, err)
+	}
+}
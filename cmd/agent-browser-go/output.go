@@ -0,0 +1,203 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	agentbrowser "github.com/cpunion/agent-browser-go"
+	"gopkg.in/yaml.v3"
+)
+
+// outputFormats are the formats --output prints responses in: text (the
+// default) picks what a person wants to read, json and yaml print whole
+// responses, table prints lists of records as columns and raw prints a
+// response's main value with nothing around it.
+var outputFormats = []string{"text", "json", "yaml", "table", "raw"}
+
+// outputFormat is the --output format; empty for text.
+var outputFormat string
+
+// writeYAML writes a whole response as YAML.
+func writeYAML(w io.Writer, resp agentbrowser.Response) {
+	doc := struct {
+		ID      string      `yaml:"id"`
+		Success bool        `yaml:"success"`
+		Data    interface{} `yaml:"data,omitempty"`
+		Error   string      `yaml:"error,omitempty"`
+	}{ID: resp.ID, Success: resp.Success, Error: resp.Error}
+	if len(resp.Data) > 0 {
+		_ = json.Unmarshal(resp.Data, &doc.Data)
+	}
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	_ = enc.Encode(doc)
+	_ = enc.Close()
+}
+
+// tableLeadColumns are the fields a table starts with when its records
+// have them; the other fields follow in name order.
+var tableLeadColumns = []string{"index", "id", "ref", "name", "role", "title", "url"}
+
+// writeTable writes a list of records, such as tabs, cookies or requests,
+// one row each, with a column per field. Other data is written a field per
+// row.
+func writeTable(out io.Writer, data interface{}) {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	defer w.Flush()
+
+	records, ok := tableRecords(data)
+	if !ok {
+		if m, isMap := data.(map[string]interface{}); isMap {
+			for _, key := range slices.Sorted(maps.Keys(m)) {
+				fmt.Fprintf(w, "%s\t%s\n", key, tableCell(m[key]))
+			}
+		} else if data != nil {
+			fmt.Fprintln(w, tableCell(data))
+		}
+		return
+	}
+
+	seen := make(map[string]bool)
+	var rest []string
+	for _, record := range records {
+		for key := range record {
+			if !seen[key] {
+				seen[key] = true
+				if !slices.Contains(tableLeadColumns, key) {
+					rest = append(rest, key)
+				}
+			}
+		}
+	}
+	var columns []string
+	for _, key := range tableLeadColumns {
+		if seen[key] {
+			columns = append(columns, key)
+		}
+	}
+	slices.Sort(rest)
+	columns = append(columns, rest...)
+
+	header := make([]string, len(columns))
+	for i, column := range columns {
+		header[i] = strings.ToUpper(column)
+	}
+	fmt.Fprintln(w, strings.Join(header, "\t"))
+	for _, record := range records {
+		cells := make([]string, len(columns))
+		for i, column := range columns {
+			cells[i] = tableCell(record[column])
+		}
+		fmt.Fprintln(w, strings.Join(cells, "\t"))
+	}
+}
+
+// tableRecords returns the records of data: data itself when it is a list
+// of objects, or the one field of data that is.
+func tableRecords(data interface{}) ([]map[string]interface{}, bool) {
+	asRecords := func(v interface{}) ([]map[string]interface{}, bool) {
+		list, ok := v.([]interface{})
+		if !ok {
+			return nil, false
+		}
+		records := make([]map[string]interface{}, 0, len(list))
+		for _, item := range list {
+			record, ok := item.(map[string]interface{})
+			if !ok {
+				return nil, false
+			}
+			records = append(records, record)
+		}
+		return records, true
+	}
+	if records, ok := asRecords(data); ok {
+		return records, true
+	}
+	m, ok := data.(map[string]interface{})
+	if !ok {
+		return nil, false
+	}
+	var found []map[string]interface{}
+	n := 0
+	for _, v := range m {
+		if records, ok := asRecords(v); ok {
+			found = records
+			n++
+		}
+	}
+	return found, n == 1
+}
+
+// tableCell formats a value for a table cell on one line.
+func tableCell(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return strings.Join(strings.Fields(v), " ")
+	case float64, bool:
+		return fmt.Sprint(v)
+	default:
+		data, _ := json.Marshal(v)
+		return string(data)
+	}
+}
+
+// rawFields are the fields holding the main value of a command's response,
+// by action, such as the text of gettext.
+var rawFields = map[string]string{
+	"snapshot":        "snapshot",
+	"evaluate":        "result",
+	"content":         "html",
+	"innerhtml":       "html",
+	"gettext":         "text",
+	"innertext":       "text",
+	"text":            "text",
+	"clipboard":       "text",
+	"getattribute":    "value",
+	"inputvalue":      "value",
+	"isvisible":       "visible",
+	"isenabled":       "enabled",
+	"ischecked":       "checked",
+	"count":           "count",
+	"navigate":        "url",
+	"url":             "url",
+	"title":           "title",
+	"fetch":           "body",
+	"screenshot":      "path",
+	"pdf":             "path",
+	"trace_stop":      "path",
+	"record_stop":     "path",
+	"screencast_stop": "dir",
+	"cookies_export":  "path",
+	"state_save":      "path",
+}
+
+// writeRaw writes the main value of a command's response as it is: the
+// field rawFields names for the action, else the one field of the data, else
+// the data. Strings are written without quotes, anything else as compact
+// JSON.
+func writeRaw(w io.Writer, action string, data interface{}) {
+	if m, ok := data.(map[string]interface{}); ok {
+		if v, ok := m[rawFields[action]]; ok {
+			data = v
+		} else if len(m) == 1 {
+			for _, v := range m {
+				data = v
+			}
+		}
+	}
+	switch v := data.(type) {
+	case nil:
+	case string:
+		fmt.Fprintln(w, v)
+	default:
+		out, _ := json.Marshal(v)
+		fmt.Fprintln(w, string(out))
+	}
+}
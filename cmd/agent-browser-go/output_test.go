@@ -0,0 +1,143 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	agentbrowser "github.com/cpunion/agent-browser-go"
+)
+
+// decode returns a response's data as the formatters get it.
+func decode(t *testing.T, data string) interface{} {
+	t.Helper()
+	var v interface{}
+	if err := json.Unmarshal([]byte(data), &v); err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+// TestWriteYAML tests printing whole responses as YAML
+func TestWriteYAML(t *testing.T) {
+	tests := []struct {
+		name string
+		resp agentbrowser.Response
+		want string
+	}{
+		{
+			name: "nested",
+			resp: agentbrowser.Response{ID: "1", Success: true, Data: json.RawMessage(`{"url":"https://example.com","viewport":{"width":800,"height":600}}`)},
+			want: "id: \"1\"\nsuccess: true\ndata:\n  url: https://example.com\n  viewport:\n    height: 600\n    width: 800\n",
+		},
+		{
+			name: "array",
+			resp: agentbrowser.Response{ID: "2", Success: true, Data: json.RawMessage(`{"tabs":[{"index":0},{"index":1}]}`)},
+			want: "id: \"2\"\nsuccess: true\ndata:\n  tabs:\n    - index: 0\n    - index: 1\n",
+		},
+		{
+			name: "scalar",
+			resp: agentbrowser.Response{ID: "3", Success: true, Data: json.RawMessage(`42`)},
+			want: "id: \"3\"\nsuccess: true\ndata: 42\n",
+		},
+		{
+			name: "error",
+			resp: agentbrowser.ErrorResponse("4", "element not found"),
+			want: "id: \"4\"\nsuccess: false\nerror: element not found\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out strings.Builder
+			writeYAML(&out, tt.resp)
+			if out.String() != tt.want {
+				t.Errorf("writeYAML() =\n%s\nwant\n%s", out.String(), tt.want)
+			}
+		})
+	}
+}
+
+// TestWriteTable tests printing lists of records as columns and other data
+// a field per row
+func TestWriteTable(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{
+			name: "records",
+			data: `[{"url":"https://a.test","index":0,"active":true},{"url":"https://b.test","index":1,"title":"B"}]`,
+			want: "INDEX  TITLE  URL             ACTIVE\n" +
+				"0             https://a.test  true\n" +
+				"1      B      https://b.test  \n",
+		},
+		{
+			name: "records in a field",
+			data: `{"cookies":[{"name":"sid","value":"a b\nc"}],"count":1}`,
+			want: "NAME  VALUE\nsid   a b c\n",
+		},
+		{
+			name: "nested values",
+			data: `[{"name":"form","fields":[{"ref":"e1"}],"rect":{"x":1}}]`,
+			want: "NAME  FIELDS          RECT\nform  [{\"ref\":\"e1\"}]  {\"x\":1}\n",
+		},
+		{
+			name: "object",
+			data: `{"url":"https://a.test","tabs":3,"secure":false}`,
+			want: "secure  false\ntabs    3\nurl     https://a.test\n",
+		},
+		{
+			name: "scalar",
+			data: `"done"`,
+			want: "done\n",
+		},
+		{
+			name: "null",
+			data: `null`,
+			want: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out strings.Builder
+			writeTable(&out, decode(t, tt.data))
+			if out.String() != tt.want {
+				t.Errorf("writeTable() =\n%q\nwant\n%q", out.String(), tt.want)
+			}
+		})
+	}
+}
+
+// TestWriteRaw tests printing the main value of each command's response
+func TestWriteRaw(t *testing.T) {
+	tests := []struct {
+		action string
+		data   string
+		want   string
+	}{
+		{"gettext", `{"text":"Hello"}`, "Hello\n"},
+		{"navigate", `{"url":"https://a.test/","title":"A"}`, "https://a.test/\n"},
+		{"title", `{"title":"A"}`, "A\n"},
+		{"snapshot", `{"snapshot":"- button \"OK\" [ref=e1]","refs":{"e1":{"role":"button"}}}`, "- button \"OK\" [ref=e1]\n"},
+		{"evaluate", `{"result":{"a":[1,2]}}`, "{\"a\":[1,2]}\n"},
+		{"fetch", `{"status":200,"url":"https://a.test/api","body":{"ok":true}}`, "{\"ok\":true}\n"},
+		{"ischecked", `{"checked":false}`, "false\n"},
+		{"screenshot", `{"base64":"iVBORw0K"}`, "iVBORw0K\n"},
+		{"screencast_stop", `{"screencasting":false,"dir":"/tmp/frames","frames":12}`, "/tmp/frames\n"},
+		{"tab_new", `{"index":2}`, "2\n"},
+		{"stats", `{"url":"https://a.test","requests":3}`, "{\"requests\":3,\"url\":\"https://a.test\"}\n"},
+		{"requests", `[{"url":"https://a.test"}]`, "[{\"url\":\"https://a.test\"}]\n"},
+		{"count", `3`, "3\n"},
+		{"click", `null`, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.action, func(t *testing.T) {
+			var out strings.Builder
+			writeRaw(&out, tt.action, decode(t, tt.data))
+			if out.String() != tt.want {
+				t.Errorf("writeRaw(%s) = %q, want %q", tt.action, out.String(), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,262 @@
+package agentbrowser_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+
+	agentbrowser "github.com/cpunion/agent-browser-go"
+)
+
+// chdir changes the working directory for the rest of the test.
+func chdir(t *testing.T, dir string) {
+	t.Helper()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.Chdir(wd) })
+}
+
+// TestLoadConfigYAML tests reading the user config with the project config on top
+func TestLoadConfigYAML(t *testing.T) {
+	home := t.TempDir()
+	userPath := filepath.Join(home, "config.yaml")
+	t.Setenv("AGENT_BROWSER_CONFIG", userPath)
+	user := `session: work
+sessions:
+  "*":
+    backend: chromedp
+    timeout: 5000
+    headers:
+      X-Team: qa
+  work:
+    headed: true
+    viewport: 1920x1080
+    proxy: http://proxy:3128
+`
+	if err := os.WriteFile(userPath, []byte(user), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	project := t.TempDir()
+	sub := filepath.Join(project, "web", "src")
+	if err := os.MkdirAll(sub, 0755); err != nil {
+		t.Fatal(err)
+	}
+	projectYAML := `sessions:
+  work:
+    backend: playwright
+    headed: false
+    userDataDir: .profile
+    headers:
+      X-Project: shop
+`
+	if err := os.WriteFile(filepath.Join(project, ".agent-browser.yaml"), []byte(projectYAML), 0600); err != nil {
+		t.Fatal(err)
+	}
+	chdir(t, sub)
+
+	cfg, err := agentbrowser.LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	if cfg.DefaultSession != "work" {
+		t.Errorf("DefaultSession = %q, want work", cfg.DefaultSession)
+	}
+	work := cfg.Session("work")
+	if work.Backend != "playwright" || work.Headed == nil || *work.Headed {
+		t.Errorf("work backend = %q, headed = %v, want the project's playwright and headless", work.Backend, work.Headed)
+	}
+	if work.Viewport != "1920x1080" || work.Proxy != "http://proxy:3128" || work.Timeout != 5000 {
+		t.Errorf("work = %+v, want the user config's viewport, proxy and timeout", work)
+	}
+	realProject, _ := filepath.EvalSymlinks(project)
+	if got, _ := filepath.EvalSymlinks(filepath.Dir(work.UserDataDir)); got != realProject {
+		t.Errorf("work user data dir = %s, want .profile in %s", work.UserDataDir, project)
+	}
+	if want := map[string]string{"x-team": "qa", "x-project": "shop"}; !reflect.DeepEqual(work.Headers, want) {
+		t.Errorf("work headers = %v, want %v", work.Headers, want)
+	}
+	if other := cfg.Session("other"); other.Backend != "chromedp" || other.Headed != nil {
+		t.Errorf("other = %+v, want only the shared defaults", other)
+	}
+
+	for _, bad := range []string{
+		"sessions:\n  work:\n    backend: firefox\n",
+		"sessions:\n  work:\n    viewport: large\n",
+		"sessions:\n  work:\n    headed: maybe\n",
+		"sessions: [",
+	} {
+		if err := os.WriteFile(userPath, []byte(bad), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := agentbrowser.LoadConfig(); err == nil {
+			t.Errorf("LoadConfig(%q) expected error", bad)
+		}
+	}
+}
+
+// TestSaveSessionSettings tests that a daemon's launch settings are recorded
+// in the session's entry of the user config, keeping the rest of the file
+func TestSaveSessionSettings(t *testing.T) {
+	userPath := filepath.Join(t.TempDir(), "config.yaml")
+	t.Setenv("AGENT_BROWSER_CONFIG", userPath)
+	user := `# team defaults
+session: work
+sessions:
+  work:
+    viewport: 800x600 # small screens
+    timeout: 5000
+`
+	if err := os.WriteFile(userPath, []byte(user), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := agentbrowser.SaveSessionBackend("work", "playwright"); err != nil {
+		t.Fatalf("SaveSessionBackend() error = %v", err)
+	}
+	if err := agentbrowser.SaveSessionHeaded("work", true); err != nil {
+		t.Fatalf("SaveSessionHeaded() error = %v", err)
+	}
+	if err := agentbrowser.SaveSessionViewport("work", &agentbrowser.Viewport{Width: 1920, Height: 1080}); err != nil {
+		t.Fatalf("SaveSessionViewport() error = %v", err)
+	}
+	if err := agentbrowser.SaveSessionHeaded("new", false); err != nil {
+		t.Fatalf("SaveSessionHeaded() error = %v", err)
+	}
+
+	cfg, err := agentbrowser.LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	work := cfg.Session("work")
+	if work.Backend != "playwright" || work.Headed == nil || !*work.Headed || work.Viewport != "1920x1080" || work.Timeout != 5000 {
+		t.Errorf("work = %+v, want the saved settings and the timeout", work)
+	}
+	if headed := cfg.Session("new").Headed; headed == nil || *headed {
+		t.Errorf("new headed = %v, want false", headed)
+	}
+	data, err := os.ReadFile(userPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "# team defaults") || !strings.Contains(string(data), "# small screens") {
+		t.Errorf("config after saving lost its comments:\n%s", data)
+	}
+
+	if err := agentbrowser.SaveSessionViewport("work", nil); err != nil {
+		t.Fatal(err)
+	}
+	if got := agentbrowser.GetSessionViewport("work"); got != nil {
+		t.Errorf("GetSessionViewport() after saving the default = %+v, want nil", got)
+	}
+}
+
+// TestMigrateSessionFiles tests that the backend, headed mode and viewport
+// older versions saved in the temp directory move into the config file
+func TestMigrateSessionFiles(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+	userPath := filepath.Join(t.TempDir(), "config.yaml")
+	t.Setenv("AGENT_BROWSER_CONFIG", userPath)
+	dir := filepath.Join(os.TempDir(), "agent-browser-go")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	legacy := map[string]string{"old.backend": "playwright", "old.headed": "true", "old.viewport": "1280x720"}
+	for name, value := range legacy {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(value), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if got := agentbrowser.GetSessionBackend("old"); got != "playwright" {
+		t.Errorf("GetSessionBackend() = %q, want the saved playwright", got)
+	}
+	for name := range legacy {
+		if _, err := os.Stat(filepath.Join(dir, name)); !os.IsNotExist(err) {
+			t.Errorf("%s still exists after the migration", name)
+		}
+	}
+	cfg, err := agentbrowser.LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	old := cfg.Session("old")
+	if old.Backend != "playwright" || old.Headed == nil || !*old.Headed || old.Viewport != "1280x720" {
+		t.Errorf("old = %+v, want the settings of the migrated files", old)
+	}
+	if !agentbrowser.GetSessionHeaded("old") {
+		t.Error("GetSessionHeaded() after the migration = false, want true")
+	}
+}
+
+// TestSessionBackendPrecedence tests that a running daemon's backend wins
+// over the config, which applies once the daemon has stopped
+func TestSessionBackendPrecedence(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the running daemon is faked with a Unix socket file")
+	}
+	t.Setenv("TMPDIR", t.TempDir())
+	t.Setenv("AGENT_BROWSER_CONFIG", filepath.Join(t.TempDir(), "config.yaml"))
+	project := t.TempDir()
+	if err := os.WriteFile(filepath.Join(project, ".agent-browser.yaml"), []byte("sessions:\n  work:\n    backend: playwright\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	chdir(t, project)
+
+	if got := agentbrowser.GetSessionBackend("other"); got != "chromedp" {
+		t.Errorf("GetSessionBackend() without config = %q, want chromedp", got)
+	}
+	if err := agentbrowser.SaveSessionBackend("work", "chromedp"); err != nil {
+		t.Fatal(err)
+	}
+	if got := agentbrowser.GetSessionBackend("work"); got != "playwright" {
+		t.Errorf("GetSessionBackend() without a daemon = %q, want the project config's playwright", got)
+	}
+
+	if err := os.WriteFile(agentbrowser.GetPIDFile("work"), []byte(strconv.Itoa(os.Getpid())), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(agentbrowser.GetSocketPath("work"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got := agentbrowser.GetSessionBackend("work"); got != "chromedp" {
+		t.Errorf("GetSessionBackend() with a running daemon = %q, want the chromedp it started with", got)
+	}
+}
+
+// TestConfigDir tests that the config directory older versions used moves
+// to ~/.config/agent-browser
+func TestConfigDir(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("the old directory is in $XDG_CONFIG_HOME only on Linux")
+	}
+	base := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", base)
+	state := filepath.Join(base, "agent-browser-go", "state", "github.json")
+	if err := os.MkdirAll(filepath.Dir(state), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(state, []byte("{}"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	dir := agentbrowser.ConfigDir()
+	if want := filepath.Join(base, "agent-browser"); dir != want {
+		t.Errorf("ConfigDir() = %s, want %s", dir, want)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "state", "github.json")); err != nil {
+		t.Errorf("saved state after the move: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(base, "agent-browser-go")); !os.IsNotExist(err) {
+		t.Error("the old config directory is still there")
+	}
+}
@@ -0,0 +1,373 @@
+package agentbrowser
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Cookie file formats.
+const (
+	CookieFormatNetscape = "netscape" // cookies.txt as read and written by curl and wget
+	CookieFormatJSON     = "json"     // JSON array as exported by browser cookie extensions
+)
+
+// netscapeHeader starts cookie files written by FormatCookies; curl looks for it.
+const netscapeHeader = "# Netscape HTTP Cookie File\n"
+
+// httpOnlyPrefix marks HttpOnly cookies in Netscape cookie files.
+const httpOnlyPrefix = "#HttpOnly_"
+
+// extensionCookie is a cookie in the JSON exported by browser extensions
+// such as EditThisCookie and Cookie-Editor. Reading also accepts the field
+// names of Cookie and of Playwright's storageState.
+type extensionCookie struct {
+	Name           string   `json:"name"`
+	Value          string   `json:"value"`
+	Domain         string   `json:"domain"`
+	Path           string   `json:"path"`
+	URL            string   `json:"url,omitempty"`
+	ExpirationDate *float64 `json:"expirationDate,omitempty"`
+	Expires        *float64 `json:"expires,omitempty"`
+	HostOnly       bool     `json:"hostOnly"`
+	HTTPOnly       bool     `json:"httpOnly"`
+	Secure         bool     `json:"secure"`
+	Session        bool     `json:"session"`
+	SameSite       string   `json:"sameSite"`
+}
+
+// CookieFormatFor returns the format for a cookie file path: JSON for .json
+// files, otherwise Netscape.
+func CookieFormatFor(path string) string {
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		return CookieFormatJSON
+	}
+	return CookieFormatNetscape
+}
+
+// FormatCookies encodes cookies in a cookie file format.
+func FormatCookies(cookies []Cookie, format string) ([]byte, error) {
+	switch format {
+	case CookieFormatNetscape:
+		var buf bytes.Buffer
+		buf.WriteString(netscapeHeader)
+		for _, c := range cookies {
+			domain := c.Domain
+			if c.HTTPOnly {
+				domain = httpOnlyPrefix + domain
+			}
+			path := c.Path
+			if path == "" {
+				path = "/"
+			}
+			expires := c.Expires
+			if expires < 0 {
+				expires = 0
+			}
+			fmt.Fprintf(&buf, "%s\t%s\t%s\t%s\t%d\t%s\t%s\n", domain, netscapeBool(strings.HasPrefix(c.Domain, ".")),
+				path, netscapeBool(c.Secure), expires, c.Name, c.Value)
+		}
+		return buf.Bytes(), nil
+	case CookieFormatJSON:
+		out := make([]extensionCookie, len(cookies))
+		for i, c := range cookies {
+			ec := extensionCookie{
+				Name:     c.Name,
+				Value:    c.Value,
+				Domain:   c.Domain,
+				Path:     c.Path,
+				HostOnly: !strings.HasPrefix(c.Domain, "."),
+				HTTPOnly: c.HTTPOnly,
+				Secure:   c.Secure,
+				Session:  c.Expires <= 0,
+				SameSite: extensionSameSite(c.SameSite),
+			}
+			if c.Expires > 0 {
+				expires := float64(c.Expires)
+				ec.ExpirationDate = &expires
+			}
+			out[i] = ec
+		}
+		return json.MarshalIndent(out, "", "  ")
+	default:
+		return nil, fmt.Errorf("unknown cookie format: %s (expected netscape or json)", format)
+	}
+}
+
+// ParseCookies decodes a cookie file. The format is detected from the
+// content: a JSON array of cookies, a JSON object with a "cookies" array
+// (storageState.json), or a Netscape cookies.txt.
+func ParseCookies(data []byte) ([]Cookie, error) {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 {
+		return nil, nil
+	}
+
+	switch trimmed[0] {
+	case '[':
+		var list []extensionCookie
+		if err := json.Unmarshal(trimmed, &list); err != nil {
+			return nil, fmt.Errorf("invalid JSON cookie file: %w", err)
+		}
+		return fromExtensionCookies(list), nil
+	case '{':
+		var state struct {
+			Cookies []extensionCookie `json:"cookies"`
+		}
+		if err := json.Unmarshal(trimmed, &state); err != nil {
+			return nil, fmt.Errorf("invalid JSON cookie file: %w", err)
+		}
+		return fromExtensionCookies(state.Cookies), nil
+	default:
+		return parseNetscapeCookies(string(data))
+	}
+}
+
+// parseNetscapeCookies parses a Netscape cookies.txt file.
+func parseNetscapeCookies(data string) ([]Cookie, error) {
+	var cookies []Cookie
+	for i, line := range strings.Split(data, "\n") {
+		line = strings.TrimRight(line, "\r")
+		httpOnly := strings.HasPrefix(line, httpOnlyPrefix)
+		if httpOnly {
+			line = strings.TrimPrefix(line, httpOnlyPrefix)
+		}
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Split(line, "\t")
+		if len(fields) != 7 {
+			return nil, fmt.Errorf("invalid cookie file line %d: expected 7 tab-separated fields, got %d", i+1, len(fields))
+		}
+		expires, err := strconv.ParseInt(fields[4], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cookie file line %d: bad expiry %q", i+1, fields[4])
+		}
+
+		domain := fields[0]
+		if strings.EqualFold(fields[1], "TRUE") && !strings.HasPrefix(domain, ".") {
+			domain = "." + domain
+		}
+		cookies = append(cookies, Cookie{
+			Name:     fields[5],
+			Value:    fields[6],
+			Domain:   domain,
+			Path:     fields[2],
+			Expires:  expires,
+			HTTPOnly: httpOnly,
+			Secure:   strings.EqualFold(fields[3], "TRUE"),
+		})
+	}
+	return cookies, nil
+}
+
+func fromExtensionCookies(list []extensionCookie) []Cookie {
+	cookies := make([]Cookie, len(list))
+	for i, ec := range list {
+		c := Cookie{
+			Name:     ec.Name,
+			Value:    ec.Value,
+			URL:      ec.URL,
+			Domain:   ec.Domain,
+			Path:     ec.Path,
+			HTTPOnly: ec.HTTPOnly,
+			Secure:   ec.Secure,
+			SameSite: cookieSameSite(ec.SameSite),
+		}
+		expires := ec.ExpirationDate
+		if expires == nil {
+			expires = ec.Expires
+		}
+		if expires != nil && *expires > 0 && !ec.Session {
+			c.Expires = int64(math.Round(*expires))
+		}
+		cookies[i] = c
+	}
+	return cookies
+}
+
+// cookieSameSite normalizes SameSite values from cookie exports to Strict,
+// Lax or None; unspecified values become empty.
+func cookieSameSite(s string) string {
+	switch strings.ToLower(s) {
+	case "strict":
+		return "Strict"
+	case "lax":
+		return "Lax"
+	case "none", "no_restriction":
+		return "None"
+	default:
+		return ""
+	}
+}
+
+// extensionSameSite converts a SameSite value to the browser extension spelling.
+func extensionSameSite(s string) string {
+	switch s {
+	case "Strict":
+		return "strict"
+	case "Lax":
+		return "lax"
+	case "None":
+		return "no_restriction"
+	default:
+		return "unspecified"
+	}
+}
+
+func netscapeBool(b bool) string {
+	if b {
+		return "TRUE"
+	}
+	return "FALSE"
+}
+
+// ExportCookies writes the browser's cookies to path in the given format
+// (default: from the file extension). It returns the number of cookies written.
+func (m *BrowserManager) ExportCookies(path, format string) (int, error) {
+	if format == "" {
+		format = CookieFormatFor(path)
+	}
+	cookies, err := m.backend.GetCookies()
+	if err != nil {
+		return 0, err
+	}
+	data, err := FormatCookies(cookies, format)
+	if err != nil {
+		return 0, err
+	}
+	// Cookies are credentials: keep the file private
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return 0, err
+	}
+	return len(cookies), nil
+}
+
+// ImportCookies adds the cookies in a cookie file to the browser. Expired
+// cookies are skipped. It returns the number of cookies imported.
+func (m *BrowserManager) ImportCookies(path string) (int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	cookies, err := ParseCookies(data)
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now().Unix()
+	valid := cookies[:0]
+	for _, c := range cookies {
+		if c.Expires <= 0 || c.Expires > now {
+			valid = append(valid, c)
+		}
+	}
+	if len(valid) == 0 {
+		return 0, nil
+	}
+	if err := m.backend.SetCookies(valid); err != nil {
+		return 0, err
+	}
+	return len(valid), nil
+}
+
+// cookieMatchesURL reports whether a browser sends c with a request to u:
+// the host domain-matches, the path matches at a "/" boundary, and secure
+// cookies only go over https.
+func cookieMatchesURL(c Cookie, u *url.URL) bool {
+	host := strings.ToLower(u.Hostname())
+	domain := strings.ToLower(c.Domain)
+	if strings.HasPrefix(domain, ".") {
+		if host != domain[1:] && !strings.HasSuffix(host, domain) {
+			return false
+		}
+	} else if host != domain {
+		return false
+	}
+
+	reqPath := u.EscapedPath()
+	if reqPath == "" {
+		reqPath = "/"
+	}
+	cookiePath := c.Path
+	if cookiePath == "" {
+		cookiePath = "/"
+	}
+	if reqPath != cookiePath && !(strings.HasPrefix(reqPath, cookiePath) &&
+		(strings.HasSuffix(cookiePath, "/") || reqPath[len(cookiePath)] == '/')) {
+		return false
+	}
+
+	return !c.Secure || u.Scheme == "https" || u.Scheme == "wss" || host == "localhost"
+}
+
+// Cookies returns the browser's cookies, or when urls are given only those
+// sent with a request to one of them.
+func (m *BrowserManager) Cookies(urls []string) ([]Cookie, error) {
+	parsed := make([]*url.URL, len(urls))
+	for i, raw := range urls {
+		u, err := url.Parse(raw)
+		if err != nil || u.Host == "" {
+			return nil, fmt.Errorf("invalid cookie url: %s", raw)
+		}
+		parsed[i] = u
+	}
+
+	cookies, err := m.backend.GetCookies()
+	if err != nil || len(parsed) == 0 {
+		return cookies, err
+	}
+	matched := []Cookie{}
+	for _, c := range cookies {
+		for _, u := range parsed {
+			if cookieMatchesURL(c, u) {
+				matched = append(matched, c)
+				break
+			}
+		}
+	}
+	return matched, nil
+}
+
+// SetCookies adds cookies to the browser, e.g. to sign in before the first
+// navigation. Each cookie needs a name and a url or domain; cookies with
+// neither are set for the current page.
+func (m *BrowserManager) SetCookies(cookies []Cookie) error {
+	if len(cookies) == 0 {
+		return fmt.Errorf("no cookies to set")
+	}
+	cookies = append([]Cookie(nil), cookies...)
+	var pageURL string
+	for i := range cookies {
+		c := &cookies[i]
+		if c.Name == "" {
+			return fmt.Errorf("cookie %d has no name", i+1)
+		}
+		if c.SameSite != "" {
+			sameSite := cookieSameSite(c.SameSite)
+			if sameSite == "" {
+				return fmt.Errorf("cookie %s: invalid sameSite %q (expected Strict, Lax or None)", c.Name, c.SameSite)
+			}
+			c.SameSite = sameSite
+		}
+		if c.URL != "" || c.Domain != "" {
+			continue
+		}
+		if pageURL == "" {
+			pageURL, _ = m.backend.URL()
+			if !strings.HasPrefix(pageURL, "http://") && !strings.HasPrefix(pageURL, "https://") {
+				return fmt.Errorf("cookie %s needs a url or domain: the page has no http(s) URL", c.Name)
+			}
+		}
+		c.URL = pageURL
+	}
+	return m.backend.SetCookies(cookies)
+}
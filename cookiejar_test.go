@@ -0,0 +1,252 @@
+package agentbrowser_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	agentbrowser "github.com/cpunion/agent-browser-go"
+)
+
+// TestParseCookies tests reading Netscape, extension JSON and storageState cookie files
+func TestParseCookies(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want []agentbrowser.Cookie
+	}{
+		{
+			"netscape",
+			"# Netscape HTTP Cookie File\n" +
+				"# comment\n\n" +
+				"example.com\tFALSE\t/\tFALSE\t0\tsid\tabc\n" +
+				"#HttpOnly_.example.com\tTRUE\t/app\tTRUE\t1900000000\ttoken\tx y\r\n" +
+				"sub.example.com\tTRUE\t/\tFALSE\t0\tlang\ten\n",
+			[]agentbrowser.Cookie{
+				{Name: "sid", Value: "abc", Domain: "example.com", Path: "/"},
+				{Name: "token", Value: "x y", Domain: ".example.com", Path: "/app", Expires: 1900000000, HTTPOnly: true, Secure: true},
+				{Name: "lang", Value: "en", Domain: ".sub.example.com", Path: "/"},
+			},
+		},
+		{
+			"extension json",
+			`[{"domain": ".example.com", "expirationDate": 1900000000.4, "hostOnly": false, "httpOnly": true,
+				"name": "token", "path": "/", "sameSite": "no_restriction", "secure": true, "session": false, "value": "t"},
+			  {"domain": "example.com", "hostOnly": true, "name": "sid", "path": "/", "sameSite": "unspecified",
+				"session": true, "value": "abc"}]`,
+			[]agentbrowser.Cookie{
+				{Name: "token", Value: "t", Domain: ".example.com", Path: "/", Expires: 1900000000, HTTPOnly: true, Secure: true, SameSite: "None"},
+				{Name: "sid", Value: "abc", Domain: "example.com", Path: "/"},
+			},
+		},
+		{
+			"storage state",
+			`{"cookies": [{"name": "sid", "value": "abc", "domain": "example.com", "path": "/", "expires": -1,
+				"httpOnly": false, "secure": false, "sameSite": "Lax"}], "origins": []}`,
+			[]agentbrowser.Cookie{
+				{Name: "sid", Value: "abc", Domain: "example.com", Path: "/", SameSite: "Lax"},
+			},
+		},
+		{"empty", "  \n", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := agentbrowser.ParseCookies([]byte(tt.data))
+			if err != nil {
+				t.Fatalf("ParseCookies() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseCookies() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	for _, bad := range []string{"example.com\tFALSE\t/\n", "example.com\tFALSE\t/\tFALSE\tsoon\tsid\tabc\n", "[{"} {
+		if _, err := agentbrowser.ParseCookies([]byte(bad)); err == nil {
+			t.Errorf("ParseCookies(%q) expected error", bad)
+		}
+	}
+}
+
+// TestFormatCookies tests that formatted cookie files parse back to the same cookies
+func TestFormatCookies(t *testing.T) {
+	cookies := []agentbrowser.Cookie{
+		{Name: "sid", Value: "abc", Domain: "example.com", Path: "/", Expires: -1},
+		{Name: "token", Value: "t", Domain: ".example.com", Path: "/app", Expires: 1900000000, HTTPOnly: true, Secure: true, SameSite: "Strict"},
+	}
+
+	for _, format := range []string{agentbrowser.CookieFormatNetscape, agentbrowser.CookieFormatJSON} {
+		t.Run(format, func(t *testing.T) {
+			data, err := agentbrowser.FormatCookies(cookies, format)
+			if err != nil {
+				t.Fatalf("FormatCookies() error = %v", err)
+			}
+			got, err := agentbrowser.ParseCookies(data)
+			if err != nil {
+				t.Fatalf("ParseCookies() error = %v", err)
+			}
+
+			want := []agentbrowser.Cookie{
+				{Name: "sid", Value: "abc", Domain: "example.com", Path: "/"},
+				{Name: "token", Value: "t", Domain: ".example.com", Path: "/app", Expires: 1900000000, HTTPOnly: true, Secure: true, SameSite: "Strict"},
+			}
+			if format == agentbrowser.CookieFormatNetscape {
+				// cookies.txt has no SameSite column
+				want[1].SameSite = ""
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("round trip = %+v, want %+v", got, want)
+			}
+		})
+	}
+
+	data, _ := agentbrowser.FormatCookies(cookies, agentbrowser.CookieFormatNetscape)
+	if !strings.HasPrefix(string(data), "# Netscape HTTP Cookie File\n") {
+		t.Errorf("netscape output missing header: %q", data)
+	}
+	if _, err := agentbrowser.FormatCookies(cookies, "yaml"); err == nil {
+		t.Error("FormatCookies(yaml) expected error")
+	}
+}
+
+// cookieBackend is a fake backend on the page *url that stores the cookies
+// it is given in *cookies.
+func cookieBackend(url *string, cookies *[]agentbrowser.Cookie) *fakeBackend {
+	return &fakeBackend{
+		url:        func() (string, error) { return *url, nil },
+		getCookies: func() ([]agentbrowser.Cookie, error) { return *cookies, nil },
+		setCookies: func(c []agentbrowser.Cookie) error {
+			*cookies = append(*cookies, c...)
+			return nil
+		},
+	}
+}
+
+// TestImportExportCookies tests cookie files written and read through the browser manager
+func TestImportExportCookies(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "in.txt")
+	data := "expired.com\tFALSE\t/\tFALSE\t1000\told\tx\n" +
+		"example.com\tFALSE\t/\tFALSE\t0\tsid\tabc\n"
+	if err := os.WriteFile(src, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	var url string
+	var cookies []agentbrowser.Cookie
+	m := agentbrowser.NewBrowserManagerForTest(cookieBackend(&url, &cookies))
+	n, err := m.ImportCookies(src)
+	if err != nil || n != 1 {
+		t.Fatalf("ImportCookies() = %d, %v, want 1 (expired skipped)", n, err)
+	}
+	if len(cookies) != 1 || cookies[0].Name != "sid" {
+		t.Errorf("imported cookies = %+v", cookies)
+	}
+
+	dst := filepath.Join(dir, "out.json")
+	if n, err := m.ExportCookies(dst, ""); err != nil || n != 1 {
+		t.Fatalf("ExportCookies() = %d, %v, want 1", n, err)
+	}
+	out, err := os.ReadFile(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(strings.TrimSpace(string(out)), "[") {
+		t.Errorf("ExportCookies(.json) wrote %q, want JSON array", out)
+	}
+}
+
+// TestCookiesForURL tests selecting the cookies a browser sends to a URL
+func TestCookiesForURL(t *testing.T) {
+	var url string
+	cookies := []agentbrowser.Cookie{
+		{Name: "host", Domain: "example.com", Path: "/"},
+		{Name: "wide", Domain: ".example.com", Path: "/"},
+		{Name: "app", Domain: "example.com", Path: "/app"},
+		{Name: "secure", Domain: "example.com", Path: "/", Secure: true},
+		{Name: "other", Domain: "other.org", Path: "/"},
+	}
+	m := agentbrowser.NewBrowserManagerForTest(cookieBackend(&url, &cookies))
+
+	names := func(urls ...string) []string {
+		t.Helper()
+		cookies, err := m.Cookies(urls)
+		if err != nil {
+			t.Fatalf("Cookies(%v) error = %v", urls, err)
+		}
+		var names []string
+		for _, c := range cookies {
+			names = append(names, c.Name)
+		}
+		return names
+	}
+
+	tests := []struct {
+		urls []string
+		want []string
+	}{
+		{nil, []string{"host", "wide", "app", "secure", "other"}},
+		{[]string{"http://example.com/"}, []string{"host", "wide"}},
+		{[]string{"https://example.com/app/settings"}, []string{"host", "wide", "app", "secure"}},
+		{[]string{"https://example.com/application"}, []string{"host", "wide", "secure"}},
+		{[]string{"https://shop.example.com/"}, []string{"wide"}},
+		{[]string{"https://notexample.com/"}, nil},
+		{[]string{"http://other.org/", "http://example.com/app"}, []string{"host", "wide", "app", "other"}},
+	}
+	for _, tt := range tests {
+		if got := names(tt.urls...); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Cookies(%v) = %v, want %v", tt.urls, got, tt.want)
+		}
+	}
+
+	if _, err := m.Cookies([]string{"example.com"}); err == nil {
+		t.Error("Cookies(example.com) expected an error for a URL without a scheme")
+	}
+}
+
+// TestSetCookies tests cookie checks and defaults before they reach the browser
+func TestSetCookies(t *testing.T) {
+	url := "https://example.com/login"
+	var set []agentbrowser.Cookie
+	m := agentbrowser.NewBrowserManagerForTest(cookieBackend(&url, &set))
+
+	cookies := []agentbrowser.Cookie{
+		{Name: "sid", Value: "abc", SameSite: "lax"},
+		{Name: "pref", Value: "dark", Domain: ".example.com"},
+	}
+	if err := m.SetCookies(cookies); err != nil {
+		t.Fatalf("SetCookies() error = %v", err)
+	}
+	want := []agentbrowser.Cookie{
+		{Name: "sid", Value: "abc", URL: "https://example.com/login", SameSite: "Lax"},
+		{Name: "pref", Value: "dark", Domain: ".example.com"},
+	}
+	if !reflect.DeepEqual(set, want) {
+		t.Errorf("backend got %+v, want %+v", set, want)
+	}
+	if cookies[0].URL != "" {
+		t.Error("SetCookies() modified the caller's cookies")
+	}
+
+	set = nil
+	for _, bad := range [][]agentbrowser.Cookie{
+		nil,
+		{{Value: "no name", Domain: "example.com"}},
+		{{Name: "sid", Domain: "example.com", SameSite: "sometimes"}},
+	} {
+		if err := m.SetCookies(bad); err == nil {
+			t.Errorf("SetCookies(%+v) expected an error", bad)
+		}
+	}
+
+	url = "about:blank"
+	if err := m.SetCookies([]agentbrowser.Cookie{{Name: "sid"}}); err == nil {
+		t.Error("SetCookies() without url or domain on about:blank expected an error")
+	}
+	if set != nil {
+		t.Errorf("backend got %+v, want no cookies", set)
+	}
+}
@@ -0,0 +1,165 @@
+package agentbrowser
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// CrawlOptions configures a crawl.
+type CrawlOptions struct {
+	StartURL   string
+	Depth      int           // link levels to follow; 0 visits only the start page
+	SameOrigin bool          // only follow links to the start page's origin
+	MaxPages   int           // stop after this many pages
+	Delay      time.Duration // minimum time between requests to the same host
+	Markdown   bool          // include each page's content as Markdown
+	Polite     bool          // skip URLs robots.txt disallows and honor its Crawl-delay
+}
+
+// CrawlPage is the record of one crawled page.
+type CrawlPage struct {
+	URL      string   `json:"url"` // final URL after redirects
+	Depth    int      `json:"depth"`
+	Title    string   `json:"title"`
+	Status   int      `json:"status,omitempty"` // HTTP status, 0 when unknown
+	Links    []string `json:"links"`
+	Markdown string   `json:"markdown,omitempty"`
+	Error    string   `json:"error,omitempty"`
+}
+
+// crawlPageScript returns the navigation's HTTP status and the page's
+// http(s) links.
+const crawlPageScript = `(() => {
+	const nav = performance.getEntriesByType('navigation')[0];
+	const links = Array.from(document.querySelectorAll('a[href]'), (a) => a.href)
+		.filter((href) => href.startsWith('http://') || href.startsWith('https://'));
+	return { status: nav ? nav.responseStatus || 0 : 0, links };
+})()`
+
+// Crawl visits pages breadth-first from the start URL in the active tab,
+// following links up to opts.Depth levels. Pages that fail to load, or
+// that robots.txt disallows in polite mode, are recorded with an error and
+// not followed. The tab is left on the last
+// page visited.
+func (m *BrowserManager) Crawl(opts CrawlOptions) ([]CrawlPage, error) {
+	start, err := url.Parse(opts.StartURL)
+	if err != nil || (start.Scheme != "http" && start.Scheme != "https") {
+		return nil, fmt.Errorf("invalid start URL %q: must be http or https", opts.StartURL)
+	}
+	maxPages := opts.MaxPages
+	if maxPages <= 0 {
+		maxPages = 50
+	}
+
+	type item struct {
+		url   string
+		depth int
+	}
+	queue := []item{{crawlKey(start), 0}}
+	seen := map[string]bool{crawlKey(start): true}
+	lastRequest := make(map[string]time.Time) // per host
+	polite := opts.Polite || m.Polite()
+	pages := []CrawlPage{}
+
+	for len(queue) > 0 && len(pages) < maxPages {
+		next := queue[0]
+		queue = queue[1:]
+
+		u, _ := url.Parse(next.url)
+		delay := opts.Delay
+		if polite {
+			if !m.RobotsAllowed(next.url) {
+				pages = append(pages, CrawlPage{URL: next.url, Depth: next.depth, Links: []string{}, Error: "disallowed by robots.txt"})
+				continue
+			}
+			delay = max(delay, m.robotsFor(u.Scheme+"://"+u.Host).CrawlDelay(RobotsUserAgent))
+		}
+		if wait := delay - time.Since(lastRequest[u.Host]); wait > 0 {
+			time.Sleep(wait)
+		}
+		lastRequest[u.Host] = time.Now()
+
+		page := m.crawlPage(next.url, opts.Markdown)
+		page.Depth = next.depth
+		pages = append(pages, page)
+		if fu, err := url.Parse(page.URL); err == nil {
+			seen[crawlKey(fu)] = true // reached through a redirect
+		}
+		if page.Error != "" || next.depth >= opts.Depth {
+			continue
+		}
+
+		for _, link := range page.Links {
+			lu, err := url.Parse(link)
+			if err != nil {
+				continue
+			}
+			if opts.SameOrigin && (lu.Scheme != start.Scheme || lu.Host != start.Host) {
+				continue
+			}
+			key := crawlKey(lu)
+			if !seen[key] {
+				seen[key] = true
+				queue = append(queue, item{key, next.depth + 1})
+			}
+		}
+	}
+	return pages, nil
+}
+
+// crawlPage loads one page and extracts its record.
+func (m *BrowserManager) crawlPage(pageURL string, markdown bool) CrawlPage {
+	page := CrawlPage{URL: pageURL, Links: []string{}}
+	finalURL, title, err := m.Navigate(pageURL, "load")
+	if err != nil {
+		page.Error = err.Error()
+		return page
+	}
+	page.URL, page.Title = finalURL, title
+
+	result, err := m.backend.Evaluate(crawlPageScript)
+	if err != nil {
+		page.Error = err.Error()
+		return page
+	}
+	data, _ := json.Marshal(result)
+	var info struct {
+		Status int      `json:"status"`
+		Links  []string `json:"links"`
+	}
+	if err := json.Unmarshal(data, &info); err != nil {
+		page.Error = fmt.Sprintf("unexpected page info: %v", err)
+		return page
+	}
+	page.Status = info.Status
+
+	seen := make(map[string]bool)
+	for _, link := range info.Links {
+		lu, err := url.Parse(link)
+		if err != nil {
+			continue
+		}
+		if key := crawlKey(lu); !seen[key] {
+			seen[key] = true
+			page.Links = append(page.Links, key)
+		}
+	}
+
+	if markdown {
+		if page.Markdown, err = m.Markdown(); err != nil {
+			page.Error = err.Error()
+		}
+	}
+	return page
+}
+
+// crawlKey normalizes a URL for deduplication: the fragment names a place
+// in the same page, so it is dropped.
+func crawlKey(u *url.URL) string {
+	c := *u
+	c.Fragment = ""
+	c.RawFragment = ""
+	return c.String()
+}
@@ -0,0 +1,117 @@
+package agentbrowser_test
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	agentbrowser "github.com/cpunion/agent-browser-go"
+)
+
+// siteBackend is a fake backend serving a link graph: each URL maps to the
+// links on it. The URLs navigated to are appended to *visited.
+func siteBackend(site map[string][]string, visited *[]string) *fakeBackend {
+	var current string
+	return &fakeBackend{
+		navigate: func(url, waitUntil string) (string, string, error) {
+			*visited = append(*visited, url)
+			if _, ok := site[url]; !ok {
+				return "", "", fmt.Errorf("net::ERR_NAME_NOT_RESOLVED")
+			}
+			current = url
+			return url, "title " + url, nil
+		},
+		url: func() (string, error) { return current, nil },
+		evaluate: func(script string) (interface{}, error) {
+			if strings.Contains(script, "getEntriesByType") {
+				links := make([]interface{}, len(site[current]))
+				for i, link := range site[current] {
+					links[i] = link
+				}
+				return map[string]interface{}{"status": 200, "links": links}, nil
+			}
+			return "# " + current, nil
+		},
+	}
+}
+
+// TestCrawl tests breadth-first crawling with depth, origin and page limits
+func TestCrawl(t *testing.T) {
+	site := map[string][]string{
+		"https://a.com/":    {"https://a.com/1", "https://a.com/2#top", "https://b.com/", "https://a.com/#main"},
+		"https://a.com/1":   {"https://a.com/1/x", "https://a.com/2"},
+		"https://a.com/2":   {"https://a.com/dead"},
+		"https://b.com/":    {"https://b.com/1"},
+		"https://a.com/1/x": nil,
+		"https://b.com/1":   nil,
+	}
+	tests := []struct {
+		name string
+		opts agentbrowser.CrawlOptions
+		want []string
+	}{
+		{
+			name: "start page only",
+			opts: agentbrowser.CrawlOptions{StartURL: "https://a.com/"},
+			want: []string{"https://a.com/"},
+		},
+		{
+			name: "depth 1",
+			opts: agentbrowser.CrawlOptions{StartURL: "https://a.com/", Depth: 1},
+			want: []string{"https://a.com/", "https://a.com/1", "https://a.com/2", "https://b.com/"},
+		},
+		{
+			name: "depth 2 same origin",
+			opts: agentbrowser.CrawlOptions{StartURL: "https://a.com/", Depth: 2, SameOrigin: true},
+			want: []string{"https://a.com/", "https://a.com/1", "https://a.com/2", "https://a.com/1/x", "https://a.com/dead"},
+		},
+		{
+			name: "max pages",
+			opts: agentbrowser.CrawlOptions{StartURL: "https://a.com/", Depth: 5, MaxPages: 2},
+			want: []string{"https://a.com/", "https://a.com/1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var visited []string
+			m := agentbrowser.NewBrowserManagerForTest(siteBackend(site, &visited))
+			pages, err := m.Crawl(tt.opts)
+			if err != nil {
+				t.Fatalf("Crawl() error = %v", err)
+			}
+			if !reflect.DeepEqual(visited, tt.want) {
+				t.Errorf("visited %q, want %q", visited, tt.want)
+			}
+			if len(pages) != len(tt.want) {
+				t.Fatalf("got %d pages, want %d", len(pages), len(tt.want))
+			}
+		})
+	}
+
+	t.Run("records", func(t *testing.T) {
+		var visited []string
+		m := agentbrowser.NewBrowserManagerForTest(siteBackend(site, &visited))
+		pages, err := m.Crawl(agentbrowser.CrawlOptions{StartURL: "https://a.com/2", Depth: 1, Markdown: true})
+		if err != nil {
+			t.Fatalf("Crawl() error = %v", err)
+		}
+		want := []agentbrowser.CrawlPage{
+			{URL: "https://a.com/2", Title: "title https://a.com/2", Status: 200, Links: []string{"https://a.com/dead"}, Markdown: "# https://a.com/2"},
+			{URL: "https://a.com/dead", Depth: 1, Links: []string{}, Error: "net::ERR_NAME_NOT_RESOLVED"},
+		}
+		if !reflect.DeepEqual(pages, want) {
+			t.Errorf("Crawl() = %+v, want %+v", pages, want)
+		}
+		// The first page's links are deduplicated without fragments
+		pages, _ = m.Crawl(agentbrowser.CrawlOptions{StartURL: "https://a.com/"})
+		if want := []string{"https://a.com/1", "https://a.com/2", "https://b.com/", "https://a.com/"}; !reflect.DeepEqual(pages[0].Links, want) {
+			t.Errorf("Links = %q, want %q", pages[0].Links, want)
+		}
+	})
+
+	if _, err := agentbrowser.NewBrowserManagerForTest(siteBackend(nil, new([]string))).Crawl(agentbrowser.CrawlOptions{StartURL: "file:///etc/passwd"}); err == nil {
+		t.Error("Crawl(file URL) error = nil, want error")
+	}
+}
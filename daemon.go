@@ -2,18 +2,25 @@ package agentbrowser
 
 import (
 	"bufio"
+	"bytes"
+	"cmp"
+	"context"
 	"crypto/md5"
 	"encoding/binary"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net"
 	"os"
 	"os/signal"
 	"path/filepath"
 	"runtime"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
 )
@@ -28,6 +35,35 @@ type Daemon struct {
 	mu          sync.Mutex
 	userDataDir string
 	locale      string
+	commands    *commandLog
+	started     time.Time
+	clients     atomic.Int32 // open connections
+
+	// Connections subscribed to events
+	subsLock    sync.Mutex
+	subscribers map[*daemonConn]bool
+
+	// Commands held back while a person uses the browser
+	pause pauseGate
+}
+
+// daemonConn is a client connection. Writes are serialized because events
+// can be pushed while a command is being answered.
+type daemonConn struct {
+	net.Conn
+	writeLock sync.Mutex
+	wants     func(string) bool // event filter, set while subscribed
+}
+
+// eventWriteTimeout bounds how long a slow subscriber can hold up delivery.
+const eventWriteTimeout = time.Second
+
+// write sends one newline-terminated message.
+func (c *daemonConn) write(data []byte) error {
+	c.writeLock.Lock()
+	defer c.writeLock.Unlock()
+	_, err := c.Conn.Write(data)
+	return err
 }
 
 // NewDaemon creates a new daemon instance.
@@ -52,67 +88,176 @@ func NewDaemonFull(session string, backendType string, userDataDir string, local
 		backend = BackendChromedp
 	}
 
-	return &Daemon{
+	d := &Daemon{
 		session:     session,
 		browser:     NewBrowserManagerWithBackend(backend),
 		shutdown:    make(chan struct{}),
 		userDataDir: userDataDir,
 		locale:      locale,
+		subscribers: make(map[*daemonConn]bool),
+		started:     time.Now(),
 	}
+	d.browser.SetEventHandler(d.broadcast)
+	logDir := GetSessionLogDir(session)
+	d.browser.SetLogDir(logDir)
+	d.browser.SetLaunchDefaults(d.launchOptions())
+	d.commands = newCommandLog(logDir)
+	return d
 }
 
-// GetBackendFile returns the backend file path for a session.
-func GetBackendFile(session string) string {
-	dir := filepath.Join(os.TempDir(), "agent-browser-go")
-	_ = os.MkdirAll(dir, 0755)
-	return filepath.Join(dir, fmt.Sprintf("%s.backend", session))
+// launchOptions returns the options the session's browser launches with:
+// the daemon's profile and locale, the preferences saved for the session
+// when it started and the launch settings of the session config.
+func (d *Daemon) launchOptions() LaunchOptions {
+	opts := LaunchOptions{
+		Headless:    !GetSessionHeaded(d.session),
+		Viewport:    GetSessionViewport(d.session),
+		UserDataDir: d.userDataDir,
+		Locale:      d.locale,
+		Stealth:     GetSessionStealth(d.session),
+		Fingerprint: GetSessionFingerprint(d.session),
+		TLS:         GetSessionTLS(d.session),
+		HostRules:   GetSessionHostRules(d.session),
+		UserAgent:   GetSessionUserAgent(d.session),
+
+		BlockServiceWorkers: GetSessionBlockServiceWorkers(d.session),
+	}
+	opts.Proxy = GetSessionProxy(d.session)
+	if opts.Proxy == nil {
+		sc := sessionConfig(d.session)
+		opts.Proxy, _ = ParseProxy(sc.Proxy, sc.ProxyBypass) // checked when the config was read
+	}
+	return opts
 }
 
-// SaveSessionBackend saves the backend type for a session.
+// legacySessionFile returns the temp directory file in which older versions
+// kept a session's backend, headed mode or viewport, by extension.
+func legacySessionFile(session, ext string) string {
+	return filepath.Join(os.TempDir(), "agent-browser-go", session+"."+ext)
+}
+
+// migrateSessionFiles moves the backend, headed mode and viewport older
+// versions kept in the temp directory into the session's entry in the user
+// config file, and removes the files.
+func migrateSessionFiles(session string) {
+	settings := map[string]any{}
+	var files []string
+	for _, ext := range []string{"backend", "headed", "viewport"} {
+		file := legacySessionFile(session, ext)
+		data, err := os.ReadFile(file)
+		if err != nil {
+			continue
+		}
+		files = append(files, file)
+		switch value := string(data); {
+		case ext == "headed":
+			settings[ext] = value == "true"
+		case ext == "backend" && (value == "chromedp" || value == "playwright"):
+			settings[ext] = value
+		case ext == "viewport" && value != "":
+			if _, err := ParseViewport(value); err == nil {
+				settings[ext] = value
+			}
+		}
+	}
+	if len(files) == 0 {
+		return
+	}
+	if len(settings) > 0 {
+		if err := updateSessionConfig(session, settings); err != nil {
+			Logger().Warn("moving saved session settings to the config file", "session", session, "err", err)
+			return
+		}
+	}
+	for _, file := range files {
+		_ = os.Remove(file)
+	}
+}
+
+// SaveSessionBackend records the backend a session's daemon starts with in
+// the session's entry of the user config file, which makes it the
+// session's default from then on.
 func SaveSessionBackend(session, backend string) error {
-	backendFile := GetBackendFile(session)
-	return os.WriteFile(backendFile, []byte(backend), 0644)
+	return updateSessionConfig(session, map[string]any{"backend": backend})
 }
 
-// GetSessionBackend retrieves the saved backend type for a session.
-// Returns "chromedp" as default if not found.
+// GetSessionBackend returns the backend of a session: the one its running
+// daemon started with, or else the session config's, which the last start
+// recorded unless a project config sets another, or "chromedp".
 func GetSessionBackend(session string) string {
-	backendFile := GetBackendFile(session)
-	data, err := os.ReadFile(backendFile)
-	if err != nil {
-		return "chromedp" // Default
+	migrateSessionFiles(session)
+	if IsDaemonRunning(session) {
+		if backend := savedSessionConfig(session).Backend; backend != "" {
+			return backend
+		}
+	}
+	return cmp.Or(sessionConfig(session).Backend, "chromedp")
+}
+
+// SaveSessionHeaded records whether a session's daemon shows its browser in
+// the session's entry of the user config file.
+func SaveSessionHeaded(session string, headed bool) error {
+	return updateSessionConfig(session, map[string]any{"headed": headed})
+}
+
+// GetSessionHeaded returns whether a session's daemon shows its browser:
+// as recorded when it started, or else as the session config says. The
+// default is headless.
+func GetSessionHeaded(session string) bool {
+	migrateSessionFiles(session)
+	headed := savedSessionConfig(session).Headed
+	if headed == nil {
+		headed = sessionConfig(session).Headed
+	}
+	return headed != nil && *headed
+}
+
+// SaveSessionViewport records the viewport a session's browser launches
+// with in the session's entry of the user config file; nil records the
+// default.
+func SaveSessionViewport(session string, viewport *Viewport) error {
+	var value any
+	if viewport != nil {
+		value = fmt.Sprintf("%dx%d", viewport.Width, viewport.Height)
 	}
-	backend := string(data)
-	if backend == "" {
-		return "chromedp"
+	return updateSessionConfig(session, map[string]any{"viewport": value})
+}
+
+// GetSessionViewport returns the viewport a session's browser launches
+// with: as recorded when its daemon started, or else the session config's.
+// nil is the default.
+func GetSessionViewport(session string) *Viewport {
+	migrateSessionFiles(session)
+	value := cmp.Or(savedSessionConfig(session).Viewport, sessionConfig(session).Viewport)
+	if value == "" {
+		return nil
 	}
-	return backend
+	viewport, _ := ParseViewport(value) // checked when the config was read
+	return viewport
 }
 
-// GetHeadedFile returns the headed preference file path for a session.
-func GetHeadedFile(session string) string {
+// GetStealthFile returns the stealth preference file path for a session.
+func GetStealthFile(session string) string {
 	dir := filepath.Join(os.TempDir(), "agent-browser-go")
 	_ = os.MkdirAll(dir, 0755)
-	return filepath.Join(dir, fmt.Sprintf("%s.headed", session))
+	return filepath.Join(dir, fmt.Sprintf("%s.stealth", session))
 }
 
-// SaveSessionHeaded saves the headed preference for a session.
-func SaveSessionHeaded(session string, headed bool) error {
-	headedFile := GetHeadedFile(session)
+// SaveSessionStealth saves the stealth preference for a session.
+func SaveSessionStealth(session string, stealth bool) error {
 	value := "false"
-	if headed {
+	if stealth {
 		value = "true"
 	}
-	return os.WriteFile(headedFile, []byte(value), 0644)
+	return os.WriteFile(GetStealthFile(session), []byte(value), 0644)
 }
 
-// GetSessionHeaded retrieves the saved headed preference for a session.
-// Returns false (headless) as default if not found.
-func GetSessionHeaded(session string) bool {
-	headedFile := GetHeadedFile(session)
-	data, err := os.ReadFile(headedFile)
+// GetSessionStealth retrieves the saved stealth preference for a session.
+// Returns false as default if not found.
+func GetSessionStealth(session string) bool {
+	data, err := os.ReadFile(GetStealthFile(session))
 	if err != nil {
-		return false // Default to headless
+		return false
 	}
 	return string(data) == "true"
 }
@@ -276,12 +421,46 @@ func (d *Daemon) Start() error {
 		d.Stop()
 	}()
 
+	// Init scripts added in earlier runs of the session
+	for _, script := range GetSessionInitScripts(d.session) {
+		if err := d.browser.AddInitScript(script); err != nil {
+			Logger().Warn("init script", "err", err)
+		}
+	}
+
+	// Session defaults from the config file, re-read on SIGHUP
+	if _, err := d.ReloadConfig(); err != nil {
+		Logger().Warn("config", "err", err)
+	}
+	hupChan := make(chan os.Signal, 1)
+	signal.Notify(hupChan, syscall.SIGHUP)
+	go func() {
+		for range hupChan {
+			reload, err := d.ReloadConfig()
+			if err != nil {
+				Logger().Warn("config reload", "err", err)
+				continue
+			}
+			Logger().Info("config reloaded", "changed", reload.Changed, "pending", reload.Pending)
+		}
+	}()
+
 	// Accept connections
 	go d.acceptLoop()
 
 	return nil
 }
 
+// ReloadConfig re-reads the config file and applies the session's defaults
+// to the browser without restarting it.
+func (d *Daemon) ReloadConfig() (*ConfigReload, error) {
+	cfg, err := LoadConfig()
+	if err != nil {
+		return nil, err
+	}
+	return d.browser.ApplySessionConfig(cfg.Session(d.session))
+}
+
 // acceptLoop accepts incoming connections.
 func (d *Daemon) acceptLoop() {
 	for {
@@ -307,9 +486,14 @@ func (d *Daemon) acceptLoop() {
 }
 
 // handleConnection handles a single connection.
-func (d *Daemon) handleConnection(conn net.Conn) {
+func (d *Daemon) handleConnection(netConn net.Conn) {
 	defer d.connections.Done()
-	defer conn.Close()
+	defer netConn.Close()
+	d.clients.Add(1)
+	defer d.clients.Add(-1)
+
+	conn := &daemonConn{Conn: netConn}
+	defer d.unsubscribe(conn)
 
 	reader := bufio.NewReader(conn)
 
@@ -324,25 +508,74 @@ func (d *Daemon) handleConnection(conn net.Conn) {
 		// Parse and execute command
 		cmd, err := ParseCommand(line)
 		if err != nil {
+			Logger().Warn("invalid command", "err", err)
 			resp := ErrorResponse("", err.Error())
+			resp.Code = CodeInvalid
 			d.writeResponse(conn, resp)
 			continue
 		}
 
-		// Ensure browser is launched for most commands
+		// Subscriptions belong to the connection, not the browser
+		switch c := cmd.(type) {
+		case *SubscribeCommand:
+			d.subscribe(conn, c.Events)
+			d.writeResponse(conn, SuccessResponse(c.ID, map[string]interface{}{"subscribed": true, "events": c.Events}))
+			continue
+		case *UnsubscribeCommand:
+			d.unsubscribe(conn)
+			d.writeResponse(conn, SuccessResponse(c.ID, map[string]bool{"subscribed": false}))
+			continue
+		case *SessionInfoCommand:
+			// Answered while paused, and without launching the browser
+			d.writeResponse(conn, SuccessResponse(c.ID, d.SessionInfo()))
+			continue
+		case *ConfigReloadCommand:
+			// The config is per session, and reloading must not launch a browser
+			reload, err := d.ReloadConfig()
+			if err != nil {
+				d.writeResponse(conn, errorResponse(c.ID, err))
+			} else {
+				d.writeResponse(conn, SuccessResponse(c.ID, reload))
+			}
+			continue
+		case *PauseCommand:
+			if err := d.Pause(); err != nil {
+				d.writeResponse(conn, errorResponse(c.ID, err))
+			} else {
+				d.writeResponse(conn, SuccessResponse(c.ID, PauseData{Paused: true}))
+			}
+			continue
+		case *ResumeCommand:
+			paused, err := d.Resume()
+			if err != nil {
+				d.writeResponse(conn, errorResponse(c.ID, err))
+			} else {
+				d.writeResponse(conn, SuccessResponse(c.ID, PauseData{PausedFor: paused.Milliseconds()}))
+			}
+			continue
+		}
+
+		// Commands wait while the session is paused
+		if !d.pause.wait(d.shutdown) {
+			return
+		}
+
+		// Execute command, launching the browser first for most commands
 		action := cmd.GetAction()
-		if action != "launch" && action != "close" && !d.browser.IsLaunched() {
-			// Auto-launch with saved preferences
-			headed := GetSessionHeaded(d.session)
-			_ = d.browser.Launch(LaunchOptions{
-				Headless:    !headed,
-				UserDataDir: d.userDataDir,
-				Locale:      d.locale,
-			})
-		}
-
-		// Execute command
-		resp := ExecuteCommand(cmd, d.browser)
+		Logger().Debug("command", "action", action, "id", cmd.GetID())
+		start := time.Now()
+		resp := executeWithTimeout(cmd, d.browser, action != "launch" && action != "close")
+		d.commands.record(action, start, resp)
+		if resp.Success {
+			Logger().Debug("command done", "action", action, "id", cmd.GetID(), "elapsed", time.Since(start))
+		} else {
+			Logger().Info("command failed", "action", action, "id", cmd.GetID(), "elapsed", time.Since(start), "err", resp.Error)
+		}
+		if c, ok := cmd.(*AddInitScriptCommand); ok && resp.Success {
+			if err := AddSessionInitScript(d.session, c.Script); err != nil {
+				Logger().Warn("init script", "err", err)
+			}
+		}
 		d.writeResponse(conn, resp)
 
 		// Handle close command - shutdown daemon
@@ -357,14 +590,192 @@ func (d *Daemon) handleConnection(conn net.Conn) {
 	}
 }
 
+// executeWithTimeout executes a command, answering with a timeout error if
+// it has not finished within its command timeout, retries included. With
+// autoLaunch, a browser that is not launched is launched first, with saved
+// preferences or the options of the last launch command, within the same
+// timeout. A browser call cannot be interrupted, so the command still
+// finishes in the background, but is not retried any more, and later
+// commands wait for it before they start.
+func executeWithTimeout(cmd Command, browser *BrowserManager, autoLaunch bool) Response {
+	ms := cmd.GetCommandTimeout()
+	var deadline <-chan time.Time // never, without a timeout
+	if ms > 0 {
+		deadline = time.After(time.Duration(ms) * time.Millisecond)
+	}
+
+	for _, running := range browser.abandonedCommands() {
+		select {
+		case <-running.finished:
+		case <-deadline:
+			resp := ErrorResponse(cmd.GetID(), fmt.Sprintf("timeout: %s did not start within %dms, the browser is still busy with a %s that timed out", cmd.GetAction(), ms, running.action))
+			resp.Code = CodeTimeout
+			return resp
+		}
+	}
+
+	var launching atomic.Bool
+	run := func(stop <-chan struct{}) Response {
+		if autoLaunch && !browser.IsLaunched() {
+			launching.Store(true)
+			if err := browser.Launch(browser.launchDefaultOptions()); err != nil {
+				Logger().Error("auto-launch failed", "err", err)
+				resp := ErrorResponse(cmd.GetID(), err.Error())
+				resp.Code = CodeBrowser
+				return resp
+			}
+			launching.Store(false)
+		}
+		return executeWithRetry(cmd, browser, stop)
+	}
+	if ms <= 0 {
+		return run(nil)
+	}
+
+	done := make(chan Response, 1)
+	finished := make(chan struct{})
+	stop := make(chan struct{})
+	go func() {
+		defer close(finished)
+		done <- run(stop)
+	}()
+	select {
+	case resp := <-done:
+		return resp
+	case <-deadline:
+		close(stop)
+		browser.abandonCommand(abandonedCommand{action: cmd.GetAction(), finished: finished})
+		msg := fmt.Sprintf("timeout: %s did not finish within %dms", cmd.GetAction(), ms)
+		if launching.Load() {
+			msg = fmt.Sprintf("timeout: the browser did not launch within %dms for %s", ms, cmd.GetAction())
+		}
+		resp := ErrorResponse(cmd.GetID(), msg)
+		resp.Code = CodeTimeout
+		return resp
+	}
+}
+
+// abandonedCommand is a command that timed out but still runs until
+// finished is closed.
+type abandonedCommand struct {
+	action   string
+	finished <-chan struct{}
+}
+
+// abandonCommand records a command that timed out but still runs.
+func (m *BrowserManager) abandonCommand(c abandonedCommand) {
+	m.abandonLock.Lock()
+	defer m.abandonLock.Unlock()
+	m.abandoned = append(m.abandoned, c)
+}
+
+// abandonedCommands returns the commands that timed out and are still
+// running, forgetting those that have finished since.
+func (m *BrowserManager) abandonedCommands() []abandonedCommand {
+	m.abandonLock.Lock()
+	defer m.abandonLock.Unlock()
+	m.abandoned = slices.DeleteFunc(m.abandoned, func(c abandonedCommand) bool {
+		select {
+		case <-c.finished:
+			return true
+		default:
+			return false
+		}
+	})
+	return slices.Clone(m.abandoned)
+}
+
+// retryBaseDelay is the wait before the first retry of a command; each
+// later retry waits twice as long as the one before, up to retryMaxDelay.
+var retryBaseDelay = 250 * time.Millisecond
+
+const retryMaxDelay = 4 * time.Second
+
+// retryActions are the element actions that may be retried: repeating one
+// leaves the page as a single success would. Actions such as click, type and
+// press are not retried, as a failed attempt may already have had effects.
+var retryActions = map[string]bool{
+	"fill": true, "check": true, "uncheck": true, "select": true, "focus": true,
+	"hover": true, "clear": true, "setvalue": true, "scrollintoview": true,
+	"highlight": true, "wait": true, "gettext": true, "getattribute": true,
+	"innertext": true, "innerhtml": true, "inputvalue": true, "isvisible": true,
+	"isenabled": true, "ischecked": true, "boundingbox": true, "describe": true,
+}
+
+// executeWithRetry executes a command and, as dynamic pages often render an
+// element a moment later, retries an element action in retryActions up to
+// its retry count while it fails because its element is not found or a wait
+// timed out. Closing stop ends the retries.
+func executeWithRetry(cmd Command, browser *BrowserManager, stop <-chan struct{}) Response {
+	resp := ExecuteCommand(cmd, browser)
+	if !retryActions[cmd.GetAction()] {
+		return resp
+	}
+	delay := retryBaseDelay
+	for attempt := 0; attempt < cmd.GetRetry() && (resp.Code == CodeNotFound || resp.Code == CodeTimeout); attempt++ {
+		select {
+		case <-stop:
+			return resp
+		case <-time.After(delay):
+		}
+		Logger().Debug("retrying", "action", cmd.GetAction(), "attempt", attempt+1, "err", resp.Error)
+		delay = min(2*delay, retryMaxDelay)
+		resp = ExecuteCommand(cmd, browser)
+	}
+	return resp
+}
+
 // writeResponse writes a response to the connection.
-func (d *Daemon) writeResponse(conn net.Conn, resp Response) {
+func (d *Daemon) writeResponse(conn *daemonConn, resp Response) {
 	data, err := SerializeResponse(resp)
 	if err != nil {
 		data = []byte(fmt.Sprintf(`{"id":"","success":false,"error":"failed to serialize response: %s"}`, err.Error()))
 	}
 	data = append(data, '\n')
-	_, _ = conn.Write(data)
+	_ = conn.write(data)
+}
+
+// subscribe starts pushing events of the given types (all when empty) to conn.
+func (d *Daemon) subscribe(conn *daemonConn, events []string) {
+	d.subsLock.Lock()
+	defer d.subsLock.Unlock()
+	conn.wants = eventFilter(events)
+	d.subscribers[conn] = true
+}
+
+// unsubscribe stops pushing events to conn.
+func (d *Daemon) unsubscribe(conn *daemonConn) {
+	d.subsLock.Lock()
+	defer d.subsLock.Unlock()
+	delete(d.subscribers, conn)
+}
+
+// broadcast pushes an event to every subscriber that wants it. Subscribers
+// that cannot keep up are dropped.
+func (d *Daemon) broadcast(ev Event) {
+	data, err := json.Marshal(ev)
+	if err != nil {
+		return
+	}
+	data = append(data, '\n')
+
+	d.subsLock.Lock()
+	var targets []*daemonConn
+	for conn := range d.subscribers {
+		if conn.wants(ev.Event) {
+			targets = append(targets, conn)
+		}
+	}
+	d.subsLock.Unlock()
+
+	for _, conn := range targets {
+		_ = conn.SetWriteDeadline(time.Now().Add(eventWriteTimeout))
+		err := conn.write(data)
+		_ = conn.SetWriteDeadline(time.Time{})
+		if err != nil {
+			d.unsubscribe(conn)
+		}
+	}
 }
 
 // Stop stops the daemon.
@@ -419,6 +830,10 @@ func (d *Daemon) Wait() {
 type Client struct {
 	session string
 	conn    net.Conn
+	reader  *bufio.Reader
+	events  []Event       // events read while waiting for a response
+	timeout time.Duration // command timeout, 0 for none
+	retry   int           // retries of failed commands
 }
 
 // NewClient creates a new client.
@@ -441,18 +856,21 @@ func (c *Client) Connect() error {
 		if err != nil {
 			return fmt.Errorf("invalid port file")
 		}
+		Logger().Debug("connecting to daemon", "session", c.session, "port", port)
 		c.conn, err = net.Dial("tcp", fmt.Sprintf("127.0.0.1:%d", port))
 		if err != nil {
 			return fmt.Errorf("failed to connect to daemon: %w", err)
 		}
 	} else {
 		socketPath := GetSocketPath(c.session)
+		Logger().Debug("connecting to daemon", "session", c.session, "socket", socketPath)
 		c.conn, err = net.Dial("unix", socketPath)
 		if err != nil {
 			return fmt.Errorf("failed to connect to daemon: %w", err)
 		}
 	}
 
+	c.reader = bufio.NewReader(c.conn)
 	return nil
 }
 
@@ -544,30 +962,124 @@ func StopAllDaemons() error {
 	return nil
 }
 
+// clientTimeoutGrace is how much longer than the command timeout the
+// client waits for the daemon's answer, for the browser to launch and the
+// answer to arrive.
+var clientTimeoutGrace = 5 * time.Second
+
+// ErrNoResponse is returned by Send when the daemon does not answer a
+// command within its timeout.
+var ErrNoResponse = errors.New("timeout: no response from the daemon")
+
+// SetTimeout sets the timeout of the commands sent from now on that do not
+// have their own: the daemon answers with a timeout error when a command
+// runs longer, and Send gives up if that answer does not come either.
+func (c *Client) SetTimeout(timeout time.Duration) {
+	c.timeout = timeout
+}
+
+// SetRetry sets how many times the commands sent from now on that do not
+// have their own retry count are retried, with growing delays, while they
+// fail because their element is not found or a wait timed out.
+func (c *Client) SetRetry(n int) {
+	c.retry = n
+}
+
 // Send sends a command and receives the response.
 func (c *Client) Send(cmd Command) (Response, error) {
+	if c.retry > 0 {
+		if r, ok := cmd.(interface{ SetRetry(int) }); ok && cmd.GetRetry() == 0 {
+			r.SetRetry(c.retry)
+		}
+	}
+	if c.timeout > 0 {
+		if t, ok := cmd.(interface{ SetCommandTimeout(int) }); ok && cmd.GetCommandTimeout() == 0 {
+			t.SetCommandTimeout(int(c.timeout.Milliseconds()))
+		}
+	}
+	if ms := cmd.GetCommandTimeout(); ms > 0 {
+		limit := time.Duration(ms)*time.Millisecond + clientTimeoutGrace
+		_ = c.conn.SetReadDeadline(time.Now().Add(limit))
+		defer func() { _ = c.conn.SetReadDeadline(time.Time{}) }()
+	}
+
 	data, err := SerializeCommand(cmd)
 	if err != nil {
 		return Response{}, fmt.Errorf("failed to serialize command: %w", err)
 	}
 	data = append(data, '\n')
 
+	Logger().Debug("sending command", "action", cmd.GetAction(), "id", cmd.GetID())
 	if _, err := c.conn.Write(data); err != nil {
 		return Response{}, fmt.Errorf("failed to send command: %w", err)
 	}
 
-	reader := bufio.NewReader(c.conn)
-	respData, err := reader.ReadBytes('\n')
+	// Events pushed to a subscribed connection may arrive before the
+	// response; keep them for NextEvent.
+	for {
+		line, err := c.reader.ReadBytes('\n')
+		if errors.Is(err, os.ErrDeadlineExceeded) {
+			return Response{}, fmt.Errorf("%w to %s within %dms", ErrNoResponse, cmd.GetAction(), cmd.GetCommandTimeout())
+		}
+		if err != nil {
+			return Response{}, fmt.Errorf("failed to read response: %w", err)
+		}
+
+		if ev, ok := parseEvent(line); ok {
+			c.events = append(c.events, ev)
+			continue
+		}
+
+		var resp Response
+		if err := json.Unmarshal(line, &resp); err != nil {
+			return Response{}, fmt.Errorf("failed to parse response: %w", err)
+		}
+		return resp, nil
+	}
+}
+
+// Subscribe asks the daemon to push events of the given types (all types
+// when empty) on this connection. Read them with NextEvent.
+func (c *Client) Subscribe(events []string) error {
+	resp, err := c.Send(&SubscribeCommand{
+		BaseCommand: BaseCommand{ID: "subscribe", Action: "subscribe"},
+		Events:      events,
+	})
 	if err != nil {
-		return Response{}, fmt.Errorf("failed to read response: %w", err)
+		return err
 	}
+	if !resp.Success {
+		return fmt.Errorf("%s", resp.Error)
+	}
+	return nil
+}
 
-	var resp Response
-	if err := json.Unmarshal(respData, &resp); err != nil {
-		return Response{}, fmt.Errorf("failed to parse response: %w", err)
+// NextEvent blocks until the next event arrives on a subscribed connection.
+func (c *Client) NextEvent() (Event, error) {
+	if len(c.events) > 0 {
+		ev := c.events[0]
+		c.events = c.events[1:]
+		return ev, nil
+	}
+
+	for {
+		line, err := c.reader.ReadBytes('\n')
+		if err != nil {
+			return Event{}, err
+		}
+		if ev, ok := parseEvent(line); ok {
+			return ev, nil
+		}
 	}
+}
 
-	return resp, nil
+// parseEvent decodes a line if it is an event rather than a response.
+func parseEvent(line []byte) (Event, bool) {
+	var ev Event
+	if err := json.Unmarshal(line, &ev); err != nil || ev.Event == "" {
+		return Event{}, false
+	}
+	return ev, true
 }
 
 // SendRaw sends raw JSON and receives raw JSON response.
@@ -580,8 +1092,115 @@ func (c *Client) SendRaw(data []byte) ([]byte, error) {
 		return nil, fmt.Errorf("failed to send: %w", err)
 	}
 
-	reader := bufio.NewReader(c.conn)
-	return reader.ReadBytes('\n')
+	return c.reader.ReadBytes('\n')
+}
+
+// SendRawContext is SendRaw that gives up when ctx is done. The connection
+// is closed then, as the late response would be read as the next one's, so
+// the client cannot be used afterwards.
+func (c *Client) SendRawContext(ctx context.Context, data []byte) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	stop := context.AfterFunc(ctx, func() { _ = c.conn.Close() })
+	defer stop()
+
+	resp, err := c.SendRaw(data)
+	if err != nil && ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	return resp, err
+}
+
+// Pipe bridges newline-delimited JSON between r and the daemon: every
+// line of r is sent as a command, and the responses and events the daemon
+// sends are written to w, one per line. Commands without an id are given
+// one, their line number in r. Pipe returns once r is exhausted and every
+// command has been answered.
+func (c *Client) Pipe(r io.Reader, w io.Writer) error {
+	var writeLock sync.Mutex
+	writeLine := func(line []byte) error {
+		writeLock.Lock()
+		defer writeLock.Unlock()
+		_, err := w.Write(line)
+		return err
+	}
+	for _, ev := range c.events {
+		data, _ := json.Marshal(ev)
+		if err := writeLine(append(data, '\n')); err != nil {
+			return err
+		}
+	}
+	c.events = nil
+
+	// The reader never waits on the loop below, so events keep flowing
+	// while it waits on r
+	var answered atomic.Int64
+	notify := make(chan struct{}, 1)
+	readErr := make(chan error, 1)
+	go func() {
+		for {
+			line, err := c.reader.ReadBytes('\n')
+			if err != nil {
+				readErr <- err
+				return
+			}
+			if err := writeLine(line); err != nil {
+				readErr <- err
+				return
+			}
+			if _, ok := parseEvent(line); !ok {
+				answered.Add(1)
+				select {
+				case notify <- struct{}{}:
+				default:
+				}
+			}
+		}
+	}()
+
+	in := bufio.NewReader(r)
+	var sent int64
+	for n := 1; ; n++ {
+		line, err := in.ReadBytes('\n')
+		if line = bytes.TrimSpace(line); len(line) > 0 {
+			line = withCommandID(line, strconv.Itoa(n))
+			if _, err := c.conn.Write(append(line, '\n')); err != nil {
+				return fmt.Errorf("failed to send command: %w", err)
+			}
+			sent++
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	for answered.Load() < sent {
+		select {
+		case <-notify:
+		case err := <-readErr:
+			return fmt.Errorf("failed to read response: %w", err)
+		}
+	}
+	return nil
+}
+
+// withCommandID returns a command line with id set unless it has an id or
+// is not a JSON object, which the daemon will reject.
+func withCommandID(line []byte, id string) []byte {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(line, &fields); err != nil {
+		return line
+	}
+	if _, ok := fields["id"]; ok {
+		return line
+	}
+	fields["id"], _ = json.Marshal(id)
+	data, _ := json.Marshal(fields)
+	return data
 }
 
 // Close closes the client connection.
@@ -0,0 +1,389 @@
+package agentbrowser_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	agentbrowser "github.com/cpunion/agent-browser-go"
+	"github.com/playwright-community/playwright-go"
+)
+
+// TestClientPipe tests bridging NDJSON commands and responses
+func TestClientPipe(t *testing.T) {
+	clientConn, daemonConn := net.Pipe()
+	defer clientConn.Close()
+
+	// A daemon that answers each command with its id and pushes an event
+	// after the first
+	var received []map[string]interface{}
+	go func() {
+		defer daemonConn.Close()
+		reader := bufio.NewReader(daemonConn)
+		for {
+			line, err := reader.ReadBytes('\n')
+			if err != nil {
+				return
+			}
+			var cmd map[string]interface{}
+			if err := json.Unmarshal(line, &cmd); err != nil {
+				resp, _ := json.Marshal(agentbrowser.ErrorResponse("", "failed to parse command"))
+				daemonConn.Write(append(resp, '\n'))
+				continue
+			}
+			received = append(received, cmd)
+			resp, _ := json.Marshal(agentbrowser.SuccessResponse(cmd["id"].(string), nil))
+			daemonConn.Write(append(resp, '\n'))
+			if len(received) == 1 {
+				daemonConn.Write([]byte(`{"event":"console","data":{"text":"hi"}}` + "\n"))
+			}
+		}
+	}()
+
+	in := strings.NewReader(`{"action": "url"}
+
+{"id": "mine", "action": "title"}
+not json
+`)
+	var out strings.Builder
+	client := agentbrowser.NewConnClient(clientConn)
+	if err := client.Pipe(in, &out); err != nil {
+		t.Fatalf("Pipe() error = %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	want := []string{
+		`{"id":"1","success":true}`,
+		`{"event":"console","data":{"text":"hi"}}`,
+		`{"id":"mine","success":true}`,
+		`{"id":"","success":false,"error":"failed to parse command"}`,
+	}
+	if strings.Join(lines, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Pipe() output =\n%s\nwant\n%s", out.String(), strings.Join(want, "\n"))
+	}
+	if len(received) != 2 || received[0]["action"] != "url" || received[1]["id"] != "mine" {
+		t.Errorf("daemon received %v, want the url command with an id and the title command as sent", received)
+	}
+}
+
+// TestCommandTimeout tests that the daemon answers commands that run too long
+func TestCommandTimeout(t *testing.T) {
+	m := agentbrowser.NewBrowserManagerForTest(&fakeBackend{
+		waitForTimeout: func(ms int) error {
+			time.Sleep(time.Duration(ms) * time.Millisecond)
+			return nil
+		},
+	})
+	wait := func(ms, limit int) *agentbrowser.WaitCommand {
+		return &agentbrowser.WaitCommand{
+			BaseCommand: agentbrowser.BaseCommand{ID: "1", Action: "wait", CommandTimeout: limit},
+			Timeout:     ms,
+		}
+	}
+
+	start := time.Now()
+	resp := agentbrowser.ExecuteTimeout(wait(200, 50), m, false)
+	if resp.Success || !strings.HasPrefix(resp.Error, "timeout: wait") || resp.ID != "1" || resp.Code != agentbrowser.CodeTimeout {
+		t.Errorf("slow wait = %+v, want a timeout error", resp)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("slow wait answered after %v, want about 50ms", elapsed)
+	}
+
+	if resp := agentbrowser.ExecuteTimeout(wait(10, 1000), m, false); !resp.Success {
+		t.Errorf("quick wait = %+v, want success", resp)
+	}
+	if resp := agentbrowser.ExecuteTimeout(wait(10, 0), m, false); !resp.Success {
+		t.Errorf("wait without limit = %+v, want success", resp)
+	}
+}
+
+// TestCommandTimeout_Abandoned tests that commands after one that timed out
+// wait for it to finish in the background before they start
+func TestCommandTimeout_Abandoned(t *testing.T) {
+	var running, overlapped atomic.Int32
+	m := agentbrowser.NewBrowserManagerForTest(&fakeBackend{
+		waitForTimeout: func(ms int) error {
+			if running.Add(1) > 1 {
+				overlapped.Add(1)
+			}
+			defer running.Add(-1)
+			time.Sleep(time.Duration(ms) * time.Millisecond)
+			return nil
+		},
+	})
+	wait := func(id string, ms, limit int) *agentbrowser.WaitCommand {
+		return &agentbrowser.WaitCommand{
+			BaseCommand: agentbrowser.BaseCommand{ID: id, Action: "wait", CommandTimeout: limit},
+			Timeout:     ms,
+		}
+	}
+
+	if resp := agentbrowser.ExecuteTimeout(wait("1", 300, 50), m, false); resp.Code != agentbrowser.CodeTimeout {
+		t.Fatalf("slow wait = %+v, want a timeout", resp)
+	}
+	resp := agentbrowser.ExecuteTimeout(wait("2", 10, 50), m, false)
+	if resp.Success || resp.Code != agentbrowser.CodeTimeout || !strings.Contains(resp.Error, "still busy with a wait") {
+		t.Errorf("wait during the slow wait = %+v, want a busy timeout", resp)
+	}
+	if resp := agentbrowser.ExecuteTimeout(wait("3", 10, 1000), m, false); !resp.Success {
+		t.Errorf("wait after the slow wait = %+v, want success", resp)
+	}
+	if n := overlapped.Load(); n != 0 {
+		t.Errorf("%d waits ran alongside the slow wait, want none", n)
+	}
+}
+
+// TestCommandTimeout_AbandonedOverlapping tests that a command waits for
+// every command that timed out and still runs, not only the last one
+func TestCommandTimeout_AbandonedOverlapping(t *testing.T) {
+	var running, overlapped atomic.Int32
+	m := agentbrowser.NewBrowserManagerForTest(&fakeBackend{
+		waitForTimeout: func(ms int) error {
+			if running.Add(1) > 1 && ms == 10 {
+				overlapped.Add(1)
+			}
+			defer running.Add(-1)
+			time.Sleep(time.Duration(ms) * time.Millisecond)
+			return nil
+		},
+	})
+	wait := func(id string, ms, limit int) *agentbrowser.WaitCommand {
+		return &agentbrowser.WaitCommand{
+			BaseCommand: agentbrowser.BaseCommand{ID: id, Action: "wait", CommandTimeout: limit},
+			Timeout:     ms,
+		}
+	}
+
+	// Two connections time out, the longer running command first
+	var wg sync.WaitGroup
+	for _, cmd := range []*agentbrowser.WaitCommand{wait("1", 400, 50), wait("2", 150, 100)} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if resp := agentbrowser.ExecuteTimeout(cmd, m, false); resp.Code != agentbrowser.CodeTimeout {
+				t.Errorf("slow wait %s = %+v, want a timeout", cmd.ID, resp)
+			}
+		}()
+	}
+	wg.Wait()
+
+	start := time.Now()
+	if resp := agentbrowser.ExecuteTimeout(wait("3", 10, 1000), m, false); !resp.Success {
+		t.Errorf("wait after the slow waits = %+v, want success", resp)
+	}
+	if n := overlapped.Load(); n != 0 {
+		t.Errorf("the wait ran alongside a slow wait, want it to wait for both")
+	}
+	if elapsed := time.Since(start); elapsed < 200*time.Millisecond {
+		t.Errorf("the wait finished after %v, want it to start once the first slow wait finished", elapsed)
+	}
+}
+
+// TestCommandTimeout_AutoLaunch tests that launching the browser for a
+// command counts against the command's timeout
+func TestCommandTimeout_AutoLaunch(t *testing.T) {
+	var launched atomic.Bool
+	var launches atomic.Int32
+	m := agentbrowser.NewBrowserManagerForTest(&fakeBackend{
+		isLaunched: launched.Load,
+		launch: func(opts agentbrowser.LaunchOptions) error {
+			launches.Add(1)
+			time.Sleep(300 * time.Millisecond)
+			launched.Store(true)
+			return nil
+		},
+		url: func() (string, error) { return "about:blank", nil },
+	})
+	url := func(id string, limit int) *agentbrowser.URLCommand {
+		return &agentbrowser.URLCommand{BaseCommand: agentbrowser.BaseCommand{ID: id, Action: "url", CommandTimeout: limit}}
+	}
+
+	start := time.Now()
+	resp := agentbrowser.ExecuteTimeout(url("1", 50), m, true)
+	if resp.Success || resp.Code != agentbrowser.CodeTimeout || !strings.Contains(resp.Error, "did not launch") {
+		t.Errorf("url during a slow launch = %+v, want a launch timeout", resp)
+	}
+	if elapsed := time.Since(start); elapsed > 250*time.Millisecond {
+		t.Errorf("url answered after %v, want about 50ms", elapsed)
+	}
+
+	if resp := agentbrowser.ExecuteTimeout(url("2", 1000), m, true); !resp.Success {
+		t.Errorf("url after the slow launch = %+v, want success", resp)
+	}
+	if n := launches.Load(); n != 1 {
+		t.Errorf("launched %d times, want once", n)
+	}
+}
+
+// TestClientTimeout tests that the client gives up on a daemon that does not answer
+func TestClientTimeout(t *testing.T) {
+	defer agentbrowser.SetClientTimeoutGrace(50 * time.Millisecond)()
+	clientConn, daemonConn := net.Pipe()
+	defer clientConn.Close()
+	defer daemonConn.Close()
+
+	sent := make(chan map[string]interface{}, 1)
+	go func() {
+		line, _ := bufio.NewReader(daemonConn).ReadBytes('\n')
+		var cmd map[string]interface{}
+		_ = json.Unmarshal(line, &cmd)
+		sent <- cmd
+	}()
+
+	client := agentbrowser.NewConnClient(clientConn)
+	client.SetTimeout(100 * time.Millisecond)
+	_, err := client.Send(&agentbrowser.ClickCommand{
+		BaseCommand: agentbrowser.BaseCommand{ID: "1", Action: "click"},
+		Selector:    "#slow",
+	})
+	if !errors.Is(err, agentbrowser.ErrNoResponse) {
+		t.Errorf("Send() error = %v, want a timeout", err)
+	}
+	if cmd := <-sent; cmd["commandTimeout"] != float64(100) {
+		t.Errorf("sent %v, want commandTimeout 100", cmd)
+	}
+}
+
+// TestClientSendRawContext tests that a raw command gives up, closing the
+// connection, when its context is done before the daemon answers
+func TestClientSendRawContext(t *testing.T) {
+	clientConn, daemonConn := net.Pipe()
+	defer daemonConn.Close()
+	go func() { _, _ = bufio.NewReader(daemonConn).ReadBytes('\n') }()
+
+	client := agentbrowser.NewConnClient(clientConn)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := client.SendRawContext(ctx, []byte(`{"id":"1","action":"url"}`))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("SendRawContext() error = %v, want the context's deadline", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("SendRawContext() returned after %v, want about 50ms", elapsed)
+	}
+	if _, err := clientConn.Write([]byte("{}\n")); err == nil {
+		t.Error("connection still open after the context was done")
+	}
+}
+
+// flakyBackend is a fake backend whose checking and typing fail with err
+// until they have failed failures times, as if their element had not
+// rendered yet. The attempts are counted in *attempts.
+func flakyBackend(failures int, err error, attempts *int) *fakeBackend {
+	attempt := func() error {
+		if *attempts++; *attempts <= failures {
+			return err
+		}
+		return nil
+	}
+	return &fakeBackend{
+		check:       func(selector string) error { return attempt() },
+		typeText:    func(selector, text string, delay int) error { return attempt() },
+		getRefMap:   func() agentbrowser.RefMap { return nil },
+		getSnapshot: noSnapshot,
+	}
+}
+
+// TestCommandRetry tests that element-not-found and timeout failures of
+// repeatable actions are retried
+func TestCommandRetry(t *testing.T) {
+	defer agentbrowser.SetRetryBaseDelay(time.Millisecond)()
+	var logs bytes.Buffer
+	defer agentbrowser.SetLogger(agentbrowser.Logger())
+	agentbrowser.SetLogger(agentbrowser.NewLogger(&logs, slog.LevelDebug))
+	notFound := fmt.Errorf("%w: #late", agentbrowser.ErrElementNotFound)
+
+	tests := []struct {
+		name     string
+		failures int
+		err      error
+		retry    int
+		success  bool
+		attempts int
+	}{
+		{"renders in time", 2, notFound, 3, true, 3},
+		{"never renders", 5, notFound, 2, false, 3},
+		{"wait times out", 1, context.DeadlineExceeded, 1, true, 2},
+		{"no retry", 1, notFound, 0, false, 1},
+		{"not retryable", 1, errors.New("element is disabled"), 3, false, 1},
+		{"message only", 1, errors.New("node not found"), 3, false, 1},
+	}
+	for _, tt := range tests {
+		var attempts int
+		m := agentbrowser.NewBrowserManagerForTest(flakyBackend(tt.failures, tt.err, &attempts))
+		resp := agentbrowser.ExecuteTimeout(&agentbrowser.CheckCommand{
+			BaseCommand: agentbrowser.BaseCommand{ID: "1", Action: "check", Retry: tt.retry},
+			Selector:    "#late",
+		}, m, false)
+		if resp.Success != tt.success || attempts != tt.attempts {
+			t.Errorf("%s: success = %v after %d attempts (%s), want %v after %d",
+				tt.name, resp.Success, attempts, resp.Error, tt.success, tt.attempts)
+		}
+	}
+	if n := strings.Count(logs.String(), "msg=retrying action=check"); n != 5 {
+		t.Errorf("logged %d retries, want 5:\n%s", n, logs.String())
+	}
+}
+
+// TestCommandRetry_NotRepeatable tests that actions a failed attempt may
+// already have had effects of are not retried
+func TestCommandRetry_NotRepeatable(t *testing.T) {
+	defer agentbrowser.SetRetryBaseDelay(time.Millisecond)()
+	var attempts int
+	m := agentbrowser.NewBrowserManagerForTest(flakyBackend(1, context.DeadlineExceeded, &attempts))
+	resp := agentbrowser.ExecuteTimeout(&agentbrowser.TypeCommand{
+		BaseCommand: agentbrowser.BaseCommand{ID: "1", Action: "type", Retry: 3},
+		Selector:    "#late",
+		Text:        "hi",
+	}, m, false)
+	if resp.Success || attempts != 1 {
+		t.Errorf("type: success = %v after %d attempts, want a failure after 1", resp.Success, attempts)
+	}
+}
+
+// TestErrorCode tests that error responses are classified by the kind of
+// failure their error wraps, not by its message
+func TestErrorCode(t *testing.T) {
+	tests := []struct {
+		err  error
+		code string
+	}{
+		{fmt.Errorf("%w: #input", agentbrowser.ErrElementNotFound), agentbrowser.CodeNotFound},
+		{&agentbrowser.RefNotFoundError{Ref: "e1"}, agentbrowser.CodeNotFound},
+		{context.DeadlineExceeded, agentbrowser.CodeTimeout},
+		{fmt.Errorf("click: %w", playwright.ErrTimeout), agentbrowser.CodeTimeout},
+		{agentbrowser.ErrBrowserNotLaunched, agentbrowser.CodeBrowser},
+		{context.Canceled, agentbrowser.CodeBrowser},
+		{playwright.ErrTargetClosed, agentbrowser.CodeBrowser},
+		{errors.New("node not found"), ""},
+		{errors.New("element is disabled"), ""},
+	}
+	for _, tt := range tests {
+		m := agentbrowser.NewBrowserManagerForTest(&fakeBackend{
+			typeText:    func(selector, text string, delay int) error { return tt.err },
+			evaluate:    func(script string) (interface{}, error) { return nil, tt.err },
+			getRefMap:   func() agentbrowser.RefMap { return nil },
+			getSnapshot: noSnapshot,
+		})
+		for _, cmd := range []agentbrowser.Command{
+			&agentbrowser.TypeCommand{BaseCommand: agentbrowser.BaseCommand{ID: "1", Action: "type"}, Selector: "#input", Text: "hi"},
+			&agentbrowser.EvaluateCommand{BaseCommand: agentbrowser.BaseCommand{ID: "2", Action: "evaluate"}, Script: "1"},
+		} {
+			if resp := agentbrowser.ExecuteTimeout(cmd, m, false); resp.Success || resp.Code != tt.code {
+				t.Errorf("%s %v: code = %q (%s), want %q", cmd.GetAction(), tt.err, resp.Code, resp.Error, tt.code)
+			}
+		}
+	}
+}
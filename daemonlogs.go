@@ -0,0 +1,162 @@
+package agentbrowser
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	// logTailBytes bounds how far back the last lines of a log are looked for.
+	logTailBytes = 256 << 10
+
+	// logPollInterval is how often followed logs are checked for new output.
+	logPollInterval = 200 * time.Millisecond
+)
+
+// GetBrowserLogFile returns the file a session's browser writes its stderr
+// to.
+func GetBrowserLogFile(session string) string {
+	return filepath.Join(sessionLogDir(session), browserLogFile)
+}
+
+// followedLog is a log file and how much of it has been written out.
+type followedLog struct {
+	path   string
+	offset int64
+}
+
+// TailLogs writes the last n lines (all when n < 0) of each log file that
+// exists to w and, with follow, what is appended to them afterwards until
+// ctx is done. Logs that are truncated or rotated are read again from the
+// start, and logs created later are followed too. With several files, each
+// run of output is headed by its file's path, as tail does.
+func TailLogs(ctx context.Context, w io.Writer, files []string, n int, follow bool) error {
+	logs := make([]*followedLog, len(files))
+	current := ""
+	write := func(log *followedLog, data []byte) error {
+		if len(files) > 1 && current != log.path {
+			if current != "" {
+				fmt.Fprintln(w)
+			}
+			fmt.Fprintf(w, "==> %s <==\n", log.path)
+			current = log.path
+		}
+		_, err := w.Write(data)
+		return err
+	}
+
+	for i, path := range files {
+		logs[i] = &followedLog{path: path}
+		data, size, err := readTail(path)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		logs[i].offset = size
+		if data = lastLines(data, n); len(data) > 0 {
+			if err := write(logs[i], data); err != nil {
+				return err
+			}
+		}
+	}
+	if !follow {
+		return nil
+	}
+
+	ticker := time.NewTicker(logPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+		for _, log := range logs {
+			data, err := readFrom(log)
+			if err != nil {
+				continue // not created yet, or gone while rotated
+			}
+			if len(data) > 0 {
+				if err := write(log, data); err != nil {
+					return err
+				}
+			}
+		}
+	}
+}
+
+// readTail reads the end of a file, at most logTailBytes, and returns it
+// with the file's size.
+func readTail(path string) ([]byte, int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, 0, err
+	}
+	start := max(info.Size()-logTailBytes, 0)
+	data := make([]byte, info.Size()-start)
+	if _, err := f.ReadAt(data, start); err != nil && err != io.EOF {
+		return nil, 0, err
+	}
+	if start > 0 {
+		// The first line was cut
+		if i := bytes.IndexByte(data, '\n'); i >= 0 {
+			data = data[i+1:]
+		}
+	}
+	return data, info.Size(), nil
+}
+
+// readFrom reads what was appended to a log since it was last read.
+func readFrom(log *followedLog) ([]byte, error) {
+	f, err := os.Open(log.path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if info.Size() < log.offset {
+		log.offset = 0
+	}
+	data := make([]byte, info.Size()-log.offset)
+	n, err := f.ReadAt(data, log.offset)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	log.offset += int64(n)
+	return data[:n], nil
+}
+
+// lastLines returns the last n lines of data, all of them when n < 0.
+func lastLines(data []byte, n int) []byte {
+	if n < 0 {
+		return data
+	}
+	if n == 0 {
+		return nil
+	}
+	end := len(bytes.TrimRight(data, "\n"))
+	for i := end - 1; i >= 0; i-- {
+		if data[i] == '\n' {
+			if n--; n == 0 {
+				return data[i+1:]
+			}
+		}
+	}
+	return data
+}
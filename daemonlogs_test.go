@@ -0,0 +1,111 @@
+package agentbrowser_test
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	agentbrowser "github.com/cpunion/agent-browser-go"
+)
+
+// syncBuffer is a bytes.Buffer safe to read while TailLogs writes it.
+type syncBuffer struct {
+	lock sync.Mutex
+	buf  bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.buf.String()
+}
+
+// TestTailLogs tests printing the last lines of logs
+func TestTailLogs(t *testing.T) {
+	dir := t.TempDir()
+	daemonLog, browserLog := filepath.Join(dir, "daemon.log"), filepath.Join(dir, "browser.log")
+	if err := os.WriteFile(daemonLog, []byte("one\ntwo\nthree\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		files []string
+		n     int
+		want  string
+	}{
+		{"last lines", []string{daemonLog}, 2, "two\nthree\n"},
+		{"all lines", []string{daemonLog}, -1, "one\ntwo\nthree\n"},
+		{"more lines than the log has", []string{daemonLog}, 10, "one\ntwo\nthree\n"},
+		{"missing logs are skipped", []string{daemonLog, browserLog}, 1, "==> " + daemonLog + " <==\nthree\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			if err := agentbrowser.TailLogs(context.Background(), &out, tt.files, tt.n, false); err != nil {
+				t.Fatalf("TailLogs() error = %v", err)
+			}
+			if out.String() != tt.want {
+				t.Errorf("TailLogs() wrote %q, want %q", out.String(), tt.want)
+			}
+		})
+	}
+}
+
+// TestTailLogsFollow tests that appended output, and logs created later,
+// are printed
+func TestTailLogsFollow(t *testing.T) {
+	dir := t.TempDir()
+	daemonLog, browserLog := filepath.Join(dir, "daemon.log"), filepath.Join(dir, "browser.log")
+	if err := os.WriteFile(daemonLog, []byte("started\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var out syncBuffer
+	done := make(chan error)
+	go func() { done <- agentbrowser.TailLogs(ctx, &out, []string{daemonLog, browserLog}, 10, true) }()
+
+	f, err := os.OpenFile(daemonLog, os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString("command failed\n")
+	f.Close()
+	if err := os.WriteFile(browserLog, []byte("chrome not found\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"started\n", "command failed\n", "==> " + browserLog + " <==\nchrome not found\n"}
+	deadline := time.Now().Add(5 * time.Second)
+	for !containsAll(out.String(), want) && time.Now().Before(deadline) {
+		time.Sleep(50 * time.Millisecond)
+	}
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("TailLogs() error = %v", err)
+	}
+	if got := out.String(); !containsAll(got, want) {
+		t.Errorf("TailLogs() wrote %q, want it to contain %q", got, want)
+	}
+}
+
+func containsAll(s string, parts []string) bool {
+	for _, part := range parts {
+		if !strings.Contains(s, part) {
+			return false
+		}
+	}
+	return true
+}
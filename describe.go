@@ -0,0 +1,109 @@
+package agentbrowser
+
+import "encoding/json"
+
+// ElementDescription summarizes a single element for inspection.
+type ElementDescription struct {
+	Tag        string            `json:"tag"`
+	Role       string            `json:"role"`
+	Name       string            `json:"name,omitempty"`
+	States     []string          `json:"states,omitempty"` // checked, disabled, expanded, focused, ...
+	Value      string            `json:"value,omitempty"`
+	Attributes map[string]string `json:"attributes,omitempty"`
+	Box        *BoundingBox      `json:"box,omitempty"`
+	Visible    bool              `json:"visible"`
+	Text       string            `json:"text,omitempty"` // excerpt of the subtree's text
+	Children   int               `json:"children"`
+}
+
+// defaultDescribeTextLength is the default length of the text excerpt.
+const defaultDescribeTextLength = 300
+
+// describeElementScript is a JavaScript function (el, maxText) that returns
+// an ElementDescription for el, or null when el is missing.
+const describeElementScript = `(el, maxText) => {
+	if (!el) return null;
+
+	const tag = el.tagName.toLowerCase();
+	const type = (el.getAttribute('type') || '').toLowerCase();
+	const implicitRoles = {
+		a: el.hasAttribute('href') ? 'link' : 'generic',
+		button: 'button', select: el.multiple ? 'listbox' : 'combobox',
+		textarea: 'textbox', img: 'img', nav: 'navigation', main: 'main',
+		header: 'banner', footer: 'contentinfo', aside: 'complementary',
+		form: 'form', table: 'table', tr: 'row', td: 'cell', th: 'columnheader',
+		ul: 'list', ol: 'list', li: 'listitem', option: 'option',
+		dialog: 'dialog', article: 'article', section: 'region', p: 'paragraph',
+		h1: 'heading', h2: 'heading', h3: 'heading', h4: 'heading', h5: 'heading', h6: 'heading',
+	};
+	const inputRoles = {
+		checkbox: 'checkbox', radio: 'radio', range: 'slider', number: 'spinbutton',
+		search: 'searchbox', button: 'button', submit: 'button', reset: 'button', image: 'button',
+	};
+	const role = el.getAttribute('role') ||
+		(tag === 'input' ? (inputRoles[type] || 'textbox') : (implicitRoles[tag] || 'generic'));
+
+	const clean = (s) => (s || '').replace(/\s+/g, ' ').trim();
+	const labelledBy = (el.getAttribute('aria-labelledby') || '').split(/\s+/)
+		.map((id) => id && document.getElementById(id)).filter(Boolean)
+		.map((n) => n.textContent).join(' ');
+	const labels = el.labels ? Array.from(el.labels).map((l) => l.textContent).join(' ') : '';
+	const name = clean(el.getAttribute('aria-label')) || clean(labelledBy) || clean(labels) ||
+		clean(el.getAttribute('alt')) || clean(el.getAttribute('title')) ||
+		clean(el.getAttribute('placeholder')) ||
+		(['button', 'link', 'heading', 'option', 'tab', 'menuitem', 'cell', 'listitem'].includes(role)
+			? clean(el.innerText || el.textContent).slice(0, 100) : '');
+
+	const states = [];
+	if (el.disabled || el.getAttribute('aria-disabled') === 'true') states.push('disabled');
+	if (el.checked || el.getAttribute('aria-checked') === 'true') states.push('checked');
+	if (el.getAttribute('aria-checked') === 'mixed' || el.indeterminate) states.push('mixed');
+	if (el.selected || el.getAttribute('aria-selected') === 'true') states.push('selected');
+	if (el.getAttribute('aria-expanded') === 'true') states.push('expanded');
+	if (el.getAttribute('aria-expanded') === 'false') states.push('collapsed');
+	if (el.getAttribute('aria-pressed') === 'true') states.push('pressed');
+	if (el.required || el.getAttribute('aria-required') === 'true') states.push('required');
+	if (el.readOnly || el.getAttribute('aria-readonly') === 'true') states.push('readonly');
+	if (el.getAttribute('aria-invalid') === 'true' || (el.validity && !el.validity.valid)) states.push('invalid');
+	if (document.activeElement === el) states.push('focused');
+	if (el.isContentEditable) states.push('editable');
+
+	let value = '';
+	if (tag === 'select') {
+		value = Array.from(el.selectedOptions).map((o) => o.value).join(', ');
+	} else if ('value' in el && tag !== 'button' && tag !== 'li' && type !== 'password') {
+		value = String(el.value);
+	}
+
+	const attributes = {};
+	for (const attr of el.attributes) {
+		attributes[attr.name] = attr.value.length > 200 ? attr.value.slice(0, 200) + '...' : attr.value;
+	}
+
+	const rect = el.getBoundingClientRect();
+	const style = window.getComputedStyle(el);
+	const visible = rect.width > 0 && rect.height > 0 &&
+		style.visibility !== 'hidden' && style.display !== 'none' && style.opacity !== '0';
+
+	let text = clean(el.innerText || el.textContent);
+	if (text.length > maxText) text = text.slice(0, maxText) + '...';
+
+	return {
+		tag, role, name, states, value, attributes, visible, text,
+		box: { x: rect.x, y: rect.y, width: rect.width, height: rect.height },
+		children: el.children.length,
+	};
+}`
+
+// decodeElementDescription converts an evaluation result into an ElementDescription.
+func decodeElementDescription(result interface{}) (*ElementDescription, error) {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return nil, err
+	}
+	var desc ElementDescription
+	if err := json.Unmarshal(data, &desc); err != nil {
+		return nil, err
+	}
+	return &desc, nil
+}
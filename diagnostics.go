@@ -0,0 +1,384 @@
+package agentbrowser
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// DoctorCheck is the outcome of one environment check.
+type DoctorCheck struct {
+	Name   string `json:"name"`
+	Status string `json:"status"` // ok, warn or fail
+	Detail string `json:"detail"`
+	Fix    string `json:"fix,omitempty"` // how to resolve a warning or failure
+}
+
+// Doctor check status values.
+const (
+	DoctorOK   = "ok"
+	DoctorWarn = "warn"
+	DoctorFail = "fail"
+)
+
+// Files in a session's log directory.
+const (
+	browserLogFile  = "browser.log"    // browser stderr
+	commandsLogFile = "commands.jsonl" // one line per command the daemon ran
+	crashDumpsDir   = "crashpad"
+)
+
+// commandsLogMax is the size at which the command log is rotated.
+const commandsLogMax = 1 << 20
+
+// Doctor checks that the backends can run: a Chrome for chromedp, the
+// Playwright driver and its Chromium, a readable config file and a writable
+// session directory.
+func Doctor() []DoctorCheck {
+	checks := []DoctorCheck{
+		{Name: "platform", Status: DoctorOK, Detail: fmt.Sprintf("%s/%s, %s", runtime.GOOS, runtime.GOARCH, runtime.Version())},
+		checkChrome(),
+	}
+	checks = append(checks, checkPlaywright()...)
+	checks = append(checks, checkConfig(), checkSessionDir())
+	return checks
+}
+
+// chromeLocations are the executables chromedp looks for, in its order.
+func chromeLocations() []string {
+	switch runtime.GOOS {
+	case "darwin":
+		return []string{
+			"/Applications/Chromium.app/Contents/MacOS/Chromium",
+			"/Applications/Google Chrome.app/Contents/MacOS/Google Chrome",
+		}
+	case "windows":
+		return []string{
+			"chrome",
+			"chrome.exe",
+			`C:\Program Files (x86)\Google\Chrome\Application\chrome.exe`,
+			`C:\Program Files\Google\Chrome\Application\chrome.exe`,
+			filepath.Join(os.Getenv("USERPROFILE"), `AppData\Local\Google\Chrome\Application\chrome.exe`),
+			filepath.Join(os.Getenv("USERPROFILE"), `AppData\Local\Chromium\Application\chrome.exe`),
+		}
+	default:
+		return []string{
+			"headless_shell", "headless-shell", "chromium", "chromium-browser",
+			"google-chrome", "google-chrome-stable", "google-chrome-beta", "google-chrome-unstable",
+			"/usr/bin/google-chrome", "/usr/local/bin/chrome", "/snap/bin/chromium", "chrome",
+		}
+	}
+}
+
+// checkChrome finds the Chrome the chromedp backend would launch.
+func checkChrome() DoctorCheck {
+	check := DoctorCheck{Name: "chrome"}
+	for _, location := range chromeLocations() {
+		path, err := exec.LookPath(location)
+		if err != nil {
+			continue
+		}
+		check.Status = DoctorOK
+		check.Detail = path
+		// Chrome on Windows prints no version
+		if out, err := exec.Command(path, "--version").Output(); err == nil && len(bytes.TrimSpace(out)) > 0 {
+			check.Detail += " (" + string(bytes.TrimSpace(out)) + ")"
+		}
+		return check
+	}
+	check.Status = DoctorFail
+	check.Detail = "no Chrome or Chromium found, the chromedp backend cannot launch"
+	check.Fix = "install Google Chrome or Chromium, or use --backend playwright"
+	return check
+}
+
+// checkPlaywright checks the Playwright driver and its Chromium download.
+func checkPlaywright() []DoctorCheck {
+	driverCheck := DoctorCheck{Name: "playwright-driver"}
+	driver, err := playwright.NewDriver(&playwright.RunOptions{Stdout: io.Discard, Stderr: io.Discard})
+	if err != nil {
+		driverCheck.Status = DoctorFail
+		driverCheck.Detail = err.Error()
+		return []DoctorCheck{driverCheck}
+	}
+	out, err := driver.Command("--version").Output()
+	version := strings.TrimSpace(string(out))
+	switch {
+	case err != nil:
+		driverCheck.Status = DoctorFail
+		driverCheck.Detail = fmt.Sprintf("driver v%s not installed, the playwright backend cannot start", driver.Version)
+		driverCheck.Fix = "agent-browser-go install --backend playwright"
+	case !strings.Contains(version, driver.Version):
+		driverCheck.Status = DoctorFail
+		driverCheck.Detail = fmt.Sprintf("driver is %s, need v%s", version, driver.Version)
+		driverCheck.Fix = "agent-browser-go install --backend playwright"
+	default:
+		driverCheck.Status = DoctorOK
+		driverCheck.Detail = version
+	}
+
+	browserCheck := DoctorCheck{Name: "playwright-chromium"}
+	dir := os.Getenv("PLAYWRIGHT_BROWSERS_PATH")
+	if dir == "" {
+		cache, _ := os.UserCacheDir()
+		dir = filepath.Join(cache, "ms-playwright")
+	}
+	found, _ := filepath.Glob(filepath.Join(dir, "chromium*"))
+	if len(found) > 0 {
+		browserCheck.Status = DoctorOK
+		browserCheck.Detail = strings.Join(found, ", ")
+	} else {
+		browserCheck.Status = DoctorWarn
+		browserCheck.Detail = "no Chromium downloaded in " + dir
+		browserCheck.Fix = "agent-browser-go install --backend playwright, or set AGENT_BROWSER_USE_CHROME=1 to use the system Chrome"
+	}
+	return []DoctorCheck{driverCheck, browserCheck}
+}
+
+// checkConfig checks that the config files, if any, parse.
+func checkConfig() DoctorCheck {
+	check := DoctorCheck{Name: "config", Status: DoctorOK}
+	var paths []string
+	if path := ConfigPath(); fileExists(path) {
+		paths = append(paths, path)
+	}
+	if path := ProjectConfigPath(); path != "" {
+		paths = append(paths, path)
+	}
+	if len(paths) == 0 {
+		check.Detail = "no config file at " + ConfigPath()
+		return check
+	}
+	cfg, err := LoadConfig()
+	if err != nil {
+		check.Status = DoctorFail
+		check.Detail = err.Error()
+		check.Fix = "fix or remove " + strings.Join(paths, " or ")
+		return check
+	}
+	check.Detail = fmt.Sprintf("%s (%d login recipes, %d session entries)", strings.Join(paths, ", "), len(cfg.Logins), len(cfg.Sessions))
+	return check
+}
+
+// checkSessionDir checks that session sockets and state can be written.
+func checkSessionDir() DoctorCheck {
+	check := DoctorCheck{Name: "session-dir"}
+	dir := filepath.Join(os.TempDir(), "agent-browser-go")
+	err := os.MkdirAll(dir, 0755)
+	var f *os.File
+	if err == nil {
+		f, err = os.CreateTemp(dir, "doctor-*")
+	}
+	if err == nil {
+		f.Close()
+		os.Remove(f.Name())
+	}
+	if err != nil {
+		check.Status = DoctorFail
+		check.Detail = err.Error()
+		check.Fix = "make " + dir + " writable, or set TMPDIR"
+		return check
+	}
+	check.Status = DoctorOK
+	check.Detail = dir
+	if sessions, _ := ListRunningSessions(); len(sessions) > 0 {
+		check.Detail += fmt.Sprintf(" (running: %s)", strings.Join(sessions, ", "))
+	}
+	return check
+}
+
+// GetSessionLogDir returns the directory for a session's browser output,
+// crash dumps and command log.
+func GetSessionLogDir(session string) string {
+	dir := sessionLogDir(session)
+	_ = os.MkdirAll(dir, 0755)
+	return dir
+}
+
+// openBrowserLog opens the browser stderr log in dir for appending and
+// returns the crash dump directory to pass to the browser.
+func openBrowserLog(dir string) (*os.File, string, error) {
+	crashDir := filepath.Join(dir, crashDumpsDir)
+	if err := os.MkdirAll(crashDir, 0755); err != nil {
+		return nil, "", err
+	}
+	f, err := os.OpenFile(filepath.Join(dir, browserLogFile), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, "", err
+	}
+	fmt.Fprintf(f, "--- browser launched %s\n", time.Now().Format(time.RFC3339))
+	return f, crashDir, nil
+}
+
+// commandRecord is one line of the command log. Only the action is kept:
+// arguments may hold typed passwords.
+type commandRecord struct {
+	Time     time.Time `json:"time"`
+	Action   string    `json:"action"`
+	Success  bool      `json:"success"`
+	Error    string    `json:"error,omitempty"`
+	Duration int64     `json:"durationMs"`
+}
+
+// commandLog appends the commands a daemon runs to its session log.
+type commandLog struct {
+	mu   sync.Mutex
+	path string
+	last commandRecord // the latest command, for session info
+}
+
+func newCommandLog(dir string) *commandLog {
+	path := filepath.Join(dir, commandsLogFile)
+	if info, err := os.Stat(path); err == nil && info.Size() > commandsLogMax {
+		_ = os.Rename(path, path+".1")
+	}
+	return &commandLog{path: path}
+}
+
+// record appends one command. Logging is best effort.
+func (l *commandLog) record(action string, start time.Time, resp Response) {
+	line, err := json.Marshal(commandRecord{
+		Time:     start,
+		Action:   action,
+		Success:  resp.Success,
+		Error:    resp.Error,
+		Duration: time.Since(start).Milliseconds(),
+	})
+	if err != nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.last = commandRecord{Time: start, Action: action}
+	f, err := os.OpenFile(l.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	_, _ = f.Write(append(line, '\n'))
+}
+
+// latest returns the action and start of the latest command, if any.
+func (l *commandLog) latest() (string, time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.last.Action, l.last.Time
+}
+
+// CollectDiagnostics writes a zip archive for bug reports to w: doctor
+// results, the session's settings, the daemon log, browser output and
+// crash dumps, recent commands and the config with credentials redacted.
+func CollectDiagnostics(session string, w io.Writer) error {
+	zw := zip.NewWriter(w)
+	addJSON := func(name string, v interface{}) error {
+		data, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return err
+		}
+		f, err := zw.Create(name)
+		if err != nil {
+			return err
+		}
+		_, err = f.Write(data)
+		return err
+	}
+	addFile := func(name, path string) error {
+		src, err := os.Open(path)
+		if os.IsNotExist(err) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		defer src.Close()
+		dst, err := zw.Create(name)
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(dst, src)
+		return err
+	}
+
+	if err := addJSON("doctor.json", Doctor()); err != nil {
+		return err
+	}
+	if err := addJSON("session.json", map[string]interface{}{
+		"session":     session,
+		"running":     IsDaemonRunning(session),
+		"backend":     GetSessionBackend(session),
+		"headed":      GetSessionHeaded(session),
+		"stealth":     GetSessionStealth(session),
+		"userAgent":   GetSessionUserAgent(session),
+		"userDataDir": GetSessionUserDataDir(session),
+		"collected":   time.Now().Format(time.RFC3339),
+	}); err != nil {
+		return err
+	}
+	if err := addJSON("config.json", redactedConfig()); err != nil {
+		return err
+	}
+	if err := addFile("daemon.log", GetLogFile(session)); err != nil {
+		return err
+	}
+
+	logDir := GetSessionLogDir(session)
+	err := filepath.WalkDir(logDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(logDir, path)
+		if err != nil {
+			return err
+		}
+		return addFile(filepath.ToSlash(filepath.Join("logs", rel)), path)
+	})
+	if err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+// redactedConfig returns the config with credentials and header values
+// masked, or the error reading it. References to environment variables are
+// kept, since they hold no secret.
+func redactedConfig() interface{} {
+	cfg, err := LoadConfig()
+	if err != nil {
+		return map[string]string{"error": err.Error()}
+	}
+	redact := func(s string) string {
+		if s == "" || strings.HasPrefix(s, "$") {
+			return s
+		}
+		return "[redacted]"
+	}
+	for site, recipe := range cfg.Logins {
+		recipe.Username = redact(recipe.Username)
+		recipe.Password = redact(recipe.Password)
+		recipe.TOTPSecret = redact(recipe.TOTPSecret)
+		cfg.Logins[site] = recipe
+	}
+	for name, sc := range cfg.Sessions {
+		for header, value := range sc.Headers {
+			sc.Headers[header] = redact(value) // may be an Authorization
+		}
+		if u, err := url.Parse(sc.Proxy); err == nil && u.User != nil {
+			sc.Proxy = u.Redacted()
+		}
+		cfg.Sessions[name] = sc
+	}
+	return cfg
+}
@@ -0,0 +1,79 @@
+package agentbrowser_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	agentbrowser "github.com/cpunion/agent-browser-go"
+)
+
+// TestCollectDiagnostics tests the bug report bundle and its redaction
+func TestCollectDiagnostics(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+	config := filepath.Join(t.TempDir(), "config.json")
+	t.Setenv("AGENT_BROWSER_CONFIG", config)
+	err := os.WriteFile(config, []byte(`{"logins": {"example": {
+		"url": "https://example.com/login",
+		"username": "alice@example.com",
+		"password": "hunter2",
+		"totpSecret": "$EXAMPLE_TOTP"
+	}}, "sessions": {"*": {"headers": {"Authorization": "Bearer s3cret"}}}}`), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	session := "diag-test"
+	logDir := agentbrowser.GetSessionLogDir(session)
+	if err := os.WriteFile(filepath.Join(logDir, "browser.log"), []byte("Received signal 11 SEGV_MAPERR\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(logDir, "crashpad"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(logDir, "crashpad", "1.dmp"), []byte("MDMP"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(agentbrowser.GetLogFile(session), []byte("daemon started\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := agentbrowser.CollectDiagnostics(session, &buf); err != nil {
+		t.Fatalf("CollectDiagnostics() error = %v", err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("invalid zip: %v", err)
+	}
+	files := map[string]string{}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, _ := io.ReadAll(rc)
+		rc.Close()
+		files[f.Name] = string(data)
+	}
+
+	for _, name := range []string{"doctor.json", "session.json", "config.json", "daemon.log", "logs/browser.log", "logs/crashpad/1.dmp"} {
+		if _, ok := files[name]; !ok {
+			t.Errorf("bundle is missing %s, has %v", name, zr.File)
+		}
+	}
+	if !strings.Contains(files["logs/browser.log"], "SEGV_MAPERR") {
+		t.Errorf("browser.log = %q", files["logs/browser.log"])
+	}
+	cfg := files["config.json"]
+	if strings.Contains(cfg, "hunter2") || strings.Contains(cfg, "alice@example.com") || strings.Contains(cfg, "s3cret") {
+		t.Errorf("config.json leaks credentials: %s", cfg)
+	}
+	if !strings.Contains(cfg, "$EXAMPLE_TOTP") || !strings.Contains(cfg, "https://example.com/login") {
+		t.Errorf("config.json lost non-secret fields: %s", cfg)
+	}
+}
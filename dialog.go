@@ -0,0 +1,46 @@
+package agentbrowser
+
+import (
+	"fmt"
+	"sync"
+)
+
+// dialogPolicy is how backends answer JavaScript dialogs, set with
+// SetDialogResponse. Backends embed it and read it from their dialog
+// listeners.
+type dialogPolicy struct {
+	dialogLock       sync.Mutex
+	dialogResponse   string // accept, dismiss, or empty for the backend's default
+	dialogPromptText string // entered in prompts that are accepted
+}
+
+func (d *dialogPolicy) setDialogResponse(response, promptText string) {
+	d.dialogLock.Lock()
+	defer d.dialogLock.Unlock()
+
+	d.dialogResponse, d.dialogPromptText = response, promptText
+}
+
+// dialogAnswer returns how the next dialog is answered.
+func (d *dialogPolicy) dialogAnswer() (response, promptText string) {
+	d.dialogLock.Lock()
+	defer d.dialogLock.Unlock()
+
+	return d.dialogResponse, d.dialogPromptText
+}
+
+// SetDialogResponse chooses how JavaScript alerts, confirms and prompts
+// opened from now on are answered: "accept" (entering promptText in
+// prompts), "dismiss", or "" for the backend's default, which is what a
+// new session starts with. By default Playwright dismisses dialogs, as it
+// does when nothing listens for them, and chromedp leaves them open. Dialogs
+// are reported in action summaries either way. The choice is kept when the
+// browser relaunches.
+func (m *BrowserManager) SetDialogResponse(response, promptText string) error {
+	switch response {
+	case "", "accept", "dismiss":
+	default:
+		return fmt.Errorf("invalid dialog response %q (expected accept or dismiss)", response)
+	}
+	return m.backend.SetDialogResponse(response, promptText)
+}
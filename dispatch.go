@@ -0,0 +1,53 @@
+package agentbrowser
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// dispatchEventScript is a JavaScript function (el, type, init) that fires
+// a DOM event of type on el. The event class follows the type, as in
+// Playwright: MouseEvent for click, KeyboardEvent for keydown, and so on;
+// other types are CustomEvents, so init.detail reaches custom listeners.
+// Events bubble, are cancelable and composed unless init says otherwise.
+// It returns null when el is missing, else whether no listener canceled it.
+const dispatchEventScript = `(el, type, init) => {
+	if (!el) return null;
+	const classes = [
+		[/^(pointer|gotpointercapture|lostpointercapture)/, 'PointerEvent'],
+		[/^(click|dblclick|auxclick|contextmenu|mouse)/, 'MouseEvent'],
+		[/^key/, 'KeyboardEvent'],
+		[/^(focus|blur|focusin|focusout)$/, 'FocusEvent'],
+		[/^(input|beforeinput)$/, 'InputEvent'],
+		[/^composition/, 'CompositionEvent'],
+		[/^drag|^drop$/, 'DragEvent'],
+		[/^wheel$/, 'WheelEvent'],
+		[/^touch/, 'TouchEvent'],
+	];
+	const match = classes.find(([re, name]) => re.test(type) && typeof window[name] === 'function');
+	const EventClass = match ? window[match[1]] : CustomEvent;
+	const event = new EventClass(type, { bubbles: true, cancelable: true, composed: true, ...init });
+	return el.dispatchEvent(event);
+}`
+
+// DispatchEvent fires a DOM event on an element, such as input or change
+// after a value is set directly, or a custom event with init.detail. init
+// holds EventInit fields like bubbles, detail, key or clientX.
+func (m *BrowserManager) DispatchEvent(selector, event string, init map[string]interface{}) error {
+	event = strings.TrimSpace(event)
+	if event == "" {
+		return fmt.Errorf("event type is required")
+	}
+	if init == nil {
+		init = map[string]interface{}{}
+	}
+	if _, err := json.Marshal(init); err != nil {
+		return fmt.Errorf("invalid event init: %w", err)
+	}
+	selector, err := m.resolveRef(selector)
+	if err != nil {
+		return err
+	}
+	return m.backend.DispatchEvent(selector, event, init)
+}
@@ -0,0 +1,33 @@
+package agentbrowser_test
+
+import (
+	"reflect"
+	"testing"
+
+	agentbrowser "github.com/cpunion/agent-browser-go"
+)
+
+// TestDispatchEvent tests event type checks and the default event init
+func TestDispatchEvent(t *testing.T) {
+	var event string
+	var init map[string]interface{}
+	m := agentbrowser.NewBrowserManagerForTest(&fakeBackend{
+		dispatchEvent: func(selector, e string, i map[string]interface{}) error {
+			event, init = e, i
+			return nil
+		},
+	})
+
+	if err := m.DispatchEvent("#email", "input", nil); err != nil {
+		t.Fatalf("DispatchEvent() error = %v", err)
+	}
+	if event != "input" || !reflect.DeepEqual(init, map[string]interface{}{}) {
+		t.Errorf("backend got %q with %v, want input with an empty init", event, init)
+	}
+	if err := m.DispatchEvent("#email", " ", nil); err == nil {
+		t.Error("DispatchEvent() without an event type expected an error")
+	}
+	if err := m.DispatchEvent("#email", "x", map[string]interface{}{"f": func() {}}); err == nil {
+		t.Error("DispatchEvent() with an unserializable init expected an error")
+	}
+}
@@ -0,0 +1,72 @@
+package agentbrowser
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+// Event types pushed to subscribed clients.
+const (
+	EventWatch      = "watch"      // summarized DOM changes, see WatchChange
+	EventScreencast = "screencast" // a screencast frame, see ScreencastFrame
+	EventConsole    = "console"    // a console message, see ConsoleMessage
+)
+
+// Event is pushed by the daemon to connections that subscribed to it.
+// Events are sent on the same newline-delimited JSON stream as responses
+// and are told apart by their "event" field.
+type Event struct {
+	Event     string          `json:"event"`
+	Timestamp int64           `json:"timestamp"`
+	Data      json.RawMessage `json:"data,omitempty"`
+}
+
+// NewEvent creates an event with the current time.
+func NewEvent(kind string, data interface{}) Event {
+	ev := Event{Event: kind, Timestamp: time.Now().UnixMilli()}
+	if data != nil {
+		if raw, err := json.Marshal(data); err == nil {
+			ev.Data = raw
+		}
+	}
+	return ev
+}
+
+// eventEmitter delivers backend events to a single handler. Backends embed
+// it; the daemon installs the handler through BrowserManager.SetEventHandler.
+type eventEmitter struct {
+	handlerLock sync.RWMutex
+	handler     func(Event)
+}
+
+// SetEventHandler sets the function that receives events. Nil disables delivery.
+func (e *eventEmitter) SetEventHandler(handler func(Event)) {
+	e.handlerLock.Lock()
+	defer e.handlerLock.Unlock()
+	e.handler = handler
+}
+
+// emit sends an event to the handler, if any.
+func (e *eventEmitter) emit(kind string, data interface{}) {
+	e.handlerLock.RLock()
+	handler := e.handler
+	e.handlerLock.RUnlock()
+
+	if handler != nil {
+		handler(NewEvent(kind, data))
+	}
+}
+
+// eventFilter reports whether an event type is selected by a subscription.
+// An empty subscription selects every event.
+func eventFilter(events []string) func(string) bool {
+	if len(events) == 0 {
+		return func(string) bool { return true }
+	}
+	set := make(map[string]bool, len(events))
+	for _, e := range events {
+		set[e] = true
+	}
+	return func(kind string) bool { return set[kind] }
+}
@@ -0,0 +1,52 @@
+package agentbrowser_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	agentbrowser "github.com/cpunion/agent-browser-go"
+)
+
+// TestNewEvent tests event construction and payload encoding
+func TestNewEvent(t *testing.T) {
+	ev := agentbrowser.NewEvent(agentbrowser.EventWatch, agentbrowser.WatchChange{
+		URL:   "https://example.com",
+		Added: map[string]int{"listitem": 2},
+	})
+	if ev.Event != "watch" {
+		t.Errorf("expected event watch, got %s", ev.Event)
+	}
+	if ev.Timestamp == 0 {
+		t.Error("expected timestamp to be set")
+	}
+
+	var change agentbrowser.WatchChange
+	if err := json.Unmarshal(ev.Data, &change); err != nil {
+		t.Fatalf("unmarshal data: %v", err)
+	}
+	if change.URL != "https://example.com" || change.Added["listitem"] != 2 {
+		t.Errorf("unexpected data: %+v", change)
+	}
+}
+
+// TestEventFilter tests subscription filtering by event type
+func TestEventFilter(t *testing.T) {
+	tests := []struct {
+		name   string
+		events []string
+		kind   string
+		want   bool
+	}{
+		{"all when empty", nil, "watch", true},
+		{"listed type", []string{"watch"}, "watch", true},
+		{"unlisted type", []string{"console"}, "watch", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := agentbrowser.EventFilter(tt.events)(tt.kind); got != tt.want {
+				t.Errorf("EventFilter(%v)(%q) = %v, want %v", tt.events, tt.kind, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package agentbrowser
+
+import (
+	"bufio"
+	"net"
+	"time"
+)
+
+// Exported for tests in package agentbrowser_test.
+var (
+	TargetQuery     = targetQuery
+	RankRefs        = rankRefs
+	SnapshotExcerpt = snapshotExcerpt
+	SummarizeAction = summarizeAction
+	EventFilter     = eventFilter
+	StealthScript   = stealthScript
+	StealthUA       = stealthUserAgent
+	TOTPCode        = totpCode
+	ParsePSRSS      = parsePSRSS
+	KeyEvents       = keyEvents
+	KeyEventParams  = keyEventParams
+	NormalizeChord  = normalizeChord
+	ExceptionError  = exceptionError
+	LocatorSelector = playwrightLocatorSelector
+	ExecuteTimeout  = executeWithTimeout
+	CertClients     = clientCertificateClients
+	BypassesProxy   = bypassesProxy
+)
+
+// LocatorMatches reports whether text matches the locator's text.
+func LocatorMatches(loc Locator, text string) bool {
+	return loc.matches(text)
+}
+
+// KeepsRefs reports whether a snapshot taken with opts leaves the backend's
+// ref map as it is.
+func KeepsRefs(opts SnapshotOptions) bool {
+	return opts.keepRefs
+}
+
+// FrameInfo is a frame's name and URL, as matched by FrameRef.
+type FrameInfo = frameInfo
+
+// MatchFrame returns the index of the frame ref names, or -1.
+func MatchFrame(frames []FrameInfo, ref FrameRef) int {
+	return matchFrame(frames, ref)
+}
+
+// UserAgentOverride returns the platform and client-hint platform derived for ua.
+func UserAgentOverride(ua string) (platform, chPlatform string, mobile bool) {
+	o := userAgentOverrideFor(ua)
+	if o.UserAgentMetadata == nil {
+		return o.Platform, "", false
+	}
+	return o.Platform, o.UserAgentMetadata.Platform, o.UserAgentMetadata.Mobile
+}
+
+// HTTPCredentialsMatch reports whether c may answer a challenge from origin.
+func HTTPCredentialsMatch(c *HTTPCredentials, origin string) bool {
+	return c.matches(origin)
+}
+
+// RequestTracker is the request log backends embed, with its event hooks
+// exported.
+type RequestTracker struct{ requestTracker }
+
+func (t *RequestTracker) Started(key any, req TrackedRequest) { t.requestStarted(key, req) }
+func (t *RequestTracker) Responded(key any, status int)       { t.requestResponded(key, status) }
+func (t *RequestTracker) Finished(key any, failure string)    { t.requestFinished(key, failure) }
+
+// ActivityTracker is the event log backends embed, with its event hooks
+// exported.
+type ActivityTracker struct{ activityTracker }
+
+func (t *ActivityTracker) Console(msg ConsoleMessage) { t.recordConsole(msg) }
+func (t *ActivityTracker) PageError(err PageError)    { t.recordPageError(err) }
+
+// PauseGate holds a paused session's commands back, with its methods
+// exported.
+type PauseGate struct{ pauseGate }
+
+func (g *PauseGate) Pause() error                   { return g.pause() }
+func (g *PauseGate) Resume() (time.Duration, error) { return g.resume() }
+func (g *PauseGate) Wait(stop <-chan struct{}) bool { return g.wait(stop) }
+
+// NewBrowserManagerForTest wraps an arbitrary backend.
+func NewBrowserManagerForTest(backend BrowserBackend) *BrowserManager {
+	return &BrowserManager{backend: backend}
+}
+
+// NewConnClient returns a client talking to a daemon over conn.
+func NewConnClient(conn net.Conn) *Client {
+	return &Client{conn: conn, reader: bufio.NewReader(conn)}
+}
+
+// SetClientTimeoutGrace sets how much longer than a command's timeout
+// clients wait for the answer, and returns a function restoring it.
+func SetClientTimeoutGrace(d time.Duration) func() {
+	old := clientTimeoutGrace
+	clientTimeoutGrace = d
+	return func() { clientTimeoutGrace = old }
+}
+
+// SetRetryBaseDelay sets the wait before the first retry of a command, and
+// returns a function restoring it.
+func SetRetryBaseDelay(d time.Duration) func() {
+	old := retryBaseDelay
+	retryBaseDelay = d
+	return func() { retryBaseDelay = old }
+}
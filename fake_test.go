@@ -0,0 +1,354 @@
+package agentbrowser_test
+
+import (
+	"errors"
+
+	agentbrowser "github.com/cpunion/agent-browser-go"
+)
+
+// fakeBackend is a BrowserBackend whose methods run the funcs a test sets.
+// Calling a method whose func is not set panics, as does any other method
+// of the embedded nil interface.
+type fakeBackend struct {
+	agentbrowser.BrowserBackend
+	launch             func(opts agentbrowser.LaunchOptions) error
+	isLaunched         func() bool
+	navigate           func(url string, waitUntil string) (string, string, error)
+	navigateRequest    func(req agentbrowser.NavigationRequest, waitUntil string) (string, string, error)
+	history            func() (*agentbrowser.NavigationHistory, error)
+	goToHistoryEntry   func(index int) error
+	click              func(selector string) error
+	fill               func(selector, value string) error
+	typeText           func(selector, text string, delay int) error
+	press              func(key string, selector string) error
+	focus              func(selector string) error
+	check              func(selector string) error
+	uncheck            func(selector string) error
+	selectOptions      func(selector string, values []string, by string) error
+	dispatchEvent      func(selector, event string, init map[string]interface{}) error
+	upload             func(selector string, files []string) error
+	inputMouse         func(ev agentbrowser.MouseEvent) error
+	inputKeyboard      func(ev agentbrowser.KeyEvent) error
+	count              func(selector string) (int, error)
+	getBoundingBox     func(selector string) (*agentbrowser.BoundingBox, error)
+	describe           func(selector string, maxText int) (*agentbrowser.ElementDescription, error)
+	readableText       func(selector string, plain bool) (string, error)
+	highlight          func(selector string, duration int) error
+	locate             func(loc agentbrowser.Locator) (string, error)
+	url                func() (string, error)
+	title              func() (string, error)
+	setViewport        func(width, height int) error
+	pdf                func(opts agentbrowser.PDFOptions) ([]byte, error)
+	stopTracing        func(path string) error
+	evaluate           func(script string) (interface{}, error)
+	wait               func(selector string, timeout int, state string) error
+	waitForTimeout     func(ms int) error
+	waitForLoadState   func(state string, timeout int) error
+	scrollIntoView     func(selector string) error
+	newTab             func(url string) (int, error)
+	switchTab          func(index int) error
+	closeTab           func(index int) error
+	listTabs           func() ([]agentbrowser.TabInfo, error)
+	bringToFront       func() error
+	switchFrame        func(ref agentbrowser.FrameRef) error
+	getSnapshot        func(opts agentbrowser.SnapshotOptions) (*agentbrowser.EnhancedSnapshot, error)
+	getRefMap          func() agentbrowser.RefMap
+	setUserAgent       func(ua string) error
+	setGeolocation     func(geo *agentbrowser.Geolocation) error
+	setLocale          func(locale string) error
+	emulateMedia       func(media agentbrowser.MediaEmulation) error
+	setDialogResponse  func(response, promptText string) error
+	grantPermissions   func(permissions []string, origin string) error
+	setExtraHeaders    func(headers map[string]string) error
+	setHTTPCredentials func(creds *agentbrowser.HTTPCredentials) error
+	addInitScript      func(script string) error
+	addScriptTag       func(url, content string) error
+	addStyleTag        func(url, content string) error
+	getCookies         func() ([]agentbrowser.Cookie, error)
+	setCookies         func(cookies []agentbrowser.Cookie) error
+	clearCookies       func() error
+	clearCache         func() error
+	clearStorage       func(origin string) error
+	performanceMetrics func() (map[string]float64, error)
+	browserProcesses   func() ([]agentbrowser.BrowserProcess, error)
+	consoleMessages    func() []agentbrowser.ConsoleMessage
+	pageErrors         func() []agentbrowser.PageError
+	requests           func() []agentbrowser.TrackedRequest
+	setEventHandler    func(handler func(agentbrowser.Event))
+	startScreencast    func(opts agentbrowser.ScreencastOptions, onFrame func(agentbrowser.ScreencastFrame)) error
+	stopScreencast     func() error
+}
+
+func (f *fakeBackend) Launch(opts agentbrowser.LaunchOptions) error {
+	return f.launch(opts)
+}
+
+func (f *fakeBackend) IsLaunched() bool {
+	return f.isLaunched()
+}
+
+func (f *fakeBackend) Navigate(url string, waitUntil string) (string, string, error) {
+	return f.navigate(url, waitUntil)
+}
+
+func (f *fakeBackend) NavigateRequest(req agentbrowser.NavigationRequest, waitUntil string) (string, string, error) {
+	return f.navigateRequest(req, waitUntil)
+}
+
+func (f *fakeBackend) History() (*agentbrowser.NavigationHistory, error) {
+	return f.history()
+}
+
+func (f *fakeBackend) GoToHistoryEntry(index int) error {
+	return f.goToHistoryEntry(index)
+}
+
+func (f *fakeBackend) Click(selector string) error {
+	return f.click(selector)
+}
+
+func (f *fakeBackend) Fill(selector, value string) error {
+	return f.fill(selector, value)
+}
+
+func (f *fakeBackend) Type(selector, text string, delay int) error {
+	return f.typeText(selector, text, delay)
+}
+
+func (f *fakeBackend) Press(key string, selector string) error {
+	return f.press(key, selector)
+}
+
+func (f *fakeBackend) Focus(selector string) error {
+	return f.focus(selector)
+}
+
+func (f *fakeBackend) Check(selector string) error {
+	return f.check(selector)
+}
+
+func (f *fakeBackend) Uncheck(selector string) error {
+	return f.uncheck(selector)
+}
+
+func (f *fakeBackend) Select(selector string, values []string, by string) error {
+	return f.selectOptions(selector, values, by)
+}
+
+func (f *fakeBackend) DispatchEvent(selector, event string, init map[string]interface{}) error {
+	return f.dispatchEvent(selector, event, init)
+}
+
+func (f *fakeBackend) Upload(selector string, files []string) error {
+	return f.upload(selector, files)
+}
+
+func (f *fakeBackend) InputMouse(ev agentbrowser.MouseEvent) error {
+	return f.inputMouse(ev)
+}
+
+func (f *fakeBackend) InputKeyboard(ev agentbrowser.KeyEvent) error {
+	return f.inputKeyboard(ev)
+}
+
+func (f *fakeBackend) Count(selector string) (int, error) {
+	return f.count(selector)
+}
+
+func (f *fakeBackend) GetBoundingBox(selector string) (*agentbrowser.BoundingBox, error) {
+	return f.getBoundingBox(selector)
+}
+
+func (f *fakeBackend) Describe(selector string, maxText int) (*agentbrowser.ElementDescription, error) {
+	return f.describe(selector, maxText)
+}
+
+func (f *fakeBackend) ReadableText(selector string, plain bool) (string, error) {
+	return f.readableText(selector, plain)
+}
+
+func (f *fakeBackend) Highlight(selector string, duration int) error {
+	return f.highlight(selector, duration)
+}
+
+func (f *fakeBackend) Locate(loc agentbrowser.Locator) (string, error) {
+	return f.locate(loc)
+}
+
+func (f *fakeBackend) URL() (string, error) {
+	return f.url()
+}
+
+func (f *fakeBackend) Title() (string, error) {
+	return f.title()
+}
+
+func (f *fakeBackend) SetViewport(width, height int) error {
+	return f.setViewport(width, height)
+}
+
+func (f *fakeBackend) PDF(opts agentbrowser.PDFOptions) ([]byte, error) {
+	return f.pdf(opts)
+}
+
+func (f *fakeBackend) StopTracing(path string) error {
+	return f.stopTracing(path)
+}
+
+func (f *fakeBackend) Evaluate(script string) (interface{}, error) {
+	return f.evaluate(script)
+}
+
+func (f *fakeBackend) Wait(selector string, timeout int, state string) error {
+	return f.wait(selector, timeout, state)
+}
+
+func (f *fakeBackend) WaitForTimeout(ms int) error {
+	return f.waitForTimeout(ms)
+}
+
+func (f *fakeBackend) WaitForLoadState(state string, timeout int) error {
+	return f.waitForLoadState(state, timeout)
+}
+
+func (f *fakeBackend) ScrollIntoView(selector string) error {
+	return f.scrollIntoView(selector)
+}
+
+func (f *fakeBackend) NewTab(url string) (int, error) {
+	return f.newTab(url)
+}
+
+func (f *fakeBackend) SwitchTab(index int) error {
+	return f.switchTab(index)
+}
+
+func (f *fakeBackend) CloseTab(index int) error {
+	return f.closeTab(index)
+}
+
+func (f *fakeBackend) ListTabs() ([]agentbrowser.TabInfo, error) {
+	return f.listTabs()
+}
+
+func (f *fakeBackend) BringToFront() error {
+	return f.bringToFront()
+}
+
+func (f *fakeBackend) SwitchFrame(ref agentbrowser.FrameRef) error {
+	return f.switchFrame(ref)
+}
+
+func (f *fakeBackend) GetSnapshot(opts agentbrowser.SnapshotOptions) (*agentbrowser.EnhancedSnapshot, error) {
+	return f.getSnapshot(opts)
+}
+
+func (f *fakeBackend) GetRefMap() agentbrowser.RefMap {
+	return f.getRefMap()
+}
+
+func (f *fakeBackend) SetUserAgent(ua string) error {
+	return f.setUserAgent(ua)
+}
+
+func (f *fakeBackend) SetGeolocation(geo *agentbrowser.Geolocation) error {
+	return f.setGeolocation(geo)
+}
+
+func (f *fakeBackend) SetLocale(locale string) error {
+	return f.setLocale(locale)
+}
+
+func (f *fakeBackend) EmulateMedia(media agentbrowser.MediaEmulation) error {
+	return f.emulateMedia(media)
+}
+
+func (f *fakeBackend) SetDialogResponse(response, promptText string) error {
+	return f.setDialogResponse(response, promptText)
+}
+
+func (f *fakeBackend) GrantPermissions(permissions []string, origin string) error {
+	return f.grantPermissions(permissions, origin)
+}
+
+func (f *fakeBackend) SetExtraHeaders(headers map[string]string) error {
+	return f.setExtraHeaders(headers)
+}
+
+func (f *fakeBackend) SetHTTPCredentials(creds *agentbrowser.HTTPCredentials) error {
+	return f.setHTTPCredentials(creds)
+}
+
+func (f *fakeBackend) AddInitScript(script string) error {
+	return f.addInitScript(script)
+}
+
+func (f *fakeBackend) AddScriptTag(url, content string) error {
+	return f.addScriptTag(url, content)
+}
+
+func (f *fakeBackend) AddStyleTag(url, content string) error {
+	return f.addStyleTag(url, content)
+}
+
+func (f *fakeBackend) GetCookies() ([]agentbrowser.Cookie, error) {
+	return f.getCookies()
+}
+
+func (f *fakeBackend) SetCookies(cookies []agentbrowser.Cookie) error {
+	return f.setCookies(cookies)
+}
+
+func (f *fakeBackend) ClearCookies() error {
+	return f.clearCookies()
+}
+
+func (f *fakeBackend) ClearCache() error {
+	return f.clearCache()
+}
+
+func (f *fakeBackend) ClearStorage(origin string) error {
+	return f.clearStorage(origin)
+}
+
+func (f *fakeBackend) PerformanceMetrics() (map[string]float64, error) {
+	return f.performanceMetrics()
+}
+
+func (f *fakeBackend) BrowserProcesses() ([]agentbrowser.BrowserProcess, error) {
+	return f.browserProcesses()
+}
+
+func (f *fakeBackend) ConsoleMessages() []agentbrowser.ConsoleMessage {
+	return f.consoleMessages()
+}
+
+func (f *fakeBackend) PageErrors() []agentbrowser.PageError {
+	return f.pageErrors()
+}
+
+func (f *fakeBackend) Requests() []agentbrowser.TrackedRequest {
+	return f.requests()
+}
+
+func (f *fakeBackend) SetEventHandler(handler func(agentbrowser.Event)) {
+	f.setEventHandler(handler)
+}
+
+func (f *fakeBackend) StartScreencast(opts agentbrowser.ScreencastOptions, onFrame func(agentbrowser.ScreencastFrame)) error {
+	return f.startScreencast(opts, onFrame)
+}
+
+func (f *fakeBackend) StopScreencast() error {
+	return f.stopScreencast()
+}
+
+// evalResult returns an evaluate func that always returns result.
+func evalResult(result interface{}) func(string) (interface{}, error) {
+	return func(string) (interface{}, error) { return result, nil }
+}
+
+// noSnapshot is a getSnapshot func for pages that cannot be snapshotted.
+func noSnapshot(agentbrowser.SnapshotOptions) (*agentbrowser.EnhancedSnapshot, error) {
+	return nil, errors.New("no snapshot")
+}
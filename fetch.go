@@ -0,0 +1,100 @@
+package agentbrowser
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"strings"
+)
+
+// FetchRequest is an HTTP request sent from the page with fetch(), so it
+// carries the page's cookies and session like the site's own API calls.
+type FetchRequest struct {
+	URL     string            // absolute, or relative to the current page
+	Method  string            // default GET
+	Headers map[string]string // request headers
+	Body    string            // request body
+}
+
+// FetchResponse is the response to a FetchRequest.
+type FetchResponse struct {
+	Status     int               `json:"status"`
+	StatusText string            `json:"statusText"`
+	URL        string            `json:"url"` // final URL after redirects
+	Headers    map[string]string `json:"headers"`
+	Body       interface{}       `json:"body"` // parsed JSON for JSON responses, text otherwise
+}
+
+// fetchScript performs a request with the page's credentials and returns
+// the response with its body as text.
+const fetchScript = `(async (url, init) => {
+	const res = await fetch(url, init);
+	const headers = {};
+	res.headers.forEach((value, name) => { headers[name] = value; });
+	return { status: res.status, statusText: res.statusText, url: res.url, headers, body: await res.text() };
+})(%s, %s)`
+
+// Fetch sends the request from the active page. Responses with a JSON
+// content type are parsed; other bodies are returned as text.
+func (m *BrowserManager) Fetch(req FetchRequest) (*FetchResponse, error) {
+	if req.URL == "" {
+		return nil, fmt.Errorf("url is required")
+	}
+	init := map[string]interface{}{
+		"method":      "GET",
+		"credentials": "include",
+	}
+	if req.Method != "" {
+		init["method"] = strings.ToUpper(req.Method)
+	}
+	if len(req.Headers) > 0 {
+		init["headers"] = req.Headers
+	}
+	if req.Body != "" {
+		init["body"] = req.Body
+	}
+	url, err := json.Marshal(req.URL)
+	if err != nil {
+		return nil, err
+	}
+	initJSON, err := json.Marshal(init)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := m.backend.Evaluate(fmt.Sprintf(fetchScript, url, initJSON))
+	if err != nil {
+		return nil, fmt.Errorf("fetch %s: %w", req.URL, err)
+	}
+	data, err := json.Marshal(result)
+	if err != nil {
+		return nil, err
+	}
+	var raw struct {
+		FetchResponse
+		Body string `json:"body"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("fetch %s: unexpected result: %w", req.URL, err)
+	}
+
+	resp := raw.FetchResponse
+	resp.Body = raw.Body
+	if isJSONContentType(resp.Headers["content-type"]) {
+		var parsed interface{}
+		if json.Unmarshal([]byte(raw.Body), &parsed) == nil {
+			resp.Body = parsed
+		}
+	}
+	return &resp, nil
+}
+
+// isJSONContentType reports whether a Content-Type is JSON, including
+// suffixed types such as application/problem+json.
+func isJSONContentType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
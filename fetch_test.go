@@ -0,0 +1,62 @@
+package agentbrowser_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	agentbrowser "github.com/cpunion/agent-browser-go"
+)
+
+// TestFetch tests request encoding and JSON parsing of fetch responses
+func TestFetch(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		want        interface{}
+	}{
+		{"json", "application/json; charset=utf-8", `{"id":1,"tags":["a"]}`, map[string]interface{}{"id": float64(1), "tags": []interface{}{"a"}}},
+		{"problem json", "application/problem+json", `{"title":"bad"}`, map[string]interface{}{"title": "bad"}},
+		{"invalid json", "application/json", `not json`, "not json"},
+		{"text", "text/html", `{"id":1}`, `{"id":1}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var script string
+			m := agentbrowser.NewBrowserManagerForTest(&fakeBackend{
+				evaluate: func(s string) (interface{}, error) {
+					script = s
+					return map[string]interface{}{
+						"status":     200,
+						"statusText": "OK",
+						"url":        "https://example.com/api",
+						"headers":    map[string]interface{}{"content-type": tt.contentType},
+						"body":       tt.body,
+					}, nil
+				},
+			})
+			resp, err := m.Fetch(agentbrowser.FetchRequest{
+				URL:     "/api",
+				Method:  "post",
+				Headers: map[string]string{"Content-Type": "application/json"},
+				Body:    `{"q":"it's"}`,
+			})
+			if err != nil {
+				t.Fatalf("Fetch() error = %v", err)
+			}
+			if resp.Status != 200 || resp.URL != "https://example.com/api" {
+				t.Errorf("Fetch() = %+v", resp)
+			}
+			if !reflect.DeepEqual(resp.Body, tt.want) {
+				t.Errorf("Body = %#v, want %#v", resp.Body, tt.want)
+			}
+			for _, want := range []string{`"/api"`, `"method":"POST"`, `"credentials":"include"`, `"body":"{\"q\":\"it's\"}"`} {
+				if !strings.Contains(script, want) {
+					t.Errorf("script missing %s:\n%s", want, script)
+				}
+			}
+		})
+	}
+}
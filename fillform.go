@@ -0,0 +1,214 @@
+package agentbrowser
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// FieldValue is the value to put in a form field, found by Field: a
+// selector or ref, else the field's label or name.
+type FieldValue struct {
+	Field string
+	Value interface{} // string, number, bool, or a list of them for a multiple select or file input
+}
+
+// FieldValues are form field values, in the order they are filled. In
+// JSON they are an object of field to value, whose order is kept.
+type FieldValues []FieldValue
+
+// UnmarshalJSON decodes a JSON object, keeping the order of its fields.
+func (v *FieldValues) UnmarshalJSON(data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return fmt.Errorf("fields must be an object of field to value")
+	}
+	*v = nil
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		field, _ := tok.(string)
+		var value interface{}
+		if err := dec.Decode(&value); err != nil {
+			return err
+		}
+		*v = append(*v, FieldValue{Field: field, Value: value})
+	}
+	_, err := dec.Token()
+	return err
+}
+
+// MarshalJSON encodes the values as a JSON object in their order.
+func (v FieldValues) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, fv := range v {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, _ := json.Marshal(fv.Field)
+		value, err := json.Marshal(fv.Value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// FilledField is the outcome of filling one field.
+type FilledField struct {
+	Field  string `json:"field"`
+	Action string `json:"action,omitempty"` // fill, check, uncheck, select or upload
+	Error  string `json:"error,omitempty"`
+}
+
+// FillFormData is the response for fill_form.
+type FillFormData struct {
+	Filled int           `json:"filled"`
+	Fields []FilledField `json:"fields"`
+}
+
+// FillForm fills form fields in order, as fill, check, uncheck, select or
+// upload would according to each field's type, and reports the outcome of
+// each. A failed field does not stop the others.
+func (m *BrowserManager) FillForm(values FieldValues) FillFormData {
+	data := FillFormData{Fields: make([]FilledField, 0, len(values))}
+	for _, fv := range values {
+		result := FilledField{Field: fv.Field}
+		action, err := m.fillField(fv)
+		result.Action = action
+		if err != nil {
+			result.Error = err.Error()
+		} else {
+			data.Filled++
+		}
+		data.Fields = append(data.Fields, result)
+	}
+	return data
+}
+
+// fillField puts a value in one field and returns the action taken.
+func (m *BrowserManager) fillField(fv FieldValue) (string, error) {
+	selector, err := m.findField(fv.Field)
+	if err != nil {
+		return "", err
+	}
+	desc, err := m.Describe(selector, 0)
+	if err != nil {
+		return "", err
+	}
+
+	inputType := strings.ToLower(desc.Attributes["type"])
+	switch {
+	case desc.Tag == "select":
+		values := fieldStrings(fv.Value)
+		err := m.Select(selector, values, "value")
+		if err != nil && strings.Contains(err.Error(), "no option with value") {
+			// Agents usually know the text shown, not the value
+			err = m.Select(selector, values, "label")
+		}
+		return "select", err
+	case inputType == "checkbox" || desc.Role == "checkbox" || desc.Role == "switch":
+		on, err := fieldBool(fv.Value)
+		if err != nil {
+			return "", err
+		}
+		if on {
+			return "check", m.Check(selector)
+		}
+		return "uncheck", m.Uncheck(selector)
+	case inputType == "radio" || desc.Role == "radio":
+		on, err := fieldBool(fv.Value)
+		if err != nil {
+			return "", err
+		}
+		if !on {
+			return "", fmt.Errorf("a radio button cannot be unchecked; check another one of its group")
+		}
+		return "check", m.Check(selector)
+	case inputType == "file":
+		return "upload", m.Upload(selector, fieldStrings(fv.Value))
+	default:
+		values := fieldStrings(fv.Value)
+		if len(values) != 1 {
+			return "", fmt.Errorf("a %s field takes one value", cmp.Or(inputType, desc.Tag))
+		}
+		return "fill", m.Fill(selector, values[0])
+	}
+}
+
+// findField returns a selector for a field given by selector or ref, else
+// by its label or name.
+func (m *BrowserManager) findField(field string) (string, error) {
+	if looksLikeSelector(field) {
+		return field, nil
+	}
+	// An exact label first, so Name does not find First name
+	for _, exact := range []bool{true, false} {
+		if selector, err := m.Locate(Locator{By: "label", Text: field, Exact: exact}); err == nil {
+			return selector, nil
+		}
+	}
+	byName := fmt.Sprintf("[name=%s]", strconv.Quote(field))
+	if n, err := m.Count(byName); err == nil && n > 0 {
+		return byName, nil
+	}
+	return "", fmt.Errorf("no field labelled or named %q", field)
+}
+
+// looksLikeSelector reports whether a field is given by a ref or selector
+// rather than by its label or name.
+func looksLikeSelector(field string) bool {
+	switch field {
+	case "input", "select", "textarea":
+		return true
+	}
+	return IsRef(field) || strings.ContainsAny(field, "#.[]=>:") || strings.HasPrefix(field, "//")
+}
+
+// fieldStrings returns a field value as the strings to fill or select.
+func fieldStrings(value interface{}) []string {
+	switch v := value.(type) {
+	case nil:
+		return []string{""}
+	case []interface{}:
+		out := make([]string, 0, len(v))
+		for _, item := range v {
+			out = append(out, fieldStrings(item)...)
+		}
+		return out
+	case string:
+		return []string{v}
+	case float64:
+		return []string{strconv.FormatFloat(v, 'f', -1, 64)}
+	default:
+		return []string{fmt.Sprint(v)}
+	}
+}
+
+// fieldBool returns whether a checkbox or radio value means checked.
+func fieldBool(value interface{}) (bool, error) {
+	switch v := value.(type) {
+	case bool:
+		return v, nil
+	case float64:
+		return v != 0, nil
+	case string:
+		switch strings.ToLower(strings.TrimSpace(v)) {
+		case "true", "yes", "on", "1", "checked", "check":
+			return true, nil
+		case "false", "no", "off", "0", "unchecked", "uncheck", "":
+			return false, nil
+		}
+	}
+	return false, fmt.Errorf("%v is not a checkbox value (expected true or false)", value)
+}
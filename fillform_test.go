@@ -0,0 +1,96 @@
+package agentbrowser_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	agentbrowser "github.com/cpunion/agent-browser-go"
+)
+
+// TestFillForm tests filling fields of each type by label, name and selector
+func TestFillForm(t *testing.T) {
+	input := func(typ string) *agentbrowser.ElementDescription {
+		return &agentbrowser.ElementDescription{Tag: "input", Attributes: map[string]string{"type": typ}}
+	}
+	labels := map[string]string{"Name": "#name", "First name": "#first", "I agree": "#agree", "Pro plan": "#pro"}
+	fields := map[string]*agentbrowser.ElementDescription{
+		"#name": input("text"), "#first": input("text"), "#agree": input("checkbox"),
+		"#pro": input("radio"), `[name="age"]`: input("number"),
+		"#country": {Tag: "select"}, "#news": input("checkbox"),
+	}
+	var done []string
+	m := agentbrowser.NewBrowserManagerForTest(&fakeBackend{
+		locate: func(loc agentbrowser.Locator) (string, error) {
+			for label, selector := range labels {
+				if loc.Exact && label == loc.Text || !loc.Exact && strings.Contains(strings.ToLower(label), strings.ToLower(loc.Text)) {
+					return selector, nil
+				}
+			}
+			return "", fmt.Errorf("no element found for label %q", loc.Text)
+		},
+		count: func(selector string) (int, error) {
+			if _, ok := fields[selector]; ok {
+				return 1, nil
+			}
+			return 0, nil
+		},
+		describe: func(selector string, maxText int) (*agentbrowser.ElementDescription, error) {
+			if desc, ok := fields[selector]; ok {
+				return desc, nil
+			}
+			return nil, fmt.Errorf("element not found: %s", selector)
+		},
+		fill: func(selector, value string) error {
+			done = append(done, "fill "+selector+" "+value)
+			return nil
+		},
+		check: func(selector string) error {
+			done = append(done, "check "+selector)
+			return nil
+		},
+		uncheck: func(selector string) error {
+			done = append(done, "uncheck "+selector)
+			return nil
+		},
+		selectOptions: func(selector string, values []string, by string) error {
+			if by == "value" && values[0] == "France" {
+				return errors.New(`no option with value "France"`)
+			}
+			done = append(done, "select "+selector+" "+by+" "+strings.Join(values, ","))
+			return nil
+		},
+		getRefMap:   func() agentbrowser.RefMap { return nil },
+		getSnapshot: noSnapshot,
+	})
+
+	var cmd agentbrowser.FillFormCommand
+	err := json.Unmarshal([]byte(`{"id": "1", "action": "fill_form", "fields": {
+		"Name": "Ada", "age": 36, "I agree": true, "#news": "no", "Pro plan": "yes",
+		"#country": "France", "Phone": "555", "#agree": "maybe"}}`), &cmd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := json.Marshal(cmd.Fields); !strings.HasPrefix(string(data), `{"Name":"Ada","age":36,"I agree":true,`) {
+		t.Errorf("fields marshal to %s, want their order kept", data)
+	}
+	resp := agentbrowser.ExecuteCommand(&cmd, m)
+
+	wantDone := []string{
+		"fill #name Ada", `fill [name="age"] 36`, "check #agree", "uncheck #news", "check #pro",
+		"select #country label France",
+	}
+	if !reflect.DeepEqual(done, wantDone) {
+		t.Errorf("done = %q, want %q", done, wantDone)
+	}
+	if resp.Success || !strings.HasPrefix(resp.Error, "2 of 8 fields failed: Phone: no field labelled or named") {
+		t.Errorf("error = %q, want Phone and #agree failures", resp.Error)
+	}
+	var data agentbrowser.FillFormData
+	if err := json.Unmarshal(resp.Data, &data); err != nil || data.Filled != 6 || len(data.Fields) != 8 {
+		t.Errorf("data = %s, want 6 of 8 filled", resp.Data)
+	}
+}
@@ -0,0 +1,294 @@
+package agentbrowser
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Fingerprint is a consistent browser identity applied at launch. All
+// values are chosen together so the user agent, platform, screen and
+// graphics strings agree with each other.
+type Fingerprint struct {
+	UserAgent           string            `json:"userAgent"`
+	Platform            string            `json:"platform"` // navigator.platform, e.g. "Win32"
+	HardwareConcurrency int               `json:"hardwareConcurrency"`
+	DeviceMemory        int               `json:"deviceMemory"` // GB
+	Screen              FingerprintScreen `json:"screen"`
+	Timezone            string            `json:"timezone"` // IANA name, e.g. "America/New_York"
+	WebGLVendor         string            `json:"webglVendor"`
+	WebGLRenderer       string            `json:"webglRenderer"`
+	NoiseSeed           uint32            `json:"noiseSeed"` // seeds canvas and WebGL readback noise
+}
+
+// FingerprintScreen describes the reported screen.
+type FingerprintScreen struct {
+	Width       int     `json:"width"`
+	Height      int     `json:"height"`
+	AvailWidth  int     `json:"availWidth"`
+	AvailHeight int     `json:"availHeight"`
+	ColorDepth  int     `json:"colorDepth"`
+	PixelRatio  float64 `json:"pixelRatio"`
+}
+
+// fingerprintPlatform holds the values that must agree for one OS family.
+type fingerprintPlatform struct {
+	Platform    string
+	UAOS        string
+	Screens     [][2]int
+	PixelRatios []float64
+	Taskbar     int // pixels of screen height taken by the OS
+	GPUs        [][2]string
+}
+
+// fingerprintPlatforms lists the OS families NewFingerprint can generate.
+var fingerprintPlatforms = map[string]fingerprintPlatform{
+	"windows": {
+		Platform:    "Win32",
+		UAOS:        "Windows NT 10.0; Win64; x64",
+		Screens:     [][2]int{{1920, 1080}, {1366, 768}, {1536, 864}, {2560, 1440}, {1440, 900}},
+		PixelRatios: []float64{1, 1, 1.25, 1.5},
+		Taskbar:     40,
+		GPUs: [][2]string{
+			{"Google Inc. (Intel)", "ANGLE (Intel, Intel(R) UHD Graphics 620 Direct3D11 vs_5_0 ps_5_0, D3D11)"},
+			{"Google Inc. (NVIDIA)", "ANGLE (NVIDIA, NVIDIA GeForce GTX 1650 Direct3D11 vs_5_0 ps_5_0, D3D11)"},
+			{"Google Inc. (AMD)", "ANGLE (AMD, AMD Radeon(TM) Graphics Direct3D11 vs_5_0 ps_5_0, D3D11)"},
+		},
+	},
+	"mac": {
+		Platform:    "MacIntel",
+		UAOS:        "Macintosh; Intel Mac OS X 10_15_7",
+		Screens:     [][2]int{{1440, 900}, {1512, 982}, {1728, 1117}, {1680, 1050}},
+		PixelRatios: []float64{2},
+		Taskbar:     25,
+		GPUs: [][2]string{
+			{"Google Inc. (Apple)", "ANGLE (Apple, ANGLE Metal Renderer: Apple M1, Unspecified Version)"},
+			{"Google Inc. (Apple)", "ANGLE (Apple, ANGLE Metal Renderer: Apple M2, Unspecified Version)"},
+			{"Google Inc. (Intel Inc.)", "ANGLE (Intel Inc., Intel(R) Iris(TM) Plus Graphics OpenGL Engine, OpenGL 4.1)"},
+		},
+	},
+	"linux": {
+		Platform:    "Linux x86_64",
+		UAOS:        "X11; Linux x86_64",
+		Screens:     [][2]int{{1920, 1080}, {2560, 1440}, {1366, 768}},
+		PixelRatios: []float64{1},
+		Taskbar:     27,
+		GPUs: [][2]string{
+			{"Google Inc. (Intel)", "ANGLE (Intel, Mesa Intel(R) UHD Graphics 620 (KBL GT2), OpenGL 4.6)"},
+			{"Google Inc. (AMD)", "ANGLE (AMD, AMD Radeon RX 580 Series (radeonsi, polaris10, LLVM 15.0.7), OpenGL 4.6)"},
+		},
+	},
+}
+
+// fingerprintChromeVersions are the reduced Chrome versions used in generated user agents.
+var fingerprintChromeVersions = []string{"129.0.0.0", "130.0.0.0", "131.0.0.0"}
+
+// fingerprintTimezones are the timezones generated fingerprints pick from.
+var fingerprintTimezones = []string{
+	"America/New_York", "America/Chicago", "America/Denver", "America/Los_Angeles",
+	"Europe/London", "Europe/Berlin", "Europe/Paris",
+}
+
+// NewFingerprint generates a fingerprint for the given OS family
+// ("windows", "mac" or "linux"; random when empty). The same seed always
+// produces the same fingerprint; a zero seed picks one from the clock.
+func NewFingerprint(platform string, seed int64) (*Fingerprint, error) {
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	rng := rand.New(rand.NewSource(seed))
+
+	if platform == "" {
+		names := []string{"windows", "mac", "linux"}
+		platform = names[rng.Intn(len(names))]
+	}
+	p, ok := fingerprintPlatforms[platform]
+	if !ok {
+		return nil, fmt.Errorf("unknown fingerprint platform: %s (expected windows, mac or linux)", platform)
+	}
+
+	screen := p.Screens[rng.Intn(len(p.Screens))]
+	gpu := p.GPUs[rng.Intn(len(p.GPUs))]
+	cores := []int{4, 8, 8, 12, 16}
+	memory := []int{4, 8, 8, 16}
+
+	return &Fingerprint{
+		UserAgent: fmt.Sprintf("Mozilla/5.0 (%s) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/%s Safari/537.36",
+			p.UAOS, fingerprintChromeVersions[rng.Intn(len(fingerprintChromeVersions))]),
+		Platform:            p.Platform,
+		HardwareConcurrency: cores[rng.Intn(len(cores))],
+		DeviceMemory:        memory[rng.Intn(len(memory))],
+		Screen: FingerprintScreen{
+			Width:       screen[0],
+			Height:      screen[1],
+			AvailWidth:  screen[0],
+			AvailHeight: screen[1] - p.Taskbar,
+			ColorDepth:  24,
+			PixelRatio:  p.PixelRatios[rng.Intn(len(p.PixelRatios))],
+		},
+		Timezone:      fingerprintTimezones[rng.Intn(len(fingerprintTimezones))],
+		WebGLVendor:   gpu[0],
+		WebGLRenderer: gpu[1],
+		NoiseSeed:     rng.Uint32(),
+	}, nil
+}
+
+// sameFingerprint reports whether two optional fingerprints are equal.
+func sameFingerprint(a, b *Fingerprint) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// fingerprintScript returns an init script that reports the fingerprint's
+// navigator, screen and WebGL values and adds seeded noise to canvas and
+// WebGL readback. The user agent and timezone are set by the backend.
+func fingerprintScript(fp Fingerprint) string {
+	data, _ := json.Marshal(fp)
+
+	return fmt.Sprintf(`(() => {
+	const fp = %s;
+	const define = (obj, prop, value) => {
+		Object.defineProperty(obj, prop, { get: () => value, configurable: true, enumerable: true });
+	};
+
+	define(Navigator.prototype, 'platform', fp.platform);
+	if (fp.hardwareConcurrency) define(Navigator.prototype, 'hardwareConcurrency', fp.hardwareConcurrency);
+	if (fp.deviceMemory) define(Navigator.prototype, 'deviceMemory', fp.deviceMemory);
+	for (const key of ['width', 'height', 'availWidth', 'availHeight', 'colorDepth']) {
+		define(Screen.prototype, key, fp.screen[key]);
+	}
+	define(Screen.prototype, 'pixelDepth', fp.screen.colorDepth);
+	define(window, 'devicePixelRatio', fp.screen.pixelRatio);
+
+	for (const ctx of [window.WebGLRenderingContext, window.WebGL2RenderingContext]) {
+		if (!ctx || !fp.webglVendor) continue;
+		const getParameter = ctx.prototype.getParameter;
+		ctx.prototype.getParameter = function (param) {
+			if (param === 37445) return fp.webglVendor; // UNMASKED_VENDOR_WEBGL
+			if (param === 37446) return fp.webglRenderer; // UNMASKED_RENDERER_WEBGL
+			return getParameter.call(this, param);
+		};
+	}
+
+	// Deterministic noise: the same image always gets the same perturbation,
+	// so repeated reads agree while the hash differs from other profiles.
+	const noise = (data) => {
+		let s = fp.noiseSeed >>> 0;
+		for (let i = 0; i < data.length; i += 4) {
+			s = (s + 0x6d2b79f5) >>> 0;
+			let t = Math.imul(s ^ (s >>> 15), s | 1);
+			t ^= t + Math.imul(t ^ (t >>> 7), t | 61);
+			if (((t ^ (t >>> 14)) >>> 0) %% 16 === 0) data[i] ^= 1;
+		}
+	};
+
+	const getImageData = CanvasRenderingContext2D.prototype.getImageData;
+	CanvasRenderingContext2D.prototype.getImageData = function (...args) {
+		const image = getImageData.apply(this, args);
+		noise(image.data);
+		return image;
+	};
+
+	const noisyCopy = (canvas) => {
+		if (!canvas.width || !canvas.height) return canvas;
+		const copy = document.createElement('canvas');
+		copy.width = canvas.width;
+		copy.height = canvas.height;
+		const ctx = copy.getContext('2d');
+		ctx.drawImage(canvas, 0, 0);
+		const image = getImageData.call(ctx, 0, 0, copy.width, copy.height);
+		noise(image.data);
+		ctx.putImageData(image, 0, 0);
+		return copy;
+	};
+	const toDataURL = HTMLCanvasElement.prototype.toDataURL;
+	HTMLCanvasElement.prototype.toDataURL = function (...args) {
+		return toDataURL.apply(noisyCopy(this), args);
+	};
+	const toBlob = HTMLCanvasElement.prototype.toBlob;
+	HTMLCanvasElement.prototype.toBlob = function (...args) {
+		return toBlob.apply(noisyCopy(this), args);
+	};
+
+	for (const ctx of [window.WebGLRenderingContext, window.WebGL2RenderingContext]) {
+		if (!ctx) continue;
+		const readPixels = ctx.prototype.readPixels;
+		ctx.prototype.readPixels = function (...args) {
+			readPixels.apply(this, args);
+			const pixels = args[6];
+			if (pixels && pixels.length) noise(pixels);
+		};
+	}
+})()`, data)
+}
+
+// GetFingerprintFile returns the fingerprint file path for a session.
+func GetFingerprintFile(session string) string {
+	dir := filepath.Join(os.TempDir(), "agent-browser-go")
+	_ = os.MkdirAll(dir, 0755)
+	return filepath.Join(dir, fmt.Sprintf("%s.fingerprint", session))
+}
+
+// SaveSessionFingerprint saves the fingerprint used when the session's browser launches.
+func SaveSessionFingerprint(session string, fp *Fingerprint) error {
+	data, err := json.MarshalIndent(fp, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(GetFingerprintFile(session), data, 0644)
+}
+
+// GetSessionFingerprint retrieves the saved fingerprint for a session.
+// Returns nil if the session has none.
+func GetSessionFingerprint(session string) *Fingerprint {
+	data, err := os.ReadFile(GetFingerprintFile(session))
+	if err != nil {
+		return nil
+	}
+	fp, err := ParseFingerprint(data)
+	if err != nil {
+		return nil
+	}
+	return fp
+}
+
+// ClearSessionFingerprint removes the saved fingerprint for a session.
+func ClearSessionFingerprint(session string) error {
+	err := os.Remove(GetFingerprintFile(session))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+// ParseFingerprint decodes and validates a fingerprint profile.
+func ParseFingerprint(data []byte) (*Fingerprint, error) {
+	var fp Fingerprint
+	if err := json.Unmarshal(data, &fp); err != nil {
+		return nil, fmt.Errorf("invalid fingerprint: %w", err)
+	}
+	if fp.UserAgent == "" || fp.Platform == "" {
+		return nil, fmt.Errorf("invalid fingerprint: userAgent and platform are required")
+	}
+	if fp.Screen.Width <= 0 || fp.Screen.Height <= 0 {
+		return nil, fmt.Errorf("invalid fingerprint: screen width and height are required")
+	}
+	if fp.Screen.ColorDepth == 0 {
+		fp.Screen.ColorDepth = 24
+	}
+	if fp.Screen.PixelRatio == 0 {
+		fp.Screen.PixelRatio = 1
+	}
+	if fp.Screen.AvailWidth == 0 {
+		fp.Screen.AvailWidth = fp.Screen.Width
+	}
+	if fp.Screen.AvailHeight == 0 {
+		fp.Screen.AvailHeight = fp.Screen.Height
+	}
+	return &fp, nil
+}
@@ -0,0 +1,78 @@
+package agentbrowser_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	agentbrowser "github.com/cpunion/agent-browser-go"
+)
+
+// TestNewFingerprint tests that generated fingerprints are consistent per platform
+func TestNewFingerprint(t *testing.T) {
+	tests := []struct {
+		platform string
+		navPlat  string
+		uaOS     string
+	}{
+		{"windows", "Win32", "Windows NT 10.0"},
+		{"mac", "MacIntel", "Mac OS X"},
+		{"linux", "Linux x86_64", "Linux x86_64"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.platform, func(t *testing.T) {
+			fp, err := agentbrowser.NewFingerprint(tt.platform, 42)
+			if err != nil {
+				t.Fatalf("NewFingerprint() error = %v", err)
+			}
+			if fp.Platform != tt.navPlat {
+				t.Errorf("expected platform %s, got %s", tt.navPlat, fp.Platform)
+			}
+			if !strings.Contains(fp.UserAgent, tt.uaOS) {
+				t.Errorf("user agent %q does not match platform", fp.UserAgent)
+			}
+			if fp.Screen.AvailHeight >= fp.Screen.Height || fp.Screen.AvailWidth != fp.Screen.Width {
+				t.Errorf("unexpected available screen: %+v", fp.Screen)
+			}
+			if fp.Timezone == "" || fp.WebGLRenderer == "" || fp.HardwareConcurrency == 0 {
+				t.Errorf("incomplete fingerprint: %+v", fp)
+			}
+
+			again, _ := agentbrowser.NewFingerprint(tt.platform, 42)
+			if *again != *fp {
+				t.Error("expected the same seed to produce the same fingerprint")
+			}
+		})
+	}
+
+	if _, err := agentbrowser.NewFingerprint("amiga", 1); err == nil {
+		t.Error("expected error for unknown platform")
+	}
+}
+
+// TestParseFingerprint tests loading fingerprints from JSON
+func TestParseFingerprint(t *testing.T) {
+	fp, _ := agentbrowser.NewFingerprint("mac", 7)
+	data, _ := json.Marshal(fp)
+
+	parsed, err := agentbrowser.ParseFingerprint(data)
+	if err != nil {
+		t.Fatalf("ParseFingerprint() error = %v", err)
+	}
+	if *parsed != *fp {
+		t.Errorf("round trip mismatch: %+v != %+v", parsed, fp)
+	}
+
+	minimal, err := agentbrowser.ParseFingerprint([]byte(`{"userAgent":"UA","platform":"Win32","screen":{"width":1920,"height":1080}}`))
+	if err != nil {
+		t.Fatalf("ParseFingerprint() error = %v", err)
+	}
+	if minimal.Screen.AvailHeight != 1080 || minimal.Screen.ColorDepth != 24 || minimal.Screen.PixelRatio != 1 {
+		t.Errorf("expected screen defaults, got %+v", minimal.Screen)
+	}
+
+	if _, err := agentbrowser.ParseFingerprint([]byte(`{"platform":"Win32"}`)); err == nil {
+		t.Error("expected error for missing userAgent")
+	}
+}
@@ -0,0 +1,149 @@
+package agentbrowser
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Form is a form on the page and the fields to fill in it.
+type Form struct {
+	Name   string      `json:"name,omitempty"`   // name or id
+	Action string      `json:"action,omitempty"` // absolute URL
+	Method string      `json:"method,omitempty"`
+	Fields []FormField `json:"fields"`
+}
+
+// FormField is a form control an agent can fill, check or select.
+type FormField struct {
+	Ref         string        `json:"ref,omitempty"` // snapshot ref such as e3, when the field has one
+	Name        string        `json:"name,omitempty"`
+	Type        string        `json:"type"` // the input type, select or textarea
+	Label       string        `json:"label,omitempty"`
+	Placeholder string        `json:"placeholder,omitempty"`
+	Value       string        `json:"value,omitempty"` // left out for passwords
+	Checked     bool          `json:"checked,omitempty"`
+	Required    bool          `json:"required,omitempty"`
+	Disabled    bool          `json:"disabled,omitempty"`
+	Options     []FieldOption `json:"options,omitempty"` // of a select
+}
+
+// FieldOption is an option of a select.
+type FieldOption struct {
+	Value    string `json:"value"`
+	Label    string `json:"label"`
+	Selected bool   `json:"selected,omitempty"`
+}
+
+// FormsData is the response for forms.
+type FormsData struct {
+	Forms []Form `json:"forms"`
+}
+
+// formsScript returns the page's forms and their visible fields in
+// document order. Fields outside any form come last, in a form without a
+// name or action, and forms without fields are left out. Buttons and hidden
+// inputs are not fields.
+const formsScript = `(() => {
+	const skipTypes = new Set(['hidden', 'submit', 'button', 'reset', 'image']);
+	const text = (s) => (s || '').replace(/\s+/g, ' ').trim();
+	const label = (el) => {
+		const byId = (el.getAttribute('aria-labelledby') || '').split(/\s+/)
+			.map((id) => document.getElementById(id)?.innerText).filter(Boolean).join(' ');
+		const own = el.labels && el.labels.length ? el.labels[0].innerText : '';
+		return text(el.getAttribute('aria-label') || byId || own || el.getAttribute('title'));
+	};
+
+	const forms = new Map();
+	const formOf = (f) => {
+		if (!forms.has(f)) {
+			forms.set(f, f ? {
+				name: f.getAttribute('name') || f.id || '',
+				action: f.hasAttribute('action') ? f.action : '',
+				method: f.hasAttribute('method') ? f.method : '',
+				fields: [],
+			} : {fields: []});
+		}
+		return forms.get(f);
+	};
+	for (const f of document.forms) formOf(f);
+
+	for (const el of document.querySelectorAll('input, select, textarea')) {
+		const tag = el.tagName.toLowerCase();
+		const type = tag === 'input' ? (el.type || 'text') : tag;
+		if (skipTypes.has(type) || el.getClientRects().length === 0) continue;
+		const field = {
+			name: el.name || el.id || '',
+			type,
+			label: label(el),
+			placeholder: el.getAttribute('placeholder') || '',
+			value: type === 'password' || type === 'file' ? '' : (tag === 'select' ? '' : el.value),
+			checked: !!el.checked && (type === 'checkbox' || type === 'radio'),
+			required: el.required,
+			disabled: el.disabled,
+		};
+		if (tag === 'select') {
+			field.options = Array.from(el.options, (o) => ({value: o.value, label: text(o.label || o.text), selected: o.selected}));
+			field.value = Array.from(el.selectedOptions, (o) => o.value).join(',');
+		}
+		formOf(el.form).fields.push(field);
+	}
+
+	const list = Array.from(forms.entries()).filter(([f, form]) => f && form.fields.length);
+	if (forms.has(null) && forms.get(null).fields.length) list.push([null, forms.get(null)]);
+	return list.map(([, form]) => form);
+})()`
+
+// fieldRoles are the snapshot roles of the field types; other text-like
+// types are textboxes.
+var fieldRoles = map[string][]string{
+	"checkbox": {"checkbox", "switch"},
+	"radio":    {"radio"},
+	"select":   {"combobox", "listbox"},
+	"range":    {"slider"},
+	"number":   {"spinbutton", "textbox"},
+	"search":   {"searchbox", "textbox"},
+}
+
+// Forms returns the page's forms and the fields to fill in them, with the
+// refs a fresh snapshot gives the fields.
+func (m *BrowserManager) Forms() ([]Form, error) {
+	result, err := m.backend.Evaluate(formsScript)
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(result)
+	if err != nil {
+		return nil, err
+	}
+	forms := []Form{}
+	if err := json.Unmarshal(data, &forms); err != nil {
+		return nil, fmt.Errorf("unexpected forms result: %v", result)
+	}
+
+	snapshot, err := m.GetSnapshot(SnapshotOptions{Interactive: true})
+	if err != nil {
+		Logger().Debug("forms without refs", "err", err)
+		return forms, nil
+	}
+	var wants []refWant
+	for _, form := range forms {
+		for _, field := range form.Fields {
+			roles, ok := fieldRoles[field.Type]
+			if !ok {
+				roles = []string{"textbox"}
+			}
+			wants = append(wants, refWant{
+				Roles:        roles,
+				Names:        []string{field.Label, field.Placeholder},
+				AllowUnnamed: true,
+			})
+		}
+	}
+	refs := matchRefs(snapshot.Refs, wants)
+	for i := range forms {
+		for j := range forms[i].Fields {
+			forms[i].Fields[j].Ref, refs = refs[0], refs[1:]
+		}
+	}
+	return forms, nil
+}
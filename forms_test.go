@@ -0,0 +1,53 @@
+package agentbrowser_test
+
+import (
+	"reflect"
+	"testing"
+
+	agentbrowser "github.com/cpunion/agent-browser-go"
+)
+
+// TestForms tests describing forms with the refs of their fields
+func TestForms(t *testing.T) {
+	field := func(typ, label string) map[string]interface{} {
+		return map[string]interface{}{"type": typ, "label": label, "name": label}
+	}
+	var result interface{} = []interface{}{
+		map[string]interface{}{
+			"name": "login", "action": "https://a.com/session", "method": "post",
+			"fields": []interface{}{field("email", "Email"), field("password", ""), field("checkbox", "Remember me")},
+		},
+		map[string]interface{}{
+			"fields": []interface{}{map[string]interface{}{
+				"type": "select", "label": "Language", "value": "en",
+				"options": []interface{}{map[string]interface{}{"value": "en", "label": "English", "selected": true}},
+			}},
+		},
+	}
+	refs := agentbrowser.RefMap{
+		"e1": {Role: "textbox", Name: ""},
+		"e2": {Role: "textbox", Name: "Email"},
+		"e3": {Role: "checkbox", Name: "Remember me"},
+		"e4": {Role: "combobox", Name: "Language"},
+	}
+	m := agentbrowser.NewBrowserManagerForTest(readBackend(&result, refs))
+
+	forms, err := m.Forms()
+	if err != nil {
+		t.Fatalf("Forms() error = %v", err)
+	}
+	want := []agentbrowser.Form{
+		{Name: "login", Action: "https://a.com/session", Method: "post", Fields: []agentbrowser.FormField{
+			{Ref: "e2", Name: "Email", Type: "email", Label: "Email"},
+			{Ref: "e1", Type: "password"},
+			{Ref: "e3", Name: "Remember me", Type: "checkbox", Label: "Remember me"},
+		}},
+		{Fields: []agentbrowser.FormField{{
+			Ref: "e4", Type: "select", Label: "Language", Value: "en",
+			Options: []agentbrowser.FieldOption{{Value: "en", Label: "English", Selected: true}},
+		}}},
+	}
+	if !reflect.DeepEqual(forms, want) {
+		t.Errorf("Forms() = %+v, want %+v", forms, want)
+	}
+}
@@ -0,0 +1,84 @@
+package agentbrowser
+
+import (
+	"fmt"
+	"strings"
+)
+
+// FrameRef identifies an iframe to scope selectors and scripts to. Exactly
+// one field is set. A selector is matched in the current frame, so frames
+// nest; a name or URL is matched against every frame of the page.
+type FrameRef struct {
+	Selector string
+	Name     string
+	URL      string
+}
+
+// String describes the ref for error messages.
+func (r FrameRef) String() string {
+	switch {
+	case r.Selector != "":
+		return r.Selector
+	case r.Name != "":
+		return "name=" + r.Name
+	default:
+		return "url=" + r.URL
+	}
+}
+
+// frameInfo is what a frame is matched on by name or URL.
+type frameInfo struct {
+	Name string
+	URL  string
+}
+
+// matchFrame returns the index of the frame ref names, or -1: the frame with
+// that name, or with that URL, else the first whose URL contains it.
+func matchFrame(frames []frameInfo, ref FrameRef) int {
+	for i, f := range frames {
+		if (ref.Name != "" && f.Name == ref.Name) || (ref.URL != "" && f.URL == ref.URL) {
+			return i
+		}
+	}
+	if ref.URL != "" {
+		for i, f := range frames {
+			if strings.Contains(f.URL, ref.URL) {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// frameNotFound is the error when no frame matches ref.
+func frameNotFound(ref FrameRef) error {
+	return fmt.Errorf("no frame matches %s", ref)
+}
+
+// SwitchFrame scopes later selectors and scripts in the current tab to an
+// iframe, until MainFrame or until the frame goes away.
+func (m *BrowserManager) SwitchFrame(ref FrameRef) error {
+	set := 0
+	for _, field := range []string{ref.Selector, ref.Name, ref.URL} {
+		if field != "" {
+			set++
+		}
+	}
+	if set != 1 {
+		return fmt.Errorf("frame needs exactly one of a selector, name or url")
+	}
+	if ref.Selector != "" {
+		selector, err := m.resolveRef(ref.Selector)
+		if err != nil {
+			return err
+		}
+		ref.Selector = selector
+	}
+	return m.backend.SwitchFrame(ref)
+}
+
+// MainFrame scopes selectors and scripts in the current tab to the top
+// document again.
+func (m *BrowserManager) MainFrame() error {
+	return m.backend.MainFrame()
+}
@@ -0,0 +1,59 @@
+package agentbrowser_test
+
+import (
+	"strings"
+	"testing"
+
+	agentbrowser "github.com/cpunion/agent-browser-go"
+)
+
+// TestMatchFrame tests finding frames by name and URL
+func TestMatchFrame(t *testing.T) {
+	frames := []agentbrowser.FrameInfo{
+		{Name: "ads", URL: "https://ads.example.net/slot?id=1"},
+		{Name: "", URL: "https://js.stripe.com/v3/elements"},
+		{Name: "payment", URL: "https://js.stripe.com/v3"},
+	}
+
+	tests := []struct {
+		ref  agentbrowser.FrameRef
+		want int
+	}{
+		{agentbrowser.FrameRef{Name: "payment"}, 2},
+		{agentbrowser.FrameRef{Name: "missing"}, -1},
+		// An exact URL wins over an earlier partial match
+		{agentbrowser.FrameRef{URL: "https://js.stripe.com/v3"}, 2},
+		{agentbrowser.FrameRef{URL: "js.stripe.com"}, 1},
+		{agentbrowser.FrameRef{URL: "example.org"}, -1},
+	}
+	for _, tt := range tests {
+		if got := agentbrowser.MatchFrame(frames, tt.ref); got != tt.want {
+			t.Errorf("MatchFrame(%v) = %d, want %d", tt.ref, got, tt.want)
+		}
+	}
+}
+
+// TestSwitchFrame tests that a frame is named by exactly one of a selector,
+// name or URL
+func TestSwitchFrame(t *testing.T) {
+	var switched []agentbrowser.FrameRef
+	m := agentbrowser.NewBrowserManagerForTest(&fakeBackend{
+		switchFrame: func(ref agentbrowser.FrameRef) error {
+			switched = append(switched, ref)
+			return nil
+		},
+	})
+
+	if err := m.SwitchFrame(agentbrowser.FrameRef{Selector: "iframe#pay"}); err != nil {
+		t.Fatalf("SwitchFrame() error = %v", err)
+	}
+	for _, ref := range []agentbrowser.FrameRef{{}, {Selector: "iframe", Name: "pay"}} {
+		err := m.SwitchFrame(ref)
+		if err == nil || !strings.Contains(err.Error(), "exactly one") {
+			t.Errorf("SwitchFrame(%+v) error = %v, want exactly one", ref, err)
+		}
+	}
+	if len(switched) != 1 {
+		t.Errorf("backend switched %d times, want 1", len(switched))
+	}
+}
@@ -0,0 +1,204 @@
+package agentbrowser
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// selectorNoise are selector tokens that say nothing about which element was meant.
+var selectorNoise = map[string]bool{
+	"role": true, "aria": true, "label": true, "name": true, "data": true,
+	"testid": true, "test": true, "id": true, "class": true, "text": true,
+	"nth": true, "child": true, "type": true, "has": true, "ref": true,
+}
+
+var quotedRe = regexp.MustCompile(`"([^"]*)"|'([^']*)'`)
+
+// targetQuery describes what a selector was trying to reach, as text that
+// can be compared against snapshot roles and names. Known refs resolve to
+// their role and name; anything else is reduced to its meaningful words.
+func targetQuery(selector string, refs RefMap) string {
+	if ref := ParseRef(selector); ref != "" {
+		if info, ok := refs[ref]; ok {
+			return info.Role + " " + info.Name
+		}
+		return ""
+	}
+
+	// Quoted values (aria-label, text=) carry the intent of the selector
+	var parts []string
+	for _, m := range quotedRe.FindAllStringSubmatch(selector, -1) {
+		parts = append(parts, m[1]+m[2])
+	}
+	parts = append(parts, quotedRe.ReplaceAllString(selector, " "))
+
+	var words []string
+	for _, tok := range tokenize(strings.Join(parts, " ")) {
+		if !selectorNoise[tok] {
+			words = append(words, tok)
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// tokenize splits text into lowercase words, also breaking camelCase.
+func tokenize(s string) []string {
+	var words []string
+	var cur []rune
+	flush := func() {
+		if len(cur) > 0 {
+			words = append(words, strings.ToLower(string(cur)))
+			cur = cur[:0]
+		}
+	}
+	runes := []rune(s)
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			flush()
+			continue
+		}
+		if unicode.IsUpper(r) && i > 0 && unicode.IsLower(runes[i-1]) {
+			flush()
+		}
+		cur = append(cur, r)
+	}
+	flush()
+	return words
+}
+
+// matchScore rates how well a candidate's text matches a query, from 0 (no
+// overlap) to 1 (every query word found).
+func matchScore(query, candidate string) float64 {
+	q := tokenize(query)
+	c := tokenize(candidate)
+	if len(q) == 0 || len(c) == 0 {
+		return 0
+	}
+
+	total := 0.0
+	for _, qw := range q {
+		best := 0.0
+		for _, cw := range c {
+			var s float64
+			switch {
+			case qw == cw:
+				s = 1
+			case strings.HasPrefix(cw, qw) || strings.HasPrefix(qw, cw):
+				s = 0.8
+			case strings.Contains(cw, qw) || strings.Contains(qw, cw):
+				s = 0.6
+			default:
+				longest := len(qw)
+				if len(cw) > longest {
+					longest = len(cw)
+				}
+				if sim := 1 - float64(levenshtein(qw, cw))/float64(longest); sim >= 0.6 {
+					s = sim * 0.8
+				}
+			}
+			if s > best {
+				best = s
+			}
+		}
+		total += best
+	}
+	return total / float64(len(q))
+}
+
+// levenshtein returns the edit distance between two strings.
+func levenshtein(a, b string) int {
+	ar, br := []rune(a), []rune(b)
+	prev := make([]int, len(br)+1)
+	cur := make([]int, len(br)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ar); i++ {
+		cur[0] = i
+		for j := 1; j <= len(br); j++ {
+			cost := 1
+			if ar[i-1] == br[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(br)]
+}
+
+// RefCandidate is a ref that may be what a selector was meant to reach.
+type RefCandidate struct {
+	Ref   string  `json:"ref"`
+	Role  string  `json:"role"`
+	Name  string  `json:"name,omitempty"`
+	Score float64 `json:"score"`
+}
+
+// rankRefs returns refs whose role and name match the query, best first.
+func rankRefs(refs RefMap, query string, minScore float64) []RefCandidate {
+	var candidates []RefCandidate
+	for ref, info := range refs {
+		score := matchScore(query, info.Role+" "+info.Name)
+		if score >= minScore && score > 0 {
+			candidates = append(candidates, RefCandidate{Ref: ref, Role: info.Role, Name: info.Name, Score: score})
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].Score != candidates[j].Score {
+			return candidates[i].Score > candidates[j].Score
+		}
+		return refOrder(candidates[i].Ref) < refOrder(candidates[j].Ref)
+	})
+	return candidates
+}
+
+// refOrder returns the numeric part of a ref so refs sort in document order.
+func refOrder(ref string) int {
+	n := 0
+	for _, r := range strings.TrimPrefix(ref, "e") {
+		if r < '0' || r > '9' {
+			return 0
+		}
+		n = n*10 + int(r-'0')
+	}
+	return n
+}
+
+// snapshotExcerpt returns the lines of a snapshot tree within radius of the
+// line carrying ref. Without a ref, it returns the start of the tree.
+func snapshotExcerpt(tree, ref string, radius int) string {
+	lines := strings.Split(tree, "\n")
+	center := -1
+	if ref != "" {
+		marker := "[ref=" + ref + "]"
+		for i, line := range lines {
+			if strings.Contains(line, marker) {
+				center = i
+				break
+			}
+		}
+	}
+
+	start, end := 0, 2*radius+1
+	if center >= 0 {
+		start, end = center-radius, center+radius+1
+	}
+	if start < 0 {
+		start = 0
+	}
+	if end > len(lines) {
+		end = len(lines)
+	}
+
+	excerpt := strings.Join(lines[start:end], "\n")
+	if start > 0 {
+		excerpt = "...\n" + excerpt
+	}
+	if end < len(lines) {
+		excerpt += "\n..."
+	}
+	return excerpt
+}
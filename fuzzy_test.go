@@ -0,0 +1,82 @@
+package agentbrowser_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	agentbrowser "github.com/cpunion/agent-browser-go"
+)
+
+// TestTargetQuery tests extracting match terms from selectors
+func TestTargetQuery(t *testing.T) {
+	refs := agentbrowser.RefMap{
+		"e1": {Role: "button", Name: "Sign in"},
+	}
+
+	tests := []struct {
+		selector string
+		expected string
+	}{
+		{"@e1", "button Sign in"},
+		{"@e99", ""},
+		{"#submitButton", "submit button"},
+		{`[aria-label="Search query"]`, "search query"},
+		{"text=Log out", "log out"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.selector, func(t *testing.T) {
+			if got := agentbrowser.TargetQuery(tt.selector, refs); got != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+// TestRankRefs tests fuzzy ranking of refs by role and name
+func TestRankRefs(t *testing.T) {
+	refs := agentbrowser.RefMap{
+		"e1": {Role: "link", Name: "Home"},
+		"e2": {Role: "button", Name: "Sign in"},
+		"e3": {Role: "textbox", Name: "Email"},
+		"e4": {Role: "button", Name: "Sign up"},
+	}
+
+	candidates := agentbrowser.RankRefs(refs, "signin button", 0)
+	if len(candidates) == 0 {
+		t.Fatal("expected candidates")
+	}
+	if candidates[0].Ref != "e2" {
+		t.Errorf("expected e2 as best match, got %s", candidates[0].Ref)
+	}
+
+	if got := agentbrowser.RankRefs(refs, "zzz", 0.5); len(got) != 0 {
+		t.Errorf("expected no candidates, got %v", got)
+	}
+}
+
+// TestSnapshotExcerpt tests excerpting snapshot lines around a ref
+func TestSnapshotExcerpt(t *testing.T) {
+	var lines []string
+	for i := 1; i <= 20; i++ {
+		lines = append(lines, fmt.Sprintf(`- button "B" [ref=e%d]`, i))
+	}
+	tree := strings.Join(lines, "\n")
+
+	excerpt := agentbrowser.SnapshotExcerpt(tree, "e10", 2)
+	if !strings.Contains(excerpt, "[ref=e8]") || !strings.Contains(excerpt, "[ref=e12]") {
+		t.Errorf("expected lines around e10, got %q", excerpt)
+	}
+	if strings.Contains(excerpt, "[ref=e7]") || strings.Contains(excerpt, "[ref=e13]") {
+		t.Errorf("expected excerpt limited to radius, got %q", excerpt)
+	}
+	if !strings.HasPrefix(excerpt, "...") || !strings.HasSuffix(excerpt, "...") {
+		t.Errorf("expected elision markers, got %q", excerpt)
+	}
+
+	head := agentbrowser.SnapshotExcerpt(tree, "", 2)
+	if !strings.HasPrefix(head, `- button "B" [ref=e1]`) {
+		t.Errorf("expected start of tree without a ref, got %q", head)
+	}
+}
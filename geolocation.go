@@ -0,0 +1,35 @@
+package agentbrowser
+
+import "fmt"
+
+// Geolocation is a position reported to pages through the Geolocation API.
+type Geolocation struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+	Accuracy  float64 `json:"accuracy,omitempty"` // meters
+}
+
+// validate checks the position is on the globe.
+func (g Geolocation) validate() error {
+	switch {
+	case g.Latitude < -90 || g.Latitude > 90:
+		return fmt.Errorf("latitude %v out of range (-90 to 90)", g.Latitude)
+	case g.Longitude < -180 || g.Longitude > 180:
+		return fmt.Errorf("longitude %v out of range (-180 to 180)", g.Longitude)
+	case g.Accuracy < 0:
+		return fmt.Errorf("accuracy %v must not be negative", g.Accuracy)
+	}
+	return nil
+}
+
+// SetGeolocation reports geo as the position of every tab, including tabs
+// opened later, and grants pages the geolocation permission so they get it
+// without a prompt. nil removes the override; the permission stays granted.
+func (m *BrowserManager) SetGeolocation(geo *Geolocation) error {
+	if geo != nil {
+		if err := geo.validate(); err != nil {
+			return err
+		}
+	}
+	return m.backend.SetGeolocation(geo)
+}
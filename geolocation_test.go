@@ -0,0 +1,50 @@
+package agentbrowser_test
+
+import (
+	"testing"
+
+	agentbrowser "github.com/cpunion/agent-browser-go"
+)
+
+// TestSetGeolocation tests that positions off the globe are rejected
+func TestSetGeolocation(t *testing.T) {
+	var calls int
+	var set *agentbrowser.Geolocation
+	m := agentbrowser.NewBrowserManagerForTest(&fakeBackend{
+		setGeolocation: func(geo *agentbrowser.Geolocation) error {
+			calls++
+			set = geo
+			return nil
+		},
+	})
+
+	for _, geo := range []agentbrowser.Geolocation{
+		{Latitude: 51.5074, Longitude: -0.1278},
+		{Latitude: -90, Longitude: 180, Accuracy: 10},
+		{},
+	} {
+		if err := m.SetGeolocation(&geo); err != nil {
+			t.Errorf("SetGeolocation(%+v) error = %v", geo, err)
+		} else if *set != geo {
+			t.Errorf("backend got %+v, want %+v", *set, geo)
+		}
+	}
+
+	before := calls
+	for _, geo := range []agentbrowser.Geolocation{
+		{Latitude: 90.5},
+		{Longitude: -181},
+		{Accuracy: -1},
+	} {
+		if err := m.SetGeolocation(&geo); err == nil {
+			t.Errorf("SetGeolocation(%+v) expected an error", geo)
+		}
+	}
+	if calls != before {
+		t.Errorf("backend called for invalid positions")
+	}
+
+	if err := m.SetGeolocation(nil); err != nil || set != nil {
+		t.Errorf("SetGeolocation(nil) = %v, backend got %+v", err, set)
+	}
+}
@@ -7,6 +7,9 @@ toolchain go1.24.11
 require (
 	github.com/chromedp/cdproto v0.0.0-20241022234722-4d5d5faf59fb
 	github.com/chromedp/chromedp v0.11.2
+	github.com/playwright-community/playwright-go v0.5200.1
+	github.com/sevlyar/go-daemon v0.1.6
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -20,7 +23,5 @@ require (
 	github.com/josharian/intern v1.0.0 // indirect
 	github.com/kardianos/osext v0.0.0-20190222173326-2bc1f35cddc0 // indirect
 	github.com/mailru/easyjson v0.7.7 // indirect
-	github.com/playwright-community/playwright-go v0.5200.1 // indirect
-	github.com/sevlyar/go-daemon v0.1.6 // indirect
 	golang.org/x/sys v0.26.0 // indirect
 )
@@ -0,0 +1,44 @@
+package agentbrowser
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SetExtraHeaders sends headers, such as Authorization or X-Request-ID,
+// with every request of every tab from now on, including tabs opened
+// later. They replace the headers set before; an empty map removes them.
+// Headers from the session config stay in effect, overridden by these.
+func (m *BrowserManager) SetExtraHeaders(headers map[string]string) error {
+	for name, value := range headers {
+		if !validHeaderName(name) {
+			return fmt.Errorf("invalid header name %q", name)
+		}
+		if strings.ContainsAny(value, "\r\n") {
+			return fmt.Errorf("invalid value for header %s: line breaks are not allowed", name)
+		}
+	}
+
+	s := &m.session
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	extra := mergeHeaders(nil, headers)
+	if err := m.backend.SetExtraHeaders(mergeHeaders(s.headers, extra)); err != nil {
+		return err
+	}
+	s.extraHeaders = extra
+	return nil
+}
+
+// validHeaderName reports whether name is an HTTP token (RFC 9110).
+func validHeaderName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, r := range name {
+		if !('a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' || '0' <= r && r <= '9' || strings.ContainsRune("!#$%&'*+-.^_`|~", r)) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,57 @@
+package agentbrowser_test
+
+import (
+	"reflect"
+	"testing"
+
+	agentbrowser "github.com/cpunion/agent-browser-go"
+)
+
+// TestSetExtraHeaders tests that command headers replace each other and
+// sit over the session config's
+func TestSetExtraHeaders(t *testing.T) {
+	applied := &appliedSettings{}
+	m := agentbrowser.NewBrowserManagerForTest(applied.backend())
+
+	if _, err := m.ApplySessionConfig(agentbrowser.SessionConfig{
+		Headers: map[string]string{"X-Request-ID": "config", "X-Team": "qa"},
+	}); err != nil {
+		t.Fatalf("ApplySessionConfig() error = %v", err)
+	}
+	if err := m.SetExtraHeaders(map[string]string{"Authorization": "Bearer abc", "X-Request-ID": "run-42"}); err != nil {
+		t.Fatalf("SetExtraHeaders() error = %v", err)
+	}
+	want := map[string]string{"authorization": "Bearer abc", "x-request-id": "run-42", "x-team": "qa"}
+	if !reflect.DeepEqual(applied.headers, want) {
+		t.Errorf("backend headers = %v, want %v", applied.headers, want)
+	}
+
+	// A config reload keeps the command's headers on top
+	if _, err := m.ApplySessionConfig(agentbrowser.SessionConfig{
+		Headers: map[string]string{"X-Request-ID": "config"},
+	}); err != nil {
+		t.Fatalf("ApplySessionConfig() error = %v", err)
+	}
+	want = map[string]string{"authorization": "Bearer abc", "x-request-id": "run-42"}
+	if !reflect.DeepEqual(applied.headers, want) {
+		t.Errorf("backend headers after reload = %v, want %v", applied.headers, want)
+	}
+
+	for _, headers := range []map[string]string{
+		{"": "x"},
+		{"X Bad": "x"},
+		{"X-Bad:": "x"},
+		{"X-Split": "a\r\nX-Injected: b"},
+	} {
+		if err := m.SetExtraHeaders(headers); err == nil {
+			t.Errorf("SetExtraHeaders(%q) expected an error", headers)
+		}
+	}
+
+	if err := m.SetExtraHeaders(nil); err != nil {
+		t.Fatalf("SetExtraHeaders(nil) error = %v", err)
+	}
+	if want := map[string]string{"x-request-id": "config"}; !reflect.DeepEqual(applied.headers, want) {
+		t.Errorf("backend headers after clearing = %v, want %v", applied.headers, want)
+	}
+}
@@ -0,0 +1,55 @@
+package agentbrowser
+
+import "fmt"
+
+// defaultHighlightDuration is how long a highlight stays, in ms.
+const defaultHighlightDuration = 3000
+
+// highlightElementScript is a JavaScript function (el, ms) that outlines el
+// with an overlay for ms milliseconds, replacing an earlier highlight. The
+// overlay ignores the pointer and follows the element while the page
+// scrolls. It returns false when el is missing.
+const highlightElementScript = `(el, ms) => {
+	if (!el) return false;
+	const id = '__agent_browser_highlight';
+	document.getElementById(id)?.remove();
+
+	el.scrollIntoView({ block: 'center', inline: 'center' });
+	const box = document.createElement('div');
+	box.id = id;
+	box.style.cssText = 'position:fixed;z-index:2147483647;pointer-events:none;box-sizing:border-box;' +
+		'border:3px solid #ff3e00;background:rgba(255,62,0,0.15);border-radius:3px;transition:all 0.1s';
+	const label = document.createElement('span');
+	label.textContent = el.tagName.toLowerCase() + (el.id ? '#' + el.id : '');
+	label.style.cssText = 'position:absolute;left:-3px;bottom:100%;padding:1px 4px;' +
+		'font:12px monospace;color:#fff;background:#ff3e00;white-space:nowrap';
+	box.appendChild(label);
+	document.documentElement.appendChild(box);
+
+	const place = () => {
+		if (!box.isConnected) return;
+		const r = el.getBoundingClientRect();
+		Object.assign(box.style, { left: r.left + 'px', top: r.top + 'px', width: r.width + 'px', height: r.height + 'px' });
+		requestAnimationFrame(place);
+	};
+	place();
+	setTimeout(() => box.remove(), ms);
+	return true;
+}`
+
+// Highlight outlines an element in the page for duration ms (0 uses 3000),
+// so a person watching a headed browser can see what an agent is about to
+// act on.
+func (m *BrowserManager) Highlight(selector string, duration int) error {
+	if duration < 0 {
+		return fmt.Errorf("invalid highlight duration %d", duration)
+	}
+	if duration == 0 {
+		duration = defaultHighlightDuration
+	}
+	selector, err := m.resolveRef(selector)
+	if err != nil {
+		return err
+	}
+	return m.backend.Highlight(selector, duration)
+}
@@ -0,0 +1,32 @@
+package agentbrowser_test
+
+import (
+	"testing"
+
+	agentbrowser "github.com/cpunion/agent-browser-go"
+)
+
+// TestHighlightDuration tests the highlight duration default
+func TestHighlightDuration(t *testing.T) {
+	var selector string
+	var duration int
+	m := agentbrowser.NewBrowserManagerForTest(&fakeBackend{
+		highlight: func(s string, d int) error {
+			selector, duration = s, d
+			return nil
+		},
+	})
+
+	if err := m.Highlight("#submit", 0); err != nil {
+		t.Fatalf("Highlight() error = %v", err)
+	}
+	if selector != "#submit" || duration != 3000 {
+		t.Errorf("backend got %q for %dms, want #submit for 3000ms", selector, duration)
+	}
+	if err := m.Highlight("#submit", 500); err != nil || duration != 500 {
+		t.Errorf("Highlight(500) = %v with %dms, want 500ms", err, duration)
+	}
+	if err := m.Highlight("#submit", -1); err == nil {
+		t.Error("Highlight(-1) expected an error")
+	}
+}
@@ -0,0 +1,37 @@
+package agentbrowser
+
+import "fmt"
+
+// NavigationHistory is the session history of the active tab.
+type NavigationHistory struct {
+	Current int            `json:"current"` // index of the current entry
+	Entries []HistoryEntry `json:"entries"`
+}
+
+// HistoryEntry is one entry of a tab's session history.
+type HistoryEntry struct {
+	Index int    `json:"index"`
+	URL   string `json:"url"`
+	Title string `json:"title"`
+}
+
+// History lists the navigation entries of the active tab.
+func (m *BrowserManager) History() (*NavigationHistory, error) {
+	return m.backend.History()
+}
+
+// HistoryGo navigates the active tab straight to the history entry at
+// index, however far back or forward it is.
+func (m *BrowserManager) HistoryGo(index int) error {
+	history, err := m.backend.History()
+	if err != nil {
+		return err
+	}
+	if index < 0 || index >= len(history.Entries) {
+		return fmt.Errorf("history index %d out of range (0-%d)", index, len(history.Entries)-1)
+	}
+	if index == history.Current {
+		return nil
+	}
+	return m.backend.GoToHistoryEntry(index)
+}
@@ -0,0 +1,46 @@
+package agentbrowser_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	agentbrowser "github.com/cpunion/agent-browser-go"
+)
+
+// TestHistoryGo tests jumping to history entries by index
+func TestHistoryGo(t *testing.T) {
+	history := agentbrowser.NavigationHistory{
+		Current: 1,
+		Entries: []agentbrowser.HistoryEntry{
+			{Index: 0, URL: "https://example.com/"},
+			{Index: 1, URL: "https://example.com/a"},
+			{Index: 2, URL: "https://example.com/b"},
+		},
+	}
+	var jumps []int
+	m := agentbrowser.NewBrowserManagerForTest(&fakeBackend{
+		history: func() (*agentbrowser.NavigationHistory, error) { return &history, nil },
+		goToHistoryEntry: func(index int) error {
+			jumps = append(jumps, index)
+			return nil
+		},
+	})
+
+	for _, index := range []int{0, 2, 1} {
+		if err := m.HistoryGo(index); err != nil {
+			t.Fatalf("HistoryGo(%d) error = %v", index, err)
+		}
+	}
+	// The current entry needs no navigation
+	if want := []int{0, 2}; !reflect.DeepEqual(jumps, want) {
+		t.Errorf("jumps = %v, want %v", jumps, want)
+	}
+
+	for _, index := range []int{-1, 3} {
+		err := m.HistoryGo(index)
+		if err == nil || !strings.Contains(err.Error(), "out of range (0-2)") {
+			t.Errorf("HistoryGo(%d) error = %v, want out of range", index, err)
+		}
+	}
+}
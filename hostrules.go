@@ -0,0 +1,137 @@
+package agentbrowser
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// ParseHostRule validates and normalizes a host resolver rule:
+//
+//	MAP <host-pattern> <replacement>   resolve matching hosts to the replacement
+//	EXCLUDE <host-pattern>             keep normal resolution for matching hosts
+//
+// Host patterns may use * wildcards, e.g. "MAP *.example.com 127.0.0.1".
+// The replacement is a host or IP, optionally with a port.
+func ParseHostRule(rule string) (string, error) {
+	fields := strings.Fields(rule)
+	if len(fields) == 0 {
+		return "", fmt.Errorf("empty host rule")
+	}
+	if strings.Contains(rule, ",") {
+		return "", fmt.Errorf("invalid host rule %q: one rule per --host-rule", rule)
+	}
+
+	switch strings.ToUpper(fields[0]) {
+	case "MAP":
+		if len(fields) != 3 {
+			return "", fmt.Errorf("invalid host rule %q: expected MAP <host> <target>", rule)
+		}
+		return "MAP " + fields[1] + " " + fields[2], nil
+	case "EXCLUDE":
+		if len(fields) != 2 {
+			return "", fmt.Errorf("invalid host rule %q: expected EXCLUDE <host>", rule)
+		}
+		return "EXCLUDE " + fields[1], nil
+	default:
+		return "", fmt.Errorf("invalid host rule %q: must start with MAP or EXCLUDE", rule)
+	}
+}
+
+// hostRulesDialer returns a DialContext that connects where the host rules
+// send the host, as Chrome's --host-resolver-rules do, for requests made on
+// the browser's behalf. It returns nil when there are no rules.
+func hostRulesDialer(rules []string) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	if len(rules) == 0 {
+		return nil
+	}
+	var dialer net.Dialer
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		mapped, err := mapHost(rules, addr)
+		if err != nil {
+			return nil, err
+		}
+		return dialer.DialContext(ctx, network, mapped)
+	}
+}
+
+// mapHost returns the address the first MAP rule matching addr's host
+// sends it to, keeping the port unless the rule gives one. A host matched
+// by an EXCLUDE rule is not mapped. Mapping to ~NOTFOUND fails the lookup.
+func mapHost(rules []string, addr string) (string, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return "", err
+	}
+	host = strings.ToLower(host)
+	for _, rule := range rules {
+		fields := strings.Fields(rule)
+		if len(fields) == 2 && fields[0] == "EXCLUDE" {
+			if matched, _ := path.Match(strings.ToLower(fields[1]), host); matched {
+				return addr, nil
+			}
+		}
+	}
+	for _, rule := range rules {
+		fields := strings.Fields(rule)
+		if len(fields) != 3 || fields[0] != "MAP" {
+			continue
+		}
+		if matched, _ := path.Match(strings.ToLower(fields[1]), host); !matched {
+			continue
+		}
+		target := fields[2]
+		if target == "~NOTFOUND" {
+			return "", &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+		}
+		if _, _, err := net.SplitHostPort(target); err == nil {
+			return target, nil
+		}
+		return net.JoinHostPort(strings.Trim(target, "[]"), port), nil
+	}
+	return addr, nil
+}
+
+// hostResolverRulesArg returns the Chromium switch value for host rules.
+func hostResolverRulesArg(rules []string) string {
+	return strings.Join(rules, ", ")
+}
+
+// sameHostRules reports whether two rule lists are equal.
+func sameHostRules(a, b []string) bool {
+	return slices.Equal(a, b)
+}
+
+// GetHostRulesFile returns the host rules file path for a session.
+func GetHostRulesFile(session string) string {
+	dir := filepath.Join(os.TempDir(), "agent-browser-go")
+	_ = os.MkdirAll(dir, 0755)
+	return filepath.Join(dir, fmt.Sprintf("%s.hostrules", session))
+}
+
+// SaveSessionHostRules saves the host rules used when the session's browser
+// launches, one per line.
+func SaveSessionHostRules(session string, rules []string) error {
+	return os.WriteFile(GetHostRulesFile(session), []byte(strings.Join(rules, "\n")), 0644)
+}
+
+// GetSessionHostRules retrieves the saved host rules for a session.
+// Returns nil if none are saved.
+func GetSessionHostRules(session string) []string {
+	data, err := os.ReadFile(GetHostRulesFile(session))
+	if err != nil {
+		return nil
+	}
+	var rules []string
+	for _, line := range strings.Split(string(data), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			rules = append(rules, line)
+		}
+	}
+	return rules
+}
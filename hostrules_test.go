@@ -0,0 +1,60 @@
+package agentbrowser_test
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	agentbrowser "github.com/cpunion/agent-browser-go"
+)
+
+// TestParseHostRule tests validation and normalization of host resolver rules
+func TestParseHostRule(t *testing.T) {
+	tests := []struct {
+		rule    string
+		want    string
+		wantErr bool
+	}{
+		{rule: "MAP example.com 127.0.0.1", want: "MAP example.com 127.0.0.1"},
+		{rule: "  map  *.example.com   staging.internal:8443 ", want: "MAP *.example.com staging.internal:8443"},
+		{rule: "EXCLUDE cdn.example.com", want: "EXCLUDE cdn.example.com"},
+		{rule: "", wantErr: true},
+		{rule: "MAP example.com", wantErr: true},
+		{rule: "EXCLUDE a.com b.com", wantErr: true},
+		{rule: "REDIRECT example.com 127.0.0.1", wantErr: true},
+		{rule: "MAP a.com 1.2.3.4, MAP b.com 1.2.3.4", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.rule, func(t *testing.T) {
+			got, err := agentbrowser.ParseHostRule(tt.rule)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseHostRule() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseHostRule() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestSessionHostRules tests saving and reading a session's host rules
+func TestSessionHostRules(t *testing.T) {
+	session := "test-hostrules-" + t.Name()
+	t.Cleanup(func() { os.Remove(agentbrowser.GetHostRulesFile(session)) })
+
+	rules := []string{"MAP example.com 127.0.0.1", "EXCLUDE cdn.example.com"}
+	if err := agentbrowser.SaveSessionHostRules(session, rules); err != nil {
+		t.Fatalf("SaveSessionHostRules() error = %v", err)
+	}
+	if got := agentbrowser.GetSessionHostRules(session); !reflect.DeepEqual(got, rules) {
+		t.Errorf("GetSessionHostRules() = %q, want %q", got, rules)
+	}
+
+	if err := agentbrowser.SaveSessionHostRules(session, nil); err != nil {
+		t.Fatal(err)
+	}
+	if got := agentbrowser.GetSessionHostRules(session); got != nil {
+		t.Errorf("GetSessionHostRules() after reset = %q, want nil", got)
+	}
+}
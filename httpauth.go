@@ -0,0 +1,65 @@
+package agentbrowser
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// HTTPCredentials answer HTTP authentication challenges (basic, digest,
+// NTLM), from servers or proxies.
+type HTTPCredentials struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+	Origin   string `json:"origin,omitempty"` // only answer this origin, e.g. https://intranet.example.com
+}
+
+// matches reports whether the credentials may be sent to an origin.
+func (c *HTTPCredentials) matches(origin string) bool {
+	return c.Origin == "" || strings.EqualFold(strings.TrimSuffix(c.Origin, "/"), origin)
+}
+
+// sameHTTPCredentials reports whether two credentials are equal.
+func sameHTTPCredentials(a, b *HTTPCredentials) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// playwrightHTTPCredentials converts credentials to the Playwright option.
+func playwrightHTTPCredentials(c *HTTPCredentials) *playwright.HttpCredentials {
+	if c == nil {
+		return nil
+	}
+	creds := &playwright.HttpCredentials{Username: c.Username, Password: c.Password}
+	if c.Origin != "" {
+		creds.Origin = playwright.String(c.Origin)
+	}
+	return creds
+}
+
+// SetHTTPCredentials makes every tab, including tabs opened later, answer
+// HTTP authentication challenges (basic, digest, NTLM) with creds, so pages
+// behind them open. nil removes them; challenges are then cancelled and the
+// 401 page loads. They are kept when the browser relaunches.
+func (m *BrowserManager) SetHTTPCredentials(creds *HTTPCredentials) error {
+	if creds != nil {
+		if creds.Username == "" {
+			return fmt.Errorf("credentials need a username")
+		}
+		if creds.Origin != "" {
+			u, err := url.Parse(creds.Origin)
+			if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || strings.Trim(u.Path, "/") != "" {
+				return fmt.Errorf("invalid origin %q (expected scheme://host[:port], e.g. https://intranet.example.com)", creds.Origin)
+			}
+		}
+	}
+	if err := m.backend.SetHTTPCredentials(creds); err != nil {
+		return err
+	}
+	m.httpCredentials = creds
+	return nil
+}
@@ -0,0 +1,84 @@
+package agentbrowser_test
+
+import (
+	"testing"
+
+	agentbrowser "github.com/cpunion/agent-browser-go"
+)
+
+// TestHTTPCredentialsMatch tests origin scoping of HTTP credentials
+func TestHTTPCredentialsMatch(t *testing.T) {
+	tests := []struct {
+		name   string
+		origin string
+		want   bool
+	}{
+		{"", "https://intranet.example.com", true},
+		{"https://intranet.example.com", "https://intranet.example.com", true},
+		{"https://intranet.example.com/", "https://intranet.example.com", true},
+		{"https://Intranet.example.com", "https://intranet.example.com", true},
+		{"https://intranet.example.com", "http://intranet.example.com", false},
+		{"https://intranet.example.com", "https://intranet.example.com:8443", false},
+		{"https://intranet.example.com", "https://evil.example.com", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name+" "+tt.origin, func(t *testing.T) {
+			c := &agentbrowser.HTTPCredentials{Username: "u", Password: "p", Origin: tt.name}
+			if got := agentbrowser.HTTPCredentialsMatch(c, tt.origin); got != tt.want {
+				t.Errorf("matches(%q) = %v, want %v", tt.origin, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestSetHTTPCredentials tests validation and that credentials outlive a
+// relaunch
+func TestSetHTTPCredentials(t *testing.T) {
+	var set, launched *agentbrowser.HTTPCredentials
+	m := agentbrowser.NewBrowserManagerForTest(&fakeBackend{
+		setHTTPCredentials: func(creds *agentbrowser.HTTPCredentials) error {
+			set = creds
+			return nil
+		},
+		launch: func(opts agentbrowser.LaunchOptions) error {
+			launched = opts.HTTPCredentials
+			return nil
+		},
+	})
+
+	for _, creds := range []agentbrowser.HTTPCredentials{
+		{Password: "p"},
+		{Username: "u", Origin: "intranet.example.com"},
+		{Username: "u", Origin: "ftp://intranet.example.com"},
+		{Username: "u", Origin: "https://intranet.example.com/login"},
+	} {
+		if err := m.SetHTTPCredentials(&creds); err == nil {
+			t.Errorf("SetHTTPCredentials(%+v) expected an error", creds)
+		}
+	}
+	if set != nil {
+		t.Errorf("backend got invalid credentials %+v", *set)
+	}
+
+	creds := &agentbrowser.HTTPCredentials{Username: "admin", Password: "secret", Origin: "http://localhost:8080/"}
+	if err := m.SetHTTPCredentials(creds); err != nil {
+		t.Fatalf("SetHTTPCredentials() error = %v", err)
+	}
+	if set != creds {
+		t.Errorf("backend got %+v, want %+v", set, creds)
+	}
+	if err := m.Launch(agentbrowser.LaunchOptions{Headless: true}); err != nil {
+		t.Fatalf("Launch() error = %v", err)
+	}
+	if launched != creds {
+		t.Errorf("launched with %+v, want %+v", launched, creds)
+	}
+
+	if err := m.SetHTTPCredentials(nil); err != nil || set != nil {
+		t.Errorf("SetHTTPCredentials(nil) = %v, backend got %+v", err, set)
+	}
+	if err := m.Launch(agentbrowser.LaunchOptions{Headless: true}); err != nil || launched != nil {
+		t.Errorf("Launch() = %v, launched with %+v after clearing", err, launched)
+	}
+}
@@ -0,0 +1,66 @@
+package agentbrowser
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// AddInitScript runs script before any page script in every document loaded
+// from now on, in every tab. Adding a script that is already registered
+// does nothing.
+func (m *BrowserManager) AddInitScript(script string) error {
+	if strings.TrimSpace(script) == "" {
+		return fmt.Errorf("init script is empty")
+	}
+
+	s := &m.session
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if slices.Contains(s.initScripts, script) || slices.Contains(s.addedScripts, script) {
+		return nil
+	}
+	if err := m.backend.AddInitScript(script); err != nil {
+		return err
+	}
+	s.addedScripts = append(s.addedScripts, script)
+	return nil
+}
+
+// GetInitScriptsFile returns the init scripts file path for a session.
+func GetInitScriptsFile(session string) string {
+	dir := filepath.Join(os.TempDir(), "agent-browser-go")
+	_ = os.MkdirAll(dir, 0755)
+	return filepath.Join(dir, fmt.Sprintf("%s.initscripts", session))
+}
+
+// AddSessionInitScript saves an init script for the session, so a restarted
+// daemon registers it again. Saving a script twice keeps one copy.
+func AddSessionInitScript(session, script string) error {
+	scripts := GetSessionInitScripts(session)
+	if slices.Contains(scripts, script) {
+		return nil
+	}
+	data, err := json.Marshal(append(scripts, script))
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(GetInitScriptsFile(session), data, 0644)
+}
+
+// GetSessionInitScripts retrieves the saved init scripts for a session, in
+// the order they were added. Returns nil if none are saved.
+func GetSessionInitScripts(session string) []string {
+	data, err := os.ReadFile(GetInitScriptsFile(session))
+	if err != nil {
+		return nil
+	}
+	var scripts []string
+	if err := json.Unmarshal(data, &scripts); err != nil {
+		return nil
+	}
+	return scripts
+}
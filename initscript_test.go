@@ -0,0 +1,56 @@
+package agentbrowser_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	agentbrowser "github.com/cpunion/agent-browser-go"
+)
+
+// TestAddInitScript tests that each script is added to the browser once,
+// including scripts already added from the config file
+func TestAddInitScript(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.js")
+	if err := os.WriteFile(path, []byte("window.config = 1"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	applied := &appliedSettings{}
+	m := agentbrowser.NewBrowserManagerForTest(applied.backend())
+	if _, err := m.ApplySessionConfig(agentbrowser.SessionConfig{InitScripts: []string{path}}); err != nil {
+		t.Fatalf("ApplySessionConfig() error = %v", err)
+	}
+
+	for _, script := range []string{"window.a = 1", "window.a = 1", "window.config = 1"} {
+		if err := m.AddInitScript(script); err != nil {
+			t.Fatalf("AddInitScript(%q) error = %v", script, err)
+		}
+	}
+	if err := m.AddInitScript("  "); err == nil {
+		t.Error("AddInitScript() with an empty script expected an error")
+	}
+	if want := []string{"window.config = 1", "window.a = 1"}; !reflect.DeepEqual(applied.initScripts, want) {
+		t.Errorf("backend init scripts = %v, want %v", applied.initScripts, want)
+	}
+}
+
+// TestSessionInitScripts tests saving init scripts for a session
+func TestSessionInitScripts(t *testing.T) {
+	session := fmt.Sprintf("test-initscripts-%d", time.Now().UnixNano())
+	t.Cleanup(func() { os.Remove(agentbrowser.GetInitScriptsFile(session)) })
+
+	if scripts := agentbrowser.GetSessionInitScripts(session); scripts != nil {
+		t.Errorf("GetSessionInitScripts() = %v, want nil for a new session", scripts)
+	}
+	for _, script := range []string{"window.a = 1", "window.b = `\n`", "window.a = 1"} {
+		if err := agentbrowser.AddSessionInitScript(session, script); err != nil {
+			t.Fatalf("AddSessionInitScript() error = %v", err)
+		}
+	}
+	if want := []string{"window.a = 1", "window.b = `\n`"}; !reflect.DeepEqual(agentbrowser.GetSessionInitScripts(session), want) {
+		t.Errorf("GetSessionInitScripts() = %v, want %v", agentbrowser.GetSessionInitScripts(session), want)
+	}
+}
@@ -0,0 +1,56 @@
+package agentbrowser
+
+import "fmt"
+
+// addTagScript is a JavaScript function (tag, url, content) that appends a
+// <script> or <style>/<link rel=stylesheet> to the document, and resolves
+// once an external file has loaded or rejects when it fails to load.
+const addTagScript = `(tag, url, content) => new Promise((resolve, reject) => {
+	let el;
+	if (tag === 'script') {
+		el = document.createElement('script');
+		if (url) el.src = url;
+		else el.textContent = content;
+	} else if (url) {
+		el = document.createElement('link');
+		el.rel = 'stylesheet';
+		el.href = url;
+	} else {
+		el = document.createElement('style');
+		el.textContent = content;
+	}
+	if (url) {
+		el.onload = () => resolve(true);
+		el.onerror = () => reject(new Error('failed to load ' + url));
+	}
+	(document.head || document.documentElement).appendChild(el);
+	if (!url) resolve(true);
+})`
+
+// checkTagSource checks that exactly one of url or content is given.
+func checkTagSource(tag, url, content string) error {
+	if (url == "") == (content == "") {
+		return fmt.Errorf("%s needs either a url or content", tag)
+	}
+	return nil
+}
+
+// AddScriptTag adds a <script> to the current page, loaded from url or
+// with inline content, e.g. helper functions for later evaluate calls. It
+// returns once the script has run. Unlike AddInitScript it does not
+// survive navigation.
+func (m *BrowserManager) AddScriptTag(url, content string) error {
+	if err := checkTagSource("script", url, content); err != nil {
+		return err
+	}
+	return m.backend.AddScriptTag(url, content)
+}
+
+// AddStyleTag adds a stylesheet to the current page, linked from url or
+// with inline content.
+func (m *BrowserManager) AddStyleTag(url, content string) error {
+	if err := checkTagSource("style", url, content); err != nil {
+		return err
+	}
+	return m.backend.AddStyleTag(url, content)
+}
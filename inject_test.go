@@ -0,0 +1,38 @@
+package agentbrowser_test
+
+import (
+	"testing"
+
+	agentbrowser "github.com/cpunion/agent-browser-go"
+)
+
+// TestAddTagSource tests that a tag needs exactly one of a URL or content
+func TestAddTagSource(t *testing.T) {
+	var tags []string
+	m := agentbrowser.NewBrowserManagerForTest(&fakeBackend{
+		addScriptTag: func(url, content string) error {
+			tags = append(tags, "script:"+url+content)
+			return nil
+		},
+		addStyleTag: func(url, content string) error {
+			tags = append(tags, "style:"+url+content)
+			return nil
+		},
+	})
+
+	if err := m.AddScriptTag("", "window.x = 1"); err != nil {
+		t.Errorf("AddScriptTag(content) error = %v", err)
+	}
+	if err := m.AddStyleTag("https://example.com/a.css", ""); err != nil {
+		t.Errorf("AddStyleTag(url) error = %v", err)
+	}
+	if err := m.AddScriptTag("", ""); err == nil {
+		t.Error("AddScriptTag() without a source expected an error")
+	}
+	if err := m.AddStyleTag("https://example.com/a.css", "p {}"); err == nil {
+		t.Error("AddStyleTag() with both a url and content expected an error")
+	}
+	if len(tags) != 2 {
+		t.Errorf("backend got %v, want 2 tags", tags)
+	}
+}
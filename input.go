@@ -0,0 +1,291 @@
+package agentbrowser
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/chromedp/cdproto/input"
+)
+
+// MouseEvent is a raw mouse event at viewport coordinates, for pages such
+// as canvas apps that have no elements to select.
+type MouseEvent struct {
+	Type       string // mousePressed, mouseReleased, mouseMoved or mouseWheel
+	X, Y       float64
+	Button     string // none, left, middle, right, back or forward
+	Buttons    int    // buttons held down: left 1, right 2, middle 4, back 8, forward 16
+	ClickCount int
+	DeltaX     float64 // wheel scroll in pixels
+	DeltaY     float64
+	Modifiers  int // Alt 1, Control 2, Meta 4, Shift 8
+}
+
+// KeyEvent is a raw key event, for games and editors that react to key
+// down and up separately.
+type KeyEvent struct {
+	Type      string // keyDown, keyUp or char
+	Key       string // key name such as a, Enter or Shift, or a code such as KeyA
+	Code      string // physical key, e.g. KeyA; derived from Key if empty
+	Text      string // text a keyDown or char event types; derived from Key if empty
+	Modifiers int    // Alt 1, Control 2, Meta 4, Shift 8
+}
+
+// mouseEventTypes maps the short event type names the CLI uses to CDP's.
+var mouseEventTypes = map[string]string{
+	"down":          "mousePressed",
+	"up":            "mouseReleased",
+	"move":          "mouseMoved",
+	"wheel":         "mouseWheel",
+	"mousePressed":  "mousePressed",
+	"mouseReleased": "mouseReleased",
+	"mouseMoved":    "mouseMoved",
+	"mouseWheel":    "mouseWheel",
+}
+
+// mouseButtons maps button names to their bit in MouseEvent.Buttons.
+var mouseButtons = map[string]int{
+	"none":    0,
+	"left":    1,
+	"right":   2,
+	"middle":  4,
+	"back":    8,
+	"forward": 16,
+}
+
+// InputMouse dispatches a raw mouse event to the active tab. Type may also
+// be down, up, move or wheel. Pressed and released events default to the
+// left button and a click count of 1. Buttons pressed this way stay held
+// for later events until they are released, so a press, moves and a
+// release drag. Modifier keys held with InputKeyboard apply too.
+func (m *BrowserManager) InputMouse(ev MouseEvent) error {
+	typ, ok := mouseEventTypes[ev.Type]
+	if !ok {
+		return fmt.Errorf("unknown mouse event type %q (expected down, up, move or wheel)", ev.Type)
+	}
+	ev.Type = typ
+	click := typ == "mousePressed" || typ == "mouseReleased"
+	if ev.Button == "" {
+		ev.Button = "none"
+		if click {
+			ev.Button = "left"
+		}
+	}
+	bit, ok := mouseButtons[ev.Button]
+	if !ok {
+		return fmt.Errorf("unknown mouse button %q (expected left, right, middle, back or forward)", ev.Button)
+	}
+	if click && ev.ClickCount == 0 {
+		ev.ClickCount = 1
+	}
+	if ev.Modifiers < 0 || ev.Modifiers > 15 {
+		return fmt.Errorf("modifiers must be a bit field of Alt 1, Control 2, Meta 4 and Shift 8")
+	}
+
+	m.inputLock.Lock()
+	defer m.inputLock.Unlock()
+	buttons := m.mouseButtons
+	switch typ {
+	case "mousePressed":
+		buttons |= bit
+	case "mouseReleased":
+		buttons &^= bit
+	}
+	if ev.Buttons == 0 {
+		ev.Buttons = buttons
+	}
+	ev.Modifiers |= m.heldModifiers
+	if err := m.backend.InputMouse(ev); err != nil {
+		return err
+	}
+	m.mouseX, m.mouseY = ev.X, ev.Y
+	m.mouseButtons = buttons
+	return nil
+}
+
+// MouseMove moves the mouse to x, y in steps equal moves from where it
+// is, for pages that follow the path, such as drawing apps and custom
+// sliders. Buttons held with MouseDown drag.
+func (m *BrowserManager) MouseMove(x, y float64, steps int) error {
+	if steps < 1 {
+		steps = 1
+	}
+	fromX, fromY := m.mousePosition()
+	for i := 1; i <= steps; i++ {
+		f := float64(i) / float64(steps)
+		if err := m.InputMouse(MouseEvent{Type: "mouseMoved", X: fromX + (x-fromX)*f, Y: fromY + (y-fromY)*f}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MouseDown presses a mouse button, left when empty, where the mouse is.
+func (m *BrowserManager) MouseDown(button string) error {
+	x, y := m.mousePosition()
+	return m.InputMouse(MouseEvent{Type: "mousePressed", X: x, Y: y, Button: button})
+}
+
+// MouseUp releases a mouse button, left when empty, where the mouse is.
+func (m *BrowserManager) MouseUp(button string) error {
+	x, y := m.mousePosition()
+	return m.InputMouse(MouseEvent{Type: "mouseReleased", X: x, Y: y, Button: button})
+}
+
+// Wheel turns the mouse wheel to scroll by deltaX, deltaY pixels over the
+// center of an element, scrolled into view first, or where the mouse is
+// when selector is empty. Unlike Scroll it sends real wheel events, which
+// virtualized lists and map widgets need.
+func (m *BrowserManager) Wheel(deltaX, deltaY float64, selector string) error {
+	if deltaX == 0 && deltaY == 0 {
+		return fmt.Errorf("wheel needs deltaX or deltaY")
+	}
+	x, y := m.mousePosition()
+	if selector != "" {
+		if err := m.ScrollIntoView(selector); err != nil {
+			return err
+		}
+		box, err := m.GetBoundingBox(selector)
+		if err != nil {
+			return err
+		}
+		x, y = box.X+box.Width/2, box.Y+box.Height/2
+	}
+	return m.InputMouse(MouseEvent{Type: "mouseWheel", X: x, Y: y, DeltaX: deltaX, DeltaY: deltaY})
+}
+
+// mousePosition returns where the last mouse event put the mouse, or the
+// top left corner.
+func (m *BrowserManager) mousePosition() (x, y float64) {
+	m.inputLock.Lock()
+	defer m.inputLock.Unlock()
+	return m.mouseX, m.mouseY
+}
+
+// InputKeyboard dispatches a raw key event to the focused element of the
+// active tab. Modifier keys pressed this way stay held for later key and
+// mouse events until they are released, so keyDown Shift, keyDown a types
+// "A".
+func (m *BrowserManager) InputKeyboard(ev KeyEvent) error {
+	switch ev.Type {
+	case "keyDown", "keyUp", "char":
+	default:
+		return fmt.Errorf("unknown key event type %q (expected keyDown, keyUp or char)", ev.Type)
+	}
+	if ev.Modifiers < 0 || ev.Modifiers > 15 {
+		return fmt.Errorf("modifiers must be a bit field of Alt 1, Control 2, Meta 4 and Shift 8")
+	}
+
+	m.inputLock.Lock()
+	defer m.inputLock.Unlock()
+	held := m.heldModifiers
+	name := ev.Key
+	if name == "" {
+		name = ev.Code
+	}
+	if k, ok := namedKeys[name]; ok {
+		switch ev.Type {
+		case "keyDown":
+			held |= int(keyModifiers[k.Key])
+		case "keyUp":
+			held &^= int(keyModifiers[k.Key])
+		}
+	}
+	ev.Modifiers |= held
+	if _, err := keyEventParams(ev); err != nil {
+		return err
+	}
+	if err := m.backend.InputKeyboard(ev); err != nil {
+		return err
+	}
+	m.heldModifiers = held
+	return nil
+}
+
+// ParseModifiers parses modifier names joined by "+" or ",", such as
+// "Control+Shift", into the bit field of MouseEvent.Modifiers.
+func ParseModifiers(names string) (int, error) {
+	modifiers := 0
+	for _, name := range strings.FieldsFunc(names, func(r rune) bool { return r == '+' || r == ',' }) {
+		bit, ok := keyModifiers[name]
+		if !ok {
+			return 0, fmt.Errorf("unknown modifier %q (expected Alt, Control, Meta or Shift)", name)
+		}
+		modifiers |= int(bit)
+	}
+	return modifiers, nil
+}
+
+// mouseEventParams returns the Input.dispatchMouseEvent parameters for ev.
+func mouseEventParams(ev MouseEvent) *input.DispatchMouseEventParams {
+	params := input.DispatchMouseEvent(input.MouseType(ev.Type), ev.X, ev.Y).
+		WithButton(input.MouseButton(ev.Button)).
+		WithButtons(int64(ev.Buttons)).
+		WithModifiers(input.Modifier(ev.Modifiers))
+	if ev.ClickCount > 0 {
+		params = params.WithClickCount(int64(ev.ClickCount))
+	}
+	if ev.Type == "mouseWheel" {
+		params = params.WithDeltaX(ev.DeltaX).WithDeltaY(ev.DeltaY)
+	}
+	return params
+}
+
+// keyEventParams returns the Input.dispatchKeyEvent parameters for ev.
+func keyEventParams(ev KeyEvent) (*input.DispatchKeyEventParams, error) {
+	modifiers := input.Modifier(ev.Modifiers)
+	if ev.Type == "char" {
+		text := ev.Text
+		if text == "" && utf8.RuneCountInString(ev.Key) == 1 {
+			text = ev.Key
+		}
+		if text == "" {
+			return nil, fmt.Errorf("char event needs text")
+		}
+		return &input.DispatchKeyEventParams{Type: input.KeyChar, Text: text, UnmodifiedText: text, Modifiers: modifiers}, nil
+	}
+
+	name := ev.Key
+	if name == "" {
+		name = ev.Code
+	}
+	if name == "" {
+		return nil, fmt.Errorf("key event needs a key or code")
+	}
+	k, err := lookupKey(name, modifiers&input.ModifierShift != 0)
+	if err != nil {
+		return nil, err
+	}
+	var text string
+	if ev.Type == "keyDown" {
+		text = ev.Text
+		// Text is only produced with no modifiers other than Shift
+		if text == "" && modifiers&^input.ModifierShift == 0 {
+			text = k.Text
+		}
+	}
+	typ := input.KeyType(ev.Type)
+	if typ == input.KeyDown && text == "" {
+		typ = input.KeyRawDown
+	}
+	params := keyEvent(typ, k, text, modifiers)
+	if ev.Code != "" {
+		params.Code = ev.Code
+	}
+	return params, nil
+}
+
+// cdpParams converts typed CDP parameters to the map a Playwright CDP
+// session sends.
+func cdpParams(v interface{}) (map[string]interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var params map[string]interface{}
+	if err := json.Unmarshal(data, &params); err != nil {
+		return nil, err
+	}
+	return params, nil
+}
@@ -0,0 +1,222 @@
+package agentbrowser_test
+
+import (
+	"fmt"
+	"testing"
+
+	agentbrowser "github.com/cpunion/agent-browser-go"
+)
+
+// inputBackend is a fake backend that appends the raw input it is sent to
+// *mouse and *keys. Every element is at (100, 200), 50 by 20.
+func inputBackend(mouse *[]agentbrowser.MouseEvent, keys *[]agentbrowser.KeyEvent) *fakeBackend {
+	return &fakeBackend{
+		scrollIntoView: func(selector string) error { return nil },
+		getBoundingBox: func(selector string) (*agentbrowser.BoundingBox, error) {
+			return &agentbrowser.BoundingBox{X: 100, Y: 200, Width: 50, Height: 20}, nil
+		},
+		inputKeyboard: func(ev agentbrowser.KeyEvent) error {
+			*keys = append(*keys, ev)
+			return nil
+		},
+		inputMouse: func(ev agentbrowser.MouseEvent) error {
+			*mouse = append(*mouse, ev)
+			return nil
+		},
+	}
+}
+
+// TestInputMouse tests event defaults and that pressed buttons stay held
+// until released
+func TestInputMouse(t *testing.T) {
+	var mouse []agentbrowser.MouseEvent
+	var keys []agentbrowser.KeyEvent
+	m := agentbrowser.NewBrowserManagerForTest(inputBackend(&mouse, &keys))
+
+	events := []agentbrowser.MouseEvent{
+		{Type: "down", X: 10, Y: 20},
+		{Type: "move", X: 30, Y: 20},
+		{Type: "mousePressed", X: 30, Y: 20, Button: "right"},
+		{Type: "up", X: 30, Y: 20},
+		{Type: "wheel", X: 30, Y: 20, DeltaY: 100},
+	}
+	for _, ev := range events {
+		if err := m.InputMouse(ev); err != nil {
+			t.Fatalf("InputMouse(%+v) error = %v", ev, err)
+		}
+	}
+
+	want := []agentbrowser.MouseEvent{
+		{Type: "mousePressed", X: 10, Y: 20, Button: "left", Buttons: 1, ClickCount: 1},
+		{Type: "mouseMoved", X: 30, Y: 20, Button: "none", Buttons: 1},
+		{Type: "mousePressed", X: 30, Y: 20, Button: "right", Buttons: 3, ClickCount: 1},
+		{Type: "mouseReleased", X: 30, Y: 20, Button: "left", Buttons: 2, ClickCount: 1},
+		{Type: "mouseWheel", X: 30, Y: 20, Button: "none", Buttons: 2, DeltaY: 100},
+	}
+	if len(mouse) != len(want) {
+		t.Fatalf("backend got %d events, want %d", len(mouse), len(want))
+	}
+	for i := range want {
+		if mouse[i] != want[i] {
+			t.Errorf("event %d = %+v, want %+v", i, mouse[i], want[i])
+		}
+	}
+
+	for _, ev := range []agentbrowser.MouseEvent{{Type: "click"}, {Type: "down", Button: "side"}, {Type: "move", Modifiers: 16}} {
+		if err := m.InputMouse(ev); err == nil {
+			t.Errorf("InputMouse(%+v) expected an error", ev)
+		}
+	}
+}
+
+// TestParseModifiers tests parsing modifier names into CDP modifier bits
+func TestParseModifiers(t *testing.T) {
+	tests := []struct {
+		names string
+		want  int
+	}{
+		{"", 0},
+		{"Alt", 1},
+		{"Control+Shift", 10},
+		{"Meta,Shift", 12},
+	}
+	for _, tt := range tests {
+		if got, err := agentbrowser.ParseModifiers(tt.names); err != nil || got != tt.want {
+			t.Errorf("ParseModifiers(%q) = %d, %v; want %d", tt.names, got, err, tt.want)
+		}
+	}
+	if _, err := agentbrowser.ParseModifiers("Ctrl"); err == nil {
+		t.Error("ParseModifiers(Ctrl) expected an error")
+	}
+}
+
+// TestInputKeyboard tests that modifier keys stay held until released and
+// apply to mouse events too
+func TestInputKeyboard(t *testing.T) {
+	var mouse []agentbrowser.MouseEvent
+	var keys []agentbrowser.KeyEvent
+	m := agentbrowser.NewBrowserManagerForTest(inputBackend(&mouse, &keys))
+
+	for _, ev := range []agentbrowser.KeyEvent{
+		{Type: "keyDown", Key: "Shift"},
+		{Type: "keyDown", Key: "a"},
+		{Type: "keyUp", Key: "a"},
+		{Type: "keyUp", Key: "Shift"},
+		{Type: "char", Text: "é"},
+	} {
+		if err := m.InputKeyboard(ev); err != nil {
+			t.Fatalf("InputKeyboard(%+v) error = %v", ev, err)
+		}
+	}
+	var modifiers []int
+	for _, ev := range keys {
+		modifiers = append(modifiers, ev.Modifiers)
+	}
+	if got, want := fmt.Sprint(modifiers), "[8 8 8 0 0]"; got != want {
+		t.Errorf("modifiers = %s, want %s", got, want)
+	}
+
+	if err := m.InputKeyboard(agentbrowser.KeyEvent{Type: "keyDown", Key: "Control"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.InputMouse(agentbrowser.MouseEvent{Type: "down"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := mouse[0].Modifiers; got != 2 {
+		t.Errorf("mouse modifiers = %d, want 2 while Control is held", got)
+	}
+
+	for _, ev := range []agentbrowser.KeyEvent{{Type: "press", Key: "a"}, {Type: "keyDown", Key: "Hyperdrive"}, {Type: "char"}} {
+		if err := m.InputKeyboard(ev); err == nil {
+			t.Errorf("InputKeyboard(%+v) expected an error", ev)
+		}
+	}
+}
+
+// TestKeyEventParams tests the CDP events raw key events become
+func TestKeyEventParams(t *testing.T) {
+	tests := []struct {
+		ev   agentbrowser.KeyEvent
+		want string
+	}{
+		{agentbrowser.KeyEvent{Type: "keyDown", Key: "a"}, `keyDown a KeyA "a" 0`},
+		{agentbrowser.KeyEvent{Type: "keyDown", Key: "a", Modifiers: 8}, `keyDown A KeyA "A" 8`},
+		{agentbrowser.KeyEvent{Type: "keyDown", Key: "a", Modifiers: 2}, `rawKeyDown a KeyA "" 2`},
+		{agentbrowser.KeyEvent{Type: "keyDown", Key: "ArrowLeft"}, `rawKeyDown ArrowLeft ArrowLeft "" 0`},
+		{agentbrowser.KeyEvent{Type: "keyDown", Code: "KeyW", Key: "z"}, `keyDown z KeyW "z" 0`},
+		{agentbrowser.KeyEvent{Type: "keyUp", Key: "Enter"}, `keyUp Enter Enter "" 0`},
+		{agentbrowser.KeyEvent{Type: "char", Key: "ß"}, `char   "ß" 0`},
+	}
+	for _, tt := range tests {
+		p, err := agentbrowser.KeyEventParams(tt.ev)
+		if err != nil {
+			t.Fatalf("KeyEventParams(%+v) error = %v", tt.ev, err)
+		}
+		if got := fmt.Sprintf("%s %s %s %q %d", p.Type, p.Key, p.Code, p.Text, p.Modifiers); got != tt.want {
+			t.Errorf("KeyEventParams(%+v) = %s, want %s", tt.ev, got, tt.want)
+		}
+	}
+}
+
+// TestMouseMoveDownUp tests that moves interpolate from the last position
+// and presses happen where the mouse is
+func TestMouseMoveDownUp(t *testing.T) {
+	var mouse []agentbrowser.MouseEvent
+	var keys []agentbrowser.KeyEvent
+	m := agentbrowser.NewBrowserManagerForTest(inputBackend(&mouse, &keys))
+
+	if err := m.MouseMove(100, 50, 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.MouseDown(""); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.MouseMove(200, 150, 4); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.MouseUp(""); err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, ev := range mouse {
+		got = append(got, fmt.Sprintf("%s %g,%g %d", ev.Type, ev.X, ev.Y, ev.Buttons))
+	}
+	want := []string{
+		"mouseMoved 100,50 0",
+		"mousePressed 100,50 1",
+		"mouseMoved 125,75 1",
+		"mouseMoved 150,100 1",
+		"mouseMoved 175,125 1",
+		"mouseMoved 200,150 1",
+		"mouseReleased 200,150 0",
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("events =\n%q\nwant\n%q", got, want)
+	}
+}
+
+// TestWheel tests that wheel events go over an element's center, or where
+// the mouse is
+func TestWheel(t *testing.T) {
+	var mouse []agentbrowser.MouseEvent
+	var keys []agentbrowser.KeyEvent
+	m := agentbrowser.NewBrowserManagerForTest(inputBackend(&mouse, &keys))
+
+	if err := m.Wheel(0, 300, ".list"); err != nil {
+		t.Fatalf("Wheel() error = %v", err)
+	}
+	if err := m.Wheel(-40, 0, ""); err != nil {
+		t.Fatalf("Wheel() error = %v", err)
+	}
+	want := []agentbrowser.MouseEvent{
+		{Type: "mouseWheel", X: 125, Y: 210, Button: "none", DeltaY: 300},
+		{Type: "mouseWheel", X: 125, Y: 210, Button: "none", DeltaX: -40},
+	}
+	if fmt.Sprint(mouse) != fmt.Sprint(want) {
+		t.Errorf("events = %+v, want %+v", mouse, want)
+	}
+	if err := m.Wheel(0, 0, ""); err == nil {
+		t.Error("Wheel() without deltas expected an error")
+	}
+}
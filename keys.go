@@ -0,0 +1,227 @@
+package agentbrowser
+
+import (
+	"fmt"
+	"runtime"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/chromedp/cdproto/input"
+	"github.com/chromedp/chromedp/kb"
+)
+
+// namedKeys maps key names to their definitions: DOM key values such as
+// Enter, ArrowDown or Control, and key codes such as KeyA, Digit1 or Space.
+// These are the names Playwright accepts.
+var namedKeys = buildNamedKeys()
+
+func buildNamedKeys() map[string]*kb.Key {
+	runes := make([]rune, 0, len(kb.Keys))
+	for r := range kb.Keys {
+		runes = append(runes, r)
+	}
+	sort.Slice(runes, func(i, j int) bool { return runes[i] < runes[j] })
+
+	index := func(name func(*kb.Key) string) map[string]*kb.Key {
+		keys := make(map[string]*kb.Key)
+		for _, r := range runes {
+			k := kb.Keys[r]
+			// Prefer the unshifted key: KeyA is "a", not "A"
+			if prev, ok := keys[name(k)]; !ok || (prev.Shift && !k.Shift) {
+				keys[name(k)] = k
+			}
+		}
+		return keys
+	}
+	keys := index(func(k *kb.Key) string { return k.Key })
+	for name := range keys {
+		if utf8.RuneCountInString(name) == 1 {
+			delete(keys, name)
+		}
+	}
+	for code, k := range index(func(k *kb.Key) string { return k.Code }) {
+		if _, ok := keys[code]; !ok {
+			keys[code] = k
+		}
+	}
+	return keys
+}
+
+// Modifier names in chords, with their CDP modifier bits.
+var keyModifiers = map[string]input.Modifier{
+	"Alt":     input.ModifierAlt,
+	"Control": input.ModifierCtrl,
+	"Meta":    input.ModifierMeta,
+	"Shift":   input.ModifierShift,
+}
+
+// modifierAliases maps modifier names in any case, and their common
+// short forms, to the names in keyModifiers.
+var modifierAliases = map[string]string{
+	"alt":           "Alt",
+	"option":        "Alt",
+	"control":       "Control",
+	"ctrl":          "Control",
+	"meta":          "Meta",
+	"cmd":           "Meta",
+	"command":       "Meta",
+	"shift":         "Shift",
+	"controlormeta": "ControlOrMeta",
+}
+
+// Keyboard presses a sequence of chords separated by spaces, such as
+// "Control+a Delete" or "Meta+Shift+P", one after another in the focused
+// element. Modifiers may be written in any case or as Ctrl, Cmd or Option.
+// Every chord is checked before any key is pressed.
+func (m *BrowserManager) Keyboard(keys string) error {
+	var chords []string
+	for _, chord := range strings.Fields(keys) {
+		chord, err := normalizeChord(chord)
+		if err != nil {
+			return err
+		}
+		chords = append(chords, chord)
+	}
+	if len(chords) == 0 {
+		return fmt.Errorf("no keys given")
+	}
+	for _, chord := range chords {
+		if err := m.backend.Press(chord, ""); err != nil {
+			return fmt.Errorf("%s: %w", chord, err)
+		}
+	}
+	return nil
+}
+
+// normalizeChord spells a chord's modifiers as keyEvents and Playwright
+// expect them, and checks that the chord can be pressed.
+func normalizeChord(chord string) (string, error) {
+	names, key := splitChord(chord)
+	parts := make([]string, 0, len(names)+1)
+	for _, name := range names {
+		modifier, ok := modifierAliases[strings.ToLower(name)]
+		if !ok {
+			return "", fmt.Errorf("unknown modifier %q in %q", name, chord)
+		}
+		parts = append(parts, modifier)
+	}
+	normalized := strings.Join(append(parts, key), "+")
+	if _, err := keyEvents(normalized); err != nil {
+		return "", err
+	}
+	return normalized, nil
+}
+
+// splitChord splits a chord such as "Control+Shift+a" into its modifiers
+// and key. The key may itself be "+", as in "Control++".
+func splitChord(chord string) (modifiers []string, key string) {
+	switch {
+	case chord == "+":
+		return nil, "+"
+	case strings.HasSuffix(chord, "++"):
+		return strings.Split(chord[:len(chord)-2], "+"), "+"
+	}
+	parts := strings.Split(chord, "+")
+	return parts[:len(parts)-1], parts[len(parts)-1]
+}
+
+// keyEvents returns the CDP key events that press chord: the modifiers go
+// down in order, the key is pressed, and the modifiers are released in
+// reverse, as Playwright's keyboard.press does.
+func keyEvents(chord string) ([]*input.DispatchKeyEventParams, error) {
+	names, key := splitChord(chord)
+	if key == "" {
+		return nil, fmt.Errorf("invalid key %q", chord)
+	}
+
+	var modifiers []*kb.Key
+	var held input.Modifier
+	var down, up []*input.DispatchKeyEventParams
+	for _, name := range names {
+		if name == "ControlOrMeta" {
+			name = "Control"
+			if runtime.GOOS == "darwin" {
+				name = "Meta"
+			}
+		}
+		bit, ok := keyModifiers[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown modifier %q in %q", name, chord)
+		}
+		held |= bit
+		k := namedKeys[name]
+		modifiers = append(modifiers, k)
+		down = append(down, keyEvent(input.KeyRawDown, k, "", held))
+	}
+
+	k, err := lookupKey(key, held&input.ModifierShift != 0)
+	if err != nil {
+		return nil, err
+	}
+	// Text is only produced with no modifiers other than Shift
+	text := k.Text
+	if held&^input.ModifierShift != 0 {
+		text = ""
+	}
+	typ := input.KeyDown
+	if text == "" {
+		typ = input.KeyRawDown
+	}
+	events := append(down, keyEvent(typ, k, text, held), keyEvent(input.KeyUp, k, "", held))
+
+	for i := len(modifiers) - 1; i >= 0; i-- {
+		held &^= keyModifiers[modifiers[i].Key]
+		up = append(up, keyEvent(input.KeyUp, modifiers[i], "", held))
+	}
+	return append(events, up...), nil
+}
+
+// lookupKey resolves a key name or a single character.
+func lookupKey(name string, shift bool) (*kb.Key, error) {
+	if k, ok := namedKeys[name]; ok {
+		if r, _ := utf8.DecodeRuneInString(k.Text); shift && unicode.IsLower(r) {
+			// Shift+KeyA types "A"
+			return kb.Keys[unicode.ToUpper(r)], nil
+		}
+		return k, nil
+	}
+	if utf8.RuneCountInString(name) != 1 {
+		return nil, fmt.Errorf("unknown key %q", name)
+	}
+	r, _ := utf8.DecodeRuneInString(name)
+	if shift {
+		r = unicode.ToUpper(r)
+	}
+	if k, ok := kb.Keys[r]; ok {
+		return k, nil
+	}
+	// Characters off the US layout are typed as text
+	return &kb.Key{Key: string(r), Text: string(r), Unmodified: string(r)}, nil
+}
+
+// keyEvent builds one CDP key event for k.
+func keyEvent(typ input.KeyType, k *kb.Key, text string, modifiers input.Modifier) *input.DispatchKeyEventParams {
+	ev := &input.DispatchKeyEventParams{
+		Type:                  typ,
+		Modifiers:             modifiers,
+		Key:                   k.Key,
+		Code:                  k.Code,
+		Text:                  text,
+		WindowsVirtualKeyCode: k.Windows,
+		NativeVirtualKeyCode:  k.Native,
+	}
+	if text != "" {
+		ev.UnmodifiedText = k.Unmodified
+	}
+	if runtime.GOOS == "darwin" {
+		ev.NativeVirtualKeyCode = 0
+	}
+	if strings.HasSuffix(k.Code, "Left") && keyModifiers[k.Key] != 0 {
+		ev.Location = 1
+	} else if strings.HasPrefix(k.Code, "Numpad") {
+		ev.Location = 3
+	}
+	return ev
+}
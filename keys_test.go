@@ -0,0 +1,131 @@
+package agentbrowser_test
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/chromedp/cdproto/input"
+
+	agentbrowser "github.com/cpunion/agent-browser-go"
+)
+
+// TestKeyEvents tests that key names and chords become CDP key events
+func TestKeyEvents(t *testing.T) {
+	describe := func(events []*input.DispatchKeyEventParams) []string {
+		var out []string
+		for _, ev := range events {
+			out = append(out, fmt.Sprintf("%s %s %s %q %d", ev.Type, ev.Key, ev.Code, ev.Text, ev.Modifiers))
+		}
+		return out
+	}
+
+	tests := []struct {
+		chord string
+		want  []string
+	}{
+		{"Enter", []string{`keyDown Enter Enter "\r" 0`, `keyUp Enter Enter "" 0`}},
+		{"Tab", []string{`rawKeyDown Tab Tab "" 0`, `keyUp Tab Tab "" 0`}},
+		{"ArrowDown", []string{`rawKeyDown ArrowDown ArrowDown "" 0`, `keyUp ArrowDown ArrowDown "" 0`}},
+		{"a", []string{`keyDown a KeyA "a" 0`, `keyUp a KeyA "" 0`}},
+		{"KeyA", []string{`keyDown a KeyA "a" 0`, `keyUp a KeyA "" 0`}},
+		{"Control+a", []string{
+			`rawKeyDown Control ControlLeft "" 2`,
+			`rawKeyDown a KeyA "" 2`,
+			`keyUp a KeyA "" 2`,
+			`keyUp Control ControlLeft "" 0`,
+		}},
+		{"Shift+a", []string{
+			`rawKeyDown Shift ShiftLeft "" 8`,
+			`keyDown A KeyA "A" 8`,
+			`keyUp A KeyA "" 8`,
+			`keyUp Shift ShiftLeft "" 0`,
+		}},
+		{"Control+Shift+ArrowLeft", []string{
+			`rawKeyDown Control ControlLeft "" 2`,
+			`rawKeyDown Shift ShiftLeft "" 10`,
+			`rawKeyDown ArrowLeft ArrowLeft "" 10`,
+			`keyUp ArrowLeft ArrowLeft "" 10`,
+			`keyUp Shift ShiftLeft "" 2`,
+			`keyUp Control ControlLeft "" 0`,
+		}},
+		{"Control++", []string{
+			`rawKeyDown Control ControlLeft "" 2`,
+			`rawKeyDown + Equal "" 2`,
+			`keyUp + Equal "" 2`,
+			`keyUp Control ControlLeft "" 0`,
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.chord, func(t *testing.T) {
+			events, err := agentbrowser.KeyEvents(tt.chord)
+			if err != nil {
+				t.Fatalf("KeyEvents() error = %v", err)
+			}
+			if got := describe(events); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("KeyEvents(%q) =\n%q\nwant\n%q", tt.chord, got, tt.want)
+			}
+		})
+	}
+
+	for _, chord := range []string{"Foo", "Hyperdrive+a", "Control+"} {
+		if _, err := agentbrowser.KeyEvents(chord); err == nil {
+			t.Errorf("KeyEvents(%q) expected an error", chord)
+		}
+	}
+}
+
+// TestNormalizeChord tests modifier aliases and chord checking
+func TestNormalizeChord(t *testing.T) {
+	tests := []struct {
+		chord string
+		want  string
+	}{
+		{"Enter", "Enter"},
+		{"ctrl+a", "Control+a"},
+		{"Cmd+Shift+P", "Meta+Shift+P"},
+		{"option+ArrowLeft", "Alt+ArrowLeft"},
+		{"controlormeta+c", "ControlOrMeta+c"},
+		{"Control++", "Control++"},
+	}
+	for _, tt := range tests {
+		if got, err := agentbrowser.NormalizeChord(tt.chord); err != nil || got != tt.want {
+			t.Errorf("NormalizeChord(%q) = %q, %v; want %q", tt.chord, got, err, tt.want)
+		}
+	}
+	for _, chord := range []string{"Hyper+a", "Control+Foo", "Shift+"} {
+		if _, err := agentbrowser.NormalizeChord(chord); err == nil {
+			t.Errorf("NormalizeChord(%q) expected an error", chord)
+		}
+	}
+}
+
+// TestKeyboard tests that combos are pressed in turn, and none if any is
+// invalid
+func TestKeyboard(t *testing.T) {
+	var pressed []string
+	m := agentbrowser.NewBrowserManagerForTest(&fakeBackend{
+		press: func(key string, selector string) error {
+			pressed = append(pressed, key)
+			return nil
+		},
+	})
+
+	if err := m.Keyboard("ctrl+a  Delete"); err != nil {
+		t.Fatalf("Keyboard() error = %v", err)
+	}
+	if want := []string{"Control+a", "Delete"}; !reflect.DeepEqual(pressed, want) {
+		t.Errorf("pressed %q, want %q", pressed, want)
+	}
+
+	pressed = nil
+	for _, keys := range []string{"", "Control+a Foo"} {
+		if err := m.Keyboard(keys); err == nil {
+			t.Errorf("Keyboard(%q) expected an error", keys)
+		}
+	}
+	if pressed != nil {
+		t.Errorf("pressed %q after invalid keys, want none", pressed)
+	}
+}
@@ -0,0 +1,64 @@
+package agentbrowser
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// SetLaunchDefaults sets the options a LaunchCommand starts from, such as
+// the preferences saved for a daemon's session. Options the command sets
+// win over them, and a successful launch makes them the new defaults.
+func (m *BrowserManager) SetLaunchDefaults(opts LaunchOptions) {
+	m.launchDefaults = &opts
+}
+
+// launchDefaultOptions returns the options a LaunchCommand starts from:
+// those set with SetLaunchDefaults, or else headless.
+func (m *BrowserManager) launchDefaultOptions() LaunchOptions {
+	if m.launchDefaults == nil {
+		return LaunchOptions{Headless: true}
+	}
+	return *m.launchDefaults
+}
+
+// checkExtension reports why dir is not an unpacked extension Chrome can
+// load, or nil if it is one.
+func checkExtension(dir string) error {
+	if !filepath.IsAbs(dir) {
+		return fmt.Errorf("extension %s: path must be absolute", dir)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "manifest.json")); err != nil {
+		return fmt.Errorf("extension %s: not an unpacked extension (no manifest.json)", dir)
+	}
+	return nil
+}
+
+// extensionArgs returns the Chrome switches, without dashes, that load
+// the unpacked extensions in dirs and no others.
+func extensionArgs(dirs []string) map[string]string {
+	list := strings.Join(dirs, ",")
+	return map[string]string{"load-extension": list, "disable-extensions-except": list}
+}
+
+// sameProcessOptions reports whether two launches start the same browser
+// binary with the same debugging port, extensions and proxy, so a running
+// browser can serve both.
+func sameProcessOptions(a, b LaunchOptions) bool {
+	return a.ExecutablePath == b.ExecutablePath && a.CDPPort == b.CDPPort &&
+		slices.Equal(a.Extensions, b.Extensions) && sameProxy(a.Proxy, b.Proxy)
+}
+
+// ParseViewport parses a viewport size such as 1024x768.
+func ParseViewport(s string) (*Viewport, error) {
+	w, h, ok := strings.Cut(s, "x")
+	width, werr := strconv.Atoi(w)
+	height, herr := strconv.Atoi(h)
+	if !ok || werr != nil || herr != nil || width <= 0 || height <= 0 {
+		return nil, fmt.Errorf("invalid viewport %q (expected WxH, e.g. 1024x768)", s)
+	}
+	return &Viewport{Width: width, Height: height}, nil
+}
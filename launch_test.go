@@ -0,0 +1,102 @@
+package agentbrowser_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	agentbrowser "github.com/cpunion/agent-browser-go"
+)
+
+// TestLaunchCommand tests that launch options add to the session's and
+// outlive the command
+func TestLaunchCommand(t *testing.T) {
+	ext := t.TempDir()
+	if err := os.WriteFile(filepath.Join(ext, "manifest.json"), []byte(`{"manifest_version": 3}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var launches []agentbrowser.LaunchOptions
+	var headers map[string]string
+	m := agentbrowser.NewBrowserManagerForTest(&fakeBackend{
+		launch: func(opts agentbrowser.LaunchOptions) error {
+			launches = append(launches, opts)
+			return nil
+		},
+		setExtraHeaders: func(h map[string]string) error {
+			headers = h
+			return nil
+		},
+		setViewport: func(width, height int) error { return nil },
+	})
+	m.SetLaunchDefaults(agentbrowser.LaunchOptions{Stealth: true, UserAgent: "Session UA", UserDataDir: "/profiles/work"})
+
+	resp := agentbrowser.ExecuteCommand(&agentbrowser.LaunchCommand{
+		BaseCommand: agentbrowser.BaseCommand{ID: "1", Action: "launch"},
+		Viewport:    &agentbrowser.Viewport{Width: 1440, Height: 900},
+		CDPPort:     9222,
+		Extensions:  []string{ext},
+		Headers:     map[string]string{"X-Request-ID": "run-42"},
+	}, m)
+	if !resp.Success {
+		t.Fatalf("launch failed: %s", resp.Error)
+	}
+	got := launches[0]
+	if !got.Stealth || got.UserAgent != "Session UA" || got.UserDataDir != "/profiles/work" || got.Headless {
+		t.Errorf("launched with %+v, want the session's options", got)
+	}
+	if *got.Viewport != (agentbrowser.Viewport{Width: 1440, Height: 900}) || got.CDPPort != 9222 || !reflect.DeepEqual(got.Extensions, []string{ext}) {
+		t.Errorf("launched with %+v, want the command's options", got)
+	}
+	if headers["x-request-id"] != "run-42" {
+		t.Errorf("headers = %v, want X-Request-ID", headers)
+	}
+
+	// A later launch keeps the earlier command's options
+	resp = agentbrowser.ExecuteCommand(&agentbrowser.LaunchCommand{BaseCommand: agentbrowser.BaseCommand{ID: "2", Action: "launch"}}, m)
+	if !resp.Success {
+		t.Fatalf("second launch failed: %s", resp.Error)
+	}
+	if again := launches[1]; again.CDPPort != 9222 || again.Viewport == nil || again.Viewport.Width != 1440 {
+		t.Errorf("second launch with %+v, want the first one's options", again)
+	}
+
+	for _, cmd := range []*agentbrowser.LaunchCommand{
+		{Browser: "firefox"},
+		{Extensions: []string{t.TempDir()}},
+		{Extensions: []string{"relative/ext"}},
+		{CDPPort: 70000},
+		{Viewport: &agentbrowser.Viewport{Width: 0, Height: 600}},
+		{Headers: map[string]string{"Bad Header": "x"}},
+	} {
+		if resp := agentbrowser.ExecuteCommand(cmd, m); resp.Success {
+			t.Errorf("launch with %+v succeeded", cmd)
+		}
+	}
+	if len(launches) != 2 {
+		t.Errorf("invalid launches reached the backend: %d launches", len(launches))
+	}
+}
+
+// TestSessionViewport tests saving and reading a session's launch viewport
+func TestSessionViewport(t *testing.T) {
+	t.Setenv("AGENT_BROWSER_CONFIG", filepath.Join(t.TempDir(), "config.yaml"))
+	session := "test-viewport-" + t.Name()
+
+	if got := agentbrowser.GetSessionViewport(session); got != nil {
+		t.Errorf("GetSessionViewport() before saving = %+v, want nil", got)
+	}
+	viewport := &agentbrowser.Viewport{Width: 1920, Height: 1080}
+	if err := agentbrowser.SaveSessionViewport(session, viewport); err != nil {
+		t.Fatalf("SaveSessionViewport() error = %v", err)
+	}
+	if got := agentbrowser.GetSessionViewport(session); !reflect.DeepEqual(got, viewport) {
+		t.Errorf("GetSessionViewport() = %+v, want %+v", got, viewport)
+	}
+	if err := agentbrowser.SaveSessionViewport(session, nil); err != nil {
+		t.Fatal(err)
+	}
+	if got := agentbrowser.GetSessionViewport(session); got != nil {
+		t.Errorf("GetSessionViewport() after reset = %+v, want nil", got)
+	}
+}
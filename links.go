@@ -0,0 +1,89 @@
+package agentbrowser
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Link is a link on the page.
+type Link struct {
+	Text string `json:"text"`
+	Href string `json:"href"`          // absolute URL
+	Ref  string `json:"ref,omitempty"` // snapshot ref such as e3, when the link has one
+}
+
+// LinksData is the response for links.
+type LinksData struct {
+	Links []Link `json:"links"`
+}
+
+// linksScript is a JavaScript function (root) that returns the text and
+// absolute URL of every link in root, in document order, or null when root
+// is missing. A link's text is its label, title or the alt text of its
+// image when it has no text of its own.
+const linksScript = `(root) => {
+	if (!root) return null;
+	const links = [];
+	for (const a of root.querySelectorAll('a[href], area[href]')) {
+		const img = a.querySelector('img[alt]');
+		const text = a.getAttribute('aria-label') || (a.innerText || a.textContent || '').trim() ||
+			a.getAttribute('title') || a.getAttribute('alt') || (img ? img.alt : '');
+		links.push({text: text.replace(/\s+/g, ' ').trim(), href: a.href});
+	}
+	return links;
+}`
+
+// decodeLinks decodes the result of linksScript.
+func decodeLinks(result interface{}) ([]Link, error) {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return nil, err
+	}
+	var links []Link
+	if err := json.Unmarshal(data, &links); err != nil {
+		return nil, fmt.Errorf("unexpected links result: %v", result)
+	}
+	return links, nil
+}
+
+// Links returns the links of the page, or of an element, with the refs of
+// the ones a fresh snapshot gives refs, so they can be clicked at once.
+func (m *BrowserManager) Links(selector string) ([]Link, error) {
+	var links []Link
+	if selector != "" {
+		selector, err := m.resolveRef(selector)
+		if err != nil {
+			return nil, err
+		}
+		if links, err = m.backend.Links(selector); err != nil {
+			return nil, err
+		}
+	} else {
+		result, err := m.backend.Evaluate(fmt.Sprintf("(%s)(document.documentElement)", linksScript))
+		if err != nil {
+			return nil, err
+		}
+		if links, err = decodeLinks(result); err != nil {
+			return nil, err
+		}
+	}
+	if links == nil {
+		links = []Link{}
+	}
+
+	// Refs come from a snapshot taken after the links were read, so
+	// they are current
+	snapshot, err := m.GetSnapshot(SnapshotOptions{Interactive: true})
+	if err != nil {
+		Logger().Debug("links without refs", "err", err)
+		return links, nil
+	}
+	wants := make([]refWant, len(links))
+	for i, link := range links {
+		wants[i] = refWant{Roles: []string{"link"}, Names: []string{link.Text}}
+	}
+	for i, ref := range matchRefs(snapshot.Refs, wants) {
+		links[i].Ref = ref
+	}
+	return links, nil
+}
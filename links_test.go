@@ -0,0 +1,63 @@
+package agentbrowser_test
+
+import (
+	"reflect"
+	"testing"
+
+	agentbrowser "github.com/cpunion/agent-browser-go"
+)
+
+// readBackend is a fake backend whose page scripts return *result, and
+// whose snapshots have refs.
+func readBackend(result *interface{}, refs agentbrowser.RefMap) *fakeBackend {
+	return &fakeBackend{
+		evaluate:  func(string) (interface{}, error) { return *result, nil },
+		getRefMap: func() agentbrowser.RefMap { return refs },
+		getSnapshot: func(agentbrowser.SnapshotOptions) (*agentbrowser.EnhancedSnapshot, error) {
+			return &agentbrowser.EnhancedSnapshot{Refs: refs}, nil
+		},
+	}
+}
+
+// TestLinks tests listing links with the refs of their snapshot entries
+func TestLinks(t *testing.T) {
+	long := "A very long link text that the snapshot cuts short at fifty characters"
+	link := func(text, href string) interface{} {
+		return map[string]interface{}{"text": text, "href": href}
+	}
+	var result interface{} = []interface{}{
+		link("Home", "https://a.com/"),
+		link("More", "https://a.com/1"),
+		link("More", "https://a.com/2"),
+		link(long, "https://a.com/long"),
+		link("Hidden", "https://a.com/hidden"),
+	}
+	refs := agentbrowser.RefMap{
+		"e1":  {Role: "link", Name: "Home"},
+		"e2":  {Role: "button", Name: "More"},
+		"e3":  {Role: "link", Name: "More"},
+		"e10": {Role: "link", Name: "More", Nth: 1},
+		"e11": {Role: "link", Name: long[:50]},
+	}
+	m := agentbrowser.NewBrowserManagerForTest(readBackend(&result, refs))
+
+	links, err := m.Links("")
+	if err != nil {
+		t.Fatalf("Links() error = %v", err)
+	}
+	want := []agentbrowser.Link{
+		{Text: "Home", Href: "https://a.com/", Ref: "e1"},
+		{Text: "More", Href: "https://a.com/1", Ref: "e3"},
+		{Text: "More", Href: "https://a.com/2", Ref: "e10"},
+		{Text: long, Href: "https://a.com/long", Ref: "e11"},
+		{Text: "Hidden", Href: "https://a.com/hidden"},
+	}
+	if !reflect.DeepEqual(links, want) {
+		t.Errorf("Links() = %+v, want %+v", links, want)
+	}
+
+	result = []interface{}{}
+	if links, err := m.Links(""); err != nil || links == nil || len(links) != 0 {
+		t.Errorf("Links() on a page without links = %v, %v, want an empty list", links, err)
+	}
+}
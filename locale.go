@@ -0,0 +1,20 @@
+package agentbrowser
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// localeRe matches BCP 47 language tags such as de, en-US or zh-Hant-TW.
+var localeRe = regexp.MustCompile(`^[A-Za-z]{2,3}(-[A-Za-z0-9]{2,8})*$`)
+
+// SetLocale makes every tab, including tabs opened later, use locale as
+// the language pages format dates and numbers in (Intl), report in
+// navigator.language and send in Accept-Language. It takes effect on the
+// next navigation; an empty locale removes the override.
+func (m *BrowserManager) SetLocale(locale string) error {
+	if locale != "" && !localeRe.MatchString(locale) {
+		return fmt.Errorf("invalid locale %q (expected a language tag such as en-US or de-DE)", locale)
+	}
+	return m.backend.SetLocale(locale)
+}
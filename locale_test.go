@@ -0,0 +1,33 @@
+package agentbrowser_test
+
+import (
+	"testing"
+
+	agentbrowser "github.com/cpunion/agent-browser-go"
+)
+
+// TestSetLocale tests that only language tags reach the backend
+func TestSetLocale(t *testing.T) {
+	var locales []string
+	m := agentbrowser.NewBrowserManagerForTest(&fakeBackend{
+		setLocale: func(locale string) error {
+			locales = append(locales, locale)
+			return nil
+		},
+	})
+
+	valid := []string{"de", "en-US", "zh-Hant-TW", "es-419", ""}
+	for _, locale := range valid {
+		if err := m.SetLocale(locale); err != nil {
+			t.Errorf("SetLocale(%q) error = %v", locale, err)
+		}
+	}
+	for _, locale := range []string{"en_US", "e", "en-US!", "en-", "english-language"} {
+		if err := m.SetLocale(locale); err == nil {
+			t.Errorf("SetLocale(%q) expected an error", locale)
+		}
+	}
+	if len(locales) != len(valid) {
+		t.Errorf("backend got %q, want %q", locales, valid)
+	}
+}
@@ -0,0 +1,167 @@
+package agentbrowser
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+)
+
+// Locator finds an element the way a user perceives it rather than by its
+// markup, like Playwright's getBy* locators.
+type Locator struct {
+	By    string `json:"by"`             // role, text, label, placeholder, alt, title or testid
+	Role  string `json:"role,omitempty"` // ARIA role, for By role
+	Text  string `json:"text"`           // accessible name, text, label or attribute to match; may be empty for By role
+	Exact bool   `json:"exact"`          // match the whole text, case-sensitively; else a case-insensitive substring
+	Attr  string `json:"attr,omitempty"` // attribute holding test ids, for By testid
+}
+
+// String describes the locator for messages.
+func (l Locator) String() string {
+	kind := l.By
+	if l.By == "role" {
+		kind = l.Role
+	}
+	if l.Text == "" {
+		return kind
+	}
+	return fmt.Sprintf("%s %q", kind, l.Text)
+}
+
+// matches reports whether text, such as an element's accessible name,
+// matches the locator's text. Runs of whitespace count as one space.
+func (l Locator) matches(text string) bool {
+	text = strings.Join(strings.Fields(text), " ")
+	want := strings.Join(strings.Fields(l.Text), " ")
+	if l.Exact {
+		return text == want
+	}
+	return strings.Contains(strings.ToLower(text), strings.ToLower(want))
+}
+
+// locatorAttr marks the element a locator resolved to in the chromedp
+// backend, whose selectors are CSS only.
+const locatorAttr = "data-agent-browser-locator"
+
+// locateScript is a JavaScript function (loc, attr, mark), taking a
+// Locator as JSON, that finds the elements a locator other than role matches, marks the
+// first visible one, or else the first, with attr=mark and reports whether
+// it found one. Text matches as Locator.matches does.
+const locateScript = `(loc, attr, mark) => {
+	const norm = s => (s || '').replace(/\s+/g, ' ').trim();
+	const want = norm(loc.text);
+	const matches = s => loc.exact ? norm(s) === want : norm(s).toLowerCase().includes(want.toLowerCase());
+	const found = [];
+	switch (loc.by) {
+	case 'text': {
+		const skip = new Set(['HEAD', 'SCRIPT', 'STYLE', 'NOSCRIPT', 'TEMPLATE']);
+		const textOf = el => el instanceof HTMLInputElement && ['button', 'submit', 'reset'].includes(el.type) ? el.value : el.textContent;
+		for (const el of document.querySelectorAll('*')) {
+			if (skip.has(el.tagName) || !matches(textOf(el))) continue;
+			// The innermost element holding the text, not its ancestors
+			if ([...el.children].some(c => !skip.has(c.tagName) && matches(textOf(c)))) continue;
+			found.push(el);
+		}
+		break;
+	}
+	case 'label': {
+		// A label's text without that of the select or textarea it wraps
+		const labelText = label => {
+			const copy = label.cloneNode(true);
+			copy.querySelectorAll('select, textarea').forEach(c => c.remove());
+			return copy.textContent;
+		};
+		for (const el of document.querySelectorAll('input, textarea, select, button, meter, output, progress, [aria-label], [aria-labelledby]')) {
+			if (el instanceof HTMLInputElement && el.type === 'hidden') continue;
+			const names = [el.getAttribute('aria-label')];
+			const ids = (el.getAttribute('aria-labelledby') || '').split(/\s+/).filter(Boolean);
+			if (ids.length) names.push(ids.map(id => document.getElementById(id)?.textContent || '').join(' '));
+			for (const label of el.labels || []) names.push(labelText(label));
+			if (names.some(name => name && matches(name))) found.push(el);
+		}
+		break;
+	}
+	case 'placeholder':
+	case 'alt':
+	case 'title':
+	case 'testid': {
+		const name = loc.by === 'testid' ? loc.attr : loc.by;
+		for (const el of document.querySelectorAll('[' + CSS.escape(name) + ']')) {
+			if (matches(el.getAttribute(name))) found.push(el);
+		}
+		break;
+	}
+	}
+	const el = found.find(el => el.getClientRects().length > 0) || found[0];
+	if (!el) return false;
+	el.setAttribute(attr, mark);
+	return true;
+}`
+
+// locatorMarks numbers the elements marked with locatorAttr.
+var locatorMarks atomic.Int64
+
+// nextLocatorMark returns a new locatorAttr value and the selector that
+// finds the element marked with it.
+func nextLocatorMark() (mark, selector string) {
+	mark = fmt.Sprint(locatorMarks.Add(1))
+	return mark, fmt.Sprintf(`[%s="%s"]`, locatorAttr, mark)
+}
+
+// playwrightLocatorSelector returns the Playwright selector for loc, as
+// page.GetByRole and friends build it.
+func playwrightLocatorSelector(loc Locator) string {
+	suffix := "i"
+	if loc.Exact {
+		suffix = "s"
+	}
+	quote := func(s string) string {
+		s = strings.ReplaceAll(s, `\`, `\\`)
+		return `"` + strings.ReplaceAll(s, `"`, `\"`) + `"` + suffix
+	}
+
+	var sel string
+	switch loc.By {
+	case "role":
+		sel = "internal:role=" + loc.Role
+		if loc.Text != "" {
+			sel += "[name=" + quote(loc.Text) + "]"
+		}
+	case "text":
+		sel = "internal:text=" + quote(loc.Text)
+	case "label":
+		sel = "internal:label=" + quote(loc.Text)
+	case "placeholder", "alt", "title":
+		sel = "internal:attr=[" + loc.By + "=" + quote(loc.Text) + "]"
+	case "testid":
+		sel = "internal:testid=[" + loc.Attr + "=" + quote(loc.Text) + "]"
+	}
+	return sel
+}
+
+// Locate resolves a locator in the current frame and returns a selector
+// for the first element it matches, which other commands accept.
+func (m *BrowserManager) Locate(loc Locator) (string, error) {
+	switch loc.By {
+	case "role":
+		if loc.Role == "" {
+			return "", fmt.Errorf("role locator needs a role")
+		}
+	case "text", "label", "placeholder", "alt", "title":
+		if strings.TrimSpace(loc.Text) == "" {
+			return "", fmt.Errorf("%s locator needs text", loc.By)
+		}
+	case "testid":
+		if loc.Text == "" {
+			return "", fmt.Errorf("testid locator needs a test id")
+		}
+		// Test ids are matched whole, as Playwright does
+		loc.Exact = true
+		if loc.Attr == "" {
+			loc.Attr = m.testIDAttribute()
+		}
+	default:
+		return "", fmt.Errorf("unknown locator %q", loc.By)
+	}
+	return m.backend.Locate(loc)
+}
@@ -0,0 +1,91 @@
+package agentbrowser_test
+
+import (
+	"reflect"
+	"testing"
+
+	agentbrowser "github.com/cpunion/agent-browser-go"
+)
+
+// TestLocatorMatches tests substring and exact matching of accessible names
+func TestLocatorMatches(t *testing.T) {
+	tests := []struct {
+		text  string
+		exact bool
+		name  string
+		want  bool
+	}{
+		{"Sign in", false, "Sign in", true},
+		{"sign IN", false, "Sign in now", true},
+		{"Sign in", false, "Sign up", false},
+		{"Sign in", false, "  Sign\n  in ", true},
+		{"", false, "anything", true},
+		{"Sign in", true, "Sign in", true},
+		{"Sign in", true, " Sign   in", true},
+		{"Sign in", true, "sign in", false},
+		{"Sign in", true, "Sign in now", false},
+	}
+	for _, tt := range tests {
+		loc := agentbrowser.Locator{By: "role", Role: "button", Text: tt.text, Exact: tt.exact}
+		if got := agentbrowser.LocatorMatches(loc, tt.name); got != tt.want {
+			t.Errorf("matches(%q, exact=%v) on %q = %v, want %v", tt.text, tt.exact, tt.name, got, tt.want)
+		}
+	}
+}
+
+// TestLocatorSelector tests the Playwright selectors built for locators
+func TestLocatorSelector(t *testing.T) {
+	tests := []struct {
+		loc  agentbrowser.Locator
+		want string
+	}{
+		{agentbrowser.Locator{By: "role", Role: "button"}, `internal:role=button`},
+		{agentbrowser.Locator{By: "role", Role: "button", Text: "Sign in"}, `internal:role=button[name="Sign in"i]`},
+		{agentbrowser.Locator{By: "role", Role: "link", Text: "Say \"hi\" \\o/", Exact: true}, `internal:role=link[name="Say \"hi\" \\o/"s]`},
+		{agentbrowser.Locator{By: "text", Text: "Sign in"}, `internal:text="Sign in"i`},
+		{agentbrowser.Locator{By: "text", Text: "Sign in", Exact: true}, `internal:text="Sign in"s`},
+		{agentbrowser.Locator{By: "label", Text: "Email"}, `internal:label="Email"i`},
+		{agentbrowser.Locator{By: "placeholder", Text: "Search"}, `internal:attr=[placeholder="Search"i]`},
+		{agentbrowser.Locator{By: "alt", Text: "Logo", Exact: true}, `internal:attr=[alt="Logo"s]`},
+		{agentbrowser.Locator{By: "title", Text: "Settings"}, `internal:attr=[title="Settings"i]`},
+		{agentbrowser.Locator{By: "testid", Text: "submit", Exact: true, Attr: "data-cy"}, `internal:testid=[data-cy="submit"s]`},
+	}
+	for _, tt := range tests {
+		if got := agentbrowser.LocatorSelector(tt.loc); got != tt.want {
+			t.Errorf("LocatorSelector(%+v) = %s, want %s", tt.loc, got, tt.want)
+		}
+	}
+}
+
+// TestLocateTestID tests that test ids match whole, in the attribute the
+// session config names
+func TestLocateTestID(t *testing.T) {
+	var locs []agentbrowser.Locator
+	m := agentbrowser.NewBrowserManagerForTest(&fakeBackend{
+		locate: func(loc agentbrowser.Locator) (string, error) {
+			locs = append(locs, loc)
+			return "#found", nil
+		},
+	})
+
+	if _, err := m.Locate(agentbrowser.Locator{By: "testid", Text: "submit"}); err != nil {
+		t.Fatalf("Locate() error = %v", err)
+	}
+	if _, err := m.ApplySessionConfig(agentbrowser.SessionConfig{TestIDAttribute: "data-cy"}); err != nil {
+		t.Fatalf("ApplySessionConfig() error = %v", err)
+	}
+	if _, err := m.Locate(agentbrowser.Locator{By: "testid", Text: "submit"}); err != nil {
+		t.Fatalf("Locate() error = %v", err)
+	}
+	if _, err := m.Locate(agentbrowser.Locator{By: "testid"}); err == nil {
+		t.Error("Locate() without a test id succeeded")
+	}
+
+	want := []agentbrowser.Locator{
+		{By: "testid", Text: "submit", Exact: true, Attr: "data-testid"},
+		{By: "testid", Text: "submit", Exact: true, Attr: "data-cy"},
+	}
+	if !reflect.DeepEqual(locs, want) {
+		t.Errorf("located %+v, want %+v", locs, want)
+	}
+}
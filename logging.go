@@ -0,0 +1,32 @@
+package agentbrowser
+
+import (
+	"io"
+	"log/slog"
+	"os"
+	"sync/atomic"
+)
+
+// logger is the package's logger. Without SetLogger it writes warnings
+// and errors to stderr, which is the log file in a daemon.
+var logger atomic.Pointer[slog.Logger]
+
+func init() {
+	logger.Store(NewLogger(os.Stderr, slog.LevelWarn))
+}
+
+// NewLogger returns a logger writing text records of level and above to w.
+func NewLogger(w io.Writer, level slog.Leveler) *slog.Logger {
+	return slog.New(slog.NewTextHandler(w, &slog.HandlerOptions{Level: level}))
+}
+
+// SetLogger sets the logger for client connections, daemon command
+// handling and browser actions.
+func SetLogger(l *slog.Logger) {
+	logger.Store(l)
+}
+
+// Logger returns the logger set with SetLogger.
+func Logger() *slog.Logger {
+	return logger.Load()
+}
@@ -0,0 +1,188 @@
+package agentbrowser
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// LoginRecipe describes how to sign in to a site. Recipes are stored per
+// site under "logins" in the config file.
+type LoginRecipe struct {
+	URL              string `json:"url"`
+	UsernameSelector string `json:"usernameSelector"`
+	Username         string `json:"username"`
+	NextSelector     string `json:"nextSelector,omitempty"` // clicked after the username on two-step forms
+	PasswordSelector string `json:"passwordSelector"`
+	Password         string `json:"password"`
+	SubmitSelector   string `json:"submitSelector,omitempty"` // default: press Enter in the password field
+
+	TOTPSelector       string `json:"totpSelector,omitempty"`
+	TOTPSecret         string `json:"totpSecret,omitempty"`         // base32, as shown by authenticator setup
+	TOTPSubmitSelector string `json:"totpSubmitSelector,omitempty"` // default: press Enter in the TOTP field
+
+	SuccessURL      string `json:"successUrl,omitempty"`      // substring of the URL after signing in
+	SuccessSelector string `json:"successSelector,omitempty"` // element shown after signing in
+	StatePath       string `json:"statePath,omitempty"`       // where to save the storage state
+	Timeout         int    `json:"timeout,omitempty"`         // ms per step (default: 30000)
+}
+
+// LoginResult is the outcome of a successful Login.
+type LoginResult struct {
+	URL       string `json:"url"`
+	StatePath string `json:"statePath,omitempty"`
+}
+
+// loginPollInterval is how often the URL is checked while waiting for success.
+const loginPollInterval = 250 * time.Millisecond
+
+// Login runs a login recipe: it fills and submits the sign-in form, enters a
+// TOTP code when configured, waits for the success condition and saves the
+// storage state to the recipe's StatePath.
+//
+// Without a success URL or selector, login succeeds once the page no longer
+// shows a login wall.
+func (m *BrowserManager) Login(r LoginRecipe) (*LoginResult, error) {
+	if r.URL == "" || r.UsernameSelector == "" || r.PasswordSelector == "" {
+		return nil, fmt.Errorf("login recipe requires url, usernameSelector and passwordSelector")
+	}
+	if r.TOTPSecret != "" && r.TOTPSelector == "" {
+		return nil, fmt.Errorf("login recipe with totpSecret requires totpSelector")
+	}
+	timeout := r.Timeout
+	if timeout <= 0 {
+		timeout = 30000
+	}
+
+	if _, _, err := m.Navigate(r.URL, "load"); err != nil {
+		return nil, fmt.Errorf("login: %w", err)
+	}
+
+	step := func(name string, fn func() error) error {
+		if err := fn(); err != nil {
+			return fmt.Errorf("login %s: %w", name, err)
+		}
+		return nil
+	}
+	fill := func(selector, value string) func() error {
+		return func() error {
+			if err := m.Wait(selector, timeout, "visible"); err != nil {
+				return err
+			}
+			return m.Fill(selector, value)
+		}
+	}
+	submit := func(submitSelector, fieldSelector string) func() error {
+		return func() error {
+			if submitSelector == "" {
+				return m.Press("Enter", fieldSelector)
+			}
+			return m.Click(submitSelector)
+		}
+	}
+
+	if err := step("username", fill(r.UsernameSelector, r.Username)); err != nil {
+		return nil, err
+	}
+	if r.NextSelector != "" {
+		if err := step("next", func() error { return m.Click(r.NextSelector) }); err != nil {
+			return nil, err
+		}
+	}
+	if err := step("password", fill(r.PasswordSelector, r.Password)); err != nil {
+		return nil, err
+	}
+	if err := step("submit", submit(r.SubmitSelector, r.PasswordSelector)); err != nil {
+		return nil, err
+	}
+
+	if r.TOTPSecret != "" {
+		code, err := totpCode(r.TOTPSecret, time.Now())
+		if err != nil {
+			return nil, fmt.Errorf("login totp: %w", err)
+		}
+		if err := step("totp", fill(r.TOTPSelector, code)); err != nil {
+			return nil, err
+		}
+		if err := step("totp submit", submit(r.TOTPSubmitSelector, r.TOTPSelector)); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := m.waitForLogin(r, timeout); err != nil {
+		return nil, err
+	}
+
+	url, err := m.URL()
+	if err != nil {
+		return nil, err
+	}
+	result := &LoginResult{URL: url}
+	if r.StatePath != "" {
+		if err := m.SaveStorageState(r.StatePath); err != nil {
+			return nil, fmt.Errorf("login: failed to save state: %w", err)
+		}
+		result.StatePath = r.StatePath
+	}
+	return result, nil
+}
+
+// waitForLogin waits for the recipe's success condition.
+func (m *BrowserManager) waitForLogin(r LoginRecipe, timeout int) error {
+	if r.SuccessSelector != "" {
+		if err := m.Wait(r.SuccessSelector, timeout, "visible"); err != nil {
+			return fmt.Errorf("login did not succeed: %w", err)
+		}
+		if r.SuccessURL == "" {
+			return nil
+		}
+	}
+
+	deadline := time.Now().Add(time.Duration(timeout) * time.Millisecond)
+	for {
+		var done bool
+		if r.SuccessURL != "" {
+			url, err := m.URL()
+			done = err == nil && strings.Contains(url, r.SuccessURL)
+		} else {
+			// Pages may be mid-navigation; an evaluation error is not a failure
+			challenge, err := m.DetectChallenge()
+			done = err == nil && (challenge == nil || challenge.Type != ChallengeLogin)
+		}
+		if done {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			if r.SuccessURL != "" {
+				return fmt.Errorf("login did not succeed: URL does not contain %q after %dms", r.SuccessURL, timeout)
+			}
+			return fmt.Errorf("login did not succeed: login form still shown after %dms", timeout)
+		}
+		time.Sleep(loginPollInterval)
+	}
+}
+
+// totpCode returns the RFC 6238 time-based one-time password for a base32
+// secret: HMAC-SHA1, 30 second steps, 6 digits.
+func totpCode(secret string, t time.Time) (string, error) {
+	secret = strings.ToUpper(strings.Join(strings.Fields(secret), ""))
+	secret = strings.TrimRight(secret, "=")
+	key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(secret)
+	if err != nil {
+		return "", fmt.Errorf("invalid TOTP secret: %w", err)
+	}
+
+	var counter [8]byte
+	binary.BigEndian.PutUint64(counter[:], uint64(t.Unix()/30))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(counter[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	code := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf("%06d", code%1000000), nil
+}
@@ -0,0 +1,206 @@
+package agentbrowser_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	agentbrowser "github.com/cpunion/agent-browser-go"
+)
+
+// TestTOTPCode tests TOTP generation against the RFC 6238 SHA-1 test vectors
+func TestTOTPCode(t *testing.T) {
+	// "12345678901234567890" in base32
+	const secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+	}
+
+	for _, tt := range tests {
+		got, err := agentbrowser.TOTPCode(secret, time.Unix(tt.unix, 0))
+		if err != nil {
+			t.Fatalf("TOTPCode() error = %v", err)
+		}
+		if got != tt.want {
+			t.Errorf("TOTPCode(t=%d) = %s, want %s", tt.unix, got, tt.want)
+		}
+	}
+
+	// Authenticator apps show secrets in lowercase groups
+	got, err := agentbrowser.TOTPCode("gezd gnbv gy3t qojq gezd gnbv gy3t qojq", time.Unix(59, 0))
+	if err != nil || got != "287082" {
+		t.Errorf("TOTPCode(spaced) = %s, %v, want 287082", got, err)
+	}
+	if _, err := agentbrowser.TOTPCode("not base32!", time.Now()); err == nil {
+		t.Error("TOTPCode(invalid) expected error")
+	}
+}
+
+// TestLoadConfig tests reading login recipes from the config file
+func TestLoadConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	t.Setenv("AGENT_BROWSER_CONFIG", path)
+
+	cfg, err := agentbrowser.LoadConfig()
+	if err != nil || len(cfg.Logins) != 0 {
+		t.Fatalf("LoadConfig() missing file = %+v, %v, want empty config", cfg, err)
+	}
+
+	data := `{"logins": {"example": {"url": "https://example.com/login", "usernameSelector": "#user",
+		"username": "$USER_NAME", "passwordSelector": "#pass", "password": "secret", "successUrl": "/home"}}}`
+	if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err = agentbrowser.LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	want := agentbrowser.LoginRecipe{
+		URL:              "https://example.com/login",
+		UsernameSelector: "#user",
+		Username:         "$USER_NAME",
+		PasswordSelector: "#pass",
+		Password:         "secret",
+		SuccessURL:       "/home",
+	}
+	if got := cfg.Logins["example"]; got != want {
+		t.Errorf("Logins[example] = %+v, want %+v", got, want)
+	}
+
+	if err := os.WriteFile(path, []byte("{"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := agentbrowser.LoadConfig(); err == nil {
+		t.Error("LoadConfig() invalid JSON expected error")
+	}
+}
+
+// loginBackend is a fake backend for a login page that is left for the home
+// page once the password is submitted. Its steps are appended to *steps.
+func loginBackend(steps *[]string) *fakeBackend {
+	var url string
+	return &fakeBackend{
+		navigate: func(u string, waitUntil string) (string, string, error) {
+			url = u
+			*steps = append(*steps, "navigate "+u)
+			return u, "", nil
+		},
+		wait: func(selector string, timeout int, state string) error { return nil },
+		fill: func(selector, value string) error {
+			*steps = append(*steps, "fill "+selector+" "+value)
+			return nil
+		},
+		click: func(selector string) error {
+			*steps = append(*steps, "click "+selector)
+			return nil
+		},
+		press: func(key string, selector string) error {
+			*steps = append(*steps, "press "+key+" "+selector)
+			url = "https://example.com/home"
+			return nil
+		},
+		url: func() (string, error) { return url, nil },
+		listTabs: func() ([]agentbrowser.TabInfo, error) {
+			return []agentbrowser.TabInfo{{Index: 0, URL: url, Active: true}}, nil
+		},
+		getCookies: func() ([]agentbrowser.Cookie, error) {
+			return []agentbrowser.Cookie{{Name: "sid", Value: "abc", Domain: "example.com", Path: "/", HTTPOnly: true}}, nil
+		},
+		evaluate: evalResult(map[string]interface{}{
+			"origin": "https://example.com",
+			"items":  []interface{}{[]interface{}{"token", "t1"}},
+		}),
+	}
+}
+
+// TestLogin tests that a recipe runs its steps in order and saves the storage state
+func TestLogin(t *testing.T) {
+	statePath := filepath.Join(t.TempDir(), "state", "example.json")
+	var steps []string
+	m := agentbrowser.NewBrowserManagerForTest(loginBackend(&steps))
+
+	result, err := m.Login(agentbrowser.LoginRecipe{
+		URL:              "https://example.com/login",
+		UsernameSelector: "#user",
+		Username:         "alice",
+		NextSelector:     "#next",
+		PasswordSelector: "#pass",
+		Password:         "secret",
+		SuccessURL:       "/home",
+		StatePath:        statePath,
+		Timeout:          1000,
+	})
+	if err != nil {
+		t.Fatalf("Login() error = %v", err)
+	}
+
+	wantSteps := []string{
+		"navigate https://example.com/login",
+		"fill #user alice",
+		"click #next",
+		"fill #pass secret",
+		"press Enter #pass",
+	}
+	if !reflect.DeepEqual(steps, wantSteps) {
+		t.Errorf("steps = %q, want %q", steps, wantSteps)
+	}
+	if result.URL != "https://example.com/home" || result.StatePath != statePath {
+		t.Errorf("Login() = %+v", result)
+	}
+
+	data, err := os.ReadFile(statePath)
+	if err != nil {
+		t.Fatalf("state not saved: %v", err)
+	}
+	var state agentbrowser.StorageState
+	if err := json.Unmarshal(data, &state); err != nil {
+		t.Fatal(err)
+	}
+	wantState := agentbrowser.StorageState{
+		Cookies: []agentbrowser.StorageStateCookie{
+			{Name: "sid", Value: "abc", Domain: "example.com", Path: "/", Expires: -1, HTTPOnly: true, SameSite: "Lax"},
+		},
+		Origins: []agentbrowser.StorageStateOrigin{
+			{Origin: "https://example.com", LocalStorage: []agentbrowser.NameValue{{Name: "token", Value: "t1"}}},
+		},
+	}
+	if !reflect.DeepEqual(state, wantState) {
+		t.Errorf("state = %+v, want %+v", state, wantState)
+	}
+}
+
+// TestLogin_Errors tests recipe validation and success timeouts
+func TestLogin_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		recipe agentbrowser.LoginRecipe
+	}{
+		{"missing selectors", agentbrowser.LoginRecipe{URL: "https://example.com/login"}},
+		{"totp without selector", agentbrowser.LoginRecipe{
+			URL: "https://example.com/login", UsernameSelector: "#u", PasswordSelector: "#p", TOTPSecret: "GEZDGNBV",
+		}},
+		{"success URL not reached", agentbrowser.LoginRecipe{
+			URL: "https://example.com/login", UsernameSelector: "#u", PasswordSelector: "#p",
+			SuccessURL: "/dashboard", Timeout: 300,
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := agentbrowser.NewBrowserManagerForTest(loginBackend(new([]string)))
+			if _, err := m.Login(tt.recipe); err == nil {
+				t.Error("Login() expected error")
+			}
+		})
+	}
+}
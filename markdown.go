@@ -0,0 +1,160 @@
+package agentbrowser
+
+import "fmt"
+
+// markdownScript is a JavaScript function (root, plain) that converts
+// root's content to Markdown: headings, paragraphs, links, images with alt
+// text, emphasis, code, quotes, lists and tables. With plain it returns the
+// same text without the Markdown syntax. Hidden elements, scripts, embedded
+// content, form controls and boilerplate (navigation, banners, sidebars and
+// page footers) are skipped. It returns null when root is missing.
+const markdownScript = `(root, plain) => {
+	if (!root) return null;
+	const skip = new Set(['SCRIPT', 'STYLE', 'NOSCRIPT', 'TEMPLATE', 'SVG', 'CANVAS', 'IFRAME', 'OBJECT', 'VIDEO', 'AUDIO',
+		'BUTTON', 'SELECT', 'NAV', 'ASIDE']);
+	const boilerplate = new Set(['navigation', 'banner', 'contentinfo', 'complementary', 'search']);
+	const blockDisplay = /^(block|flex|grid|table|list-item|flow-root)/;
+
+	const children = (el) => {
+		const out = [];
+		for (const c of el.childNodes) walk(c, out);
+		return out.join('').trim();
+	};
+	const block = (s) => s ? '\n\n' + s + '\n\n' : '';
+	// Site headers and footers, not those of an article or section
+	const pageChrome = (el) => !el.parentElement.closest('article, section');
+
+	function walk(node, out) {
+		if (node.nodeType === Node.TEXT_NODE) {
+			out.push(node.textContent.replace(/\s+/g, ' '));
+			return;
+		}
+		if (node.nodeType !== Node.ELEMENT_NODE) return;
+		const el = node;
+		const tag = el.tagName.toUpperCase();
+		if (el !== root) {
+			if (skip.has(tag) || boilerplate.has(el.getAttribute('role'))) return;
+			if ((tag === 'HEADER' || tag === 'FOOTER') && pageChrome(el)) return;
+		}
+		if (el.hidden || el.getAttribute('aria-hidden') === 'true') return;
+		const style = getComputedStyle(el);
+		if (style.display === 'none' || style.visibility === 'hidden') return;
+
+		switch (tag) {
+		case 'H1': case 'H2': case 'H3': case 'H4': case 'H5': case 'H6': {
+			const t = children(el).replace(/\n+/g, ' ');
+			if (t) out.push(block(plain ? t : '#'.repeat(+tag[1]) + ' ' + t));
+			return;
+		}
+		case 'BR':
+			out.push('\n');
+			return;
+		case 'HR':
+			if (!plain) out.push(block('---'));
+			return;
+		case 'A': {
+			const t = children(el);
+			const href = el.getAttribute('href') ? el.href : '';
+			out.push(t && href && !plain && !href.startsWith('javascript:') ? '[' + t + '](' + href + ')' : t);
+			return;
+		}
+		case 'IMG':
+			if (el.alt && !plain) out.push('![' + el.alt + '](' + el.src + ')');
+			return;
+		case 'STRONG': case 'B': {
+			const t = children(el);
+			if (t) out.push(plain ? t : '**' + t + '**');
+			return;
+		}
+		case 'EM': case 'I': {
+			const t = children(el);
+			if (t) out.push(plain ? t : '*' + t + '*');
+			return;
+		}
+		case 'CODE':
+			out.push(plain ? el.textContent : '` + "`" + `' + el.textContent + '` + "`" + `');
+			return;
+		case 'PRE': {
+			const t = el.textContent.replace(/\n+$/, '');
+			out.push(block(plain ? t : '` + "```" + `\n' + t + '\n` + "```" + `'));
+			return;
+		}
+		case 'BLOCKQUOTE': {
+			const t = children(el);
+			out.push(block(plain ? t : t.split('\n').map((l) => '> ' + l).join('\n')));
+			return;
+		}
+		case 'UL': case 'OL': {
+			const items = [];
+			for (const li of el.children) {
+				if (li.tagName.toUpperCase() !== 'LI') continue;
+				const marker = tag === 'OL' ? (items.length + 1) + '. ' : '- ';
+				items.push(marker + children(li).replace(/\n+/g, '\n  '));
+			}
+			out.push(block(items.join('\n')));
+			return;
+		}
+		case 'TABLE': {
+			const cells = (row) => Array.from(row.cells, (cell) => children(cell).replace(/\s*\n\s*/g, ' '));
+			if (el.rows.length === 0) return;
+			if (plain) {
+				out.push(block(Array.from(el.rows, (row) => cells(row).join('\t')).join('\n')));
+				return;
+			}
+			const rows = Array.from(el.rows, (row) => '| ' + cells(row).map((c) => c.replace(/\|/g, '\\|')).join(' | ') + ' |');
+			rows.splice(1, 0, '|' + ' --- |'.repeat(el.rows[0].cells.length));
+			out.push(block(rows.join('\n')));
+			return;
+		}
+		}
+
+		const t = children(el);
+		out.push(blockDisplay.test(style.display) ? block(t) : t);
+	}
+
+	const out = [];
+	walk(root, out);
+	return out.join('')
+		.split('\n').map((l) => l.replace(/[ \t]+$/, '')).join('\n')
+		.replace(/\n{3,}/g, '\n\n')
+		.trim();
+}`
+
+// mainContentScript evaluates to the element holding a page's main content.
+const mainContentScript = `document.querySelector('main, article, [role=main]') || document.body`
+
+// TextData is the response for text.
+type TextData struct {
+	Text string `json:"text"`
+}
+
+// Markdown returns the main content of the page as Markdown, which is far
+// smaller than the HTML and keeps the structure an agent needs to read it.
+func (m *BrowserManager) Markdown() (string, error) {
+	return m.ReadableText("", false)
+}
+
+// ReadableText returns the readable content of an element, or of the
+// page's main content when selector is empty, as Markdown or, with plain,
+// as plain text.
+func (m *BrowserManager) ReadableText(selector string, plain bool) (string, error) {
+	if selector != "" {
+		selector, err := m.resolveRef(selector)
+		if err != nil {
+			return "", err
+		}
+		return m.backend.ReadableText(selector, plain)
+	}
+	result, err := m.backend.Evaluate(fmt.Sprintf("(%s)(%s, %t)", markdownScript, mainContentScript, plain))
+	if err != nil {
+		return "", err
+	}
+	if result == nil {
+		return "", nil
+	}
+	text, ok := result.(string)
+	if !ok {
+		return "", fmt.Errorf("unexpected markdown result: %v", result)
+	}
+	return text, nil
+}
@@ -0,0 +1,52 @@
+package agentbrowser_test
+
+import (
+	"strings"
+	"testing"
+
+	agentbrowser "github.com/cpunion/agent-browser-go"
+)
+
+// TestTextCommand tests reading the page or an element as text
+func TestTextCommand(t *testing.T) {
+	var script, selector string
+	var plain bool
+	m := agentbrowser.NewBrowserManagerForTest(&fakeBackend{
+		evaluate: func(s string) (interface{}, error) {
+			script = s
+			return "# Page", nil
+		},
+		readableText: func(s string, p bool) (string, error) {
+			selector, plain = s, p
+			return "Element", nil
+		},
+		getRefMap:   func() agentbrowser.RefMap { return agentbrowser.RefMap{"e1": {Role: "article"}} },
+		getSnapshot: noSnapshot,
+	})
+	text := func(selector string, plain bool) agentbrowser.Response {
+		return agentbrowser.ExecuteCommand(&agentbrowser.TextCommand{
+			BaseCommand: agentbrowser.BaseCommand{ID: "1", Action: "text"},
+			Selector:    selector,
+			Plain:       plain,
+		}, m)
+	}
+
+	resp := text("", true)
+	if !resp.Success || string(resp.Data) != `{"text":"# Page"}` {
+		t.Errorf("text = %+v, want the page text", resp)
+	}
+	if !strings.HasSuffix(script, "document.body, true)") {
+		t.Errorf("evaluated %q, want the main content in plain mode", script)
+	}
+
+	if resp := text("@e1", false); !resp.Success || string(resp.Data) != `{"text":"Element"}` {
+		t.Errorf("text @e1 = %+v, want the element text", resp)
+	}
+	if selector != "@e1" || plain {
+		t.Errorf("backend got %q plain=%v, want @e1 as Markdown", selector, plain)
+	}
+
+	if resp := text("@e9", false); resp.Success || !strings.Contains(resp.Error, "@e9") {
+		t.Errorf("text @e9 = %+v, want a ref error", resp)
+	}
+}
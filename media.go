@@ -0,0 +1,55 @@
+package agentbrowser
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// MediaEmulation is the CSS media type and the user preference media
+// features pages see. Empty fields are not overridden.
+type MediaEmulation struct {
+	Media         string `json:"media,omitempty"`         // screen or print
+	ColorScheme   string `json:"colorScheme,omitempty"`   // light or dark
+	ReducedMotion string `json:"reducedMotion,omitempty"` // reduce or no-preference
+	ForcedColors  string `json:"forcedColors,omitempty"`  // active or none
+}
+
+// mediaNoOverride removes a field's override in EmulateMedia, as in
+// Playwright's page.emulateMedia.
+const mediaNoOverride = "no-override"
+
+// EmulateMedia changes the media type and features every tab, including
+// tabs opened later, matches in CSS and matchMedia, e.g. to capture a dark
+// mode screenshot or a print layout. Fields left empty keep their current
+// value and "no-override" removes that override. It returns the emulation
+// now in effect, which is kept when the browser relaunches.
+func (m *BrowserManager) EmulateMedia(change MediaEmulation) (MediaEmulation, error) {
+	media := m.media
+	fields := []struct {
+		name, value string
+		field       *string
+		values      []string
+	}{
+		{"media type", change.Media, &media.Media, []string{"screen", "print"}},
+		{"color scheme", change.ColorScheme, &media.ColorScheme, []string{"light", "dark"}},
+		{"reduced motion", change.ReducedMotion, &media.ReducedMotion, []string{"reduce", "no-preference"}},
+		{"forced colors", change.ForcedColors, &media.ForcedColors, []string{"active", "none"}},
+	}
+	for _, f := range fields {
+		switch {
+		case f.value == "":
+		case f.value == mediaNoOverride:
+			*f.field = ""
+		case slices.Contains(f.values, f.value):
+			*f.field = f.value
+		default:
+			return MediaEmulation{}, fmt.Errorf("invalid %s %q (expected %s or %s)", f.name, f.value, strings.Join(f.values, ", "), mediaNoOverride)
+		}
+	}
+	if err := m.backend.EmulateMedia(media); err != nil {
+		return MediaEmulation{}, err
+	}
+	m.media = media
+	return media, nil
+}
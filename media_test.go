@@ -0,0 +1,59 @@
+package agentbrowser_test
+
+import (
+	"testing"
+
+	agentbrowser "github.com/cpunion/agent-browser-go"
+)
+
+// TestEmulateMedia tests that changes add up and invalid values are
+// rejected
+func TestEmulateMedia(t *testing.T) {
+	var calls int
+	var emulated agentbrowser.MediaEmulation
+	m := agentbrowser.NewBrowserManagerForTest(&fakeBackend{
+		emulateMedia: func(media agentbrowser.MediaEmulation) error {
+			calls++
+			emulated = media
+			return nil
+		},
+	})
+
+	steps := []struct {
+		change agentbrowser.MediaEmulation
+		want   agentbrowser.MediaEmulation
+	}{
+		{agentbrowser.MediaEmulation{ColorScheme: "dark"}, agentbrowser.MediaEmulation{ColorScheme: "dark"}},
+		{agentbrowser.MediaEmulation{Media: "print", ReducedMotion: "reduce"}, agentbrowser.MediaEmulation{Media: "print", ColorScheme: "dark", ReducedMotion: "reduce"}},
+		{agentbrowser.MediaEmulation{ColorScheme: "no-override", ForcedColors: "active"}, agentbrowser.MediaEmulation{Media: "print", ReducedMotion: "reduce", ForcedColors: "active"}},
+	}
+	for _, step := range steps {
+		got, err := m.EmulateMedia(step.change)
+		if err != nil {
+			t.Fatalf("EmulateMedia(%+v) error = %v", step.change, err)
+		}
+		if got != step.want || emulated != step.want {
+			t.Errorf("EmulateMedia(%+v) = %+v, backend got %+v, want %+v", step.change, got, emulated, step.want)
+		}
+	}
+
+	before := calls
+	for _, change := range []agentbrowser.MediaEmulation{
+		{Media: "tv"},
+		{ColorScheme: "Dark"},
+		{ReducedMotion: "yes"},
+		{ForcedColors: "active", ColorScheme: "sepia"},
+	} {
+		if _, err := m.EmulateMedia(change); err == nil {
+			t.Errorf("EmulateMedia(%+v) expected an error", change)
+		}
+	}
+	if calls != before {
+		t.Errorf("backend called for invalid values")
+	}
+
+	reset := agentbrowser.MediaEmulation{Media: "no-override", ColorScheme: "no-override", ReducedMotion: "no-override", ForcedColors: "no-override"}
+	if got, err := m.EmulateMedia(reset); err != nil || got != (agentbrowser.MediaEmulation{}) {
+		t.Errorf("EmulateMedia(reset) = %+v, %v, want no emulation", got, err)
+	}
+}
@@ -0,0 +1,144 @@
+package agentbrowser
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// MemoryStats reports memory use per tab and per browser process.
+type MemoryStats struct {
+	Tabs      []TabMemory     `json:"tabs"`
+	Processes []ProcessMemory `json:"processes"`
+	TotalRSS  int64           `json:"totalRSS,omitempty"` // bytes, all browser processes
+}
+
+// TabMemory is the memory use of one tab, from CDP Performance.getMetrics.
+type TabMemory struct {
+	Index       int    `json:"index"`
+	URL         string `json:"url"`
+	JSHeapUsed  int64  `json:"jsHeapUsed"`  // bytes
+	JSHeapTotal int64  `json:"jsHeapTotal"` // bytes
+	Nodes       int    `json:"nodes"`       // DOM nodes, including detached ones not yet collected
+	Listeners   int    `json:"listeners"`   // JavaScript event listeners
+	Documents   int    `json:"documents"`
+	Frames      int    `json:"frames"`
+}
+
+// BrowserProcess is one process of the browser.
+type BrowserProcess struct {
+	PID  int    `json:"pid"`
+	Type string `json:"type"` // browser, renderer, GPU, utility...
+}
+
+// ProcessMemory is the resident memory of one browser process.
+type ProcessMemory struct {
+	BrowserProcess
+	RSS int64 `json:"rss,omitempty"` // bytes, absent where it cannot be read
+}
+
+// MemoryStats collects the memory use of every tab and browser process.
+// Renderers cannot be matched to tabs, so process memory is listed
+// separately.
+func (m *BrowserManager) MemoryStats() (*MemoryStats, error) {
+	tabs, err := m.backend.ListTabs()
+	if err != nil {
+		return nil, err
+	}
+	active := 0
+	for _, tab := range tabs {
+		if tab.Active {
+			active = tab.Index
+		}
+	}
+	defer m.backend.SwitchTab(active)
+
+	stats := &MemoryStats{Tabs: []TabMemory{}, Processes: []ProcessMemory{}}
+	for _, tab := range tabs {
+		if err := m.backend.SwitchTab(tab.Index); err != nil {
+			return nil, err
+		}
+		metrics, err := m.backend.PerformanceMetrics()
+		if err != nil {
+			return nil, fmt.Errorf("tab %d: %w", tab.Index, err)
+		}
+		stats.Tabs = append(stats.Tabs, TabMemory{
+			Index:       tab.Index,
+			URL:         tab.URL,
+			JSHeapUsed:  int64(metrics["JSHeapUsedSize"]),
+			JSHeapTotal: int64(metrics["JSHeapTotalSize"]),
+			Nodes:       int(metrics["Nodes"]),
+			Listeners:   int(metrics["JSEventListeners"]),
+			Documents:   int(metrics["Documents"]),
+			Frames:      int(metrics["Frames"]),
+		})
+	}
+
+	processes, err := m.backend.BrowserProcesses()
+	if err != nil {
+		return nil, err
+	}
+	pids := make([]int, len(processes))
+	for i, p := range processes {
+		pids[i] = p.PID
+	}
+	rss := processRSS(pids)
+	for _, p := range processes {
+		stats.Processes = append(stats.Processes, ProcessMemory{BrowserProcess: p, RSS: rss[p.PID]})
+		stats.TotalRSS += rss[p.PID]
+	}
+	return stats, nil
+}
+
+// HeapSnapshot writes a V8 heap snapshot of the active tab to path, to be
+// loaded in the Memory panel of Chrome DevTools.
+func (m *BrowserManager) HeapSnapshot(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := m.backend.HeapSnapshot(f); err != nil {
+		f.Close()
+		os.Remove(path)
+		return err
+	}
+	return f.Close()
+}
+
+// processRSS reads the resident set size of processes with ps. It returns
+// nothing on Windows or when ps fails.
+func processRSS(pids []int) map[int]int64 {
+	rss := make(map[int]int64)
+	if runtime.GOOS == "windows" || len(pids) == 0 {
+		return rss
+	}
+	list := make([]string, len(pids))
+	for i, pid := range pids {
+		list[i] = strconv.Itoa(pid)
+	}
+	out, err := exec.Command("ps", "-o", "pid=,rss=", "-p", strings.Join(list, ",")).Output()
+	if err != nil && len(out) == 0 {
+		return rss
+	}
+	return parsePSRSS(string(out))
+}
+
+// parsePSRSS parses "pid rss" lines, rss in KiB, into bytes by pid.
+func parsePSRSS(out string) map[int]int64 {
+	rss := make(map[int]int64)
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+		pid, err1 := strconv.Atoi(fields[0])
+		kib, err2 := strconv.ParseInt(fields[1], 10, 64)
+		if err1 == nil && err2 == nil {
+			rss[pid] = kib * 1024
+		}
+	}
+	return rss
+}
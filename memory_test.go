@@ -0,0 +1,66 @@
+package agentbrowser_test
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	agentbrowser "github.com/cpunion/agent-browser-go"
+)
+
+// TestMemoryStats tests collecting memory use across tabs and processes
+func TestMemoryStats(t *testing.T) {
+	urls := []string{"https://a.com/", "https://b.com/"}
+	metrics := []map[string]float64{
+		{"JSHeapUsedSize": 1 << 20, "JSHeapTotalSize": 2 << 20, "Nodes": 100, "JSEventListeners": 10, "Documents": 1, "Frames": 1},
+		{"JSHeapUsedSize": 3 << 20, "JSHeapTotalSize": 4 << 20, "Nodes": 5000, "JSEventListeners": 900, "Documents": 2, "Frames": 2},
+	}
+	active := 1
+	backend := &fakeBackend{
+		listTabs: func() ([]agentbrowser.TabInfo, error) {
+			tabs := make([]agentbrowser.TabInfo, len(urls))
+			for i, url := range urls {
+				tabs[i] = agentbrowser.TabInfo{Index: i, URL: url, Active: i == active}
+			}
+			return tabs, nil
+		},
+		switchTab: func(index int) error {
+			active = index
+			return nil
+		},
+		performanceMetrics: func() (map[string]float64, error) { return metrics[active], nil },
+		browserProcesses: func() ([]agentbrowser.BrowserProcess, error) {
+			return []agentbrowser.BrowserProcess{{PID: os.Getpid(), Type: "browser"}}, nil
+		},
+	}
+
+	stats, err := agentbrowser.NewBrowserManagerForTest(backend).MemoryStats()
+	if err != nil {
+		t.Fatalf("MemoryStats() error = %v", err)
+	}
+	want := []agentbrowser.TabMemory{
+		{Index: 0, URL: "https://a.com/", JSHeapUsed: 1 << 20, JSHeapTotal: 2 << 20, Nodes: 100, Listeners: 10, Documents: 1, Frames: 1},
+		{Index: 1, URL: "https://b.com/", JSHeapUsed: 3 << 20, JSHeapTotal: 4 << 20, Nodes: 5000, Listeners: 900, Documents: 2, Frames: 2},
+	}
+	if !reflect.DeepEqual(stats.Tabs, want) {
+		t.Errorf("Tabs = %+v, want %+v", stats.Tabs, want)
+	}
+	if active != 1 {
+		t.Errorf("active tab = %d after MemoryStats, want 1", active)
+	}
+	if len(stats.Processes) != 1 || stats.Processes[0].Type != "browser" {
+		t.Fatalf("Processes = %+v", stats.Processes)
+	}
+	if stats.Processes[0].RSS != stats.TotalRSS {
+		t.Errorf("TotalRSS = %d, want %d", stats.TotalRSS, stats.Processes[0].RSS)
+	}
+}
+
+// TestParsePSRSS tests reading ps output
+func TestParsePSRSS(t *testing.T) {
+	got := agentbrowser.ParsePSRSS("  123  2048\n 45 10\n\nbad line here\n")
+	want := map[int]int64{123: 2048 * 1024, 45: 10 * 1024}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParsePSRSS() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,116 @@
+package agentbrowser
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// NavigationRequest is a navigation that sends a custom request: another
+// method, a body, extra headers or a referrer.
+type NavigationRequest struct {
+	URL      string
+	Method   string            // default GET, or POST when Body is set
+	Body     string            // request body, e.g. form data or JSON
+	Headers  map[string]string // extra headers, e.g. Content-Type
+	Referrer string            // Referer header and document.referrer
+}
+
+// method returns the HTTP method of the request.
+func (r NavigationRequest) method() string {
+	switch {
+	case r.Method != "":
+		return strings.ToUpper(r.Method)
+	case r.Body != "":
+		return "POST"
+	default:
+		return "GET"
+	}
+}
+
+// rewritesRequest reports whether the request differs from a GET beyond
+// its referrer, which navigation supports directly.
+func (r NavigationRequest) rewritesRequest() bool {
+	return r.method() != "GET" || r.Body != "" || len(r.Headers) > 0
+}
+
+// plain reports whether the request is an ordinary GET navigation.
+func (r NavigationRequest) plain() bool {
+	return !r.rewritesRequest() && r.Referrer == ""
+}
+
+// mergeHeaders returns the request's headers with extra set over them.
+// Header names compare case-insensitively; the result uses lowercase names.
+func mergeHeaders(headers map[string]string, extra map[string]string) map[string]string {
+	merged := make(map[string]string, len(headers)+len(extra))
+	for name, value := range headers {
+		merged[strings.ToLower(name)] = value
+	}
+	for name, value := range extra {
+		merged[strings.ToLower(name)] = value
+	}
+	return merged
+}
+
+// NavigateRequest navigates with a custom method, body, headers or
+// referrer, for endpoints that only answer POST or check the referrer.
+// Only the navigation request itself is changed; redirects and
+// subresources are sent as usual.
+func (m *BrowserManager) NavigateRequest(req NavigationRequest, waitUntil string) (string, string, error) {
+	if err := m.checkRobots(req.URL); err != nil {
+		return "", "", err
+	}
+	if err := m.rotateForNavigation(); err != nil {
+		return "", "", err
+	}
+	m.waitRateLimit()
+	if req.plain() {
+		return m.backend.Navigate(req.URL, waitUntil)
+	}
+	return m.backend.NavigateRequest(req, waitUntil)
+}
+
+// LocalFileURL returns the file:// URL for target when it names a local
+// file: a path starting with ./, ../, / or ~/, an existing file, or a
+// file: URL with a relative path. Relative paths resolve against the
+// working directory, so callers resolve them before handing the URL to
+// the daemon.
+func LocalFileURL(target string) (string, bool) {
+	path := target
+	switch {
+	case strings.HasPrefix(target, "file://"):
+		rest := strings.TrimPrefix(target, "file://")
+		if strings.HasPrefix(rest, "/") || !strings.HasPrefix(rest, ".") {
+			// Absolute, or a host such as file://localhost/...
+			return target, true
+		}
+		path = rest
+	case strings.HasPrefix(target, "file:"):
+		path = strings.TrimPrefix(target, "file:")
+	case strings.HasPrefix(target, "~/"):
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", false
+		}
+		path = filepath.Join(home, target[2:])
+	case strings.HasPrefix(target, "./"), strings.HasPrefix(target, "../"),
+		strings.HasPrefix(target, "/"), filepath.IsAbs(target):
+	default:
+		if strings.Contains(target, "://") {
+			return "", false
+		}
+		if info, err := os.Stat(target); err != nil || info.IsDir() {
+			return "", false
+		}
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", false
+	}
+	slashed := filepath.ToSlash(abs)
+	if !strings.HasPrefix(slashed, "/") {
+		slashed = "/" + slashed // Windows drive letter
+	}
+	return (&url.URL{Scheme: "file", Path: slashed}).String(), true
+}
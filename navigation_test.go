@@ -0,0 +1,120 @@
+package agentbrowser_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	agentbrowser "github.com/cpunion/agent-browser-go"
+)
+
+// navigateBackend is a fake backend that appends the URLs of plain
+// navigations to *plain and custom requests to *requests.
+func navigateBackend(plain *[]string, requests *[]agentbrowser.NavigationRequest) *fakeBackend {
+	return &fakeBackend{
+		navigate: func(url string, waitUntil string) (string, string, error) {
+			*plain = append(*plain, url)
+			return url, "", nil
+		},
+		navigateRequest: func(req agentbrowser.NavigationRequest, waitUntil string) (string, string, error) {
+			*requests = append(*requests, req)
+			return req.URL, "", nil
+		},
+	}
+}
+
+// TestNavigateRequest tests that only custom requests use request rewriting
+func TestNavigateRequest(t *testing.T) {
+	tests := []struct {
+		name      string
+		req       agentbrowser.NavigationRequest
+		rewritten bool
+	}{
+		{"plain", agentbrowser.NavigationRequest{URL: "https://example.com"}, false},
+		{"explicit GET", agentbrowser.NavigationRequest{URL: "https://example.com", Method: "get"}, false},
+		{"body", agentbrowser.NavigationRequest{URL: "https://example.com", Body: "a=1"}, true},
+		{"method", agentbrowser.NavigationRequest{URL: "https://example.com", Method: "DELETE"}, true},
+		{"headers", agentbrowser.NavigationRequest{URL: "https://example.com", Headers: map[string]string{"X-A": "1"}}, true},
+		{"referrer", agentbrowser.NavigationRequest{URL: "https://example.com", Referrer: "https://news.example.com/"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var plain []string
+			var requests []agentbrowser.NavigationRequest
+			m := agentbrowser.NewBrowserManagerForTest(navigateBackend(&plain, &requests))
+			if _, _, err := m.NavigateRequest(tt.req, "load"); err != nil {
+				t.Fatalf("NavigateRequest() error = %v", err)
+			}
+			if tt.rewritten {
+				if !reflect.DeepEqual(requests, []agentbrowser.NavigationRequest{tt.req}) || len(plain) != 0 {
+					t.Errorf("expected request navigation, got plain=%q request=%+v", plain, requests)
+				}
+			} else if len(plain) != 1 || len(requests) != 0 {
+				t.Errorf("expected plain navigation, got plain=%q request=%+v", plain, requests)
+			}
+		})
+	}
+}
+
+// TestNavigateWaitUntil tests that unknown load states are rejected before navigating
+func TestNavigateWaitUntil(t *testing.T) {
+	var plain []string
+	var requests []agentbrowser.NavigationRequest
+	m := agentbrowser.NewBrowserManagerForTest(navigateBackend(&plain, &requests))
+	resp := agentbrowser.ExecuteCommand(&agentbrowser.NavigateCommand{
+		BaseCommand: agentbrowser.BaseCommand{ID: "1", Action: "navigate"},
+		URL:         "https://example.com",
+		WaitUntil:   "idle",
+	}, m)
+	if resp.Success || !strings.Contains(resp.Error, "networkidle") {
+		t.Errorf("navigate with waitUntil idle = %+v, want an error listing the states", resp)
+	}
+	if len(plain) != 0 || len(requests) != 0 {
+		t.Errorf("navigated with an unknown waitUntil: plain=%q request=%+v", plain, requests)
+	}
+}
+
+// TestLocalFileURL tests that local paths become file:// URLs
+func TestLocalFileURL(t *testing.T) {
+	dir := t.TempDir()
+	page := filepath.Join(dir, "report.html")
+	if err := os.WriteFile(page, []byte("<h1>Report</h1>"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fileURL := func(path string) string {
+		return "file://" + filepath.ToSlash(path)
+	}
+
+	tests := []struct {
+		target string
+		want   string
+		ok     bool
+	}{
+		{page, fileURL(page), true},
+		{"./report.html", fileURL(filepath.Join(wd, "report.html")), true},
+		{"../out/a b.html", "file://" + filepath.ToSlash(filepath.Join(filepath.Dir(wd), "out")) + "/a%20b.html", true},
+		{"file:report.html", fileURL(filepath.Join(wd, "report.html")), true},
+		{"file://./report.html", fileURL(filepath.Join(wd, "report.html")), true},
+		{"file:///tmp/page.html", "file:///tmp/page.html", true},
+		{"navigation_test.go", fileURL(filepath.Join(wd, "navigation_test.go")), true},
+		{"example.com", "", false},
+		{"https://example.com/a.html", "", false},
+		{"about:blank", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.target, func(t *testing.T) {
+			got, ok := agentbrowser.LocalFileURL(tt.target)
+			if got != tt.want || ok != tt.ok {
+				t.Errorf("LocalFileURL(%q) = %q, %v, want %q, %v", tt.target, got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
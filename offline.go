@@ -0,0 +1,9 @@
+package agentbrowser
+
+// SetOffline cuts every tab, including tabs opened later, off the network,
+// or reconnects them, so offline-first pages and service worker fallbacks
+// can be tested. Requests fail as they do without a connection and
+// navigator.onLine reports false. It is kept when the browser relaunches.
+func (m *BrowserManager) SetOffline(offline bool) error {
+	return m.backend.SetOffline(offline)
+}
@@ -0,0 +1,254 @@
+package agentbrowser
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// ExtractSpec describes records to extract from a page. Item selects one
+// element per record; without it the whole page is one record. Fields map
+// record keys to selectors relative to the item: "h3" takes the text of
+// the first match, "a@href" an attribute and "" or "." the item itself.
+type ExtractSpec struct {
+	Item   string            `json:"item,omitempty"`
+	Fields map[string]string `json:"fields"`
+}
+
+// PaginateOptions configures Paginate.
+type PaginateOptions struct {
+	Next     string       // next-page control: CSS selector or text=Label
+	Extract  *ExtractSpec // records to extract on each page, optional
+	MaxPages int          // stop after this many pages
+	Timeout  time.Duration
+}
+
+// PaginateResult holds the records extracted from every page visited.
+type PaginateResult struct {
+	Items   []map[string]interface{} `json:"items"`
+	Visited []string                 `json:"visited"` // URL of each page
+	Reason  string                   `json:"reason"`  // no-next, disabled, no-change, repeat or max-pages
+}
+
+const (
+	defaultPaginatePages   = 20
+	defaultPaginateTimeout = 10 * time.Second
+)
+
+// paginateNextAttr marks the next-page control so it can be clicked with a
+// plain CSS selector on every backend.
+const paginateNextAttr = "data-agent-browser-next"
+
+// paginateNextScript finds the next-page control. A text= selector matches
+// links and buttons by their text, exactly when quoted and as a
+// case-insensitive substring otherwise, like Playwright's text engine.
+const paginateNextScript = `((selector, attr) => {
+	document.querySelectorAll('[' + attr + ']').forEach(el => el.removeAttribute(attr));
+	const norm = s => (s || '').replace(/\s+/g, ' ').trim();
+	let el = null;
+	if (selector.startsWith('text=')) {
+		let text = selector.slice(5);
+		const exact = text.length > 1 && text.startsWith('"') && text.endsWith('"');
+		text = exact ? text.slice(1, -1) : text.toLowerCase();
+		const controls = document.querySelectorAll('a, button, [role=link], [role=button], input[type=submit], input[type=button]');
+		for (const c of controls) {
+			const label = norm(c.innerText || c.value || c.getAttribute('aria-label'));
+			if (exact ? label === text : label.toLowerCase().includes(text)) {
+				el = c;
+				break;
+			}
+		}
+	} else {
+		el = document.querySelector(selector);
+	}
+	if (!el) return { found: false };
+	el.setAttribute(attr, '');
+	const disabled = el.disabled || el.getAttribute('aria-disabled') === 'true' ||
+		el.classList.contains('disabled');
+	const raw = el.getAttribute('href') || '';
+	const href = el.tagName === 'A' && raw && !raw.startsWith('#') && !raw.startsWith('javascript:') ? el.href : '';
+	return { found: true, disabled, href };
+})(%s, %q)`
+
+// extractScript extracts records according to an ExtractSpec.
+const extractScript = `((spec) => {
+	const value = (root, sel) => {
+		let attr = '';
+		const at = sel.lastIndexOf('@');
+		if (at >= 0) {
+			attr = sel.slice(at + 1);
+			sel = sel.slice(0, at);
+		}
+		sel = sel.trim();
+		const el = sel === '' || sel === '.' ? root : root.querySelector(sel);
+		if (!el) return null;
+		if (!attr) return (el.innerText || el.textContent || '').replace(/\s+/g, ' ').trim();
+		if ((attr === 'href' || attr === 'src') && el[attr]) return el[attr];
+		return el.getAttribute(attr);
+	};
+	const roots = spec.item ? Array.from(document.querySelectorAll(spec.item)) : [document.body];
+	return roots.map(root => {
+		const record = {};
+		for (const [key, sel] of Object.entries(spec.fields || {})) record[key] = value(root, sel);
+		return record;
+	});
+})(%s)`
+
+// pageSignatureScript summarizes the page content, to tell when a
+// JavaScript pager has rendered the next page.
+const pageSignatureScript = `(() => location.href + '\n' + (document.body ? document.body.innerText : ''))()`
+
+// Paginate extracts records from the current page and follows the next-page
+// control until it disappears or is disabled, the page stops changing, or
+// opts.MaxPages pages were visited. Links are navigated to directly; other
+// controls are clicked and the page is watched for new content.
+func (m *BrowserManager) Paginate(opts PaginateOptions) (*PaginateResult, error) {
+	if opts.Next == "" {
+		return nil, fmt.Errorf("paginate requires a next-page selector")
+	}
+	maxPages := opts.MaxPages
+	if maxPages <= 0 {
+		maxPages = defaultPaginatePages
+	}
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = defaultPaginateTimeout
+	}
+
+	result := &PaginateResult{Items: []map[string]interface{}{}, Visited: []string{}}
+	visited := make(map[string]bool)
+	for {
+		current, err := m.backend.URL()
+		if err != nil {
+			return nil, err
+		}
+		result.Visited = append(result.Visited, current)
+		visited[crawlKeyString(current)] = true
+
+		if opts.Extract != nil {
+			items, err := m.Extract(*opts.Extract)
+			if err != nil {
+				return nil, err
+			}
+			result.Items = append(result.Items, items...)
+		}
+		if len(result.Visited) >= maxPages {
+			result.Reason = "max-pages"
+			return result, nil
+		}
+
+		next, err := m.findNextPage(opts.Next)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case !next.Found:
+			result.Reason = "no-next"
+			return result, nil
+		case next.Disabled:
+			result.Reason = "disabled"
+			return result, nil
+		}
+
+		if next.Href != "" {
+			if visited[crawlKeyString(next.Href)] {
+				result.Reason = "repeat"
+				return result, nil
+			}
+			if _, _, err := m.Navigate(next.Href, "load"); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		before, err := m.pageSignature()
+		if err != nil {
+			return nil, err
+		}
+		if err := m.backend.Click(fmt.Sprintf("[%s]", paginateNextAttr)); err != nil {
+			return nil, err
+		}
+		if !m.waitForPageChange(before, timeout) {
+			result.Reason = "no-change"
+			return result, nil
+		}
+	}
+}
+
+// Extract returns the records spec describes on the current page.
+func (m *BrowserManager) Extract(spec ExtractSpec) ([]map[string]interface{}, error) {
+	specJSON, _ := json.Marshal(spec)
+	value, err := m.backend.Evaluate(fmt.Sprintf(extractScript, specJSON))
+	if err != nil {
+		return nil, err
+	}
+	data, _ := json.Marshal(value)
+	var items []map[string]interface{}
+	if err := json.Unmarshal(data, &items); err != nil {
+		return nil, fmt.Errorf("unexpected extraction result: %w", err)
+	}
+	return items, nil
+}
+
+type nextPage struct {
+	Found    bool   `json:"found"`
+	Disabled bool   `json:"disabled"`
+	Href     string `json:"href"`
+}
+
+// findNextPage locates the next-page control and marks it for clicking.
+func (m *BrowserManager) findNextPage(selector string) (*nextPage, error) {
+	selJSON, _ := json.Marshal(selector)
+	value, err := m.backend.Evaluate(fmt.Sprintf(paginateNextScript, selJSON, paginateNextAttr))
+	if err != nil {
+		return nil, err
+	}
+	data, _ := json.Marshal(value)
+	var next nextPage
+	if err := json.Unmarshal(data, &next); err != nil {
+		return nil, fmt.Errorf("unexpected next-page result: %w", err)
+	}
+	return &next, nil
+}
+
+// waitForPageChange waits for the page signature to differ from before and
+// then hold still for a moment, so the new page has finished rendering.
+func (m *BrowserManager) waitForPageChange(before string, timeout time.Duration) bool {
+	deadline := time.Now().Add(timeout)
+	last := before
+	changed := false
+	for time.Now().Before(deadline) {
+		time.Sleep(200 * time.Millisecond)
+		now, err := m.pageSignature()
+		if err != nil {
+			continue // navigating
+		}
+		if changed && now == last {
+			return true
+		}
+		if now != before {
+			changed = true
+		}
+		last = now
+	}
+	return changed
+}
+
+// pageSignature summarizes the current page, see pageSignatureScript.
+func (m *BrowserManager) pageSignature() (string, error) {
+	value, err := m.backend.Evaluate(pageSignatureScript)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprint(value), nil
+}
+
+// crawlKeyString is crawlKey for unparsed URLs.
+func crawlKeyString(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	return crawlKey(u)
+}
@@ -0,0 +1,150 @@
+package agentbrowser_test
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	agentbrowser "github.com/cpunion/agent-browser-go"
+)
+
+// pager describes numbered result pages linked by a next control.
+type pager struct {
+	pages    int  // number of pages
+	links    bool // next is a link to ?page=n rather than a JavaScript button
+	disabled bool // the last page keeps a disabled next control
+	stuck    bool // clicking next does nothing
+}
+
+// backend returns a fake backend serving the pages, from the first.
+func (p pager) backend() *fakeBackend {
+	page := 0
+	return &fakeBackend{
+		url: func() (string, error) {
+			if p.links {
+				return fmt.Sprintf("https://a.com/?page=%d", page), nil
+			}
+			return "https://a.com/", nil
+		},
+		navigate: func(url string, waitUntil string) (string, string, error) {
+			fmt.Sscanf(url, "https://a.com/?page=%d", &page)
+			return url, "results", nil
+		},
+		click: func(selector string) error {
+			if selector != "[data-agent-browser-next]" {
+				return fmt.Errorf("unexpected click on %s", selector)
+			}
+			if !p.stuck {
+				page++
+			}
+			return nil
+		},
+		evaluate: func(script string) (interface{}, error) {
+			switch {
+			case strings.Contains(script, "Object.entries(spec.fields"):
+				return []interface{}{
+					map[string]interface{}{"title": fmt.Sprintf("result %d.1", page)},
+					map[string]interface{}{"title": fmt.Sprintf("result %d.2", page)},
+				}, nil
+			case strings.Contains(script, "setAttribute(attr"):
+				if page == p.pages-1 {
+					if p.disabled {
+						return map[string]interface{}{"found": true, "disabled": true, "href": ""}, nil
+					}
+					return map[string]interface{}{"found": false}, nil
+				}
+				href := ""
+				if p.links {
+					href = fmt.Sprintf("https://a.com/?page=%d", page+1)
+				}
+				return map[string]interface{}{"found": true, "disabled": false, "href": href}, nil
+			default:
+				return fmt.Sprintf("page %d", page), nil
+			}
+		},
+	}
+}
+
+// TestPaginate tests following link and JavaScript pagers with extraction
+func TestPaginate(t *testing.T) {
+	spec := &agentbrowser.ExtractSpec{Item: ".result", Fields: map[string]string{"title": "h3"}}
+	tests := []struct {
+		name        string
+		pager       pager
+		opts        agentbrowser.PaginateOptions
+		wantItems   int
+		wantVisited int
+		wantReason  string
+	}{
+		{
+			name:        "links",
+			pager:       pager{pages: 3, links: true},
+			opts:        agentbrowser.PaginateOptions{Next: "text=Next", Extract: spec},
+			wantItems:   6,
+			wantVisited: 3,
+			wantReason:  "no-next",
+		},
+		{
+			name:        "javascript pager",
+			pager:       pager{pages: 3, disabled: true},
+			opts:        agentbrowser.PaginateOptions{Next: "button.next", Extract: spec},
+			wantItems:   6,
+			wantVisited: 3,
+			wantReason:  "disabled",
+		},
+		{
+			name:        "max pages",
+			pager:       pager{pages: 10, links: true},
+			opts:        agentbrowser.PaginateOptions{Next: "text=Next", Extract: spec, MaxPages: 4},
+			wantItems:   8,
+			wantVisited: 4,
+			wantReason:  "max-pages",
+		},
+		{
+			name:        "no change",
+			pager:       pager{pages: 3, stuck: true},
+			opts:        agentbrowser.PaginateOptions{Next: "button.next", Timeout: 500 * time.Millisecond},
+			wantItems:   0,
+			wantVisited: 1,
+			wantReason:  "no-change",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := agentbrowser.NewBrowserManagerForTest(tt.pager.backend())
+			got, err := m.Paginate(tt.opts)
+			if err != nil {
+				t.Fatalf("Paginate() error = %v", err)
+			}
+			if len(got.Items) != tt.wantItems || len(got.Visited) != tt.wantVisited || got.Reason != tt.wantReason {
+				t.Errorf("Paginate() = %d items, %d pages, %q; want %d, %d, %q",
+					len(got.Items), len(got.Visited), got.Reason, tt.wantItems, tt.wantVisited, tt.wantReason)
+			}
+		})
+	}
+
+	t.Run("records in page order", func(t *testing.T) {
+		m := agentbrowser.NewBrowserManagerForTest(pager{pages: 2, links: true}.backend())
+		got, err := m.Paginate(agentbrowser.PaginateOptions{Next: "text=Next", Extract: spec})
+		if err != nil {
+			t.Fatalf("Paginate() error = %v", err)
+		}
+		want := []map[string]interface{}{
+			{"title": "result 0.1"}, {"title": "result 0.2"},
+			{"title": "result 1.1"}, {"title": "result 1.2"},
+		}
+		if !reflect.DeepEqual(got.Items, want) {
+			t.Errorf("Items = %v, want %v", got.Items, want)
+		}
+		if want := []string{"https://a.com/?page=0", "https://a.com/?page=1"}; !reflect.DeepEqual(got.Visited, want) {
+			t.Errorf("Visited = %q, want %q", got.Visited, want)
+		}
+	})
+
+	if _, err := agentbrowser.NewBrowserManagerForTest(pager{}.backend()).Paginate(agentbrowser.PaginateOptions{}); err == nil {
+		t.Error("Paginate() without next selector error = nil, want error")
+	}
+}
@@ -0,0 +1,90 @@
+package agentbrowser
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// PauseData is the response for pause and resume.
+type PauseData struct {
+	Paused    bool  `json:"paused"`
+	PausedFor int64 `json:"pausedFor,omitempty"` // ms the session was paused, on resume
+}
+
+// pauseGate holds a session's commands back while a person uses the
+// browser, e.g. to solve a CAPTCHA or enter a 2FA code.
+type pauseGate struct {
+	lock    sync.Mutex
+	resumed chan struct{} // closed on resume, nil while running
+	since   time.Time
+}
+
+// pause starts holding commands back.
+func (g *pauseGate) pause() error {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	if g.resumed != nil {
+		return fmt.Errorf("session is already paused")
+	}
+	g.resumed = make(chan struct{})
+	g.since = time.Now()
+	return nil
+}
+
+// resume releases the held commands and returns how long the session was
+// paused.
+func (g *pauseGate) resume() (time.Duration, error) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	if g.resumed == nil {
+		return 0, fmt.Errorf("session is not paused")
+	}
+	close(g.resumed)
+	g.resumed = nil
+	return time.Since(g.since), nil
+}
+
+// wait blocks while the session is paused. It returns false if stop is
+// closed first.
+func (g *pauseGate) wait(stop <-chan struct{}) bool {
+	g.lock.Lock()
+	resumed := g.resumed
+	g.lock.Unlock()
+	if resumed == nil {
+		return true
+	}
+	select {
+	case <-resumed:
+		return true
+	case <-stop:
+		return false
+	}
+}
+
+// Pause holds back the session's commands until Resume, so a person can use
+// the headed browser. Commands sent meanwhile wait and run after Resume.
+func (d *Daemon) Pause() error {
+	if !d.browser.IsLaunched() {
+		return ErrBrowserNotLaunched
+	}
+	if d.browser.Headless() {
+		return fmt.Errorf("pause needs a headed browser; relaunch the session with --headed")
+	}
+	if err := d.pause.pause(); err != nil {
+		return err
+	}
+	Logger().Info("paused; waiting for resume")
+	return nil
+}
+
+// Resume runs the commands held back by Pause and returns how long the
+// session was paused.
+func (d *Daemon) Resume() (time.Duration, error) {
+	paused, err := d.pause.resume()
+	if err != nil {
+		return 0, err
+	}
+	Logger().Info("resumed", "after", paused.Round(time.Second))
+	return paused, nil
+}
@@ -0,0 +1,53 @@
+package agentbrowser_test
+
+import (
+	"testing"
+	"time"
+
+	agentbrowser "github.com/cpunion/agent-browser-go"
+)
+
+// TestPauseGate tests that commands wait while paused and run on resume
+func TestPauseGate(t *testing.T) {
+	var g agentbrowser.PauseGate
+	stop := make(chan struct{})
+	if !g.Wait(stop) {
+		t.Fatal("Wait() while running = false, want true")
+	}
+	if _, err := g.Resume(); err == nil {
+		t.Error("Resume() while running expected an error")
+	}
+
+	if err := g.Pause(); err != nil {
+		t.Fatalf("Pause() error = %v", err)
+	}
+	if err := g.Pause(); err == nil {
+		t.Error("Pause() while paused expected an error")
+	}
+	done := make(chan bool)
+	go func() { done <- g.Wait(stop) }()
+	select {
+	case <-done:
+		t.Fatal("Wait() returned while paused")
+	case <-time.After(50 * time.Millisecond):
+	}
+	paused, err := g.Resume()
+	if err != nil {
+		t.Fatalf("Resume() error = %v", err)
+	}
+	if !<-done {
+		t.Error("Wait() after resume = false, want true")
+	}
+	if paused < 50*time.Millisecond {
+		t.Errorf("Resume() = %v, want the time paused", paused)
+	}
+
+	// Stopping the daemon releases waiting commands without running them
+	if err := g.Pause(); err != nil {
+		t.Fatalf("Pause() error = %v", err)
+	}
+	close(stop)
+	if g.Wait(stop) {
+		t.Error("Wait() after stop = true, want false")
+	}
+}
@@ -0,0 +1,131 @@
+package agentbrowser
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// PDFOptions controls how a page is printed to PDF.
+type PDFOptions struct {
+	Format          string     `json:"format,omitempty"` // paper size: Letter (default), Legal, Tabloid, Ledger or A0-A6
+	Landscape       bool       `json:"landscape,omitempty"`
+	PrintBackground bool       `json:"printBackground,omitempty"`
+	Margin          *PDFMargin `json:"margin,omitempty"`
+}
+
+// PDFMargin holds page margins as CSS lengths such as 1cm, 0.5in, 10mm or
+// 20px. A plain number is in pixels; empty is no margin.
+type PDFMargin struct {
+	Top    string `json:"top,omitempty"`
+	Right  string `json:"right,omitempty"`
+	Bottom string `json:"bottom,omitempty"`
+	Left   string `json:"left,omitempty"`
+}
+
+// paperSizes are the paper formats in inches, width by height, as in
+// Playwright.
+var paperSizes = map[string][2]float64{
+	"Letter":  {8.5, 11},
+	"Legal":   {8.5, 14},
+	"Tabloid": {11, 17},
+	"Ledger":  {17, 11},
+	"A0":      {33.1, 46.8},
+	"A1":      {23.4, 33.1},
+	"A2":      {16.54, 23.4},
+	"A3":      {11.7, 16.54},
+	"A4":      {8.27, 11.7},
+	"A5":      {5.83, 8.27},
+	"A6":      {4.13, 5.83},
+}
+
+// paperSize returns the canonical name and size in inches of a paper
+// format, matched case-insensitively. Empty is Letter.
+func paperSize(format string) (string, [2]float64, error) {
+	if format == "" {
+		format = "Letter"
+	}
+	for name, size := range paperSizes {
+		if strings.EqualFold(name, format) {
+			return name, size, nil
+		}
+	}
+	names := make([]string, 0, len(paperSizes))
+	for name := range paperSizes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return "", [2]float64{}, fmt.Errorf("unknown paper format %q (expected %s)", format, strings.Join(names, ", "))
+}
+
+// cssInches converts a CSS length in px, in, cm or mm to inches.
+func cssInches(length string) (float64, error) {
+	if length == "" {
+		return 0, nil
+	}
+	units := map[string]float64{"px": 1.0 / 96, "in": 1, "cm": 1 / 2.54, "mm": 1 / 25.4}
+	value, scale := strings.TrimSpace(strings.ToLower(length)), units["px"]
+	for unit, s := range units {
+		if strings.HasSuffix(value, unit) {
+			value, scale = strings.TrimSuffix(value, unit), s
+			break
+		}
+	}
+	n, err := strconv.ParseFloat(value, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid length %q (expected a number with px, in, cm or mm)", length)
+	}
+	return n * scale, nil
+}
+
+// inches returns the margins in inches.
+func (m *PDFMargin) inches() (top, right, bottom, left float64, err error) {
+	if m == nil {
+		return 0, 0, 0, 0, nil
+	}
+	values := make([]float64, 4)
+	for i, length := range []string{m.Top, m.Right, m.Bottom, m.Left} {
+		if values[i], err = cssInches(length); err != nil {
+			return 0, 0, 0, 0, err
+		}
+	}
+	return values[0], values[1], values[2], values[3], nil
+}
+
+// ParsePDFMargin parses margins written like the CSS margin shorthand: one
+// length for all sides, two for vertical and horizontal, three for top,
+// horizontal and bottom, or four for top, right, bottom and left.
+func ParsePDFMargin(s string) (*PDFMargin, error) {
+	v := strings.Fields(s)
+	var m PDFMargin
+	switch len(v) {
+	case 1:
+		m = PDFMargin{v[0], v[0], v[0], v[0]}
+	case 2:
+		m = PDFMargin{v[0], v[1], v[0], v[1]}
+	case 3:
+		m = PDFMargin{v[0], v[1], v[2], v[1]}
+	case 4:
+		m = PDFMargin{v[0], v[1], v[2], v[3]}
+	default:
+		return nil, fmt.Errorf("invalid margin %q (expected 1 to 4 lengths)", s)
+	}
+	if _, _, _, _, err := m.inches(); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// PDF prints the current page to PDF. Only headless Chrome can print.
+func (m *BrowserManager) PDF(opts PDFOptions) ([]byte, error) {
+	format, _, err := paperSize(opts.Format)
+	if err != nil {
+		return nil, err
+	}
+	opts.Format = format
+	if _, _, _, _, err := opts.Margin.inches(); err != nil {
+		return nil, err
+	}
+	return m.backend.PDF(opts)
+}
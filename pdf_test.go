@@ -0,0 +1,71 @@
+package agentbrowser_test
+
+import (
+	"reflect"
+	"testing"
+
+	agentbrowser "github.com/cpunion/agent-browser-go"
+)
+
+// TestParsePDFMargin tests the CSS-like margin shorthand
+func TestParsePDFMargin(t *testing.T) {
+	tests := []struct {
+		in   string
+		want agentbrowser.PDFMargin
+	}{
+		{"1cm", agentbrowser.PDFMargin{Top: "1cm", Right: "1cm", Bottom: "1cm", Left: "1cm"}},
+		{"10mm 0.5in", agentbrowser.PDFMargin{Top: "10mm", Right: "0.5in", Bottom: "10mm", Left: "0.5in"}},
+		{"1cm 2cm 3cm", agentbrowser.PDFMargin{Top: "1cm", Right: "2cm", Bottom: "3cm", Left: "2cm"}},
+		{"1 2px 3in 4mm", agentbrowser.PDFMargin{Top: "1", Right: "2px", Bottom: "3in", Left: "4mm"}},
+	}
+	for _, tt := range tests {
+		got, err := agentbrowser.ParsePDFMargin(tt.in)
+		if err != nil {
+			t.Errorf("ParsePDFMargin(%q) error = %v", tt.in, err)
+			continue
+		}
+		if !reflect.DeepEqual(*got, tt.want) {
+			t.Errorf("ParsePDFMargin(%q) = %+v, want %+v", tt.in, *got, tt.want)
+		}
+	}
+
+	for _, in := range []string{"", "1cm 2cm 3cm 4cm 5cm", "1pt", "-1cm", "wide"} {
+		if _, err := agentbrowser.ParsePDFMargin(in); err == nil {
+			t.Errorf("ParsePDFMargin(%q) expected an error", in)
+		}
+	}
+}
+
+// TestPDFOptions tests that paper formats are matched and validated before
+// printing
+func TestPDFOptions(t *testing.T) {
+	var printed *agentbrowser.PDFOptions
+	m := agentbrowser.NewBrowserManagerForTest(&fakeBackend{
+		pdf: func(opts agentbrowser.PDFOptions) ([]byte, error) {
+			printed = &opts
+			return []byte("%PDF-1.4"), nil
+		},
+	})
+
+	for in, want := range map[string]string{"": "Letter", "a4": "A4", "LEGAL": "Legal"} {
+		if _, err := m.PDF(agentbrowser.PDFOptions{Format: in}); err != nil {
+			t.Fatalf("PDF(%q) error = %v", in, err)
+		}
+		if printed.Format != want {
+			t.Errorf("PDF(%q) format = %q, want %q", in, printed.Format, want)
+		}
+	}
+
+	printed = nil
+	for _, opts := range []agentbrowser.PDFOptions{
+		{Format: "B5"},
+		{Margin: &agentbrowser.PDFMargin{Top: "1furlong"}},
+	} {
+		if _, err := m.PDF(opts); err == nil {
+			t.Errorf("PDF(%+v) expected an error", opts)
+		}
+	}
+	if printed != nil {
+		t.Errorf("backend printed with %+v, want no print", printed)
+	}
+}
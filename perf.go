@@ -0,0 +1,148 @@
+package agentbrowser
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// PerfReport is a performance report of the current page. Times are in
+// milliseconds from the start of navigation, sizes in bytes.
+type PerfReport struct {
+	URL        string             `json:"url"`
+	Navigation *NavigationTiming  `json:"navigation,omitempty"`
+	Resources  ResourceSummary    `json:"resources"`
+	Vitals     WebVitals          `json:"vitals"`
+	Metrics    map[string]float64 `json:"metrics,omitempty"` // CDP Performance.getMetrics
+}
+
+// NavigationTiming is the Navigation Timing entry of the document.
+type NavigationTiming struct {
+	Type             string  `json:"type"` // navigate, reload, back_forward
+	DNS              float64 `json:"dns"`  // duration
+	Connect          float64 `json:"connect"`
+	TTFB             float64 `json:"ttfb"`
+	Response         float64 `json:"response"` // end of the response
+	DOMInteractive   float64 `json:"domInteractive"`
+	DOMContentLoaded float64 `json:"domContentLoaded"`
+	Load             float64 `json:"load"`
+	TransferSize     int64   `json:"transferSize"`
+	DecodedSize      int64   `json:"decodedSize"`
+}
+
+// ResourceSummary totals the page's subresource requests.
+type ResourceSummary struct {
+	Count        int                      `json:"count"`
+	TransferSize int64                    `json:"transferSize"` // 0 for cached and opaque cross-origin responses
+	DecodedSize  int64                    `json:"decodedSize"`
+	ByType       map[string]ResourceStats `json:"byType"` // by initiator type: script, img, css, fetch...
+}
+
+// ResourceStats totals the requests of one initiator type.
+type ResourceStats struct {
+	Count        int   `json:"count"`
+	TransferSize int64 `json:"transferSize"`
+}
+
+// WebVitals holds the Core Web Vitals measured so far. Absent values were
+// not observed: LCP needs a painted element and FID and INP an interaction.
+// INP is approximated by the slowest interaction.
+type WebVitals struct {
+	FCP *float64 `json:"fcp,omitempty"`
+	LCP *float64 `json:"lcp,omitempty"`
+	CLS float64  `json:"cls"`
+	FID *float64 `json:"fid,omitempty"`
+	INP *float64 `json:"inp,omitempty"`
+}
+
+// perfScript collects timing entries. Buffered PerformanceObservers replay
+// the entries recorded since navigation, so nothing has to be injected
+// before the page loads. CLS is the largest session window of layout
+// shifts (shifts under 1s apart, the window at most 5s long).
+const perfScript = `(async () => {
+	const round = n => Math.round(n * 100) / 100;
+	const observe = type => new Promise(resolve => {
+		if (!PerformanceObserver.supportedEntryTypes.includes(type)) return resolve([]);
+		const entries = [];
+		const po = new PerformanceObserver(list => entries.push(...list.getEntries()));
+		const opts = { type, buffered: true };
+		if (type === 'event') opts.durationThreshold = 16;
+		po.observe(opts);
+		setTimeout(() => { entries.push(...po.takeRecords()); po.disconnect(); resolve(entries); }, 100);
+	});
+	const [lcp, shifts, firstInput, events] = await Promise.all(
+		['largest-contentful-paint', 'layout-shift', 'first-input', 'event'].map(observe));
+
+	const report = { url: location.href, vitals: { cls: 0 } };
+	const nav = performance.getEntriesByType('navigation')[0];
+	if (nav) {
+		report.navigation = {
+			type: nav.type,
+			dns: round(nav.domainLookupEnd - nav.domainLookupStart),
+			connect: round(nav.connectEnd - nav.connectStart),
+			ttfb: round(nav.responseStart),
+			response: round(nav.responseEnd),
+			domInteractive: round(nav.domInteractive),
+			domContentLoaded: round(nav.domContentLoadedEventEnd),
+			load: round(nav.loadEventEnd),
+			transferSize: nav.transferSize || 0,
+			decodedSize: nav.decodedBodySize || 0,
+		};
+	}
+
+	const resources = { count: 0, transferSize: 0, decodedSize: 0, byType: {} };
+	for (const r of performance.getEntriesByType('resource')) {
+		resources.count++;
+		resources.transferSize += r.transferSize || 0;
+		resources.decodedSize += r.decodedBodySize || 0;
+		const t = resources.byType[r.initiatorType] || (resources.byType[r.initiatorType] = { count: 0, transferSize: 0 });
+		t.count++;
+		t.transferSize += r.transferSize || 0;
+	}
+	report.resources = resources;
+
+	const fcp = performance.getEntriesByName('first-contentful-paint')[0];
+	if (fcp) report.vitals.fcp = round(fcp.startTime);
+	if (lcp.length) report.vitals.lcp = round(lcp[lcp.length - 1].startTime);
+	let cls = 0, session = 0, first = 0, last = 0;
+	for (const s of shifts) {
+		if (s.hadRecentInput) continue;
+		if (session && s.startTime - last < 1000 && s.startTime - first < 5000) {
+			session += s.value;
+		} else {
+			session = s.value;
+			first = s.startTime;
+		}
+		last = s.startTime;
+		cls = Math.max(cls, session);
+	}
+	report.vitals.cls = Math.round(cls * 10000) / 10000;
+	if (firstInput.length) report.vitals.fid = round(firstInput[0].processingStart - firstInput[0].startTime);
+	const interactions = events.filter(e => e.interactionId);
+	if (interactions.length) report.vitals.inp = Math.max(...interactions.map(e => e.duration));
+	return report;
+})()`
+
+// Perf reports navigation timing, resource totals, Web Vitals and the
+// browser's run-time metrics for the current page.
+func (m *BrowserManager) Perf() (*PerfReport, error) {
+	result, err := m.backend.Evaluate(perfScript)
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(result)
+	if err != nil {
+		return nil, err
+	}
+	report := &PerfReport{}
+	if err := json.Unmarshal(data, report); err != nil {
+		return nil, fmt.Errorf("unexpected performance report: %w", err)
+	}
+	if report.Resources.ByType == nil {
+		report.Resources.ByType = map[string]ResourceStats{}
+	}
+
+	if report.Metrics, err = m.backend.PerformanceMetrics(); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
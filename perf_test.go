@@ -0,0 +1,52 @@
+package agentbrowser_test
+
+import (
+	"reflect"
+	"testing"
+
+	agentbrowser "github.com/cpunion/agent-browser-go"
+)
+
+// TestPerf tests decoding of the performance report
+func TestPerf(t *testing.T) {
+	metrics := map[string]float64{"JSHeapUsedSize": 1e6, "Nodes": 120}
+	backend := &fakeBackend{
+		evaluate: evalResult(map[string]interface{}{
+			"url": "https://a.com/",
+			"navigation": map[string]interface{}{
+				"type": "navigate", "ttfb": 42.5, "load": 300.0, "transferSize": 1200.0,
+			},
+			"resources": map[string]interface{}{
+				"count": 3.0, "transferSize": 5000.0, "decodedSize": 9000.0,
+				"byType": map[string]interface{}{
+					"script": map[string]interface{}{"count": 2.0, "transferSize": 4000.0},
+					"img":    map[string]interface{}{"count": 1.0, "transferSize": 1000.0},
+				},
+			},
+			"vitals": map[string]interface{}{"fcp": 100.0, "lcp": 250.0, "cls": 0.05},
+		}),
+		performanceMetrics: func() (map[string]float64, error) { return metrics, nil },
+	}
+
+	report, err := agentbrowser.NewBrowserManagerForTest(backend).Perf()
+	if err != nil {
+		t.Fatalf("Perf() error = %v", err)
+	}
+	if report.Navigation == nil || report.Navigation.TTFB != 42.5 || report.Navigation.TransferSize != 1200 {
+		t.Errorf("Navigation = %+v", report.Navigation)
+	}
+	wantTypes := map[string]agentbrowser.ResourceStats{
+		"script": {Count: 2, TransferSize: 4000},
+		"img":    {Count: 1, TransferSize: 1000},
+	}
+	if report.Resources.Count != 3 || !reflect.DeepEqual(report.Resources.ByType, wantTypes) {
+		t.Errorf("Resources = %+v", report.Resources)
+	}
+	v := report.Vitals
+	if v.FCP == nil || *v.FCP != 100 || v.LCP == nil || *v.LCP != 250 || v.CLS != 0.05 || v.FID != nil || v.INP != nil {
+		t.Errorf("Vitals = %+v, want FCP, LCP and CLS only", v)
+	}
+	if !reflect.DeepEqual(report.Metrics, metrics) {
+		t.Errorf("Metrics = %v, want %v", report.Metrics, metrics)
+	}
+}
@@ -0,0 +1,78 @@
+package agentbrowser
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// knownPermissions are the permission names both backends understand, as
+// in the Permissions API.
+var knownPermissions = map[string]bool{
+	"geolocation":     true,
+	"notifications":   true,
+	"camera":          true,
+	"microphone":      true,
+	"clipboard-read":  true,
+	"clipboard-write": true,
+	"midi":            true,
+	"midi-sysex":      true,
+	"background-sync": true,
+	"accelerometer":   true,
+	"gyroscope":       true,
+	"magnetometer":    true,
+	"payment-handler": true,
+	"storage-access":  true,
+}
+
+// permissionNames checks permission names and expands "clipboard" to
+// clipboard-read and clipboard-write.
+func permissionNames(permissions []string) ([]string, error) {
+	if len(permissions) == 0 {
+		return nil, fmt.Errorf("no permissions given")
+	}
+	var names []string
+	for _, p := range permissions {
+		p = strings.ToLower(p)
+		switch {
+		case p == "clipboard":
+			names = append(names, "clipboard-read", "clipboard-write")
+		case knownPermissions[p]:
+			names = append(names, p)
+		default:
+			known := make([]string, 0, len(knownPermissions))
+			for name := range knownPermissions {
+				known = append(known, name)
+			}
+			sort.Strings(known)
+			return nil, fmt.Errorf("unknown permission %q (expected clipboard, %s)", p, strings.Join(known, ", "))
+		}
+	}
+	return names, nil
+}
+
+// GrantPermissions grants permissions to origin, or to every origin when
+// origin is empty, so pages get them without a prompt.
+func (m *BrowserManager) GrantPermissions(permissions []string, origin string) error {
+	names, err := permissionNames(permissions)
+	if err != nil {
+		return err
+	}
+	return m.backend.GrantPermissions(names, origin)
+}
+
+// DenyPermissions takes permissions away from origin, or from every origin
+// when origin is empty.
+func (m *BrowserManager) DenyPermissions(permissions []string, origin string) error {
+	names, err := permissionNames(permissions)
+	if err != nil {
+		return err
+	}
+	return m.backend.DenyPermissions(names, origin)
+}
+
+// ResetPermissions drops every grant and denial, including the geolocation
+// grant made by SetGeolocation.
+func (m *BrowserManager) ResetPermissions() error {
+	return m.backend.ResetPermissions()
+}
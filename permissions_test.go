@@ -0,0 +1,49 @@
+package agentbrowser_test
+
+import (
+	"reflect"
+	"testing"
+
+	agentbrowser "github.com/cpunion/agent-browser-go"
+)
+
+// TestGrantPermissions tests permission name checking and expansion
+func TestGrantPermissions(t *testing.T) {
+	var granted []string
+	var origin string
+	m := agentbrowser.NewBrowserManagerForTest(&fakeBackend{
+		grantPermissions: func(permissions []string, o string) error {
+			granted, origin = permissions, o
+			return nil
+		},
+	})
+
+	tests := []struct {
+		in   []string
+		want []string
+	}{
+		{[]string{"geolocation"}, []string{"geolocation"}},
+		{[]string{"Camera", "microphone"}, []string{"camera", "microphone"}},
+		{[]string{"clipboard"}, []string{"clipboard-read", "clipboard-write"}},
+		{[]string{"midi-sysex", "clipboard-write"}, []string{"midi-sysex", "clipboard-write"}},
+	}
+	for _, tt := range tests {
+		if err := m.GrantPermissions(tt.in, "https://example.com"); err != nil {
+			t.Errorf("GrantPermissions(%v) error = %v", tt.in, err)
+			continue
+		}
+		if !reflect.DeepEqual(granted, tt.want) || origin != "https://example.com" {
+			t.Errorf("GrantPermissions(%v) granted %v to %q, want %v", tt.in, granted, origin, tt.want)
+		}
+	}
+
+	granted = nil
+	for _, in := range [][]string{nil, {"teleport"}, {"camera", "webcam"}} {
+		if err := m.GrantPermissions(in, ""); err == nil {
+			t.Errorf("GrantPermissions(%v) expected an error", in)
+		}
+	}
+	if granted != nil {
+		t.Errorf("backend granted %v, want nothing", granted)
+	}
+}
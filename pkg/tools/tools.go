@@ -0,0 +1,248 @@
+// Package tools exposes the browser as tools for LLM agent frameworks.
+//
+// Each tool wraps one protocol command (see agentbrowser.ToolSpecs) and is
+// backed by a daemon Client. The Tool interface has the same method set as
+// langchaingo's tools.Tool, so the values returned by New can be handed to a
+// langchaingo agent directly, and other frameworks can wrap them easily.
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"unicode/utf8"
+
+	agentbrowser "github.com/cpunion/agent-browser-go"
+)
+
+// DefaultMaxResultChars is the default limit on the size of a tool result.
+const DefaultMaxResultChars = 8000
+
+// Tool is a generic LLM tool.
+type Tool interface {
+	Name() string
+	Description() string
+	Call(ctx context.Context, input string) (string, error)
+}
+
+// Sender sends a raw JSON command to the daemon and returns the raw
+// response, giving up with ctx's error when ctx is done first.
+// *agentbrowser.Client satisfies this interface.
+type Sender interface {
+	SendRawContext(ctx context.Context, data []byte) ([]byte, error)
+}
+
+// Option configures the tools returned by New.
+type Option func(*config)
+
+type config struct {
+	maxResultChars int
+	include        map[string]bool
+}
+
+// WithMaxResultChars limits the number of characters returned by a tool call.
+// Longer results are truncated with a marker. Zero disables truncation.
+func WithMaxResultChars(n int) Option {
+	return func(c *config) { c.maxResultChars = n }
+}
+
+// WithTools restricts the returned tools to the given action names.
+func WithTools(names ...string) Option {
+	return func(c *config) {
+		c.include = make(map[string]bool, len(names))
+		for _, n := range names {
+			c.include[n] = true
+		}
+	}
+}
+
+// New returns a tool for each command in agentbrowser.ToolSpecs, all sharing client.
+func New(client Sender, opts ...Option) []Tool {
+	cfg := config{maxResultChars: DefaultMaxResultChars}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	shared := &conn{sender: client}
+	var result []Tool
+	for _, spec := range agentbrowser.ToolSpecs() {
+		if cfg.include != nil && !cfg.include[spec.Name] {
+			continue
+		}
+		result = append(result, &BrowserTool{spec: spec, conn: shared, maxResultChars: cfg.maxResultChars})
+	}
+	return result
+}
+
+// conn serializes requests over a single daemon connection.
+type conn struct {
+	sender Sender
+	mu     sync.Mutex
+	seq    atomic.Int64
+}
+
+// BrowserTool is a Tool that sends one protocol command to the daemon.
+type BrowserTool struct {
+	spec           agentbrowser.ToolSpec
+	conn           *conn
+	maxResultChars int
+}
+
+// Name returns the protocol action name.
+func (t *BrowserTool) Name() string {
+	return t.spec.Name
+}
+
+// Description returns the command description followed by its input format.
+func (t *BrowserTool) Description() string {
+	props, _ := t.spec.Parameters["properties"].(map[string]interface{})
+	if len(props) == 0 {
+		return t.spec.Description + " Input: none."
+	}
+
+	required := make(map[string]bool)
+	if req, ok := t.spec.Parameters["required"].([]string); ok {
+		for _, r := range req {
+			required[r] = true
+		}
+	}
+
+	names := make([]string, 0, len(props))
+	for name := range props {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fields := make([]string, len(names))
+	for i, name := range names {
+		prop, _ := props[name].(map[string]interface{})
+		field := fmt.Sprintf("%s (%v", name, prop["type"])
+		if required[name] {
+			field += ", required"
+		}
+		field += ")"
+		fields[i] = field
+	}
+	return fmt.Sprintf("%s Input: JSON object with fields %s.", t.spec.Description, strings.Join(fields, ", "))
+}
+
+// Spec returns the underlying tool spec.
+func (t *BrowserTool) Spec() agentbrowser.ToolSpec {
+	return t.spec
+}
+
+// Call executes the command. Command failures are returned as an
+// "error: ..." result rather than a Go error, so the agent can observe
+// and recover from them; Go errors indicate transport problems, or that
+// ctx was done before the daemon answered.
+func (t *BrowserTool) Call(ctx context.Context, input string) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	params, err := t.parseInput(input)
+	if err != nil {
+		return "error: " + err.Error(), nil
+	}
+	params["id"] = fmt.Sprintf("tool-%d", t.conn.seq.Add(1))
+	params["action"] = t.spec.Name
+
+	data, err := json.Marshal(params)
+	if err != nil {
+		return "", err
+	}
+
+	t.conn.mu.Lock()
+	raw, err := t.conn.sender.SendRawContext(ctx, data)
+	t.conn.mu.Unlock()
+	if err != nil {
+		return "", err
+	}
+
+	var resp agentbrowser.Response
+	if err := json.Unmarshal(raw, &resp); err != nil {
+		return "", fmt.Errorf("failed to parse response: %w", err)
+	}
+	if !resp.Success {
+		return Truncate(formatError(resp), t.maxResultChars), nil
+	}
+	return Truncate(formatResult(resp.Data), t.maxResultChars), nil
+}
+
+// parseInput accepts a JSON object, or a bare string for commands with a
+// single required parameter (e.g. a URL for navigate).
+func (t *BrowserTool) parseInput(input string) (map[string]interface{}, error) {
+	params := make(map[string]interface{})
+	input = strings.TrimSpace(input)
+	if input == "" {
+		return params, nil
+	}
+
+	if strings.HasPrefix(input, "{") {
+		if err := json.Unmarshal([]byte(input), &params); err != nil {
+			return nil, fmt.Errorf("invalid JSON input: %v", err)
+		}
+		return params, nil
+	}
+
+	required, _ := t.spec.Parameters["required"].([]string)
+	if len(required) != 1 {
+		return nil, fmt.Errorf("%s expects a JSON object input", t.spec.Name)
+	}
+	params[required[0]] = input
+	return params, nil
+}
+
+// formatResult renders response data for an LLM: text payloads are returned
+// as-is, everything else as compact JSON.
+func formatResult(data json.RawMessage) string {
+	if len(data) == 0 || string(data) == "null" {
+		return "OK"
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err == nil && len(fields) <= 2 {
+		for _, key := range []string{"snapshot", "text", "html"} {
+			if s, ok := fields[key].(string); ok {
+				return s
+			}
+		}
+	}
+	return string(data)
+}
+
+// formatError renders a failed response, appending any error context (such
+// as the snapshot excerpt attached to element-not-found errors) as JSON.
+func formatError(resp agentbrowser.Response) string {
+	msg := "error: " + resp.Error
+	if len(resp.Data) > 0 && string(resp.Data) != "null" {
+		msg += "\n" + string(resp.Data)
+	}
+	return msg
+}
+
+// Truncate shortens s to at most max characters (runes), the marker noting
+// the cut included, unless max is shorter than the marker itself. A
+// non-positive max disables truncation.
+func Truncate(s string, max int) string {
+	total := utf8.RuneCountInString(s)
+	if max <= 0 || total <= max {
+		return s
+	}
+	// The marker's length depends on the count it reports
+	kept := max
+	var marker string
+	for {
+		marker = fmt.Sprintf("\n... [truncated %d chars]", total-kept)
+		if n := utf8.RuneCountInString(marker); kept+n <= max || kept == 0 {
+			break
+		}
+		kept--
+	}
+	runes := []rune(s)
+	return string(runes[:kept]) + marker
+}
@@ -0,0 +1,96 @@
+package tools_test
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/cpunion/agent-browser-go/pkg/tools"
+)
+
+// fakeSender records commands and replies with a canned response
+type fakeSender struct {
+	sent []map[string]interface{}
+	resp string
+}
+
+func (f *fakeSender) SendRawContext(ctx context.Context, data []byte) ([]byte, error) {
+	var cmd map[string]interface{}
+	if err := json.Unmarshal(data, &cmd); err != nil {
+		return nil, err
+	}
+	f.sent = append(f.sent, cmd)
+	return []byte(f.resp), nil
+}
+
+func findTool(t *testing.T, list []tools.Tool, name string) tools.Tool {
+	t.Helper()
+	for _, tool := range list {
+		if tool.Name() == name {
+			return tool
+		}
+	}
+	t.Fatalf("tool %s not found", name)
+	return nil
+}
+
+// TestTool_Call tests command construction and result formatting
+func TestTool_Call(t *testing.T) {
+	sender := &fakeSender{resp: `{"id":"1","success":true,"data":{"snapshot":"- button \"OK\" [ref=e1]"}}`}
+	snapshot := findTool(t, tools.New(sender), "snapshot")
+
+	out, err := snapshot.Call(context.Background(), `{"interactive":true}`)
+	if err != nil {
+		t.Fatalf("Call() error = %v", err)
+	}
+	if out != `- button "OK" [ref=e1]` {
+		t.Errorf("unexpected result: %s", out)
+	}
+	if sender.sent[0]["action"] != "snapshot" || sender.sent[0]["interactive"] != true {
+		t.Errorf("unexpected command: %v", sender.sent[0])
+	}
+}
+
+// TestTool_BareInput tests that a bare string maps to the single required parameter
+func TestTool_BareInput(t *testing.T) {
+	sender := &fakeSender{resp: `{"id":"1","success":false,"error":"boom"}`}
+	navigate := findTool(t, tools.New(sender), "navigate")
+
+	out, err := navigate.Call(context.Background(), "https://example.com")
+	if err != nil {
+		t.Fatalf("Call() error = %v", err)
+	}
+	if out != "error: boom" {
+		t.Errorf("unexpected result: %s", out)
+	}
+	if sender.sent[0]["url"] != "https://example.com" {
+		t.Errorf("expected url parameter, got %v", sender.sent[0])
+	}
+}
+
+// TestTruncate tests result truncation
+func TestTruncate(t *testing.T) {
+	long := strings.Repeat("a", 100)
+	out := tools.Truncate(long, 40)
+	if !strings.HasPrefix(out, strings.Repeat("a", 15)) || !strings.HasSuffix(out, "truncated 85 chars]") {
+		t.Errorf("unexpected truncation: %q", out)
+	}
+	if n := utf8.RuneCountInString(out); n > 40 {
+		t.Errorf("truncated to %d characters, want at most 40: %q", n, out)
+	}
+	wide := strings.Repeat("é", 100)
+	if out := tools.Truncate(wide, 40); utf8.RuneCountInString(out) > 40 || !utf8.ValidString(out) || !strings.HasPrefix(out, strings.Repeat("é", 15)) {
+		t.Errorf("unexpected truncation of multi-byte text: %q", out)
+	}
+	if tools.Truncate(wide, 100) != wide {
+		t.Error("strings of max characters should not be truncated")
+	}
+	if tools.Truncate("short", 10) != "short" {
+		t.Error("short strings should not be truncated")
+	}
+	if tools.Truncate(long, 0) != long {
+		t.Error("zero limit should disable truncation")
+	}
+}
@@ -1,9 +1,15 @@
 package agentbrowser
 
 import (
+	"cmp"
+	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
+	"io"
+	"log/slog"
 	"os"
+	"strconv"
+	"strings"
 	"sync"
 	"sync/atomic"
 
@@ -18,10 +24,69 @@ type PlaywrightBackend struct {
 	context   playwright.BrowserContext
 	launched  atomic.Bool
 	headless  bool
+	tls       TLSOptions
+	hostRules []string
 	viewport  *Viewport
 	refMap    RefMap
 	refLock   sync.RWMutex
 	activeTab int
+
+	// Frame selected with SwitchFrame per page
+	frames map[playwright.Page]playwright.Frame
+
+	// Context options, so changing them relaunches
+	httpCredentials *HTTPCredentials
+	blockSW         bool
+	launchOpts      LaunchOptions // what SetHTTPCredentials relaunches with
+
+	browserLog *os.File // driver and browser stderr, when LaunchOptions.LogDir is set
+
+	// Service worker bypass set with SetServiceWorkerBypass. Like the user
+	// agent, it lives in a CDP session per page.
+	bypassSW   bool
+	swSessions map[playwright.Page]playwright.CDPSession
+
+	// Identity settings applied at launch
+	stealth     bool
+	fingerprint *Fingerprint
+	launchUA    string // the context's user agent, what an empty SetUserAgent restores
+
+	// Extra headers and init scripts, kept across relaunches
+	extraHeaders map[string]string
+	initScripts  []string
+
+	// User agent and locale set with SetUserAgent and SetLocale, kept
+	// across relaunches. The overrides live in a CDP session per page and
+	// end when the session detaches.
+	userAgent  string
+	locale     string
+	uaSessions map[playwright.Page]playwright.CDPSession
+
+	// Geolocation set with SetGeolocation, kept across relaunches
+	geolocation *Geolocation
+
+	offline bool           // set with SetOffline, kept across relaunches
+	media   MediaEmulation // set with EmulateMedia, kept across relaunches
+
+	// Permissions granted per origin ("" for every origin). Playwright can
+	// only clear all grants, so denying clears them and grants the rest again.
+	grants map[string]map[string]bool
+
+	// DOM change notifications
+	watchLock    sync.Mutex
+	watchOpts    *WatchOptions
+	watchPage    playwright.Page
+	watchExposed bool
+	watchHooked  map[playwright.Page]bool
+
+	// CDP session of the running screencast
+	screencastLock    sync.Mutex
+	screencastSession playwright.CDPSession
+
+	activityTracker
+	dialogPolicy
+	requestTracker
+	eventEmitter
 }
 
 // NewPlaywrightBackend creates a new Playwright backend.
@@ -37,7 +102,10 @@ func NewPlaywrightBackend() *PlaywrightBackend {
 func (p *PlaywrightBackend) Launch(opts LaunchOptions) error {
 	if p.launched.Load() {
 		// Check if headless setting changed
-		if p.headless != opts.Headless {
+		if p.headless != opts.Headless || p.stealth != opts.Stealth ||
+			!sameFingerprint(p.fingerprint, opts.Fingerprint) || !sameTLS(p.tls, opts.TLS) ||
+			!sameHostRules(p.hostRules, opts.HostRules) || !sameHTTPCredentials(p.httpCredentials, opts.HTTPCredentials) ||
+			p.blockSW != opts.BlockServiceWorkers || p.launchUA != opts.UserAgent || !sameProcessOptions(p.launchOpts, opts) {
 			// Need to relaunch with new settings
 			p.Close()
 		} else {
@@ -45,13 +113,45 @@ func (p *PlaywrightBackend) Launch(opts LaunchOptions) error {
 		}
 	}
 
+	// Chromium loads extensions only into its default context, which
+	// Playwright uses for persistent profiles
+	if len(opts.Extensions) > 0 && opts.UserDataDir == "" {
+		return fmt.Errorf("extensions require a profile with the playwright backend (--user-data-dir)")
+	}
+
 	var err error
-	p.pw, err = playwright.Run()
+	var runOpts []*playwright.RunOptions
+	var crashDir string
+	p.closeBrowserLog() // left open by a failed launch
+	if opts.LogDir != "" {
+		p.browserLog, crashDir, err = openBrowserLog(opts.LogDir)
+		if err != nil {
+			return fmt.Errorf("failed to open browser log: %w", err)
+		}
+		// The driver forwards the browser's output to its stderr when asked
+		if os.Getenv("DEBUG") == "" {
+			os.Setenv("DEBUG", "pw:browser")
+		}
+		runOpts = append(runOpts, &playwright.RunOptions{
+			Stderr: p.browserLog,
+			Logger: slog.New(slog.NewTextHandler(p.browserLog, nil)),
+		})
+	}
+	p.pw, err = playwright.Run(runOpts...)
 	if err != nil {
+		p.closeBrowserLog()
 		return fmt.Errorf("failed to start playwright: %w", err)
 	}
 
+	p.launchOpts = opts
 	p.headless = opts.Headless
+	p.tls = opts.TLS
+	p.hostRules = opts.HostRules
+	p.httpCredentials = opts.HTTPCredentials
+	p.blockSW = opts.BlockServiceWorkers
+	p.stealth = opts.Stealth
+	p.fingerprint = opts.Fingerprint
+	p.launchUA = opts.UserAgent
 	if opts.Viewport != nil {
 		p.viewport = opts.Viewport
 	} else {
@@ -65,6 +165,25 @@ func (p *PlaywrightBackend) Launch(opts LaunchOptions) error {
 		"--disable-blink-features=AutomationControlled",
 		"--disable-infobars",
 	}
+	if opts.Stealth {
+		args = append(args, stealthArgs...)
+	}
+	if len(opts.HostRules) > 0 {
+		args = append(args, "--host-resolver-rules="+hostResolverRulesArg(opts.HostRules))
+	}
+	if crashDir != "" {
+		args = append(args, "--crash-dumps-dir="+crashDir)
+	}
+	if opts.CDPPort != 0 {
+		args = append(args, fmt.Sprintf("--remote-debugging-port=%d", opts.CDPPort))
+	}
+	ignoreArgs := []string{"--enable-automation"}
+	if len(opts.Extensions) > 0 {
+		for name, value := range extensionArgs(opts.Extensions) {
+			args = append(args, "--"+name+"="+value)
+		}
+		ignoreArgs = append(ignoreArgs, "--disable-extensions")
+	}
 
 	// Use persistent context if UserDataDir is specified
 	if opts.UserDataDir != "" {
@@ -72,7 +191,7 @@ func (p *PlaywrightBackend) Launch(opts LaunchOptions) error {
 		contextOpts := playwright.BrowserTypeLaunchPersistentContextOptions{
 			Headless:          &opts.Headless,
 			Args:              args,
-			IgnoreDefaultArgs: []string{"--enable-automation"},
+			IgnoreDefaultArgs: ignoreArgs,
 		}
 
 		// Use system Chrome if requested (better compatibility for YouTube Studio, etc.)
@@ -88,6 +207,26 @@ func (p *PlaywrightBackend) Launch(opts LaunchOptions) error {
 		if opts.Locale != "" {
 			contextOpts.Locale = &opts.Locale
 		}
+		if opts.TLS.IgnoreHTTPSErrors {
+			contextOpts.IgnoreHttpsErrors = playwright.Bool(true)
+		}
+		if len(opts.TLS.ClientCertificates) > 0 {
+			contextOpts.ClientCertificates = playwrightClientCertificates(opts.TLS.ClientCertificates)
+		}
+		contextOpts.HttpCredentials = playwrightHTTPCredentials(opts.HTTPCredentials)
+		contextOpts.Proxy = playwrightProxy(opts.Proxy)
+		if opts.BlockServiceWorkers {
+			contextOpts.ServiceWorkers = playwright.ServiceWorkerPolicyBlock
+		}
+		if fp := opts.Fingerprint; fp != nil {
+			contextOpts.UserAgent = &fp.UserAgent
+			if fp.Timezone != "" {
+				contextOpts.TimezoneId = &fp.Timezone
+			}
+		}
+		if opts.UserAgent != "" {
+			contextOpts.UserAgent = &opts.UserAgent
+		}
 		if p.viewport != nil {
 			contextOpts.Viewport = &playwright.Size{
 				Width:  p.viewport.Width,
@@ -112,11 +251,12 @@ func (p *PlaywrightBackend) Launch(opts LaunchOptions) error {
 		launchOpts := playwright.BrowserTypeLaunchOptions{
 			Headless:          &opts.Headless,
 			Args:              args,
-			IgnoreDefaultArgs: []string{"--enable-automation"},
+			IgnoreDefaultArgs: ignoreArgs,
 		}
 		if opts.ExecutablePath != "" {
 			launchOpts.ExecutablePath = &opts.ExecutablePath
 		}
+		launchOpts.Proxy = playwrightProxy(opts.Proxy)
 
 		p.browser, err = p.pw.Chromium.Launch(launchOpts)
 		if err != nil {
@@ -126,9 +266,33 @@ func (p *PlaywrightBackend) Launch(opts LaunchOptions) error {
 
 		// Create context
 		contextOpts := playwright.BrowserNewContextOptions{}
+		if fp := opts.Fingerprint; fp != nil {
+			contextOpts.UserAgent = &fp.UserAgent
+			if fp.Timezone != "" {
+				contextOpts.TimezoneId = &fp.Timezone
+			}
+		} else if opts.Stealth {
+			if ua := p.browserUserAgent(); ua != "" {
+				ua = stealthUserAgent(ua)
+				contextOpts.UserAgent = &ua
+			}
+		}
+		if opts.UserAgent != "" {
+			contextOpts.UserAgent = &opts.UserAgent
+		}
 		if opts.Locale != "" {
 			contextOpts.Locale = &opts.Locale
 		}
+		if opts.TLS.IgnoreHTTPSErrors {
+			contextOpts.IgnoreHttpsErrors = playwright.Bool(true)
+		}
+		if len(opts.TLS.ClientCertificates) > 0 {
+			contextOpts.ClientCertificates = playwrightClientCertificates(opts.TLS.ClientCertificates)
+		}
+		contextOpts.HttpCredentials = playwrightHTTPCredentials(opts.HTTPCredentials)
+		if opts.BlockServiceWorkers {
+			contextOpts.ServiceWorkers = playwright.ServiceWorkerPolicyBlock
+		}
 		if p.viewport != nil {
 			contextOpts.Viewport = &playwright.Size{
 				Width:  p.viewport.Width,
@@ -156,10 +320,552 @@ func (p *PlaywrightBackend) Launch(opts LaunchOptions) error {
 		p.activeTab = 0
 	}
 
+	var initScripts []string
+	if opts.Stealth {
+		initScripts = append(initScripts, stealthScript(opts.Locale))
+	}
+	if opts.Fingerprint != nil {
+		initScripts = append(initScripts, fingerprintScript(*opts.Fingerprint))
+	}
+	initScripts = append(initScripts, p.initScripts...)
+	for _, script := range initScripts {
+		if err := p.context.AddInitScript(playwright.Script{Content: &script}); err != nil {
+			_ = p.context.Close()
+			if p.browser != nil {
+				_ = p.browser.Close()
+			}
+			_ = p.pw.Stop()
+			return fmt.Errorf("failed to add init script: %w", err)
+		}
+	}
+
+	if len(p.extraHeaders) > 0 {
+		if err := p.context.SetExtraHTTPHeaders(p.extraHeaders); err != nil {
+			Logger().Warn("failed to set extra headers", "err", err)
+		}
+	}
+	p.grants = nil
+	if p.geolocation != nil {
+		if err := p.applyGeolocation(); err != nil {
+			Logger().Warn("failed to set geolocation", "err", err)
+		}
+	}
+	if p.offline {
+		if err := p.context.SetOffline(true); err != nil {
+			Logger().Warn("failed to set offline mode", "err", err)
+		}
+	}
+	p.trackContext()
+	p.uaSessions = nil
+	p.swSessions = nil
+	p.frames = nil
+	for _, page := range p.pages {
+		if err := p.applyUserAgent(page); err != nil {
+			Logger().Warn("failed to set user agent and locale", "err", err)
+		}
+		if err := p.applyServiceWorkerBypass(page); err != nil {
+			Logger().Warn("failed to set service worker bypass", "err", err)
+		}
+		if err := p.applyMedia(page); err != nil {
+			Logger().Warn("failed to emulate media", "err", err)
+		}
+	}
 	p.launched.Store(true)
 	return nil
 }
 
+// SetExtraHeaders sets headers sent with every request of the context. An
+// empty map removes them.
+func (p *PlaywrightBackend) SetExtraHeaders(headers map[string]string) error {
+	p.extraHeaders = headers
+	if !p.launched.Load() {
+		return nil // applied at launch
+	}
+	if headers == nil {
+		headers = map[string]string{}
+	}
+	return p.context.SetExtraHTTPHeaders(headers)
+}
+
+// SetHTTPCredentials sets the credentials that answer HTTP authentication
+// challenges. Playwright takes them only when it creates the context, so
+// changing them on a running browser relaunches it, closing open tabs.
+func (p *PlaywrightBackend) SetHTTPCredentials(creds *HTTPCredentials) error {
+	if !p.launched.Load() {
+		p.httpCredentials = creds
+		return nil // applied at launch
+	}
+	if sameHTTPCredentials(p.httpCredentials, creds) {
+		return nil
+	}
+	opts := p.launchOpts
+	opts.HTTPCredentials = creds
+	return p.Launch(opts)
+}
+
+// AddInitScript runs script before page scripts in every new document of
+// the context.
+func (p *PlaywrightBackend) AddInitScript(script string) error {
+	p.initScripts = append(p.initScripts, script)
+	if !p.launched.Load() {
+		return nil // applied at launch
+	}
+	return p.context.AddInitScript(playwright.Script{Content: &script})
+}
+
+// AddScriptTag adds a <script> to the current frame.
+func (p *PlaywrightBackend) AddScriptTag(url, content string) error {
+	frame := p.getCurrentFrame()
+	if frame == nil {
+		return ErrBrowserNotLaunched
+	}
+	var opts playwright.FrameAddScriptTagOptions
+	if url != "" {
+		opts.URL = &url
+	} else {
+		opts.Content = &content
+	}
+	_, err := frame.AddScriptTag(opts)
+	return err
+}
+
+// AddStyleTag adds a stylesheet to the current frame.
+func (p *PlaywrightBackend) AddStyleTag(url, content string) error {
+	frame := p.getCurrentFrame()
+	if frame == nil {
+		return ErrBrowserNotLaunched
+	}
+	var opts playwright.FrameAddStyleTagOptions
+	if url != "" {
+		opts.URL = &url
+	} else {
+		opts.Content = &content
+	}
+	_, err := frame.AddStyleTag(opts)
+	return err
+}
+
+// SetGeolocation overrides the position in every tab of the context and
+// grants the geolocation permission. nil removes the override.
+func (p *PlaywrightBackend) SetGeolocation(geo *Geolocation) error {
+	p.geolocation = geo
+	if !p.launched.Load() {
+		return nil // applied at launch
+	}
+	return p.applyGeolocation()
+}
+
+// applyGeolocation sets or clears the context's position.
+func (p *PlaywrightBackend) applyGeolocation() error {
+	geo := p.geolocation
+	if geo == nil {
+		return p.context.SetGeolocation(nil)
+	}
+	if err := p.grantPermissions([]string{"geolocation"}, ""); err != nil {
+		return err
+	}
+	return p.context.SetGeolocation(&playwright.Geolocation{
+		Latitude:  geo.Latitude,
+		Longitude: geo.Longitude,
+		Accuracy:  playwright.Float(geo.Accuracy),
+	})
+}
+
+// GrantPermissions grants permissions to origin, or to every origin.
+func (p *PlaywrightBackend) GrantPermissions(permissions []string, origin string) error {
+	if !p.launched.Load() {
+		return ErrBrowserNotLaunched
+	}
+	return p.grantPermissions(permissions, origin)
+}
+
+// grantPermissions grants permissions in the context and records them.
+func (p *PlaywrightBackend) grantPermissions(permissions []string, origin string) error {
+	var opts playwright.BrowserContextGrantPermissionsOptions
+	if origin != "" {
+		opts.Origin = &origin
+	}
+	if err := p.context.GrantPermissions(permissions, opts); err != nil {
+		return err
+	}
+	if p.grants == nil {
+		p.grants = make(map[string]map[string]bool)
+	}
+	if p.grants[origin] == nil {
+		p.grants[origin] = make(map[string]bool)
+	}
+	for _, name := range permissions {
+		p.grants[origin][name] = true
+	}
+	return nil
+}
+
+// DenyPermissions takes permissions granted to origin, or to every origin,
+// away again. Playwright has no denied state: the permissions go back to
+// the browser default, which headless browsers answer with a denial, and a
+// grant to every origin still covers an origin denied on its own.
+func (p *PlaywrightBackend) DenyPermissions(permissions []string, origin string) error {
+	if !p.launched.Load() {
+		return ErrBrowserNotLaunched
+	}
+	for o, names := range p.grants {
+		if origin != "" && o != origin {
+			continue
+		}
+		for _, name := range permissions {
+			delete(names, name)
+		}
+	}
+	grants := p.grants
+	if err := p.ResetPermissions(); err != nil {
+		return err
+	}
+	for o, names := range grants {
+		var keep []string
+		for name := range names {
+			keep = append(keep, name)
+		}
+		if len(keep) == 0 {
+			continue
+		}
+		if err := p.grantPermissions(keep, o); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ResetPermissions drops every permission grant.
+func (p *PlaywrightBackend) ResetPermissions() error {
+	if !p.launched.Load() {
+		return ErrBrowserNotLaunched
+	}
+	p.grants = nil
+	return p.context.ClearPermissions()
+}
+
+// SetUserAgent overrides the user agent and client hints in every tab,
+// including tabs opened later. An empty ua restores the launch user agent.
+func (p *PlaywrightBackend) SetUserAgent(ua string) error {
+	p.userAgent = ua
+	for _, page := range p.pages {
+		if err := p.applyUserAgent(page); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SetLocale overrides the locale in every tab, including tabs opened
+// later. An empty locale restores the context's.
+func (p *PlaywrightBackend) SetLocale(locale string) error {
+	p.locale = locale
+	for _, page := range p.pages {
+		if err := p.applyUserAgent(page); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyUserAgent sets the page's user agent and locale through a CDP
+// session, or detaches the session to fall back to the context's.
+func (p *PlaywrightBackend) applyUserAgent(page playwright.Page) error {
+	session := p.uaSessions[page]
+	if p.userAgent == "" && p.locale == "" {
+		if session == nil {
+			return nil
+		}
+		delete(p.uaSessions, page)
+		return session.Detach()
+	}
+
+	if session == nil {
+		var err error
+		if session, err = p.context.NewCDPSession(page); err != nil {
+			return err
+		}
+		if p.uaSessions == nil {
+			p.uaSessions = make(map[playwright.Page]playwright.CDPSession)
+		}
+		p.uaSessions[page] = session
+	}
+
+	o := userAgentOverrideFor(p.userAgent)
+	o.AcceptLanguage = p.locale
+	params, err := cdpParams(o)
+	if err != nil {
+		return err
+	}
+	if _, err := session.Send("Emulation.setUserAgentOverride", params); err != nil {
+		return err
+	}
+	// Chrome refuses to replace a locale override, so the old one is
+	// cleared first
+	if _, err := session.Send("Emulation.setLocaleOverride", nil); err != nil {
+		return err
+	}
+	if p.locale == "" {
+		return nil
+	}
+	_, err = session.Send("Emulation.setLocaleOverride", map[string]interface{}{"locale": p.locale})
+	return err
+}
+
+// EmulateMedia sets the media type and features in every tab, including
+// tabs opened later.
+func (p *PlaywrightBackend) EmulateMedia(media MediaEmulation) error {
+	p.media = media
+	for _, page := range p.pages {
+		if err := p.applyMedia(page); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyMedia sets the page's emulated media. Empty values remove
+// overrides.
+func (p *PlaywrightBackend) applyMedia(page playwright.Page) error {
+	media := playwright.Media(cmp.Or(p.media.Media, mediaNoOverride))
+	colorScheme := playwright.ColorScheme(cmp.Or(p.media.ColorScheme, mediaNoOverride))
+	reducedMotion := playwright.ReducedMotion(cmp.Or(p.media.ReducedMotion, mediaNoOverride))
+	forcedColors := playwright.ForcedColors(cmp.Or(p.media.ForcedColors, mediaNoOverride))
+	return page.EmulateMedia(playwright.PageEmulateMediaOptions{
+		Media:         &media,
+		ColorScheme:   &colorScheme,
+		ReducedMotion: &reducedMotion,
+		ForcedColors:  &forcedColors,
+	})
+}
+
+// SetDialogResponse sets how dialogs opened from now on are answered.
+func (p *PlaywrightBackend) SetDialogResponse(response, promptText string) error {
+	p.setDialogResponse(response, promptText)
+	return nil
+}
+
+// SetOffline cuts the context, service workers included, off the network,
+// or reconnects it.
+func (p *PlaywrightBackend) SetOffline(offline bool) error {
+	p.offline = offline
+	if !p.launched.Load() {
+		return nil // applied at launch
+	}
+	return p.context.SetOffline(offline)
+}
+
+// SetServiceWorkerBypass makes requests skip service workers in every tab,
+// including tabs opened later, or restores normal handling.
+func (p *PlaywrightBackend) SetServiceWorkerBypass(bypass bool) error {
+	p.bypassSW = bypass
+	for _, page := range p.pages {
+		if err := p.applyServiceWorkerBypass(page); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyServiceWorkerBypass sets the page's bypass through a CDP session,
+// or detaches the session to restore normal handling.
+func (p *PlaywrightBackend) applyServiceWorkerBypass(page playwright.Page) error {
+	session := p.swSessions[page]
+	if !p.bypassSW {
+		if session == nil {
+			return nil
+		}
+		delete(p.swSessions, page)
+		return session.Detach()
+	}
+
+	if session == nil {
+		var err error
+		if session, err = p.context.NewCDPSession(page); err != nil {
+			return err
+		}
+		if p.swSessions == nil {
+			p.swSessions = make(map[playwright.Page]playwright.CDPSession)
+		}
+		p.swSessions[page] = session
+		// The bypass only takes effect while the Network domain is enabled
+		if _, err := session.Send("Network.enable", nil); err != nil {
+			return err
+		}
+	}
+	_, err := session.Send("Network.setBypassServiceWorker", map[string]interface{}{"bypass": true})
+	return err
+}
+
+// browserUserAgent returns the browser's default user agent, read from a
+// throwaway page since Playwright does not expose it directly.
+func (p *PlaywrightBackend) browserUserAgent() string {
+	page, err := p.browser.NewPage()
+	if err != nil {
+		return ""
+	}
+	defer page.Close()
+
+	ua, err := page.Evaluate("navigator.userAgent")
+	if err != nil {
+		return ""
+	}
+	s, _ := ua.(string)
+	return s
+}
+
+// trackContext records console messages, page errors, dialogs, network
+// requests and main-frame navigations for every page in the context.
+// Dialogs are answered as SetDialogResponse chose, or dismissed, which is
+// what Playwright does when no listener is registered.
+func (p *PlaywrightBackend) trackContext() {
+	p.resetActivity()
+	p.ClearRequests()
+
+	p.context.OnConsole(func(msg playwright.ConsoleMessage) {
+		// Named like the console method, as chromedp reports it
+		msgType := msg.Type()
+		if msgType == "warning" {
+			msgType = "warn"
+		}
+		logged := p.recordConsole(ConsoleMessage{Type: msgType, Text: msg.Text()})
+		p.emit(EventConsole, logged)
+	})
+	p.context.OnWebError(func(webErr playwright.WebError) {
+		pageErr := PageError{Message: webErr.Error().Error()}
+		var jsErr *playwright.Error
+		if errors.As(webErr.Error(), &jsErr) {
+			pageErr.Message, pageErr.Stack = jsErr.Message, jsErr.Stack
+			if jsErr.Name != "" {
+				pageErr.Message = jsErr.Name + ": " + jsErr.Message
+			}
+		}
+		p.recordPageError(pageErr)
+	})
+	p.context.OnDialog(func(dialog playwright.Dialog) {
+		p.recordDialog(dialog.Message())
+		if response, promptText := p.dialogAnswer(); response == "accept" {
+			_ = dialog.Accept(promptText)
+		} else {
+			_ = dialog.Dismiss()
+		}
+	})
+	p.context.OnRequest(func(req playwright.Request) {
+		p.requestStarted(req, TrackedRequest{
+			URL:          req.URL(),
+			Method:       req.Method(),
+			Headers:      req.Headers(),
+			ResourceType: req.ResourceType(),
+		})
+		if !req.IsNavigationRequest() {
+			return
+		}
+		if frame := req.Frame(); frame != nil && frame.ParentFrame() == nil {
+			p.recordNavigation()
+		}
+	})
+	p.context.OnResponse(func(resp playwright.Response) {
+		p.requestResponded(resp.Request(), resp.Status())
+	})
+	p.context.OnRequestFinished(func(req playwright.Request) {
+		p.requestFinished(req, "")
+	})
+	p.context.OnRequestFailed(func(req playwright.Request) {
+		failure := "failed"
+		if err := req.Failure(); err != nil {
+			failure = err.Error()
+		}
+		p.requestFinished(req, failure)
+	})
+}
+
+// StartWatch starts reporting DOM changes in the active tab as watch events.
+func (p *PlaywrightBackend) StartWatch(opts WatchOptions) error {
+	page := p.getCurrentPage()
+	if page == nil {
+		return ErrBrowserNotLaunched
+	}
+	if err := p.StopWatch(); err != nil {
+		return err
+	}
+
+	// The lock is never held across Playwright calls: binding callbacks and
+	// event handlers take it too.
+	p.watchLock.Lock()
+	exposed := p.watchExposed
+	if p.watchHooked == nil {
+		p.watchHooked = make(map[playwright.Page]bool)
+	}
+	hooked := p.watchHooked[page]
+	p.watchHooked[page] = true
+	p.watchOpts = &opts
+	p.watchPage = page
+	p.watchLock.Unlock()
+
+	if !exposed {
+		err := p.context.ExposeFunction(watchBinding, func(args ...interface{}) interface{} {
+			if len(args) == 0 {
+				return nil
+			}
+			payload, _ := args[0].(string)
+			p.watchLock.Lock()
+			watching := p.watchOpts != nil
+			p.watchLock.Unlock()
+			if change, err := decodeWatchChange(payload); err == nil && watching {
+				p.emit(EventWatch, change)
+			}
+			return nil
+		})
+		if err != nil {
+			_ = p.StopWatch()
+			return fmt.Errorf("failed to expose watch binding: %w", err)
+		}
+		p.watchLock.Lock()
+		p.watchExposed = true
+		p.watchLock.Unlock()
+	}
+
+	if !hooked {
+		// Observers do not survive navigation; reinstall on each new document
+		page.OnDOMContentLoaded(func(pg playwright.Page) {
+			go p.reinstallWatch(pg)
+		})
+	}
+
+	if _, err := page.Evaluate(watchScript(opts)); err != nil {
+		_ = p.StopWatch()
+		return err
+	}
+	return nil
+}
+
+func (p *PlaywrightBackend) reinstallWatch(page playwright.Page) {
+	p.watchLock.Lock()
+	opts := p.watchOpts
+	watching := opts != nil && p.watchPage == page
+	p.watchLock.Unlock()
+
+	if watching {
+		_, _ = page.Evaluate(watchScript(*opts))
+	}
+}
+
+// StopWatch stops DOM change notifications.
+func (p *PlaywrightBackend) StopWatch() error {
+	p.watchLock.Lock()
+	page := p.watchPage
+	watching := p.watchOpts != nil
+	p.watchOpts = nil
+	p.watchPage = nil
+	p.watchLock.Unlock()
+
+	if !watching || page == nil || page.IsClosed() {
+		return nil
+	}
+	_, err := page.Evaluate(stopWatchScript)
+	return err
+}
+
 func (p *PlaywrightBackend) Close() error {
 	if !p.launched.Load() {
 		return nil
@@ -179,12 +885,32 @@ func (p *PlaywrightBackend) Close() error {
 	if p.pw != nil {
 		_ = p.pw.Stop()
 	}
+	p.closeBrowserLog()
 
 	p.launched.Store(false)
 	p.pages = nil
+
+	p.watchLock.Lock()
+	p.watchOpts = nil
+	p.watchPage = nil
+	p.watchExposed = false
+	p.watchHooked = nil
+	p.watchLock.Unlock()
+
+	p.screencastLock.Lock()
+	p.screencastSession = nil
+	p.screencastLock.Unlock()
 	return nil
 }
 
+// closeBrowserLog closes the browser log opened at launch.
+func (p *PlaywrightBackend) closeBrowserLog() {
+	if p.browserLog != nil {
+		p.browserLog.Close()
+		p.browserLog = nil
+	}
+}
+
 func (p *PlaywrightBackend) IsLaunched() bool {
 	return p.launched.Load()
 }
@@ -192,9 +918,14 @@ func (p *PlaywrightBackend) IsLaunched() bool {
 // Navigation
 
 func (p *PlaywrightBackend) Navigate(url string, waitUntil string) (string, string, error) {
+	return p.navigate(url, "", waitUntil)
+}
+
+// navigate navigates to a URL with an optional referrer.
+func (p *PlaywrightBackend) navigate(url, referrer, waitUntil string) (string, string, error) {
 	page := p.getCurrentPage()
 	if page == nil {
-		return "", "", fmt.Errorf("browser not launched")
+		return "", "", ErrBrowserNotLaunched
 	}
 
 	var waitOpt playwright.WaitUntilState
@@ -207,9 +938,13 @@ func (p *PlaywrightBackend) Navigate(url string, waitUntil string) (string, stri
 		waitOpt = *playwright.WaitUntilStateLoad
 	}
 
-	_, err := page.Goto(url, playwright.PageGotoOptions{
+	gotoOpts := playwright.PageGotoOptions{
 		WaitUntil: &waitOpt,
-	})
+	}
+	if referrer != "" {
+		gotoOpts.Referer = &referrer
+	}
+	_, err := page.Goto(url, gotoOpts)
 	if err != nil {
 		return "", "", err
 	}
@@ -220,10 +955,49 @@ func (p *PlaywrightBackend) Navigate(url string, waitUntil string) (string, stri
 	return currentURL, title, nil
 }
 
+// NavigateRequest navigates with a custom method, body, headers or
+// referrer. The navigation request is rewritten in a route; other requests
+// fall through.
+func (p *PlaywrightBackend) NavigateRequest(req NavigationRequest, waitUntil string) (string, string, error) {
+	if !req.rewritesRequest() {
+		return p.navigate(req.URL, req.Referrer, waitUntil)
+	}
+
+	page := p.getCurrentPage()
+	if page == nil {
+		return "", "", ErrBrowserNotLaunched
+	}
+
+	var rewritten atomic.Bool
+	handler := func(route playwright.Route) {
+		r := route.Request()
+		if !r.IsNavigationRequest() || r.Frame() != page.MainFrame() || r.RedirectedFrom() != nil ||
+			!rewritten.CompareAndSwap(false, true) {
+			_ = route.Fallback()
+			return
+		}
+		method := req.method()
+		opts := playwright.RouteFallbackOptions{
+			Method:  &method,
+			Headers: mergeHeaders(r.Headers(), req.Headers),
+		}
+		if req.Body != "" {
+			opts.PostData = req.Body
+		}
+		_ = route.Fallback(opts)
+	}
+	if err := page.Route("**/*", handler); err != nil {
+		return "", "", err
+	}
+	defer func() { _ = page.Unroute("**/*", handler) }()
+
+	return p.navigate(req.URL, req.Referrer, waitUntil)
+}
+
 func (p *PlaywrightBackend) Back() error {
 	page := p.getCurrentPage()
 	if page == nil {
-		return fmt.Errorf("browser not launched")
+		return ErrBrowserNotLaunched
 	}
 	_, err := page.GoBack()
 	return err
@@ -232,16 +1006,79 @@ func (p *PlaywrightBackend) Back() error {
 func (p *PlaywrightBackend) Forward() error {
 	page := p.getCurrentPage()
 	if page == nil {
-		return fmt.Errorf("browser not launched")
+		return ErrBrowserNotLaunched
 	}
 	_, err := page.GoForward()
 	return err
 }
 
+// navigationHistory reads the current page's session history over CDP,
+// which Playwright does not expose.
+func (p *PlaywrightBackend) navigationHistory() (current int, entries []historyEntryCDP, err error) {
+	var result interface{}
+	err = p.withCDPSession(func(session playwright.CDPSession) error {
+		var err error
+		result, err = session.Send("Page.getNavigationHistory", nil)
+		return err
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+	data, _ := json.Marshal(result)
+	var reply struct {
+		CurrentIndex int               `json:"currentIndex"`
+		Entries      []historyEntryCDP `json:"entries"`
+	}
+	if err := json.Unmarshal(data, &reply); err != nil {
+		return 0, nil, fmt.Errorf("unexpected navigation history: %w", err)
+	}
+	return reply.CurrentIndex, reply.Entries, nil
+}
+
+// historyEntryCDP is a CDP Page.NavigationEntry.
+type historyEntryCDP struct {
+	ID    int    `json:"id"`
+	URL   string `json:"url"`
+	Title string `json:"title"`
+}
+
+// History returns the session history of the current tab.
+func (p *PlaywrightBackend) History() (*NavigationHistory, error) {
+	current, entries, err := p.navigationHistory()
+	if err != nil {
+		return nil, err
+	}
+	history := &NavigationHistory{Current: current, Entries: []HistoryEntry{}}
+	for i, e := range entries {
+		history.Entries = append(history.Entries, HistoryEntry{Index: i, URL: e.URL, Title: e.Title})
+	}
+	return history, nil
+}
+
+// GoToHistoryEntry navigates to the history entry at index and waits for
+// its URL to load.
+func (p *PlaywrightBackend) GoToHistoryEntry(index int) error {
+	page := p.getCurrentPage()
+	if page == nil {
+		return ErrBrowserNotLaunched
+	}
+	_, entries, err := p.navigationHistory()
+	if err != nil {
+		return err
+	}
+	if index < 0 || index >= len(entries) {
+		return fmt.Errorf("history index %d out of range", index)
+	}
+	if err := p.sendCDP("Page.navigateToHistoryEntry", map[string]interface{}{"entryId": entries[index].ID}); err != nil {
+		return err
+	}
+	return page.WaitForURL(entries[index].URL)
+}
+
 func (p *PlaywrightBackend) Reload() error {
 	page := p.getCurrentPage()
 	if page == nil {
-		return fmt.Errorf("browser not launched")
+		return ErrBrowserNotLaunched
 	}
 	_, err := page.Reload()
 	return err
@@ -250,136 +1087,237 @@ func (p *PlaywrightBackend) Reload() error {
 // Interaction
 
 func (p *PlaywrightBackend) Click(selector string) error {
-	page := p.getCurrentPage()
-	if page == nil {
-		return fmt.Errorf("browser not launched")
+	frame := p.getCurrentFrame()
+	if frame == nil {
+		return ErrBrowserNotLaunched
 	}
 	sel := p.resolveSelector(selector)
-	return page.Click(sel)
+	return frame.Click(sel)
 }
 
 func (p *PlaywrightBackend) Fill(selector, value string) error {
-	page := p.getCurrentPage()
-	if page == nil {
-		return fmt.Errorf("browser not launched")
+	frame := p.getCurrentFrame()
+	if frame == nil {
+		return ErrBrowserNotLaunched
 	}
 	sel := p.resolveSelector(selector)
-	return page.Fill(sel, value)
+	return frame.Fill(sel, value)
 }
 
 func (p *PlaywrightBackend) Type(selector, text string, delay int) error {
-	page := p.getCurrentPage()
-	if page == nil {
-		return fmt.Errorf("browser not launched")
+	frame := p.getCurrentFrame()
+	if frame == nil {
+		return ErrBrowserNotLaunched
 	}
 	sel := p.resolveSelector(selector)
 
 	if delay > 0 {
 		delayFloat := float64(delay)
-		return page.Type(sel, text, playwright.PageTypeOptions{
+		return frame.Type(sel, text, playwright.FrameTypeOptions{
 			Delay: &delayFloat,
 		})
 	}
 
-	return page.Type(sel, text)
+	return frame.Type(sel, text)
 }
 
 func (p *PlaywrightBackend) Press(key string, selector string) error {
 	page := p.getCurrentPage()
 	if page == nil {
-		return fmt.Errorf("browser not launched")
+		return ErrBrowserNotLaunched
 	}
 
 	if selector != "" {
 		sel := p.resolveSelector(selector)
-		return page.Press(sel, key)
+		return p.getCurrentFrame().Press(sel, key)
 	}
 
 	return page.Keyboard().Press(key)
 }
 
-func (p *PlaywrightBackend) Hover(selector string) error {
+// InsertText inserts text with keyboard.InsertText, after focusing the
+// element if one is given.
+func (p *PlaywrightBackend) InsertText(selector, text string) error {
 	page := p.getCurrentPage()
 	if page == nil {
-		return fmt.Errorf("browser not launched")
+		return ErrBrowserNotLaunched
+	}
+	if selector != "" {
+		sel := p.resolveSelector(selector)
+		if err := p.getCurrentFrame().Focus(sel); err != nil {
+			return err
+		}
+	}
+	return page.Keyboard().InsertText(text)
+}
+
+func (p *PlaywrightBackend) Hover(selector string) error {
+	frame := p.getCurrentFrame()
+	if frame == nil {
+		return ErrBrowserNotLaunched
 	}
 	sel := p.resolveSelector(selector)
-	return page.Hover(sel)
+	return frame.Hover(sel)
+}
+
+// Tap taps an element. Playwright only taps in contexts with touch
+// support, so elsewhere the touch events are sent over CDP.
+func (p *PlaywrightBackend) Tap(selector string) error {
+	frame := p.getCurrentFrame()
+	if frame == nil {
+		return ErrBrowserNotLaunched
+	}
+	sel := p.resolveSelector(selector)
+	err := frame.Tap(sel)
+	if err == nil || !strings.Contains(err.Error(), "hasTouch") {
+		return err
+	}
+
+	locator := frame.Locator(sel).First()
+	if err := locator.ScrollIntoViewIfNeeded(); err != nil {
+		return err
+	}
+	box, err := locator.BoundingBox()
+	if err != nil {
+		return err
+	}
+	if box == nil {
+		return fmt.Errorf("element has no visible area: %s", selector)
+	}
+	point := map[string]interface{}{"x": box.X + box.Width/2, "y": box.Y + box.Height/2}
+	return p.withCDPSession(func(session playwright.CDPSession) error {
+		if _, err := session.Send("Input.dispatchTouchEvent", map[string]interface{}{
+			"type": "touchStart", "touchPoints": []interface{}{point},
+		}); err != nil {
+			return err
+		}
+		_, err := session.Send("Input.dispatchTouchEvent", map[string]interface{}{
+			"type": "touchEnd", "touchPoints": []interface{}{},
+		})
+		return err
+	})
 }
 
 func (p *PlaywrightBackend) Focus(selector string) error {
-	page := p.getCurrentPage()
-	if page == nil {
-		return fmt.Errorf("browser not launched")
+	frame := p.getCurrentFrame()
+	if frame == nil {
+		return ErrBrowserNotLaunched
 	}
 	sel := p.resolveSelector(selector)
-	return page.Focus(sel)
+	return frame.Focus(sel)
 }
 
 func (p *PlaywrightBackend) Check(selector string) error {
-	page := p.getCurrentPage()
-	if page == nil {
-		return fmt.Errorf("browser not launched")
+	frame := p.getCurrentFrame()
+	if frame == nil {
+		return ErrBrowserNotLaunched
 	}
 	sel := p.resolveSelector(selector)
-	return page.Check(sel)
+	return frame.Check(sel)
 }
 
 func (p *PlaywrightBackend) Uncheck(selector string) error {
-	page := p.getCurrentPage()
-	if page == nil {
-		return fmt.Errorf("browser not launched")
+	frame := p.getCurrentFrame()
+	if frame == nil {
+		return ErrBrowserNotLaunched
 	}
 	sel := p.resolveSelector(selector)
-	return page.Uncheck(sel)
+	return frame.Uncheck(sel)
 }
 
-func (p *PlaywrightBackend) Select(selector string, values []string) error {
-	page := p.getCurrentPage()
-	if page == nil {
-		return fmt.Errorf("browser not launched")
+func (p *PlaywrightBackend) Select(selector string, values []string, by string) error {
+	frame := p.getCurrentFrame()
+	if frame == nil {
+		return ErrBrowserNotLaunched
 	}
 	sel := p.resolveSelector(selector)
-	_, err := page.SelectOption(sel, playwright.SelectOptionValues{Values: &values})
+
+	var options playwright.SelectOptionValues
+	switch by {
+	case "label":
+		options.Labels = &values
+	case "index":
+		indexes := make([]int, len(values))
+		for i, v := range values {
+			indexes[i], _ = strconv.Atoi(v) // checked by BrowserManager.Select
+		}
+		options.Indexes = &indexes
+	default:
+		options.Values = &values
+	}
+	_, err := frame.SelectOption(sel, options)
 	return err
 }
 
 func (p *PlaywrightBackend) DoubleClick(selector string) error {
-	page := p.getCurrentPage()
-	if page == nil {
-		return fmt.Errorf("browser not launched")
+	frame := p.getCurrentFrame()
+	if frame == nil {
+		return ErrBrowserNotLaunched
 	}
 	sel := p.resolveSelector(selector)
-	return page.Dblclick(sel)
+	return frame.Dblclick(sel)
 }
 
 func (p *PlaywrightBackend) Clear(selector string) error {
-	page := p.getCurrentPage()
-	if page == nil {
-		return fmt.Errorf("browser not launched")
+	frame := p.getCurrentFrame()
+	if frame == nil {
+		return ErrBrowserNotLaunched
+	}
+	sel := p.resolveSelector(selector)
+	return frame.Fill(sel, "")
+}
+
+func (p *PlaywrightBackend) Upload(selector string, files []string) error {
+	frame := p.getCurrentFrame()
+	if frame == nil {
+		return ErrBrowserNotLaunched
 	}
 	sel := p.resolveSelector(selector)
-	return page.Fill(sel, "")
+	return frame.SetInputFiles(sel, files)
+}
+
+// InputMouse dispatches a raw mouse event over CDP, which unlike
+// page.Mouse() takes modifiers and the held buttons.
+func (p *PlaywrightBackend) InputMouse(ev MouseEvent) error {
+	params, err := cdpParams(mouseEventParams(ev))
+	if err != nil {
+		return err
+	}
+	return p.sendCDP("Input.dispatchMouseEvent", params)
+}
+
+// InputKeyboard dispatches a raw key event over CDP, as page.Keyboard()
+// cannot send a keyDown without its text or a char alone.
+func (p *PlaywrightBackend) InputKeyboard(ev KeyEvent) error {
+	key, err := keyEventParams(ev)
+	if err != nil {
+		return err
+	}
+	params, err := cdpParams(key)
+	if err != nil {
+		return err
+	}
+	return p.sendCDP("Input.dispatchKeyEvent", params)
 }
 
 // Queries
 
 func (p *PlaywrightBackend) GetText(selector string) (string, error) {
-	page := p.getCurrentPage()
-	if page == nil {
-		return "", fmt.Errorf("browser not launched")
+	frame := p.getCurrentFrame()
+	if frame == nil {
+		return "", ErrBrowserNotLaunched
 	}
 	sel := p.resolveSelector(selector)
-	return page.TextContent(sel)
+	return frame.TextContent(sel)
 }
 
 func (p *PlaywrightBackend) GetAttribute(selector, attr string) (string, error) {
-	page := p.getCurrentPage()
-	if page == nil {
-		return "", fmt.Errorf("browser not launched")
+	frame := p.getCurrentFrame()
+	if frame == nil {
+		return "", ErrBrowserNotLaunched
 	}
 	sel := p.resolveSelector(selector)
-	value, err := page.GetAttribute(sel, attr)
+	value, err := frame.GetAttribute(sel, attr)
 	if err != nil {
 		return "", err
 	}
@@ -387,14 +1325,14 @@ func (p *PlaywrightBackend) GetAttribute(selector, attr string) (string, error)
 }
 
 func (p *PlaywrightBackend) GetHTML(selector string, outer bool) (string, error) {
-	page := p.getCurrentPage()
-	if page == nil {
-		return "", fmt.Errorf("browser not launched")
+	frame := p.getCurrentFrame()
+	if frame == nil {
+		return "", ErrBrowserNotLaunched
 	}
 	sel := p.resolveSelector(selector)
 
 	if outer {
-		result, err := page.Evaluate(fmt.Sprintf(`document.querySelector(%q).outerHTML`, sel))
+		result, err := frame.Evaluate(fmt.Sprintf(`document.querySelector(%q).outerHTML`, sel))
 		if err != nil {
 			return "", err
 		}
@@ -404,75 +1342,75 @@ func (p *PlaywrightBackend) GetHTML(selector string, outer bool) (string, error)
 		return "", fmt.Errorf("unexpected result type")
 	}
 
-	return page.InnerHTML(sel)
+	return frame.InnerHTML(sel)
 }
 
 func (p *PlaywrightBackend) GetInputValue(selector string) (string, error) {
-	page := p.getCurrentPage()
-	if page == nil {
-		return "", fmt.Errorf("browser not launched")
+	frame := p.getCurrentFrame()
+	if frame == nil {
+		return "", ErrBrowserNotLaunched
 	}
 	sel := p.resolveSelector(selector)
-	return page.InputValue(sel)
+	return frame.InputValue(sel)
 }
 
-func (p *PlaywrightBackend) SetValue(selector, value string) error {
-	page := p.getCurrentPage()
-	if page == nil {
-		return fmt.Errorf("browser not launched")
+func (p *PlaywrightBackend) SetValue(selector, value string) error {
+	frame := p.getCurrentFrame()
+	if frame == nil {
+		return ErrBrowserNotLaunched
 	}
 	sel := p.resolveSelector(selector)
-	return page.Fill(sel, value)
+	return frame.Fill(sel, value)
 }
 
 func (p *PlaywrightBackend) IsVisible(selector string) (bool, error) {
-	page := p.getCurrentPage()
-	if page == nil {
-		return false, fmt.Errorf("browser not launched")
+	frame := p.getCurrentFrame()
+	if frame == nil {
+		return false, ErrBrowserNotLaunched
 	}
 	sel := p.resolveSelector(selector)
-	return page.IsVisible(sel)
+	return frame.IsVisible(sel)
 }
 
 func (p *PlaywrightBackend) IsEnabled(selector string) (bool, error) {
-	page := p.getCurrentPage()
-	if page == nil {
-		return false, fmt.Errorf("browser not launched")
+	frame := p.getCurrentFrame()
+	if frame == nil {
+		return false, ErrBrowserNotLaunched
 	}
 	sel := p.resolveSelector(selector)
-	return page.IsEnabled(sel)
+	return frame.IsEnabled(sel)
 }
 
 func (p *PlaywrightBackend) IsChecked(selector string) (bool, error) {
-	page := p.getCurrentPage()
-	if page == nil {
-		return false, fmt.Errorf("browser not launched")
+	frame := p.getCurrentFrame()
+	if frame == nil {
+		return false, ErrBrowserNotLaunched
 	}
 	sel := p.resolveSelector(selector)
-	return page.IsChecked(sel)
+	return frame.IsChecked(sel)
 }
 
 func (p *PlaywrightBackend) Count(selector string) (int, error) {
-	page := p.getCurrentPage()
-	if page == nil {
-		return 0, fmt.Errorf("browser not launched")
+	frame := p.getCurrentFrame()
+	if frame == nil {
+		return 0, ErrBrowserNotLaunched
 	}
 	sel := p.resolveSelector(selector)
-	return page.Locator(sel).Count()
+	return frame.Locator(sel).Count()
 }
 
 func (p *PlaywrightBackend) GetBoundingBox(selector string) (*BoundingBox, error) {
-	page := p.getCurrentPage()
-	if page == nil {
-		return nil, fmt.Errorf("browser not launched")
+	frame := p.getCurrentFrame()
+	if frame == nil {
+		return nil, ErrBrowserNotLaunched
 	}
 	sel := p.resolveSelector(selector)
-	box, err := page.Locator(sel).BoundingBox()
+	box, err := frame.Locator(sel).BoundingBox()
 	if err != nil {
 		return nil, err
 	}
 	if box == nil {
-		return nil, fmt.Errorf("element not found")
+		return nil, ErrElementNotFound
 	}
 	return &BoundingBox{
 		X:      box.X,
@@ -482,12 +1420,106 @@ func (p *PlaywrightBackend) GetBoundingBox(selector string) (*BoundingBox, error
 	}, nil
 }
 
+func (p *PlaywrightBackend) Describe(selector string, maxText int) (*ElementDescription, error) {
+	frame := p.getCurrentFrame()
+	if frame == nil {
+		return nil, ErrBrowserNotLaunched
+	}
+	sel := p.resolveSelector(selector)
+	result, err := frame.Locator(sel).First().Evaluate(describeElementScript, maxText)
+	if err != nil {
+		return nil, err
+	}
+	return decodeElementDescription(result)
+}
+
+func (p *PlaywrightBackend) ReadableText(selector string, plain bool) (string, error) {
+	frame := p.getCurrentFrame()
+	if frame == nil {
+		return "", ErrBrowserNotLaunched
+	}
+	sel := p.resolveSelector(selector)
+	result, err := frame.Locator(sel).First().Evaluate(markdownScript, plain)
+	if err != nil {
+		return "", err
+	}
+	text, _ := result.(string)
+	return text, nil
+}
+
+func (p *PlaywrightBackend) Links(selector string) ([]Link, error) {
+	frame := p.getCurrentFrame()
+	if frame == nil {
+		return nil, ErrBrowserNotLaunched
+	}
+	sel := p.resolveSelector(selector)
+	result, err := frame.Locator(sel).First().Evaluate(linksScript, nil)
+	if err != nil {
+		return nil, err
+	}
+	return decodeLinks(result)
+}
+
+func (p *PlaywrightBackend) DispatchEvent(selector, event string, init map[string]interface{}) error {
+	frame := p.getCurrentFrame()
+	if frame == nil {
+		return ErrBrowserNotLaunched
+	}
+	sel := p.resolveSelector(selector)
+	// The shared script rather than Playwright's dispatchEvent, which makes
+	// plain Events of custom types and drops their detail
+	_, err := frame.Locator(sel).First().Evaluate(
+		`(el, [type, init]) => (`+dispatchEventScript+`)(el, type, init)`, []interface{}{event, init})
+	return err
+}
+
+func (p *PlaywrightBackend) SelectAll(selector string) error {
+	frame := p.getCurrentFrame()
+	if frame == nil {
+		return ErrBrowserNotLaunched
+	}
+	sel := p.resolveSelector(selector)
+	_, err := frame.Locator(sel).First().Evaluate(selectAllScript, nil)
+	return err
+}
+
+func (p *PlaywrightBackend) Highlight(selector string, duration int) error {
+	frame := p.getCurrentFrame()
+	if frame == nil {
+		return ErrBrowserNotLaunched
+	}
+	sel := p.resolveSelector(selector)
+	_, err := frame.Locator(sel).First().Evaluate(highlightElementScript, duration)
+	return err
+}
+
+// Locate returns Playwright's own selector for the locator's first match,
+// visible if any is, so commands given it resolve it the way page.GetByRole
+// and friends do.
+func (p *PlaywrightBackend) Locate(loc Locator) (string, error) {
+	frame := p.getCurrentFrame()
+	if frame == nil {
+		return "", ErrBrowserNotLaunched
+	}
+	base := playwrightLocatorSelector(loc)
+	for _, sel := range []string{base + " >> visible=true", base} {
+		n, err := frame.Locator(sel).Count()
+		if err != nil {
+			return "", err
+		}
+		if n > 0 {
+			return sel + " >> nth=0", nil
+		}
+	}
+	return "", fmt.Errorf("%w: %s", ErrElementNotFound, loc)
+}
+
 // Page Info
 
 func (p *PlaywrightBackend) URL() (string, error) {
 	page := p.getCurrentPage()
 	if page == nil {
-		return "", fmt.Errorf("browser not launched")
+		return "", ErrBrowserNotLaunched
 	}
 	return page.URL(), nil
 }
@@ -495,25 +1527,25 @@ func (p *PlaywrightBackend) URL() (string, error) {
 func (p *PlaywrightBackend) Title() (string, error) {
 	page := p.getCurrentPage()
 	if page == nil {
-		return "", fmt.Errorf("browser not launched")
+		return "", ErrBrowserNotLaunched
 	}
 	return page.Title()
 }
 
 func (p *PlaywrightBackend) Content() (string, error) {
-	page := p.getCurrentPage()
-	if page == nil {
-		return "", fmt.Errorf("browser not launched")
+	frame := p.getCurrentFrame()
+	if frame == nil {
+		return "", ErrBrowserNotLaunched
 	}
-	return page.Content()
+	return frame.Content()
 }
 
 func (p *PlaywrightBackend) SetContent(html string) error {
-	page := p.getCurrentPage()
-	if page == nil {
-		return fmt.Errorf("browser not launched")
+	frame := p.getCurrentFrame()
+	if frame == nil {
+		return ErrBrowserNotLaunched
 	}
-	return page.SetContent(html)
+	return frame.SetContent(html)
 }
 
 // Viewport & Screenshot
@@ -521,7 +1553,7 @@ func (p *PlaywrightBackend) SetContent(html string) error {
 func (p *PlaywrightBackend) SetViewport(width, height int) error {
 	page := p.getCurrentPage()
 	if page == nil {
-		return fmt.Errorf("browser not launched")
+		return ErrBrowserNotLaunched
 	}
 	return page.SetViewportSize(width, height)
 }
@@ -529,7 +1561,7 @@ func (p *PlaywrightBackend) SetViewport(width, height int) error {
 func (p *PlaywrightBackend) Screenshot(fullPage bool, selector string, quality int) ([]byte, error) {
 	page := p.getCurrentPage()
 	if page == nil {
-		return nil, fmt.Errorf("browser not launched")
+		return nil, ErrBrowserNotLaunched
 	}
 
 	// Use JPEG format to support quality parameter
@@ -545,7 +1577,7 @@ func (p *PlaywrightBackend) Screenshot(fullPage bool, selector string, quality i
 
 	if selector != "" {
 		sel := p.resolveSelector(selector)
-		locator := page.Locator(sel)
+		locator := p.getCurrentFrame().Locator(sel)
 		return locator.Screenshot(playwright.LocatorScreenshotOptions{
 			Type:    screenshotType,
 			Quality: opts.Quality,
@@ -555,26 +1587,125 @@ func (p *PlaywrightBackend) Screenshot(fullPage bool, selector string, quality i
 	return page.Screenshot(opts)
 }
 
-// JavaScript
+func (p *PlaywrightBackend) PDF(opts PDFOptions) ([]byte, error) {
+	page := p.getCurrentPage()
+	if page == nil {
+		return nil, ErrBrowserNotLaunched
+	}
 
-func (p *PlaywrightBackend) Evaluate(script string) (interface{}, error) {
+	format := opts.Format
+	pdfOpts := playwright.PagePdfOptions{
+		Format:          &format,
+		Landscape:       &opts.Landscape,
+		PrintBackground: &opts.PrintBackground,
+	}
+	if m := opts.Margin; m != nil {
+		length := func(s string) *string {
+			if s == "" {
+				return nil
+			}
+			return &s
+		}
+		pdfOpts.Margin = &playwright.Margin{
+			Top:    length(m.Top),
+			Right:  length(m.Right),
+			Bottom: length(m.Bottom),
+			Left:   length(m.Left),
+		}
+	}
+	return page.PDF(pdfOpts)
+}
+
+// StartScreencast starts Page.startScreencast over a CDP session on the
+// active page, which stays attached until StopScreencast. Each frame is
+// acknowledged so Chrome sends the next.
+func (p *PlaywrightBackend) StartScreencast(opts ScreencastOptions, onFrame func(ScreencastFrame)) error {
+	p.screencastLock.Lock()
+	defer p.screencastLock.Unlock()
+	if p.screencastSession != nil {
+		return fmt.Errorf("screencast already started")
+	}
 	page := p.getCurrentPage()
 	if page == nil {
-		return nil, fmt.Errorf("browser not launched")
+		return ErrBrowserNotLaunched
+	}
+	session, err := p.context.NewCDPSession(page)
+	if err != nil {
+		return err
+	}
+
+	session.On("Page.screencastFrame", func(params map[string]interface{}) {
+		var frame cdpScreencastFrame
+		data, err := json.Marshal(params)
+		if err != nil || json.Unmarshal(data, &frame) != nil {
+			return
+		}
+		go func() {
+			_, _ = session.Send("Page.screencastFrameAck", map[string]interface{}{"sessionId": frame.SessionID})
+		}()
+		onFrame(frame.frame())
+	})
+	if _, err := session.Send("Page.startScreencast", screencastParams(opts)); err != nil {
+		_ = session.Detach()
+		return err
+	}
+	p.screencastSession = session
+	return nil
+}
+
+// StopScreencast stops the screencast and detaches its CDP session.
+func (p *PlaywrightBackend) StopScreencast() error {
+	p.screencastLock.Lock()
+	session := p.screencastSession
+	p.screencastSession = nil
+	p.screencastLock.Unlock()
+	if session == nil {
+		return nil // ended with the browser
+	}
+	_, err := session.Send("Page.stopScreencast", nil)
+	_ = session.Detach()
+	return err
+}
+
+// StartTracing starts recording a Playwright trace of the context.
+func (p *PlaywrightBackend) StartTracing(opts TraceOptions) error {
+	if !p.launched.Load() {
+		return ErrBrowserNotLaunched
+	}
+	return p.context.Tracing().Start(playwright.TracingStartOptions{
+		Screenshots: &opts.Screenshots,
+		Snapshots:   &opts.Snapshots,
+	})
+}
+
+// StopTracing stops tracing and saves the trace zip to path.
+func (p *PlaywrightBackend) StopTracing(path string) error {
+	if !p.launched.Load() {
+		return ErrBrowserNotLaunched
+	}
+	return p.context.Tracing().Stop(path)
+}
+
+// JavaScript
+
+func (p *PlaywrightBackend) Evaluate(script string) (interface{}, error) {
+	frame := p.getCurrentFrame()
+	if frame == nil {
+		return nil, ErrBrowserNotLaunched
 	}
-	return page.Evaluate(script)
+	return frame.Evaluate(script)
 }
 
 // Waiting
 
 func (p *PlaywrightBackend) Wait(selector string, timeout int, state string) error {
-	page := p.getCurrentPage()
-	if page == nil {
-		return fmt.Errorf("browser not launched")
+	frame := p.getCurrentFrame()
+	if frame == nil {
+		return ErrBrowserNotLaunched
 	}
 
 	sel := p.resolveSelector(selector)
-	opts := playwright.PageWaitForSelectorOptions{}
+	opts := playwright.FrameWaitForSelectorOptions{}
 
 	if timeout > 0 {
 		timeoutFloat := float64(timeout)
@@ -592,14 +1723,35 @@ func (p *PlaywrightBackend) Wait(selector string, timeout int, state string) err
 		opts.State = playwright.WaitForSelectorStateVisible
 	}
 
-	_, err := page.WaitForSelector(sel, opts)
+	_, err := frame.WaitForSelector(sel, opts)
 	return err
 }
 
+// WaitForLoadState waits for a load state of the current page.
+func (p *PlaywrightBackend) WaitForLoadState(state string, timeout int) error {
+	page := p.getCurrentPage()
+	if page == nil {
+		return ErrBrowserNotLaunched
+	}
+
+	loadState := playwright.LoadStateLoad
+	switch state {
+	case "domcontentloaded":
+		loadState = playwright.LoadStateDomcontentloaded
+	case "networkidle":
+		loadState = playwright.LoadStateNetworkidle
+	}
+	timeoutFloat := float64(timeout)
+	return page.WaitForLoadState(playwright.PageWaitForLoadStateOptions{
+		State:   loadState,
+		Timeout: &timeoutFloat,
+	})
+}
+
 func (p *PlaywrightBackend) WaitForTimeout(ms int) error {
 	page := p.getCurrentPage()
 	if page == nil {
-		return fmt.Errorf("browser not launched")
+		return ErrBrowserNotLaunched
 	}
 	page.WaitForTimeout(float64(ms))
 	return nil
@@ -610,7 +1762,7 @@ func (p *PlaywrightBackend) WaitForTimeout(ms int) error {
 func (p *PlaywrightBackend) Scroll(direction string, amount int) error {
 	page := p.getCurrentPage()
 	if page == nil {
-		return fmt.Errorf("browser not launched")
+		return ErrBrowserNotLaunched
 	}
 
 	dx, dy := 0, 0
@@ -630,25 +1782,34 @@ func (p *PlaywrightBackend) Scroll(direction string, amount int) error {
 }
 
 func (p *PlaywrightBackend) ScrollIntoView(selector string) error {
-	page := p.getCurrentPage()
-	if page == nil {
-		return fmt.Errorf("browser not launched")
+	frame := p.getCurrentFrame()
+	if frame == nil {
+		return ErrBrowserNotLaunched
 	}
 	sel := p.resolveSelector(selector)
-	return page.Locator(sel).ScrollIntoViewIfNeeded()
+	return frame.Locator(sel).ScrollIntoViewIfNeeded()
 }
 
 // Tabs
 
 func (p *PlaywrightBackend) NewTab(url string) (int, error) {
 	if p.context == nil {
-		return 0, fmt.Errorf("browser not launched")
+		return 0, ErrBrowserNotLaunched
 	}
 
 	page, err := p.context.NewPage()
 	if err != nil {
 		return 0, err
 	}
+	if err := p.applyUserAgent(page); err != nil {
+		return 0, err
+	}
+	if err := p.applyServiceWorkerBypass(page); err != nil {
+		return 0, err
+	}
+	if err := p.applyMedia(page); err != nil {
+		return 0, err
+	}
 
 	p.pages = append(p.pages, page)
 	p.activeTab = len(p.pages) - 1
@@ -663,6 +1824,83 @@ func (p *PlaywrightBackend) NewTab(url string) (int, error) {
 	return p.activeTab, nil
 }
 
+// NewWindow opens a tab in a new browser window. Playwright has no notion
+// of windows, so the page is created over CDP in the current page's browser
+// context and picked up as Playwright reports it.
+func (p *PlaywrightBackend) NewWindow(viewport *Viewport) (int, error) {
+	if p.context == nil {
+		return 0, ErrBrowserNotLaunched
+	}
+
+	params := map[string]interface{}{"url": "about:blank", "newWindow": true}
+	page, err := p.context.ExpectPage(func() error {
+		return p.withCDPSession(func(session playwright.CDPSession) error {
+			// A persistent context is the browser's default one, which
+			// needs no ID
+			if p.browser != nil {
+				info, err := session.Send("Target.getTargetInfo", nil)
+				if err != nil {
+					return err
+				}
+				var result struct {
+					TargetInfo struct {
+						BrowserContextID string `json:"browserContextId"`
+					} `json:"targetInfo"`
+				}
+				data, _ := json.Marshal(info)
+				if err := json.Unmarshal(data, &result); err != nil {
+					return fmt.Errorf("unexpected target info: %w", err)
+				}
+				params["browserContextId"] = result.TargetInfo.BrowserContextID
+			}
+			_, err := session.Send("Target.createTarget", params)
+			return err
+		})
+	})
+	if err != nil {
+		return 0, err
+	}
+	if err := p.applyUserAgent(page); err != nil {
+		return 0, err
+	}
+	if err := p.applyServiceWorkerBypass(page); err != nil {
+		return 0, err
+	}
+	if err := p.applyMedia(page); err != nil {
+		return 0, err
+	}
+
+	p.pages = append(p.pages, page)
+	p.activeTab = len(p.pages) - 1
+	if viewport != nil {
+		if err := page.SetViewportSize(viewport.Width, viewport.Height); err != nil {
+			return 0, err
+		}
+	}
+	return p.activeTab, nil
+}
+
+// windowID returns the ID of the browser window holding page.
+func (p *PlaywrightBackend) windowID(page playwright.Page) (int, error) {
+	session, err := p.context.NewCDPSession(page)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = session.Detach() }()
+	result, err := session.Send("Browser.getWindowForTarget", nil)
+	if err != nil {
+		return 0, err
+	}
+	var window struct {
+		WindowID int `json:"windowId"`
+	}
+	data, _ := json.Marshal(result)
+	if err := json.Unmarshal(data, &window); err != nil {
+		return 0, fmt.Errorf("unexpected window: %w", err)
+	}
+	return window.WindowID, nil
+}
+
 func (p *PlaywrightBackend) SwitchTab(index int) error {
 	if index < 0 || index >= len(p.pages) {
 		return fmt.Errorf("tab index out of range: %d", index)
@@ -671,12 +1909,23 @@ func (p *PlaywrightBackend) SwitchTab(index int) error {
 	return nil
 }
 
+func (p *PlaywrightBackend) BringToFront() error {
+	page := p.getCurrentPage()
+	if page == nil {
+		return ErrBrowserNotLaunched
+	}
+	return page.BringToFront()
+}
+
 func (p *PlaywrightBackend) CloseTab(index int) error {
 	if index < 0 || index >= len(p.pages) {
 		return fmt.Errorf("tab index out of range: %d", index)
 	}
 
 	if p.pages[index] != nil {
+		delete(p.uaSessions, p.pages[index])
+		delete(p.swSessions, p.pages[index])
+		delete(p.frames, p.pages[index])
 		p.pages[index].Close()
 	}
 
@@ -697,9 +1946,11 @@ func (p *PlaywrightBackend) ListTabs() ([]TabInfo, error) {
 
 	for i, page := range p.pages {
 		var url, title string
+		var window int
 		if page != nil {
 			url = page.URL()
 			title, _ = page.Title()
+			window, _ = p.windowID(page)
 		}
 
 		tabs[i] = TabInfo{
@@ -707,31 +1958,94 @@ func (p *PlaywrightBackend) ListTabs() ([]TabInfo, error) {
 			URL:    url,
 			Title:  title,
 			Active: i == p.activeTab,
+			Window: window,
 		}
 	}
 
 	return tabs, nil
 }
 
+// Frames
+
+func (p *PlaywrightBackend) SwitchFrame(ref FrameRef) error {
+	page := p.getCurrentPage()
+	if page == nil {
+		return ErrBrowserNotLaunched
+	}
+
+	var frame playwright.Frame
+	if ref.Selector != "" {
+		// Matched in the current frame, so frames nest
+		el, err := p.getCurrentFrame().QuerySelector(p.resolveSelector(ref.Selector))
+		if err != nil {
+			return err
+		}
+		if el == nil {
+			return frameNotFound(ref)
+		}
+		if frame, err = el.ContentFrame(); err != nil || frame == nil {
+			return fmt.Errorf("%s is not a frame", ref.Selector)
+		}
+	} else {
+		var children []playwright.Frame
+		var frames []frameInfo
+		for _, f := range page.Frames() {
+			if f != page.MainFrame() {
+				children = append(children, f)
+				frames = append(frames, frameInfo{Name: f.Name(), URL: f.URL()})
+			}
+		}
+		i := matchFrame(frames, ref)
+		if i < 0 {
+			return frameNotFound(ref)
+		}
+		frame = children[i]
+	}
+
+	if p.frames == nil {
+		p.frames = make(map[playwright.Page]playwright.Frame)
+	}
+	p.frames[page] = frame
+	p.clearRefs()
+	return nil
+}
+
+func (p *PlaywrightBackend) MainFrame() error {
+	page := p.getCurrentPage()
+	if page == nil {
+		return ErrBrowserNotLaunched
+	}
+	delete(p.frames, page)
+	p.clearRefs()
+	return nil
+}
+
+// clearRefs drops snapshot refs, which belong to the frame they were taken in.
+func (p *PlaywrightBackend) clearRefs() {
+	p.refLock.Lock()
+	p.refMap = make(RefMap)
+	p.refLock.Unlock()
+}
+
 // Snapshot
 
 func (p *PlaywrightBackend) GetSnapshot(opts SnapshotOptions) (*EnhancedSnapshot, error) {
 	page := p.getCurrentPage()
 	if page == nil {
-		return nil, fmt.Errorf("browser not launched")
+		return nil, ErrBrowserNotLaunched
 	}
 
 	// Wait for page to be fully loaded (networkidle ensures all resources loaded)
 	if err := page.WaitForLoadState(playwright.PageWaitForLoadStateOptions{
 		State: playwright.LoadStateNetworkidle,
 	}); err != nil {
-		log.Printf("Warning: WaitForLoadState failed: %v", err)
+		Logger().Warn("snapshot: page not idle", "err", err)
 		// Continue anyway - page might already be loaded
 	}
 
 	// Use Playwright's built-in AriaSnapshot API (like TypeScript version)
 	// This returns a formatted ARIA tree string
-	locator := page.Locator(":root")
+	locator := p.getCurrentFrame().Locator(":root")
 	ariaTree, err := locator.AriaSnapshot()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get ARIA snapshot: %w", err)
@@ -745,9 +2059,11 @@ func (p *PlaywrightBackend) GetSnapshot(opts SnapshotOptions) (*EnhancedSnapshot
 	// This matches the TypeScript processAriaTree function
 	snapshot := processAriaTree(ariaTree, opts)
 
-	p.refLock.Lock()
-	p.refMap = snapshot.Refs
-	p.refLock.Unlock()
+	if !opts.keepRefs {
+		p.refLock.Lock()
+		p.refMap = snapshot.Refs
+		p.refLock.Unlock()
+	}
 
 	return snapshot, nil
 }
@@ -769,7 +2085,7 @@ func (p *PlaywrightBackend) GetRefMap() RefMap {
 
 func (p *PlaywrightBackend) GetCookies() ([]Cookie, error) {
 	if p.context == nil {
-		return nil, fmt.Errorf("browser not launched")
+		return nil, ErrBrowserNotLaunched
 	}
 
 	pwCookies, err := p.context.Cookies()
@@ -798,6 +2114,177 @@ func (p *PlaywrightBackend) GetCookies() ([]Cookie, error) {
 	return cookies, nil
 }
 
+// SetCookies adds cookies to the browser context. Cookies without an expiry
+// are session cookies.
+func (p *PlaywrightBackend) SetCookies(cookies []Cookie) error {
+	if p.context == nil {
+		return ErrBrowserNotLaunched
+	}
+
+	pwCookies := make([]playwright.OptionalCookie, len(cookies))
+	for i, c := range cookies {
+		cookie := playwright.OptionalCookie{
+			Name:     c.Name,
+			Value:    c.Value,
+			HttpOnly: playwright.Bool(c.HTTPOnly),
+			Secure:   playwright.Bool(c.Secure),
+		}
+		// Playwright needs either a URL or a domain and path
+		if c.URL != "" {
+			cookie.URL = playwright.String(c.URL)
+		} else {
+			path := c.Path
+			if path == "" {
+				path = "/"
+			}
+			cookie.Domain = playwright.String(c.Domain)
+			cookie.Path = playwright.String(path)
+		}
+		if c.Expires > 0 {
+			cookie.Expires = playwright.Float(float64(c.Expires))
+		}
+		if c.SameSite != "" {
+			sameSite := playwright.SameSiteAttribute(c.SameSite)
+			cookie.SameSite = &sameSite
+		}
+		pwCookies[i] = cookie
+	}
+
+	return p.context.AddCookies(pwCookies)
+}
+
+// ClearCookies deletes all cookies in the browser context.
+func (p *PlaywrightBackend) ClearCookies() error {
+	if p.context == nil {
+		return ErrBrowserNotLaunched
+	}
+	return p.context.ClearCookies()
+}
+
+// ClearCache clears the HTTP cache, which Playwright only exposes over CDP.
+func (p *PlaywrightBackend) ClearCache() error {
+	return p.sendCDP("Network.clearBrowserCache", nil)
+}
+
+// ClearStorage clears an origin's stored data other than cookies.
+func (p *PlaywrightBackend) ClearStorage(origin string) error {
+	return p.sendCDP("Storage.clearDataForOrigin", map[string]interface{}{
+		"origin":       origin,
+		"storageTypes": clearStorageTypes,
+	})
+}
+
+// PerformanceMetrics returns the page's run-time metrics, which Playwright
+// only exposes over CDP.
+func (p *PlaywrightBackend) PerformanceMetrics() (map[string]float64, error) {
+	var result interface{}
+	err := p.withCDPSession(func(session playwright.CDPSession) error {
+		if _, err := session.Send("Performance.enable", nil); err != nil {
+			return err
+		}
+		var err error
+		result, err = session.Send("Performance.getMetrics", nil)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	data, _ := json.Marshal(result)
+	var reply struct {
+		Metrics []struct {
+			Name  string  `json:"name"`
+			Value float64 `json:"value"`
+		} `json:"metrics"`
+	}
+	if err := json.Unmarshal(data, &reply); err != nil {
+		return nil, fmt.Errorf("unexpected performance metrics: %w", err)
+	}
+	metrics := make(map[string]float64, len(reply.Metrics))
+	for _, m := range reply.Metrics {
+		metrics[m.Name] = m.Value
+	}
+	return metrics, nil
+}
+
+// BrowserProcesses lists the browser's processes. It needs a browser-level
+// CDP session, which persistent contexts do not offer.
+func (p *PlaywrightBackend) BrowserProcesses() ([]BrowserProcess, error) {
+	if p.browser == nil {
+		return nil, fmt.Errorf("browser processes are not available with a persistent profile")
+	}
+	session, err := p.browser.NewBrowserCDPSession()
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = session.Detach() }()
+	result, err := session.Send("SystemInfo.getProcessInfo", nil)
+	if err != nil {
+		return nil, err
+	}
+	data, _ := json.Marshal(result)
+	var reply struct {
+		ProcessInfo []struct {
+			Type string `json:"type"`
+			ID   int    `json:"id"`
+		} `json:"processInfo"`
+	}
+	if err := json.Unmarshal(data, &reply); err != nil {
+		return nil, fmt.Errorf("unexpected process info: %w", err)
+	}
+	processes := make([]BrowserProcess, len(reply.ProcessInfo))
+	for i, info := range reply.ProcessInfo {
+		processes[i] = BrowserProcess{PID: info.ID, Type: info.Type}
+	}
+	return processes, nil
+}
+
+// HeapSnapshot writes a heap snapshot of the active tab, which the page
+// streams as chunk events while the command runs.
+func (p *PlaywrightBackend) HeapSnapshot(w io.Writer) error {
+	return p.withCDPSession(func(session playwright.CDPSession) error {
+		var mu sync.Mutex
+		var writeErr error
+		session.On("HeapProfiler.addHeapSnapshotChunk", func(params map[string]interface{}) {
+			chunk, _ := params["chunk"].(string)
+			mu.Lock()
+			defer mu.Unlock()
+			if writeErr == nil {
+				_, writeErr = io.WriteString(w, chunk)
+			}
+		})
+		if _, err := session.Send("HeapProfiler.takeHeapSnapshot", map[string]interface{}{"reportProgress": false}); err != nil {
+			return err
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		return writeErr
+	})
+}
+
+// sendCDP sends one CDP command through a short-lived session on the
+// current page.
+func (p *PlaywrightBackend) sendCDP(method string, params map[string]interface{}) error {
+	return p.withCDPSession(func(session playwright.CDPSession) error {
+		_, err := session.Send(method, params)
+		return err
+	})
+}
+
+// withCDPSession runs fn with a short-lived CDP session on the current
+// page, for commands that must share a session.
+func (p *PlaywrightBackend) withCDPSession(fn func(session playwright.CDPSession) error) error {
+	page := p.getCurrentPage()
+	if page == nil {
+		return ErrBrowserNotLaunched
+	}
+	session, err := p.context.NewCDPSession(page)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = session.Detach() }()
+	return fn(session)
+}
+
 // Helper methods
 
 func (p *PlaywrightBackend) getCurrentPage() playwright.Page {
@@ -807,6 +2294,24 @@ func (p *PlaywrightBackend) getCurrentPage() playwright.Page {
 	return p.pages[p.activeTab]
 }
 
+// getCurrentFrame returns the frame the current tab's selectors and scripts
+// run in: the one selected with SwitchFrame, else the main frame. A selected
+// frame that has been detached, for example because the page navigated,
+// drops back to the main frame.
+func (p *PlaywrightBackend) getCurrentFrame() playwright.Frame {
+	page := p.getCurrentPage()
+	if page == nil {
+		return nil
+	}
+	if frame, ok := p.frames[page]; ok {
+		if !frame.IsDetached() {
+			return frame
+		}
+		delete(p.frames, page)
+	}
+	return page.MainFrame()
+}
+
 func (p *PlaywrightBackend) resolveSelector(selector string) string {
 	ref := ParseRef(selector)
 	if ref == "" {
@@ -1,8 +1,12 @@
 package agentbrowser
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+
+	"github.com/playwright-community/playwright-go"
 )
 
 // ParseCommand parses a JSON command into the appropriate typed command.
@@ -119,6 +123,50 @@ func ParseCommand(data []byte) (Command, error) {
 		var c CookiesClearCommand
 		err = json.Unmarshal(data, &c)
 		cmd = &c
+	case "cookies_export":
+		var c CookiesExportCommand
+		err = json.Unmarshal(data, &c)
+		cmd = &c
+	case "cookies_import":
+		var c CookiesImportCommand
+		err = json.Unmarshal(data, &c)
+		cmd = &c
+	case "fetch":
+		var c FetchCommand
+		err = json.Unmarshal(data, &c)
+		cmd = &c
+	case "crawl":
+		var c CrawlCommand
+		err = json.Unmarshal(data, &c)
+		cmd = &c
+	case "paginate":
+		var c PaginateCommand
+		err = json.Unmarshal(data, &c)
+		cmd = &c
+	case "polite":
+		var c PoliteCommand
+		err = json.Unmarshal(data, &c)
+		cmd = &c
+	case "sitemap":
+		var c SitemapCommand
+		err = json.Unmarshal(data, &c)
+		cmd = &c
+	case "clear_data":
+		var c ClearDataCommand
+		err = json.Unmarshal(data, &c)
+		cmd = &c
+	case "sw_list":
+		var c ServiceWorkersCommand
+		err = json.Unmarshal(data, &c)
+		cmd = &c
+	case "sw_unregister":
+		var c ServiceWorkersUnregisterCommand
+		err = json.Unmarshal(data, &c)
+		cmd = &c
+	case "sw_bypass":
+		var c ServiceWorkersBypassCommand
+		err = json.Unmarshal(data, &c)
+		cmd = &c
 	case "storage_get":
 		var c StorageGetCommand
 		err = json.Unmarshal(data, &c)
@@ -187,6 +235,14 @@ func ParseCommand(data []byte) (Command, error) {
 		var c ReloadCommand
 		err = json.Unmarshal(data, &c)
 		cmd = &c
+	case "history":
+		var c HistoryCommand
+		err = json.Unmarshal(data, &c)
+		cmd = &c
+	case "history_go":
+		var c HistoryGoCommand
+		err = json.Unmarshal(data, &c)
+		cmd = &c
 	case "url":
 		var c URLCommand
 		err = json.Unmarshal(data, &c)
@@ -223,6 +279,26 @@ func ParseCommand(data []byte) (Command, error) {
 		var c BoundingBoxCommand
 		err = json.Unmarshal(data, &c)
 		cmd = &c
+	case "describe":
+		var c DescribeCommand
+		err = json.Unmarshal(data, &c)
+		cmd = &c
+	case "text":
+		var c TextCommand
+		err = json.Unmarshal(data, &c)
+		cmd = &c
+	case "links":
+		var c LinksCommand
+		err = json.Unmarshal(data, &c)
+		cmd = &c
+	case "forms":
+		var c FormsCommand
+		err = json.Unmarshal(data, &c)
+		cmd = &c
+	case "fill_form":
+		var c FillFormCommand
+		err = json.Unmarshal(data, &c)
+		cmd = &c
 	case "press":
 		var c PressCommand
 		err = json.Unmarshal(data, &c)
@@ -451,6 +527,14 @@ func ParseCommand(data []byte) (Command, error) {
 		var c ScreencastStopCommand
 		err = json.Unmarshal(data, &c)
 		cmd = &c
+	case "record_start":
+		var c RecordStartCommand
+		err = json.Unmarshal(data, &c)
+		cmd = &c
+	case "record_stop":
+		var c RecordStopCommand
+		err = json.Unmarshal(data, &c)
+		cmd = &c
 	case "input_mouse":
 		var c InputMouseCommand
 		err = json.Unmarshal(data, &c)
@@ -467,6 +551,66 @@ func ParseCommand(data []byte) (Command, error) {
 		var c ClipboardCommand
 		err = json.Unmarshal(data, &c)
 		cmd = &c
+	case "session_info":
+		var c SessionInfoCommand
+		err = json.Unmarshal(data, &c)
+		cmd = &c
+	case "config_reload":
+		var c ConfigReloadCommand
+		err = json.Unmarshal(data, &c)
+		cmd = &c
+	case "subscribe":
+		var c SubscribeCommand
+		err = json.Unmarshal(data, &c)
+		cmd = &c
+	case "resume":
+		var c ResumeCommand
+		err = json.Unmarshal(data, &c)
+		cmd = &c
+	case "unsubscribe":
+		var c UnsubscribeCommand
+		err = json.Unmarshal(data, &c)
+		cmd = &c
+	case "watch_start":
+		var c WatchStartCommand
+		err = json.Unmarshal(data, &c)
+		cmd = &c
+	case "watch_stop":
+		var c WatchStopCommand
+		err = json.Unmarshal(data, &c)
+		cmd = &c
+	case "perf":
+		var c PerfCommand
+		err = json.Unmarshal(data, &c)
+		cmd = &c
+	case "stats":
+		var c StatsCommand
+		err = json.Unmarshal(data, &c)
+		cmd = &c
+	case "audit":
+		var c AuditCommand
+		err = json.Unmarshal(data, &c)
+		cmd = &c
+	case "challenge":
+		var c ChallengeCommand
+		err = json.Unmarshal(data, &c)
+		cmd = &c
+	case "ua_rotation_start":
+		var c UARotationStartCommand
+		err = json.Unmarshal(data, &c)
+		cmd = &c
+	case "ua_rotation_stop":
+		var c UARotationStopCommand
+		err = json.Unmarshal(data, &c)
+		cmd = &c
+	case "ua_rotate":
+		var c UARotateCommand
+		err = json.Unmarshal(data, &c)
+		cmd = &c
+	case "login":
+		var c LoginCommand
+		err = json.Unmarshal(data, &c)
+		cmd = &c
 	default:
 		return nil, fmt.Errorf("unknown action: %s", base.Action)
 	}
@@ -504,6 +648,48 @@ func ErrorResponse(id string, errMsg string) Response {
 	}
 }
 
+// Failure codes of error responses.
+const (
+	CodeNotFound = "not_found" // the element or ref was not found
+	CodeTimeout  = "timeout"   // a wait or the command timeout ran out
+	CodeBrowser  = "browser"   // the browser failed to launch, crashed or was closed
+	CodeInvalid  = "invalid"   // the command could not be parsed
+)
+
+var (
+	// ErrElementNotFound is wrapped by the errors of element lookups that
+	// matched nothing.
+	ErrElementNotFound = errors.New("element not found")
+
+	// ErrBrowserNotLaunched is returned by browser calls made before a
+	// launch or after a close.
+	ErrBrowserNotLaunched = errors.New("browser not launched")
+)
+
+// failureCode classifies an error by its kind of failure, returning "" for
+// other failures. Backends wrap the errors it looks for, so the code does
+// not depend on the wording of a message.
+func failureCode(err error) string {
+	switch {
+	case errors.Is(err, ErrBrowserNotLaunched), errors.Is(err, playwright.ErrTargetClosed),
+		errors.Is(err, context.Canceled): // chromedp cancels a closed or crashed browser's contexts
+		return CodeBrowser
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, playwright.ErrTimeout):
+		return CodeTimeout
+	case errors.Is(err, ErrElementNotFound):
+		return CodeNotFound
+	}
+	return ""
+}
+
+// errorResponse creates an error response with the code of err's kind of
+// failure.
+func errorResponse(id string, err error) Response {
+	resp := ErrorResponse(id, err.Error())
+	resp.Code = failureCode(err)
+	return resp
+}
+
 // SerializeResponse serializes a response to JSON.
 func SerializeResponse(resp Response) ([]byte, error) {
 	return json.Marshal(resp)
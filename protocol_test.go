@@ -29,6 +29,34 @@ func TestParseCommand_Navigation(t *testing.T) {
 				}
 			},
 		},
+		{
+			name:    "navigate with POST body",
+			input:   `{"id":"1","action":"navigate","url":"https://example.com/search","method":"POST","body":"q=go","headers":{"Content-Type":"application/x-www-form-urlencoded"}}`,
+			wantErr: false,
+			check: func(t *testing.T, cmd agentbrowser.Command) {
+				navCmd, ok := cmd.(*agentbrowser.NavigateCommand)
+				if !ok {
+					t.Fatal("expected NavigateCommand")
+				}
+				if navCmd.Method != "POST" || navCmd.Body != "q=go" || navCmd.Headers["Content-Type"] != "application/x-www-form-urlencoded" {
+					t.Errorf("unexpected request: %+v", navCmd)
+				}
+			},
+		},
+		{
+			name:    "navigate with referrer",
+			input:   `{"id":"1","action":"navigate","url":"https://example.com/article","referrer":"https://news.example.com/"}`,
+			wantErr: false,
+			check: func(t *testing.T, cmd agentbrowser.Command) {
+				navCmd, ok := cmd.(*agentbrowser.NavigateCommand)
+				if !ok {
+					t.Fatal("expected NavigateCommand")
+				}
+				if navCmd.Referrer != "https://news.example.com/" {
+					t.Errorf("Referrer = %q, want https://news.example.com/", navCmd.Referrer)
+				}
+			},
+		},
 		{
 			name:    "back command",
 			input:   `{"id":"1","action":"back"}`,
@@ -235,6 +263,25 @@ func TestParseCommand_Snapshot(t *testing.T) {
 	}
 }
 
+// TestParseCommand_Describe tests describe command parsing
+func TestParseCommand_Describe(t *testing.T) {
+	cmd, err := agentbrowser.ParseCommand([]byte(`{"id":"1","action":"describe","selector":"@e3","maxText":80}`))
+	if err != nil {
+		t.Fatalf("ParseCommand() error = %v", err)
+	}
+
+	describeCmd, ok := cmd.(*agentbrowser.DescribeCommand)
+	if !ok {
+		t.Fatal("expected DescribeCommand")
+	}
+	if describeCmd.Selector != "@e3" {
+		t.Errorf("expected selector @e3, got %s", describeCmd.Selector)
+	}
+	if describeCmd.MaxText != 80 {
+		t.Errorf("expected maxText 80, got %d", describeCmd.MaxText)
+	}
+}
+
 // TestParseCommand_Tabs tests tab command parsing
 func TestParseCommand_Tabs(t *testing.T) {
 	tests := []struct {
@@ -298,6 +345,79 @@ func TestParseCommand_Tabs(t *testing.T) {
 	}
 }
 
+// TestParseCommand_Watch tests watch and subscription command parsing
+func TestParseCommand_Watch(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		check func(*testing.T, agentbrowser.Command)
+	}{
+		{
+			name:  "watch_start",
+			input: `{"id":"1","action":"watch_start","selector":"#results","debounce":500}`,
+			check: func(t *testing.T, cmd agentbrowser.Command) {
+				c, ok := cmd.(*agentbrowser.WatchStartCommand)
+				if !ok {
+					t.Fatal("expected WatchStartCommand")
+				}
+				if c.Selector != "#results" || c.Debounce != 500 {
+					t.Errorf("got selector %q debounce %d", c.Selector, c.Debounce)
+				}
+			},
+		},
+		{
+			name:  "watch_stop",
+			input: `{"id":"1","action":"watch_stop"}`,
+			check: func(t *testing.T, cmd agentbrowser.Command) {
+				if _, ok := cmd.(*agentbrowser.WatchStopCommand); !ok {
+					t.Fatal("expected WatchStopCommand")
+				}
+			},
+		},
+		{
+			name:  "subscribe",
+			input: `{"id":"1","action":"subscribe","events":["watch"]}`,
+			check: func(t *testing.T, cmd agentbrowser.Command) {
+				c, ok := cmd.(*agentbrowser.SubscribeCommand)
+				if !ok {
+					t.Fatal("expected SubscribeCommand")
+				}
+				if len(c.Events) != 1 || c.Events[0] != "watch" {
+					t.Errorf("expected events [watch], got %v", c.Events)
+				}
+			},
+		},
+		{
+			name:  "unsubscribe",
+			input: `{"id":"1","action":"unsubscribe"}`,
+			check: func(t *testing.T, cmd agentbrowser.Command) {
+				if _, ok := cmd.(*agentbrowser.UnsubscribeCommand); !ok {
+					t.Fatal("expected UnsubscribeCommand")
+				}
+			},
+		},
+		{
+			name:  "session_info",
+			input: `{"id":"1","action":"session_info"}`,
+			check: func(t *testing.T, cmd agentbrowser.Command) {
+				if _, ok := cmd.(*agentbrowser.SessionInfoCommand); !ok {
+					t.Fatal("expected SessionInfoCommand")
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd, err := agentbrowser.ParseCommand([]byte(tt.input))
+			if err != nil {
+				t.Fatalf("ParseCommand() error = %v", err)
+			}
+			tt.check(t, cmd)
+		})
+	}
+}
+
 // TestSerializeResponse tests response serialization
 func TestSerializeResponse(t *testing.T) {
 	tests := []struct {
@@ -0,0 +1,172 @@
+package agentbrowser
+
+import (
+	"cmp"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RecordOptions configures a video recording of the active tab.
+type RecordOptions struct {
+	Path   string // a .webm or .mp4 file, or a directory to save a timestamped one in
+	Format string // webm (default) or mp4, for a directory
+}
+
+// RecordData is the response for record_start and record_stop.
+type RecordData struct {
+	Recording bool    `json:"recording"`
+	Path      string  `json:"path"`
+	Frames    int     `json:"frames,omitempty"`
+	Duration  float64 `json:"duration,omitempty"` // seconds
+}
+
+// videoRecorder is the recording in progress.
+type videoRecorder struct {
+	lock   sync.Mutex
+	path   string
+	frames string // directory of the frames, removed once encoded
+}
+
+// recordCodecs are the ffmpeg arguments that encode each video format.
+var recordCodecs = map[string][]string{
+	"webm": {"-c:v", "libvpx", "-b:v", "2M", "-deadline", "realtime", "-cpu-used", "8"},
+	"mp4":  {"-c:v", "libx264", "-preset", "veryfast", "-movflags", "+faststart"},
+}
+
+// ffmpegPath returns the ffmpeg to encode recordings with:
+// AGENT_BROWSER_FFMPEG, else the one on the PATH.
+func ffmpegPath() (string, error) {
+	path, err := exec.LookPath(cmp.Or(os.Getenv("AGENT_BROWSER_FFMPEG"), "ffmpeg"))
+	if err != nil {
+		return "", fmt.Errorf("recording a video needs ffmpeg: install it or set AGENT_BROWSER_FFMPEG")
+	}
+	return path, nil
+}
+
+// StartRecording records a video of the active tab. Both backends record
+// the frames the page paints, as a screencast does, so a recording can start
+// and stop at any point of the session; StopRecording encodes them with
+// ffmpeg, keeping the time between frames. Playwright's own RecordVideo is
+// not used: it only records contexts created with it, and a new context
+// would lose the tabs' page state.
+func (m *BrowserManager) StartRecording(opts RecordOptions) (*RecordData, error) {
+	if opts.Path == "" {
+		return nil, fmt.Errorf("a file or directory to save the video in is required")
+	}
+	if _, err := ffmpegPath(); err != nil {
+		return nil, err
+	}
+	path, err := filepath.Abs(opts.Path)
+	if err != nil {
+		return nil, err
+	}
+	ext := strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), ".")
+	if _, ok := recordCodecs[ext]; ok {
+		if opts.Format != "" && opts.Format != ext {
+			return nil, fmt.Errorf("%s is not a %s file", path, opts.Format)
+		}
+	} else {
+		format := cmp.Or(opts.Format, "webm")
+		if _, ok := recordCodecs[format]; !ok {
+			return nil, fmt.Errorf("unknown video format %q (expected webm or mp4)", format)
+		}
+		path = filepath.Join(path, fmt.Sprintf("recording-%s.%s", time.Now().Format("20060102-150405"), format))
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+
+	frames, err := os.MkdirTemp("", "agent-browser-recording-")
+	if err != nil {
+		return nil, err
+	}
+	v := &m.recording
+	v.lock.Lock()
+	defer v.lock.Unlock()
+	if v.path != "" {
+		os.RemoveAll(frames)
+		return nil, fmt.Errorf("already recording to %s", v.path)
+	}
+	if _, err := m.startScreencast(ScreencastOptions{Dir: frames}, true); err != nil {
+		os.RemoveAll(frames)
+		return nil, err
+	}
+	v.path, v.frames = path, frames
+	return &RecordData{Recording: true, Path: path}, nil
+}
+
+// StopRecording stops the recording and encodes its video.
+func (m *BrowserManager) StopRecording() (*RecordData, error) {
+	v := &m.recording
+	v.lock.Lock()
+	defer v.lock.Unlock()
+	if v.path == "" {
+		return nil, fmt.Errorf("recording not started")
+	}
+	path, frames := v.path, v.frames
+	v.path, v.frames = "", ""
+	defer os.RemoveAll(frames)
+
+	screencast, err := m.stopScreencast(true)
+	if err != nil {
+		return nil, err
+	}
+	end := float64(time.Now().UnixMicro()) / 1e6
+	m.screencast.lock.Lock()
+	times := m.screencast.times
+	m.screencast.lock.Unlock()
+	if screencast.Frames == 0 || len(times) != screencast.Frames {
+		return nil, fmt.Errorf("no frames were recorded")
+	}
+
+	list := filepath.Join(frames, "frames.txt")
+	if err := os.WriteFile(list, []byte(concatList(times, end, ".jpg")), 0644); err != nil {
+		return nil, err
+	}
+	if err := encodeVideo(list, path); err != nil {
+		return nil, err
+	}
+	return &RecordData{Path: path, Frames: screencast.Frames, Duration: end - times[0]}, nil
+}
+
+// concatList returns an ffmpeg concat list showing frame n, named as the
+// screencast names it, from times[n-1] until the next frame, the last one
+// until end.
+func concatList(times []float64, end float64, ext string) string {
+	var b strings.Builder
+	b.WriteString("ffconcat version 1.0\n")
+	for i, at := range times {
+		next := end
+		if i+1 < len(times) {
+			next = times[i+1]
+		}
+		fmt.Fprintf(&b, "file 'frame-%06d%s'\nduration %.3f\n", i+1, ext, max(next-at, 0.001))
+	}
+	// The concat demuxer only honors the duration of a file followed by another
+	fmt.Fprintf(&b, "file 'frame-%06d%s'\n", len(times), ext)
+	return b.String()
+}
+
+// encodeVideo encodes the frames of a concat list into a video whose
+// format follows its extension, at a constant 25 frames a second.
+func encodeVideo(list, path string) error {
+	ffmpeg, err := ffmpegPath()
+	if err != nil {
+		return err
+	}
+	format := strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), ".")
+	args := []string{"-y", "-loglevel", "error", "-f", "concat", "-safe", "0", "-i", list,
+		// Even sizes, as yuv420p needs them
+		"-vf", "scale=trunc(iw/2)*2:trunc(ih/2)*2,format=yuv420p", "-r", "25"}
+	args = append(args, recordCodecs[format]...)
+	out, err := exec.Command(ffmpeg, append(args, path)...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("encoding the video: %v: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package agentbrowser_test
+
+import (
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	agentbrowser "github.com/cpunion/agent-browser-go"
+)
+
+// fakeFFmpeg writes its arguments and the concat list it is given to the
+// output file, the last argument.
+const fakeFFmpeg = `#!/bin/sh
+prev=""
+for arg; do
+	[ "$prev" = "-i" ] && list="$arg"
+	prev="$arg"
+done
+{ echo "$@"; cat "$list"; } > "$prev"
+`
+
+// TestRecording tests that a recording encodes the screencast frames with
+// the time between them
+func TestRecording(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake ffmpeg is a shell script")
+	}
+	ffmpeg := filepath.Join(t.TempDir(), "ffmpeg")
+	if err := os.WriteFile(ffmpeg, []byte(fakeFFmpeg), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("AGENT_BROWSER_FFMPEG", ffmpeg)
+
+	image := base64.StdEncoding.EncodeToString([]byte("jpeg"))
+	frame := func(at float64) agentbrowser.ScreencastFrame {
+		return agentbrowser.ScreencastFrame{Data: image, Metadata: agentbrowser.ScreencastMetadata{Timestamp: at}}
+	}
+	var screencastStopped bool
+	m := agentbrowser.NewBrowserManagerForTest(screencastBackend([]agentbrowser.ScreencastFrame{frame(100), frame(100.5)}, new(agentbrowser.ScreencastOptions), &screencastStopped))
+	var events []agentbrowser.Event
+	m.SetEventHandler(func(ev agentbrowser.Event) { events = append(events, ev) })
+
+	dir := filepath.Join(t.TempDir(), "videos")
+	started, err := m.StartRecording(agentbrowser.RecordOptions{Path: dir, Format: "mp4"})
+	if err != nil {
+		t.Fatalf("StartRecording() error = %v", err)
+	}
+	if !started.Recording || filepath.Dir(started.Path) != dir || filepath.Ext(started.Path) != ".mp4" {
+		t.Errorf("StartRecording() = %+v, want an mp4 in %s", started, dir)
+	}
+	if _, err := m.StartScreencast(agentbrowser.ScreencastOptions{}); err == nil {
+		t.Error("StartScreencast() while recording expected an error")
+	}
+	if _, err := m.StopScreencast(); err == nil {
+		t.Error("StopScreencast() while recording expected an error")
+	}
+	if len(events) != 0 {
+		t.Errorf("events = %+v, want none while recording", events)
+	}
+
+	stopped, err := m.StopRecording()
+	if err != nil {
+		t.Fatalf("StopRecording() error = %v", err)
+	}
+	if stopped.Recording || stopped.Path != started.Path || stopped.Frames != 2 || !screencastStopped {
+		t.Errorf("StopRecording() = %+v, want the 2 frames saved to %s", stopped, started.Path)
+	}
+	video, err := os.ReadFile(stopped.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"libx264", "file 'frame-000001.jpg'\nduration 0.500\n", "file 'frame-000002.jpg'\n"} {
+		if !strings.Contains(string(video), want) {
+			t.Errorf("ffmpeg was given:\n%s\nwant %q", video, want)
+		}
+	}
+
+	if _, err := m.StopRecording(); err == nil {
+		t.Error("StopRecording() while stopped expected an error")
+	}
+}
+
+// TestRecordingOptions tests that a recording needs ffmpeg and a known
+// video format
+func TestRecordingOptions(t *testing.T) {
+	m := agentbrowser.NewBrowserManagerForTest(screencastBackend(nil, new(agentbrowser.ScreencastOptions), new(bool)))
+	t.Setenv("AGENT_BROWSER_FFMPEG", filepath.Join(t.TempDir(), "missing-ffmpeg"))
+	if _, err := m.StartRecording(agentbrowser.RecordOptions{Path: t.TempDir()}); err == nil || !strings.Contains(err.Error(), "ffmpeg") {
+		t.Errorf("StartRecording() without ffmpeg error = %v, want ffmpeg is needed", err)
+	}
+
+	t.Setenv("AGENT_BROWSER_FFMPEG", os.Args[0]) // any executable
+	for _, opts := range []agentbrowser.RecordOptions{
+		{},
+		{Path: t.TempDir(), Format: "avi"},
+		{Path: filepath.Join(t.TempDir(), "run.webm"), Format: "mp4"},
+	} {
+		if _, err := m.StartRecording(opts); err == nil {
+			t.Errorf("StartRecording(%+v) expected an error", opts)
+		}
+	}
+}
@@ -0,0 +1,188 @@
+package agentbrowser
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+const (
+	// minRefMatchScore is the lowest score reported as a ref candidate.
+	minRefMatchScore = 0.5
+	// retargetMinScore is the score a candidate needs to replace a missing ref.
+	retargetMinScore = 0.9
+	// maxRefCandidates bounds the candidates reported for a missing ref.
+	maxRefCandidates = 5
+)
+
+// RefNotFoundError is returned when a selector names a ref that is not in
+// the current snapshot. When the ref came from an earlier snapshot, Role
+// and Name describe the element it pointed to and Candidates lists similar
+// elements in the current snapshot.
+type RefNotFoundError struct {
+	Ref        string
+	Role       string
+	Name       string
+	Candidates []RefCandidate
+}
+
+// Unwrap makes a missing ref an ErrElementNotFound.
+func (e *RefNotFoundError) Unwrap() error {
+	return ErrElementNotFound
+}
+
+func (e *RefNotFoundError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "ref @%s not found in current snapshot", e.Ref)
+	if e.Role != "" {
+		fmt.Fprintf(&b, " (was %s %q)", e.Role, e.Name)
+	}
+	if len(e.Candidates) == 0 {
+		b.WriteString(". Run 'snapshot' to get fresh refs.")
+		return b.String()
+	}
+	b.WriteString(". Closest matches:")
+	for _, c := range e.Candidates {
+		fmt.Fprintf(&b, " @%s %s %q;", c.Ref, c.Role, c.Name)
+	}
+	return strings.TrimSuffix(b.String(), ";")
+}
+
+// SetRefRetarget controls what happens when a selector names a ref that is
+// not in the current snapshot. When enabled, a single strong match for the
+// element the ref used to point to is used in its place; otherwise a
+// RefNotFoundError listing the candidates is returned.
+func (m *BrowserManager) SetRefRetarget(enabled bool) {
+	m.refLock.Lock()
+	defer m.refLock.Unlock()
+	m.retargetRefs = enabled
+}
+
+// rememberRefs keeps the refs of the snapshot about to be replaced, so refs
+// handed out earlier can still be matched after the page changes.
+func (m *BrowserManager) rememberRefs(refs RefMap) {
+	if len(refs) == 0 {
+		return
+	}
+	m.refLock.Lock()
+	defer m.refLock.Unlock()
+	m.prevRefs = refs
+}
+
+// resolveRef checks that a ref selector exists in the current snapshot.
+// Non-ref selectors are returned unchanged.
+func (m *BrowserManager) resolveRef(selector string) (string, error) {
+	ref := ParseRef(selector)
+	if ref == "" {
+		return selector, nil
+	}
+
+	current := m.backend.GetRefMap()
+	if _, ok := current[ref]; ok {
+		return selector, nil
+	}
+
+	m.refLock.Lock()
+	prev, known := m.prevRefs[ref]
+	retarget := m.retargetRefs
+	m.refLock.Unlock()
+
+	refErr := &RefNotFoundError{Ref: ref}
+	if !known {
+		return "", refErr
+	}
+
+	refErr.Role, refErr.Name = prev.Role, prev.Name
+	candidates := rankRefs(current, prev.Role+" "+prev.Name, minRefMatchScore)
+	refErr.Candidates = candidates
+	if len(refErr.Candidates) > maxRefCandidates {
+		refErr.Candidates = refErr.Candidates[:maxRefCandidates]
+	}
+
+	if !retarget || len(candidates) == 0 || candidates[0].Score < retargetMinScore {
+		return "", refErr
+	}
+
+	// Equally good matches are told apart by their position among
+	// same-named elements; anything still ambiguous is left to the caller.
+	var best []RefCandidate
+	for _, c := range candidates {
+		if c.Score == candidates[0].Score {
+			best = append(best, c)
+		}
+	}
+	if len(best) > 1 {
+		var sameNth []RefCandidate
+		for _, c := range best {
+			if current[c.Ref].Nth == prev.Nth {
+				sameNth = append(sameNth, c)
+			}
+		}
+		best = sameNth
+	}
+	if len(best) != 1 {
+		return "", refErr
+	}
+
+	Logger().Info("ref retargeted", "ref", "@"+ref, "to", "@"+best[0].Ref, "role", best[0].Role, "name", best[0].Name)
+	return "@" + best[0].Ref, nil
+}
+
+// resolveOptionalRef is resolveRef for selectors that may be empty.
+func (m *BrowserManager) resolveOptionalRef(selector string) (string, error) {
+	if selector == "" {
+		return "", nil
+	}
+	return m.resolveRef(selector)
+}
+
+// refWant describes an element read from the page whose ref is wanted: the
+// roles the snapshot may give it and the names it may have there.
+type refWant struct {
+	Roles        []string
+	Names        []string
+	AllowUnnamed bool // may match a ref without a name, as unlabelled fields have
+}
+
+// minTruncatedRefName is the length from which a snapshot name may have
+// been cut short.
+const minTruncatedRefName = 40
+
+// matchRefs returns the ref of each wanted element, "" where none matches.
+// Elements are matched in document order to the first unused ref of their
+// role with one of their names; snapshot names may be cut short, so a long
+// name also matches the start of one. Elements still unmatched then take
+// the first unused unnamed ref of their role, if they allow it.
+func matchRefs(refs RefMap, wants []refWant) []string {
+	ordered := make([]string, 0, len(refs))
+	for ref := range refs {
+		ordered = append(ordered, ref)
+	}
+	slices.SortFunc(ordered, func(a, b string) int { return refOrder(a) - refOrder(b) })
+
+	matched := make([]string, len(wants))
+	used := make(map[string]bool)
+	find := func(want refWant, accept func(name string) bool) string {
+		for _, ref := range ordered {
+			info := refs[ref]
+			if !used[ref] && slices.Contains(want.Roles, info.Role) && accept(strings.Join(strings.Fields(info.Name), " ")) {
+				used[ref] = true
+				return ref
+			}
+		}
+		return ""
+	}
+	for i, want := range wants {
+		matched[i] = find(want, func(name string) bool {
+			return name != "" && slices.ContainsFunc(want.Names, func(n string) bool {
+				return n == name || len(name) >= minTruncatedRefName && strings.HasPrefix(n, name)
+			})
+		})
+	}
+	for i, want := range wants {
+		if matched[i] == "" && want.AllowUnnamed {
+			matched[i] = find(want, func(name string) bool { return name == "" })
+		}
+	}
+	return matched
+}
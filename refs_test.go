@@ -0,0 +1,119 @@
+package agentbrowser_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	agentbrowser "github.com/cpunion/agent-browser-go"
+)
+
+// newStaleRefManager returns a manager whose snapshot was retaken after the
+// page changed, and the selector its last click was on.
+func newStaleRefManager(t *testing.T) (*agentbrowser.BrowserManager, *string) {
+	t.Helper()
+	refs := agentbrowser.RefMap{
+		"e1": {Role: "textbox", Name: "Email"},
+		"e2": {Role: "textbox", Name: "Password"},
+		"e3": {Role: "button", Name: "Sign in"},
+	}
+	next := agentbrowser.RefMap{
+		"e1": {Role: "button", Name: "Sign in"},
+		"e2": {Role: "link", Name: "Forgot password"},
+	}
+	clicked := new(string)
+	m := agentbrowser.NewBrowserManagerForTest(&fakeBackend{
+		getRefMap: func() agentbrowser.RefMap { return refs },
+		getSnapshot: func(agentbrowser.SnapshotOptions) (*agentbrowser.EnhancedSnapshot, error) {
+			refs = next
+			return &agentbrowser.EnhancedSnapshot{Refs: refs}, nil
+		},
+		click: func(selector string) error {
+			*clicked = selector
+			return nil
+		},
+	})
+	if _, err := m.GetSnapshot(agentbrowser.SnapshotOptions{}); err != nil {
+		t.Fatalf("GetSnapshot() error = %v", err)
+	}
+	return m, clicked
+}
+
+// TestResolveRef_Suggestions tests that stale refs report candidates
+func TestResolveRef_Suggestions(t *testing.T) {
+	m, clicked := newStaleRefManager(t)
+
+	err := m.Click("@e3")
+	var refErr *agentbrowser.RefNotFoundError
+	if !errors.As(err, &refErr) {
+		t.Fatalf("expected RefNotFoundError, got %v", err)
+	}
+	if refErr.Role != "button" || refErr.Name != "Sign in" {
+		t.Errorf("expected stale ref to describe button \"Sign in\", got %s %q", refErr.Role, refErr.Name)
+	}
+	if len(refErr.Candidates) == 0 || refErr.Candidates[0].Ref != "e1" {
+		t.Errorf("expected e1 as first candidate, got %v", refErr.Candidates)
+	}
+	if *clicked != "" {
+		t.Errorf("expected no click, got %q", *clicked)
+	}
+
+	if err := m.Click("@e9"); !errors.As(err, &refErr) || len(refErr.Candidates) != 0 {
+		t.Errorf("expected RefNotFoundError without candidates for unknown ref, got %v", err)
+	}
+}
+
+// TestResolveRef_Retarget tests auto-retargeting stale refs
+func TestResolveRef_Retarget(t *testing.T) {
+	m, clicked := newStaleRefManager(t)
+	m.SetRefRetarget(true)
+
+	if err := m.Click("@e3"); err != nil {
+		t.Fatalf("Click() error = %v", err)
+	}
+	if *clicked != "@e1" {
+		t.Errorf("expected click retargeted to @e1, got %q", *clicked)
+	}
+
+	if err := m.Click("#submit"); err != nil {
+		t.Fatalf("Click() error = %v", err)
+	}
+	if *clicked != "#submit" {
+		t.Errorf("expected CSS selector passed through, got %q", *clicked)
+	}
+}
+
+// TestElementError_KeepsRefs tests that the snapshot describing a failed
+// action on a ref does not replace the refs handed out earlier
+func TestElementError_KeepsRefs(t *testing.T) {
+	refs := agentbrowser.RefMap{"e1": {Role: "textbox", Name: "Email"}}
+	m := agentbrowser.NewBrowserManagerForTest(&fakeBackend{
+		getRefMap: func() agentbrowser.RefMap { return refs },
+		getSnapshot: func(opts agentbrowser.SnapshotOptions) (*agentbrowser.EnhancedSnapshot, error) {
+			snapshot := &agentbrowser.EnhancedSnapshot{
+				Tree: `- textbox "Email address" [ref=e1]`,
+				Refs: agentbrowser.RefMap{"e1": {Role: "textbox", Name: "Email address"}},
+			}
+			if !agentbrowser.KeepsRefs(opts) {
+				refs = snapshot.Refs
+			}
+			return snapshot, nil
+		},
+		typeText: func(selector, text string, delay int) error {
+			return fmt.Errorf("%w: %s", agentbrowser.ErrElementNotFound, selector)
+		},
+		url:   func() (string, error) { return "https://example.com/login", nil },
+		title: func() (string, error) { return "Log in", nil },
+	})
+
+	resp := agentbrowser.ExecuteCommand(&agentbrowser.TypeCommand{
+		BaseCommand: agentbrowser.BaseCommand{ID: "1", Action: "type"},
+		Selector:    "@e1", Text: "ada@example.com",
+	}, m)
+	if resp.Success || len(resp.Data) == 0 {
+		t.Fatalf("response = %+v, want an error with the page excerpt", resp)
+	}
+	if got := m.GetRefMap()["e1"]; got.Name != "Email" {
+		t.Errorf("ref e1 = %+v after the failed action, want the Email textbox kept", got)
+	}
+}
@@ -0,0 +1,134 @@
+package agentbrowser
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ConfigReload reports what applying a session config changed.
+type ConfigReload struct {
+	Changed []string `json:"changed"`           // settings now in effect
+	Pending []string `json:"pending,omitempty"` // changes that need a browser restart
+}
+
+// sessionSettings are the session config settings in effect.
+type sessionSettings struct {
+	lock         sync.Mutex
+	headers      map[string]string // from the config
+	extraHeaders map[string]string // set with SetExtraHeaders, sent over the config's
+	initScripts  []string          // sources of the config scripts added to the browser
+	addedScripts []string          // sources of the scripts added with AddInitScript
+	rateLimit    time.Duration
+	lastNav      time.Time
+	timeout      int // ms
+	testIDAttr   string
+}
+
+// ApplySessionConfig brings session defaults into effect on the browser,
+// launched or not, without restarting it. Init scripts cannot be removed
+// from a running browser, so removed scripts are reported as pending.
+func (m *BrowserManager) ApplySessionConfig(cfg SessionConfig) (*ConfigReload, error) {
+	scripts := make([]string, 0, len(cfg.InitScripts))
+	for _, path := range cfg.InitScripts {
+		data, err := os.ReadFile(expandHome(path))
+		if err != nil {
+			return nil, fmt.Errorf("init script: %w", err)
+		}
+		scripts = append(scripts, string(data))
+	}
+
+	s := &m.session
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	reload := &ConfigReload{Changed: []string{}}
+	headers := mergeHeaders(nil, cfg.Headers)
+	if !maps.Equal(headers, s.headers) {
+		if err := m.backend.SetExtraHeaders(mergeHeaders(headers, s.extraHeaders)); err != nil {
+			return nil, fmt.Errorf("headers: %w", err)
+		}
+		s.headers = headers
+		reload.Changed = append(reload.Changed, fmt.Sprintf("headers (%d)", len(headers)))
+	}
+
+	added := 0
+	for _, script := range scripts {
+		if slices.Contains(s.initScripts, script) || slices.Contains(s.addedScripts, script) {
+			continue
+		}
+		if err := m.backend.AddInitScript(script); err != nil {
+			return nil, fmt.Errorf("init script: %w", err)
+		}
+		s.initScripts = append(s.initScripts, script)
+		added++
+	}
+	if added > 0 {
+		reload.Changed = append(reload.Changed, fmt.Sprintf("init scripts (%d added)", added))
+	}
+	removed := 0
+	for _, script := range s.initScripts {
+		if !slices.Contains(scripts, script) {
+			removed++
+		}
+	}
+	if removed > 0 {
+		reload.Pending = append(reload.Pending, fmt.Sprintf("init scripts (%d removed, still run until the browser restarts)", removed))
+	}
+
+	rateLimit := time.Duration(cfg.RateLimit) * time.Millisecond
+	if rateLimit != s.rateLimit {
+		s.rateLimit = rateLimit
+		reload.Changed = append(reload.Changed, fmt.Sprintf("rate limit (%v)", rateLimit))
+	}
+
+	if cfg.Timeout != s.timeout {
+		s.timeout = cfg.Timeout
+		reload.Changed = append(reload.Changed, fmt.Sprintf("timeout (%dms)", cmp.Or(s.timeout, defaultWaitTimeout)))
+	}
+
+	if cfg.TestIDAttribute != s.testIDAttr {
+		s.testIDAttr = cfg.TestIDAttribute
+		reload.Changed = append(reload.Changed, fmt.Sprintf("test id attribute (%s)", cmp.Or(s.testIDAttr, defaultTestIDAttribute)))
+	}
+	return reload, nil
+}
+
+// defaultTestIDAttribute is the attribute test ids are read from unless
+// the session config names another, such as data-test or data-cy.
+const defaultTestIDAttribute = "data-testid"
+
+// testIDAttribute returns the attribute the session's test ids are in.
+func (m *BrowserManager) testIDAttribute() string {
+	m.session.lock.Lock()
+	defer m.session.lock.Unlock()
+	return cmp.Or(m.session.testIDAttr, defaultTestIDAttribute)
+}
+
+// waitRateLimit holds a navigation back until the session's rate limit
+// allows it.
+func (m *BrowserManager) waitRateLimit() {
+	s := &m.session
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if wait := s.rateLimit - time.Since(s.lastNav); wait > 0 {
+		time.Sleep(wait)
+	}
+	s.lastNav = time.Now()
+}
+
+// expandHome expands a leading ~/ to the home directory.
+func expandHome(path string) string {
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, rest)
+		}
+	}
+	return path
+}
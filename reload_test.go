@@ -0,0 +1,125 @@
+package agentbrowser_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	agentbrowser "github.com/cpunion/agent-browser-go"
+)
+
+// appliedSettings records the session settings applied to a fake backend.
+type appliedSettings struct {
+	headers     map[string]string
+	initScripts []string
+	navigations []time.Time
+}
+
+// backend returns a fake backend recording its settings in a.
+func (a *appliedSettings) backend() *fakeBackend {
+	return &fakeBackend{
+		setExtraHeaders: func(headers map[string]string) error {
+			a.headers = headers
+			return nil
+		},
+		addInitScript: func(script string) error {
+			a.initScripts = append(a.initScripts, script)
+			return nil
+		},
+		navigate: func(url string, waitUntil string) (string, string, error) {
+			a.navigations = append(a.navigations, time.Now())
+			return url, "", nil
+		},
+	}
+}
+
+// TestConfigSession tests merging the shared and per-session defaults
+func TestConfigSession(t *testing.T) {
+	cfg := &agentbrowser.Config{Sessions: map[string]agentbrowser.SessionConfig{
+		"*":    {Headers: map[string]string{"X-A": "1", "X-B": "1"}, InitScripts: []string{"all.js"}, RateLimit: 500},
+		"work": {Headers: map[string]string{"X-B": "2"}, InitScripts: []string{"work.js"}},
+		"fast": {RateLimit: 100, TestIDAttribute: "data-cy"},
+	}}
+
+	work := cfg.Session("work")
+	if want := map[string]string{"x-a": "1", "x-b": "2"}; !reflect.DeepEqual(work.Headers, want) {
+		t.Errorf("work headers = %v, want %v", work.Headers, want)
+	}
+	if want := []string{"all.js", "work.js"}; !reflect.DeepEqual(work.InitScripts, want) {
+		t.Errorf("work init scripts = %v, want %v", work.InitScripts, want)
+	}
+	if work.RateLimit != 500 {
+		t.Errorf("work rate limit = %d, want 500", work.RateLimit)
+	}
+	if fast := cfg.Session("fast"); fast.RateLimit != 100 || fast.TestIDAttribute != "data-cy" {
+		t.Errorf("fast = %+v, want rate limit 100 and test id attribute data-cy", fast)
+	}
+	if other := cfg.Session("other"); !reflect.DeepEqual(other.InitScripts, []string{"all.js"}) {
+		t.Errorf("other = %+v, want the shared defaults", other)
+	}
+}
+
+// TestApplySessionConfig tests applying and reapplying session defaults
+func TestApplySessionConfig(t *testing.T) {
+	dir := t.TempDir()
+	a := filepath.Join(dir, "a.js")
+	b := filepath.Join(dir, "b.js")
+	for path, src := range map[string]string{a: "window.a = 1", b: "window.b = 1"} {
+		if err := os.WriteFile(path, []byte(src), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	applied := &appliedSettings{}
+	m := agentbrowser.NewBrowserManagerForTest(applied.backend())
+
+	reload, err := m.ApplySessionConfig(agentbrowser.SessionConfig{
+		Headers:     map[string]string{"X-Request-ID": "agent"},
+		InitScripts: []string{a},
+	})
+	if err != nil {
+		t.Fatalf("ApplySessionConfig() error = %v", err)
+	}
+	if want := []string{"headers (1)", "init scripts (1 added)"}; !reflect.DeepEqual(reload.Changed, want) || len(reload.Pending) != 0 {
+		t.Errorf("first reload = %+v, want changed %v", reload, want)
+	}
+	if !reflect.DeepEqual(applied.headers, map[string]string{"x-request-id": "agent"}) {
+		t.Errorf("backend headers = %v", applied.headers)
+	}
+
+	// Unchanged settings are not reapplied; a swapped script is added and
+	// the removed one reported
+	reload, err = m.ApplySessionConfig(agentbrowser.SessionConfig{
+		Headers:     map[string]string{"X-Request-ID": "agent"},
+		InitScripts: []string{b},
+		RateLimit:   50,
+	})
+	if err != nil {
+		t.Fatalf("ApplySessionConfig() error = %v", err)
+	}
+	if want := []string{"init scripts (1 added)", "rate limit (50ms)"}; !reflect.DeepEqual(reload.Changed, want) {
+		t.Errorf("second reload changed = %v, want %v", reload.Changed, want)
+	}
+	if len(reload.Pending) != 1 {
+		t.Errorf("second reload pending = %v, want the removed script", reload.Pending)
+	}
+	if want := []string{"window.a = 1", "window.b = 1"}; !reflect.DeepEqual(applied.initScripts, want) {
+		t.Errorf("backend init scripts = %v, want %v", applied.initScripts, want)
+	}
+
+	for i := 0; i < 3; i++ {
+		if _, _, err := m.Navigate("https://example.com/", "load"); err != nil {
+			t.Fatalf("Navigate() error = %v", err)
+		}
+	}
+	for i := 1; i < len(applied.navigations); i++ {
+		if gap := applied.navigations[i].Sub(applied.navigations[i-1]); gap < 50*time.Millisecond {
+			t.Errorf("navigation %d came %v after the previous one, want >= 50ms", i, gap)
+		}
+	}
+
+	if _, err := m.ApplySessionConfig(agentbrowser.SessionConfig{InitScripts: []string{filepath.Join(dir, "missing.js")}}); err == nil {
+		t.Error("ApplySessionConfig() with a missing script expected an error")
+	}
+}
@@ -0,0 +1,118 @@
+package agentbrowser
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// trackedEntry is a request in the log, with the key its backend reports
+// later events under.
+type trackedEntry struct {
+	key     any
+	started time.Time
+	req     TrackedRequest
+}
+
+// requestTracker records network requests for a backend. Backends embed it
+// and feed it from their network listeners, keyed by whatever identifies a
+// request in their events.
+type requestTracker struct {
+	requestsLock sync.Mutex
+	requests     []*trackedEntry
+	pending      map[any]*trackedEntry // requests not yet finished or failed
+}
+
+func (t *requestTracker) requestStarted(key any, req TrackedRequest) {
+	t.requestsLock.Lock()
+	defer t.requestsLock.Unlock()
+
+	entry := &trackedEntry{key: key, started: time.Now(), req: req}
+	if entry.req.Timestamp == 0 {
+		entry.req.Timestamp = entry.started.UnixMilli()
+	}
+	entry.req.ResourceType = strings.ToLower(entry.req.ResourceType)
+	t.requests = append(t.requests, entry)
+	if len(t.requests) > maxTrackedEvents {
+		for _, dropped := range t.requests[:len(t.requests)-maxTrackedEvents] {
+			if t.pending[dropped.key] == dropped {
+				delete(t.pending, dropped.key)
+			}
+		}
+		t.requests = t.requests[len(t.requests)-maxTrackedEvents:]
+	}
+	if t.pending == nil {
+		t.pending = make(map[any]*trackedEntry)
+	}
+	t.pending[key] = entry
+}
+
+func (t *requestTracker) requestResponded(key any, status int) {
+	t.requestsLock.Lock()
+	defer t.requestsLock.Unlock()
+
+	if entry, ok := t.pending[key]; ok {
+		entry.req.Status = status
+		entry.req.ResponseTime = time.Since(entry.started).Milliseconds()
+	}
+}
+
+// requestFinished ends a request; failure is empty when it succeeded.
+func (t *requestTracker) requestFinished(key any, failure string) {
+	t.requestsLock.Lock()
+	defer t.requestsLock.Unlock()
+
+	if entry, ok := t.pending[key]; ok {
+		entry.req.Duration = time.Since(entry.started).Milliseconds()
+		entry.req.Failure = failure
+		delete(t.pending, key)
+	}
+}
+
+// Requests returns the recorded requests, oldest first.
+func (t *requestTracker) Requests() []TrackedRequest {
+	t.requestsLock.Lock()
+	defer t.requestsLock.Unlock()
+
+	reqs := make([]TrackedRequest, len(t.requests))
+	for i, entry := range t.requests {
+		reqs[i] = entry.req
+	}
+	return reqs
+}
+
+// ClearRequests empties the request log. Requests still in flight are
+// forgotten too.
+func (t *requestTracker) ClearRequests() {
+	t.requestsLock.Lock()
+	defer t.requestsLock.Unlock()
+
+	t.requests = nil
+	t.pending = nil
+}
+
+// filterRequests keeps the requests whose resource type is filter, such as
+// xhr, fetch or document, or whose URL contains it.
+func filterRequests(reqs []TrackedRequest, filter string) []TrackedRequest {
+	if filter == "" {
+		return reqs
+	}
+	var matched []TrackedRequest
+	for _, req := range reqs {
+		if strings.EqualFold(req.ResourceType, filter) || strings.Contains(req.URL, filter) {
+			matched = append(matched, req)
+		}
+	}
+	return matched
+}
+
+// Requests returns the network requests recorded since launch, or since
+// the log was last cleared, that match filter; empty matches all.
+func (m *BrowserManager) Requests(filter string) []TrackedRequest {
+	return filterRequests(m.backend.Requests(), filter)
+}
+
+// ClearRequests empties the request log.
+func (m *BrowserManager) ClearRequests() {
+	m.backend.ClearRequests()
+}
@@ -0,0 +1,115 @@
+package agentbrowser_test
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	agentbrowser "github.com/cpunion/agent-browser-go"
+)
+
+// TestRequestTracker tests that request events update the logged requests
+func TestRequestTracker(t *testing.T) {
+	var tracker agentbrowser.RequestTracker
+
+	tracker.Started(1, agentbrowser.TrackedRequest{URL: "https://example.com/", Method: "GET", ResourceType: "Document"})
+	tracker.Started(2, agentbrowser.TrackedRequest{URL: "https://example.com/api", Method: "POST", ResourceType: "XHR"})
+	tracker.Started(3, agentbrowser.TrackedRequest{URL: "https://ads.example.net/t.js", Method: "GET", ResourceType: "Script"})
+	tracker.Responded(1, 200)
+	tracker.Finished(1, "")
+	tracker.Responded(2, 201)
+	tracker.Finished(3, "net::ERR_BLOCKED_BY_CLIENT")
+	tracker.Responded(99, 500) // unknown requests are ignored
+
+	reqs := tracker.Requests()
+	if len(reqs) != 3 {
+		t.Fatalf("Requests() returned %d requests, want 3", len(reqs))
+	}
+	for i, want := range []struct {
+		resourceType string
+		status       int
+		failure      string
+	}{
+		{"document", 200, ""},
+		{"xhr", 201, ""},
+		{"script", 0, "net::ERR_BLOCKED_BY_CLIENT"},
+	} {
+		got := reqs[i]
+		if got.ResourceType != want.resourceType || got.Status != want.status || got.Failure != want.failure {
+			t.Errorf("request %d = %+v, want type %q, status %d, failure %q", i, got, want.resourceType, want.status, want.failure)
+		}
+		if got.Timestamp == 0 {
+			t.Errorf("request %d has no timestamp", i)
+		}
+	}
+
+	// A finished request no longer takes events
+	tracker.Responded(1, 304)
+	if reqs := tracker.Requests(); reqs[0].Status != 200 {
+		t.Errorf("finished request status = %d, want 200", reqs[0].Status)
+	}
+
+	tracker.ClearRequests()
+	if reqs := tracker.Requests(); len(reqs) != 0 {
+		t.Errorf("Requests() after ClearRequests() = %v, want none", reqs)
+	}
+}
+
+// TestRequestTrackerBounded tests that the log keeps the newest requests
+func TestRequestTrackerBounded(t *testing.T) {
+	var tracker agentbrowser.RequestTracker
+
+	for i := 0; i < 1010; i++ {
+		tracker.Started(i, agentbrowser.TrackedRequest{URL: fmt.Sprintf("https://example.com/%d", i)})
+	}
+	tracker.Responded(0, 200) // dropped from the log
+
+	reqs := tracker.Requests()
+	if len(reqs) != 1000 {
+		t.Fatalf("Requests() returned %d requests, want 1000", len(reqs))
+	}
+	if reqs[0].URL != "https://example.com/10" {
+		t.Errorf("oldest request = %s, want https://example.com/10", reqs[0].URL)
+	}
+	for _, req := range reqs {
+		if req.Status != 0 {
+			t.Errorf("request %s status = %d, want 0", req.URL, req.Status)
+		}
+	}
+}
+
+// TestRequestsFilter tests filtering by resource type and URL
+func TestRequestsFilter(t *testing.T) {
+	requests := []agentbrowser.TrackedRequest{
+		{URL: "https://example.com/", ResourceType: "document"},
+		{URL: "https://example.com/api/items", ResourceType: "xhr"},
+		{URL: "https://example.com/api/user", ResourceType: "fetch"},
+		{URL: "https://cdn.example.net/app.js", ResourceType: "script"},
+	}
+	m := agentbrowser.NewBrowserManagerForTest(&fakeBackend{
+		requests: func() []agentbrowser.TrackedRequest { return requests },
+	})
+
+	urls := func(reqs []agentbrowser.TrackedRequest) []string {
+		var urls []string
+		for _, req := range reqs {
+			urls = append(urls, req.URL)
+		}
+		return urls
+	}
+	tests := []struct {
+		filter string
+		want   []string
+	}{
+		{"", []string{"https://example.com/", "https://example.com/api/items", "https://example.com/api/user", "https://cdn.example.net/app.js"}},
+		{"xhr", []string{"https://example.com/api/items"}},
+		{"XHR", []string{"https://example.com/api/items"}},
+		{"/api/", []string{"https://example.com/api/items", "https://example.com/api/user"}},
+		{"image", nil},
+	}
+	for _, tt := range tests {
+		if got := urls(m.Requests(tt.filter)); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Requests(%q) = %v, want %v", tt.filter, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,240 @@
+package agentbrowser
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RobotsUserAgent is the product token matched against robots.txt groups.
+const RobotsUserAgent = "agent-browser-go"
+
+// robotsMaxSize is how much of a robots.txt is read, the minimum RFC 9309
+// asks parsers to handle.
+const robotsMaxSize = 500 << 10
+
+// webClient fetches robots.txt and sitemaps outside the browser.
+var webClient = &http.Client{Timeout: 10 * time.Second}
+
+// Robots is a parsed robots.txt (RFC 9309).
+type Robots struct {
+	groups      []robotsGroup
+	Sitemaps    []string // Sitemap URLs listed in the file
+	disallowAll bool     // the file could not be fetched
+}
+
+type robotsGroup struct {
+	agents     []string
+	rules      []robotsRule
+	crawlDelay time.Duration
+}
+
+type robotsRule struct {
+	allow   bool
+	pattern string
+	re      *regexp.Regexp
+}
+
+// ParseRobots parses a robots.txt file. Unknown lines are ignored.
+func ParseRobots(data []byte) *Robots {
+	r := &Robots{}
+	var group *robotsGroup
+	inAgents := false // consecutive user-agent lines share a group
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		switch key {
+		case "user-agent":
+			if !inAgents {
+				r.groups = append(r.groups, robotsGroup{})
+				group = &r.groups[len(r.groups)-1]
+			}
+			group.agents = append(group.agents, strings.ToLower(value))
+			inAgents = true
+			continue
+		case "allow", "disallow":
+			if group != nil && value != "" {
+				group.rules = append(group.rules, robotsRule{
+					allow:   key == "allow",
+					pattern: value,
+					re:      robotsPattern(value),
+				})
+			}
+		case "crawl-delay":
+			if group != nil {
+				if secs, err := strconv.ParseFloat(value, 64); err == nil && secs > 0 {
+					group.crawlDelay = time.Duration(secs * float64(time.Second))
+				}
+			}
+		case "sitemap":
+			if value != "" {
+				r.Sitemaps = append(r.Sitemaps, value)
+			}
+		}
+		inAgents = false
+	}
+	return r
+}
+
+// robotsPattern compiles a path pattern: * matches any characters and a
+// trailing $ anchors the end.
+func robotsPattern(pattern string) *regexp.Regexp {
+	anchored := strings.HasSuffix(pattern, "$")
+	pattern = strings.TrimSuffix(pattern, "$")
+	expr := "^" + strings.ReplaceAll(regexp.QuoteMeta(pattern), `\*`, ".*")
+	if anchored {
+		expr += "$"
+	}
+	return regexp.MustCompile(expr)
+}
+
+// rulesFor returns the rules and crawl delay of the groups matching agent,
+// or of the * groups when none match.
+func (r *Robots) rulesFor(agent string) ([]robotsRule, time.Duration) {
+	agent = strings.ToLower(agent)
+	for _, want := range []string{agent, "*"} {
+		var rules []robotsRule
+		var delay time.Duration
+		found := false
+		for _, g := range r.groups {
+			for _, a := range g.agents {
+				if a == want {
+					found = true
+					rules = append(rules, g.rules...)
+					delay = max(delay, g.crawlDelay)
+					break
+				}
+			}
+		}
+		if found {
+			return rules, delay
+		}
+	}
+	return nil, 0
+}
+
+// Allowed reports whether agent may fetch the path (with its query). The
+// longest matching rule decides; Allow wins a tie.
+func (r *Robots) Allowed(agent, path string) bool {
+	if r.disallowAll {
+		return false
+	}
+	if path == "/robots.txt" {
+		return true
+	}
+	rules, _ := r.rulesFor(agent)
+	allowed, matched := true, -1
+	for _, rule := range rules {
+		if !rule.re.MatchString(path) {
+			continue
+		}
+		if n := len(rule.pattern); n > matched || (n == matched && rule.allow) {
+			allowed, matched = rule.allow, n
+		}
+	}
+	return allowed
+}
+
+// CrawlDelay returns the Crawl-delay for agent, 0 when none is given.
+func (r *Robots) CrawlDelay(agent string) time.Duration {
+	_, delay := r.rulesFor(agent)
+	return delay
+}
+
+// fetchRobots fetches an origin's robots.txt. A missing file allows
+// everything; an unreachable one disallows everything, as RFC 9309 asks.
+func fetchRobots(origin string) *Robots {
+	resp, err := webClient.Get(origin + "/robots.txt")
+	if err != nil {
+		return &Robots{disallowAll: true}
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		data, err := io.ReadAll(io.LimitReader(resp.Body, robotsMaxSize))
+		if err != nil {
+			return &Robots{disallowAll: true}
+		}
+		return ParseRobots(data)
+	case resp.StatusCode >= 400 && resp.StatusCode < 500:
+		return &Robots{}
+	default:
+		return &Robots{disallowAll: true}
+	}
+}
+
+// robotsFor returns the robots.txt of an origin, fetched once per manager.
+func (m *BrowserManager) robotsFor(origin string) *Robots {
+	m.robotsLock.Lock()
+	r, ok := m.robots[origin]
+	m.robotsLock.Unlock()
+	if ok {
+		return r
+	}
+
+	r = fetchRobots(origin)
+	m.robotsLock.Lock()
+	if m.robots == nil {
+		m.robots = make(map[string]*Robots)
+	}
+	m.robots[origin] = r
+	m.robotsLock.Unlock()
+	return r
+}
+
+// RobotsAllowed reports whether robots.txt allows fetching a URL. URLs
+// other than http(s) are always allowed.
+func (m *BrowserManager) RobotsAllowed(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return true
+	}
+	path := u.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	if u.RawQuery != "" {
+		path += "?" + u.RawQuery
+	}
+	return m.robotsFor(u.Scheme+"://"+u.Host).Allowed(RobotsUserAgent, path)
+}
+
+// SetPolite turns polite mode on or off. In polite mode navigation to URLs
+// that robots.txt disallows fails, and crawls skip them and honor
+// Crawl-delay.
+func (m *BrowserManager) SetPolite(polite bool) {
+	m.robotsLock.Lock()
+	defer m.robotsLock.Unlock()
+	m.polite = polite
+}
+
+// Polite reports whether polite mode is on.
+func (m *BrowserManager) Polite() bool {
+	m.robotsLock.Lock()
+	defer m.robotsLock.Unlock()
+	return m.polite
+}
+
+// checkRobots fails navigation to a URL robots.txt disallows, in polite mode.
+func (m *BrowserManager) checkRobots(rawURL string) error {
+	if m.Polite() && !m.RobotsAllowed(rawURL) {
+		return fmt.Errorf("%s is disallowed by robots.txt (polite mode)", rawURL)
+	}
+	return nil
+}
@@ -0,0 +1,154 @@
+package agentbrowser_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	agentbrowser "github.com/cpunion/agent-browser-go"
+)
+
+// TestRobots tests robots.txt group selection, rule precedence and patterns
+func TestRobots(t *testing.T) {
+	robots := agentbrowser.ParseRobots([]byte(`
+# comment
+User-agent: *
+Disallow: /private
+Allow: /private/public
+Disallow: /*.pdf$
+Crawl-delay: 2
+
+User-agent: other-bot
+User-agent: Agent-Browser-Go
+Disallow: /
+Allow: /docs
+Crawl-delay: 0.5
+
+Sitemap: https://a.com/sitemap.xml
+`))
+
+	tests := []struct {
+		agent string
+		path  string
+		want  bool
+	}{
+		{"somebot", "/", true},
+		{"somebot", "/private/x", false},
+		{"somebot", "/private/public/x", true},
+		{"somebot", "/a/file.pdf", false},
+		{"somebot", "/a/file.pdf?x=1", true},
+		{"agent-browser-go", "/docs/intro", true},
+		{"agent-browser-go", "/private/public", false},
+		{"agent-browser-go", "/robots.txt", true},
+	}
+	for _, tt := range tests {
+		if got := robots.Allowed(tt.agent, tt.path); got != tt.want {
+			t.Errorf("Allowed(%q, %q) = %v, want %v", tt.agent, tt.path, got, tt.want)
+		}
+	}
+
+	if got := robots.CrawlDelay("somebot"); got != 2*time.Second {
+		t.Errorf("CrawlDelay(somebot) = %v, want 2s", got)
+	}
+	if got := robots.CrawlDelay(agentbrowser.RobotsUserAgent); got != 500*time.Millisecond {
+		t.Errorf("CrawlDelay(%s) = %v, want 500ms", agentbrowser.RobotsUserAgent, got)
+	}
+	if want := []string{"https://a.com/sitemap.xml"}; !reflect.DeepEqual(robots.Sitemaps, want) {
+		t.Errorf("Sitemaps = %q, want %q", robots.Sitemaps, want)
+	}
+}
+
+// TestPoliteCrawl tests that polite crawls skip pages robots.txt disallows
+func TestPoliteCrawl(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/robots.txt" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte("User-agent: *\nDisallow: /private\n"))
+	}))
+	defer srv.Close()
+
+	site := map[string][]string{
+		srv.URL + "/":        {srv.URL + "/public", srv.URL + "/private"},
+		srv.URL + "/public":  nil,
+		srv.URL + "/private": nil,
+	}
+	var visited []string
+	m := agentbrowser.NewBrowserManagerForTest(siteBackend(site, &visited))
+	pages, err := m.Crawl(agentbrowser.CrawlOptions{StartURL: srv.URL + "/", Depth: 1, Polite: true})
+	if err != nil {
+		t.Fatalf("Crawl() error = %v", err)
+	}
+	if want := []string{srv.URL + "/", srv.URL + "/public"}; !reflect.DeepEqual(visited, want) {
+		t.Errorf("visited %q, want %q", visited, want)
+	}
+	if len(pages) != 3 || pages[2].Error != "disallowed by robots.txt" {
+		t.Errorf("Crawl() = %+v, want the private page recorded as disallowed", pages)
+	}
+
+	m.SetPolite(true)
+	if _, _, err := m.Navigate(srv.URL+"/private", ""); err == nil {
+		t.Error("Navigate(disallowed) in polite mode error = nil, want error")
+	}
+	m.SetPolite(false)
+	if _, _, err := m.Navigate(srv.URL+"/private", ""); err != nil {
+		t.Errorf("Navigate(disallowed) without polite mode error = %v", err)
+	}
+}
+
+// TestSitemap tests parsing sitemaps and following sitemap indexes
+func TestSitemap(t *testing.T) {
+	var gz bytes.Buffer
+	zw := gzip.NewWriter(&gz)
+	zw.Write([]byte("https://a.com/3\nnot a url\nhttps://a.com/1\n"))
+	zw.Close()
+
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+	mux.HandleFunc("/robots.txt", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("Sitemap: " + srv.URL + "/index.xml\n"))
+	})
+	mux.HandleFunc("/index.xml", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <sitemap><loc>` + srv.URL + `/pages.xml</loc></sitemap>
+  <sitemap><loc>` + srv.URL + `/more.txt.gz</loc></sitemap>
+  <sitemap><loc>` + srv.URL + `/missing.xml</loc></sitemap>
+</sitemapindex>`))
+	})
+	mux.HandleFunc("/pages.xml", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <url><loc> https://a.com/1 </loc><lastmod>2024-01-01</lastmod></url>
+  <url><loc>https://a.com/2</loc></url>
+</urlset>`))
+	})
+	mux.HandleFunc("/more.txt.gz", func(w http.ResponseWriter, r *http.Request) {
+		w.Write(gz.Bytes())
+	})
+
+	m := agentbrowser.NewBrowserManagerForTest(siteBackend(map[string][]string{srv.URL + "/": nil}, new([]string)))
+	if _, _, err := m.Navigate(srv.URL+"/", ""); err != nil {
+		t.Fatalf("Navigate() error = %v", err)
+	}
+	result, err := m.Sitemap("")
+	if err != nil {
+		t.Fatalf("Sitemap() error = %v", err)
+	}
+	want := &agentbrowser.SitemapResult{
+		Sitemaps: []string{srv.URL + "/index.xml", srv.URL + "/pages.xml", srv.URL + "/more.txt.gz"},
+		URLs:     []string{"https://a.com/1", "https://a.com/2", "https://a.com/3"},
+	}
+	if !reflect.DeepEqual(result, want) {
+		t.Errorf("Sitemap() = %+v, want %+v", result, want)
+	}
+
+	if _, err := m.Sitemap(srv.URL + "/missing.xml"); err == nil {
+		t.Error("Sitemap(missing) error = nil, want error")
+	}
+}
@@ -0,0 +1,222 @@
+package agentbrowser
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// ScreencastOptions configures a screencast of the active tab.
+type ScreencastOptions struct {
+	Format        string // jpeg (default) or png
+	Quality       int    // jpeg quality 0-100, default 80
+	MaxWidth      int    // maximum frame size in pixels, 0 for the viewport's
+	MaxHeight     int
+	EveryNthFrame int    // send every nth frame the page paints, default 1
+	Dir           string // directory frames are written to, empty to send them as events
+}
+
+// ScreencastData is the response for screencast_start and screencast_stop.
+type ScreencastData struct {
+	Screencasting bool   `json:"screencasting"`
+	Dir           string `json:"dir,omitempty"`
+	Frames        int    `json:"frames"` // frames received so far
+}
+
+// cdpScreencastFrame is a Page.screencastFrame event as both backends
+// receive it.
+type cdpScreencastFrame struct {
+	Data     string `json:"data"`
+	Metadata struct {
+		OffsetTop       float64 `json:"offsetTop"`
+		PageScaleFactor float64 `json:"pageScaleFactor"`
+		DeviceWidth     float64 `json:"deviceWidth"`
+		DeviceHeight    float64 `json:"deviceHeight"`
+		ScrollOffsetX   float64 `json:"scrollOffsetX"`
+		ScrollOffsetY   float64 `json:"scrollOffsetY"`
+		Timestamp       float64 `json:"timestamp"`
+	} `json:"metadata"`
+	SessionID int64 `json:"sessionId"` // acknowledged to receive the next frame
+}
+
+// frame converts the event to a ScreencastFrame.
+func (f *cdpScreencastFrame) frame() ScreencastFrame {
+	m := f.Metadata
+	return ScreencastFrame{
+		Data: f.Data,
+		Metadata: ScreencastMetadata{
+			OffsetTop:       int(m.OffsetTop),
+			PageScaleFactor: m.PageScaleFactor,
+			DeviceWidth:     int(m.DeviceWidth),
+			DeviceHeight:    int(m.DeviceHeight),
+			ScrollOffsetX:   int(m.ScrollOffsetX),
+			ScrollOffsetY:   int(m.ScrollOffsetY),
+			Timestamp:       m.Timestamp,
+		},
+	}
+}
+
+// screencastParams returns the Page.startScreencast parameters for opts.
+func screencastParams(opts ScreencastOptions) map[string]interface{} {
+	params := map[string]interface{}{"format": opts.Format, "everyNthFrame": opts.EveryNthFrame}
+	if opts.Format == "jpeg" {
+		params["quality"] = opts.Quality
+	}
+	if opts.MaxWidth > 0 {
+		params["maxWidth"] = opts.MaxWidth
+	}
+	if opts.MaxHeight > 0 {
+		params["maxHeight"] = opts.MaxHeight
+	}
+	return params
+}
+
+// screencastRecorder delivers the frames of the running screencast.
+type screencastRecorder struct {
+	lock   sync.Mutex
+	active bool
+	dir    string
+	ext    string
+	frames int
+	err    error // first frame that could not be written
+
+	// A recording keeps its frames to itself and notes when each was painted
+	recording bool
+	times     []float64 // seconds since the epoch
+}
+
+// StartScreencast streams frames of the active tab as the page paints them.
+// With opts.Dir each frame is written there as frame-000001.jpg and so on,
+// and the screencast event carries its path; otherwise the event carries
+// the base64 image.
+func (m *BrowserManager) StartScreencast(opts ScreencastOptions) (*ScreencastData, error) {
+	return m.startScreencast(opts, false)
+}
+
+// startScreencast starts a screencast, or the one behind a recording.
+func (m *BrowserManager) startScreencast(opts ScreencastOptions, recording bool) (*ScreencastData, error) {
+	switch opts.Format {
+	case "":
+		opts.Format = "jpeg"
+	case "jpeg", "png":
+	default:
+		return nil, fmt.Errorf("unknown screencast format %q (expected jpeg or png)", opts.Format)
+	}
+	if opts.Quality < 0 || opts.Quality > 100 {
+		return nil, fmt.Errorf("quality must be between 0 and 100")
+	}
+	if opts.Quality == 0 {
+		opts.Quality = 80
+	}
+	if opts.EveryNthFrame <= 0 {
+		opts.EveryNthFrame = 1
+	}
+	if opts.Dir != "" {
+		dir, err := filepath.Abs(opts.Dir)
+		if err != nil {
+			return nil, err
+		}
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, err
+		}
+		opts.Dir = dir
+	}
+
+	r := &m.screencast
+	r.lock.Lock()
+	if r.active {
+		r.lock.Unlock()
+		if r.recording {
+			return nil, fmt.Errorf("a recording is using the screencast; stop it with record stop")
+		}
+		return nil, fmt.Errorf("screencast already started")
+	}
+	r.active = true
+	r.dir, r.ext, r.frames, r.err = opts.Dir, map[string]string{"jpeg": ".jpg", "png": ".png"}[opts.Format], 0, nil
+	r.recording, r.times = recording, nil
+	r.lock.Unlock()
+
+	// Frames may arrive before the backend returns
+	if err := m.backend.StartScreencast(opts, m.screencastFrame); err != nil {
+		r.lock.Lock()
+		r.active = false
+		r.lock.Unlock()
+		return nil, err
+	}
+	return &ScreencastData{Screencasting: true, Dir: opts.Dir}, nil
+}
+
+// screencastFrame writes or sends one frame.
+func (m *BrowserManager) screencastFrame(frame ScreencastFrame) {
+	r := &m.screencast
+	r.lock.Lock()
+	r.frames++
+	dir, ext, n, recording := r.dir, r.ext, r.frames, r.recording
+	if recording {
+		at := frame.Metadata.Timestamp
+		if at <= 0 {
+			at = float64(time.Now().UnixMicro()) / 1e6
+		}
+		r.times = append(r.times, at)
+	}
+	r.lock.Unlock()
+
+	if dir != "" {
+		path := filepath.Join(dir, fmt.Sprintf("frame-%06d%s", n, ext))
+		data, err := base64.StdEncoding.DecodeString(frame.Data)
+		if err == nil {
+			err = os.WriteFile(path, data, 0644)
+		}
+		if err != nil {
+			r.lock.Lock()
+			if r.err == nil {
+				r.err = err
+			}
+			r.lock.Unlock()
+			return
+		}
+		frame.Data, frame.Path = "", path
+	}
+	if recording {
+		return
+	}
+	m.events.emit(EventScreencast, frame)
+}
+
+// StopScreencast stops the screencast and reports how many frames it
+// delivered. Failing to write a frame is reported here.
+func (m *BrowserManager) StopScreencast() (*ScreencastData, error) {
+	return m.stopScreencast(false)
+}
+
+// stopScreencast stops a screencast, or the one behind a recording.
+func (m *BrowserManager) stopScreencast(recording bool) (*ScreencastData, error) {
+	r := &m.screencast
+	r.lock.Lock()
+	active, wasRecording := r.active, r.recording
+	if active && wasRecording == recording {
+		r.active = false
+	}
+	r.lock.Unlock()
+	switch {
+	case recording && !(active && wasRecording):
+		return nil, fmt.Errorf("recording not started")
+	case !active:
+		return nil, fmt.Errorf("screencast not started")
+	case wasRecording && !recording:
+		return nil, fmt.Errorf("the screencast is recording a video; stop it with record stop")
+	}
+	if err := m.backend.StopScreencast(); err != nil {
+		return nil, err
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if r.err != nil {
+		return nil, fmt.Errorf("writing frames: %w", r.err)
+	}
+	return &ScreencastData{Dir: r.dir, Frames: r.frames}, nil
+}
@@ -0,0 +1,85 @@
+package agentbrowser_test
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	agentbrowser "github.com/cpunion/agent-browser-go"
+)
+
+// screencastBackend is a fake backend that sends frames once a screencast
+// starts, keeping its options in *opts, and sets *stopped once it stops.
+func screencastBackend(frames []agentbrowser.ScreencastFrame, opts *agentbrowser.ScreencastOptions, stopped *bool) *fakeBackend {
+	return &fakeBackend{
+		startScreencast: func(o agentbrowser.ScreencastOptions, onFrame func(agentbrowser.ScreencastFrame)) error {
+			*opts = o
+			for _, frame := range frames {
+				onFrame(frame)
+			}
+			return nil
+		},
+		stopScreencast: func() error {
+			*stopped = true
+			return nil
+		},
+		setEventHandler: func(func(agentbrowser.Event)) {},
+	}
+}
+
+// TestScreencastDir tests that frames are written to the directory and
+// announced by path
+func TestScreencastDir(t *testing.T) {
+	image := base64.StdEncoding.EncodeToString([]byte("jpeg"))
+	var opts agentbrowser.ScreencastOptions
+	var stopped bool
+	m := agentbrowser.NewBrowserManagerForTest(screencastBackend([]agentbrowser.ScreencastFrame{{Data: image}, {Data: image}}, &opts, &stopped))
+	var events []agentbrowser.Event
+	m.SetEventHandler(func(ev agentbrowser.Event) { events = append(events, ev) })
+
+	dir := filepath.Join(t.TempDir(), "frames")
+	if _, err := m.StartScreencast(agentbrowser.ScreencastOptions{Dir: dir}); err != nil {
+		t.Fatalf("StartScreencast() error = %v", err)
+	}
+	if opts.Format != "jpeg" || opts.Quality != 80 || opts.EveryNthFrame != 1 {
+		t.Errorf("backend options = %+v, want jpeg defaults", opts)
+	}
+	if _, err := m.StartScreencast(agentbrowser.ScreencastOptions{}); err == nil {
+		t.Error("StartScreencast() while running expected an error")
+	}
+
+	result, err := m.StopScreencast()
+	if err != nil {
+		t.Fatalf("StopScreencast() error = %v", err)
+	}
+	if result.Frames != 2 || result.Dir != dir || !stopped {
+		t.Errorf("StopScreencast() = %+v, want 2 frames in %s", result, dir)
+	}
+	second := filepath.Join(dir, "frame-000002.jpg")
+	if data, err := os.ReadFile(second); err != nil || string(data) != "jpeg" {
+		t.Errorf("frame file = %q, %v; want the decoded image", data, err)
+	}
+	if len(events) != 2 || events[1].Event != agentbrowser.EventScreencast {
+		t.Fatalf("events = %+v, want 2 screencast events", events)
+	}
+	var frame agentbrowser.ScreencastFrame
+	if err := json.Unmarshal(events[1].Data, &frame); err != nil || frame.Path != second || frame.Data != "" {
+		t.Errorf("event frame = %+v, want the path instead of the data", frame)
+	}
+
+	if _, err := m.StopScreencast(); err == nil {
+		t.Error("StopScreencast() while stopped expected an error")
+	}
+}
+
+// TestScreencastOptions tests validation of screencast options
+func TestScreencastOptions(t *testing.T) {
+	m := agentbrowser.NewBrowserManagerForTest(screencastBackend(nil, new(agentbrowser.ScreencastOptions), new(bool)))
+	for _, opts := range []agentbrowser.ScreencastOptions{{Format: "gif"}, {Quality: 101}} {
+		if _, err := m.StartScreencast(opts); err == nil {
+			t.Errorf("StartScreencast(%+v) expected an error", opts)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package agentbrowser
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// ScriptStep is one step of a batch script: the arguments of a CLI
+// command, or a protocol command written as JSON.
+type ScriptStep struct {
+	Source  string          // the step as written, for reports
+	Args    []string        // CLI command and arguments
+	Command json.RawMessage // protocol command; its id may be left out
+}
+
+// ParseScript parses a batch script. A JSON array holds one step per
+// element: a command line, an array of arguments or a protocol command
+// object. Any other script has one command line per line; blank lines and
+// lines starting with # are skipped.
+func ParseScript(data []byte) ([]ScriptStep, error) {
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+		return parseJSONScript(trimmed)
+	}
+
+	var steps []ScriptStep
+	for n, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		args, err := SplitCommandLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", n+1, err)
+		}
+		steps = append(steps, ScriptStep{Source: line, Args: args})
+	}
+	return steps, nil
+}
+
+// parseJSONScript parses a script written as a JSON array.
+func parseJSONScript(data []byte) ([]ScriptStep, error) {
+	var elems []json.RawMessage
+	if err := json.Unmarshal(data, &elems); err != nil {
+		return nil, fmt.Errorf("invalid script: %w", err)
+	}
+
+	steps := make([]ScriptStep, 0, len(elems))
+	for i, elem := range elems {
+		step := ScriptStep{Source: string(elem)}
+		var err error
+		switch elem[0] {
+		case '"':
+			var line string
+			if err = json.Unmarshal(elem, &line); err == nil {
+				step.Source = line
+				step.Args, err = SplitCommandLine(line)
+			}
+		case '[':
+			if err = json.Unmarshal(elem, &step.Args); err == nil {
+				step.Source = strings.Join(step.Args, " ")
+			}
+		case '{':
+			var base BaseCommand
+			if err = json.Unmarshal(elem, &base); err == nil && base.Action == "" {
+				err = fmt.Errorf("command missing action")
+			}
+			step.Command = elem
+		default:
+			err = fmt.Errorf("expected a command line, an argument array or a command object")
+		}
+		if err == nil && step.Command == nil && len(step.Args) == 0 {
+			err = fmt.Errorf("empty command")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("step %d: %w", i+1, err)
+		}
+		steps = append(steps, step)
+	}
+	return steps, nil
+}
+
+// SplitCommandLine splits a command line into arguments as a POSIX shell
+// would, without expansions: whitespace separates arguments, single quotes
+// keep text as is and double quotes keep it but for backslash escapes of
+// " and \.
+func SplitCommandLine(line string) ([]string, error) {
+	var args []string
+	var cur strings.Builder
+	inArg := false
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case c == ' ' || c == '\t':
+			if inArg {
+				args = append(args, cur.String())
+				cur.Reset()
+				inArg = false
+			}
+		case c == '\'':
+			end := strings.IndexByte(line[i+1:], '\'')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated ' in %s", line)
+			}
+			cur.WriteString(line[i+1 : i+1+end])
+			i += end + 1
+			inArg = true
+		case c == '"':
+			i++
+			for ; i < len(line) && line[i] != '"'; i++ {
+				if line[i] == '\\' && i+1 < len(line) && (line[i+1] == '"' || line[i+1] == '\\') {
+					i++
+				}
+				cur.WriteByte(line[i])
+			}
+			if i == len(line) {
+				return nil, fmt.Errorf("unterminated \" in %s", line)
+			}
+			inArg = true
+		case c == '\\' && i+1 < len(line):
+			i++
+			cur.WriteByte(line[i])
+			inArg = true
+		default:
+			cur.WriteByte(c)
+			inArg = true
+		}
+	}
+	if inArg {
+		args = append(args, cur.String())
+	}
+	return args, nil
+}
@@ -0,0 +1,90 @@
+package agentbrowser_test
+
+import (
+	"reflect"
+	"testing"
+
+	agentbrowser "github.com/cpunion/agent-browser-go"
+)
+
+// TestSplitCommandLine tests splitting script lines into arguments
+func TestSplitCommandLine(t *testing.T) {
+	tests := []struct {
+		line string
+		want []string
+	}{
+		{"click @e1", []string{"click", "@e1"}},
+		{`fill "#email"   'a b'`, []string{"fill", "#email", "a b"}},
+		{`type #q "say \"hi\" \\ now"`, []string{"type", "#q", `say "hi" \ now`}},
+		{`eval 'document.title = "x"'`, []string{"eval", `document.title = "x"`}},
+		{`fill #a ""`, []string{"fill", "#a", ""}},
+		{`press Control\ a`, []string{"press", "Control a"}},
+		{`open https://a.test/?q=1&b=2`, []string{"open", "https://a.test/?q=1&b=2"}},
+	}
+	for _, tt := range tests {
+		got, err := agentbrowser.SplitCommandLine(tt.line)
+		if err != nil || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("SplitCommandLine(%q) = %q, %v, want %q", tt.line, got, err, tt.want)
+		}
+	}
+	for _, line := range []string{`fill "#a`, `eval 'x`} {
+		if _, err := agentbrowser.SplitCommandLine(line); err == nil {
+			t.Errorf("SplitCommandLine(%q) expected error", line)
+		}
+	}
+}
+
+// TestParseScript tests reading text and JSON batch scripts
+func TestParseScript(t *testing.T) {
+	text := `# sign in
+open https://example.com/login
+
+fill #user "ada lovelace"
+click "button[type=submit]"
+`
+	steps, err := agentbrowser.ParseScript([]byte(text))
+	if err != nil {
+		t.Fatalf("ParseScript(text) error = %v", err)
+	}
+	want := [][]string{
+		{"open", "https://example.com/login"},
+		{"fill", "#user", "ada lovelace"},
+		{"click", "button[type=submit]"},
+	}
+	if len(steps) != len(want) {
+		t.Fatalf("ParseScript(text) = %d steps, want %d", len(steps), len(want))
+	}
+	for i, step := range steps {
+		if !reflect.DeepEqual(step.Args, want[i]) {
+			t.Errorf("step %d args = %q, want %q", i+1, step.Args, want[i])
+		}
+	}
+	if steps[1].Source != `fill #user "ada lovelace"` {
+		t.Errorf("step 2 source = %q, want the line as written", steps[1].Source)
+	}
+
+	script := `[
+		"open https://example.com",
+		["fill", "#q", "a \"quoted\" term"],
+		{"action": "press", "key": "Enter"}
+	]`
+	steps, err = agentbrowser.ParseScript([]byte(script))
+	if err != nil {
+		t.Fatalf("ParseScript(json) error = %v", err)
+	}
+	if len(steps) != 3 {
+		t.Fatalf("ParseScript(json) = %d steps, want 3", len(steps))
+	}
+	if !reflect.DeepEqual(steps[1].Args, []string{"fill", "#q", `a "quoted" term`}) {
+		t.Errorf("step 2 args = %q", steps[1].Args)
+	}
+	if steps[2].Args != nil || string(steps[2].Command) != `{"action": "press", "key": "Enter"}` {
+		t.Errorf("step 3 = %+v, want the command object", steps[2])
+	}
+
+	for _, bad := range []string{`[`, `[1]`, `[""]`, `[{"key": "Enter"}]`, "fill 'x\n"} {
+		if _, err := agentbrowser.ParseScript([]byte(bad)); err == nil {
+			t.Errorf("ParseScript(%q) expected error", bad)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package agentbrowser
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// ScrollToEndOptions configures ScrollToEnd.
+type ScrollToEndOptions struct {
+	MaxRounds     int    // stop after this many scrolls
+	UntilSelector string // stop once an element matches
+}
+
+// ScrollToEndResult summarizes what scrolling to the end loaded.
+type ScrollToEndResult struct {
+	Rounds int    `json:"rounds"` // scrolls performed
+	Items  int    `json:"items"`  // elements added to the page
+	Pixels int    `json:"pixels"` // growth of the document height
+	Height int    `json:"height"` // final document height
+	Reason string `json:"reason"` // end, selector or max-rounds
+}
+
+// defaultScrollRounds is the MaxRounds used when none is given.
+const defaultScrollRounds = 20
+
+// scrollRoundScript scrolls to the bottom of the page, then waits until no
+// elements are added and no requests start for 500ms (at most 10s), and
+// reports the document height before and after and how many elements were
+// added meanwhile.
+const scrollRoundScript = `(async () => {
+	const root = document.scrollingElement || document.documentElement;
+	let added = 0;
+	let last = performance.now();
+	const observer = new MutationObserver(records => {
+		for (const r of records) {
+			for (const n of r.addedNodes) if (n.nodeType === 1) added++;
+		}
+		last = performance.now();
+	});
+	observer.observe(document.body || root, { childList: true, subtree: true });
+	let requests = performance.getEntriesByType('resource').length;
+	const before = root.scrollHeight;
+
+	window.scrollTo(0, root.scrollHeight);
+	const start = performance.now();
+	while (performance.now() - start < 10000) {
+		await new Promise(resolve => setTimeout(resolve, 100));
+		const n = performance.getEntriesByType('resource').length;
+		if (n !== requests) {
+			requests = n;
+			last = performance.now();
+		}
+		if (performance.now() - last >= 500) break;
+	}
+	observer.disconnect();
+	window.scrollTo(0, root.scrollHeight);
+	return { before, after: root.scrollHeight, added };
+})()`
+
+// ScrollToEnd scrolls an infinite-scroll page until no new content appears,
+// an element matches opts.UntilSelector, or opts.MaxRounds scrolls were made.
+func (m *BrowserManager) ScrollToEnd(opts ScrollToEndOptions) (*ScrollToEndResult, error) {
+	maxRounds := opts.MaxRounds
+	if maxRounds <= 0 {
+		maxRounds = defaultScrollRounds
+	}
+
+	result := &ScrollToEndResult{Reason: "max-rounds"}
+	for result.Rounds < maxRounds {
+		if found, err := m.scrollTargetFound(opts.UntilSelector); err != nil {
+			return nil, err
+		} else if found {
+			result.Reason = "selector"
+			break
+		}
+
+		value, err := m.backend.Evaluate(scrollRoundScript)
+		if err != nil {
+			return nil, err
+		}
+		data, _ := json.Marshal(value)
+		var round struct {
+			Before int `json:"before"`
+			After  int `json:"after"`
+			Added  int `json:"added"`
+		}
+		if err := json.Unmarshal(data, &round); err != nil {
+			return nil, fmt.Errorf("unexpected scroll result: %w", err)
+		}
+		result.Rounds++
+		result.Items += round.Added
+		result.Pixels += max(round.After-round.Before, 0)
+		result.Height = round.After
+		if round.After <= round.Before && round.Added == 0 {
+			result.Reason = "end"
+			break
+		}
+	}
+	if result.Reason == "max-rounds" {
+		if found, err := m.scrollTargetFound(opts.UntilSelector); err == nil && found {
+			result.Reason = "selector"
+		}
+	}
+	return result, nil
+}
+
+// scrollTargetFound reports whether an element matches selector, which may
+// be empty.
+func (m *BrowserManager) scrollTargetFound(selector string) (bool, error) {
+	if selector == "" {
+		return false, nil
+	}
+	n, err := m.Count(selector)
+	return n > 0, err
+}
@@ -0,0 +1,87 @@
+package agentbrowser_test
+
+import (
+	"reflect"
+	"testing"
+
+	agentbrowser "github.com/cpunion/agent-browser-go"
+)
+
+// feed describes an infinite-scroll feed.
+type feed struct {
+	batches  []int // items loaded by each scroll
+	marker   int   // round after which the Count selector matches
+	selector string
+}
+
+// backend returns a fake backend scrolling through the feed.
+func (f feed) backend() *fakeBackend {
+	rounds := 0
+	return &fakeBackend{
+		evaluate: func(script string) (interface{}, error) {
+			height := 1000
+			for _, n := range f.batches[:min(rounds, len(f.batches))] {
+				height += n * 100
+			}
+			added := 0
+			if rounds < len(f.batches) {
+				added = f.batches[rounds]
+			}
+			rounds++
+			return map[string]interface{}{"before": height, "after": height + added*100, "added": added}, nil
+		},
+		count: func(selector string) (int, error) {
+			if selector == f.selector && f.marker > 0 && rounds >= f.marker {
+				return 1, nil
+			}
+			return 0, nil
+		},
+	}
+}
+
+// TestScrollToEnd tests the stop conditions of infinite scrolling
+func TestScrollToEnd(t *testing.T) {
+	tests := []struct {
+		name string
+		feed feed
+		opts agentbrowser.ScrollToEndOptions
+		want agentbrowser.ScrollToEndResult
+	}{
+		{
+			name: "end of feed",
+			feed: feed{batches: []int{10, 10, 5}},
+			want: agentbrowser.ScrollToEndResult{Rounds: 4, Items: 25, Pixels: 2500, Height: 3500, Reason: "end"},
+		},
+		{
+			name: "max rounds",
+			feed: feed{batches: []int{10, 10, 5}},
+			opts: agentbrowser.ScrollToEndOptions{MaxRounds: 2},
+			want: agentbrowser.ScrollToEndResult{Rounds: 2, Items: 20, Pixels: 2000, Height: 3000, Reason: "max-rounds"},
+		},
+		{
+			name: "until selector",
+			feed: feed{batches: []int{10, 10, 5}, selector: "#done", marker: 1},
+			opts: agentbrowser.ScrollToEndOptions{UntilSelector: "#done"},
+			want: agentbrowser.ScrollToEndResult{Rounds: 1, Items: 10, Pixels: 1000, Height: 2000, Reason: "selector"},
+		},
+		{
+			name: "selector never matches",
+			feed: feed{batches: []int{10}},
+			opts: agentbrowser.ScrollToEndOptions{UntilSelector: "#other"},
+			want: agentbrowser.ScrollToEndResult{Rounds: 2, Items: 10, Pixels: 1000, Height: 2000, Reason: "end"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := agentbrowser.NewBrowserManagerForTest(tt.feed.backend())
+			got, err := m.ScrollToEnd(tt.opts)
+			if err != nil {
+				t.Fatalf("ScrollToEnd() error = %v", err)
+			}
+			if !reflect.DeepEqual(*got, tt.want) {
+				t.Errorf("ScrollToEnd() = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package agentbrowser
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// selectOptionsScript selects the options of a <select> matching values by
+// value, label or index, deselects the others and fires input and change
+// events as a user's choice would. It returns an error message, or "".
+const selectOptionsScript = `(el, values, by) => {
+	if (!el) return "element not found";
+	if (el.tagName !== "SELECT") return "element is not a <select>";
+	const options = [...el.options];
+	const picked = [];
+	for (const v of values) {
+		const i = by === "index" ? Number(v) :
+			options.findIndex(o => (by === "label" ? o.label : o.value) === v);
+		if (!options[i]) return "no option with " + by + " " + JSON.stringify(v);
+		picked.push(options[i]);
+	}
+	if (picked.length > 1 && !el.multiple) return "cannot select several options in a single <select>";
+	for (const o of options) o.selected = picked.includes(o);
+	el.dispatchEvent(new Event("input", { bubbles: true }));
+	el.dispatchEvent(new Event("change", { bubbles: true }));
+	return "";
+}`
+
+// Select selects the options of a <select> element whose value, label or
+// index, as chosen by by (default value), is one of values. Other options
+// are deselected.
+func (m *BrowserManager) Select(selector string, values []string, by string) error {
+	if len(values) == 0 {
+		return fmt.Errorf("no option to select")
+	}
+	switch by {
+	case "":
+		by = "value"
+	case "value", "label":
+	case "index":
+		for _, v := range values {
+			if i, err := strconv.Atoi(v); err != nil || i < 0 {
+				return fmt.Errorf("invalid option index %q", v)
+			}
+		}
+	default:
+		return fmt.Errorf("unknown select mode %q (expected value, label or index)", by)
+	}
+
+	selector, err := m.resolveRef(selector)
+	if err != nil {
+		return err
+	}
+	return m.backend.Select(selector, values, by)
+}
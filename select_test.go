@@ -0,0 +1,47 @@
+package agentbrowser_test
+
+import (
+	"reflect"
+	"testing"
+
+	agentbrowser "github.com/cpunion/agent-browser-go"
+)
+
+// TestSelect tests the select mode default and argument checks
+func TestSelect(t *testing.T) {
+	var values []string
+	var by string
+	m := agentbrowser.NewBrowserManagerForTest(&fakeBackend{
+		selectOptions: func(selector string, v []string, b string) error {
+			values, by = v, b
+			return nil
+		},
+	})
+
+	if err := m.Select("#country", []string{"US"}, ""); err != nil {
+		t.Fatalf("Select() error = %v", err)
+	}
+	if by != "value" || !reflect.DeepEqual(values, []string{"US"}) {
+		t.Errorf("backend got %v by %q, want [US] by value", values, by)
+	}
+	if err := m.Select("#size", []string{"0", "2"}, "index"); err != nil {
+		t.Fatalf("Select(index) error = %v", err)
+	}
+	if by != "index" {
+		t.Errorf("backend got by %q, want index", by)
+	}
+
+	for _, tt := range []struct {
+		values []string
+		by     string
+	}{
+		{nil, "value"},
+		{[]string{"large"}, "index"},
+		{[]string{"-1"}, "index"},
+		{[]string{"US"}, "text"},
+	} {
+		if err := m.Select("#country", tt.values, tt.by); err == nil {
+			t.Errorf("Select(%v, %q) expected an error", tt.values, tt.by)
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package agentbrowser
+
+// selectAllScript is a JavaScript function (el) that focuses el and
+// selects all of its text: the value of an input or textarea, else the
+// element's contents with the Selection API, as Ctrl+A does. It returns
+// false when el is missing.
+const selectAllScript = `(el) => {
+	if (!el) return false;
+	el.focus();
+	if (typeof el.select === 'function' && 'value' in el) {
+		el.select();
+		return true;
+	}
+	const range = document.createRange();
+	range.selectNodeContents(el);
+	const selection = window.getSelection();
+	selection.removeAllRanges();
+	selection.addRange(range);
+	return true;
+}`
+
+// SelectAll focuses an element and selects all its text, so typing next
+// replaces the existing content.
+func (m *BrowserManager) SelectAll(selector string) error {
+	selector, err := m.resolveRef(selector)
+	if err != nil {
+		return err
+	}
+	return m.backend.SelectAll(selector)
+}
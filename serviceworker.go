@@ -0,0 +1,118 @@
+package agentbrowser
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// ServiceWorker is a service worker registration of the current origin.
+type ServiceWorker struct {
+	Scope     string `json:"scope"`
+	ScriptURL string `json:"scriptURL"`
+	State     string `json:"state"` // installing, installed, activating, activated or redundant
+}
+
+// blockServiceWorkersScript makes service worker registration fail, like
+// Playwright's serviceWorkers: "block" context option.
+const blockServiceWorkersScript = `(() => {
+	if (!window.ServiceWorkerContainer) return;
+	ServiceWorkerContainer.prototype.register = function () {
+		return Promise.reject(new DOMException('Service worker registration is blocked', 'SecurityError'));
+	};
+})();`
+
+// serviceWorkersScript lists the origin's registrations. Pages without
+// service worker support, such as about:blank, have none.
+const serviceWorkersScript = `(async () => {
+	if (!navigator.serviceWorker) return [];
+	const regs = await navigator.serviceWorker.getRegistrations();
+	return regs.map((reg) => {
+		const worker = reg.active || reg.waiting || reg.installing;
+		return { scope: reg.scope, scriptURL: worker ? worker.scriptURL : '', state: worker ? worker.state : '' };
+	});
+})()`
+
+// unregisterServiceWorkersScript unregisters the origin's registrations,
+// or only the one with the given scope, and returns how many it removed.
+const unregisterServiceWorkersScript = `(async (scope) => {
+	if (!navigator.serviceWorker) return 0;
+	let n = 0;
+	for (const reg of await navigator.serviceWorker.getRegistrations()) {
+		if (scope && reg.scope !== scope) continue;
+		if (await reg.unregister()) n++;
+	}
+	return n;
+})(%s)`
+
+// ServiceWorkers lists the service workers registered for the current
+// page's origin.
+func (m *BrowserManager) ServiceWorkers() ([]ServiceWorker, error) {
+	result, err := m.backend.Evaluate(serviceWorkersScript)
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(result)
+	if err != nil {
+		return nil, err
+	}
+	workers := []ServiceWorker{}
+	if err := json.Unmarshal(data, &workers); err != nil {
+		return nil, fmt.Errorf("unexpected service worker list: %w", err)
+	}
+	return workers, nil
+}
+
+// UnregisterServiceWorkers unregisters the current origin's service
+// workers, or only the one registered for scope, and returns the number
+// removed. Pages they control keep them until the next navigation.
+func (m *BrowserManager) UnregisterServiceWorkers(scope string) (int, error) {
+	arg, err := json.Marshal(scope)
+	if err != nil {
+		return 0, err
+	}
+	result, err := m.backend.Evaluate(fmt.Sprintf(unregisterServiceWorkersScript, arg))
+	if err != nil {
+		return 0, err
+	}
+	n, ok := result.(float64)
+	if !ok {
+		return 0, fmt.Errorf("unexpected unregister result: %v", result)
+	}
+	return int(n), nil
+}
+
+// SetServiceWorkerBypass makes requests skip service workers and go to the
+// network in every tab, or restores normal handling.
+func (m *BrowserManager) SetServiceWorkerBypass(bypass bool) error {
+	return m.backend.SetServiceWorkerBypass(bypass)
+}
+
+// GetServiceWorkersFile returns the service worker blocking preference
+// file path for a session.
+func GetServiceWorkersFile(session string) string {
+	dir := filepath.Join(os.TempDir(), "agent-browser-go")
+	_ = os.MkdirAll(dir, 0755)
+	return filepath.Join(dir, fmt.Sprintf("%s.serviceworkers", session))
+}
+
+// SaveSessionBlockServiceWorkers saves whether the session's browser
+// launches with service worker registration blocked.
+func SaveSessionBlockServiceWorkers(session string, block bool) error {
+	value := "allow"
+	if block {
+		value = "block"
+	}
+	return os.WriteFile(GetServiceWorkersFile(session), []byte(value), 0644)
+}
+
+// GetSessionBlockServiceWorkers retrieves the saved service worker blocking
+// preference for a session. Returns false as default if not found.
+func GetSessionBlockServiceWorkers(session string) bool {
+	data, err := os.ReadFile(GetServiceWorkersFile(session))
+	if err != nil {
+		return false
+	}
+	return string(data) == "block"
+}
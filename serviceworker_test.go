@@ -0,0 +1,74 @@
+package agentbrowser_test
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	agentbrowser "github.com/cpunion/agent-browser-go"
+)
+
+// TestServiceWorkers tests listing and unregistering service workers
+func TestServiceWorkers(t *testing.T) {
+	var result interface{} = []interface{}{
+		map[string]interface{}{"scope": "https://app.example.com/", "scriptURL": "https://app.example.com/sw.js", "state": "activated"},
+	}
+	var script string
+	m := agentbrowser.NewBrowserManagerForTest(&fakeBackend{
+		evaluate: func(s string) (interface{}, error) {
+			script = s
+			return result, nil
+		},
+	})
+
+	workers, err := m.ServiceWorkers()
+	if err != nil {
+		t.Fatalf("ServiceWorkers() error = %v", err)
+	}
+	want := []agentbrowser.ServiceWorker{{Scope: "https://app.example.com/", ScriptURL: "https://app.example.com/sw.js", State: "activated"}}
+	if !reflect.DeepEqual(workers, want) {
+		t.Errorf("ServiceWorkers() = %+v, want %+v", workers, want)
+	}
+
+	result = []interface{}{}
+	if workers, err := m.ServiceWorkers(); err != nil || workers == nil || len(workers) != 0 {
+		t.Errorf("ServiceWorkers() on empty = %#v, %v, want empty list", workers, err)
+	}
+
+	tests := []struct {
+		scope string
+		arg   string
+	}{
+		{"", `})("")`},
+		{"https://app.example.com/", `})("https://app.example.com/")`},
+	}
+	for _, tt := range tests {
+		result = float64(2)
+		n, err := m.UnregisterServiceWorkers(tt.scope)
+		if err != nil || n != 2 {
+			t.Errorf("UnregisterServiceWorkers(%q) = %d, %v, want 2", tt.scope, n, err)
+		}
+		if !strings.HasSuffix(script, tt.arg) {
+			t.Errorf("UnregisterServiceWorkers(%q) script ends %q, want %q", tt.scope, script[len(script)-30:], tt.arg)
+		}
+	}
+}
+
+// TestSessionBlockServiceWorkers tests saving the service worker preference
+func TestSessionBlockServiceWorkers(t *testing.T) {
+	session := "test-sw-" + t.Name()
+	t.Cleanup(func() { os.Remove(agentbrowser.GetServiceWorkersFile(session)) })
+
+	if agentbrowser.GetSessionBlockServiceWorkers(session) {
+		t.Error("GetSessionBlockServiceWorkers() = true before saving, want false")
+	}
+	for _, block := range []bool{true, false} {
+		if err := agentbrowser.SaveSessionBlockServiceWorkers(session, block); err != nil {
+			t.Fatal(err)
+		}
+		if got := agentbrowser.GetSessionBlockServiceWorkers(session); got != block {
+			t.Errorf("GetSessionBlockServiceWorkers() = %v, want %v", got, block)
+		}
+	}
+}
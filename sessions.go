@@ -0,0 +1,299 @@
+package agentbrowser
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"time"
+)
+
+// SessionInfo describes a session, for tools deciding whether to reuse it.
+type SessionInfo struct {
+	Name        string `json:"name"`
+	Running     bool   `json:"running"` // whether its daemon is running
+	Backend     string `json:"backend"`
+	Headed      bool   `json:"headed"`
+	UserDataDir string `json:"userDataDir,omitempty"`
+	PID         int    `json:"pid,omitempty"`    // of the daemon
+	Socket      string `json:"socket,omitempty"` // the daemon listens on, or its TCP address on Windows
+	Uptime      int64  `json:"uptime,omitempty"` // ms since the daemon started
+	Clients     int    `json:"clients"`          // connected clients besides the one asking
+	LastCommand string `json:"lastCommand,omitempty"`
+	LastAt      int64  `json:"lastAt,omitempty"` // ms since the epoch when the last command started
+	Launched    bool   `json:"launched"`         // whether the browser is open
+	URL         string `json:"url,omitempty"`    // of the active tab
+	Title       string `json:"title,omitempty"`
+	Tabs        int    `json:"tabs,omitempty"`
+	Memory      int64  `json:"memory,omitempty"` // bytes resident in the daemon and browser processes
+}
+
+// sessionFiles returns the files in the session directory that hold a
+// session's daemon state and saved launch settings.
+func sessionFiles(session string) []string {
+	files := []string{
+		GetPIDFile(session),
+		GetPortFile(session),
+		GetLogFile(session),
+		legacySessionFile(session, "backend"),
+		legacySessionFile(session, "headed"),
+		legacySessionFile(session, "viewport"),
+		GetStealthFile(session),
+		GetUserDataDirFile(session),
+		GetFingerprintFile(session),
+		GetHostRulesFile(session),
+		GetUserAgentFile(session),
+		GetProxyFile(session),
+		GetServiceWorkersFile(session),
+		GetTLSFile(session),
+		GetInitScriptsFile(session),
+	}
+	if socket := GetSocketPath(session); socket != "" {
+		files = append(files, socket)
+	}
+	return files
+}
+
+// daemonAddress returns the socket path of a session's daemon, or its TCP
+// address on Windows.
+func daemonAddress(session string) string {
+	if runtime.GOOS == "windows" {
+		return fmt.Sprintf("127.0.0.1:%d", GetPortForSession(session))
+	}
+	return GetSocketPath(session)
+}
+
+// ListSessions returns the names of the sessions with a running daemon or
+// saved state, sorted.
+func ListSessions() ([]string, error) {
+	dir := filepath.Join(os.TempDir(), "agent-browser-go")
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	suffixes := map[string]bool{}
+	for _, file := range sessionFiles("") {
+		suffixes[filepath.Ext(file)] = true
+	}
+	var names []string
+	for _, entry := range entries {
+		ext := filepath.Ext(entry.Name())
+		if name := strings.TrimSuffix(entry.Name(), ext); !entry.IsDir() && suffixes[ext] && name != "" {
+			names = append(names, name)
+		}
+	}
+	if logs, err := os.ReadDir(filepath.Join(dir, "logs")); err == nil {
+		for _, entry := range logs {
+			if entry.IsDir() {
+				names = append(names, entry.Name())
+			}
+		}
+	}
+	slices.Sort(names)
+	return slices.Compact(names), nil
+}
+
+var (
+	// ErrInvalidSessionName is wrapped by the errors of session names that
+	// cannot be a session's file names.
+	ErrInvalidSessionName = errors.New("invalid session name")
+
+	// ErrNoSession is wrapped by the errors of session commands naming a
+	// session that has neither a running daemon nor saved state.
+	ErrNoSession = errors.New("no session named")
+)
+
+// sessionLogDir returns the session's log directory without creating it.
+func sessionLogDir(session string) string {
+	return filepath.Join(os.TempDir(), "agent-browser-go", "logs", session)
+}
+
+// checkSessionName rejects names that cannot be a session's file names.
+func checkSessionName(session string) error {
+	if session == "" || session == "." || session == ".." || filepath.Base(session) != session {
+		return fmt.Errorf("%w %q", ErrInvalidSessionName, session)
+	}
+	return nil
+}
+
+// SessionExists reports whether a session has a running daemon or any
+// saved state.
+func SessionExists(session string) bool {
+	if IsDaemonRunning(session) {
+		return true
+	}
+	for _, file := range append(sessionFiles(session), sessionLogDir(session)) {
+		if _, err := os.Stat(file); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// DeleteSession closes the session's browser, stops its daemon and removes
+// its state: socket, pid, log and saved launch settings. A profile given
+// with --user-data-dir is the user's and is kept, as is the session's entry
+// in the config file with the backend, headed mode and viewport it last
+// started with.
+func DeleteSession(session string) error {
+	if err := checkSessionName(session); err != nil {
+		return err
+	}
+	if !SessionExists(session) {
+		return fmt.Errorf("%w %s", ErrNoSession, session)
+	}
+	if IsDaemonRunning(session) {
+		// Closing the browser shuts the daemon down; stopping the daemon
+		// alone would leave the browser running
+		client := NewClient(session)
+		if client.Connect() == nil {
+			_, _ = client.Send(&CloseCommand{BaseCommand: BaseCommand{ID: "delete", Action: "close"}})
+			client.Close()
+		}
+		for i := 0; i < 50 && IsDaemonRunning(session); i++ {
+			time.Sleep(100 * time.Millisecond)
+		}
+		if IsDaemonRunning(session) {
+			if err := StopDaemon(session); err != nil {
+				return err
+			}
+		}
+	}
+
+	var errs []error
+	for _, file := range sessionFiles(session) {
+		if err := os.Remove(file); err != nil && !errors.Is(err, os.ErrNotExist) {
+			errs = append(errs, err)
+		}
+	}
+	if err := os.RemoveAll(sessionLogDir(session)); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// RenameSession moves a stopped session's saved launch settings, logs and
+// entry in the user config file to a new name. Entries in project config
+// files are not renamed.
+func RenameSession(session, name string) error {
+	for _, s := range []string{session, name} {
+		if err := checkSessionName(s); err != nil {
+			return err
+		}
+	}
+	switch {
+	case IsDaemonRunning(session):
+		return fmt.Errorf("session %s is running; stop it first with daemon stop --session %s", session, session)
+	case !SessionExists(session):
+		return fmt.Errorf("%w %s", ErrNoSession, session)
+	case SessionExists(name):
+		return fmt.Errorf("session %s already exists", name)
+	}
+
+	if err := renameSessionConfig(session, name); err != nil {
+		return err
+	}
+
+	// The socket, pid and port of a stopped daemon are stale
+	stale := map[string]bool{GetSocketPath(session): true, GetPIDFile(session): true, GetPortFile(session): true}
+	from, to := sessionFiles(session), sessionFiles(name)
+	var errs []error
+	for i, file := range from {
+		var err error
+		if stale[file] {
+			err = os.Remove(file)
+		} else {
+			err = os.Rename(file, to[i])
+		}
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			errs = append(errs, err)
+		}
+	}
+	if err := os.Rename(sessionLogDir(session), sessionLogDir(name)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// savedSessionInfo returns what the session's saved settings tell about it.
+func savedSessionInfo(session string) SessionInfo {
+	return SessionInfo{
+		Name:        session,
+		Backend:     GetSessionBackend(session),
+		Headed:      GetSessionHeaded(session),
+		UserDataDir: GetSessionUserDataDir(session),
+	}
+}
+
+// GetSessionInfo describes a session, asking its daemon when it runs.
+func GetSessionInfo(session string) (*SessionInfo, error) {
+	if err := checkSessionName(session); err != nil {
+		return nil, err
+	}
+	if !IsDaemonRunning(session) {
+		if !SessionExists(session) {
+			return nil, fmt.Errorf("%w %s", ErrNoSession, session)
+		}
+		info := savedSessionInfo(session)
+		return &info, nil
+	}
+
+	client := NewClient(session)
+	if err := client.Connect(); err != nil {
+		return nil, err
+	}
+	defer client.Close()
+	resp, err := client.Send(&SessionInfoCommand{BaseCommand: BaseCommand{ID: "session-info", Action: "session_info"}})
+	if err != nil {
+		return nil, err
+	}
+	if !resp.Success {
+		return nil, errors.New(resp.Error)
+	}
+	var info SessionInfo
+	if err := json.Unmarshal(resp.Data, &info); err != nil {
+		return nil, fmt.Errorf("unexpected session info: %w", err)
+	}
+	return &info, nil
+}
+
+// SessionInfo describes the daemon's session and its browser.
+func (d *Daemon) SessionInfo() SessionInfo {
+	info := savedSessionInfo(d.session)
+	info.Running = true
+	info.UserDataDir = cmp.Or(d.userDataDir, info.UserDataDir)
+	info.PID = os.Getpid()
+	info.Socket = daemonAddress(d.session)
+	info.Uptime = time.Since(d.started).Milliseconds()
+	info.Clients = max(int(d.clients.Load())-1, 0)
+	if action, at := d.commands.latest(); action != "" {
+		info.LastCommand, info.LastAt = action, at.UnixMilli()
+	}
+
+	pids := []int{info.PID}
+	if d.browser.IsLaunched() {
+		info.Launched = true
+		info.URL, _ = d.browser.URL()
+		info.Title, _ = d.browser.Title()
+		if tabs, err := d.browser.ListTabs(); err == nil {
+			info.Tabs = len(tabs)
+		}
+		if processes, err := d.browser.backend.BrowserProcesses(); err == nil {
+			for _, p := range processes {
+				pids = append(pids, p.PID)
+			}
+		}
+	}
+	for _, rss := range processRSS(pids) {
+		info.Memory += rss
+	}
+	return info
+}
@@ -0,0 +1,160 @@
+package agentbrowser_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	agentbrowser "github.com/cpunion/agent-browser-go"
+)
+
+// TestDeleteSession tests that a stopped session's state is removed
+func TestDeleteSession(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+	t.Setenv("AGENT_BROWSER_CONFIG", filepath.Join(t.TempDir(), "config.yaml"))
+	if err := agentbrowser.SaveSessionBackend("old", "playwright"); err != nil {
+		t.Fatal(err)
+	}
+	if err := agentbrowser.SaveSessionHeaded("old", true); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(agentbrowser.GetPIDFile("old"), []byte("0"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	logDir := agentbrowser.GetSessionLogDir("old")
+	if err := os.WriteFile(filepath.Join(logDir, "browser.log"), []byte("log"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if !agentbrowser.SessionExists("old") {
+		t.Fatal("SessionExists() = false, want true")
+	}
+	if err := agentbrowser.DeleteSession("old"); err != nil {
+		t.Fatalf("DeleteSession() error = %v", err)
+	}
+	for _, path := range []string{agentbrowser.GetPIDFile("old"), logDir} {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("%s still exists after DeleteSession()", path)
+		}
+	}
+	if agentbrowser.SessionExists("old") {
+		t.Error("SessionExists() after DeleteSession() = true, want false")
+	}
+
+	if err := agentbrowser.DeleteSession("old"); !errors.Is(err, agentbrowser.ErrNoSession) {
+		t.Errorf("DeleteSession() of a deleted session error = %v, want ErrNoSession", err)
+	}
+	for _, name := range []string{"", "../etc", "a/b"} {
+		if err := agentbrowser.DeleteSession(name); !errors.Is(err, agentbrowser.ErrInvalidSessionName) {
+			t.Errorf("DeleteSession(%q) error = %v, want ErrInvalidSessionName", name, err)
+		}
+	}
+}
+
+// TestRenameSession tests that a session's saved settings and logs move to
+// the new name
+func TestRenameSession(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+	t.Setenv("AGENT_BROWSER_CONFIG", filepath.Join(t.TempDir(), "config.yaml"))
+	if err := agentbrowser.SaveSessionBackend("old", "playwright"); err != nil {
+		t.Fatal(err)
+	}
+	if err := agentbrowser.SaveSessionUserAgent("old", "TestAgent/1.0"); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(agentbrowser.GetPIDFile("old"), []byte("0"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(agentbrowser.GetSessionLogDir("old"), "browser.log"), []byte("log"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := agentbrowser.SaveSessionBackend("taken", "chromedp"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := agentbrowser.RenameSession("old", "taken"); err == nil {
+		t.Error("RenameSession() onto an existing session expected an error")
+	}
+	if err := agentbrowser.RenameSession("missing", "other"); !errors.Is(err, agentbrowser.ErrNoSession) {
+		t.Errorf("RenameSession() of a missing session error = %v, want ErrNoSession", err)
+	}
+	if err := agentbrowser.RenameSession("old", "new"); err != nil {
+		t.Fatalf("RenameSession() error = %v", err)
+	}
+
+	if got := agentbrowser.GetSessionBackend("new"); got != "playwright" {
+		t.Errorf("backend of the renamed session = %q, want playwright", got)
+	}
+	if got := agentbrowser.GetSessionUserAgent("new"); got != "TestAgent/1.0" {
+		t.Errorf("user agent of the renamed session = %q, want TestAgent/1.0", got)
+	}
+	if _, err := os.Stat(filepath.Join(agentbrowser.GetSessionLogDir("new"), "browser.log")); err != nil {
+		t.Errorf("log of the renamed session: %v", err)
+	}
+	if _, err := os.Stat(agentbrowser.GetPIDFile("new")); !os.IsNotExist(err) {
+		t.Error("the stale pid file was renamed, want it removed")
+	}
+	if agentbrowser.SessionExists("old") {
+		t.Error("SessionExists() of the old name = true, want false")
+	}
+}
+
+// TestGetSessionInfo tests describing a stopped session from its saved
+// settings
+func TestGetSessionInfo(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+	t.Setenv("AGENT_BROWSER_CONFIG", filepath.Join(t.TempDir(), "config.yaml"))
+	if _, err := agentbrowser.GetSessionInfo("stopped"); err == nil {
+		t.Error("GetSessionInfo() of a missing session expected an error")
+	}
+	if err := agentbrowser.SaveSessionBackend("stopped", "playwright"); err != nil {
+		t.Fatal(err)
+	}
+	if err := agentbrowser.SaveSessionHeaded("stopped", true); err != nil {
+		t.Fatal(err)
+	}
+	if err := agentbrowser.SaveSessionUserDataDir("stopped", "/tmp/profile"); err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := agentbrowser.GetSessionInfo("stopped")
+	if err != nil {
+		t.Fatalf("GetSessionInfo() error = %v", err)
+	}
+	want := agentbrowser.SessionInfo{Name: "stopped", Backend: "playwright", Headed: true, UserDataDir: "/tmp/profile"}
+	if *info != want {
+		t.Errorf("GetSessionInfo() = %+v, want %+v", *info, want)
+	}
+}
+
+// TestListSessions tests that sessions are found by their saved state
+func TestListSessions(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+	t.Setenv("AGENT_BROWSER_CONFIG", filepath.Join(t.TempDir(), "config.yaml"))
+	if names, err := agentbrowser.ListSessions(); err != nil || len(names) != 0 {
+		t.Fatalf("ListSessions() = %v, %v; want none", names, err)
+	}
+	if err := agentbrowser.SaveSessionStealth("b", false); err != nil {
+		t.Fatal(err)
+	}
+	if err := agentbrowser.SaveSessionBackend("d", "chromedp"); err != nil {
+		t.Fatal(err)
+	}
+	if err := agentbrowser.SaveSessionUserAgent("a", "TestAgent/1.0"); err != nil {
+		t.Fatal(err)
+	}
+	agentbrowser.GetSessionLogDir("c")
+	if err := os.WriteFile(filepath.Join(os.TempDir(), "agent-browser-go", "notes.txt"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	names, err := agentbrowser.ListSessions()
+	if err != nil {
+		t.Fatalf("ListSessions() error = %v", err)
+	}
+	if want := []string{"a", "b", "c"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("ListSessions() = %v, want %v", names, want)
+	}
+}
@@ -0,0 +1,157 @@
+package agentbrowser
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+)
+
+// maxSitemaps caps how many sitemap files one Sitemap call fetches,
+// counting those reached through sitemap indexes.
+const maxSitemaps = 50
+
+// sitemapMaxSize is the largest sitemap read, the protocol's own limit.
+const sitemapMaxSize = 50 << 20
+
+// SitemapResult lists the page URLs found in an origin's sitemaps.
+type SitemapResult struct {
+	Sitemaps []string `json:"sitemaps"` // sitemap files read
+	URLs     []string `json:"urls"`
+}
+
+// sitemapXML matches both <urlset> and <sitemapindex> documents.
+type sitemapXML struct {
+	URLs []struct {
+		Loc string `xml:"loc"`
+	} `xml:"url"`
+	Sitemaps []struct {
+		Loc string `xml:"loc"`
+	} `xml:"sitemap"`
+}
+
+// Sitemap reads sitemaps into a list of page URLs, for seeding a crawl. With
+// an empty sitemapURL the sitemaps are discovered from the current page's
+// origin: those listed in robots.txt, or /sitemap.xml. Sitemap indexes are
+// followed, and gzipped and plain text sitemaps are read too.
+func (m *BrowserManager) Sitemap(sitemapURL string) (*SitemapResult, error) {
+	var queue []string
+	if sitemapURL != "" {
+		queue = []string{sitemapURL}
+	} else {
+		current, err := m.backend.URL()
+		if err != nil {
+			return nil, err
+		}
+		origin := originOf(current)
+		if origin == "" {
+			return nil, fmt.Errorf("no sitemap URL given and the current page has no http(s) origin")
+		}
+		queue = m.robotsFor(origin).Sitemaps
+		if len(queue) == 0 {
+			queue = []string{origin + "/sitemap.xml"}
+		}
+	}
+
+	result := &SitemapResult{Sitemaps: []string{}, URLs: []string{}}
+	seenSitemaps := make(map[string]bool)
+	seenURLs := make(map[string]bool)
+	var firstErr error
+	for len(queue) > 0 && len(result.Sitemaps) < maxSitemaps {
+		next := queue[0]
+		queue = queue[1:]
+		if seenSitemaps[next] {
+			continue
+		}
+		seenSitemaps[next] = true
+
+		urls, children, err := fetchSitemap(next)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		result.Sitemaps = append(result.Sitemaps, next)
+		queue = append(queue, children...)
+		for _, u := range urls {
+			if !seenURLs[u] {
+				seenURLs[u] = true
+				result.URLs = append(result.URLs, u)
+			}
+		}
+	}
+	if len(result.Sitemaps) == 0 && firstErr != nil {
+		return nil, firstErr
+	}
+	return result, nil
+}
+
+// fetchSitemap fetches one sitemap and returns its page URLs and, for a
+// sitemap index, the sitemaps it lists.
+func fetchSitemap(sitemapURL string) (urls, sitemaps []string, err error) {
+	resp, err := webClient.Get(sitemapURL)
+	if err != nil {
+		return nil, nil, fmt.Errorf("fetch sitemap %s: %w", sitemapURL, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, nil, fmt.Errorf("fetch sitemap %s: %s", sitemapURL, resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, sitemapMaxSize))
+	if err != nil {
+		return nil, nil, fmt.Errorf("read sitemap %s: %w", sitemapURL, err)
+	}
+	return ParseSitemap(data)
+}
+
+// ParseSitemap parses an XML sitemap or sitemap index, gzipped or not, or
+// a plain text sitemap with one URL per line.
+func ParseSitemap(data []byte) (urls, sitemaps []string, err error) {
+	if bytes.HasPrefix(data, []byte{0x1f, 0x8b}) {
+		zr, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid gzipped sitemap: %w", err)
+		}
+		if data, err = io.ReadAll(io.LimitReader(zr, sitemapMaxSize)); err != nil {
+			return nil, nil, fmt.Errorf("invalid gzipped sitemap: %w", err)
+		}
+	}
+
+	if trimmed := bytes.TrimSpace(data); !bytes.HasPrefix(trimmed, []byte("<")) {
+		return parseTextSitemap(trimmed), nil, nil
+	}
+
+	var doc sitemapXML
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		return nil, nil, fmt.Errorf("invalid sitemap: %w", err)
+	}
+	for _, u := range doc.URLs {
+		if loc := strings.TrimSpace(u.Loc); loc != "" {
+			urls = append(urls, loc)
+		}
+	}
+	for _, s := range doc.Sitemaps {
+		if loc := strings.TrimSpace(s.Loc); loc != "" {
+			sitemaps = append(sitemaps, loc)
+		}
+	}
+	return urls, sitemaps, nil
+}
+
+// parseTextSitemap reads the absolute http(s) URLs of a text sitemap.
+func parseTextSitemap(data []byte) []string {
+	var urls []string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if u, err := url.Parse(line); err == nil && (u.Scheme == "http" || u.Scheme == "https") {
+			urls = append(urls, line)
+		}
+	}
+	return urls
+}
@@ -29,6 +29,8 @@ type SnapshotOptions struct {
 	MaxDepth    int    `json:"maxDepth,omitempty"`
 	Compact     bool   `json:"compact,omitempty"`
 	Selector    string `json:"selector,omitempty"`
+
+	keepRefs bool // leave the backend's ref map as it is
 }
 
 // Role classifications
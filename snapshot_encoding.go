@@ -0,0 +1,159 @@
+package agentbrowser
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Snapshot encodings.
+const (
+	SnapshotFormatTree      = "tree"
+	SnapshotFormatCompactV2 = "compact-v2"
+)
+
+// CompactV2Legend documents how to decode the compact-v2 snapshot encoding.
+const CompactV2Legend = `compact-v2 snapshot encoding (one element per line):
+  <role>"<name>"#<ref>~<nth> <attrs>   e.g. b"Submit"#e3, h2"Title"#e1, x"Remember me"#e4 checked
+  role       abbreviated (see below); unknown roles are kept verbatim
+  "name"     accessible name, omitted when empty
+  #ref       element ref, use as @ref in commands (e.g. @e3)
+  ~nth       index among elements with the same role and name (omitted for the first)
+  attrs      remaining states such as checked, disabled, expanded, selected
+  =text      inline text value (e.g. text=Hello)
+  indent     one space per tree level (none with --no-indent)
+roles: a=link b=button t=textbox sb=searchbox x=checkbox o=radio c=combobox
+  lb=listbox op=option m=menuitem mx=menuitemcheckbox mo=menuitemradio
+  sl=slider sp=spinbutton sw=switch tb=tab ti=treeitem h<N>=heading level N
+  i=img p=paragraph li=listitem ul=list nav=navigation rg=region ar=article
+  td=cell gc=gridcell th=columnheader rh=rowheader`
+
+// compactRoles maps ARIA roles to their compact-v2 abbreviations.
+var compactRoles = map[string]string{
+	"link":             "a",
+	"button":           "b",
+	"textbox":          "t",
+	"searchbox":        "sb",
+	"checkbox":         "x",
+	"radio":            "o",
+	"combobox":         "c",
+	"listbox":          "lb",
+	"option":           "op",
+	"menuitem":         "m",
+	"menuitemcheckbox": "mx",
+	"menuitemradio":    "mo",
+	"slider":           "sl",
+	"spinbutton":       "sp",
+	"switch":           "sw",
+	"tab":              "tb",
+	"treeitem":         "ti",
+	"heading":          "h",
+	"img":              "i",
+	"paragraph":        "p",
+	"listitem":         "li",
+	"list":             "ul",
+	"navigation":       "nav",
+	"region":           "rg",
+	"article":          "ar",
+	"cell":             "td",
+	"gridcell":         "gc",
+	"columnheader":     "th",
+	"rowheader":        "rh",
+}
+
+var (
+	compactLineRe = regexp.MustCompile(`^(\s*)-\s*(\w+)(?:\s+"((?:[^"\\]|\\.)*)")?(.*)$`)
+	compactAttrRe = regexp.MustCompile(`\[([^\]=]+)(?:=([^\]]*))?\]`)
+)
+
+// EncodeSnapshot re-encodes a snapshot tree in the given format.
+func EncodeSnapshot(tree, format string, noIndent bool) (string, error) {
+	switch format {
+	case "", SnapshotFormatTree:
+		return tree, nil
+	case SnapshotFormatCompactV2:
+		return EncodeCompactV2(tree, noIndent), nil
+	default:
+		return "", fmt.Errorf("unknown snapshot format: %s (expected %s or %s)", format, SnapshotFormatTree, SnapshotFormatCompactV2)
+	}
+}
+
+// EncodeCompactV2 converts a snapshot tree into the token-optimized
+// compact-v2 encoding described by CompactV2Legend.
+func EncodeCompactV2(tree string, noIndent bool) string {
+	lines := strings.Split(tree, "\n")
+	out := make([]string, 0, len(lines))
+
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		match := compactLineRe.FindStringSubmatch(line)
+		if match == nil {
+			// Metadata such as "- /url: ..." carries no element
+			continue
+		}
+
+		indent := ""
+		if !noIndent {
+			indent = strings.Repeat(" ", len(match[1])/2)
+		}
+		role, name, rest := match[2], match[3], match[4]
+
+		abbr, ok := compactRoles[strings.ToLower(role)]
+		if !ok {
+			abbr = role
+		}
+
+		var ref, nth string
+		var attrs []string
+		for _, attr := range compactAttrRe.FindAllStringSubmatch(rest, -1) {
+			key, value := attr[1], attr[2]
+			switch key {
+			case "ref":
+				ref = value
+			case "nth":
+				nth = value
+			case "level":
+				if abbr == "h" {
+					abbr += value
+				} else {
+					attrs = append(attrs, "level="+value)
+				}
+			default:
+				if value == "" || value == "true" {
+					attrs = append(attrs, key)
+				} else {
+					attrs = append(attrs, key+"="+value)
+				}
+			}
+		}
+
+		var b strings.Builder
+		b.WriteString(indent)
+		b.WriteString(abbr)
+		if name != "" {
+			b.WriteString(`"` + name + `"`)
+		}
+		if ref != "" {
+			b.WriteString("#" + ref)
+		}
+		if nth != "" {
+			b.WriteString("~" + nth)
+		}
+		for _, a := range attrs {
+			b.WriteString(" " + a)
+		}
+
+		// Inline text values, e.g. `- text: Hello` or `- textbox "Email": foo`
+		text := strings.TrimSpace(compactAttrRe.ReplaceAllString(rest, ""))
+		text = strings.TrimSpace(strings.TrimPrefix(text, ":"))
+		if text != "" {
+			b.WriteString("=" + text)
+		}
+
+		out = append(out, b.String())
+	}
+
+	return strings.Join(out, "\n")
+}
@@ -0,0 +1,80 @@
+package agentbrowser_test
+
+import (
+	"testing"
+
+	agentbrowser "github.com/cpunion/agent-browser-go"
+)
+
+// TestEncodeCompactV2 tests the compact-v2 snapshot encoding
+func TestEncodeCompactV2(t *testing.T) {
+	tests := []struct {
+		name     string
+		tree     string
+		noIndent bool
+		expected string
+	}{
+		{
+			name:     "button with ref",
+			tree:     `- button "Submit" [ref=e3]`,
+			expected: `b"Submit"#e3`,
+		},
+		{
+			name:     "heading level",
+			tree:     `- heading "Title" [level=2] [ref=e1]`,
+			expected: `h2"Title"#e1`,
+		},
+		{
+			name:     "nth and states",
+			tree:     `- checkbox "Remember me" [checked] [ref=e4] [nth=1]`,
+			expected: `x"Remember me"#e4~1 checked`,
+		},
+		{
+			name:     "inline text",
+			tree:     `- text: Hello`,
+			expected: `text=Hello`,
+		},
+		{
+			name:     "nested with metadata",
+			tree:     "- navigation \"Main\":\n  - link \"Home\" [ref=e1]:\n    - /url: /\n  - link \"Docs\" [ref=e2]",
+			expected: "nav\"Main\"\n a\"Home\"#e1\n a\"Docs\"#e2",
+		},
+		{
+			name:     "no indent",
+			tree:     "- list:\n  - listitem:\n    - link \"Home\" [ref=e1]",
+			noIndent: true,
+			expected: "ul\nli\na\"Home\"#e1",
+		},
+		{
+			name:     "unknown role kept verbatim",
+			tree:     `- banner [ref=e5]`,
+			expected: `banner#e5`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := agentbrowser.EncodeCompactV2(tt.tree, tt.noIndent)
+			if got != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+// TestEncodeSnapshot_Format tests snapshot format selection
+func TestEncodeSnapshot_Format(t *testing.T) {
+	tree := `- button "Submit" [ref=e3]`
+
+	got, err := agentbrowser.EncodeSnapshot(tree, "", false)
+	if err != nil || got != tree {
+		t.Errorf("expected default format to return tree unchanged, got %q, %v", got, err)
+	}
+	got, err = agentbrowser.EncodeSnapshot(tree, agentbrowser.SnapshotFormatTree, false)
+	if err != nil || got != tree {
+		t.Errorf("expected tree format to return tree unchanged, got %q, %v", got, err)
+	}
+	if _, err := agentbrowser.EncodeSnapshot(tree, "yaml", false); err == nil {
+		t.Error("expected error for unknown format")
+	}
+}
@@ -0,0 +1,118 @@
+package agentbrowser
+
+import (
+	"regexp"
+	"strings"
+)
+
+// SnapshotDiff is how a snapshot differs from an earlier one of the page.
+type SnapshotDiff struct {
+	Lines       []string `json:"lines"` // changed lines in order, "- " removed and "+ " added
+	Added       int      `json:"added"`
+	Removed     int      `json:"removed"`
+	RefsChanged bool     `json:"refsChanged,omitempty"` // unchanged lines got other refs
+}
+
+// maxDiffCells bounds the table compared snapshots are aligned with. Larger
+// changes are reported as all old lines removed and all new lines added.
+const maxDiffCells = 1 << 20
+
+// refPattern matches the ref of a snapshot line.
+var refPattern = regexp.MustCompile(`\s*\[ref=[^\]]*\]`)
+
+// DiffSnapshots compares two snapshots line by line. Refs are numbered
+// afresh for every snapshot, so an inserted element renumbers the ones after
+// it; lines are compared without their refs, and added lines keep theirs.
+func DiffSnapshots(old, new string) SnapshotDiff {
+	a, b := splitLines(old), splitLines(new)
+	ka, kb := make([]string, len(a)), make([]string, len(b))
+	for i, line := range a {
+		ka[i] = refPattern.ReplaceAllString(line, "")
+	}
+	for j, line := range b {
+		kb[j] = refPattern.ReplaceAllString(line, "")
+	}
+
+	// Unchanged lines at either end need no alignment
+	start := 0
+	for start < len(a) && start < len(b) && ka[start] == kb[start] {
+		start++
+	}
+	endA, endB := len(a), len(b)
+	for endA > start && endB > start && ka[endA-1] == kb[endB-1] {
+		endA--
+		endB--
+	}
+
+	diff := SnapshotDiff{Lines: []string{}}
+	same := func(i, j int) {
+		if a[i] != b[j] {
+			diff.RefsChanged = true
+		}
+	}
+	removed := func(i int) {
+		diff.Lines = append(diff.Lines, "- "+a[i])
+		diff.Removed++
+	}
+	added := func(j int) {
+		diff.Lines = append(diff.Lines, "+ "+b[j])
+		diff.Added++
+	}
+	for i := 0; i < start; i++ {
+		same(i, i)
+	}
+
+	midA, midB := ka[start:endA], kb[start:endB]
+	if len(midA)*len(midB) > maxDiffCells {
+		for i := range midA {
+			removed(start + i)
+		}
+		for j := range midB {
+			added(start + j)
+		}
+	} else {
+		// lcs[i][j] is the longest common run of midA[i:] and midB[j:]
+		lcs := make([][]int, len(midA)+1)
+		for i := range lcs {
+			lcs[i] = make([]int, len(midB)+1)
+		}
+		for i := len(midA) - 1; i >= 0; i-- {
+			for j := len(midB) - 1; j >= 0; j-- {
+				if midA[i] == midB[j] {
+					lcs[i][j] = lcs[i+1][j+1] + 1
+				} else {
+					lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+				}
+			}
+		}
+		i, j := 0, 0
+		for i < len(midA) || j < len(midB) {
+			switch {
+			case i < len(midA) && j < len(midB) && midA[i] == midB[j]:
+				same(start+i, start+j)
+				i++
+				j++
+			case i < len(midA) && (j == len(midB) || lcs[i+1][j] >= lcs[i][j+1]):
+				removed(start + i)
+				i++
+			default:
+				added(start + j)
+				j++
+			}
+		}
+	}
+
+	for i := endA; i < len(a); i++ {
+		same(i, endB+i-endA)
+	}
+	return diff
+}
+
+// splitLines splits a snapshot into its lines.
+func splitLines(s string) []string {
+	s = strings.TrimRight(s, "\n")
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, "\n")
+}
@@ -0,0 +1,67 @@
+package agentbrowser_test
+
+import (
+	"reflect"
+	"testing"
+
+	agentbrowser "github.com/cpunion/agent-browser-go"
+)
+
+// TestDiffSnapshots tests that snapshots are compared without their refs
+func TestDiffSnapshots(t *testing.T) {
+	old := `- heading "Results" [ref=e1]
+- listitem: Apple
+- listitem: Banana
+- button "More" [ref=e2]
+`
+	tests := []struct {
+		name string
+		new  string
+		want agentbrowser.SnapshotDiff
+	}{
+		{
+			name: "unchanged",
+			new:  old,
+			want: agentbrowser.SnapshotDiff{Lines: []string{}},
+		},
+		{
+			name: "inserted element renumbers refs",
+			new: `- heading "Results" [ref=e1]
+- link "Filter" [ref=e2]
+- listitem: Apple
+- listitem: Banana
+- button "More" [ref=e3]`,
+			want: agentbrowser.SnapshotDiff{
+				Lines:       []string{`+ - link "Filter" [ref=e2]`},
+				Added:       1,
+				RefsChanged: true,
+			},
+		},
+		{
+			name: "replaced and removed lines",
+			new: `- heading "Results" [ref=e1]
+- listitem: Cherry
+- button "More" [ref=e2]`,
+			want: agentbrowser.SnapshotDiff{
+				Lines:   []string{"- - listitem: Apple", "- - listitem: Banana", "+ - listitem: Cherry"},
+				Added:   1,
+				Removed: 2,
+			},
+		},
+		{
+			name: "emptied",
+			new:  "",
+			want: agentbrowser.SnapshotDiff{
+				Lines:   []string{`- - heading "Results" [ref=e1]`, "- - listitem: Apple", "- - listitem: Banana", `- - button "More" [ref=e2]`},
+				Removed: 4,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := agentbrowser.DiffSnapshots(old, tt.new); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DiffSnapshots() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
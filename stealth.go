@@ -0,0 +1,166 @@
+package agentbrowser
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// stealthArgs are Chrome switches added in stealth mode, on top of the
+// automation flags that are always set.
+var stealthArgs = []string{
+	"--ignore-gpu-blocklist", // keep WebGL available in headless mode
+	"--enable-webgl",
+	"--disable-features=IsolateOrigins,site-per-process,Translate",
+	"--disable-popup-blocking",
+}
+
+// stealthUserAgent removes the headless marker from a user agent.
+func stealthUserAgent(ua string) string {
+	return strings.ReplaceAll(ua, "HeadlessChrome", "Chrome")
+}
+
+// stealthLanguages returns navigator.languages for a locale.
+func stealthLanguages(locale string) []string {
+	if locale == "" {
+		return []string{"en-US", "en"}
+	}
+	langs := []string{locale}
+	if base, _, ok := strings.Cut(locale, "-"); ok {
+		langs = append(langs, base)
+	}
+	return langs
+}
+
+// stealthScript returns an init script that hides the usual automation
+// tells: navigator.webdriver, empty plugins and languages, the missing
+// chrome.runtime object, software WebGL strings, the notifications
+// permission mismatch, the headless user agent and zero outer window size.
+// Patched functions report native source through Function.prototype.toString.
+func stealthScript(locale string) string {
+	langs, _ := json.Marshal(stealthLanguages(locale))
+
+	return fmt.Sprintf(`(() => {
+	const nativeSource = new WeakMap();
+	const toString = Function.prototype.toString;
+	const patchedToString = function () {
+		return nativeSource.has(this) ? nativeSource.get(this) : toString.call(this);
+	};
+	nativeSource.set(patchedToString, 'function toString() { [native code] }');
+	Function.prototype.toString = patchedToString;
+	const native = (fn, name) => {
+		nativeSource.set(fn, 'function ' + name + '() { [native code] }');
+		return fn;
+	};
+	const getter = (proto, prop, fn) => {
+		Object.defineProperty(proto, prop, { get: native(fn, 'get ' + prop), configurable: true, enumerable: true });
+	};
+
+	// navigator.webdriver is true under automation
+	getter(Navigator.prototype, 'webdriver', () => undefined);
+
+	// Headless Chrome reports an empty or short language list
+	const languages = Object.freeze(%s);
+	getter(Navigator.prototype, 'languages', () => languages);
+
+	// User agent without the HeadlessChrome marker
+	const ua = navigator.userAgent.replace('HeadlessChrome', 'Chrome');
+	const appVersion = navigator.appVersion.replace('HeadlessChrome', 'Chrome');
+	getter(Navigator.prototype, 'userAgent', () => ua);
+	getter(Navigator.prototype, 'appVersion', () => appVersion);
+
+	// Headless Chrome has no plugins; report the built-in PDF viewers
+	if (navigator.plugins.length === 0) {
+		const mimeType = { type: 'application/pdf', suffixes: 'pdf', description: 'Portable Document Format' };
+		Object.setPrototypeOf(mimeType, MimeType.prototype);
+		const names = ['PDF Viewer', 'Chrome PDF Viewer', 'Chromium PDF Viewer', 'Microsoft Edge PDF Viewer', 'WebKit built-in PDF'];
+		const plugins = names.map((name) => {
+			const plugin = { name, filename: 'internal-pdf-viewer', description: 'Portable Document Format', length: 1, 0: mimeType };
+			plugin.item = native((i) => (i === 0 ? mimeType : null), 'item');
+			plugin.namedItem = native((n) => (n === mimeType.type ? mimeType : null), 'namedItem');
+			return Object.setPrototypeOf(plugin, Plugin.prototype);
+		});
+		const list = (items, key) => {
+			const arr = Object.assign({ length: items.length }, items);
+			for (const item of items) arr[item[key]] = item;
+			arr.item = native((i) => items[i] || null, 'item');
+			arr.namedItem = native((n) => items.find((item) => item[key] === n) || null, 'namedItem');
+			arr[Symbol.iterator] = native(function* () { yield* items; }, 'values');
+			return arr;
+		};
+		const pluginArray = Object.setPrototypeOf(list(plugins, 'name'), PluginArray.prototype);
+		pluginArray.refresh = native(() => {}, 'refresh');
+		const mimeTypeArray = Object.setPrototypeOf(list([mimeType], 'type'), MimeTypeArray.prototype);
+		mimeType.enabledPlugin = plugins[0];
+		getter(Navigator.prototype, 'plugins', () => pluginArray);
+		getter(Navigator.prototype, 'mimeTypes', () => mimeTypeArray);
+	}
+
+	// Regular Chrome exposes window.chrome with runtime, app and timing helpers
+	if (!window.chrome) {
+		Object.defineProperty(window, 'chrome', { value: {}, writable: true, configurable: true });
+	}
+	if (!window.chrome.runtime) {
+		window.chrome.runtime = {
+			OnInstalledReason: { CHROME_UPDATE: 'chrome_update', INSTALL: 'install', SHARED_MODULE_UPDATE: 'shared_module_update', UPDATE: 'update' },
+			OnRestartRequiredReason: { APP_UPDATE: 'app_update', OS_UPDATE: 'os_update', PERIODIC: 'periodic' },
+			PlatformOs: { ANDROID: 'android', CROS: 'cros', LINUX: 'linux', MAC: 'mac', OPENBSD: 'openbsd', WIN: 'win' },
+			connect: native(() => { throw new TypeError('Error in invocation of runtime.connect(optional string extensionId, optional object connectInfo): chrome.runtime.connect() called from a webpage must specify an Extension ID (string) for its first argument.'); }, 'connect'),
+			sendMessage: native(() => { throw new TypeError('Error in invocation of runtime.sendMessage(optional string extensionId, any message, optional object options, optional function callback): chrome.runtime.sendMessage() called from a webpage must specify an Extension ID (string) for its first argument.'); }, 'sendMessage'),
+			id: undefined,
+		};
+	}
+	if (!window.chrome.app) {
+		window.chrome.app = {
+			isInstalled: false,
+			InstallState: { DISABLED: 'disabled', INSTALLED: 'installed', NOT_INSTALLED: 'not_installed' },
+			RunningState: { CANNOT_RUN: 'cannot_run', READY_TO_RUN: 'ready_to_run', RUNNING: 'running' },
+			getDetails: native(() => null, 'getDetails'),
+			getIsInstalled: native(() => false, 'getIsInstalled'),
+		};
+	}
+	if (!window.chrome.csi) {
+		const start = performance.timeOrigin;
+		window.chrome.csi = native(() => ({ onloadT: start + 100, startE: start, pageT: performance.now(), tran: 15 }), 'csi');
+	}
+	if (!window.chrome.loadTimes) {
+		const start = performance.timeOrigin / 1000;
+		window.chrome.loadTimes = native(() => ({
+			requestTime: start, startLoadTime: start, commitLoadTime: start + 0.05,
+			finishDocumentLoadTime: start + 0.1, finishLoadTime: start + 0.15, firstPaintTime: start + 0.12,
+			firstPaintAfterLoadTime: 0, navigationType: 'Other', wasFetchedViaSpdy: true,
+			wasNpnNegotiated: true, npnNegotiatedProtocol: 'h2', wasAlternateProtocolAvailable: false,
+			connectionInfo: 'h2',
+		}), 'loadTimes');
+	}
+
+	// Software rendering reports SwiftShader as the WebGL vendor
+	for (const ctx of [window.WebGLRenderingContext, window.WebGL2RenderingContext]) {
+		if (!ctx) continue;
+		const getParameter = ctx.prototype.getParameter;
+		ctx.prototype.getParameter = native(function (param) {
+			if (param === 37445) return 'Intel Inc.'; // UNMASKED_VENDOR_WEBGL
+			if (param === 37446) return 'Intel Iris OpenGL Engine'; // UNMASKED_RENDERER_WEBGL
+			return getParameter.call(this, param);
+		}, 'getParameter');
+	}
+
+	// Headless Chrome denies notifications while the query says "prompt"
+	if (navigator.permissions && window.Notification) {
+		const query = Permissions.prototype.query;
+		Permissions.prototype.query = native(function (desc) {
+			if (desc && desc.name === 'notifications') {
+				const state = Notification.permission === 'default' ? 'prompt' : Notification.permission;
+				return Promise.resolve(Object.setPrototypeOf({ state, name: 'notifications', onchange: null }, PermissionStatus.prototype));
+			}
+			return query.call(this, desc);
+		}, 'query');
+	}
+
+	// Headless windows have no outer frame
+	if (window.outerWidth === 0 && window.outerHeight === 0) {
+		getter(window, 'outerWidth', () => window.innerWidth);
+		getter(window, 'outerHeight', () => window.innerHeight + 85);
+	}
+})()`, langs)
+}
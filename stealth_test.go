@@ -0,0 +1,50 @@
+package agentbrowser_test
+
+import (
+	"strings"
+	"testing"
+
+	agentbrowser "github.com/cpunion/agent-browser-go"
+)
+
+// TestStealthUserAgent tests removal of the headless marker
+func TestStealthUserAgent(t *testing.T) {
+	tests := []struct {
+		ua   string
+		want string
+	}{
+		{
+			"Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) HeadlessChrome/131.0.0.0 Safari/537.36",
+			"Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/131.0.0.0 Safari/537.36",
+		},
+		{
+			"Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/131.0.0.0 Safari/537.36",
+			"Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/131.0.0.0 Safari/537.36",
+		},
+	}
+
+	for _, tt := range tests {
+		if got := agentbrowser.StealthUA(tt.ua); got != tt.want {
+			t.Errorf("StealthUA(%q) = %q, want %q", tt.ua, got, tt.want)
+		}
+	}
+}
+
+// TestStealthScript tests that the locale sets navigator.languages
+func TestStealthScript(t *testing.T) {
+	tests := []struct {
+		locale string
+		want   string
+	}{
+		{"", `["en-US","en"]`},
+		{"zh-CN", `["zh-CN","zh"]`},
+		{"fr", `["fr"]`},
+	}
+
+	for _, tt := range tests {
+		script := agentbrowser.StealthScript(tt.locale)
+		if !strings.Contains(script, "Object.freeze("+tt.want+")") {
+			t.Errorf("StealthScript(%q) does not set languages %s", tt.locale, tt.want)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package agentbrowser
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// StorageData holds Web Storage entries of the current page's origin.
+type StorageData struct {
+	Type   string            `json:"type"` // local or session
+	Origin string            `json:"origin"`
+	Items  map[string]string `json:"items"`
+}
+
+// storageScript runs one operation on localStorage or sessionStorage and
+// returns the origin and the entries. Opaque origins such as about:blank
+// throw on access, which is returned as error.
+const storageScript = `((area, op, key, value) => {
+	let storage;
+	try {
+		storage = window[area];
+	} catch (e) {
+		return { origin: location.origin, error: e.message };
+	}
+	switch (op) {
+	case "set":
+		storage.setItem(key, value);
+		break;
+	case "clear":
+		storage.clear();
+		break;
+	}
+	return { origin: location.origin, items: Object.entries(storage) };
+})(%s, %s, %s, %s)`
+
+// storageArea returns the window property of a storage type: local (the
+// default) or session.
+func storageArea(kind string) (string, error) {
+	switch kind {
+	case "", "local":
+		return "localStorage", nil
+	case "session":
+		return "sessionStorage", nil
+	}
+	return "", fmt.Errorf("unknown storage type %q (expected local or session)", kind)
+}
+
+// storage runs op on a storage area of the current page.
+func (m *BrowserManager) storage(kind, op, key, value string) (*StorageData, error) {
+	area, err := storageArea(kind)
+	if err != nil {
+		return nil, err
+	}
+	args := make([]any, 4)
+	for i, arg := range []string{area, op, key, value} {
+		args[i], _ = json.Marshal(arg)
+	}
+	result, err := m.backend.Evaluate(fmt.Sprintf(storageScript, args...))
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(result)
+	if err != nil {
+		return nil, err
+	}
+	var out struct {
+		Origin string     `json:"origin"`
+		Items  [][]string `json:"items"`
+		Error  string     `json:"error"`
+	}
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, err
+	}
+	if out.Error != "" {
+		return nil, fmt.Errorf("%s is not available on %s: %s", area, out.Origin, out.Error)
+	}
+
+	storage := &StorageData{Type: kind, Origin: out.Origin, Items: map[string]string{}}
+	if storage.Type == "" {
+		storage.Type = "local"
+	}
+	for _, item := range out.Items {
+		if len(item) == 2 {
+			storage.Items[item[0]] = item[1]
+		}
+	}
+	return storage, nil
+}
+
+// Storage returns the localStorage or sessionStorage entries of the current
+// page's origin, or only the entry for key when it is not empty.
+func (m *BrowserManager) Storage(kind, key string) (*StorageData, error) {
+	storage, err := m.storage(kind, "get", "", "")
+	if err != nil {
+		return nil, err
+	}
+	if key != "" {
+		value, ok := storage.Items[key]
+		if !ok {
+			return nil, fmt.Errorf("no %s storage entry %q on %s", storage.Type, key, storage.Origin)
+		}
+		storage.Items = map[string]string{key: value}
+	}
+	return storage, nil
+}
+
+// SetStorage writes a localStorage or sessionStorage entry for the current
+// page's origin.
+func (m *BrowserManager) SetStorage(kind, key, value string) error {
+	if key == "" {
+		return fmt.Errorf("storage key is required")
+	}
+	_, err := m.storage(kind, "set", key, value)
+	return err
+}
+
+// ClearStorage removes every localStorage or sessionStorage entry of the
+// current page's origin.
+func (m *BrowserManager) ClearStorage(kind string) error {
+	_, err := m.storage(kind, "clear", "", "")
+	return err
+}
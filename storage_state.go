@@ -0,0 +1,277 @@
+package agentbrowser
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/url"
+	"os"
+	"path/filepath"
+)
+
+// StorageState is the authentication state of a browser: its cookies and
+// the localStorage of the open origins. It uses the storageState.json
+// schema of Playwright, so states can be shared with Playwright and the
+// TypeScript agent-browser.
+type StorageState struct {
+	Cookies []StorageStateCookie `json:"cookies"`
+	Origins []StorageStateOrigin `json:"origins"`
+}
+
+// StorageStateCookie is a cookie in a StorageState.
+type StorageStateCookie struct {
+	Name     string  `json:"name"`
+	Value    string  `json:"value"`
+	Domain   string  `json:"domain"`
+	Path     string  `json:"path"`
+	Expires  float64 `json:"expires"` // Unix seconds, -1 for session cookies
+	HTTPOnly bool    `json:"httpOnly"`
+	Secure   bool    `json:"secure"`
+	SameSite string  `json:"sameSite"` // Strict, Lax or None
+}
+
+// StorageStateOrigin holds the localStorage of one origin.
+type StorageStateOrigin struct {
+	Origin       string      `json:"origin"`
+	LocalStorage []NameValue `json:"localStorage"`
+}
+
+// NameValue is a localStorage entry.
+type NameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// localStorageScript returns the current origin and its localStorage entries.
+const localStorageScript = `(() => {
+	try {
+		return { origin: location.origin, items: Object.entries(localStorage) };
+	} catch (e) {
+		return { origin: location.origin, items: [] };
+	}
+})()`
+
+// StorageState collects the browser's cookies and the localStorage of the
+// origins open in its tabs, as Playwright's context.storageState() does.
+func (m *BrowserManager) StorageState() (*StorageState, error) {
+	cookies, err := m.backend.GetCookies()
+	if err != nil {
+		return nil, err
+	}
+
+	state := &StorageState{
+		Cookies: make([]StorageStateCookie, len(cookies)),
+		Origins: []StorageStateOrigin{},
+	}
+	for i, c := range cookies {
+		sameSite := c.SameSite
+		if sameSite == "" {
+			sameSite = "Lax"
+		}
+		expires := float64(c.Expires)
+		if expires <= 0 {
+			expires = -1
+		}
+		state.Cookies[i] = StorageStateCookie{
+			Name:     c.Name,
+			Value:    c.Value,
+			Domain:   c.Domain,
+			Path:     c.Path,
+			Expires:  expires,
+			HTTPOnly: c.HTTPOnly,
+			Secure:   c.Secure,
+			SameSite: sameSite,
+		}
+	}
+
+	tabs, err := m.backend.ListTabs()
+	if err != nil {
+		return nil, err
+	}
+	active := -1
+	for _, tab := range tabs {
+		if tab.Active {
+			active = tab.Index
+		}
+	}
+
+	seen := make(map[string]bool)
+	for _, tab := range tabs {
+		// localStorage can only be read from a page of its origin
+		if len(tabs) > 1 {
+			if err := m.backend.SwitchTab(tab.Index); err != nil {
+				return nil, err
+			}
+		}
+		origin, err := m.localStorage()
+		if err != nil {
+			return nil, err
+		}
+		if origin != nil && !seen[origin.Origin] {
+			seen[origin.Origin] = true
+			state.Origins = append(state.Origins, *origin)
+		}
+	}
+	if len(tabs) > 1 && active >= 0 {
+		if err := m.backend.SwitchTab(active); err != nil {
+			return nil, err
+		}
+	}
+
+	return state, nil
+}
+
+// localStorage returns the localStorage of the active tab's origin, or nil
+// when it is empty or the origin is opaque.
+func (m *BrowserManager) localStorage() (*StorageStateOrigin, error) {
+	result, err := m.backend.Evaluate(localStorageScript)
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(result)
+	if err != nil {
+		return nil, err
+	}
+	var local struct {
+		Origin string     `json:"origin"`
+		Items  [][]string `json:"items"`
+	}
+	if err := json.Unmarshal(data, &local); err != nil {
+		return nil, err
+	}
+	// Opaque origins such as about:blank serialize as "null"
+	if local.Origin == "" || local.Origin == "null" || len(local.Items) == 0 {
+		return nil, nil
+	}
+
+	origin := &StorageStateOrigin{Origin: local.Origin, LocalStorage: []NameValue{}}
+	for _, item := range local.Items {
+		if len(item) == 2 {
+			origin.LocalStorage = append(origin.LocalStorage, NameValue{Name: item[0], Value: item[1]})
+		}
+	}
+	return origin, nil
+}
+
+// SaveStorageState writes the storage state to path. The file holds
+// session credentials, so it is only readable by the owner.
+func (m *BrowserManager) SaveStorageState(path string) error {
+	state, err := m.StorageState()
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			return fmt.Errorf("failed to create state directory: %w", err)
+		}
+	}
+	return os.WriteFile(path, data, 0600)
+}
+
+// ReadStorageState reads a storageState.json file, as written by
+// SaveStorageState or by Playwright.
+func ReadStorageState(path string) (*StorageState, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var state StorageState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("invalid storage state %s: %w", path, err)
+	}
+	return &state, nil
+}
+
+// LoadStorageState restores a storageState.json file: it adds the cookies
+// and writes the localStorage entries of each origin. Origins other than the
+// active tab's are opened briefly in a new tab to write their localStorage.
+func (m *BrowserManager) LoadStorageState(path string) (*StorageState, error) {
+	state, err := ReadStorageState(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(state.Cookies) > 0 {
+		cookies := make([]Cookie, len(state.Cookies))
+		for i, c := range state.Cookies {
+			cookies[i] = Cookie{
+				Name:     c.Name,
+				Value:    c.Value,
+				Domain:   c.Domain,
+				Path:     c.Path,
+				HTTPOnly: c.HTTPOnly,
+				Secure:   c.Secure,
+				SameSite: c.SameSite,
+			}
+			if c.Expires > 0 {
+				cookies[i].Expires = int64(math.Round(c.Expires))
+			}
+		}
+		if err := m.backend.SetCookies(cookies); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, origin := range state.Origins {
+		if len(origin.LocalStorage) > 0 {
+			if err := m.setLocalStorage(origin); err != nil {
+				return nil, fmt.Errorf("failed to restore localStorage of %s: %w", origin.Origin, err)
+			}
+		}
+	}
+	return state, nil
+}
+
+// setLocalStorage writes localStorage entries for an origin.
+func (m *BrowserManager) setLocalStorage(origin StorageStateOrigin) error {
+	entries, err := json.Marshal(origin.LocalStorage)
+	if err != nil {
+		return err
+	}
+	script := fmt.Sprintf(`((entries) => {
+	for (const { name, value } of entries) localStorage.setItem(name, value);
+})(%s)`, entries)
+
+	tabs, err := m.backend.ListTabs()
+	if err != nil {
+		return err
+	}
+	active := -1
+	for _, tab := range tabs {
+		if tab.Active {
+			active = tab.Index
+			if originOf(tab.URL) == origin.Origin {
+				_, err := m.backend.Evaluate(script)
+				return err
+			}
+		}
+	}
+
+	index, err := m.backend.NewTab(origin.Origin)
+	if err != nil {
+		return err
+	}
+	_, evalErr := m.backend.Evaluate(script)
+	if err := m.backend.CloseTab(index); err != nil && evalErr == nil {
+		evalErr = err
+	}
+	if active >= 0 {
+		if err := m.backend.SwitchTab(active); err != nil && evalErr == nil {
+			evalErr = err
+		}
+	}
+	return evalErr
+}
+
+// originOf returns the scheme://host[:port] origin of a URL.
+func originOf(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return ""
+	}
+	return u.Scheme + "://" + u.Host
+}
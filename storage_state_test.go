@@ -0,0 +1,184 @@
+package agentbrowser_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	agentbrowser "github.com/cpunion/agent-browser-go"
+)
+
+// browserTabs is the state of a fake browser whose tabs' localStorage is
+// keyed by origin.
+type browserTabs struct {
+	tabs    []string // tab URLs
+	active  int
+	storage map[string][][]string // origin -> entries
+	cookies []agentbrowser.Cookie
+	scripts []string // scripts evaluated, with the URL of the tab
+}
+
+// backend returns a fake backend over the tabs.
+func (b *browserTabs) backend() *fakeBackend {
+	return &fakeBackend{
+		listTabs: func() ([]agentbrowser.TabInfo, error) {
+			tabs := make([]agentbrowser.TabInfo, len(b.tabs))
+			for i, url := range b.tabs {
+				tabs[i] = agentbrowser.TabInfo{Index: i, URL: url, Active: i == b.active}
+			}
+			return tabs, nil
+		},
+		switchTab: func(index int) error {
+			b.active = index
+			return nil
+		},
+		newTab: func(url string) (int, error) {
+			b.tabs = append(b.tabs, url)
+			b.active = len(b.tabs) - 1
+			return b.active, nil
+		},
+		closeTab: func(index int) error {
+			b.tabs = append(b.tabs[:index], b.tabs[index+1:]...)
+			if b.active >= len(b.tabs) {
+				b.active = len(b.tabs) - 1
+			}
+			return nil
+		},
+		getCookies: func() ([]agentbrowser.Cookie, error) { return b.cookies, nil },
+		setCookies: func(cookies []agentbrowser.Cookie) error {
+			b.cookies = append(b.cookies, cookies...)
+			return nil
+		},
+		evaluate: func(script string) (interface{}, error) {
+			url := b.tabs[b.active]
+			origin := url
+			if i := strings.Index(url[len("https://"):], "/"); i >= 0 {
+				origin = url[:len("https://")+i]
+			}
+			if strings.Contains(script, "setItem") {
+				b.scripts = append(b.scripts, url)
+				return nil, nil
+			}
+			items := []interface{}{}
+			for _, kv := range b.storage[origin] {
+				items = append(items, []interface{}{kv[0], kv[1]})
+			}
+			return map[string]interface{}{"origin": origin, "items": items}, nil
+		},
+	}
+}
+
+// TestSaveStorageState tests that saved state uses Playwright's storageState.json layout
+func TestSaveStorageState(t *testing.T) {
+	browser := &browserTabs{
+		tabs:   []string{"https://a.example/page", "https://b.example/", "https://a.example/other"},
+		active: 1,
+		storage: map[string][][]string{
+			"https://a.example": {{"token", "a1"}},
+			"https://b.example": {{"theme", "dark"}},
+		},
+		cookies: []agentbrowser.Cookie{
+			{Name: "sid", Value: "abc", Domain: "a.example", Path: "/", Expires: 1900000000, HTTPOnly: true, Secure: true, SameSite: "Strict"},
+		},
+	}
+	m := agentbrowser.NewBrowserManagerForTest(browser.backend())
+
+	path := filepath.Join(t.TempDir(), "state.json")
+	if err := m.SaveStorageState(path); err != nil {
+		t.Fatalf("SaveStorageState() error = %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `{
+  "cookies": [
+    {
+      "name": "sid",
+      "value": "abc",
+      "domain": "a.example",
+      "path": "/",
+      "expires": 1900000000,
+      "httpOnly": true,
+      "secure": true,
+      "sameSite": "Strict"
+    }
+  ],
+  "origins": [
+    {
+      "origin": "https://a.example",
+      "localStorage": [
+        {
+          "name": "token",
+          "value": "a1"
+        }
+      ]
+    },
+    {
+      "origin": "https://b.example",
+      "localStorage": [
+        {
+          "name": "theme",
+          "value": "dark"
+        }
+      ]
+    }
+  ]
+}`
+	if string(data) != want {
+		t.Errorf("saved state =\n%s\nwant\n%s", data, want)
+	}
+	if browser.active != 1 {
+		t.Errorf("active tab = %d, want 1 restored", browser.active)
+	}
+}
+
+// TestLoadStorageState tests restoring a storageState.json written by Playwright
+func TestLoadStorageState(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "user.json")
+	data := `{
+  "cookies": [
+    {"name": "sid", "value": "abc", "domain": ".a.example", "path": "/", "expires": 1900000000.52,
+     "httpOnly": true, "secure": true, "sameSite": "Lax"},
+    {"name": "tmp", "value": "1", "domain": "a.example", "path": "/", "expires": -1,
+     "httpOnly": false, "secure": false, "sameSite": "None"}
+  ],
+  "origins": [
+    {"origin": "https://a.example", "localStorage": [{"name": "token", "value": "a1"}]},
+    {"origin": "https://b.example", "localStorage": [{"name": "theme", "value": "dark"}]}
+  ]
+}`
+	if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	browser := &browserTabs{tabs: []string{"https://a.example/home"}}
+	m := agentbrowser.NewBrowserManagerForTest(browser.backend())
+	state, err := m.LoadStorageState(path)
+	if err != nil {
+		t.Fatalf("LoadStorageState() error = %v", err)
+	}
+	if len(state.Cookies) != 2 || len(state.Origins) != 2 {
+		t.Errorf("LoadStorageState() = %+v", state)
+	}
+
+	wantCookies := []agentbrowser.Cookie{
+		{Name: "sid", Value: "abc", Domain: ".a.example", Path: "/", Expires: 1900000001, HTTPOnly: true, Secure: true, SameSite: "Lax"},
+		{Name: "tmp", Value: "1", Domain: "a.example", Path: "/", SameSite: "None"},
+	}
+	if !reflect.DeepEqual(browser.cookies, wantCookies) {
+		t.Errorf("cookies = %+v, want %+v", browser.cookies, wantCookies)
+	}
+
+	// The current tab's origin is written in place, the other in a temporary tab
+	wantScripts := []string{"https://a.example/home", "https://b.example"}
+	if !reflect.DeepEqual(browser.scripts, wantScripts) {
+		t.Errorf("localStorage written in %q, want %q", browser.scripts, wantScripts)
+	}
+	if len(browser.tabs) != 1 || browser.active != 0 {
+		t.Errorf("tabs = %q (active %d), want the original tab only", browser.tabs, browser.active)
+	}
+}
@@ -0,0 +1,85 @@
+package agentbrowser_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	agentbrowser "github.com/cpunion/agent-browser-go"
+)
+
+// storageBackend is a fake backend whose storage scripts return *result and
+// are appended to *scripts.
+func storageBackend(result *map[string]interface{}, scripts *[]string) *fakeBackend {
+	return &fakeBackend{
+		evaluate: func(script string) (interface{}, error) {
+			*scripts = append(*scripts, script)
+			return *result, nil
+		},
+	}
+}
+
+// TestStorage tests reading storage entries and the storage type
+func TestStorage(t *testing.T) {
+	result := map[string]interface{}{
+		"origin": "https://example.com",
+		"items":  []interface{}{[]interface{}{"theme", "dark"}, []interface{}{"token", "abc"}},
+	}
+	var scripts []string
+	m := agentbrowser.NewBrowserManagerForTest(storageBackend(&result, &scripts))
+
+	got, err := m.Storage("", "")
+	if err != nil {
+		t.Fatalf("Storage() error = %v", err)
+	}
+	want := &agentbrowser.StorageData{
+		Type:   "local",
+		Origin: "https://example.com",
+		Items:  map[string]string{"theme": "dark", "token": "abc"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Storage() = %+v, want %+v", got, want)
+	}
+
+	got, err = m.Storage("session", "theme")
+	if err != nil {
+		t.Fatalf("Storage(session, theme) error = %v", err)
+	}
+	if got.Type != "session" || !reflect.DeepEqual(got.Items, map[string]string{"theme": "dark"}) {
+		t.Errorf("Storage(session, theme) = %+v, want the theme entry of sessionStorage", got)
+	}
+	if script := scripts[len(scripts)-1]; !strings.Contains(script, `"sessionStorage"`) {
+		t.Errorf("Storage(session) ran %s, want sessionStorage", script)
+	}
+
+	if _, err := m.Storage("", "missing"); err == nil {
+		t.Error("Storage(missing) expected an error")
+	}
+	if _, err := m.Storage("cookie", ""); err == nil {
+		t.Error("Storage(cookie) expected an error for an unknown type")
+	}
+	if err := m.SetStorage("", "", "value"); err == nil {
+		t.Error("SetStorage() without a key expected an error")
+	}
+
+	result = map[string]interface{}{"origin": "null", "error": "Access is denied for this document."}
+	if err := m.ClearStorage(""); err == nil || !strings.Contains(err.Error(), "localStorage is not available") {
+		t.Errorf("ClearStorage() on an opaque origin error = %v", err)
+	}
+}
+
+// TestStorageQuotesArguments tests that keys and values reach the script as
+// JSON strings
+func TestStorageQuotesArguments(t *testing.T) {
+	result := map[string]interface{}{"origin": "https://example.com"}
+	var scripts []string
+	m := agentbrowser.NewBrowserManagerForTest(storageBackend(&result, &scripts))
+
+	if err := m.SetStorage("", `a"b`, "line\nbreak"); err != nil {
+		t.Fatalf("SetStorage() error = %v", err)
+	}
+	script := scripts[0]
+	if !strings.Contains(script, `"set", "a\"b", "line\nbreak")`) {
+		t.Errorf("SetStorage() ran %s, want quoted arguments", script)
+	}
+}
@@ -0,0 +1,183 @@
+package agentbrowser
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// ClientCertificate is a TLS client certificate presented to one origin.
+// Give either a PEM certificate and key or a PFX/PKCS12 bundle.
+type ClientCertificate struct {
+	Origin     string `json:"origin"` // e.g. https://internal.example.com:8443
+	CertPath   string `json:"certPath,omitempty"`
+	KeyPath    string `json:"keyPath,omitempty"`
+	PfxPath    string `json:"pfxPath,omitempty"`
+	Passphrase string `json:"passphrase,omitempty"`
+}
+
+// TLSOptions configures certificate handling for a browser.
+type TLSOptions struct {
+	IgnoreHTTPSErrors  bool                `json:"ignoreHTTPSErrors,omitempty"` // accept self-signed and invalid certificates
+	ClientCertificates []ClientCertificate `json:"clientCertificates,omitempty"`
+}
+
+// Validate checks that the certificate names an origin and exactly one of
+// a PEM pair or a PFX bundle.
+func (c ClientCertificate) Validate() error {
+	if !strings.HasPrefix(c.Origin, "https://") {
+		return fmt.Errorf("client certificate origin must start with https://: %q", c.Origin)
+	}
+	pem := c.CertPath != "" || c.KeyPath != ""
+	switch {
+	case pem && c.PfxPath != "":
+		return fmt.Errorf("client certificate for %s: give cert and key, or pfx, not both", c.Origin)
+	case pem && (c.CertPath == "" || c.KeyPath == ""):
+		return fmt.Errorf("client certificate for %s: cert and key are both required", c.Origin)
+	case !pem && c.PfxPath == "":
+		return fmt.Errorf("client certificate for %s: cert and key, or pfx, is required", c.Origin)
+	}
+	return nil
+}
+
+// ParseClientCertificate parses a comma-separated key=value certificate
+// spec with keys origin, cert, key, pfx and passphrase, e.g.
+// "origin=https://intranet.example.com,cert=client.pem,key=client.key".
+func ParseClientCertificate(spec string) (ClientCertificate, error) {
+	var c ClientCertificate
+	for _, part := range strings.Split(spec, ",") {
+		key, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return c, fmt.Errorf("invalid client certificate %q: expected key=value pairs", spec)
+		}
+		switch strings.TrimSpace(key) {
+		case "origin":
+			c.Origin = value
+		case "cert":
+			c.CertPath = value
+		case "key":
+			c.KeyPath = value
+		case "pfx":
+			c.PfxPath = value
+		case "passphrase":
+			c.Passphrase = value
+		default:
+			return c, fmt.Errorf("invalid client certificate %q: unknown key %q", spec, key)
+		}
+	}
+	return c, c.Validate()
+}
+
+// sameTLS reports whether two TLS configurations are equal.
+func sameTLS(a, b TLSOptions) bool {
+	if len(a.ClientCertificates) == 0 && len(b.ClientCertificates) == 0 {
+		return a.IgnoreHTTPSErrors == b.IgnoreHTTPSErrors
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// certificateOrigin returns the origin a client certificate is presented
+// to for a URL or an origin, without the default port.
+func certificateOrigin(rawURL string) string {
+	return strings.TrimSuffix(strings.ToLower(originOf(rawURL)), ":443")
+}
+
+// clientCertificateClients returns HTTP clients presenting the launch
+// options' client certificates, by origin, for browsers that cannot present
+// them themselves. The clients reach the origins as the browser would:
+// through the proxy unless it is bypassed, and with the host rules applied.
+// They leave redirects to the browser. Only PEM certificates with
+// unencrypted keys are loaded.
+func clientCertificateClients(opts LaunchOptions) (map[string]*http.Client, error) {
+	certs := opts.TLS.ClientCertificates
+	if len(certs) == 0 {
+		return nil, nil
+	}
+	proxy, err := opts.Proxy.transportProxy()
+	if err != nil {
+		return nil, err
+	}
+	dial := hostRulesDialer(opts.HostRules)
+
+	clients := make(map[string]*http.Client, len(certs))
+	for _, c := range certs {
+		if c.PfxPath != "" || c.Passphrase != "" {
+			return nil, fmt.Errorf("client certificate for %s: PFX bundles and encrypted keys require the playwright backend (--backend playwright)", c.Origin)
+		}
+		cert, err := tls.LoadX509KeyPair(c.CertPath, c.KeyPath)
+		if err != nil {
+			return nil, fmt.Errorf("client certificate for %s: %w", c.Origin, err)
+		}
+		clients[certificateOrigin(c.Origin)] = &http.Client{
+			Transport: &http.Transport{
+				TLSClientConfig: &tls.Config{
+					Certificates:       []tls.Certificate{cert},
+					InsecureSkipVerify: opts.TLS.IgnoreHTTPSErrors,
+				},
+				Proxy:             proxy,
+				DialContext:       dial,
+				ForceAttemptHTTP2: true,
+			},
+			CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+		}
+	}
+	return clients, nil
+}
+
+// playwrightClientCertificates converts certificates to Playwright options.
+func playwrightClientCertificates(certs []ClientCertificate) []playwright.ClientCertificate {
+	optional := func(s string) *string {
+		if s == "" {
+			return nil
+		}
+		return playwright.String(s)
+	}
+
+	out := make([]playwright.ClientCertificate, len(certs))
+	for i, c := range certs {
+		out[i] = playwright.ClientCertificate{
+			Origin:     c.Origin,
+			CertPath:   optional(c.CertPath),
+			KeyPath:    optional(c.KeyPath),
+			PfxPath:    optional(c.PfxPath),
+			Passphrase: optional(c.Passphrase),
+		}
+	}
+	return out
+}
+
+// GetTLSFile returns the TLS settings file path for a session.
+func GetTLSFile(session string) string {
+	dir := filepath.Join(os.TempDir(), "agent-browser-go")
+	_ = os.MkdirAll(dir, 0755)
+	return filepath.Join(dir, fmt.Sprintf("%s.tls", session))
+}
+
+// SaveSessionTLS saves the TLS settings used when the session's browser
+// launches. The file may hold passphrases, so only the owner can read it.
+func SaveSessionTLS(session string, opts TLSOptions) error {
+	data, err := json.Marshal(opts)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(GetTLSFile(session), data, 0600)
+}
+
+// GetSessionTLS retrieves the saved TLS settings for a session.
+// Returns the zero value if none are saved.
+func GetSessionTLS(session string) TLSOptions {
+	var opts TLSOptions
+	data, err := os.ReadFile(GetTLSFile(session))
+	if err != nil {
+		return opts
+	}
+	_ = json.Unmarshal(data, &opts)
+	return opts
+}
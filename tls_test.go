@@ -0,0 +1,273 @@
+package agentbrowser_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	agentbrowser "github.com/cpunion/agent-browser-go"
+)
+
+// TestParseClientCertificate tests parsing of --client-cert specs
+func TestParseClientCertificate(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    agentbrowser.ClientCertificate
+		wantErr bool
+	}{
+		{
+			spec: "origin=https://intranet.example.com,cert=client.pem,key=client.key",
+			want: agentbrowser.ClientCertificate{Origin: "https://intranet.example.com", CertPath: "client.pem", KeyPath: "client.key"},
+		},
+		{
+			spec: "origin=https://vpn.example.com:8443,pfx=client.p12,passphrase=a=b",
+			want: agentbrowser.ClientCertificate{Origin: "https://vpn.example.com:8443", PfxPath: "client.p12", Passphrase: "a=b"},
+		},
+		{spec: "origin=http://example.com,pfx=c.p12", wantErr: true},                       // not https
+		{spec: "origin=https://example.com,cert=c.pem", wantErr: true},                     // key missing
+		{spec: "origin=https://example.com", wantErr: true},                                // no certificate
+		{spec: "origin=https://example.com,cert=c.pem,key=k.pem,pfx=c.p12", wantErr: true}, // both kinds
+		{spec: "origin=https://example.com,pem=c.pem", wantErr: true},                      // unknown key
+		{spec: "https://example.com", wantErr: true},                                       // not key=value
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			got, err := agentbrowser.ParseClientCertificate(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseClientCertificate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("ParseClientCertificate() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestSessionTLS tests saving and reading a session's TLS settings
+func TestSessionTLS(t *testing.T) {
+	session := "test-tls-" + t.Name()
+	t.Cleanup(func() { os.Remove(agentbrowser.GetTLSFile(session)) })
+	opts := agentbrowser.TLSOptions{
+		IgnoreHTTPSErrors: true,
+		ClientCertificates: []agentbrowser.ClientCertificate{
+			{Origin: "https://intranet.example.com", CertPath: "/certs/client.pem", KeyPath: "/certs/client.key"},
+		},
+	}
+	if err := agentbrowser.SaveSessionTLS(session, opts); err != nil {
+		t.Fatalf("SaveSessionTLS() error = %v", err)
+	}
+	if got := agentbrowser.GetSessionTLS(session); !reflect.DeepEqual(got, opts) {
+		t.Errorf("GetSessionTLS() = %+v, want %+v", got, opts)
+	}
+
+	if err := agentbrowser.SaveSessionTLS(session, agentbrowser.TLSOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if got := agentbrowser.GetSessionTLS(session); !reflect.DeepEqual(got, agentbrowser.TLSOptions{}) {
+		t.Errorf("GetSessionTLS() after reset = %+v, want zero", got)
+	}
+	if got := agentbrowser.GetSessionTLS("test-tls-missing"); !reflect.DeepEqual(got, agentbrowser.TLSOptions{}) {
+		t.Errorf("GetSessionTLS(missing) = %+v, want zero", got)
+	}
+}
+
+// writeClientCertificate writes a self-signed PEM certificate for cn and
+// its key, and returns their paths.
+func writeClientCertificate(t *testing.T, cn string) (certPath, keyPath string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: cn},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	certPath, keyPath = filepath.Join(dir, "client.pem"), filepath.Join(dir, "client.key")
+	if err := os.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return certPath, keyPath
+}
+
+// TestClientCertificateClients tests that the clients browsers without
+// client certificate support use present the certificate of their origin
+func TestClientCertificateClients(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.TLS.PeerCertificates[0].Subject.CommonName))
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	server.StartTLS()
+	defer server.Close()
+
+	certPath, keyPath := writeClientCertificate(t, "agent")
+	clients, err := agentbrowser.CertClients(agentbrowser.LaunchOptions{TLS: agentbrowser.TLSOptions{
+		IgnoreHTTPSErrors:  true,
+		ClientCertificates: []agentbrowser.ClientCertificate{{Origin: strings.ToUpper(server.URL) + "/", CertPath: certPath, KeyPath: keyPath}},
+	}})
+	if err != nil {
+		t.Fatalf("CertClients() error = %v", err)
+	}
+	client := clients[server.URL]
+	if client == nil {
+		t.Fatalf("CertClients() = %v, want a client for %s", clients, server.URL)
+	}
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("GET with the certificate error = %v", err)
+	}
+	defer resp.Body.Close()
+	var body strings.Builder
+	if _, err := io.Copy(&body, resp.Body); err != nil || body.String() != "agent" {
+		t.Errorf("server saw certificate %q (%v), want agent", body.String(), err)
+	}
+
+	_, err = agentbrowser.CertClients(agentbrowser.LaunchOptions{TLS: agentbrowser.TLSOptions{
+		ClientCertificates: []agentbrowser.ClientCertificate{{Origin: server.URL, PfxPath: "client.p12"}},
+	}})
+	if err == nil || !strings.Contains(err.Error(), "playwright") {
+		t.Errorf("CertClients(pfx) error = %v, want the playwright backend required", err)
+	}
+}
+
+// TestClientCertificateClientsRoute tests that certificate origins are
+// reached as the browser reaches them: through the session proxy with its
+// credentials unless bypassed, and with the host rules applied
+func TestClientCertificateClientsRoute(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.TLS.PeerCertificates[0].Subject.CommonName))
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	server.StartTLS()
+	defer server.Close()
+	_, port, _ := net.SplitHostPort(server.Listener.Addr().String())
+	origin := "https://certs.test:" + port
+
+	var (
+		tunnelsLock sync.Mutex
+		tunnels     []string
+	)
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodConnect {
+			http.Error(w, "CONNECT only", http.StatusMethodNotAllowed)
+			return
+		}
+		tunnelsLock.Lock()
+		tunnels = append(tunnels, r.Host+" "+r.Header.Get("Proxy-Authorization"))
+		tunnelsLock.Unlock()
+		upstream, err := net.Dial("tcp", server.Listener.Addr().String())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		conn, _, err := http.NewResponseController(w).Hijack()
+		if err != nil {
+			upstream.Close()
+			return
+		}
+		go func() {
+			io.Copy(upstream, conn)
+			upstream.Close()
+		}()
+		io.Copy(conn, upstream)
+		conn.Close()
+	}))
+	defer proxy.Close()
+	takeTunnels := func() []string {
+		tunnelsLock.Lock()
+		defer tunnelsLock.Unlock()
+		seen := tunnels
+		tunnels = nil
+		return seen
+	}
+
+	certPath, keyPath := writeClientCertificate(t, "agent")
+	get := func(opts agentbrowser.LaunchOptions) error {
+		opts.TLS = agentbrowser.TLSOptions{
+			IgnoreHTTPSErrors:  true,
+			ClientCertificates: []agentbrowser.ClientCertificate{{Origin: origin, CertPath: certPath, KeyPath: keyPath}},
+		}
+		clients, err := agentbrowser.CertClients(opts)
+		if err != nil {
+			return err
+		}
+		resp, err := clients[origin].Get(origin)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		if err == nil && string(body) != "agent" {
+			err = fmt.Errorf("server saw certificate %q, want agent", body)
+		}
+		return err
+	}
+
+	proxied := &agentbrowser.ProxyOptions{Server: proxy.URL, Username: "user", Password: "pass"}
+	if err := get(agentbrowser.LaunchOptions{Proxy: proxied}); err != nil {
+		t.Fatalf("GET through the proxy error = %v", err)
+	}
+	wantAuth := "Basic " + base64.StdEncoding.EncodeToString([]byte("user:pass"))
+	if got, want := takeTunnels(), []string{"certs.test:" + port + " " + wantAuth}; !reflect.DeepEqual(got, want) {
+		t.Errorf("proxy tunnels = %q, want %q", got, want)
+	}
+
+	bypassed := &agentbrowser.ProxyOptions{Server: proxy.URL, Bypass: []string{"*.test"}}
+	err := get(agentbrowser.LaunchOptions{Proxy: bypassed, HostRules: []string{"MAP certs.test 127.0.0.1"}})
+	if err != nil {
+		t.Fatalf("GET bypassing the proxy with a host rule error = %v", err)
+	}
+	if got := takeTunnels(); len(got) != 0 {
+		t.Errorf("proxy tunnels = %q, want the bypassed origin reached directly", got)
+	}
+
+	err = get(agentbrowser.LaunchOptions{HostRules: []string{"MAP *.test ~NOTFOUND"}})
+	if err == nil {
+		t.Error("GET with the origin mapped to ~NOTFOUND succeeded, want a lookup error")
+	}
+
+	_, err = agentbrowser.CertClients(agentbrowser.LaunchOptions{
+		Proxy: &agentbrowser.ProxyOptions{Server: "socks4://127.0.0.1:1080"},
+		TLS: agentbrowser.TLSOptions{
+			ClientCertificates: []agentbrowser.ClientCertificate{{Origin: origin, CertPath: certPath, KeyPath: keyPath}},
+		},
+	})
+	if err == nil || !strings.Contains(err.Error(), "socks4") {
+		t.Errorf("CertClients(socks4 proxy) error = %v, want socks4 rejected", err)
+	}
+}
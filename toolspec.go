@@ -0,0 +1,480 @@
+package agentbrowser
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// ToolSpec describes a protocol command as an LLM-callable tool.
+type ToolSpec struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	Parameters  map[string]interface{} `json:"parameters"`
+}
+
+// toolCommands lists the commands exposed as tools, in presentation order.
+// Parameter schemas are derived from the command types returned by
+// ParseCommand, so the tool definitions always match the wire protocol.
+var toolCommands = []struct {
+	Action      string
+	Description string
+}{
+	{"navigate", "Navigate the current tab to a URL and return the final URL and page title. A 'challenge' field reports a CAPTCHA, bot-check interstitial or login wall on the loaded page."},
+	{"back", "Go back in the current tab's history."},
+	{"forward", "Go forward in the current tab's history."},
+	{"reload", "Reload the current page."},
+	{"history", "List the current tab's navigation history: each entry's index, URL and title, and the index of the current entry."},
+	{"history_go", "Jump straight to the history entry at the given index, instead of repeating back or forward."},
+	{"snapshot", "Get the accessibility tree of the page. Interactive elements carry refs like [ref=e1] that can be passed as selectors (@e1) to other tools."},
+	{"getbyrole", "Find the first element with an ARIA role (button, link, textbox, checkbox, heading, ...) whose accessible name contains name, as a screen reader announces it, and run subaction on it. Use when there is no snapshot ref and the markup has no stable selector."},
+	{"getbytext", "Find the innermost element whose visible text contains text (or equals it, with exact) and run subaction on it, e.g. click a link or button by its label. Prefers visible elements."},
+	{"getbylabel", "Find the form control labelled label, by a <label> (for= or wrapping), aria-labelledby or aria-label, and run subaction on it, e.g. fill an input by the label shown next to it."},
+	{"getbyplaceholder", "Find the first input whose placeholder contains placeholder and run subaction on it, e.g. fill a search box that has no label."},
+	{"getbyalttext", "Find the first element, usually an image, whose alt text contains text and run subaction on it, e.g. click a logo or icon link."},
+	{"getbytitle", "Find the first element whose title (tooltip) contains text and run subaction on it, e.g. click an icon button that only has a tooltip."},
+	{"getbytestid", "Find the element whose test id (data-testid, or the attribute the session config names) is testId and run subaction on it. Test ids are stable across restyling, so prefer them when a page has them."},
+	{"click", "Click an element."},
+	{"dblclick", "Double-click an element."},
+	{"type", "Type text into an element, keystroke by keystroke."},
+	{"inserttext", "Insert text at the caret of an element (or of the focused element) as one input event, like an IME commit. Use for emoji, CJK and other text that type would split; existing content is kept."},
+	{"fill", "Clear an input and fill it with a value."},
+	{"setvalue", "Set an input's value directly, without focusing it or firing key events."},
+	{"press", "Press a key (Enter, Tab, Control+a), optionally focusing an element first."},
+	{"keyboard", "Press key combos in the focused element, e.g. Control+Shift+K or Meta+Shift+P; several separated by spaces are pressed in turn, like \"Control+a Delete\"."},
+	{"keydown", "Hold a key down in the focused element until keyup releases it, e.g. Shift while clicking."},
+	{"keyup", "Release a key held with keydown."},
+	{"hover", "Hover the mouse over an element."},
+	{"focus", "Focus an element."},
+	{"tap", "Tap an element with touch events, for mobile pages that only respond to touch."},
+	{"mousemove", "Move the mouse to viewport coordinates, in steps equal moves for pages that follow the path (drawing, sliders). A button held with mousedown drags."},
+	{"mousedown", "Press a mouse button where the mouse is, e.g. to start a drag; it stays held until mouseup."},
+	{"mouseup", "Release a mouse button where the mouse is, ending a drag or completing a click."},
+	{"clipboard", "Use the clipboard: copy puts text on it, read returns its text (e.g. after clicking a page's copy button), paste pastes its text into an element with a real paste event."},
+	{"input_keyboard", "Send one raw key event to the focused element, for games and editors that react to keys being held: keyDown and keyUp of a key, or char to type text. Modifier keys stay held from keyDown until keyUp and apply to later key and mouse events."},
+	{"input_mouse", "Send one raw mouse event at viewport coordinates, for canvas apps, maps and games without elements to select. A button pressed with mousePressed stays held until mouseReleased, so press, move and release drag."},
+	{"dispatch", "Fire a DOM event on an element, e.g. input or change after setting a value so React/Vue notice it, blur to trigger validation, or a custom event with eventInit.detail."},
+	{"selectall", "Focus an element and select all its text (Ctrl+A), so the next type replaces the existing content."},
+	{"highlight", "Outline an element in the page for a few seconds, so a person watching the browser can see what you are about to act on."},
+	{"check", "Check a checkbox or radio button."},
+	{"uncheck", "Uncheck a checkbox."},
+	{"upload", "Set the files of a file input (<input type=file>) to local files, as if the user picked them."},
+	{"select", "Select option(s) in a <select> element by value, visible label or 0-based index, firing its change event. Several values need a <select multiple>."},
+	{"clear", "Clear an input."},
+	{"scroll", "Scroll the page in a direction, or with toEnd keep scrolling an infinite-scroll page until no new content loads and report how much was loaded."},
+	{"wheel", "Turn the mouse wheel over an element (or where the mouse is) to scroll by deltaX/deltaY pixels with real wheel events. Use for virtualized lists, map widgets and custom scroll areas that ignore scroll."},
+	{"scrollintoview", "Scroll an element into view."},
+	{"wait", "Wait for an element to reach a state, or for a number of milliseconds when no selector is given."},
+	{"waitforurl", "Wait until the page URL matches a pattern, e.g. the redirect to **/dashboard after submitting a login form. Globs match the whole URL (* within a path segment, ** across them); /regex/ matches anywhere."},
+	{"waitforloadstate", "Wait until the page reaches a load state instead of sleeping: domcontentloaded, load, or networkidle (no requests for 500ms, e.g. after a single-page app fetches its data). Returns at once if the page is already there."},
+	{"gettext", "Get the text content of an element."},
+	{"innertext", "Get the rendered text of an element, as the user sees it."},
+	{"innerhtml", "Get the inner HTML of an element."},
+	{"text", "Read the page's main content, or an element's, as Markdown (or plain text with plain), without scripts, navigation and other boilerplate. Far fewer tokens than HTML or a snapshot for reading an article."},
+	{"forms", "List the page's forms and the fields to fill in them: each field's ref, name, type, label, current value, whether it is required and a select's options. A structured view of what needs filling, smaller than a snapshot."},
+	{"fill_form", "Fill several form fields in one call. fields maps each field, by label, name, selector or ref, to its value: text for inputs, true/false for checkboxes and radio buttons, an option value or label (or a list of them) for selects. Fields are filled in order; one that fails does not stop the others."},
+	{"links", "List the links of the page, or of an element, with their text, absolute URL and snapshot ref (to click them), in document order."},
+	{"inputvalue", "Get the current value of an input."},
+	{"getattribute", "Get an attribute value of an element."},
+	{"isvisible", "Check whether an element is visible."},
+	{"isenabled", "Check whether an element is enabled."},
+	{"ischecked", "Check whether a checkbox is checked."},
+	{"count", "Count elements matching a selector."},
+	{"boundingbox", "Get the bounding box of an element."},
+	{"describe", "Describe one element: role, accessible name, states, value, attributes, bounding box, visibility and a text excerpt. Cheaper than a full snapshot when inspecting a single element."},
+	{"challenge", "Check the current page for a CAPTCHA, bot-check interstitial or login wall. Returns null when the page is clear."},
+	{"perf", "Report the current page's navigation timing, resource counts and sizes, Core Web Vitals (FCP, LCP, CLS, FID, INP approximation) and browser performance metrics."},
+	{"console", "List the console messages (log, warn, error, ...) logged by pages since launch, oldest first, with type and timestamp. Use clear to empty the log after reading it."},
+	{"errors", "List the uncaught exceptions thrown in pages since launch, oldest first, with message, JavaScript stack and timestamp. Use clear to empty the log after reading it."},
+	{"requests", "List the network requests made since launch, oldest first: URL, method, resource type, status, response time, duration and any failure. Filter by resource type (xhr, fetch, document, ...) or a URL substring."},
+	{"stats", "Report memory use: JS heap, DOM node and event listener counts per tab, and resident memory per browser process. Optionally saves a heap snapshot of the active tab."},
+	{"audit", "Score the current page: page weight, request count, render-blocking resources, image formats, meta/SEO basics, image alt text, mixed content and console errors. Each check passes, warns or fails."},
+	{"url", "Get the current page URL."},
+	{"title", "Get the current page title."},
+	{"content", "Get the HTML of the page, or of an element when a selector is given."},
+	{"screenshot", "Take a screenshot. Returns base64 data unless a path is given."},
+	{"pdf", "Print the current page to PDF with print CSS. Returns base64 data unless a path is given. Headless only."},
+	{"trace_start", "Start recording a trace of the session to debug a run offline: a Playwright trace viewer zip, or a Chrome trace of the current tab with chromedp."},
+	{"trace_stop", "Stop the trace and save it to a file. Returns the path, size and format."},
+	{"record_start", "Start recording a video of the active tab, to watch a run afterwards. Needs ffmpeg."},
+	{"record_stop", "Stop recording and save the video. Returns its path, frame count and duration in seconds."},
+	{"evaluate", "Run JavaScript in the page and return the result."},
+	{"addscript", "Add a <script> to the current page from a URL or inline content, e.g. helper functions to call from evaluate. Removed by navigation."},
+	{"addstyle", "Add a stylesheet to the current page from a URL or inline CSS, e.g. to hide overlays before a screenshot. Removed by navigation."},
+	{"addinitscript", "Register JavaScript that runs before page scripts in every document loaded from now on, in every tab, e.g. to stub APIs. Kept for the session; reload to apply it to the current page."},
+	{"setcontent", "Replace the current page's HTML, e.g. to render and inspect a generated email or template."},
+	{"paginate", "Follow a next-page link or button from the current page, extracting records from each page with an extract spec: item selects one element per record and fields map keys to selectors within it ('h3' for text, 'a@href' for an attribute). Returns all records and the visited URLs."},
+	{"crawl", "Crawl pages breadth-first from a URL, following links up to depth levels, and return each page's URL, title, status and links (and content as Markdown with markdown)."},
+	{"sitemap", "List the page URLs of a sitemap, or of the current site's sitemaps when url is empty."},
+	{"cookies_get", "List the browser's cookies, or only those sent with a request to one of the given URLs."},
+	{"cookies_set", "Add cookies, e.g. an auth session cookie before navigating. Each cookie needs name and value, and url or domain (default: the current page)."},
+	{"cookies_clear", "Delete all cookies."},
+	{"clear_data", "Clear the selected kinds of browsing data: cache, cookies, and the storage of the origins open in the tabs."},
+	{"storage_get", "Read the localStorage (or sessionStorage) entries of the current page's origin, or one entry by key."},
+	{"storage_set", "Write a localStorage (or sessionStorage) entry for the current page's origin, e.g. a feature flag or saved token."},
+	{"storage_clear", "Remove every localStorage (or sessionStorage) entry of the current page's origin."},
+	{"sw_list", "List the current origin's service workers with their scope, script and state."},
+	{"sw_unregister", "Unregister the current origin's service workers, or only the one with the given scope."},
+	{"fetch", "Send an HTTP request from the page with its cookies and session, e.g. to call the site's own API. Returns status, headers and body; JSON bodies are parsed."},
+	{"tab_list", "List open tabs."},
+	{"tab_new", "Open a new tab, optionally navigating to a URL."},
+	{"window_new", "Open a tab in a new browser window and make it active, optionally with its own viewport size. tab_list reports the window each tab is in."},
+	{"tab_switch", "Switch to the tab at the given index."},
+	{"tab_close", "Close the tab at the given index, or the active tab."},
+	{"pause", "Hand the headed browser to a person, e.g. to solve a CAPTCHA or enter a 2FA code. Later actions wait until the person runs resume."},
+	{"bringtofront", "Raise the active tab's window and show the tab in it. Switching tabs only changes which tab actions apply to; use this to see it in a headed browser."},
+	{"frame", "Scope later selectors and scripts to an iframe, e.g. an embedded login or payment form: by a selector for the iframe element, its name, or its URL (exact, else a substring). Selectors nest into frames within the current one."},
+	{"mainframe", "Scope selectors and scripts to the top document again after frame."},
+	{"useragent", "Change the user agent in every tab, with matching navigator.platform and client hints. Takes effect on the next request; an empty userAgent restores the launch user agent."},
+	{"locale", "Change the locale in every tab: navigator.language, Intl number and date formatting and the Accept-Language header, e.g. de-DE. Takes effect on the next navigation; an empty locale removes the override."},
+	{"credentials", "Answer HTTP authentication challenges (basic, digest, NTLM) in every tab, e.g. for intranet pages or an authenticating proxy. Set clear to remove them, so challenges are cancelled and the 401 page loads."},
+	{"headers", "Send extra HTTP headers, e.g. Authorization or X-Request-ID, with every request of every tab, replacing those set before. An empty headers object removes them."},
+	{"emulatemedia", "Emulate the CSS media type (screen, print) and user preferences (dark color scheme, reduced motion, forced colors) in every tab, e.g. for dark mode screenshots or print layouts. Omitted fields keep their value; no-override removes one."},
+	{"dialog", "Choose how JavaScript alerts, confirms and prompts opened from now on are answered: accept (entering promptText in prompts) or dismiss. An empty response restores the default, which depends on the backend: playwright dismisses them, chromedp leaves them open and blocking the page. Action results report dialogs either way."},
+	{"offline", "Cut every tab off the network, e.g. to test offline-first apps and service worker fallbacks; navigator.onLine becomes false. Set offline false to go back online."},
+	{"geolocation", "Report a position to the page's Geolocation API in every tab and grant the geolocation permission, e.g. to test location-aware pages. Set clear to remove the override."},
+	{"permissions", "Grant or deny browser permissions (geolocation, notifications, camera, microphone, clipboard, clipboard-read, clipboard-write, midi, ...) to an origin, or to every origin, so pages get them without a prompt. Set reset to drop all grants and denials."},
+	{"viewport", "Set the viewport size."},
+	{"close", "Close the browser."},
+}
+
+// fieldDescriptions documents parameters shared across commands, and
+// parameters of one command keyed by "action.field".
+var fieldDescriptions = map[string]string{
+	"selector":        "CSS selector or snapshot ref (e.g. @e1)",
+	"url":             "URL",
+	"text":            "Text",
+	"value":           "Value",
+	"values":          "Option values",
+	"key":             "Key name, e.g. Enter, Tab, ArrowDown or a combo like Control+a",
+	"script":          "JavaScript expression to evaluate",
+	"html":            "HTML document to render",
+	"timeout":         "Timeout in milliseconds",
+	"index":           "Tab or history entry index (0-based)",
+	"path":            "File path",
+	"files":           "Absolute paths of the files to upload",
+	"landscape":       "Landscape orientation",
+	"printBackground": "Print background colors and images",
+	"margin":          "Page margins as CSS lengths (px, in, cm or mm)",
+	"interactive":     "Only include interactive elements",
+	"compact":         "Remove empty structural elements",
+	"maxDepth":        "Maximum tree depth (0 = unlimited)",
+	"fullPage":        "Capture the full scrollable page",
+	"attribute":       "Attribute name",
+	"amount":          "Distance in pixels",
+	"width":           "Width in pixels",
+	"height":          "Height in pixels",
+	"delay":           "Delay between keystrokes in milliseconds",
+	"maxText":         "Maximum length of the text excerpt",
+	"method":          "HTTP method",
+	"headers":         "HTTP headers",
+	"body":            "Request body",
+	"toEnd":           "Scroll down until no new content loads",
+	"maxRounds":       "Maximum number of scrolls",
+	"untilSelector":   "Stop scrolling once an element matches this selector",
+	"next":            "Next-page control: CSS selector or text=Label",
+	"maxPages":        "Maximum number of pages",
+	"heapSnapshot":    "Path to save a heap snapshot of the active tab",
+	"filter":          "Resource type (xhr, fetch, document, ...) or URL substring to match",
+	"clear":           "Empty the log after listing it",
+	"userAgent":       "User agent string",
+	"urls":            "URLs the cookies are sent to",
+	"cookies":         "Cookies: name, value, url or domain, path, expires (Unix seconds), httpOnly, secure, sameSite",
+	"latitude":        "Latitude in decimal degrees (-90 to 90)",
+	"longitude":       "Longitude in decimal degrees (-180 to 180)",
+	"accuracy":        "Accuracy radius in meters",
+	"permissions":     "Permission names, e.g. geolocation, notifications, camera, clipboard",
+	"origin":          "Origin the change applies to, e.g. https://example.com; empty for every origin",
+	"duration":        "Duration in milliseconds",
+	"event":           "DOM event type, e.g. input, change, blur or a custom name",
+	"eventInit":       "Event init fields, e.g. {\"bubbles\": false}, {\"key\": \"Enter\"} or {\"detail\": {...}}",
+
+	// Overrides for one action, keyed by "action.field"
+	"frame.name":                   "Frame name",
+	"locale.locale":                "BCP 47 language tag, e.g. en-US, de-DE or zh-CN",
+	"credentials.username":         "User name",
+	"credentials.password":         "Password",
+	"credentials.origin":           "Only answer this origin, e.g. https://intranet.example.com; empty for every origin",
+	"credentials.clear":            "Remove the credentials instead of setting them",
+	"headers.headers":              "Header names and values, e.g. {\"Authorization\": \"Bearer abc\"}",
+	"emulatemedia.media":           "CSS media type",
+	"emulatemedia.colorScheme":     "prefers-color-scheme",
+	"emulatemedia.reducedMotion":   "prefers-reduced-motion",
+	"emulatemedia.forcedColors":    "forced-colors",
+	"offline.offline":              "true to go offline, false to go back online",
+	"geolocation.clear":            "Remove the override instead of setting a position",
+	"permissions.grant":            "Grant the permissions; false denies them",
+	"permissions.reset":            "Drop every grant and denial instead",
+	"waitforurl.url":               "URL glob (e.g. **/dashboard) or /regex/",
+	"select.values":                "Options to select: values, labels or 0-based indexes (see by)",
+	"addscript.content":            "JavaScript source",
+	"addscript.url":                "URL of the script",
+	"addstyle.content":             "CSS source",
+	"addstyle.url":                 "URL of the stylesheet",
+	"addinitscript.script":         "JavaScript source to run at the start of every document",
+	"inserttext.selector":          "Element to focus first: CSS selector or snapshot ref (e.g. @e1); empty for the focused element",
+	"keyboard.keys":                "Key combos separated by spaces, e.g. Control+a or \"Control+a Delete\"; modifiers are Control, Shift, Alt, Meta and ControlOrMeta",
+	"getbyrole.role":               "ARIA role, explicit or implicit (a <button> is a button, an <input type=email> a textbox)",
+	"getbyrole.name":               "Accessible name to look for; a case-insensitive substring unless exact",
+	"getbyrole.exact":              "Match the whole name, case-sensitively",
+	"getbyrole.subaction":          "What to do with the element (default describe)",
+	"getbyrole.value":              "Text for fill and type",
+	"getbytext.text":               "Text to look for; a case-insensitive substring unless exact",
+	"getbytext.exact":              "Match the element's whole text, case-sensitively",
+	"getbytext.subaction":          "What to do with the element (default describe)",
+	"getbytext.value":              "Text for fill and type",
+	"getbylabel.label":             "Label text to look for; a case-insensitive substring unless exact",
+	"getbylabel.exact":             "Match the whole label, case-sensitively",
+	"getbylabel.subaction":         "What to do with the control (default describe)",
+	"getbylabel.value":             "Text for fill and type",
+	"getbyplaceholder.placeholder": "Placeholder to look for; a case-insensitive substring unless exact",
+	"getbyplaceholder.exact":       "Match the whole placeholder, case-sensitively",
+	"getbyplaceholder.subaction":   "What to do with the element (default describe)",
+	"getbyplaceholder.value":       "Text for fill and type",
+	"getbyalttext.text":            "Alt text to look for; a case-insensitive substring unless exact",
+	"getbyalttext.exact":           "Match the whole alt text, case-sensitively",
+	"getbyalttext.subaction":       "What to do with the element (default describe)",
+	"getbyalttext.value":           "Text for fill and type",
+	"getbytitle.text":              "Title to look for; a case-insensitive substring unless exact",
+	"getbytitle.exact":             "Match the whole title, case-sensitively",
+	"getbytitle.subaction":         "What to do with the element (default describe)",
+	"getbytitle.value":             "Text for fill and type",
+	"getbytestid.testId":           "Test id, matched whole",
+	"getbytestid.subaction":        "What to do with the element (default describe)",
+	"getbytestid.value":            "Text for fill and type",
+	"window_new.viewport":          "Viewport size of the new window's tab; empty for the default",
+	"wheel.deltaX":                 "Pixels to scroll right (negative for left)",
+	"wheel.deltaY":                 "Pixels to scroll down (negative for up)",
+	"wheel.selector":               "Element to scroll over: CSS selector or snapshot ref (e.g. @e1); empty for where the mouse is",
+	"mousemove.x":                  "Horizontal position in CSS pixels from the viewport's left edge",
+	"mousemove.y":                  "Vertical position in CSS pixels from the viewport's top edge",
+	"mousemove.steps":              "Number of moves to get there (default 1)",
+	"mousedown.button":             "Mouse button (default left)",
+	"mouseup.button":               "Mouse button (default left)",
+	"clipboard.operation":          "copy text to the clipboard, paste it into an element, or read it",
+	"clipboard.text":               "Text to copy",
+	"clipboard.selector":           "Element to paste into: CSS selector or snapshot ref (e.g. @e1)",
+	"input_keyboard.type":          "Key event type",
+	"input_keyboard.key":           "Key name, e.g. a, Enter, ArrowLeft or Shift, or a code such as KeyA",
+	"input_keyboard.code":          "Physical key code to report, e.g. KeyW; derived from key if empty",
+	"input_keyboard.text":          "Text a keyDown or char event types; derived from key if empty",
+	"input_keyboard.modifiers":     "Extra keys held during the event as a bit field: Alt 1, Control 2, Meta 4, Shift 8",
+	"input_mouse.type":             "Mouse event type",
+	"input_mouse.x":                "Horizontal position in CSS pixels from the viewport's left edge",
+	"input_mouse.y":                "Vertical position in CSS pixels from the viewport's top edge",
+	"input_mouse.button":           "Mouse button; pressed and released events default to left",
+	"input_mouse.clickCount":       "Click count, e.g. 2 for the second click of a double-click",
+	"input_mouse.deltaX":           "Horizontal scroll of a mouseWheel event in pixels",
+	"input_mouse.deltaY":           "Vertical scroll of a mouseWheel event in pixels",
+	"input_mouse.modifiers":        "Keys held during the event as a bit field: Alt 1, Control 2, Meta 4, Shift 8",
+	"trace_start.screenshots":      "Record screenshots for the timeline",
+	"trace_start.snapshots":        "Record DOM snapshots and network activity (Playwright only)",
+	"trace_stop.path":              "File to save the trace to",
+	"record_start.path":            "A .webm or .mp4 file to save the video to, or a directory to save a timestamped one in",
+	"record_start.format":          "webm (default) or mp4, when path is a directory",
+	"storage_get.key":              "Storage key; empty for every entry",
+	"storage_set.key":              "Storage key",
+	"fill_form.fields":             "Field label, name, CSS selector or snapshot ref to value, e.g. {\"Email\": \"ada@example.com\", \"Country\": \"France\", \"I agree\": true}",
+}
+
+// fieldEnums lists allowed values for enumerated parameters, keyed by "action.field".
+var fieldEnums = map[string][]string{
+	"navigate.waitUntil":         {"load", "domcontentloaded", "networkidle"},
+	"scroll.direction":           {"up", "down", "left", "right"},
+	"wait.state":                 {"attached", "detached", "visible", "hidden"},
+	"screenshot.format":          {"png", "jpeg"},
+	"pdf.format":                 {"Letter", "Legal", "Tabloid", "Ledger", "A0", "A1", "A2", "A3", "A4", "A5", "A6"},
+	"select.by":                  {"value", "label", "index"},
+	"click.button":               {"left", "right", "middle"},
+	"snapshot.format":            {SnapshotFormatTree, SnapshotFormatCompactV2},
+	"stats.kind":                 {"memory"},
+	"audit.kind":                 {"page"},
+	"waitforloadstate.state":     {"load", "domcontentloaded", "networkidle"},
+	"mousedown.button":           {"left", "right", "middle"},
+	"mouseup.button":             {"left", "right", "middle"},
+	"clipboard.operation":        {"copy", "paste", "read"},
+	"input_keyboard.type":        {"keyDown", "keyUp", "char"},
+	"input_mouse.type":           {"mousePressed", "mouseReleased", "mouseMoved", "mouseWheel"},
+	"input_mouse.button":         {"none", "left", "right", "middle", "back", "forward"},
+	"getbyrole.subaction":        {"click", "dblclick", "fill", "type", "check", "uncheck", "hover", "focus", "text", "describe"},
+	"getbytext.subaction":        {"click", "dblclick", "fill", "type", "check", "uncheck", "hover", "focus", "text", "describe"},
+	"getbylabel.subaction":       {"click", "dblclick", "fill", "type", "check", "uncheck", "hover", "focus", "text", "describe"},
+	"getbyplaceholder.subaction": {"click", "dblclick", "fill", "type", "check", "uncheck", "hover", "focus", "text", "describe"},
+	"getbyalttext.subaction":     {"click", "dblclick", "fill", "type", "check", "uncheck", "hover", "focus", "text", "describe"},
+	"getbytitle.subaction":       {"click", "dblclick", "fill", "type", "check", "uncheck", "hover", "focus", "text", "describe"},
+	"getbytestid.subaction":      {"click", "dblclick", "fill", "type", "check", "uncheck", "hover", "focus", "text", "describe"},
+	"storage_get.type":           {"local", "session"},
+	"storage_set.type":           {"local", "session"},
+	"storage_clear.type":         {"local", "session"},
+	"emulatemedia.media":         {"screen", "print", "no-override"},
+	"emulatemedia.colorScheme":   {"light", "dark", "no-override"},
+	"emulatemedia.reducedMotion": {"reduce", "no-preference", "no-override"},
+	"emulatemedia.forcedColors":  {"active", "none", "no-override"},
+	"dialog.response":            {"accept", "dismiss"},
+}
+
+// ToolSpecs returns tool definitions for the commands agents can call.
+func ToolSpecs() []ToolSpec {
+	specs := make([]ToolSpec, 0, len(toolCommands))
+	for _, tc := range toolCommands {
+		cmd, err := ParseCommand([]byte(fmt.Sprintf(`{"id":"toolspec","action":%q}`, tc.Action)))
+		if err != nil {
+			panic(fmt.Sprintf("tool %s: %v", tc.Action, err)) // toolCommands names an unknown action
+		}
+		specs = append(specs, ToolSpec{
+			Name:        tc.Action,
+			Description: tc.Description,
+			Parameters:  commandSchema(tc.Action, reflect.TypeOf(cmd)),
+		})
+	}
+	return specs
+}
+
+// FormatToolSpecs renders tool definitions in a provider's function-calling format.
+// Supported formats are "openai" and "anthropic".
+func FormatToolSpecs(format string) (interface{}, error) {
+	specs := ToolSpecs()
+
+	switch format {
+	case "openai", "":
+		tools := make([]map[string]interface{}, len(specs))
+		for i, s := range specs {
+			tools[i] = map[string]interface{}{
+				"type": "function",
+				"function": map[string]interface{}{
+					"name":        s.Name,
+					"description": s.Description,
+					"parameters":  s.Parameters,
+				},
+			}
+		}
+		return tools, nil
+	case "anthropic":
+		tools := make([]map[string]interface{}, len(specs))
+		for i, s := range specs {
+			tools[i] = map[string]interface{}{
+				"name":         s.Name,
+				"description":  s.Description,
+				"input_schema": s.Parameters,
+			}
+		}
+		return tools, nil
+	default:
+		return nil, fmt.Errorf("unknown toolspec format: %s (expected openai or anthropic)", format)
+	}
+}
+
+// commandSchema builds a JSON schema object for a command struct type.
+func commandSchema(action string, t reflect.Type) map[string]interface{} {
+	properties := make(map[string]interface{})
+	required := []string{}
+	collectFields(action, t, properties, &required)
+
+	schema := map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+// collectFields adds the JSON fields of a struct type to properties.
+func collectFields(action string, t reflect.Type, properties map[string]interface{}, required *[]string) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Anonymous {
+			if f.Type != reflect.TypeOf(BaseCommand{}) {
+				collectFields(action, f.Type, properties, required)
+			}
+			continue
+		}
+
+		name, omitempty := jsonFieldName(f)
+		if name == "" {
+			continue
+		}
+
+		prop := typeSchema(f.Type)
+		if desc, ok := fieldDescriptions[action+"."+name]; ok {
+			prop["description"] = desc
+		} else if desc, ok := fieldDescriptions[name]; ok && action != "" {
+			// Fields of nested objects are left to their parent's description
+			prop["description"] = desc
+		}
+		if enum, ok := fieldEnums[action+"."+name]; ok {
+			prop["enum"] = enum
+		}
+		properties[name] = prop
+
+		if !omitempty && f.Type.Kind() != reflect.Ptr {
+			*required = append(*required, name)
+		}
+	}
+}
+
+// jsonFieldName returns a field's JSON name and whether it is optional.
+func jsonFieldName(f reflect.StructField) (string, bool) {
+	if !f.IsExported() {
+		return "", false
+	}
+	tag := f.Tag.Get("json")
+	if tag == "-" {
+		return "", false
+	}
+	parts := strings.Split(tag, ",")
+	name := parts[0]
+	if name == "" {
+		name = f.Name
+	}
+	omitempty := false
+	for _, opt := range parts[1:] {
+		if opt == "omitempty" {
+			omitempty = true
+		}
+	}
+	return name, omitempty
+}
+
+// typeSchema maps a Go type to a JSON schema fragment.
+func typeSchema(t reflect.Type) map[string]interface{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	// FieldValues keep their order in Go but are an object in JSON
+	if t == reflect.TypeOf(FieldValues{}) {
+		return map[string]interface{}{"type": "object", "additionalProperties": map[string]interface{}{}}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{"type": "array", "items": typeSchema(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": typeSchema(t.Elem())}
+	case reflect.Struct:
+		properties := make(map[string]interface{})
+		required := []string{}
+		collectFields("", t, properties, &required)
+		schema := map[string]interface{}{"type": "object", "properties": properties}
+		if len(required) > 0 {
+			schema["required"] = required
+		}
+		return schema
+	default:
+		return map[string]interface{}{}
+	}
+}
@@ -0,0 +1,181 @@
+package agentbrowser_test
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"reflect"
+	"strconv"
+	"testing"
+
+	agentbrowser "github.com/cpunion/agent-browser-go"
+)
+
+// TestToolSpecs_Schema tests that tool schemas are derived from command types
+func TestToolSpecs_Schema(t *testing.T) {
+	specs := agentbrowser.ToolSpecs()
+	if len(specs) == 0 {
+		t.Fatal("expected tool specs")
+	}
+
+	var click *agentbrowser.ToolSpec
+	for i := range specs {
+		if specs[i].Name == "click" {
+			click = &specs[i]
+		}
+		if _, ok := specs[i].Parameters["properties"].(map[string]interface{})["id"]; ok {
+			t.Errorf("%s: id should not be exposed as a parameter", specs[i].Name)
+		}
+	}
+	if click == nil {
+		t.Fatal("expected click tool")
+	}
+
+	props := click.Parameters["properties"].(map[string]interface{})
+	if _, ok := props["selector"]; !ok {
+		t.Error("expected click to have selector parameter")
+	}
+	required, _ := click.Parameters["required"].([]string)
+	if len(required) != 1 || required[0] != "selector" {
+		t.Errorf("expected click to require only selector, got %v", required)
+	}
+}
+
+// TestToolSpecs_FieldDescriptions tests that a field can be described for
+// one action
+func TestToolSpecs_FieldDescriptions(t *testing.T) {
+	describe := func(action, field string) string {
+		for _, spec := range agentbrowser.ToolSpecs() {
+			if spec.Name == action {
+				prop, _ := spec.Parameters["properties"].(map[string]interface{})[field].(map[string]interface{})
+				desc, _ := prop["description"].(string)
+				return desc
+			}
+		}
+		t.Fatalf("expected %s tool", action)
+		return ""
+	}
+
+	if got, want := describe("requests", "clear"), "Empty the log after listing it"; got != want {
+		t.Errorf("requests.clear description = %q, want %q", got, want)
+	}
+	if got, want := describe("geolocation", "clear"), "Remove the override instead of setting a position"; got != want {
+		t.Errorf("geolocation.clear description = %q, want %q", got, want)
+	}
+}
+
+// TestFormatToolSpecs tests provider-specific tool formats
+func TestFormatToolSpecs(t *testing.T) {
+	tests := []struct {
+		format  string
+		key     string
+		wantErr bool
+	}{
+		{format: "openai", key: "function"},
+		{format: "anthropic", key: "input_schema"},
+		{format: "unknown", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			out, err := agentbrowser.FormatToolSpecs(tt.format)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("FormatToolSpecs() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			tools := out.([]map[string]interface{})
+			if _, ok := tools[0][tt.key]; !ok {
+				t.Errorf("expected %s key in %s format", tt.key, tt.format)
+			}
+		})
+	}
+}
+
+// toolExcluded are the actions ExecuteCommand runs that are not offered as
+// tools, with why.
+var toolExcluded = map[string]string{
+	"launch":            "the browser launches on demand with the session's options",
+	"login":             "its recipe carries credentials from the config, which the CLI fills in",
+	"cookies_export":    "moving session state between files is setup, not browsing",
+	"cookies_import":    "moving session state between files is setup, not browsing",
+	"state_save":        "moving session state between files is setup, not browsing",
+	"state_load":        "moving session state between files is setup, not browsing",
+	"polite":            "a session setting, made from the CLI",
+	"sw_bypass":         "a session setting, made from the CLI",
+	"ua_rotation_start": "a session setting, made from the CLI",
+	"ua_rotation_stop":  "a session setting, made from the CLI",
+	"ua_rotate":         "a session setting, made from the CLI",
+	"screencast_start":  "its frames are events, which a tool call cannot receive",
+	"screencast_stop":   "its frames are events, which a tool call cannot receive",
+	"watch_start":       "its snapshots are events, which a tool call cannot receive",
+	"watch_stop":        "its snapshots are events, which a tool call cannot receive",
+}
+
+// TestToolSpecs_Coverage tests that every action ExecuteCommand runs is
+// either a tool or excluded in toolExcluded
+func TestToolSpecs_Coverage(t *testing.T) {
+	fset := token.NewFileSet()
+	funcDecl := func(file, name string) *ast.FuncDecl {
+		f, err := parser.ParseFile(fset, file, nil, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, decl := range f.Decls {
+			if fn, ok := decl.(*ast.FuncDecl); ok && fn.Name.Name == name {
+				return fn
+			}
+		}
+		t.Fatalf("%s has no func %s", file, name)
+		return nil
+	}
+
+	// The command types ExecuteCommand switches on
+	dispatched := map[string]bool{}
+	ast.Inspect(funcDecl("actions.go", "ExecuteCommand"), func(n ast.Node) bool {
+		if c, ok := n.(*ast.CaseClause); ok {
+			for _, expr := range c.List {
+				if star, ok := expr.(*ast.StarExpr); ok {
+					dispatched[star.X.(*ast.Ident).Name] = true
+				}
+			}
+		}
+		return true
+	})
+	// The actions ParseCommand knows
+	var actions []string
+	ast.Inspect(funcDecl("protocol.go", "ParseCommand"), func(n ast.Node) bool {
+		if c, ok := n.(*ast.CaseClause); ok {
+			for _, expr := range c.List {
+				if lit, ok := expr.(*ast.BasicLit); ok && lit.Kind == token.STRING {
+					action, _ := strconv.Unquote(lit.Value)
+					actions = append(actions, action)
+				}
+			}
+		}
+		return true
+	})
+
+	tools := map[string]bool{}
+	for _, spec := range agentbrowser.ToolSpecs() {
+		tools[spec.Name] = true
+	}
+	for _, action := range actions {
+		cmd, err := agentbrowser.ParseCommand([]byte(`{"id":"1","action":` + strconv.Quote(action) + `}`))
+		if err != nil {
+			t.Errorf("ParseCommand(%s) error = %v", action, err)
+			continue
+		}
+		if !dispatched[reflect.TypeOf(cmd).Elem().Name()] {
+			continue
+		}
+		_, excluded := toolExcluded[action]
+		switch {
+		case tools[action] && excluded:
+			t.Errorf("%s is a tool and excluded", action)
+		case !tools[action] && !excluded:
+			t.Errorf("%s is neither a tool nor excluded in toolExcluded", action)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package agentbrowser
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// TraceOptions controls what a trace records besides the actions.
+type TraceOptions struct {
+	Screenshots bool // screenshots for the timeline
+	Snapshots   bool // DOM snapshots and network activity, Playwright only
+}
+
+// StartTracing starts recording a trace of the browser session. Playwright
+// records a trace zip for its trace viewer, chromedp a Chrome trace for the
+// Performance panel of DevTools.
+func (m *BrowserManager) StartTracing(opts TraceOptions) error {
+	return m.backend.StartTracing(opts)
+}
+
+// StopTracing stops the trace started by StartTracing and writes it to
+// path, creating its directory. It returns the absolute path, size and
+// format of the file.
+func (m *BrowserManager) StopTracing(path string) (*TraceData, error) {
+	if path == "" {
+		return nil, fmt.Errorf("trace path is required")
+	}
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	if err := m.backend.StopTracing(path); err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	trace := &TraceData{Path: path, Bytes: info.Size(), Format: "chrome"}
+	magic := make([]byte, 4)
+	if _, err := io.ReadFull(f, magic); err == nil && bytes.Equal(magic, []byte("PK\x03\x04")) {
+		trace.Format = "playwright"
+	}
+	return trace, nil
+}
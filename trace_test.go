@@ -0,0 +1,44 @@
+package agentbrowser_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	agentbrowser "github.com/cpunion/agent-browser-go"
+)
+
+// traceBackend is a fake backend whose traces hold trace.
+func traceBackend(trace string) *fakeBackend {
+	return &fakeBackend{
+		stopTracing: func(path string) error { return os.WriteFile(path, []byte(trace), 0o644) },
+	}
+}
+
+// TestStopTracing tests that the trace directory is created and the format
+// of the written trace reported
+func TestStopTracing(t *testing.T) {
+	dir := t.TempDir()
+	for _, tt := range []struct {
+		trace  string
+		format string
+	}{
+		{`{"traceEvents":[]}`, "chrome"},
+		{"PK\x03\x04zip", "playwright"},
+	} {
+		m := agentbrowser.NewBrowserManagerForTest(traceBackend(tt.trace))
+		path := filepath.Join(dir, tt.format, "trace")
+		trace, err := m.StopTracing(path)
+		if err != nil {
+			t.Fatalf("StopTracing() error = %v", err)
+		}
+		if trace.Path != path || trace.Bytes != int64(len(tt.trace)) || trace.Format != tt.format {
+			t.Errorf("StopTracing() = %+v, want %s (%d bytes, %s)", trace, path, len(tt.trace), tt.format)
+		}
+	}
+
+	m := agentbrowser.NewBrowserManagerForTest(traceBackend(""))
+	if _, err := m.StopTracing(""); err == nil {
+		t.Error("StopTracing() without a path expected an error")
+	}
+}
@@ -0,0 +1,56 @@
+package agentbrowser_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	agentbrowser "github.com/cpunion/agent-browser-go"
+)
+
+// TestUpload tests that uploads pass existing files through and reject
+// missing files and directories before reaching the browser
+func TestUpload(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "report.txt")
+	if err := os.WriteFile(file, []byte("report"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var selector string
+	var files []string
+	m := agentbrowser.NewBrowserManagerForTest(&fakeBackend{
+		upload: func(s string, f []string) error {
+			selector, files = s, f
+			return nil
+		},
+	})
+	if err := m.Upload("#file", []string{file}); err != nil {
+		t.Fatalf("Upload() error = %v", err)
+	}
+	if selector != "#file" || !reflect.DeepEqual(files, []string{file}) {
+		t.Errorf("backend got %q %v, want #file [%s]", selector, files, file)
+	}
+
+	tests := []struct {
+		name  string
+		files []string
+		want  string
+	}{
+		{"none", nil, "no files"},
+		{"missing", []string{file, filepath.Join(dir, "missing.txt")}, "missing.txt"},
+		{"directory", []string{dir}, "is a directory"},
+	}
+	for _, tt := range tests {
+		files = nil
+		err := m.Upload("#file", tt.files)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: Upload() error = %v, want %q", tt.name, err, tt.want)
+		}
+		if files != nil {
+			t.Errorf("%s: backend got %v, want no upload", tt.name, files)
+		}
+	}
+}
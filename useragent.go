@@ -0,0 +1,238 @@
+package agentbrowser
+
+import (
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/chromedp/cdproto/emulation"
+)
+
+// userAgentOverride is a user agent together with the navigator.platform
+// and client hints (Sec-CH-UA-*, navigator.userAgentData) that agree with it.
+// It marshals to the parameters of Emulation.setUserAgentOverride.
+type userAgentOverride struct {
+	UserAgent         string                       `json:"userAgent"`
+	Platform          string                       `json:"platform,omitempty"`
+	UserAgentMetadata *emulation.UserAgentMetadata `json:"userAgentMetadata,omitempty"`
+	AcceptLanguage    string                       `json:"acceptLanguage,omitempty"` // also sets navigator.language
+}
+
+var (
+	chromeVersionRe = regexp.MustCompile(`Chrome/(\d+)\.([\d.]+)`)
+	edgeVersionRe   = regexp.MustCompile(`Edg/(\d+)\.([\d.]+)`)
+	macVersionRe    = regexp.MustCompile(`Mac OS X (\d+)[_.](\d+)(?:[_.](\d+))?`)
+	androidRe       = regexp.MustCompile(`Android (\d+)`)
+)
+
+// userAgentOverrideFor derives the platform and client hints for a user
+// agent. Client hints are only sent for Chromium-based user agents.
+func userAgentOverrideFor(ua string) userAgentOverride {
+	o := userAgentOverride{UserAgent: ua}
+	if ua == "" {
+		return o
+	}
+
+	var chPlatform, platformVersion string
+	switch {
+	case strings.Contains(ua, "Windows"):
+		o.Platform, chPlatform, platformVersion = "Win32", "Windows", "10.0.0"
+	case strings.Contains(ua, "Android"):
+		o.Platform, chPlatform = "Linux armv8l", "Android"
+		if m := androidRe.FindStringSubmatch(ua); m != nil {
+			platformVersion = m[1] + ".0.0"
+		}
+	case strings.Contains(ua, "iPhone"):
+		o.Platform = "iPhone"
+	case strings.Contains(ua, "iPad"):
+		o.Platform = "iPad"
+	case strings.Contains(ua, "Macintosh"):
+		o.Platform, chPlatform = "MacIntel", "macOS"
+		if m := macVersionRe.FindStringSubmatch(ua); m != nil {
+			patch := m[3]
+			if patch == "" {
+				patch = "0"
+			}
+			platformVersion = m[1] + "." + m[2] + "." + patch
+		}
+	case strings.Contains(ua, "CrOS"):
+		o.Platform, chPlatform = "Linux x86_64", "Chrome OS"
+	case strings.Contains(ua, "Linux"):
+		o.Platform, chPlatform = "Linux x86_64", "Linux"
+	}
+
+	chrome := chromeVersionRe.FindStringSubmatch(ua)
+	if chrome == nil || chPlatform == "" {
+		return o
+	}
+
+	major, full := chrome[1], chrome[1]+"."+chrome[2]
+	brand, brandMajor, brandFull := "Google Chrome", major, full
+	if edge := edgeVersionRe.FindStringSubmatch(ua); edge != nil {
+		brand, brandMajor, brandFull = "Microsoft Edge", edge[1], edge[1]+"."+edge[2]
+	}
+
+	mobile := strings.Contains(ua, "Mobile")
+	arch, bitness := "x86", "64"
+	if chPlatform == "Android" {
+		arch, bitness = "", ""
+	}
+
+	o.UserAgentMetadata = &emulation.UserAgentMetadata{
+		Brands: []*emulation.UserAgentBrandVersion{
+			{Brand: "Chromium", Version: major},
+			{Brand: brand, Version: brandMajor},
+			{Brand: "Not?A_Brand", Version: "99"},
+		},
+		FullVersionList: []*emulation.UserAgentBrandVersion{
+			{Brand: "Chromium", Version: full},
+			{Brand: brand, Version: brandFull},
+			{Brand: "Not?A_Brand", Version: "99.0.0.0"},
+		},
+		Platform:        chPlatform,
+		PlatformVersion: platformVersion,
+		Architecture:    arch,
+		Bitness:         bitness,
+		Mobile:          mobile,
+	}
+	return o
+}
+
+// GetUserAgentFile returns the launch user agent file path for a session.
+func GetUserAgentFile(session string) string {
+	dir := filepath.Join(os.TempDir(), "agent-browser-go")
+	_ = os.MkdirAll(dir, 0755)
+	return filepath.Join(dir, fmt.Sprintf("%s.useragent", session))
+}
+
+// SaveSessionUserAgent saves the user agent the session's browser launches
+// with. Empty removes it.
+func SaveSessionUserAgent(session, ua string) error {
+	if ua == "" {
+		err := os.Remove(GetUserAgentFile(session))
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	return os.WriteFile(GetUserAgentFile(session), []byte(ua), 0644)
+}
+
+// GetSessionUserAgent retrieves the saved launch user agent for a session.
+// Returns "" (the browser's own) if none is saved.
+func GetSessionUserAgent(session string) string {
+	data, err := os.ReadFile(GetUserAgentFile(session))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// DefaultRotationUserAgents returns the user agents rotated through when no
+// list is configured: desktop Chrome on each fingerprint platform.
+func DefaultRotationUserAgents() []string {
+	names := make([]string, 0, len(fingerprintPlatforms))
+	for name := range fingerprintPlatforms {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var uas []string
+	for _, name := range names {
+		for _, version := range fingerprintChromeVersions {
+			uas = append(uas, fmt.Sprintf("Mozilla/5.0 (%s) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/%s Safari/537.36",
+				fingerprintPlatforms[name].UAOS, version))
+		}
+	}
+	return uas
+}
+
+// UARotation configures user-agent rotation.
+type UARotation struct {
+	UserAgents    []string // user agents to rotate through (default: DefaultRotationUserAgents)
+	PerNavigation bool     // rotate before every navigation, not only on RotateUserAgent
+	Random        bool     // pick the next user agent at random instead of in order
+}
+
+// StartUARotation starts rotating the user agent and applies the first one.
+// The user agent is set in every tab with matching client hints, and
+// replaces any fingerprint or stealth user agent until StopUARotation.
+func (m *BrowserManager) StartUARotation(r UARotation) (string, error) {
+	if len(r.UserAgents) == 0 {
+		r.UserAgents = DefaultRotationUserAgents()
+	}
+
+	m.uaLock.Lock()
+	m.uaRotation = &r
+	m.uaIndex = -1
+	m.uaLock.Unlock()
+
+	return m.RotateUserAgent()
+}
+
+// StopUARotation stops rotating and restores the launch user agent.
+func (m *BrowserManager) StopUARotation() error {
+	m.uaLock.Lock()
+	m.uaRotation = nil
+	m.uaLock.Unlock()
+
+	return m.backend.SetUserAgent("")
+}
+
+// RotateUserAgent switches to the next user agent in the rotation.
+func (m *BrowserManager) RotateUserAgent() (string, error) {
+	m.uaLock.Lock()
+	r := m.uaRotation
+	if r == nil {
+		m.uaLock.Unlock()
+		return "", fmt.Errorf("user agent rotation is not started")
+	}
+	next := (m.uaIndex + 1) % len(r.UserAgents)
+	if r.Random {
+		if m.uaIndex < 0 || len(r.UserAgents) == 1 {
+			next = rand.Intn(len(r.UserAgents))
+		} else {
+			// Never repeat the current user agent
+			next = rand.Intn(len(r.UserAgents) - 1)
+			if next >= m.uaIndex {
+				next++
+			}
+		}
+	}
+	m.uaIndex = next
+	m.uaUnused = true
+	ua := r.UserAgents[next]
+	m.uaLock.Unlock()
+
+	if err := m.backend.SetUserAgent(ua); err != nil {
+		return "", err
+	}
+	return ua, nil
+}
+
+// rotateForNavigation rotates the user agent when rotation is per
+// navigation. A user agent that no navigation has used yet is kept, so the
+// first navigation after starting or rotating uses the one just applied.
+func (m *BrowserManager) rotateForNavigation() error {
+	m.uaLock.Lock()
+	perNavigation := m.uaRotation != nil && m.uaRotation.PerNavigation
+	unused := m.uaUnused
+	m.uaUnused = false
+	m.uaLock.Unlock()
+
+	if !perNavigation || unused {
+		return nil
+	}
+	if _, err := m.RotateUserAgent(); err != nil {
+		return err
+	}
+
+	m.uaLock.Lock()
+	m.uaUnused = false
+	m.uaLock.Unlock()
+	return nil
+}
@@ -0,0 +1,107 @@
+package agentbrowser_test
+
+import (
+	"os"
+	"testing"
+
+	agentbrowser "github.com/cpunion/agent-browser-go"
+)
+
+// uaBackend is a fake backend that keeps its user agent in *userAgent and
+// appends the user agent of each navigation to *navigated.
+func uaBackend(userAgent *string, navigated *[]string) *fakeBackend {
+	return &fakeBackend{
+		setUserAgent: func(ua string) error {
+			*userAgent = ua
+			return nil
+		},
+		navigate: func(url, waitUntil string) (string, string, error) {
+			*navigated = append(*navigated, *userAgent)
+			return url, "", nil
+		},
+	}
+}
+
+// TestUARotation tests on-demand and per-navigation rotation
+func TestUARotation(t *testing.T) {
+	var userAgent string
+	var navigated []string
+	m := agentbrowser.NewBrowserManagerForTest(uaBackend(&userAgent, &navigated))
+
+	if _, err := m.RotateUserAgent(); err == nil {
+		t.Error("expected error when rotation is not started")
+	}
+
+	ua, err := m.StartUARotation(agentbrowser.UARotation{UserAgents: []string{"a", "b", "c"}, PerNavigation: true})
+	if err != nil {
+		t.Fatalf("StartUARotation() error = %v", err)
+	}
+	if ua != "a" || userAgent != "a" {
+		t.Errorf("expected first user agent a, got %q (backend %q)", ua, userAgent)
+	}
+
+	for i := 0; i < 3; i++ {
+		_, _, _ = m.Navigate("https://example.com", "load")
+	}
+	if got := navigated; len(got) != 3 || got[0] != "a" || got[1] != "b" || got[2] != "c" {
+		t.Errorf("expected navigations with a, b, c; got %v", got)
+	}
+
+	if ua, _ := m.RotateUserAgent(); ua != "a" {
+		t.Errorf("expected rotation to wrap to a, got %q", ua)
+	}
+	_, _, _ = m.Navigate("https://example.com", "load")
+	if last := navigated[len(navigated)-1]; last != "a" {
+		t.Errorf("expected navigation to use the user agent just rotated to, got %q", last)
+	}
+
+	if err := m.StopUARotation(); err != nil {
+		t.Fatalf("StopUARotation() error = %v", err)
+	}
+	if userAgent != "" {
+		t.Errorf("expected launch user agent to be restored, got %q", userAgent)
+	}
+}
+
+// TestUARotation_Random tests that random rotation never repeats the current user agent
+func TestUARotation_Random(t *testing.T) {
+	var userAgent string
+	var navigated []string
+	m := agentbrowser.NewBrowserManagerForTest(uaBackend(&userAgent, &navigated))
+
+	prev, _ := m.StartUARotation(agentbrowser.UARotation{UserAgents: []string{"a", "b"}, Random: true})
+	for i := 0; i < 10; i++ {
+		ua, _ := m.RotateUserAgent()
+		if ua == prev {
+			t.Fatalf("rotation repeated %q", ua)
+		}
+		prev = ua
+	}
+}
+
+// TestSessionUserAgent tests saving and reading a session's launch user agent
+func TestSessionUserAgent(t *testing.T) {
+	session := "test-useragent-" + t.Name()
+	t.Cleanup(func() { os.Remove(agentbrowser.GetUserAgentFile(session)) })
+
+	if got := agentbrowser.GetSessionUserAgent(session); got != "" {
+		t.Errorf("GetSessionUserAgent() before saving = %q, want empty", got)
+	}
+
+	ua := "Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36"
+	if err := agentbrowser.SaveSessionUserAgent(session, ua); err != nil {
+		t.Fatalf("SaveSessionUserAgent() error = %v", err)
+	}
+	if got := agentbrowser.GetSessionUserAgent(session); got != ua {
+		t.Errorf("GetSessionUserAgent() = %q, want %q", got, ua)
+	}
+
+	for i := 0; i < 2; i++ { // removing twice is fine
+		if err := agentbrowser.SaveSessionUserAgent(session, ""); err != nil {
+			t.Fatalf("SaveSessionUserAgent(\"\") error = %v", err)
+		}
+	}
+	if got := agentbrowser.GetSessionUserAgent(session); got != "" {
+		t.Errorf("GetSessionUserAgent() after reset = %q, want empty", got)
+	}
+}
@@ -0,0 +1,161 @@
+package agentbrowser
+
+import (
+	"cmp"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+// waitPollInterval is how often waits poll the page.
+const waitPollInterval = 100 * time.Millisecond
+
+// defaultWaitTimeout is the timeout of waits in ms when neither the wait
+// nor the session config gives one.
+const defaultWaitTimeout = 30000
+
+// waitTimeout returns the timeout in ms of a wait: the one given, or else
+// the session's default.
+func (m *BrowserManager) waitTimeout(timeout int) int {
+	if timeout > 0 {
+		return timeout
+	}
+	m.session.lock.Lock()
+	defer m.session.lock.Unlock()
+	return cmp.Or(m.session.timeout, defaultWaitTimeout)
+}
+
+// networkIdleTime is how long a page must have no requests in flight to be
+// network idle, as in Playwright.
+const networkIdleTime = 500 * time.Millisecond
+
+// loadStates are the states WaitForLoadState waits for, in the order a page
+// reaches them.
+var loadStates = []string{"domcontentloaded", "load", "networkidle"}
+
+// URLPattern matches page URLs. It is a glob matched against the whole URL,
+// or a regular expression written between slashes, like /\/item\/\d+/ or
+// /dashboard/i, matched anywhere in the URL.
+type URLPattern struct {
+	raw string
+	re  *regexp.Regexp
+}
+
+// ParseURLPattern compiles a URL pattern. In globs, * matches any
+// characters but /, ** matches any characters and {a,b} matches either
+// alternative; ? is literal as URLs use it for queries. A glob without
+// wildcards must equal the URL.
+func ParseURLPattern(pattern string) (*URLPattern, error) {
+	if pattern == "" {
+		return nil, fmt.Errorf("empty URL pattern")
+	}
+	if re, flags, ok := splitRegexLiteral(pattern); ok {
+		if strings.Contains(flags, "i") {
+			re = "(?i)" + re
+		}
+		compiled, err := regexp.Compile(re)
+		if err != nil {
+			return nil, fmt.Errorf("invalid URL pattern %s: %w", pattern, err)
+		}
+		return &URLPattern{raw: pattern, re: compiled}, nil
+	}
+
+	compiled, err := regexp.Compile("^" + globRegexp(pattern) + "$")
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL pattern %s: %w", pattern, err)
+	}
+	return &URLPattern{raw: pattern, re: compiled}, nil
+}
+
+// splitRegexLiteral splits /re/flags into the expression and its flags.
+func splitRegexLiteral(pattern string) (re, flags string, ok bool) {
+	end := strings.LastIndex(pattern, "/")
+	if len(pattern) < 3 || pattern[0] != '/' || end < 2 {
+		return "", "", false
+	}
+	flags = pattern[end+1:]
+	if strings.Trim(flags, "i") != "" {
+		return "", "", false
+	}
+	return pattern[1:end], flags, true
+}
+
+// globRegexp converts a URL glob to a regular expression.
+func globRegexp(glob string) string {
+	var b strings.Builder
+	inGroup := false
+	for i := 0; i < len(glob); i++ {
+		switch c := glob[i]; {
+		case c == '*' && i+1 < len(glob) && glob[i+1] == '*':
+			b.WriteString(".*")
+			i++
+		case c == '*':
+			b.WriteString("[^/]*")
+		case c == '{' && !inGroup:
+			b.WriteString("(?:")
+			inGroup = true
+		case c == '}' && inGroup:
+			b.WriteString(")")
+			inGroup = false
+		case c == ',' && inGroup:
+			b.WriteString("|")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return b.String()
+}
+
+// Match reports whether url matches the pattern.
+func (p *URLPattern) Match(url string) bool {
+	return p.re.MatchString(url)
+}
+
+// String returns the pattern as written.
+func (p *URLPattern) String() string {
+	return p.raw
+}
+
+// WaitForURL waits until the current page's URL matches pattern, e.g. for
+// the redirect after a form is submitted, and returns the URL. The timeout
+// is in ms; 0 uses the session's default, 30000 unless configured.
+func (m *BrowserManager) WaitForURL(pattern string, timeout int) (string, error) {
+	p, err := ParseURLPattern(pattern)
+	if err != nil {
+		return "", err
+	}
+	timeout = m.waitTimeout(timeout)
+
+	deadline := time.Now().Add(time.Duration(timeout) * time.Millisecond)
+	last := ""
+	for {
+		// The URL may be unavailable mid-navigation; keep polling
+		if url, err := m.backend.URL(); err == nil {
+			if p.Match(url) {
+				return url, nil
+			}
+			last = url
+		}
+		if time.Now().After(deadline) {
+			return "", fmt.Errorf("timeout %dms waiting for URL to match %s (last URL: %s)", timeout, p, last)
+		}
+		time.Sleep(waitPollInterval)
+	}
+}
+
+// WaitForLoadState waits until the active tab's page reaches a load state:
+// domcontentloaded, load (the default) or networkidle, when no request has
+// been in flight for 500ms. A state the page already reached returns at
+// once. The timeout is in ms; 0 uses the session's default.
+func (m *BrowserManager) WaitForLoadState(state string, timeout int) error {
+	if state == "" {
+		state = "load"
+	}
+	if !slices.Contains(loadStates, state) {
+		return fmt.Errorf("unknown load state %q (expected %s)", state, strings.Join(loadStates, ", "))
+	}
+	timeout = m.waitTimeout(timeout)
+	return m.backend.WaitForLoadState(state, timeout)
+}
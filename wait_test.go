@@ -0,0 +1,110 @@
+package agentbrowser_test
+
+import (
+	"strings"
+	"testing"
+
+	agentbrowser "github.com/cpunion/agent-browser-go"
+)
+
+// TestURLPattern tests glob and regex URL matching
+func TestURLPattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		url     string
+		want    bool
+	}{
+		{"**/dashboard", "https://example.com/dashboard", true},
+		{"**/dashboard", "https://example.com/app/dashboard", true},
+		{"**/dashboard", "https://example.com/dashboard?tab=1", false},
+		{"**/dashboard*", "https://example.com/dashboard?tab=1", true},
+		{"https://example.com/*", "https://example.com/orders", true},
+		{"https://example.com/*", "https://example.com/orders/7", false},
+		{"https://example.com/**", "https://example.com/orders/7", true},
+		{"**/*.{png,jpg}", "https://cdn.example.com/a/logo.jpg", true},
+		{"**/*.{png,jpg}", "https://cdn.example.com/a/logo.gif", false},
+		{"https://example.com/search?q=go", "https://example.com/search?q=go", true},
+		{"https://example.com/search?q=go", "https://example.com/searchXq=go", false},
+		{"https://example.com/", "https://example.com/login", false},
+		{`/\/item\/\d+$/`, "https://shop.example.com/item/42", true},
+		{`/\/item\/\d+$/`, "https://shop.example.com/item/42/reviews", false},
+		{"/Dashboard/i", "https://example.com/dashboard", true},
+		{"/Dashboard/", "https://example.com/dashboard", false},
+	}
+	for _, tt := range tests {
+		p, err := agentbrowser.ParseURLPattern(tt.pattern)
+		if err != nil {
+			t.Errorf("ParseURLPattern(%q) error = %v", tt.pattern, err)
+			continue
+		}
+		if got := p.Match(tt.url); got != tt.want {
+			t.Errorf("ParseURLPattern(%q).Match(%q) = %v, want %v", tt.pattern, tt.url, got, tt.want)
+		}
+	}
+
+	for _, pattern := range []string{"", "/(unclosed/"} {
+		if _, err := agentbrowser.ParseURLPattern(pattern); err == nil {
+			t.Errorf("ParseURLPattern(%q) expected an error", pattern)
+		}
+	}
+}
+
+// TestWaitForURL tests polling the URL until it matches
+func TestWaitForURL(t *testing.T) {
+	urls := []string{
+		"https://example.com/login",
+		"https://example.com/login?next=%2Fdashboard",
+		"https://example.com/dashboard",
+	}
+	// The page moves on to the next URL each time it is asked
+	m := agentbrowser.NewBrowserManagerForTest(&fakeBackend{
+		url: func() (string, error) {
+			url := urls[0]
+			if len(urls) > 1 {
+				urls = urls[1:]
+			}
+			return url, nil
+		},
+	})
+
+	url, err := m.WaitForURL("**/dashboard", 5000)
+	if err != nil {
+		t.Fatalf("WaitForURL() error = %v", err)
+	}
+	if url != "https://example.com/dashboard" {
+		t.Errorf("WaitForURL() = %q, want the dashboard URL", url)
+	}
+
+	_, err = m.WaitForURL("**/settings", 200)
+	if err == nil || !strings.Contains(err.Error(), "last URL: https://example.com/dashboard") {
+		t.Errorf("WaitForURL(settings) error = %v, want a timeout naming the last URL", err)
+	}
+}
+
+// TestWaitForLoadState tests the load state and timeout defaults
+func TestWaitForLoadState(t *testing.T) {
+	var state string
+	var timeout int
+	m := agentbrowser.NewBrowserManagerForTest(&fakeBackend{
+		waitForLoadState: func(s string, t int) error {
+			state, timeout = s, t
+			return nil
+		},
+	})
+
+	if err := m.WaitForLoadState("", 0); err != nil {
+		t.Fatalf("WaitForLoadState() error = %v", err)
+	}
+	if state != "load" || timeout != 30000 {
+		t.Errorf("backend got %q, %d, want load, 30000", state, timeout)
+	}
+	if err := m.WaitForLoadState("networkidle", 5000); err != nil {
+		t.Fatalf("WaitForLoadState(networkidle) error = %v", err)
+	}
+	if state != "networkidle" || timeout != 5000 {
+		t.Errorf("backend got %q, %d, want networkidle, 5000", state, timeout)
+	}
+	if err := m.WaitForLoadState("idle", 0); err == nil {
+		t.Error("WaitForLoadState(idle) expected an error")
+	}
+}
@@ -0,0 +1,109 @@
+package agentbrowser
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// WatchOptions configures DOM change notifications.
+type WatchOptions struct {
+	Selector string // root element to observe (default: whole document)
+	Debounce int    // milliseconds to batch mutations before reporting (default 250)
+}
+
+// WatchChange summarizes the DOM mutations observed during one debounce window.
+type WatchChange struct {
+	URL     string         `json:"url"`
+	Added   map[string]int `json:"added,omitempty"`   // added elements by role
+	Removed map[string]int `json:"removed,omitempty"` // removed elements by role
+	Text    []string       `json:"text,omitempty"`    // text of added nodes and changed text, truncated
+}
+
+const (
+	// watchBinding is the page function the observer reports changes through.
+	watchBinding = "__agentBrowserWatch"
+
+	defaultWatchDebounce = 250
+)
+
+// watchScript returns a script that installs a MutationObserver reporting
+// summarized changes through watchBinding. It is idempotent per document.
+func watchScript(opts WatchOptions) string {
+	debounce := opts.Debounce
+	if debounce <= 0 {
+		debounce = defaultWatchDebounce
+	}
+
+	return fmt.Sprintf(`(() => {
+	if (window.__agentBrowserWatcher) return;
+	const selector = %q, debounce = %d, send = window[%q];
+	if (typeof send !== 'function') return;
+
+	const roles = {
+		A: 'link', BUTTON: 'button', INPUT: 'textbox', TEXTAREA: 'textbox', SELECT: 'combobox',
+		IMG: 'img', LI: 'listitem', UL: 'list', OL: 'list', TR: 'row', TD: 'cell', TABLE: 'table',
+		DIALOG: 'dialog', FORM: 'form', NAV: 'navigation', P: 'paragraph', ARTICLE: 'article',
+		H1: 'heading', H2: 'heading', H3: 'heading', H4: 'heading', H5: 'heading', H6: 'heading',
+	};
+	const skip = new Set(['SCRIPT', 'STYLE', 'NOSCRIPT', 'LINK', 'META']);
+	const roleOf = (el) => el.getAttribute('role') || roles[el.tagName] || 'generic';
+	const clip = (s) => { s = (s || '').replace(/\s+/g, ' ').trim(); return s.length > 120 ? s.slice(0, 120) + '...' : s; };
+
+	let pending = null, timer = null;
+	const reset = () => { pending = { added: {}, removed: {}, text: [] }; };
+	const addText = (s) => { s = clip(s); if (s && pending.text.length < 20 && !pending.text.includes(s)) pending.text.push(s); };
+	const flush = () => {
+		timer = null;
+		const p = pending;
+		reset();
+		if (!Object.keys(p.added).length && !Object.keys(p.removed).length && !p.text.length) return;
+		send(JSON.stringify({ url: location.href, added: p.added, removed: p.removed, text: p.text }));
+	};
+
+	const start = () => {
+		const root = (selector && document.querySelector(selector)) || document.documentElement;
+		reset();
+		const observer = new MutationObserver((records) => {
+			for (const r of records) {
+				if (r.type === 'characterData') {
+					addText(r.target.textContent);
+					continue;
+				}
+				for (const n of r.addedNodes) {
+					if (n.nodeType === 3) { addText(n.textContent); continue; }
+					if (n.nodeType !== 1 || skip.has(n.tagName)) continue;
+					const role = roleOf(n);
+					pending.added[role] = (pending.added[role] || 0) + 1;
+					addText(n.innerText || n.textContent);
+				}
+				for (const n of r.removedNodes) {
+					if (n.nodeType !== 1 || skip.has(n.tagName)) continue;
+					const role = roleOf(n);
+					pending.removed[role] = (pending.removed[role] || 0) + 1;
+				}
+			}
+			if (!timer) timer = setTimeout(flush, debounce);
+		});
+		observer.observe(root, { childList: true, subtree: true, characterData: true });
+		window.__agentBrowserWatcher = {
+			stop: () => { observer.disconnect(); clearTimeout(timer); delete window.__agentBrowserWatcher; },
+		};
+	};
+
+	if (document.readyState === 'loading') {
+		document.addEventListener('DOMContentLoaded', start, { once: true });
+	} else {
+		start();
+	}
+})()`, opts.Selector, debounce, watchBinding)
+}
+
+// stopWatchScript disconnects the observer installed by watchScript.
+const stopWatchScript = `window.__agentBrowserWatcher && window.__agentBrowserWatcher.stop()`
+
+// decodeWatchChange parses a payload sent through watchBinding.
+func decodeWatchChange(payload string) (WatchChange, error) {
+	var change WatchChange
+	err := json.Unmarshal([]byte(payload), &change)
+	return change, err
+}